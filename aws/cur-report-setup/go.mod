@@ -0,0 +1,10 @@
+module github.com/hamstah/awstools/aws/cur-report-setup
+
+go 1.15
+
+require (
+	github.com/aws/aws-sdk-go v1.36.31
+	github.com/hamstah/awstools/common v0.0.0-20210118215825-d772cda2a155
+	github.com/stretchr/testify v1.6.1
+	gopkg.in/alecthomas/kingpin.v2 v2.2.6
+)
@@ -0,0 +1,299 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/cloudformation"
+	"github.com/aws/aws-sdk-go/service/costandusagereportservice"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/sts"
+	"github.com/hamstah/awstools/common"
+	kingpin "gopkg.in/alecthomas/kingpin.v2"
+)
+
+var (
+	reportName   = kingpin.Flag("report-name", "Name of the Cost and Usage Report.").Required().String()
+	bucket       = kingpin.Flag("bucket", "S3 bucket the report is delivered to.").Required().String()
+	prefix       = kingpin.Flag("prefix", "S3 prefix of the report.").Default("cur").String()
+	bucketRegion = kingpin.Flag("bucket-region", "Region of the S3 bucket.").Default("us-east-1").String()
+	timeUnit     = kingpin.Flag("time-unit", "Granularity of the report.").Default("DAILY").Enum("HOURLY", "DAILY", "MONTHLY")
+	athena       = kingpin.Flag("athena", "Set up the Athena integration (Parquet report and Glue crawler stack).").Default("true").Bool()
+	stackName    = kingpin.Flag("athena-stack-name", "Name of the CloudFormation stack of the Athena integration. Defaults to <report-name>-cur-athena.").String()
+	apply        = kingpin.Flag("apply", "Create or update what is missing instead of only validating.").Default("false").Bool()
+)
+
+const (
+	statusOK      = "ok"
+	statusMissing = "missing"
+	statusPending = "pending"
+	statusApplied = "applied"
+)
+
+var (
+	failed = false
+)
+
+func printStatus(status, format string, args ...interface{}) {
+	if status == statusMissing {
+		failed = true
+	}
+	fmt.Printf("%-8s %s\n", status, fmt.Sprintf(format, args...))
+}
+
+func isErrorCode(err error, codes ...string) bool {
+	if aerr, ok := err.(awserr.Error); ok {
+		for _, code := range codes {
+			if aerr.Code() == code {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func main() {
+	kingpin.CommandLine.Name = "aws-cur-report-setup"
+	kingpin.CommandLine.Help = "Validate or set up a Cost and Usage Report with its bucket and Athena integration."
+	flags := common.HandleFlags()
+
+	sess, conf := common.OpenSession(flags)
+
+	identity, err := sts.New(sess, conf).GetCallerIdentity(&sts.GetCallerIdentityInput{})
+	common.FatalOnErrorW(err, "failed to get the account id")
+	accountID := *identity.Account
+
+	bucketConf := conf.Copy(&aws.Config{Region: bucketRegion})
+	s3Client := s3.New(sess, bucketConf)
+
+	setupBucket(s3Client)
+	setupBucketPolicy(s3Client, accountID)
+	setupReport(sess, conf)
+
+	if *athena {
+		name := *stackName
+		if name == "" {
+			name = fmt.Sprintf("%s-cur-athena", *reportName)
+		}
+		setupAthena(cloudformation.New(sess, bucketConf), s3Client, name)
+	}
+
+	if failed {
+		os.Exit(1)
+	}
+}
+
+func setupBucket(client *s3.S3) {
+	_, err := client.HeadBucket(&s3.HeadBucketInput{Bucket: bucket})
+	if err == nil {
+		printStatus(statusOK, "bucket %s exists", *bucket)
+		return
+	}
+	if !isErrorCode(err, "NotFound", s3.ErrCodeNoSuchBucket) {
+		common.FatalOnErrorW(err, "failed to check the bucket")
+	}
+
+	if !*apply {
+		printStatus(statusMissing, "bucket %s does not exist", *bucket)
+		return
+	}
+
+	input := &s3.CreateBucketInput{Bucket: bucket}
+	if *bucketRegion != "us-east-1" {
+		input.CreateBucketConfiguration = &s3.CreateBucketConfiguration{LocationConstraint: bucketRegion}
+	}
+	_, err = client.CreateBucket(input)
+	common.FatalOnErrorW(err, "failed to create the bucket")
+
+	_, err = client.PutPublicAccessBlock(&s3.PutPublicAccessBlockInput{
+		Bucket: bucket,
+		PublicAccessBlockConfiguration: &s3.PublicAccessBlockConfiguration{
+			BlockPublicAcls:       aws.Bool(true),
+			BlockPublicPolicy:     aws.Bool(true),
+			IgnorePublicAcls:      aws.Bool(true),
+			RestrictPublicBuckets: aws.Bool(true),
+		},
+	})
+	common.FatalOnErrorW(err, "failed to block public access to the bucket")
+
+	printStatus(statusApplied, "created bucket %s", *bucket)
+}
+
+func setupBucketPolicy(client *s3.S3, accountID string) {
+	policy := ""
+	res, err := client.GetBucketPolicy(&s3.GetBucketPolicyInput{Bucket: bucket})
+	if err != nil {
+		if !isErrorCode(err, "NoSuchBucketPolicy", "NotFound", s3.ErrCodeNoSuchBucket) {
+			common.FatalOnErrorW(err, "failed to get the bucket policy")
+		}
+	} else {
+		policy = aws.StringValue(res.Policy)
+	}
+
+	document, err := ParsePolicy(policy)
+	common.FatalOnErrorW(err, "failed to parse the bucket policy")
+
+	missing := MissingStatements(document, RequiredStatements(*bucket, accountID))
+	if len(missing) == 0 {
+		printStatus(statusOK, "bucket policy allows %s", billingReportsPrincipal)
+		return
+	}
+
+	if !*apply {
+		for _, statement := range missing {
+			printStatus(statusMissing, "bucket policy statement %s", statement["Sid"])
+		}
+		return
+	}
+
+	document.Statement = append(document.Statement, missing...)
+	updated, err := json.Marshal(document)
+	common.FatalOnErrorW(err, "failed to serialise the bucket policy")
+
+	_, err = client.PutBucketPolicy(&s3.PutBucketPolicyInput{
+		Bucket: bucket,
+		Policy: aws.String(string(updated)),
+	})
+	common.FatalOnErrorW(err, "failed to update the bucket policy")
+
+	for _, statement := range missing {
+		printStatus(statusApplied, "added bucket policy statement %s", statement["Sid"])
+	}
+}
+
+func setupReport(sess *session.Session, conf *aws.Config) {
+	// the Cost and Usage Report API is only available in us-east-1
+	client := costandusagereportservice.New(sess, conf.Copy(&aws.Config{Region: aws.String("us-east-1")}))
+
+	expected := ExpectedReportSettings(*timeUnit, *bucket, *prefix, *bucketRegion, *athena)
+
+	var existing *costandusagereportservice.ReportDefinition
+	input := &costandusagereportservice.DescribeReportDefinitionsInput{}
+	for existing == nil {
+		res, err := client.DescribeReportDefinitions(input)
+		common.FatalOnErrorW(err, "failed to describe the report definitions")
+
+		for _, definition := range res.ReportDefinitions {
+			if aws.StringValue(definition.ReportName) == *reportName {
+				existing = definition
+			}
+		}
+
+		if res.NextToken == nil {
+			break
+		}
+		input.NextToken = res.NextToken
+	}
+
+	if existing == nil {
+		if !*apply {
+			printStatus(statusMissing, "report %s does not exist", *reportName)
+			return
+		}
+
+		_, err := client.PutReportDefinition(&costandusagereportservice.PutReportDefinitionInput{
+			ReportDefinition: reportDefinition(expected),
+		})
+		common.FatalOnErrorW(err, "failed to create the report")
+		printStatus(statusApplied, "created report %s", *reportName)
+		return
+	}
+
+	diffs := expected.Diff(reportSettings(existing))
+	if len(diffs) == 0 {
+		printStatus(statusOK, "report %s is configured", *reportName)
+		return
+	}
+
+	if !*apply {
+		for _, diff := range diffs {
+			printStatus(statusMissing, "report %s: %s", *reportName, diff)
+		}
+		return
+	}
+
+	_, err := client.ModifyReportDefinition(&costandusagereportservice.ModifyReportDefinitionInput{
+		ReportName:       reportName,
+		ReportDefinition: reportDefinition(expected),
+	})
+	common.FatalOnErrorW(err, "failed to update the report")
+	for _, diff := range diffs {
+		printStatus(statusApplied, "report %s: fixed %s", *reportName, diff)
+	}
+}
+
+func reportSettings(definition *costandusagereportservice.ReportDefinition) ReportSettings {
+	return ReportSettings{
+		TimeUnit:                 aws.StringValue(definition.TimeUnit),
+		Format:                   aws.StringValue(definition.Format),
+		Compression:              aws.StringValue(definition.Compression),
+		AdditionalSchemaElements: aws.StringValueSlice(definition.AdditionalSchemaElements),
+		S3Bucket:                 aws.StringValue(definition.S3Bucket),
+		S3Prefix:                 aws.StringValue(definition.S3Prefix),
+		S3Region:                 aws.StringValue(definition.S3Region),
+		AdditionalArtifacts:      aws.StringValueSlice(definition.AdditionalArtifacts),
+		RefreshClosedReports:     aws.BoolValue(definition.RefreshClosedReports),
+		ReportVersioning:         aws.StringValue(definition.ReportVersioning),
+	}
+}
+
+func reportDefinition(settings ReportSettings) *costandusagereportservice.ReportDefinition {
+	definition := &costandusagereportservice.ReportDefinition{
+		ReportName:               reportName,
+		TimeUnit:                 aws.String(settings.TimeUnit),
+		Format:                   aws.String(settings.Format),
+		Compression:              aws.String(settings.Compression),
+		AdditionalSchemaElements: aws.StringSlice(settings.AdditionalSchemaElements),
+		S3Bucket:                 aws.String(settings.S3Bucket),
+		S3Prefix:                 aws.String(settings.S3Prefix),
+		S3Region:                 aws.String(settings.S3Region),
+		RefreshClosedReports:     aws.Bool(settings.RefreshClosedReports),
+		ReportVersioning:         aws.String(settings.ReportVersioning),
+	}
+	if len(settings.AdditionalArtifacts) > 0 {
+		definition.AdditionalArtifacts = aws.StringSlice(settings.AdditionalArtifacts)
+	}
+	return definition
+}
+
+// setupAthena deploys the Glue crawler template AWS delivers next to the
+// report once the first report has been generated.
+func setupAthena(client *cloudformation.CloudFormation, s3Client *s3.S3, name string) {
+	res, err := client.DescribeStacks(&cloudformation.DescribeStacksInput{StackName: aws.String(name)})
+	if err == nil && len(res.Stacks) > 0 {
+		printStatus(statusOK, "athena stack %s is %s", name, aws.StringValue(res.Stacks[0].StackStatus))
+		return
+	}
+	// a missing stack is reported as a validation error
+	if err != nil && !isErrorCode(err, "ValidationError") {
+		common.FatalOnErrorW(err, "failed to describe the athena stack")
+	}
+
+	templateKey := path.Join(*prefix, *reportName, "crawler-cfn.yml")
+	_, err = s3Client.HeadObject(&s3.HeadObjectInput{Bucket: bucket, Key: aws.String(templateKey)})
+	if err != nil {
+		if !isErrorCode(err, "NotFound", s3.ErrCodeNoSuchKey, s3.ErrCodeNoSuchBucket) {
+			common.FatalOnErrorW(err, "failed to check the athena template")
+		}
+		printStatus(statusPending, "athena template s3://%s/%s is not delivered yet, the first report can take up to 24 hours", *bucket, templateKey)
+		return
+	}
+
+	if !*apply {
+		printStatus(statusMissing, "athena stack %s does not exist", name)
+		return
+	}
+
+	_, err = client.CreateStack(&cloudformation.CreateStackInput{
+		StackName:    aws.String(name),
+		TemplateURL:  aws.String(fmt.Sprintf("https://%s.s3.%s.amazonaws.com/%s", *bucket, *bucketRegion, templateKey)),
+		Capabilities: aws.StringSlice([]string{cloudformation.CapabilityCapabilityIam}),
+	})
+	common.FatalOnErrorW(err, "failed to create the athena stack")
+	printStatus(statusApplied, "creating athena stack %s", name)
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+const (
+	billingReportsPrincipal = "billingreports.amazonaws.com"
+)
+
+// PolicyDocument is the subset of an IAM policy document needed to check and
+// update the report bucket policy. Unknown statement keys are preserved.
+type PolicyDocument struct {
+	Version   string                   `json:"Version"`
+	Statement []map[string]interface{} `json:"Statement"`
+}
+
+// RequiredStatements returns the bucket policy statements allowing the billing
+// reports service to deliver the reports of accountID to bucket.
+func RequiredStatements(bucket, accountID string) []map[string]interface{} {
+	condition := map[string]interface{}{
+		"StringEquals": map[string]interface{}{
+			"aws:SourceAccount": accountID,
+			"aws:SourceArn":     fmt.Sprintf("arn:aws:cur:us-east-1:%s:definition/*", accountID),
+		},
+	}
+
+	return []map[string]interface{}{
+		{
+			"Sid":       "CURGetBucket",
+			"Effect":    "Allow",
+			"Principal": map[string]interface{}{"Service": billingReportsPrincipal},
+			"Action":    []interface{}{"s3:GetBucketAcl", "s3:GetBucketPolicy"},
+			"Resource":  fmt.Sprintf("arn:aws:s3:::%s", bucket),
+			"Condition": condition,
+		},
+		{
+			"Sid":       "CURPutObject",
+			"Effect":    "Allow",
+			"Principal": map[string]interface{}{"Service": billingReportsPrincipal},
+			"Action":    "s3:PutObject",
+			"Resource":  fmt.Sprintf("arn:aws:s3:::%s/*", bucket),
+			"Condition": condition,
+		},
+	}
+}
+
+// ParsePolicy parses a bucket policy, an empty policy returns an empty document.
+func ParsePolicy(policy string) (*PolicyDocument, error) {
+	document := &PolicyDocument{Version: "2012-10-17"}
+	if policy == "" {
+		return document, nil
+	}
+
+	raw := map[string]interface{}{}
+	err := json.Unmarshal([]byte(policy), &raw)
+	if err != nil {
+		return nil, err
+	}
+
+	if version, ok := raw["Version"].(string); ok {
+		document.Version = version
+	}
+
+	// a single statement doesn't have to be wrapped in a list
+	switch statement := raw["Statement"].(type) {
+	case map[string]interface{}:
+		document.Statement = append(document.Statement, statement)
+	case []interface{}:
+		for _, item := range statement {
+			if itemMap, ok := item.(map[string]interface{}); ok {
+				document.Statement = append(document.Statement, itemMap)
+			}
+		}
+	}
+
+	return document, nil
+}
+
+// MissingStatements returns the required statements whose actions and
+// resources are not all allowed to the billing reports service by document.
+func MissingStatements(document *PolicyDocument, required []map[string]interface{}) []map[string]interface{} {
+	missing := []map[string]interface{}{}
+	for _, statement := range required {
+		for _, action := range stringOrSlice(statement["Action"]) {
+			if !document.allows(action, statement["Resource"].(string)) {
+				missing = append(missing, statement)
+				break
+			}
+		}
+	}
+	return missing
+}
+
+func (d *PolicyDocument) allows(action, resource string) bool {
+	for _, statement := range d.Statement {
+		if statement["Effect"] != "Allow" {
+			continue
+		}
+
+		principal, ok := statement["Principal"].(map[string]interface{})
+		if !ok || !contains(stringOrSlice(principal["Service"]), billingReportsPrincipal) {
+			continue
+		}
+
+		actions := stringOrSlice(statement["Action"])
+		if !contains(actions, action) && !contains(actions, "s3:*") && !contains(actions, "*") {
+			continue
+		}
+
+		if contains(stringOrSlice(statement["Resource"]), resource) {
+			return true
+		}
+	}
+	return false
+}
+
+func stringOrSlice(value interface{}) []string {
+	switch v := value.(type) {
+	case string:
+		return []string{v}
+	case []interface{}:
+		values := []string{}
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	}
+	return nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
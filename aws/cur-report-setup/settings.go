@@ -0,0 +1,79 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ReportSettings are the Cost and Usage Report definition settings managed by
+// the tool.
+type ReportSettings struct {
+	TimeUnit                 string
+	Format                   string
+	Compression              string
+	AdditionalSchemaElements []string
+	S3Bucket                 string
+	S3Prefix                 string
+	S3Region                 string
+	AdditionalArtifacts      []string
+	RefreshClosedReports     bool
+	ReportVersioning         string
+}
+
+// ExpectedReportSettings returns the settings of the report. The Athena
+// integration requires Parquet files overwriting the previous version and no
+// other artifacts.
+func ExpectedReportSettings(timeUnit, bucket, prefix, region string, athena bool) ReportSettings {
+	settings := ReportSettings{
+		TimeUnit:                 timeUnit,
+		Format:                   "textORcsv",
+		Compression:              "GZIP",
+		AdditionalSchemaElements: []string{"RESOURCES"},
+		S3Bucket:                 bucket,
+		S3Prefix:                 prefix,
+		S3Region:                 region,
+		AdditionalArtifacts:      []string{},
+		RefreshClosedReports:     true,
+		ReportVersioning:         "CREATE_NEW_REPORT",
+	}
+
+	if athena {
+		settings.Format = "Parquet"
+		settings.Compression = "Parquet"
+		settings.AdditionalArtifacts = []string{"ATHENA"}
+		settings.ReportVersioning = "OVERWRITE_REPORT"
+	}
+
+	return settings
+}
+
+// Diff returns a description of each setting of actual different from s.
+func (s ReportSettings) Diff(actual ReportSettings) []string {
+	diffs := []string{}
+
+	compare := func(name, expected, actual string) {
+		if expected != actual {
+			diffs = append(diffs, fmt.Sprintf("%s is %q, expected %q", name, actual, expected))
+		}
+	}
+
+	compare("TimeUnit", s.TimeUnit, actual.TimeUnit)
+	compare("Format", s.Format, actual.Format)
+	compare("Compression", s.Compression, actual.Compression)
+	compare("AdditionalSchemaElements", joinSorted(s.AdditionalSchemaElements), joinSorted(actual.AdditionalSchemaElements))
+	compare("S3Bucket", s.S3Bucket, actual.S3Bucket)
+	compare("S3Prefix", s.S3Prefix, actual.S3Prefix)
+	compare("S3Region", s.S3Region, actual.S3Region)
+	compare("AdditionalArtifacts", joinSorted(s.AdditionalArtifacts), joinSorted(actual.AdditionalArtifacts))
+	compare("RefreshClosedReports", fmt.Sprint(s.RefreshClosedReports), fmt.Sprint(actual.RefreshClosedReports))
+	compare("ReportVersioning", s.ReportVersioning, actual.ReportVersioning)
+
+	return diffs
+}
+
+func joinSorted(values []string) string {
+	sorted := append([]string{}, values...)
+	sort.Strings(sorted)
+	return strings.Join(sorted, ",")
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMissingStatements(t *testing.T) {
+	t.Parallel()
+
+	required := RequiredStatements("cur-bucket", "123456789012")
+
+	document, err := ParsePolicy("")
+	require.NoError(t, err)
+	require.Len(t, MissingStatements(document, required), 2)
+
+	document, err = ParsePolicy(`{
+		"Version": "2012-10-17",
+		"Statement": {
+			"Effect": "Allow",
+			"Principal": {"Service": ["billingreports.amazonaws.com"]},
+			"Action": "s3:*",
+			"Resource": ["arn:aws:s3:::cur-bucket/*"]
+		}
+	}`)
+	require.NoError(t, err)
+	missing := MissingStatements(document, required)
+	require.Len(t, missing, 1)
+	require.Equal(t, "CURGetBucket", missing[0]["Sid"])
+
+	document.Statement = append(document.Statement, missing...)
+	require.Empty(t, MissingStatements(document, required))
+}
+
+func TestReportSettingsDiff(t *testing.T) {
+	t.Parallel()
+
+	expected := ExpectedReportSettings("DAILY", "cur-bucket", "cur", "eu-west-1", true)
+	require.Equal(t, "Parquet", expected.Format)
+	require.Empty(t, expected.Diff(expected))
+
+	actual := ExpectedReportSettings("HOURLY", "cur-bucket", "cur", "eu-west-1", false)
+	require.Equal(t, []string{
+		`TimeUnit is "HOURLY", expected "DAILY"`,
+		`Format is "textORcsv", expected "Parquet"`,
+		`Compression is "GZIP", expected "Parquet"`,
+		`AdditionalArtifacts is "", expected "ATHENA"`,
+		`ReportVersioning is "CREATE_NEW_REPORT", expected "OVERWRITE_REPORT"`,
+	}, expected.Diff(actual))
+}
@@ -0,0 +1,340 @@
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hamstah/awstools/aws/dump/resources"
+	"github.com/hamstah/awstools/common"
+)
+
+const redacted = "REDACTED"
+
+// RedactionRule redacts the values of the metadata keys matching Key, at any
+// depth, for the resources of Service and Type, or all of them when empty.
+type RedactionRule struct {
+	Service string `json:"service"`
+	Type    string `json:"type"`
+	Key     string `json:"key"`
+
+	key *regexp.Regexp
+}
+
+// DefaultRedactionRules are used when no redaction rules file is given.
+var DefaultRedactionRules = []*RedactionRule{
+	{Key: "(?i)(password|secret|private_?key)"},
+}
+
+func NewRedactionRulesFromFile(filename string) ([]*RedactionRule, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	rules := []*RedactionRule{}
+	err = json.Unmarshal(data, &rules)
+	if err != nil {
+		return nil, err
+	}
+
+	return rules, nil
+}
+
+func (r *RedactionRule) compile() error {
+	if r.Key == "" {
+		return fmt.Errorf("redaction rule without key")
+	}
+
+	key, err := regexp.Compile(r.Key)
+	if err != nil {
+		return fmt.Errorf("invalid redaction rule key %s: %s", r.Key, err)
+	}
+	r.key = key
+	return nil
+}
+
+func (r *RedactionRule) appliesTo(service, resourceType string) bool {
+	return (r.Service == "" || r.Service == service) && (r.Type == "" || r.Type == resourceType)
+}
+
+// redact replaces in place the values of the keys matching the rule and
+// returns the number of values replaced.
+func (r *RedactionRule) redact(value interface{}) int {
+	count := 0
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		for key, item := range typed {
+			if r.key.MatchString(key) {
+				if item != nil && item != redacted {
+					typed[key] = redacted
+					count++
+				}
+				continue
+			}
+			count += r.redact(item)
+		}
+	case []interface{}:
+		for _, item := range typed {
+			count += r.redact(item)
+		}
+	}
+	return count
+}
+
+// Pseudonyms maps the account-internal identifiers to the pseudonyms used in
+// share bundles. Keeping the mapping between bundles gives the same pseudonyms
+// to the same identifiers.
+type Pseudonyms map[string]string
+
+// NewPseudonymsFromFile loads the mapping, a missing file is an empty mapping.
+func NewPseudonymsFromFile(filename string) (Pseudonyms, error) {
+	pseudonyms := Pseudonyms{}
+	data, err := ioutil.ReadFile(filename)
+	if os.IsNotExist(err) {
+		return pseudonyms, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	err = json.Unmarshal(data, &pseudonyms)
+	if err != nil {
+		return nil, err
+	}
+	return pseudonyms, nil
+}
+
+func (p Pseudonyms) Save(filename string) error {
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filename, data, 0600)
+}
+
+// AddAccounts gives a pseudonym to the accounts of the resources and of their
+// ARNs that are not in the mapping yet.
+func (p Pseudonyms) AddAccounts(resourceList []resources.Resource) {
+	used := map[string]bool{}
+	for _, pseudonym := range p {
+		used[pseudonym] = true
+	}
+
+	next := 1
+	add := func(accountID string) {
+		if accountID == "" {
+			return
+		}
+		if _, ok := p[accountID]; ok {
+			return
+		}
+		for used[fmt.Sprintf("account-%03d", next)] {
+			next++
+		}
+		p[accountID] = fmt.Sprintf("account-%03d", next)
+		used[p[accountID]] = true
+	}
+
+	for _, resource := range resourceList {
+		add(resource.AccountID)
+		// arn:partition:service:region:account-id:resource
+		parts := strings.SplitN(resource.ARN, ":", 6)
+		if len(parts) == 6 {
+			add(parts[4])
+		}
+	}
+}
+
+// replacer replaces the longest identifiers first so identifiers containing
+// other identifiers keep their own pseudonym.
+func (p Pseudonyms) replacer() *strings.Replacer {
+	identifiers := []string{}
+	for identifier := range p {
+		if identifier != "" {
+			identifiers = append(identifiers, identifier)
+		}
+	}
+	sort.Slice(identifiers, func(i, j int) bool {
+		if len(identifiers[i]) != len(identifiers[j]) {
+			return len(identifiers[i]) > len(identifiers[j])
+		}
+		return identifiers[i] < identifiers[j]
+	})
+
+	pairs := []string{}
+	for _, identifier := range identifiers {
+		pairs = append(pairs, identifier, p[identifier])
+	}
+	return strings.NewReplacer(pairs...)
+}
+
+type ShareBundleOptions struct {
+	RedactionRules []*RedactionRule
+	Pseudonyms     Pseudonyms
+	CreatedAt      time.Time
+}
+
+type BundleManifest struct {
+	CreatedAt                time.Time         `json:"created_at"`
+	Version                  string            `json:"version"`
+	Resources                int               `json:"resources"`
+	Types                    map[string]int    `json:"types"`
+	RedactedValues           int               `json:"redacted_values"`
+	PseudonymisedIdentifiers int               `json:"pseudonymised_identifiers"`
+	Files                    map[string]string `json:"files"`
+}
+
+type BundleSchema struct {
+	Fields map[string]string   `json:"fields"`
+	Types  map[string][]string `json:"types"`
+}
+
+var bundleSchemaFields = map[string]string{
+	"id":         "Identifier of the resource, pseudonymised",
+	"arn":        "ARN of the resource if it has one, pseudonymised",
+	"service":    "AWS service of the resource",
+	"type":       "Type of the resource in the service",
+	"account_id": "Pseudonym of the account of the resource",
+	"region":     "Region of the resource, empty for global resources",
+	"metadata":   "Attributes of the resource as returned by the AWS API, redacted and pseudonymised. Keys listed per type under types",
+	"managed_by": "Terraform state managing the resource, if any",
+}
+
+// WriteShareBundle writes a .tar.gz archive with the resources after redaction
+// and pseudonymisation, a schema of the resources and a manifest with the
+// checksums of the other files.
+func WriteShareBundle(writer io.Writer, resourceList []resources.Resource, options ShareBundleOptions) (*BundleManifest, error) {
+	for _, rule := range options.RedactionRules {
+		if err := rule.compile(); err != nil {
+			return nil, err
+		}
+	}
+
+	manifest := &BundleManifest{
+		CreatedAt: options.CreatedAt.UTC(),
+		Version:   common.Version,
+		Types:     map[string]int{},
+		Files:     map[string]string{},
+	}
+	schema := &BundleSchema{
+		Fields: bundleSchemaFields,
+		Types:  map[string][]string{},
+	}
+
+	replacer := options.Pseudonyms.replacer()
+	pseudonymised := map[string]bool{}
+	metadataKeys := map[string]map[string]bool{}
+
+	shared := []map[string]interface{}{}
+	for _, resource := range resourceList {
+		data, err := json.Marshal(resource)
+		if err != nil {
+			return nil, err
+		}
+		for identifier := range options.Pseudonyms {
+			if identifier != "" && bytes.Contains(data, []byte(identifier)) {
+				pseudonymised[identifier] = true
+			}
+		}
+
+		item := map[string]interface{}{}
+		err = json.Unmarshal([]byte(replacer.Replace(string(data))), &item)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, rule := range options.RedactionRules {
+			if rule.appliesTo(resource.Service, resource.Type) {
+				manifest.RedactedValues += rule.redact(item["metadata"])
+			}
+		}
+
+		resourceType := fmt.Sprintf("%s:%s", resource.Service, resource.Type)
+		manifest.Types[resourceType]++
+		if metadataKeys[resourceType] == nil {
+			metadataKeys[resourceType] = map[string]bool{}
+		}
+		if metadata, ok := item["metadata"].(map[string]interface{}); ok {
+			for key := range metadata {
+				metadataKeys[resourceType][key] = true
+			}
+		}
+
+		shared = append(shared, item)
+	}
+	manifest.Resources = len(shared)
+	manifest.PseudonymisedIdentifiers = len(pseudonymised)
+
+	for resourceType, keys := range metadataKeys {
+		schema.Types[resourceType] = []string{}
+		for key := range keys {
+			schema.Types[resourceType] = append(schema.Types[resourceType], key)
+		}
+		sort.Strings(schema.Types[resourceType])
+	}
+
+	files := []struct {
+		name  string
+		value interface{}
+	}{
+		{"resources.json", shared},
+		{"schema.json", schema},
+	}
+
+	gzipWriter := gzip.NewWriter(writer)
+	tarWriter := tar.NewWriter(gzipWriter)
+	for _, file := range files {
+		data, err := json.MarshalIndent(file.value, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		checksum := sha256.Sum256(data)
+		manifest.Files[file.name] = hex.EncodeToString(checksum[:])
+
+		err = writeTarFile(tarWriter, file.name, data, manifest.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	err = writeTarFile(tarWriter, "manifest.json", data, manifest.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tarWriter.Close(); err != nil {
+		return nil, err
+	}
+	return manifest, gzipWriter.Close()
+}
+
+func writeTarFile(tarWriter *tar.Writer, name string, data []byte, modTime time.Time) error {
+	err := tarWriter.WriteHeader(&tar.Header{
+		Name:    name,
+		Mode:    0644,
+		Size:    int64(len(data)),
+		ModTime: modTime,
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = tarWriter.Write(data)
+	return err
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/hamstah/awstools/aws/dump/resources"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPseudonymsAddAccounts(t *testing.T) {
+	pseudonyms := Pseudonyms{"111111111111": "account-001", "my-bucket": "bucket-a"}
+	pseudonyms.AddAccounts([]resources.Resource{
+		{AccountID: "111111111111", ARN: "arn:aws:iam::222222222222:role/audit"},
+		{AccountID: "333333333333", ARN: "arn:aws:s3:::my-bucket"},
+	})
+
+	require.Equal(t, Pseudonyms{
+		"111111111111": "account-001",
+		"222222222222": "account-002",
+		"333333333333": "account-003",
+		"my-bucket":    "bucket-a",
+	}, pseudonyms)
+}
+
+func TestWriteShareBundle(t *testing.T) {
+	password := "hunter2"
+	resourceList := []resources.Resource{
+		{
+			ID:        "db-1",
+			ARN:       "arn:aws:rds:eu-west-1:111111111111:db:db-1",
+			AccountID: "111111111111",
+			Region:    "eu-west-1",
+			Service:   "rds",
+			Type:      "db-instance",
+			Metadata: map[string]interface{}{
+				"MasterUserPassword": &password,
+				"Endpoint":           map[string]interface{}{"Address": "db-1.internal.example.com"},
+			},
+		},
+		{
+			ID:        "app",
+			AccountID: "111111111111",
+			Service:   "lambda",
+			Type:      "function",
+			Metadata: map[string]interface{}{
+				"Environment": map[string]interface{}{"API_TOKEN": "abc", "DB_PASSWORD": "hunter2"},
+			},
+		},
+	}
+
+	rules := []*RedactionRule{
+		{Key: "(?i)password"},
+		{Service: "lambda", Type: "function", Key: "^API_TOKEN$"},
+		{Service: "rds", Key: "^Address$"},
+	}
+	pseudonyms := Pseudonyms{"111111111111": "account-001", "internal.example.com": "domain-a", "unused": "x"}
+
+	buffer := &bytes.Buffer{}
+	createdAt := time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)
+	manifest, err := WriteShareBundle(buffer, resourceList, ShareBundleOptions{
+		RedactionRules: rules,
+		Pseudonyms:     pseudonyms,
+		CreatedAt:      createdAt,
+	})
+	require.NoError(t, err)
+	require.Equal(t, 2, manifest.Resources)
+	require.Equal(t, 4, manifest.RedactedValues)
+	require.Equal(t, 2, manifest.PseudonymisedIdentifiers)
+	require.Equal(t, map[string]int{"rds:db-instance": 1, "lambda:function": 1}, manifest.Types)
+
+	gzipReader, err := gzip.NewReader(buffer)
+	require.NoError(t, err)
+	tarReader := tar.NewReader(gzipReader)
+	files := map[string][]byte{}
+	names := []string{}
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		data, err := ioutil.ReadAll(tarReader)
+		require.NoError(t, err)
+		files[header.Name] = data
+		names = append(names, header.Name)
+	}
+	require.Equal(t, []string{"resources.json", "schema.json", "manifest.json"}, names)
+
+	shared := []map[string]interface{}{}
+	require.NoError(t, json.Unmarshal(files["resources.json"], &shared))
+	require.Equal(t, "arn:aws:rds:eu-west-1:account-001:db:db-1", shared[0]["arn"])
+	require.Equal(t, "account-001", shared[0]["account_id"])
+	require.Equal(t, map[string]interface{}{
+		"MasterUserPassword": "REDACTED",
+		"Endpoint":           map[string]interface{}{"Address": "REDACTED"},
+	}, shared[0]["metadata"])
+	require.Equal(t, map[string]interface{}{
+		"Environment": map[string]interface{}{"API_TOKEN": "REDACTED", "DB_PASSWORD": "REDACTED"},
+	}, shared[1]["metadata"])
+
+	schema := &BundleSchema{}
+	require.NoError(t, json.Unmarshal(files["schema.json"], schema))
+	require.Equal(t, []string{"Endpoint", "MasterUserPassword"}, schema.Types["rds:db-instance"])
+
+	written := &BundleManifest{}
+	require.NoError(t, json.Unmarshal(files["manifest.json"], written))
+	require.Equal(t, createdAt, written.CreatedAt)
+	require.Len(t, written.Files["resources.json"], 64)
+	require.Len(t, written.Files["schema.json"], 64)
+}
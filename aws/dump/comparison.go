@@ -0,0 +1,106 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+
+	"github.com/hamstah/awstools/aws/dump/resources"
+)
+
+// ComparisonRule matches the resources of Type, service:type with * wildcards
+// (e.g. ec2:*), whose metadata has the Metadata values. The keys of Metadata
+// are nested keys separated by dots, like the csv columns.
+type ComparisonRule struct {
+	Type     string            `json:"type"`
+	Metadata map[string]string `json:"metadata"`
+}
+
+func (r *ComparisonRule) Verify() error {
+	if r.Type == "" {
+		return fmt.Errorf("comparison rule without type")
+	}
+	if _, err := path.Match(r.Type, ""); err != nil {
+		return fmt.Errorf("invalid comparison rule type %s: %s", r.Type, err)
+	}
+	return nil
+}
+
+func (r *ComparisonRule) Matches(resource resources.Resource, metadata interface{}) bool {
+	matched, _ := path.Match(r.Type, fmt.Sprintf("%s:%s", resource.Service, resource.Type))
+	if !matched {
+		return false
+	}
+
+	for key, expected := range r.Metadata {
+		value, err := metadataValue(metadata, key)
+		if err != nil || value != expected {
+			return false
+		}
+	}
+	return true
+}
+
+// ComparisonFilter selects the resources compared with the terraform states.
+// The resources that are not compared are never reported as unmanaged, e.g.
+// types that are never managed with terraform like IAM access keys.
+type ComparisonFilter struct {
+	// Include, when not empty, restricts the comparison to these resources
+	Include []*ComparisonRule `json:"include"`
+	Exclude []*ComparisonRule `json:"exclude"`
+}
+
+func (f *ComparisonFilter) Verify() error {
+	for _, rule := range append(append([]*ComparisonRule{}, f.Include...), f.Exclude...) {
+		if err := rule.Verify(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func hasMetadataRules(rules []*ComparisonRule) bool {
+	for _, rule := range rules {
+		if len(rule.Metadata) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Compared is false for the resources excluded or not included.
+func (f *ComparisonFilter) Compared(resource resources.Resource) bool {
+	if f == nil || (len(f.Include) == 0 && len(f.Exclude) == 0) {
+		return true
+	}
+
+	// go through JSON to handle the pointers and structs of the metadata, only
+	// when a rule needs it
+	var metadata interface{}
+	if hasMetadataRules(f.Include) || hasMetadataRules(f.Exclude) {
+		data, err := json.Marshal(resource.Metadata)
+		if err == nil {
+			json.Unmarshal(data, &metadata)
+		}
+	}
+
+	if len(f.Include) > 0 {
+		included := false
+		for _, rule := range f.Include {
+			if rule.Matches(resource, metadata) {
+				included = true
+				break
+			}
+		}
+		if !included {
+			return false
+		}
+	}
+
+	for _, rule := range f.Exclude {
+		if rule.Matches(resource, metadata) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/hamstah/awstools/aws/dump/resources"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComparisonFilter(t *testing.T) {
+	requesterManaged := true
+	accessKey := resources.Resource{Service: "iam", Type: "access-key"}
+	role := resources.Resource{Service: "iam", Type: "role"}
+	instance := resources.Resource{Service: "ec2", Type: "instance"}
+	autoCreated := resources.Resource{
+		Service:  "ec2",
+		Type:     "network-interface",
+		Metadata: map[string]interface{}{"RequesterManaged": &requesterManaged},
+	}
+	created := resources.Resource{
+		Service:  "ec2",
+		Type:     "network-interface",
+		Metadata: map[string]interface{}{"RequesterManaged": false},
+	}
+
+	var filter *ComparisonFilter
+	require.True(t, filter.Compared(accessKey))
+
+	filter = &ComparisonFilter{
+		Exclude: []*ComparisonRule{
+			{Type: "iam:access-key"},
+			{Type: "ec2:network-interface", Metadata: map[string]string{"RequesterManaged": "true"}},
+		},
+	}
+	require.NoError(t, filter.Verify())
+	require.False(t, filter.Compared(accessKey))
+	require.True(t, filter.Compared(role))
+	require.False(t, filter.Compared(autoCreated))
+	require.True(t, filter.Compared(created))
+
+	filter.Include = []*ComparisonRule{{Type: "ec2:*"}}
+	require.False(t, filter.Compared(role))
+	require.True(t, filter.Compared(instance))
+	require.False(t, filter.Compared(autoCreated))
+
+	require.Error(t, (&ComparisonFilter{Exclude: []*ComparisonRule{{Type: "ec2:["}}}).Verify())
+	require.Error(t, (&ComparisonFilter{Include: []*ComparisonRule{{}}}).Verify())
+}
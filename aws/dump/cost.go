@@ -0,0 +1,121 @@
+package main
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hamstah/awstools/aws/dump/resources"
+)
+
+const (
+	// price of a GB-second of lambda on x86
+	lambdaGBSecondPrice = 0.0000166667
+)
+
+// APIPrices are the us-east-1 prices in USD of a unit of the billable API
+// calls of the dump, by service:Operation or by service for all its
+// operations. The units are counted by resources.CallCounter, the other calls
+// are free.
+var APIPrices = map[string]float64{
+	// per metric requested
+	"cloudwatch:GetMetricData":       0.01 / 1000,
+	"cloudwatch:GetMetricStatistics": 0.01 / 1000,
+	"cloudwatch:ListMetrics":         0.01 / 1000,
+	"kms":                            0.03 / 10000,
+	"sqs":                            0.40 / 1000000,
+	// GET and other requests, LIST and PUT requests below
+	"s3":                         0.0004 / 1000,
+	"s3:ListBuckets":             0.005 / 1000,
+	"s3:ListObjects":             0.005 / 1000,
+	"s3:ListObjectsV2":           0.005 / 1000,
+	"s3:PutObject":               0.005 / 1000,
+	"s3:CreateMultipartUpload":   0.005 / 1000,
+	"s3:UploadPart":              0.005 / 1000,
+	"s3:CompleteMultipartUpload": 0.005 / 1000,
+	// per request, the Price List API is free
+	"costexplorer:GetCostAndUsage": 0.01,
+	// per item written to an on-demand table
+	"dynamodb:BatchWriteItem": 1.25 / 1000000,
+	"dynamodb:PutItem":        1.25 / 1000000,
+}
+
+type CostItem struct {
+	Service   string  `json:"service"`
+	Operation string  `json:"operation"`
+	Units     int64   `json:"units"`
+	UnitPrice float64 `json:"unit_price"`
+	Cost      float64 `json:"cost"`
+}
+
+// CostEstimate is the estimated cost in USD of a dump run.
+type CostEstimate struct {
+	Calls int64      `json:"calls"`
+	Items []CostItem `json:"items"`
+	Total float64    `json:"total"`
+}
+
+// EstimateCost returns the cost of the units of each service:Operation with
+// the prices, sorted by decreasing cost.
+func EstimateCost(calls int64, units map[string]int64, prices map[string]float64) *CostEstimate {
+	estimate := &CostEstimate{Calls: calls, Items: []CostItem{}}
+	for operation, count := range units {
+		parts := strings.SplitN(operation, ":", 2)
+		price, ok := prices[operation]
+		if !ok {
+			price, ok = prices[parts[0]]
+		}
+		if !ok || count == 0 {
+			continue
+		}
+
+		item := CostItem{
+			Service:   parts[0],
+			Units:     count,
+			UnitPrice: price,
+			Cost:      float64(count) * price,
+		}
+		if len(parts) == 2 {
+			item.Operation = parts[1]
+		}
+		estimate.add(item)
+	}
+	return estimate
+}
+
+// AddLambda adds the duration of a lambda invocation with the memory in MB.
+func (e *CostEstimate) AddLambda(duration time.Duration, memoryMB int) {
+	gbSeconds := duration.Seconds() * float64(memoryMB) / 1024
+	e.add(CostItem{
+		Service:   "lambda",
+		Operation: "Duration",
+		Units:     int64(duration / time.Millisecond),
+		UnitPrice: lambdaGBSecondPrice * float64(memoryMB) / 1024 / 1000,
+		Cost:      gbSeconds * lambdaGBSecondPrice,
+	})
+}
+
+func (e *CostEstimate) add(item CostItem) {
+	e.Items = append(e.Items, item)
+	e.Total += item.Cost
+	sort.SliceStable(e.Items, func(i, j int) bool {
+		if e.Items[i].Cost != e.Items[j].Cost {
+			return e.Items[i].Cost > e.Items[j].Cost
+		}
+		return e.Items[i].Service+e.Items[i].Operation < e.Items[j].Service+e.Items[j].Operation
+	})
+}
+
+// Resource returns the marker added to the output for the estimate.
+func (e *CostEstimate) Resource() resources.Resource {
+	return resources.Resource{
+		ID:      "cost-estimate",
+		Service: "aws-dump",
+		Type:    "cost-estimate",
+		Metadata: map[string]interface{}{
+			"Calls": e.Calls,
+			"Items": e.Items,
+			"Total": e.Total,
+		},
+	}
+}
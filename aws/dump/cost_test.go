@@ -0,0 +1,37 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEstimateCost(t *testing.T) {
+	estimate := EstimateCost(4100, map[string]int64{
+		"cloudwatch:GetMetricData":  20000,
+		"s3:ListBuckets":            2,
+		"s3:GetBucketPolicy":        1000,
+		"ec2:DescribeInstances":     3000,
+		"dynamodb:DescribeTable":    50,
+		"dynamodb:BatchWriteItem":   0,
+		"kms:DescribeKey":           40,
+		"sts:GetCallerIdentity":     8,
+		"cloudtrail:LookupEvents":   4,
+		"resourcegroupstaggingapi:": 1,
+	}, APIPrices)
+
+	require.Equal(t, int64(4100), estimate.Calls)
+	require.Len(t, estimate.Items, 4)
+	require.Equal(t, "cloudwatch", estimate.Items[0].Service)
+	require.Equal(t, "GetMetricData", estimate.Items[0].Operation)
+	require.InDelta(t, 0.2, estimate.Items[0].Cost, 1e-9)
+	require.Equal(t, "s3", estimate.Items[1].Service)
+	require.Equal(t, "GetBucketPolicy", estimate.Items[1].Operation)
+	require.InDelta(t, 0.0004, estimate.Items[1].Cost, 1e-9)
+	require.InDelta(t, 0.2+0.0004+0.00001+0.00012, estimate.Total, 1e-9)
+
+	estimate.AddLambda(2*time.Minute, 1024)
+	require.Equal(t, "lambda", estimate.Items[1].Service)
+	require.InDelta(t, 120*lambdaGBSecondPrice, estimate.Items[1].Cost, 1e-9)
+}
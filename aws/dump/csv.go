@@ -0,0 +1,94 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/hamstah/awstools/aws/dump/resources"
+)
+
+var csvHeader = []string{"id", "arn", "account_id", "region", "service", "type", "managed_by", "state"}
+
+// metadataValue returns the value at path (keys separated by dots, with
+// indexes for lists, e.g. Tags.0.Value) of the metadata as a string. Values
+// that are not strings or numbers are JSON encoded.
+func metadataValue(metadata interface{}, path string) (string, error) {
+	value := metadata
+	for _, key := range strings.Split(path, ".") {
+		switch typed := value.(type) {
+		case map[string]interface{}:
+			value = typed[key]
+		case []interface{}:
+			index, err := strconv.Atoi(key)
+			if err != nil || index < 0 || index >= len(typed) {
+				return "", nil
+			}
+			value = typed[index]
+		default:
+			return "", nil
+		}
+	}
+
+	switch typed := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return typed, nil
+	case float64, bool:
+		return fmt.Sprint(typed), nil
+	}
+
+	data, err := json.Marshal(value)
+	return string(data), err
+}
+
+// WriteCSV writes one row per resource with the metadata columns after the
+// common ones.
+func WriteCSV(writer io.Writer, resourceList []resources.Resource, metadataColumns []string) error {
+	csvWriter := csv.NewWriter(writer)
+	err := csvWriter.Write(append(append([]string{}, csvHeader...), metadataColumns...))
+	if err != nil {
+		return err
+	}
+
+	for _, resource := range resourceList {
+		// go through JSON to handle the pointers and structs of the metadata
+		data, err := json.Marshal(resource.Metadata)
+		if err != nil {
+			return err
+		}
+		var metadata interface{}
+		if err := json.Unmarshal(data, &metadata); err != nil {
+			return err
+		}
+
+		row := []string{
+			resource.ID,
+			resource.ARN,
+			resource.AccountID,
+			resource.Region,
+			resource.Service,
+			resource.Type,
+			resource.ManagedBy["type"],
+			resource.ManagedBy["state"],
+		}
+		for _, column := range metadataColumns {
+			value, err := metadataValue(metadata, column)
+			if err != nil {
+				return err
+			}
+			row = append(row, value)
+		}
+
+		if err := csvWriter.Write(row); err != nil {
+			return err
+		}
+	}
+
+	csvWriter.Flush()
+	return csvWriter.Error()
+}
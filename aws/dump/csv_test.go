@@ -0,0 +1,42 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/hamstah/awstools/aws/dump/resources"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteCSV(t *testing.T) {
+	name := "web"
+	resourceList := []resources.Resource{
+		{
+			ID:        "i-0123456789abcdef0",
+			ARN:       "arn:aws:ec2:eu-west-1:123456789012:instance/i-0123456789abcdef0",
+			AccountID: "123456789012",
+			Region:    "eu-west-1",
+			Service:   "ec2",
+			Type:      "instance",
+			Metadata: map[string]interface{}{
+				"InstanceType": &name,
+				"State":        map[string]interface{}{"Name": "running", "Code": 16},
+				"Tags":         []map[string]string{{"Key": "Name", "Value": "web, frontend"}},
+			},
+			ManagedBy: map[string]string{"type": "terraform", "state": "arn:aws:s3:::states/web.tfstate"},
+		},
+		{
+			ID:      "bucket",
+			Service: "s3",
+			Type:    "bucket",
+		},
+	}
+
+	buffer := &bytes.Buffer{}
+	err := WriteCSV(buffer, resourceList, []string{"State.Name", "State.Code", "Tags.0.Value", "InstanceType", "Tags", "Missing.Key"})
+	require.NoError(t, err)
+	require.Equal(t, `id,arn,account_id,region,service,type,managed_by,state,State.Name,State.Code,Tags.0.Value,InstanceType,Tags,Missing.Key
+i-0123456789abcdef0,arn:aws:ec2:eu-west-1:123456789012:instance/i-0123456789abcdef0,123456789012,eu-west-1,ec2,instance,terraform,arn:aws:s3:::states/web.tfstate,running,16,"web, frontend",web,"[{""Key"":""Name"",""Value"":""web, frontend""}]",
+bucket,,,,s3,bucket,,,,,,,,
+`, buffer.String())
+}
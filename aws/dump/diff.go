@@ -0,0 +1,262 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hamstah/awstools/aws/dump/resources"
+)
+
+// FieldChange is a change of a metadata or managed_by field, Path has the
+// nested keys separated by dots like the csv columns. Old or New is nil when
+// the field was added or removed.
+type FieldChange struct {
+	Path string      `json:"path"`
+	Old  interface{} `json:"old"`
+	New  interface{} `json:"new"`
+}
+
+type ResourceChange struct {
+	Key     string        `json:"key"`
+	Changes []FieldChange `json:"changes"`
+}
+
+// TypeDiff has the keys of the resources of a type added, removed and
+// changed between two dumps.
+type TypeDiff struct {
+	Service string           `json:"service"`
+	Type    string           `json:"type"`
+	Added   []string         `json:"added"`
+	Removed []string         `json:"removed"`
+	Changed []ResourceChange `json:"changed"`
+}
+
+// LoadDump reads a dump written with the json, with or without
+// --output-schema, or jsonl output format.
+func LoadDump(filename string) ([]resources.Resource, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	result := []resources.Resource{}
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '[' {
+		err = json.Unmarshal(trimmed, &result)
+		return result, err
+	}
+
+	// the json output format with --output-schema, the jsonl output format is
+	// not a single JSON value
+	envelope := &VersionedOutput{}
+	if json.Unmarshal(data, envelope) == nil && envelope.SchemaVersion > 0 {
+		if envelope.SchemaVersion > OutputSchemaVersion {
+			return nil, fmt.Errorf("unsupported schema version %d in %s", envelope.SchemaVersion, filename)
+		}
+		return envelope.Resources, nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	for {
+		resource := resources.Resource{}
+		err := decoder.Decode(&resource)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid resource in %s: %s", filename, err)
+		}
+		result = append(result, resource)
+	}
+	return result, nil
+}
+
+// normalise goes through JSON so the values of the dumps and of the resources
+// in memory compare the same way.
+func normalise(value interface{}) interface{} {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return value
+	}
+	var result interface{}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return value
+	}
+	return result
+}
+
+func ignored(fieldPath string, ignoredFields []string) bool {
+	for _, pattern := range ignoredFields {
+		if matched, _ := path.Match(pattern, fieldPath); matched {
+			return true
+		}
+	}
+	return false
+}
+
+func diffValues(fieldPath string, before, after interface{}, ignoredFields []string, changes []FieldChange) []FieldChange {
+	if ignored(fieldPath, ignoredFields) {
+		return changes
+	}
+
+	oldMap, oldIsMap := before.(map[string]interface{})
+	newMap, newIsMap := after.(map[string]interface{})
+	if oldIsMap && newIsMap {
+		keys := []string{}
+		for key := range oldMap {
+			keys = append(keys, key)
+		}
+		for key := range newMap {
+			if _, ok := oldMap[key]; !ok {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			changes = diffValues(fieldPath+"."+key, oldMap[key], newMap[key], ignoredFields, changes)
+		}
+		return changes
+	}
+
+	oldList, oldIsList := before.([]interface{})
+	newList, newIsList := after.([]interface{})
+	if oldIsList && newIsList {
+		length := len(oldList)
+		if len(newList) > length {
+			length = len(newList)
+		}
+		for index := 0; index < length; index++ {
+			var oldItem, newItem interface{}
+			if index < len(oldList) {
+				oldItem = oldList[index]
+			}
+			if index < len(newList) {
+				newItem = newList[index]
+			}
+			changes = diffValues(fieldPath+"."+strconv.Itoa(index), oldItem, newItem, ignoredFields, changes)
+		}
+		return changes
+	}
+
+	if !reflect.DeepEqual(before, after) {
+		changes = append(changes, FieldChange{Path: fieldPath, Old: before, New: after})
+	}
+	return changes
+}
+
+// DiffResources compares the metadata and managed_by of the resources of two
+// dumps, matched by their key, and groups the differences by type. The fields
+// matching ignoredFields (e.g. metadata.LastModified or metadata.Tags.*) are
+// not compared.
+func DiffResources(oldResources, newResources []resources.Resource, ignoredFields []string) []*TypeDiff {
+	diffs := map[string]*TypeDiff{}
+	typeDiff := func(resource resources.Resource) *TypeDiff {
+		name := resource.Service + ":" + resource.Type
+		if diffs[name] == nil {
+			diffs[name] = &TypeDiff{
+				Service: resource.Service,
+				Type:    resource.Type,
+				Added:   []string{},
+				Removed: []string{},
+				Changed: []ResourceChange{},
+			}
+		}
+		return diffs[name]
+	}
+
+	old := map[string]resources.Resource{}
+	for _, resource := range oldResources {
+		old[ResourceKey(resource)] = resource
+	}
+
+	seen := map[string]bool{}
+	for _, resource := range newResources {
+		key := ResourceKey(resource)
+		seen[key] = true
+
+		previous, ok := old[key]
+		if !ok {
+			diff := typeDiff(resource)
+			diff.Added = append(diff.Added, key)
+			continue
+		}
+
+		changes := diffValues("metadata", normalise(previous.Metadata), normalise(resource.Metadata), ignoredFields, []FieldChange{})
+		changes = diffValues("managed_by", normalise(previous.ManagedBy), normalise(resource.ManagedBy), ignoredFields, changes)
+		if len(changes) > 0 {
+			diff := typeDiff(resource)
+			diff.Changed = append(diff.Changed, ResourceChange{Key: key, Changes: changes})
+		}
+	}
+
+	for _, resource := range oldResources {
+		key := ResourceKey(resource)
+		if !seen[key] {
+			seen[key] = true
+			diff := typeDiff(resource)
+			diff.Removed = append(diff.Removed, key)
+		}
+	}
+
+	result := []*TypeDiff{}
+	for _, diff := range diffs {
+		sort.Strings(diff.Added)
+		sort.Strings(diff.Removed)
+		sort.Slice(diff.Changed, func(i, j int) bool { return diff.Changed[i].Key < diff.Changed[j].Key })
+		result = append(result, diff)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Service != result[j].Service {
+			return result[i].Service < result[j].Service
+		}
+		return result[i].Type < result[j].Type
+	})
+	return result
+}
+
+func formatValue(value interface{}) string {
+	if value == nil {
+		return "(none)"
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(data)
+}
+
+// WriteDiff writes the differences grouped by type, + for the resources
+// added, - for the resources removed and ~ for the resources changed followed
+// by their field changes.
+func WriteDiff(writer io.Writer, diffs []*TypeDiff) error {
+	lines := []string{}
+	for _, diff := range diffs {
+		lines = append(lines, fmt.Sprintf("%s:%s +%d -%d ~%d", diff.Service, diff.Type, len(diff.Added), len(diff.Removed), len(diff.Changed)))
+		for _, key := range diff.Added {
+			lines = append(lines, "  + "+key)
+		}
+		for _, key := range diff.Removed {
+			lines = append(lines, "  - "+key)
+		}
+		for _, change := range diff.Changed {
+			lines = append(lines, "  ~ "+change.Key)
+			for _, field := range change.Changes {
+				lines = append(lines, fmt.Sprintf("      %s: %s -> %s", field.Path, formatValue(field.Old), formatValue(field.New)))
+			}
+		}
+	}
+
+	if len(lines) == 0 {
+		return nil
+	}
+	_, err := io.WriteString(writer, strings.Join(lines, "\n")+"\n")
+	return err
+}
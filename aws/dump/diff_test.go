@@ -0,0 +1,94 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hamstah/awstools/aws/dump/resources"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadDump(t *testing.T) {
+	dir, err := ioutil.TempDir("", "aws-dump-diff")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	jsonFilename := filepath.Join(dir, "dump.json")
+	err = ioutil.WriteFile(jsonFilename, []byte(`[{"id": "a", "service": "s3", "type": "bucket"}]`), 0644)
+	require.NoError(t, err)
+	jsonlFilename := filepath.Join(dir, "dump.jsonl")
+	err = ioutil.WriteFile(jsonlFilename, []byte("{\"id\": \"a\"}\n{\"id\": \"b\"}\n"), 0644)
+	require.NoError(t, err)
+
+	loaded, err := LoadDump(jsonFilename)
+	require.NoError(t, err)
+	require.Len(t, loaded, 1)
+	require.Equal(t, "bucket", loaded[0].Type)
+
+	loaded, err = LoadDump(jsonlFilename)
+	require.NoError(t, err)
+	require.Len(t, loaded, 2)
+	require.Equal(t, "b", loaded[1].ID)
+
+	versionedFilename := filepath.Join(dir, "dump-v1.json")
+	err = ioutil.WriteFile(versionedFilename, []byte(`{"schema_version": 1, "run": {}, "resources": [{"id": "a"}]}`), 0644)
+	require.NoError(t, err)
+	loaded, err = LoadDump(versionedFilename)
+	require.NoError(t, err)
+	require.Len(t, loaded, 1)
+
+	err = ioutil.WriteFile(versionedFilename, []byte(`{"schema_version": 2, "resources": []}`), 0644)
+	require.NoError(t, err)
+	_, err = LoadDump(versionedFilename)
+	require.Error(t, err)
+}
+
+func TestDiffResources(t *testing.T) {
+	oldResources := []resources.Resource{
+		{ARN: "arn:aws:s3:::removed", Service: "s3", Type: "bucket"},
+		{ARN: "arn:aws:s3:::changed", Service: "s3", Type: "bucket", Metadata: map[string]interface{}{
+			"Versioning":   "Suspended",
+			"Tags":         map[string]interface{}{"team": "a"},
+			"LastModified": "2021-01-01",
+		}},
+		{ARN: "arn:aws:s3:::same", Service: "s3", Type: "bucket", Metadata: map[string]interface{}{"Versioning": "Enabled"}},
+	}
+	newResources := []resources.Resource{
+		{ARN: "arn:aws:s3:::changed", Service: "s3", Type: "bucket", Metadata: map[string]interface{}{
+			"Versioning":   "Enabled",
+			"Tags":         map[string]interface{}{"team": "a", "env": "prod"},
+			"LastModified": "2021-02-01",
+		}},
+		{ARN: "arn:aws:s3:::same", Service: "s3", Type: "bucket", Metadata: map[string]interface{}{"Versioning": "Enabled"}},
+		{ARN: "arn:aws:ec2:eu-west-1:123456789012:instance/i-1", Service: "ec2", Type: "instance"},
+	}
+
+	diffs := DiffResources(oldResources, newResources, []string{"metadata.LastModified"})
+	require.Len(t, diffs, 2)
+
+	require.Equal(t, "ec2", diffs[0].Service)
+	require.Equal(t, []string{"arn:aws:ec2:eu-west-1:123456789012:instance/i-1"}, diffs[0].Added)
+
+	require.Equal(t, []string{"arn:aws:s3:::removed"}, diffs[1].Removed)
+	require.Equal(t, []ResourceChange{{
+		Key: "arn:aws:s3:::changed",
+		Changes: []FieldChange{
+			{Path: "metadata.Tags.env", Old: nil, New: "prod"},
+			{Path: "metadata.Versioning", Old: "Suspended", New: "Enabled"},
+		},
+	}}, diffs[1].Changed)
+
+	buffer := &bytes.Buffer{}
+	require.NoError(t, WriteDiff(buffer, diffs))
+	require.Equal(t, `ec2:instance +1 -0 ~0
+  + arn:aws:ec2:eu-west-1:123456789012:instance/i-1
+s3:bucket +0 -1 ~1
+  - arn:aws:s3:::removed
+  ~ arn:aws:s3:::changed
+      metadata.Tags.env: (none) -> "prod"
+      metadata.Versioning: "Suspended" -> "Enabled"
+`, buffer.String())
+}
@@ -0,0 +1,216 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/hamstah/awstools/aws/dump/resources"
+)
+
+// dotNode is a resource, or a resource referenced by a relationship but not
+// part of the dump.
+type dotNode struct {
+	ID       string
+	Label    string
+	External bool
+}
+
+type dotEdge struct {
+	From     string
+	To       string
+	Relation string
+}
+
+// dotGraph groups the nodes by account and VPC, the nodes outside of a VPC
+// have an empty VPC and the external ones an empty account and VPC.
+type dotGraph struct {
+	Nodes map[string]map[string][]*dotNode
+	Edges []dotEdge
+}
+
+type resourceRef struct{ AccountID, Type, Value string }
+
+// resourceIndex finds the resources referenced by the relationships by type
+// and id or ARN, in the same account first.
+type resourceIndex map[resourceRef]string
+
+// Add indexes the resource by id and ARN, value identifies it in the graph.
+func (i resourceIndex) Add(resource resources.Resource, value string) {
+	resourceType := fmt.Sprintf("%s:%s", resource.Service, resource.Type)
+	for _, ref := range []string{resource.ID, resource.ARN} {
+		if ref == "" {
+			continue
+		}
+		i[resourceRef{resource.AccountID, resourceType, ref}] = value
+		if _, ok := i[resourceRef{"", resourceType, ref}]; !ok {
+			i[resourceRef{"", resourceType, ref}] = value
+		}
+	}
+}
+
+func (i resourceIndex) Find(accountID, resourceType, ref string) (string, bool) {
+	if value, ok := i[resourceRef{accountID, resourceType, ref}]; ok {
+		return value, true
+	}
+	value, ok := i[resourceRef{"", resourceType, ref}]
+	return value, ok
+}
+
+func dotQuote(value string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value) + `"`
+}
+
+func dotLabel(resourceType, id string, tags map[string]string) string {
+	if name := tags["Name"]; name != "" {
+		return fmt.Sprintf("%s\n%s\n%s", resourceType, name, id)
+	}
+	return fmt.Sprintf("%s\n%s", resourceType, id)
+}
+
+// newDOTGraph returns the graph of the resources and of the relationships
+// derived from their metadata. The relationships are matched to the resources
+// of the same type by id or ARN, in the same account first.
+func newDOTGraph(resourceList []resources.Resource) *dotGraph {
+	graph := &dotGraph{Nodes: map[string]map[string][]*dotNode{}}
+
+	index := resourceIndex{}
+	nodeResources := []resources.Resource{}
+	for _, resource := range resourceList {
+		// markers of the dump and relationships added with --relationships
+		if resource.Service == "aws-dump" {
+			continue
+		}
+		index.Add(resource, resource.UniqueID())
+		nodeResources = append(nodeResources, resource)
+	}
+
+	addNode := func(accountID, vpc string, node *dotNode) {
+		if graph.Nodes[accountID] == nil {
+			graph.Nodes[accountID] = map[string][]*dotNode{}
+		}
+		graph.Nodes[accountID][vpc] = append(graph.Nodes[accountID][vpc], node)
+	}
+
+	external := map[string]bool{}
+	seen := map[string]bool{}
+	for _, resource := range nodeResources {
+		id := resource.UniqueID()
+		relationships := resources.Relationships(resource)
+
+		vpc := ""
+		if resource.Service == "ec2" && resource.Type == "vpc" {
+			vpc = resource.ID
+		}
+		for _, relationship := range relationships {
+			if relationship.Relation == resources.RelationIn && relationship.ToType == "ec2:vpc" {
+				vpc = relationship.To
+			}
+		}
+
+		if !seen[id] {
+			seen[id] = true
+			addNode(resource.AccountID, vpc, &dotNode{
+				ID:    id,
+				Label: dotLabel(fmt.Sprintf("%s:%s", resource.Service, resource.Type), resource.ID, ResourceTags(resource)),
+			})
+		}
+
+		for _, relationship := range relationships {
+			from, ok := index.Find(resource.AccountID, relationship.FromType, relationship.From)
+			if !ok {
+				from = relationship.From
+			}
+			to, ok := index.Find(resource.AccountID, relationship.ToType, relationship.To)
+			if !ok {
+				to = relationship.To
+				if !external[to] {
+					external[to] = true
+					addNode("", "", &dotNode{ID: to, Label: dotLabel(relationship.ToType, to, nil), External: true})
+				}
+			}
+			if !seen[from] && !external[from] {
+				external[from] = true
+				addNode("", "", &dotNode{ID: from, Label: dotLabel(relationship.FromType, from, nil), External: true})
+			}
+			graph.Edges = append(graph.Edges, dotEdge{from, to, relationship.Relation})
+		}
+	}
+	return graph
+}
+
+func sortedMapKeys(values map[string][]*dotNode) []string {
+	keys := []string{}
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// WriteDOT writes the resources as the nodes of a Graphviz graph, grouped by
+// account and VPC, with the relationships derived from their metadata as
+// edges. The resources referenced but not part of the dump are dashed.
+func WriteDOT(writer io.Writer, resourceList []resources.Resource) error {
+	graph := newDOTGraph(resourceList)
+
+	lines := []string{
+		"digraph aws {",
+		"  rankdir=LR;",
+		"  node [shape=box, fontsize=10];",
+		"  edge [fontsize=8];",
+	}
+
+	writeNodes := func(indent string, nodes []*dotNode) {
+		sort.Slice(nodes, func(i, j int) bool { return nodes[i].ID < nodes[j].ID })
+		for _, node := range nodes {
+			style := ""
+			if node.External {
+				style = ", style=dashed"
+			}
+			lines = append(lines, fmt.Sprintf("%s%s [label=%s%s];", indent, dotQuote(node.ID), dotQuote(node.Label), style))
+		}
+	}
+
+	accounts := []string{}
+	for accountID := range graph.Nodes {
+		accounts = append(accounts, accountID)
+	}
+	sort.Strings(accounts)
+
+	for _, accountID := range accounts {
+		if accountID == "" {
+			continue
+		}
+		lines = append(lines,
+			fmt.Sprintf("  subgraph %s {", dotQuote("cluster_"+accountID)),
+			fmt.Sprintf("    label=%s;", dotQuote(accountID)),
+		)
+		for _, vpc := range sortedMapKeys(graph.Nodes[accountID]) {
+			if vpc == "" {
+				writeNodes("    ", graph.Nodes[accountID][vpc])
+				continue
+			}
+			lines = append(lines,
+				fmt.Sprintf("    subgraph %s {", dotQuote(fmt.Sprintf("cluster_%s_%s", accountID, vpc))),
+				fmt.Sprintf("      label=%s;", dotQuote(vpc)),
+				"      style=rounded;",
+			)
+			writeNodes("      ", graph.Nodes[accountID][vpc])
+			lines = append(lines, "    }")
+		}
+		lines = append(lines, "  }")
+	}
+	for _, vpc := range sortedMapKeys(graph.Nodes[""]) {
+		writeNodes("  ", graph.Nodes[""][vpc])
+	}
+
+	for _, edge := range graph.Edges {
+		lines = append(lines, fmt.Sprintf("  %s -> %s [label=%s];", dotQuote(edge.From), dotQuote(edge.To), dotQuote(edge.Relation)))
+	}
+	lines = append(lines, "}")
+
+	_, err := io.WriteString(writer, strings.Join(lines, "\n")+"\n")
+	return err
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/hamstah/awstools/aws/dump/resources"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteDOT(t *testing.T) {
+	vpcID := "vpc-1"
+	resourceList := []resources.Resource{
+		{
+			ID:        "vpc-1",
+			Service:   "ec2",
+			Type:      "vpc",
+			AccountID: "123456789012",
+		},
+		{
+			ID:        "i-1",
+			Service:   "ec2",
+			Type:      "instance",
+			AccountID: "123456789012",
+			Metadata: map[string]interface{}{
+				"VpcId":          &vpcID,
+				"SecurityGroups": []map[string]string{{"GroupId": "sg-1"}},
+				"Tags":           []map[string]string{{"Key": "Name", "Value": `web "a"`}},
+			},
+		},
+		{
+			ID:        "sg-1",
+			ARN:       "arn:aws:ec2:eu-west-1:123456789012:security-group/sg-1",
+			Service:   "ec2",
+			Type:      "security-group",
+			AccountID: "123456789012",
+			Metadata:  map[string]interface{}{"VpcId": "vpc-1"},
+		},
+		{
+			ID:        "bucket",
+			ARN:       "arn:aws:s3:::bucket",
+			Service:   "s3",
+			Type:      "bucket",
+			AccountID: "123456789012",
+		},
+		{
+			ID:        "ec2:instances",
+			Service:   "aws-dump",
+			Type:      "error",
+			AccountID: "123456789012",
+		},
+	}
+
+	buffer := &bytes.Buffer{}
+	require.NoError(t, WriteDOT(buffer, resourceList))
+	require.Equal(t, `digraph aws {
+  rankdir=LR;
+  node [shape=box, fontsize=10];
+  edge [fontsize=8];
+  subgraph "cluster_123456789012" {
+    label="123456789012";
+    "arn:aws:s3:::bucket" [label="s3:bucket\nbucket"];
+    subgraph "cluster_123456789012_vpc-1" {
+      label="vpc-1";
+      style=rounded;
+      "arn:aws:ec2:eu-west-1:123456789012:security-group/sg-1" [label="ec2:security-group\nsg-1"];
+      "i-1" [label="ec2:instance\nweb \"a\"\ni-1"];
+      "vpc-1" [label="ec2:vpc\nvpc-1"];
+    }
+  }
+  "i-1" -> "vpc-1" [label="in"];
+  "i-1" -> "arn:aws:ec2:eu-west-1:123456789012:security-group/sg-1" [label="uses"];
+  "arn:aws:ec2:eu-west-1:123456789012:security-group/sg-1" -> "vpc-1" [label="in"];
+}
+`, buffer.String())
+}
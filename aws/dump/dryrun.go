@@ -0,0 +1,252 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/hamstah/awstools/aws/dump/resources"
+)
+
+const (
+	DryRunFormatText   = "text"
+	DryRunFormatJSON   = "json"
+	DryRunFormatPolicy = "policy"
+
+	// the steps use the credentials of the accounts dumped, or the ones aws-dump
+	// is run with
+	CredentialsAccounts = "accounts"
+	CredentialsCaller   = "caller"
+)
+
+// callerStepActions are the IAM actions of the steps run with the credentials
+// aws-dump is run with, outside of the resources package.
+var callerStepActions = map[string][]string{
+	"assume-role":        {"sts:AssumeRole"},
+	"terraform-backends": {"s3:GetObject"},
+}
+
+// PlannedAccount is an account the dump would run the reports in, AccountID
+// is empty for the accounts using the credentials aws-dump is run with.
+type PlannedAccount struct {
+	AccountID string   `json:"account_id"`
+	RoleARN   string   `json:"role_arn"`
+	Regions   []string `json:"regions"`
+}
+
+// PlannedReport is a report the dump would run, global reports only run in
+// the first region of each account.
+type PlannedReport struct {
+	Name    string   `json:"name"`
+	Global  bool     `json:"global"`
+	Actions []string `json:"actions"`
+}
+
+// PlannedStep is a step of the dump run outside of the reports.
+type PlannedStep struct {
+	Name        string   `json:"name"`
+	Credentials string   `json:"credentials"`
+	Actions     []string `json:"actions"`
+}
+
+// DryRunPlan describes what the dump would do, built without calling AWS. The
+// accounts of the organization are only listed when the dump runs.
+type DryRunPlan struct {
+	Accounts     []PlannedAccount              `json:"accounts"`
+	Organization *resources.OrganizationConfig `json:"organization,omitempty"`
+	Reports      []PlannedReport               `json:"reports"`
+	Steps        []PlannedStep                 `json:"steps"`
+}
+
+// PolicyDocument is an IAM policy document.
+type PolicyDocument struct {
+	Version   string            `json:"Version"`
+	Statement []PolicyStatement `json:"Statement"`
+}
+
+type PolicyStatement struct {
+	Effect   string   `json:"Effect"`
+	Action   []string `json:"Action"`
+	Resource string   `json:"Resource"`
+}
+
+// roleAccountID returns the account of a role ARN, empty if it is not one.
+func roleAccountID(roleARN string) string {
+	parts := strings.Split(roleARN, ":")
+	if len(parts) < 6 || parts[0] != "arn" {
+		return ""
+	}
+	return parts[4]
+}
+
+// NewDryRunPlan returns the plan of the dump of the event. The actions of the
+// reports come from resources.ReportActions.
+func NewDryRunPlan(event Input) (*DryRunPlan, error) {
+	services := resources.AllServices()
+	selected, err := SelectReports(&event, services)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &DryRunPlan{
+		Accounts: []PlannedAccount{},
+		Reports:  []PlannedReport{},
+		Steps:    []PlannedStep{},
+	}
+
+	if event.Organization != nil {
+		organization := *event.Organization
+		if len(organization.Regions) == 0 {
+			organization.Regions = event.Regions
+		}
+		plan.Organization = &organization
+	}
+
+	assumesRoles := plan.Organization != nil
+	for _, account := range event.Accounts {
+		regions := account.Regions
+		if len(event.Regions) > 0 {
+			regions = event.Regions
+		}
+		plan.Accounts = append(plan.Accounts, PlannedAccount{
+			AccountID: roleAccountID(account.RoleARN),
+			RoleARN:   account.RoleARN,
+			Regions:   regions,
+		})
+		assumesRoles = assumesRoles || account.RoleARN != ""
+	}
+
+	for _, name := range selected {
+		parts := strings.Split(name, ":")
+		actions, ok := resources.ReportActions[name]
+		if !ok {
+			actions = []string{}
+		}
+		plan.Reports = append(plan.Reports, PlannedReport{
+			Name:    name,
+			Global:  services[parts[0]].IsGlobal,
+			Actions: actions,
+		})
+	}
+
+	addStep := func(name, credentials string, actions []string) {
+		plan.Steps = append(plan.Steps, PlannedStep{Name: name, Credentials: credentials, Actions: actions})
+	}
+	if plan.Organization != nil {
+		addStep("organization", CredentialsCaller, resources.StepActions["organization"])
+	}
+	if assumesRoles {
+		addStep("assume-role", CredentialsCaller, callerStepActions["assume-role"])
+	}
+	if event.TerraformBackendConfig != nil {
+		addStep("terraform-backends", CredentialsCaller, callerStepActions["terraform-backends"])
+	}
+	addStep("sessions", CredentialsAccounts, resources.StepActions["sessions"])
+	if event.SSMInventory {
+		addStep("ssm-inventory", CredentialsAccounts, resources.StepActions["ssm-inventory"])
+	}
+	if event.Rightsizing {
+		addStep("rightsizing", CredentialsAccounts, resources.StepActions["rightsizing"])
+	}
+	if event.MonthlyCost {
+		addStep("monthly-cost", CredentialsAccounts, resources.StepActions["monthly-cost"])
+	}
+	if event.TagUnmanaged && event.ApplyTags {
+		addStep("tag-unmanaged", CredentialsAccounts, resources.StepActions["tag-unmanaged"])
+	}
+
+	return plan, nil
+}
+
+// Policy returns the policy allowing the actions of the reports and of the
+// steps run with the credentials of the accounts, to attach to the role of
+// the dump in each account.
+func (p *DryRunPlan) Policy() *PolicyDocument {
+	seen := map[string]bool{}
+	add := func(actions []string) {
+		for _, action := range actions {
+			seen[action] = true
+		}
+	}
+	for _, report := range p.Reports {
+		add(report.Actions)
+	}
+	for _, step := range p.Steps {
+		if step.Credentials == CredentialsAccounts {
+			add(step.Actions)
+		}
+	}
+
+	actions := []string{}
+	for action := range seen {
+		actions = append(actions, action)
+	}
+	sort.Strings(actions)
+
+	return &PolicyDocument{
+		Version: "2012-10-17",
+		Statement: []PolicyStatement{
+			{Effect: "Allow", Action: actions, Resource: "*"},
+		},
+	}
+}
+
+// WriteText writes the accounts, reports and steps of the plan as tables.
+func (p *DryRunPlan) WriteText(writer io.Writer) error {
+	w := tabwriter.NewWriter(writer, 0, 4, 2, ' ', 0)
+
+	fmt.Fprintln(w, "ACCOUNT\tROLE\tREGIONS")
+	for _, account := range p.Accounts {
+		accountID := account.AccountID
+		if accountID == "" {
+			accountID = "current"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", accountID, account.RoleARN, strings.Join(account.Regions, ", "))
+	}
+	if p.Organization != nil {
+		accounts := "organization"
+		if len(p.Organization.ExcludeAccountIDs) > 0 {
+			accounts = fmt.Sprintf("organization except %s", strings.Join(p.Organization.ExcludeAccountIDs, ", "))
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", accounts, p.Organization.RoleName, strings.Join(p.Organization.Regions, ", "))
+	}
+
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "REPORT\tSCOPE\tACTIONS")
+	for _, report := range p.Reports {
+		scope := "regional"
+		if report.Global {
+			scope = "global"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", report.Name, scope, strings.Join(report.Actions, ", "))
+	}
+
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "STEP\tCREDENTIALS\tACTIONS")
+	for _, step := range p.Steps {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", step.Name, step.Credentials, strings.Join(step.Actions, ", "))
+	}
+	return w.Flush()
+}
+
+// WriteDryRun writes the plan in the format, the policy format only writes
+// the policy of the role of the dump.
+func WriteDryRun(writer io.Writer, plan *DryRunPlan, format string) error {
+	var value interface{} = plan
+	switch format {
+	case DryRunFormatText:
+		return plan.WriteText(writer)
+	case DryRunFormatPolicy:
+		value = plan.Policy()
+	}
+
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(writer, string(data))
+	return err
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/hamstah/awstools/aws/dump/resources"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReportActions(t *testing.T) {
+	// report_actions.go is generated, go generate ./... when it fails
+	for _, report := range resources.AllReports() {
+		require.NotEmpty(t, resources.ReportActions[report], report)
+	}
+}
+
+func TestNewDryRunPlan(t *testing.T) {
+	event := Input{
+		Accounts: []*resources.Account{
+			{RoleARN: "arn:aws:iam::123456789012:role/aws-dump", Regions: []string{"eu-west-1"}},
+		},
+		Regions:     []string{"eu-west-1", "us-east-1"},
+		Reports:     []string{"iam:account-summary", "ec2:instances"},
+		Rightsizing: true,
+	}
+
+	plan, err := NewDryRunPlan(event)
+	require.NoError(t, err)
+	require.Equal(t, []PlannedAccount{
+		{AccountID: "123456789012", RoleARN: "arn:aws:iam::123456789012:role/aws-dump", Regions: []string{"eu-west-1", "us-east-1"}},
+	}, plan.Accounts)
+	require.Len(t, plan.Reports, 2)
+	require.Equal(t, "ec2:instances", plan.Reports[0].Name)
+	require.False(t, plan.Reports[0].Global)
+	require.Equal(t, []string{"ec2:DescribeInstances"}, plan.Reports[0].Actions)
+	require.Equal(t, "iam:account-summary", plan.Reports[1].Name)
+	require.True(t, plan.Reports[1].Global)
+
+	steps := []string{}
+	for _, step := range plan.Steps {
+		steps = append(steps, step.Name)
+	}
+	require.Equal(t, []string{"assume-role", "sessions", "rightsizing"}, steps)
+
+	policy := plan.Policy()
+	require.Len(t, policy.Statement, 1)
+	actions := policy.Statement[0].Action
+	require.Contains(t, actions, "iam:GetAccountSummary")
+	require.Contains(t, actions, "cloudwatch:GetMetricData")
+	// assuming the role is done by the caller
+	require.NotContains(t, actions, "sts:AssumeRole")
+
+	buffer := &bytes.Buffer{}
+	require.NoError(t, WriteDryRun(buffer, plan, DryRunFormatText))
+	require.Contains(t, buffer.String(), "123456789012  arn:aws:iam::123456789012:role/aws-dump  eu-west-1, us-east-1")
+	require.Contains(t, buffer.String(), "iam:account-summary  global    iam:GetAccountSummary")
+
+	_, err = NewDryRunPlan(Input{Reports: []string{"s3:unknown"}})
+	require.EqualError(t, err, "unknown resource unknown for service s3")
+}
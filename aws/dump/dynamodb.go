@@ -0,0 +1,154 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	"github.com/hamstah/awstools/aws/dump/resources"
+)
+
+const (
+	// maximum number of items of BatchWriteItem
+	dynamoDBBatchSize = 25
+	// items are limited to 400KB, larger metadata is dropped
+	dynamoDBMaxMetadataSize = 350 * 1024
+	dynamoDBMaxAttempts     = 8
+)
+
+// DynamoDBSink upserts the resources in a DynamoDB table with the time they
+// were last seen, and optionally when they expire if they are not seen again.
+type DynamoDBSink struct {
+	client   dynamodbiface.DynamoDBAPI
+	table    string
+	lastSeen time.Time
+	ttl      time.Duration
+
+	pending []*dynamodb.WriteRequest
+	keys    map[string]int
+	written int
+}
+
+func NewDynamoDBSink(client dynamodbiface.DynamoDBAPI, table string, lastSeen time.Time, ttl time.Duration) *DynamoDBSink {
+	return &DynamoDBSink{
+		client:   client,
+		table:    table,
+		lastSeen: lastSeen,
+		ttl:      ttl,
+		pending:  []*dynamodb.WriteRequest{},
+		keys:     map[string]int{},
+	}
+}
+
+func (s *DynamoDBSink) item(resource resources.Resource) (map[string]*dynamodb.AttributeValue, error) {
+	item := map[string]*dynamodb.AttributeValue{
+		"key":        {S: aws.String(ResourceKey(resource))},
+		"service":    {S: aws.String(resource.Service)},
+		"type":       {S: aws.String(resource.Type)},
+		"account_id": {S: aws.String(resource.AccountID)},
+		"last_seen":  {S: aws.String(s.lastSeen.UTC().Format(time.RFC3339))},
+	}
+
+	// empty strings are only allowed for non-key attributes since 2020, skip
+	// them to keep the items small
+	optional := map[string]string{
+		"id":     resource.ID,
+		"arn":    resource.ARN,
+		"region": resource.Region,
+	}
+	for name, value := range optional {
+		if value != "" {
+			item[name] = &dynamodb.AttributeValue{S: aws.String(value)}
+		}
+	}
+
+	if len(resource.ManagedBy) > 0 {
+		managedBy := map[string]*dynamodb.AttributeValue{}
+		for key, value := range resource.ManagedBy {
+			managedBy[key] = &dynamodb.AttributeValue{S: aws.String(value)}
+		}
+		item["managed_by"] = &dynamodb.AttributeValue{M: managedBy}
+	}
+
+	// metadata is stored as JSON, its types don't map well to attribute values
+	metadata, err := json.Marshal(resource.Metadata)
+	if err != nil {
+		return nil, err
+	}
+	if len(metadata) > dynamoDBMaxMetadataSize {
+		item["metadata_dropped"] = &dynamodb.AttributeValue{BOOL: aws.Bool(true)}
+	} else {
+		item["metadata"] = &dynamodb.AttributeValue{S: aws.String(string(metadata))}
+	}
+
+	if s.ttl > 0 {
+		expiresAt := s.lastSeen.Add(s.ttl).Unix()
+		item["expires_at"] = &dynamodb.AttributeValue{N: aws.String(strconv.FormatInt(expiresAt, 10))}
+	}
+
+	return item, nil
+}
+
+// Put adds the resource to the current batch and writes the batch when it is
+// full.
+func (s *DynamoDBSink) Put(resource resources.Resource) error {
+	item, err := s.item(resource)
+	if err != nil {
+		return err
+	}
+
+	request := &dynamodb.WriteRequest{PutRequest: &dynamodb.PutRequest{Item: item}}
+
+	// a batch can't contain the same key twice, keep the last one
+	key := ResourceKey(resource)
+	if index, ok := s.keys[key]; ok {
+		s.pending[index] = request
+		return nil
+	}
+	s.keys[key] = len(s.pending)
+	s.pending = append(s.pending, request)
+
+	if len(s.pending) >= dynamoDBBatchSize {
+		return s.Flush()
+	}
+	return nil
+}
+
+// Flush writes the current batch, retrying the unprocessed items with a
+// backoff.
+func (s *DynamoDBSink) Flush() error {
+	requests := s.pending
+	written := len(requests)
+	s.pending = []*dynamodb.WriteRequest{}
+	s.keys = map[string]int{}
+
+	delay := 100 * time.Millisecond
+	for attempt := 1; len(requests) > 0; attempt++ {
+		if attempt > dynamoDBMaxAttempts {
+			return fmt.Errorf("failed to write %d items to %s after %d attempts", len(requests), s.table, dynamoDBMaxAttempts)
+		}
+		if attempt > 1 {
+			time.Sleep(delay)
+			delay *= 2
+		}
+
+		res, err := s.client.BatchWriteItem(&dynamodb.BatchWriteItemInput{
+			RequestItems: map[string][]*dynamodb.WriteRequest{s.table: requests},
+		})
+		if err != nil {
+			return err
+		}
+		requests = res.UnprocessedItems[s.table]
+	}
+
+	s.written += written
+	return nil
+}
+
+func (s *DynamoDBSink) Written() int {
+	return s.written
+}
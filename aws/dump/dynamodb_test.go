@@ -0,0 +1,74 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	"github.com/hamstah/awstools/aws/dump/resources"
+	"github.com/stretchr/testify/require"
+)
+
+type stubDynamoDB struct {
+	dynamodbiface.DynamoDBAPI
+	batches     [][]*dynamodb.WriteRequest
+	unprocessed int
+}
+
+func (client *stubDynamoDB) BatchWriteItem(input *dynamodb.BatchWriteItemInput) (*dynamodb.BatchWriteItemOutput, error) {
+	requests := input.RequestItems["inventory"]
+	client.batches = append(client.batches, requests)
+
+	output := &dynamodb.BatchWriteItemOutput{UnprocessedItems: map[string][]*dynamodb.WriteRequest{}}
+	if client.unprocessed > 0 {
+		output.UnprocessedItems["inventory"] = requests[:client.unprocessed]
+		client.unprocessed = 0
+	}
+	return output, nil
+}
+
+func TestDynamoDBSink(t *testing.T) {
+	client := &stubDynamoDB{unprocessed: 1}
+	lastSeen := time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)
+	sink := NewDynamoDBSink(client, "inventory", lastSeen, 7*24*time.Hour)
+
+	for i := 0; i < 26; i++ {
+		require.NoError(t, sink.Put(resources.Resource{
+			ID:        "bucket",
+			ARN:       "arn:aws:s3:::bucket-" + string(rune('a'+i)),
+			AccountID: "123456789012",
+			Service:   "s3",
+			Type:      "bucket",
+			Metadata:  map[string]interface{}{"Name": "bucket"},
+		}))
+	}
+	// same key as the previous resource
+	require.NoError(t, sink.Put(resources.Resource{
+		ARN:       "arn:aws:s3:::bucket-z",
+		AccountID: "123456789012",
+		Service:   "s3",
+		Type:      "bucket",
+		ManagedBy: map[string]string{"type": "terraform"},
+	}))
+	require.NoError(t, sink.Flush())
+
+	// a full batch, its unprocessed item and the last resource
+	require.Len(t, client.batches, 3)
+	require.Len(t, client.batches[0], 25)
+	require.Len(t, client.batches[1], 1)
+	require.Len(t, client.batches[2], 1)
+	require.Equal(t, 26, sink.Written())
+
+	item := client.batches[0][0].PutRequest.Item
+	require.Equal(t, "arn:aws:s3:::bucket-a", aws.StringValue(item["key"].S))
+	require.Equal(t, "2021-01-02T03:04:05Z", aws.StringValue(item["last_seen"].S))
+	require.Equal(t, "1610161445", aws.StringValue(item["expires_at"].N))
+	require.Equal(t, `{"Name":"bucket"}`, aws.StringValue(item["metadata"].S))
+	require.Nil(t, item["region"])
+
+	item = client.batches[2][0].PutRequest.Item
+	require.Equal(t, "terraform", aws.StringValue(item["managed_by"].M["type"].S))
+	require.Nil(t, item["id"])
+}
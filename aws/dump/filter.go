@@ -0,0 +1,97 @@
+package main
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/hamstah/awstools/aws/dump/resources"
+)
+
+// ResourceFilter keeps the resources with all the Tags, of one of the Types
+// (service:type with * wildcards) and in one of the Regions. Empty filters
+// match all the resources.
+type ResourceFilter struct {
+	// Tags maps the tag keys to their value, * for any value
+	Tags    map[string]string `json:"tags"`
+	Types   []string          `json:"types"`
+	Regions []string          `json:"regions"`
+}
+
+// NewResourceFilter returns the filter of the key=value tags, a key alone
+// matching any value, the types and the regions.
+func NewResourceFilter(tags, types, regions []string) (*ResourceFilter, error) {
+	filter := &ResourceFilter{
+		Tags:    map[string]string{},
+		Types:   types,
+		Regions: regions,
+	}
+
+	for _, tag := range tags {
+		parts := strings.SplitN(tag, "=", 2)
+		if parts[0] == "" {
+			return nil, fmt.Errorf("invalid tag filter %s, should be key=value or key", tag)
+		}
+		if len(parts) == 1 {
+			filter.Tags[parts[0]] = "*"
+		} else {
+			filter.Tags[parts[0]] = parts[1]
+		}
+	}
+
+	for _, resourceType := range types {
+		if _, err := path.Match(resourceType, ""); err != nil {
+			return nil, fmt.Errorf("invalid type filter %s: %s", resourceType, err)
+		}
+	}
+	return filter, nil
+}
+
+func (f *ResourceFilter) Matches(resource resources.Resource) bool {
+	if f == nil {
+		return true
+	}
+
+	if len(f.Types) > 0 {
+		matched := false
+		for _, resourceType := range f.Types {
+			if ok, _ := path.Match(resourceType, fmt.Sprintf("%s:%s", resource.Service, resource.Type)); ok {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+
+	if len(f.Regions) > 0 {
+		matched := false
+		for _, region := range f.Regions {
+			if region == resource.Region {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+
+	if len(f.Tags) > 0 {
+		tags := ResourceTags(resource)
+		for key, expected := range f.Tags {
+			value, ok := tags[key]
+			if !ok || (expected != "*" && value != expected) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// ResourceTags returns the tags of the metadata, either a list of Key and
+// Value (or key and value) or a map.
+func ResourceTags(resource resources.Resource) map[string]string {
+	return resource.Tags()
+}
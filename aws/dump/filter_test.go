@@ -0,0 +1,66 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/hamstah/awstools/aws/dump/resources"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResourceTags(t *testing.T) {
+	require.Equal(t, map[string]string{"team": "payments", "env": "prod"}, ResourceTags(resources.Resource{
+		Metadata: map[string]interface{}{
+			"Tags": []map[string]string{{"Key": "team", "Value": "payments"}},
+			"TagList": []interface{}{
+				map[string]interface{}{"key": "env", "value": "prod"},
+			},
+		},
+	}))
+	require.Equal(t, map[string]string{"team": "payments"}, ResourceTags(resources.Resource{
+		Metadata: map[string]interface{}{"Tags": map[string]*string{"team": aws.String("payments")}},
+	}))
+	require.Equal(t, map[string]string{}, ResourceTags(resources.Resource{}))
+}
+
+func TestResourceFilter(t *testing.T) {
+	instance := resources.Resource{
+		Service: "ec2",
+		Type:    "instance",
+		Region:  "eu-west-1",
+		Metadata: map[string]interface{}{
+			"Tags": []interface{}{
+				map[string]interface{}{"Key": "team", "Value": "payments"},
+				map[string]interface{}{"Key": "env", "Value": "prod"},
+			},
+		},
+	}
+	bucket := resources.Resource{Service: "s3", Type: "bucket", Region: "us-east-1"}
+
+	var none *ResourceFilter
+	require.True(t, none.Matches(instance))
+
+	filter, err := NewResourceFilter([]string{"team=payments", "env"}, nil, nil)
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"team": "payments", "env": "*"}, filter.Tags)
+	require.True(t, filter.Matches(instance))
+	require.False(t, filter.Matches(bucket))
+
+	filter, err = NewResourceFilter([]string{"team=billing"}, nil, nil)
+	require.NoError(t, err)
+	require.False(t, filter.Matches(instance))
+
+	filter, err = NewResourceFilter(nil, []string{"ec2:*", "rds:db-instance"}, []string{"eu-west-1"})
+	require.NoError(t, err)
+	require.True(t, filter.Matches(instance))
+	require.False(t, filter.Matches(bucket))
+
+	bucket.Region = "eu-west-1"
+	require.False(t, filter.Matches(bucket))
+
+	_, err = NewResourceFilter([]string{"=value"}, nil, nil)
+	require.EqualError(t, err, "invalid tag filter =value, should be key=value or key")
+
+	_, err = NewResourceFilter(nil, []string{"ec2:["}, nil)
+	require.Error(t, err)
+}
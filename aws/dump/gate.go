@@ -0,0 +1,167 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+
+	"github.com/hamstah/awstools/aws/dump/resources"
+)
+
+const (
+	// GateExitCode is the exit code of the dump when the gate fails, the
+	// other failures exit with 1
+	GateExitCode = 3
+
+	GateMaxUnmanaged            = "max_unmanaged"
+	GateMaxHighSeverityFindings = "max_high_severity_findings"
+	GateRequiredServices        = "required_services"
+)
+
+// severities of the findings counted by max_high_severity_findings
+var gateHighSeverities = map[string]bool{"high": true, "critical": true}
+
+// Gate is the set of conditions the dump needs to meet to pass, e.g. to fail
+// a CI build on inventory or compliance regressions. The conditions not set
+// are not checked.
+type Gate struct {
+	MaxUnmanaged            *int     `json:"max_unmanaged"`
+	MaxHighSeverityFindings *int     `json:"max_high_severity_findings"`
+	RequiredServices        []string `json:"required_services"`
+}
+
+func NewGateFromFile(filename string) (*Gate, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	gate := &Gate{}
+	err = json.Unmarshal(data, gate)
+	if err != nil {
+		return nil, err
+	}
+	return gate, nil
+}
+
+// GateStats are the values of the dump checked by the gate.
+type GateStats struct {
+	Unmanaged            int
+	HighSeverityFindings int
+	// Services are the services of the reports run
+	Services map[string]bool
+	Errors   []*resources.JobError
+}
+
+// GateCheck is the result of a condition of the gate.
+type GateCheck struct {
+	Name    string   `json:"name"`
+	Passed  bool     `json:"passed"`
+	Limit   int      `json:"limit"`
+	Value   int      `json:"value"`
+	Details []string `json:"details,omitempty"`
+}
+
+// GateResult is the result of the gate, written to --gate-result.
+type GateResult struct {
+	Passed bool        `json:"passed"`
+	Checks []GateCheck `json:"checks"`
+}
+
+// IsHighSeverityFinding returns whether the resource has a high or critical
+// Severity, either a label or an object with a Label like the Security Hub
+// findings.
+func IsHighSeverityFinding(resource resources.Resource) bool {
+	severity, ok := resource.Metadata["Severity"]
+	if !ok {
+		return false
+	}
+
+	// go through JSON to handle the structs of the SDK
+	data, err := json.Marshal(severity)
+	if err != nil {
+		return false
+	}
+	var value interface{}
+	if json.Unmarshal(data, &value) != nil {
+		return false
+	}
+
+	label, _ := value.(string)
+	if object, ok := value.(map[string]interface{}); ok {
+		label, _ = object["Label"].(string)
+	}
+	return gateHighSeverities[strings.ToLower(label)]
+}
+
+// Add counts the resource, status is its terraform status like in Summary.Add.
+func (s *GateStats) Add(resource resources.Resource, status string) {
+	if status == SummaryUnmanaged {
+		s.Unmanaged++
+	}
+	if IsHighSeverityFinding(resource) {
+		s.HighSeverityFindings++
+	}
+}
+
+// Evaluate returns the result of the conditions of the gate on the stats.
+func (g *Gate) Evaluate(stats *GateStats) *GateResult {
+	result := &GateResult{Passed: true, Checks: []GateCheck{}}
+	add := func(check GateCheck) {
+		result.Checks = append(result.Checks, check)
+		result.Passed = result.Passed && check.Passed
+	}
+
+	if g.MaxUnmanaged != nil {
+		add(GateCheck{
+			Name:   GateMaxUnmanaged,
+			Passed: stats.Unmanaged <= *g.MaxUnmanaged,
+			Limit:  *g.MaxUnmanaged,
+			Value:  stats.Unmanaged,
+		})
+	}
+
+	if g.MaxHighSeverityFindings != nil {
+		add(GateCheck{
+			Name:   GateMaxHighSeverityFindings,
+			Passed: stats.HighSeverityFindings <= *g.MaxHighSeverityFindings,
+			Limit:  *g.MaxHighSeverityFindings,
+			Value:  stats.HighSeverityFindings,
+		})
+	}
+
+	if len(g.RequiredServices) > 0 {
+		failed := map[string]bool{}
+		for _, jobError := range stats.Errors {
+			failed[jobError.Service] = true
+		}
+
+		details := []string{}
+		for _, service := range g.RequiredServices {
+			if !stats.Services[service] {
+				details = append(details, fmt.Sprintf("%s: no report run", service))
+			} else if failed[service] {
+				details = append(details, fmt.Sprintf("%s: failed", service))
+			}
+		}
+		sort.Strings(details)
+		add(GateCheck{
+			Name:    GateRequiredServices,
+			Passed:  len(details) == 0,
+			Value:   len(details),
+			Details: details,
+		})
+	}
+
+	return result
+}
+
+func (r *GateResult) WriteFile(filename string) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filename, append(data, '\n'), 0644)
+}
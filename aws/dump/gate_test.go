@@ -0,0 +1,45 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/hamstah/awstools/aws/dump/resources"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsHighSeverityFinding(t *testing.T) {
+	require.True(t, IsHighSeverityFinding(resources.Resource{Metadata: map[string]interface{}{"Severity": "high"}}))
+	require.True(t, IsHighSeverityFinding(resources.Resource{Metadata: map[string]interface{}{
+		"Severity": map[string]interface{}{"Label": "CRITICAL", "Normalized": 90},
+	}}))
+	require.False(t, IsHighSeverityFinding(resources.Resource{Metadata: map[string]interface{}{"Severity": "medium"}}))
+	require.False(t, IsHighSeverityFinding(resources.Resource{Metadata: map[string]interface{}{}}))
+}
+
+func TestGateEvaluate(t *testing.T) {
+	stats := &GateStats{Services: map[string]bool{"iam": true, "ec2": true}}
+	stats.Add(resources.Resource{Service: "ec2", Type: "instance", Metadata: map[string]interface{}{}}, SummaryUnmanaged)
+	stats.Add(resources.Resource{Service: "ec2", Type: "instance", Metadata: map[string]interface{}{}}, SummaryManaged)
+	stats.Add(resources.Resource{Service: "sharing", Type: "finding", Metadata: map[string]interface{}{"Severity": "high"}}, "")
+	stats.Errors = []*resources.JobError{{Service: "ec2", Report: "instances", Message: "AccessDenied"}}
+
+	zero, one := 0, 1
+	result := (&Gate{MaxUnmanaged: &one, MaxHighSeverityFindings: &zero}).Evaluate(stats)
+	require.False(t, result.Passed)
+	require.Equal(t, []GateCheck{
+		{Name: GateMaxUnmanaged, Passed: true, Limit: 1, Value: 1},
+		{Name: GateMaxHighSeverityFindings, Passed: false, Limit: 0, Value: 1},
+	}, result.Checks)
+
+	result = (&Gate{RequiredServices: []string{"iam", "ec2", "s3"}}).Evaluate(stats)
+	require.False(t, result.Passed)
+	require.Equal(t, []string{"ec2: failed", "s3: no report run"}, result.Checks[0].Details)
+
+	result = (&Gate{RequiredServices: []string{"iam"}}).Evaluate(stats)
+	require.True(t, result.Passed)
+
+	// no conditions
+	result = (&Gate{}).Evaluate(stats)
+	require.True(t, result.Passed)
+	require.Empty(t, result.Checks)
+}
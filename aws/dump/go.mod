@@ -4,7 +4,7 @@ go 1.15
 
 require (
 	github.com/aws/aws-lambda-go v1.22.0
-	github.com/aws/aws-sdk-go v1.36.31
+	github.com/aws/aws-sdk-go v1.44.153
 	github.com/fatih/structs v1.1.0
 	github.com/hamstah/awstools/common v0.0.0-20210118215825-d772cda2a155
 	github.com/hashicorp/terraform v0.12.13
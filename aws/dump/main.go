@@ -4,11 +4,18 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
 	"github.com/hamstah/awstools/aws/dump/resources"
 	"github.com/hamstah/awstools/common"
 	log "github.com/sirupsen/logrus"
@@ -18,101 +25,599 @@ import (
 
 var (
 	accountsConfigFilename         = kingpin.Flag("accounts-config", "Configuration file with the accounts to list resources for.").Short('c').String()
+	organizationRoleName           = kingpin.Flag("organization-role-name", "Dump all the accounts of the organization by assuming this role in each account (e.g. OrganizationAccountAccessRole). Requires --regions.").String()
+	organizationExcludeAccountIDs  = kingpin.Flag("organization-exclude-account-id", "Account of the organization not to dump. Can be repeated.").Strings()
+	regions                        = kingpin.Flag("regions", "Regions to dump for every account instead of the regions of the accounts config, all for all the enabled regions. Can be repeated or comma separated.").Strings()
 	terraformBackendConfigFilename = kingpin.Flag("terraform-backends-config", "Configuration file with the terraform backends to compare with.").Short('t').String()
-	outputFilename                 = kingpin.Flag("output", "Filename to store the results in.").Short('o').String()
+	outputFilename                 = kingpin.Flag("output", "Filename to store the results in, - for stdout.").Short('o').String()
+	outputFormat                   = kingpin.Flag("output-format", "Output format, jsonl writes one resource per line as soon as it is collected, dot writes a Graphviz graph of the resources and their relationships, cypher a Cypher script merging them in Neo4j.").Default("json").Enum("json", "jsonl", "csv", "dot", "cypher")
+	csvColumns                     = kingpin.Flag("csv-column", "Metadata to add as a column in the csv output, nested keys separated by dots, e.g. State.Name. Can be repeated.").Strings()
+	outputSchema                   = kingpin.Flag("output-schema", "Schema of the json output format, v1 wraps the resources with the metadata of the run, see aws-dump schema.").Default(OutputSchemaNone).Enum(OutputSchemaNone, OutputSchemaV1)
+	shareBundle                    = kingpin.Flag("share-bundle", "Write a .tar.gz bundle for external auditors to the output: the resources after redaction and pseudonymisation, a manifest and a schema.").Default("false").Bool()
+	redactionRulesFilename         = kingpin.Flag("redaction-rules", "JSON file with the redaction rules of --share-bundle. Passwords, secrets and private keys are redacted by default.").String()
+	pseudonymMappingFilename       = kingpin.Flag("pseudonym-mapping", "JSON file mapping identifiers to their pseudonym in --share-bundle. The accounts not in the file are added to it.").String()
+	terraformIncludeTypes          = kingpin.Flag("terraform-include-type", "Only compare the resources of this type with terraform, e.g. ec2:instance or ec2:*. Can be repeated.").Strings()
+	terraformExcludeTypes          = kingpin.Flag("terraform-exclude-type", "Don't compare the resources of this type with terraform, e.g. iam:access-key. Can be repeated.").Strings()
+	filterTags                     = kingpin.Flag("filter-tag", "Only output the resources with this tag, key=value or key for any value. Can be repeated, the resources need all the tags.").Strings()
+	filterTypes                    = kingpin.Flag("filter-type", "Only output the resources of this type, e.g. ec2:instance or ec2:*. Can be repeated.").Strings()
+	filterRegions                  = kingpin.Flag("filter-region", "Only output the resources in this region. Can be repeated.").Strings()
 	onlyUnmanaged                  = kingpin.Flag("only-unmanaged", "Only return resources not managed by terraform.").Default("false").Bool()
+	tagUnmanaged                   = kingpin.Flag("tag-unmanaged", "Tag the resources not managed by terraform with managed-by=unknown and the time of the dump. Only logs the resources to tag unless --apply-tags is used.").Default("false").Bool()
+	applyTags                      = kingpin.Flag("apply-tags", "Apply the tags of --tag-unmanaged.").Default("false").Bool()
+	profile                        = kingpin.Flag("profile", "Run the reports and enrichments of a built-in profile, refined with --only and --exclude.").Enum(ProfileNames()...)
 	reports                        = kingpin.Flag("report", "Only run the specified report. Can be repeated.").Strings()
+	onlyReports                    = kingpin.Flag("only", "Only run the reports matching this pattern, e.g. 'iam:*'. Can be repeated.").Strings()
+	excludeReports                 = kingpin.Flag("exclude", "Don't run the reports matching this pattern, e.g. iam:account-authorization-details. Can be repeated.").Strings()
+	includeOptionalReports         = kingpin.Flag("include-optional-reports", "Also run optional reports (e.g. classic WAF) when no report is specified.").Default("false").Bool()
+	backupCoverageDays             = kingpin.Flag("backup-coverage-days", "Age in days after which the latest recovery point is not considered recent in backup:coverage.").Default("7").Int()
+	keyPairMaxAgeDays              = kingpin.Flag("key-pair-max-age-days", "Age in days after which a key pair is reported as ancient in ec2:key-pair-audit.").Default("365").Int()
+	keyPairSharedInstances         = kingpin.Flag("key-pair-shared-instances", "Number of instances from which a key pair is reported as shared in ec2:key-pair-audit.").Default("5").Int()
+	ssmInventory                   = kingpin.Flag("ssm-inventory", "Add the SSM inventory (agent, OS and applications) to EC2 instances.").Default("false").Bool()
+	includeAWSManaged              = kingpin.Flag("include-aws-managed", "Add the AWS managed policies attached to users, groups or roles to iam:policies.").Default("false").Bool()
+	securityHubSeverities          = kingpin.Flag("securityhub-severity", "Only report the Security Hub findings with this severity label (e.g. CRITICAL) in securityhub:findings. Can be repeated.").Strings()
+	relationships                  = kingpin.Flag("relationships", "Add the relationships between the resources derived from their metadata (e.g. instance to security group) as aws-dump relationship resources.").Default("false").Bool()
+	rightsizing                    = kingpin.Flag("rightsizing", "Add the 14 days CPU and memory utilisation to EC2 and RDS instances and report under-utilised ones.").Default("false").Bool()
+	monthlyCost                    = kingpin.Flag("monthly-cost", "Add the estimated monthly on-demand cost to EC2 instances, NAT gateways, load balancers and RDS instances.").Default("false").Bool()
+	costExplorerTag                = kingpin.Flag("cost-explorer-tag", "Add the actual cost of the last month of the resources with the same value of this cost allocation tag from Cost Explorer with --monthly-cost.").String()
+	metricsFile                    = kingpin.Flag("metrics-file", "Write the Prometheus metrics of the run to this file, e.g. for the textfile collector of the node exporter.").String()
+	metricsPushgateway             = kingpin.Flag("metrics-pushgateway", "Push the Prometheus metrics of the run to the Pushgateway at this URL.").String()
+	metricsJob                     = kingpin.Flag("metrics-job", "Job of the metrics pushed to the Pushgateway.").Default("aws-dump").String()
+	progress                       = kingpin.Flag("progress", "Print the progress of the reports to stderr, auto prints text when stderr is a terminal.").Default(ProgressAuto).Enum(ProgressAuto, ProgressNone, ProgressText, ProgressJSON)
+	concurrency                    = kingpin.Flag("concurrency", "Number of reports to run at the same time.").Default("10").Int()
+	serviceConcurrency             = kingpin.Flag("service-concurrency", "Maximum number of reports of a service to run at the same time, e.g. iam=2. Can be repeated.").StringMap()
+	maxRetries                     = kingpin.Flag("max-retries", "Number of retries of each API call, with exponential backoff.").Default("3").Int()
+	partialResults                 = kingpin.Flag("partial-results", "Keep the resources of the reports that failed and add their errors to the output instead of only logging them.").Default("false").Bool()
+	summaryMode                    = kingpin.Flag("summary", "Add the number of resources per account, region, service and type to the output as aws-dump summary resources, only outputs them with only.").Default(SummaryNone).Enum(SummaryNone, SummaryAppend, SummaryOnly)
+	estimateCost                   = kingpin.Flag("estimate-cost", "Log the estimated cost of the API calls of the dump and add it to the output as an aws-dump cost-estimate resource.").Default("false").Bool()
+	requiredTags                   = kingpin.Flag("required-tag", "Report the resources without this tag as aws-dump tag-violation resources, key or key=value1,value2 for the allowed values. Can be repeated.").Strings()
+	ownerTag                       = kingpin.Flag("owner-tag", "Tag of the owner of the resources used to group the tag violations.").String()
+	onlyTagViolations              = kingpin.Flag("only-tag-violations", "Only output the tag violations and compliance of --required-tag instead of the resources.").Default("false").Bool()
+	gateFilename                   = kingpin.Flag("gate", "Configuration file of the gate evaluated at the end of the dump, exits with 3 when it fails.").ExistingFile()
+	gateResultFilename             = kingpin.Flag("gate-result", "Write the result of the gate as JSON to this file.").String()
+	rateLimits                     = kingpin.Flag("rate-limit", "Maximum number of requests per second to a service in each account, e.g. iam=5. Can be repeated.").StringMap()
+	defaultRateLimit               = kingpin.Flag("default-rate-limit", "Maximum number of requests per second to the services without --rate-limit in each account, 0 for no limit.").Default("0").Float64()
+	maxOutputSize                  = kingpin.Flag("max-output-size", "Truncate the output above this size (e.g. 5MB). Truncated resources and a truncation marker are included in the output.").Bytes()
+	dynamoDBTable                  = kingpin.Flag("dynamodb-table", "Upsert the resources in this DynamoDB table as they are collected instead of writing them to the output.").String()
+	dynamoDBTTLDays                = kingpin.Flag("dynamodb-ttl-days", "Set expires_at on the DynamoDB items to this number of days after the dump, for the resources not seen again to expire.").Default("0").Int()
+	openSearchURL                  = kingpin.Flag("opensearch-url", "Index the resources in the OpenSearch or Elasticsearch cluster at this URL as they are collected instead of writing them to the output.").String()
+	openSearchIndex                = kingpin.Flag("opensearch-index", "Prefix of the OpenSearch indexes.").Default("aws-dump").String()
+	openSearchIndexPer             = kingpin.Flag("opensearch-index-per", "Index the resources in an index per day or per service.").Default("day").Enum(OpenSearchIndexPerDay, OpenSearchIndexPerService)
+	openSearchAWSAuth              = kingpin.Flag("opensearch-aws-auth", "Sign the OpenSearch requests with the AWS credentials, for Amazon OpenSearch Service domains.").Default("false").Bool()
+	neo4jURL                       = kingpin.Flag("neo4j-url", "Merge the resources and their relationships in the Neo4j server at this URL, using its HTTP API, as they are collected instead of writing them to the output.").String()
+	neo4jDatabase                  = kingpin.Flag("neo4j-database", "Neo4j database to merge the resources in.").Default("neo4j").String()
+	s3Bucket                       = kingpin.Flag("s3-bucket", "Upload the dump to this S3 bucket at the end of the run.").String()
+	s3Prefix                       = kingpin.Flag("s3-prefix", "Prefix of the dump in the S3 bucket, followed by the date and the account.").String()
+	s3KMSKeyID                     = kingpin.Flag("s3-kms-key-id", "KMS key to encrypt the dump uploaded to S3 with.").String()
+	incrementalFrom                = kingpin.Flag("incremental-from", "Previous dump in the json or jsonl output format. The details of the resources that didn't change since are reused where the listing APIs allow it and the resources with the same metadata are marked unchanged.").String()
+	recordFixtures                 = kingpin.Flag("record-fixtures", "Record the API calls and responses to this directory, to be used as test fixtures.").String()
 	listReports                    = kingpin.Flag("list-reports", "Prints the list of available reports and exits.").Default("false").Bool()
+	dryRun                         = kingpin.Flag("dry-run", "Print the accounts, regions and reports of the dump and the IAM actions they need without calling AWS, and exit.").Default("false").Bool()
+	dryRunFormat                   = kingpin.Flag("dry-run-format", "Format of --dry-run, policy only prints the IAM policy of the role of the dump.").Default(DryRunFormatText).Enum(DryRunFormatText, DryRunFormatJSON, DryRunFormatPolicy)
 	startAsLambda                  = kingpin.Flag("start-as-lambda", "Start as lambda.").Default("false").Bool()
+
+	dumpCommand      = kingpin.Command("dump", "Dump the resources.").Default()
+	diffCommand      = kingpin.Command("diff", "Report the resources added, removed and changed between two dumps.")
+	diffOld          = diffCommand.Arg("old", "Previous dump, in the json or jsonl output format.").Required().ExistingFile()
+	diffNew          = diffCommand.Arg("new", "Current dump, in the json or jsonl output format.").Required().ExistingFile()
+	diffFormat       = diffCommand.Flag("format", "Format of the differences.").Default("text").Enum("text", "json")
+	diffIgnoreFields = diffCommand.Flag("ignore-field", "Don't compare the fields matching this pattern, e.g. metadata.LastModified or metadata.Tags.*. Can be repeated.").Strings()
+
+	// set by the action of the schema command, which has no arguments
+	printSchema   bool
+	schemaCommand = kingpin.Command("schema", "Print the JSON schema of the output with --output-schema v1.").Action(func(*kingpin.ParseContext) error {
+		printSchema = true
+		return nil
+	})
 )
 
 type Input struct {
-	Accounts               []*resources.Account `json:"accounts"`
-	TerraformBackendConfig *TerraformBackends   `json:"terraform_backend_config"`
-	OnlyUnmanaged          bool                 `json:"only_unmanaged"`
-	Reports                []string             `json:"reports"`
+	Accounts               []*resources.Account          `json:"accounts"`
+	Organization           *resources.OrganizationConfig `json:"organization"`
+	Regions                []string                      `json:"regions"`
+	TerraformBackendConfig *TerraformBackends            `json:"terraform_backend_config"`
+	Filter                 *ResourceFilter               `json:"filter"`
+	OnlyUnmanaged          bool                          `json:"only_unmanaged"`
+	TagUnmanaged           bool                          `json:"tag_unmanaged"`
+	ApplyTags              bool                          `json:"apply_tags"`
+	Profile                string                        `json:"profile"`
+	Reports                []string                      `json:"reports"`
+	Only                   []string                      `json:"only"`
+	Exclude                []string                      `json:"exclude"`
+	IncludeOptionalReports bool                          `json:"include_optional_reports"`
+	SSMInventory           bool                          `json:"ssm_inventory"`
+	BackupCoverageDays     int                           `json:"backup_coverage_days"`
+	KeyPairMaxAgeDays      int                           `json:"key_pair_max_age_days"`
+	KeyPairSharedInstances int                           `json:"key_pair_shared_instances"`
+	Rightsizing            bool                          `json:"rightsizing"`
+	MonthlyCost            bool                          `json:"monthly_cost"`
+	CostExplorerTag        string                        `json:"cost_explorer_tag"`
+	Relationships          bool                          `json:"relationships"`
+	SecurityHubSeverities  []string                      `json:"securityhub_severities"`
+	IncludeAWSManaged      bool                          `json:"include_aws_managed"`
+	MaxOutputSize          int64                         `json:"max_output_size"`
+	Progress               string                        `json:"progress"`
+	MetricsFile            string                        `json:"metrics_file"`
+	MetricsPushgateway     string                        `json:"metrics_pushgateway"`
+	MetricsJob             string                        `json:"metrics_job"`
+	EstimateCost           bool                          `json:"estimate_cost"`
+	Summary                string                        `json:"summary"`
+	Gate                   *Gate                         `json:"gate"`
+	TagPolicy              *TagPolicy                    `json:"tag_policy"`
+	Concurrency            int                           `json:"concurrency"`
+	ServiceConcurrency     map[string]int                `json:"service_concurrency"`
+	RateLimits             map[string]float64            `json:"rate_limits"`
+	MaxRetries             int                           `json:"max_retries"`
+	PartialResults         bool                          `json:"partial_results"`
+	DefaultRateLimit       float64                       `json:"default_rate_limit"`
+	DynamoDBTable          string                        `json:"dynamodb_table"`
+	DynamoDBTTLDays        int                           `json:"dynamodb_ttl_days"`
+	OpenSearchURL          string                        `json:"opensearch_url"`
+	OpenSearchIndex        string                        `json:"opensearch_index"`
+	OpenSearchIndexPer     string                        `json:"opensearch_index_per"`
+	OpenSearchAWSAuth      bool                          `json:"opensearch_aws_auth"`
+	Neo4jURL               string                        `json:"neo4j_url"`
+	Neo4jDatabase          string                        `json:"neo4j_database"`
+	PreviousDump           string                        `json:"previous_dump"`
 }
 
 type Output struct {
-	Resources []resources.Resource `json:"resources"`
+	Resources  []resources.Resource  `json:"resources"`
+	AccountIDs []string              `json:"account_ids"`
+	Regions    []string              `json:"regions"`
+	StartedAt  time.Time             `json:"started_at"`
+	FinishedAt time.Time             `json:"finished_at"`
+	Errors     []*resources.JobError `json:"errors,omitempty"`
+	// CostEstimate is the estimated cost of the dump itself
+	CostEstimate *CostEstimate `json:"cost_estimate,omitempty"`
+	// Gate is the result of the gate of the input
+	Gate *GateResult `json:"gate,omitempty"`
 }
 
 func Handler() func(ctx context.Context, event Input) (*Output, error) {
 	return func(ctx context.Context, event Input) (*Output, error) {
-		output := &Output{}
+		sess := session.Must(session.NewSession())
+		resources.APICalls.Attach(&sess.Handlers)
+		sink, err := NewSink(event, sess, sess.Config)
+		if err != nil {
+			return nil, err
+		}
+		if sink != nil {
+			return DumpToSink(event, sink)
+		}
+		return Dump(event, nil)
+	}
+}
 
-		err := resources.OpenSessions(event.Accounts)
+// Dump runs the reports of the event. With stream, each resource is passed to
+// stream as soon as its report completes instead of being returned in the
+// output, which is not supported by the options that need all the resources.
+func Dump(event Input, stream func(resources.Resource) error) (*Output, error) {
+	startedAt := time.Now()
+	output := &Output{Resources: []resources.Resource{}, AccountIDs: []string{}, Regions: []string{}, StartedAt: startedAt}
+	resources.APICalls.Reset()
+
+	if event.TagUnmanaged && event.TerraformBackendConfig == nil {
+		return nil, fmt.Errorf("tagging unmanaged resources requires terraform backends")
+	}
+
+	if stream != nil && (event.SSMInventory || event.Rightsizing || event.MonthlyCost || event.MaxOutputSize > 0) {
+		return nil, fmt.Errorf("SSM inventory, rightsizing, monthly cost and the maximum output size are not supported with streaming output")
+	}
+
+	if event.Organization != nil {
+		if len(event.Organization.Regions) == 0 {
+			event.Organization.Regions = event.Regions
+		}
+		accounts, err := resources.NewAccountsFromOrganization(event.Organization)
 		if err != nil {
 			return nil, err
 		}
+		event.Accounts = append(event.Accounts, accounts...)
+	}
 
-		services := resources.AllServices()
+	if len(event.Regions) > 0 {
+		for _, account := range event.Accounts {
+			account.Regions = event.Regions
+		}
+	}
 
-		jobs := []resources.Job{}
+	resources.RateLimits = resources.NewRateLimiter(event.DefaultRateLimit, event.RateLimits)
+	resources.MaxRetries = event.MaxRetries
+	resources.PartialResults = event.PartialResults
 
-		if len(event.Reports) == 0 {
-			for _, service := range services {
-				for _, account := range event.Accounts {
-					newJobs, err := service.GenerateAllJobs(account)
-					common.FatalOnErrorW(err, "failed to generate jobs")
-					jobs = append(jobs, newJobs...)
-				}
+	err := resources.OpenSessions(event.Accounts)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := map[string]bool{}
+	seenRegions := map[string]bool{}
+	for _, account := range event.Accounts {
+		for _, accountSession := range account.Sessions {
+			if !seen[accountSession.AccountID] {
+				seen[accountSession.AccountID] = true
+				output.AccountIDs = append(output.AccountIDs, accountSession.AccountID)
 			}
-		} else {
-			for _, name := range event.Reports {
+			if region := *accountSession.Config.Region; !seenRegions[region] {
+				seenRegions[region] = true
+				output.Regions = append(output.Regions, region)
+			}
+		}
+	}
+	sort.Strings(output.AccountIDs)
+	sort.Strings(output.Regions)
 
-				parts := strings.Split(name, ":")
-				if len(parts) != 2 {
-					common.Fatalln(fmt.Sprintf("Invalid report format %s, should be service:resource", name))
-				}
+	if event.BackupCoverageDays > 0 {
+		resources.BackupCoverageMaxAge = time.Duration(event.BackupCoverageDays) * 24 * time.Hour
+	}
+
+	if event.KeyPairMaxAgeDays > 0 {
+		resources.KeyPairMaxAge = time.Duration(event.KeyPairMaxAgeDays) * 24 * time.Hour
+	}
+	if event.KeyPairSharedInstances > 0 {
+		resources.KeyPairSharedInstances = event.KeyPairSharedInstances
+	}
+
+	resources.IAMIncludeAWSManagedPolicies = event.IncludeAWSManaged
+
+	if len(event.SecurityHubSeverities) > 0 {
+		resources.SecurityHubFindingSeverities = event.SecurityHubSeverities
+	}
+
+	if event.PreviousDump != "" {
+		previous, err := LoadDump(event.PreviousDump)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load the previous dump: %s", err)
+		}
+		resources.Previous = resources.NewPreviousDump(previous)
+	}
+
+	services := resources.AllServices()
+
+	selected, err := SelectReports(&event, services)
+	if err != nil {
+		return nil, err
+	}
+
+	jobs := []resources.Job{}
+	gateStats := &GateStats{Services: map[string]bool{}}
+	for _, name := range selected {
+		parts := strings.Split(name, ":")
+		gateStats.Services[parts[0]] = true
+		service := services[parts[0]]
+		for _, account := range event.Accounts {
+			newJobs, err := service.GenerateJobs(account, parts[1])
+			common.FatalOnErrorW(err, "failed to generate jobs")
+			jobs = append(jobs, newJobs...)
+		}
+	}
+
+	var managed ResourceMap
+	var comparison *ComparisonFilter
+	if event.TerraformBackendConfig != nil {
+		if options := event.TerraformBackendConfig.Options; options != nil && options.Comparison != nil {
+			err := options.Comparison.Verify()
+			common.FatalOnErrorW(err, "invalid terraform comparison")
+			comparison = options.Comparison
+		}
+
+		err := event.TerraformBackendConfig.Pull()
+		common.FatalOnErrorW(err, "failed to pull terraform state files")
+
+		managed, err = event.TerraformBackendConfig.Load()
+		common.FatalOnErrorW(err, "failed to load terraform state files")
+	}
+
+	// annotate sets ManagedBy, counts the resources in the summary and the
+	// gate stats and returns false for the resources to drop
+	resourceSummary := NewSummary(managed != nil)
+	var tagCompliance *TagCompliance
+	if event.TagPolicy != nil {
+		tagCompliance = NewTagCompliance(event.TagPolicy)
+	}
+	count := func(resource resources.Resource, status string) {
+		resourceSummary.Add(resource, status)
+		gateStats.Add(resource, status)
+		if tagCompliance != nil {
+			tagCompliance.Add(resource)
+		}
+	}
+	// the resources are only counted with --summary only and
+	// --only-tag-violations
+	skipResources := event.Summary == SummaryOnly || (event.TagPolicy != nil && event.TagPolicy.OnlyViolations)
+	unmanaged := []resources.Resource{}
+	unchanged := 0
+	annotate := func(resource *resources.Resource) bool {
+		if !event.Filter.Matches(*resource) {
+			return false
+		}
+
+		resource.Unchanged = resources.Previous.Unchanged(*resource)
+		if resource.Unchanged {
+			unchanged++
+		}
+
+		if managed == nil {
+			count(*resource, "")
+			return true
+		}
 
-				service, ok := services[parts[0]]
-				if !ok {
-					common.Fatalln(fmt.Sprintf("Invalid service %s", parts[0]))
+		s3Path, isManaged := managed[resource.UniqueID()]
+		if !isManaged {
+			// the resources not compared are not reported as unmanaged
+			if !comparison.Compared(*resource) {
+				if event.OnlyUnmanaged {
+					return false
 				}
+				count(*resource, "")
+				return true
+			}
+			if event.TagUnmanaged {
+				unmanaged = append(unmanaged, *resource)
+			}
+			count(*resource, SummaryUnmanaged)
+			return true
+		}
+		if event.OnlyUnmanaged {
+			return false
+		}
+		resource.ManagedBy = map[string]string{
+			"type":  "terraform",
+			"state": s3Path,
+		}
+		count(*resource, SummaryManaged)
+		return true
+	}
 
-				for _, account := range event.Accounts {
-					newJobs, err := service.GenerateJobs(account, parts[1])
-					common.FatalOnErrorW(err, "failed to generate jobs")
-					jobs = append(jobs, newJobs...)
+	runOptions := resources.RunOptions{
+		Concurrency:        event.Concurrency,
+		ServiceConcurrency: event.ServiceConcurrency,
+		PartialResults:     event.PartialResults,
+	}
+
+	var streamErr error
+	if stream != nil {
+		runOptions.Sink = func(result []resources.Resource) {
+			for _, resource := range result {
+				if streamErr != nil || !annotate(&resource) || skipResources {
+					continue
+				}
+				streamErr = stream(resource)
+				if event.Relationships {
+					for _, relationship := range resources.Relationships(resource) {
+						if streamErr == nil {
+							streamErr = stream(relationship.Resource(resource))
+						}
+					}
 				}
 			}
 		}
+	}
 
-		result, errors := resources.Run(jobs)
+	metrics := NewDumpMetrics()
+	var printer *ProgressPrinter
+	if event.Progress == ProgressText || event.Progress == ProgressJSON {
+		printer = NewProgressPrinter(event.Progress, os.Stderr)
+		printer.Start(len(jobs))
+	}
+	runOptions.Progress = func(progress resources.JobProgress) {
+		metrics.Add(progress)
+		if printer != nil {
+			printer.Report(progress)
+		}
+	}
 
-		if event.TerraformBackendConfig != nil {
+	result, errors := resources.Run(jobs, runOptions)
+	if printer != nil {
+		printer.Done()
+	}
+	if streamErr != nil {
+		return nil, streamErr
+	}
 
-			err := event.TerraformBackendConfig.Pull()
-			common.FatalOnErrorW(err, "failed to pull terraform state files")
+	if event.SSMInventory {
+		errors = append(errors, stepErrors("ssm-inventory", resources.AttachSSMInventory(event.Accounts, result))...)
+	}
 
-			managed, err := event.TerraformBackendConfig.Load()
-			common.FatalOnErrorW(err, "failed to load terraform state files")
+	if event.Rightsizing {
+		findings, rightsizingErrors := resources.AttachRightsizing(event.Accounts, result)
+		result = append(result, findings...)
+		errors = append(errors, stepErrors("rightsizing", rightsizingErrors)...)
+	}
 
-			for _, resource := range result {
-				s3Path, managed := managed[resource.UniqueID()]
-				if managed {
-					if event.OnlyUnmanaged {
-						continue
-					}
-					resource.ManagedBy = map[string]string{
-						"type":  "terraform",
-						"state": s3Path,
-					}
+	if event.MonthlyCost {
+		errors = append(errors, stepErrors("monthly-cost", resources.AttachMonthlyCost(event.Accounts, result, event.CostExplorerTag))...)
+	}
+
+	for _, resource := range result {
+		if !annotate(&resource) || skipResources {
+			continue
+		}
+		output.Resources = append(output.Resources, resource)
+		if event.Relationships {
+			for _, relationship := range resources.Relationships(resource) {
+				output.Resources = append(output.Resources, relationship.Resource(resource))
+			}
+		}
+	}
+
+	if event.TagUnmanaged {
+		tagged, tagErrors := resources.TagUnmanaged(event.Accounts, unmanaged, startedAt, !event.ApplyTags)
+		message := "Tagged unmanaged resource"
+		if !event.ApplyTags {
+			message = "Would tag unmanaged resource"
+		}
+		for _, arn := range tagged {
+			log.WithField("arn", arn).Warn(message)
+		}
+		errors = append(errors, stepErrors("tag-unmanaged", tagErrors)...)
+	}
+
+	if event.MaxOutputSize > 0 {
+		truncated, summary := resources.Truncate(output.Resources, event.MaxOutputSize)
+		if summary != nil {
+			output.Resources = truncated
+			log.WithFields(log.Fields{
+				"max_output_size":   summary.MaxOutputSize,
+				"original_size":     summary.OriginalSize,
+				"truncated_keys":    summary.TruncatedKeys,
+				"stripped_metadata": summary.StrippedMetadata,
+				"dropped_resources": summary.DroppedResources,
+			}).Warn("Output truncated")
+		}
+	}
+
+	for _, err := range errors {
+		jobError, ok := err.(*resources.JobError)
+		if !ok {
+			jobError = &resources.JobError{Service: "aws-dump", Message: err.Error()}
+		}
+		output.Errors = append(output.Errors, jobError)
+	}
+
+	// the summary, the tag compliance and the errors are added to the output
+	// after the resources, they are never truncated
+	if event.Summary == SummaryAppend || event.Summary == SummaryOnly {
+		for _, resource := range resourceSummary.Resources() {
+			if stream != nil {
+				if err := stream(resource); err != nil {
+					return nil, err
 				}
-				output.Resources = append(output.Resources, resource)
+				continue
+			}
+			output.Resources = append(output.Resources, resource)
+		}
+	}
+
+	if tagCompliance != nil {
+		for _, resource := range tagCompliance.Resources() {
+			if stream != nil {
+				if err := stream(resource); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			output.Resources = append(output.Resources, resource)
+		}
+	}
+
+	if event.PartialResults {
+		for _, jobError := range output.Errors {
+			if stream != nil {
+				if err := stream(jobError.Resource()); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			output.Resources = append(output.Resources, jobError.Resource())
+		}
+	}
+
+	if event.Gate != nil {
+		gateStats.Errors = output.Errors
+		output.Gate = event.Gate.Evaluate(gateStats)
+		for _, check := range output.Gate.Checks {
+			if !check.Passed {
+				log.WithFields(log.Fields{
+					"check":   check.Name,
+					"limit":   check.Limit,
+					"value":   check.Value,
+					"details": check.Details,
+				}).Warn("Gate check failed")
 			}
+		}
+	}
+
+	if throttles := resources.RateLimits.Throttles(); throttles > 0 {
+		log.WithField("throttles", throttles).Warn("Requests throttled, consider lowering --rate-limit")
+	}
+
+	if resources.Previous != nil {
+		log.WithFields(log.Fields{
+			"unchanged": unchanged,
+			"reused":    resources.Previous.Reused(),
+		}).Info("Incremental dump")
+	}
+
+	for _, err := range errors {
+		log.Error(err)
+	}
 
+	finished := time.Now()
+	output.FinishedAt = finished
+	output.CostEstimate = EstimateCost(resources.APICalls.Calls(), resources.APICalls.Units(), APIPrices)
+	if memory, err := strconv.Atoi(os.Getenv("AWS_LAMBDA_FUNCTION_MEMORY_SIZE")); err == nil && RunningInLambda() {
+		output.CostEstimate.AddLambda(finished.Sub(startedAt), memory)
+	}
+	if event.EstimateCost {
+		for _, item := range output.CostEstimate.Items {
+			log.WithFields(log.Fields{
+				"service":   item.Service,
+				"operation": item.Operation,
+				"units":     item.Units,
+				"cost":      fmt.Sprintf("%.4f", item.Cost),
+			}).Warn("Estimated cost")
+		}
+		log.WithFields(log.Fields{
+			"calls": output.CostEstimate.Calls,
+			"total": fmt.Sprintf("%.4f", output.CostEstimate.Total),
+		}).Warn("Estimated cost of the dump in USD")
+
+		if stream != nil {
+			if err := stream(output.CostEstimate.Resource()); err != nil {
+				return nil, err
+			}
 		} else {
-			output.Resources = result
+			output.Resources = append(output.Resources, output.CostEstimate.Resource())
 		}
+	}
 
-		for _, err := range errors {
-			log.Error(err)
+	if event.MetricsFile != "" || event.MetricsPushgateway != "" {
+		metrics.Throttles = resources.RateLimits.Throttles()
+		metrics.APICalls = output.CostEstimate.Calls
+		metrics.EstimatedCost = output.CostEstimate.Total
+		metrics.Finished = finished
+		metrics.Duration = metrics.Finished.Sub(startedAt)
+
+		// the dump is still returned when the metrics can't be written
+		if event.MetricsFile != "" {
+			if err := metrics.WriteFile(event.MetricsFile); err != nil {
+				log.WithError(err).Error("Failed to write the metrics")
+			}
 		}
+		if event.MetricsPushgateway != "" {
+			if err := metrics.Push(event.MetricsPushgateway, event.MetricsJob); err != nil {
+				log.WithError(err).Error("Failed to push the metrics")
+			}
+		}
+	}
+
+	return output, nil
+}
 
-		return output, nil
+// gateExitCode writes the result of the gate to --gate-result and returns
+// the exit code of the dump.
+func gateExitCode(result *GateResult) int {
+	if result == nil {
+		return 0
 	}
+	if *gateResultFilename != "" {
+		err := result.WriteFile(*gateResultFilename)
+		common.FatalOnErrorW(err, "failed to write the gate result")
+	}
+	if !result.Passed {
+		return GateExitCode
+	}
+	return 0
+}
+
+// stepErrors returns the errors of a step of the dump run after the reports.
+func stepErrors(step string, errs []error) []error {
+	result := []error{}
+	for _, err := range errs {
+		result = append(result, &resources.JobError{Service: "aws-dump", Report: step, Message: err.Error()})
+	}
+	return result
 }
 
 func RunningInLambda() bool {
@@ -120,46 +625,354 @@ func RunningInLambda() bool {
 	return strings.HasPrefix(os.Getenv("AWS_EXECUTION_ENV"), "AWS_Lambda_")
 }
 
+func writeShareBundle(writer io.Writer, resourceList []resources.Resource) {
+	options := ShareBundleOptions{
+		RedactionRules: DefaultRedactionRules,
+		Pseudonyms:     Pseudonyms{},
+		CreatedAt:      time.Now(),
+	}
+
+	if *redactionRulesFilename != "" {
+		rules, err := NewRedactionRulesFromFile(*redactionRulesFilename)
+		common.FatalOnErrorW(err, "failed to load the redaction rules")
+		options.RedactionRules = rules
+	}
+
+	if *pseudonymMappingFilename != "" {
+		pseudonyms, err := NewPseudonymsFromFile(*pseudonymMappingFilename)
+		common.FatalOnErrorW(err, "failed to load the pseudonym mapping")
+		options.Pseudonyms = pseudonyms
+	}
+	options.Pseudonyms.AddAccounts(resourceList)
+
+	manifest, err := WriteShareBundle(writer, resourceList, options)
+	common.FatalOnErrorW(err, "failed to write the share bundle")
+
+	if *pseudonymMappingFilename != "" {
+		err = options.Pseudonyms.Save(*pseudonymMappingFilename)
+		common.FatalOnErrorW(err, "failed to save the pseudonym mapping")
+	}
+
+	log.WithFields(log.Fields{
+		"resources":                 manifest.Resources,
+		"redacted_values":           manifest.RedactedValues,
+		"pseudonymised_identifiers": manifest.PseudonymisedIdentifiers,
+	}).Info("Share bundle written")
+}
+
+// writeOutput writes the dump in the output format and returns the ids of
+// the accounts dumped and the extension of the output.
+func writeOutput(writer io.Writer, handler func(context.Context, Input) (*Output, error), input Input) (*Output, string) {
+	if *outputFormat == "jsonl" {
+		encoder := json.NewEncoder(writer)
+		output, err := Dump(input, func(resource resources.Resource) error {
+			return encoder.Encode(resource)
+		})
+		common.FatalOnErrorW(err, "dump failed")
+		return output, "jsonl"
+	}
+
+	output, err := handler(context.Background(), input)
+	common.FatalOnErrorW(err, "handler failed")
+
+	if *shareBundle {
+		writeShareBundle(writer, output.Resources)
+		return output, "tar.gz"
+	}
+
+	if *outputFormat == "csv" {
+		err = WriteCSV(writer, output.Resources, *csvColumns)
+		common.FatalOnErrorW(err, "failed to write the report")
+		return output, "csv"
+	}
+
+	if *outputFormat == "dot" {
+		err = WriteDOT(writer, output.Resources)
+		common.FatalOnErrorW(err, "failed to write the graph")
+		return output, "dot"
+	}
+
+	if *outputFormat == "cypher" {
+		err = WriteCypher(writer, output.Resources, time.Now())
+		common.FatalOnErrorW(err, "failed to write the graph")
+		return output, "cypher"
+	}
+
+	var report interface{} = output.Resources
+	if *outputSchema == OutputSchemaV1 {
+		report = NewVersionedOutput(output)
+	}
+	reportJSON, err := json.MarshalIndent(report, "", "  ")
+	common.FatalOnErrorW(err, "failed to serialise the report")
+
+	_, err = writer.Write(reportJSON)
+	common.FatalOnErrorW(err, "failed to write the report")
+	return output, "json"
+}
+
+func diff() {
+	oldResources, err := LoadDump(*diffOld)
+	common.FatalOnErrorW(err, "failed to load the previous dump")
+	newResources, err := LoadDump(*diffNew)
+	common.FatalOnErrorW(err, "failed to load the current dump")
+
+	diffs := DiffResources(oldResources, newResources, *diffIgnoreFields)
+	if *diffFormat == "json" {
+		data, err := json.MarshalIndent(diffs, "", "  ")
+		common.FatalOnErrorW(err, "failed to serialise the differences")
+		fmt.Println(string(data))
+		return
+	}
+
+	err = WriteDiff(os.Stdout, diffs)
+	common.FatalOnErrorW(err, "failed to write the differences")
+}
+
 func main() {
 	kingpin.CommandLine.Name = "aws-dump"
 	kingpin.CommandLine.Help = "Dump AWS resources"
-	common.HandleFlags()
+	flags := common.HandleFlags()
 
 	handler := Handler()
 
 	if RunningInLambda() {
 		lambda.Start(handler)
 	} else {
+		// the exit code of the gate is used after the deferred cleanups of
+		// the output files
+		exitCode := 0
+		defer func() {
+			if exitCode != 0 {
+				os.Exit(exitCode)
+			}
+		}()
+
+		if printSchema {
+			fmt.Print(OutputSchema)
+			return
+		}
+
+		// the arguments of diff are only set when it is the command used
+		if *diffOld != "" {
+			diff()
+			return
+		}
+
 		if *listReports {
+			if *profile != "" {
+				selected, err := ProfileReports(*profile)
+				common.FatalOnErrorW(err, "invalid profile")
+				for _, report := range selected {
+					fmt.Println(report)
+				}
+				os.Exit(0)
+			}
 			for _, report := range resources.AllReports() {
 				fmt.Println(report)
 			}
 			os.Exit(0)
 		}
 
-		accounts, err := resources.NewAccountsFromFile(*accountsConfigFilename)
-		common.FatalOnErrorW(err, "failed to load accounts from file")
+		if *recordFixtures != "" {
+			resources.RecordFixtures = resources.NewFixtureRecorder(*recordFixtures)
+		}
+
+		accounts := []*resources.Account{}
+		if *accountsConfigFilename != "" {
+			var err error
+			accounts, err = resources.NewAccountsFromFile(*accountsConfigFilename)
+			common.FatalOnErrorW(err, "failed to load accounts from file")
+		}
 
 		input := Input{
-			Accounts:      accounts,
-			Reports:       *reports,
-			OnlyUnmanaged: *onlyUnmanaged,
+			Accounts:               accounts,
+			Regions:                []string{},
+			Profile:                *profile,
+			Reports:                *reports,
+			Only:                   *onlyReports,
+			Exclude:                *excludeReports,
+			OnlyUnmanaged:          *onlyUnmanaged,
+			TagUnmanaged:           *tagUnmanaged,
+			ApplyTags:              *applyTags,
+			IncludeOptionalReports: *includeOptionalReports,
+			SSMInventory:           *ssmInventory,
+			BackupCoverageDays:     *backupCoverageDays,
+			KeyPairMaxAgeDays:      *keyPairMaxAgeDays,
+			KeyPairSharedInstances: *keyPairSharedInstances,
+			Rightsizing:            *rightsizing,
+			MonthlyCost:            *monthlyCost,
+			CostExplorerTag:        *costExplorerTag,
+			Relationships:          *relationships,
+			SecurityHubSeverities:  *securityHubSeverities,
+			IncludeAWSManaged:      *includeAWSManaged,
+			MaxOutputSize:          int64(*maxOutputSize),
+			Progress:               ResolveProgressFormat(*progress),
+			MetricsFile:            *metricsFile,
+			MetricsPushgateway:     *metricsPushgateway,
+			MetricsJob:             *metricsJob,
+			EstimateCost:           *estimateCost,
+			Summary:                *summaryMode,
+			Concurrency:            *concurrency,
+			ServiceConcurrency:     map[string]int{},
+			RateLimits:             map[string]float64{},
+			MaxRetries:             *maxRetries,
+			PartialResults:         *partialResults,
+			DefaultRateLimit:       *defaultRateLimit,
+		}
+
+		if *organizationRoleName != "" {
+			input.Organization = &resources.OrganizationConfig{
+				RoleName:          *organizationRoleName,
+				ExcludeAccountIDs: *organizationExcludeAccountIDs,
+			}
+		} else if *accountsConfigFilename == "" {
+			common.Fatalln("--accounts-config or --organization-role-name is required")
+		}
+
+		if len(*filterTags) > 0 || len(*filterTypes) > 0 || len(*filterRegions) > 0 {
+			filter, err := NewResourceFilter(*filterTags, *filterTypes, *filterRegions)
+			common.FatalOnErrorW(err, "invalid filter")
+			input.Filter = filter
+		}
+
+		for _, value := range *regions {
+			for _, region := range strings.Split(value, ",") {
+				if region = strings.TrimSpace(region); region != "" {
+					input.Regions = append(input.Regions, region)
+				}
+			}
+		}
+
+		for service, value := range *serviceConcurrency {
+			limit, err := strconv.Atoi(value)
+			if err != nil {
+				common.Fatalln(fmt.Sprintf("Invalid --service-concurrency %s=%s, should be service=number", service, value))
+			}
+			input.ServiceConcurrency[service] = limit
+		}
+
+		for service, value := range *rateLimits {
+			limit, err := strconv.ParseFloat(value, 64)
+			if err != nil || limit <= 0 {
+				common.Fatalln(fmt.Sprintf("Invalid --rate-limit %s=%s, should be service=requests per second", service, value))
+			}
+			input.RateLimits[service] = limit
 		}
 
 		if *terraformBackendConfigFilename != "" {
 			backends, err := NewTerraformBackendsFromFile(*terraformBackendConfigFilename)
 			common.FatalOnErrorW(err, "failed to load terraform backends from file")
 
+			if len(*terraformIncludeTypes) > 0 || len(*terraformExcludeTypes) > 0 {
+				if backends.Options == nil {
+					backends.Options = &Options{PathSubstitutions: []Substitution{}}
+				}
+				if backends.Options.Comparison == nil {
+					backends.Options.Comparison = &ComparisonFilter{}
+				}
+				for _, resourceType := range *terraformIncludeTypes {
+					backends.Options.Comparison.Include = append(backends.Options.Comparison.Include, &ComparisonRule{Type: resourceType})
+				}
+				for _, resourceType := range *terraformExcludeTypes {
+					backends.Options.Comparison.Exclude = append(backends.Options.Comparison.Exclude, &ComparisonRule{Type: resourceType})
+				}
+			}
+
 			input.TerraformBackendConfig = backends
 		}
 
-		output, err := handler(context.Background(), input)
-		common.FatalOnErrorW(err, "handler failed")
+		input.DynamoDBTable = *dynamoDBTable
+		input.DynamoDBTTLDays = *dynamoDBTTLDays
+		input.OpenSearchURL = *openSearchURL
+		input.OpenSearchIndex = *openSearchIndex
+		input.OpenSearchIndexPer = *openSearchIndexPer
+		input.OpenSearchAWSAuth = *openSearchAWSAuth
+		input.Neo4jURL = *neo4jURL
+		input.Neo4jDatabase = *neo4jDatabase
+		input.PreviousDump = *incrementalFrom
+
+		if len(*requiredTags) > 0 {
+			policy, err := NewTagPolicy(*requiredTags, *ownerTag, *onlyTagViolations)
+			common.FatalOnErrorW(err, "invalid tag policy")
+			input.TagPolicy = policy
+		} else if *onlyTagViolations {
+			common.Fatalln("--only-tag-violations requires --required-tag")
+		}
+
+		if *gateFilename != "" {
+			gate, err := NewGateFromFile(*gateFilename)
+			common.FatalOnErrorW(err, "failed to load the gate")
+			input.Gate = gate
+		}
+
+		if *dryRun {
+			plan, err := NewDryRunPlan(input)
+			common.FatalOnErrorW(err, "invalid reports")
+			err = WriteDryRun(os.Stdout, plan, *dryRunFormat)
+			common.FatalOnErrorW(err, "failed to write the dry run")
+			return
+		}
 
-		reportJSON, err := json.MarshalIndent(output.Resources, "", "  ")
-		common.FatalOnErrorW(err, "failed to serialise the report")
+		if input.DynamoDBTable != "" || input.OpenSearchURL != "" || input.Neo4jURL != "" {
+			sess, conf := common.OpenSession(flags)
+			resources.APICalls.Attach(&sess.Handlers)
+			sink, err := NewSink(input, sess, conf)
+			common.FatalOnErrorW(err, "failed to create the sink")
 
-		err = ioutil.WriteFile(*outputFilename, reportJSON, 0644)
-		common.FatalOnErrorW(err, "failed to write the report")
+			output, err := DumpToSink(input, sink)
+			common.FatalOnErrorW(err, "dump failed")
+			exitCode = gateExitCode(output.Gate)
+			return
+		}
+
+		if *shareBundle && *outputFormat != "json" {
+			common.Fatalln("--share-bundle is only supported with the json output format")
+		}
+		if *outputSchema != OutputSchemaNone && (*outputFormat != "json" || *shareBundle) {
+			common.Fatalln("--output-schema is only supported with the json output format")
+		}
+
+		var upload *S3Upload
+		filename := *outputFilename
+		if *s3Bucket != "" {
+			if filename == "-" {
+				common.Fatalln("--s3-bucket can't be used with the standard output")
+			}
+			upload = &S3Upload{
+				Bucket:   *s3Bucket,
+				Prefix:   *s3Prefix,
+				KMSKeyID: *s3KMSKeyID,
+			}
+
+			// without --output the dump is only uploaded
+			if filename == "" {
+				file, err := ioutil.TempFile("", "aws-dump-")
+				common.FatalOnErrorW(err, "failed to create the output file")
+				file.Close()
+				filename = file.Name()
+				defer os.Remove(filename)
+			}
+		}
+
+		writer := os.Stdout
+		if filename != "-" {
+			file, err := os.Create(filename)
+			common.FatalOnErrorW(err, "failed to create the output file")
+			defer file.Close()
+			writer = file
+		}
+
+		output, extension := writeOutput(writer, handler, input)
+		exitCode = gateExitCode(output.Gate)
+
+		if upload != nil {
+			sess, conf := common.OpenSession(flags)
+			uploader := s3manager.NewUploaderWithClient(s3.New(sess, conf))
+
+			key := upload.Key(output.AccountIDs, time.Now(), extension)
+			location, err := upload.Upload(uploader, filename, key, extension)
+			common.FatalOnErrorW(err, "failed to upload the dump")
+			log.WithField("location", location).Info("Dump uploaded")
+		}
 	}
 }
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hamstah/awstools/aws/dump/resources"
+)
+
+// DumpMetrics are the metrics of a dump run, written in the Prometheus text
+// format to a file for the textfile collector of the node exporter or pushed
+// to a Pushgateway.
+type DumpMetrics struct {
+	// Resources and Errors are by service
+	Resources map[string]int
+	Errors    map[string]int
+	Reports   int
+	Throttles int
+	APICalls  int64
+	// EstimatedCost is the estimated cost of the dump in USD
+	EstimatedCost float64
+	Duration      time.Duration
+	Finished      time.Time
+}
+
+func NewDumpMetrics() *DumpMetrics {
+	return &DumpMetrics{
+		Resources: map[string]int{},
+		Errors:    map[string]int{},
+	}
+}
+
+// Add counts the resources or the error of a report.
+func (m *DumpMetrics) Add(progress resources.JobProgress) {
+	m.Reports++
+	m.Resources[progress.Service] += progress.Resources
+	if progress.Error != nil {
+		m.Errors[progress.Service]++
+	}
+}
+
+func (m *DumpMetrics) errorCount() int {
+	count := 0
+	for _, errors := range m.Errors {
+		count += errors
+	}
+	return count
+}
+
+func writeMetric(w io.Writer, name, metricType, help string, values map[string]int, label string) {
+	fmt.Fprintf(w, "# HELP %s %s\n", name, help)
+	fmt.Fprintf(w, "# TYPE %s %s\n", name, metricType)
+
+	keys := []string{}
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fmt.Fprintf(w, "%s{%s=\"%s\"} %d\n", name, label, escapeLabelValue(key), values[key])
+	}
+}
+
+func escapeLabelValue(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
+
+// Write writes the metrics in the Prometheus text format.
+func (m *DumpMetrics) Write(w io.Writer) {
+	writeMetric(w, "aws_dump_resources", "gauge", "Resources collected by the last dump.", m.Resources, "service")
+	writeMetric(w, "aws_dump_report_errors", "gauge", "Reports that failed in the last dump.", m.Errors, "service")
+
+	success := 0
+	if m.errorCount() == 0 {
+		success = 1
+	}
+	for _, metric := range []struct {
+		name  string
+		help  string
+		value string
+	}{
+		{"aws_dump_reports", "Reports run by the last dump.", fmt.Sprint(m.Reports)},
+		{"aws_dump_throttles", "Throttled API calls in the last dump.", fmt.Sprint(m.Throttles)},
+		{"aws_dump_api_calls", "API calls of the last dump.", fmt.Sprint(m.APICalls)},
+		{"aws_dump_estimated_cost_dollars", "Estimated cost of the API calls of the last dump.", fmt.Sprint(m.EstimatedCost)},
+		{"aws_dump_success", "Whether all the reports of the last dump succeeded.", fmt.Sprint(success)},
+		{"aws_dump_duration_seconds", "Duration of the last dump.", fmt.Sprint(m.Duration.Seconds())},
+		{"aws_dump_last_run_timestamp_seconds", "Time the last dump finished.", fmt.Sprint(m.Finished.Unix())},
+	} {
+		fmt.Fprintf(w, "# HELP %s %s\n", metric.name, metric.help)
+		fmt.Fprintf(w, "# TYPE %s gauge\n", metric.name)
+		fmt.Fprintf(w, "%s %s\n", metric.name, metric.value)
+	}
+}
+
+// WriteFile writes the metrics to a temporary file renamed to filename, for
+// the textfile collector not to read partial files.
+func (m *DumpMetrics) WriteFile(filename string) error {
+	file, err := ioutil.TempFile(filepath.Dir(filename), ".aws-dump-metrics")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+
+	m.Write(file)
+	if err := file.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(file.Name(), 0644); err != nil {
+		return err
+	}
+	return os.Rename(file.Name(), filename)
+}
+
+// Push replaces the metrics of the job in the Pushgateway.
+func (m *DumpMetrics) Push(gateway, job string) error {
+	if job == "" {
+		job = "aws-dump"
+	}
+	buffer := &bytes.Buffer{}
+	m.Write(buffer)
+
+	pushURL := fmt.Sprintf("%s/metrics/job/%s", strings.TrimSuffix(gateway, "/"), url.PathEscape(job))
+	request, err := http.NewRequest(http.MethodPut, pushURL, buffer)
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "text/plain; version=0.0.4")
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	response, err := client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode/100 != 2 {
+		body, _ := ioutil.ReadAll(response.Body)
+		return fmt.Errorf("pushgateway returned %s: %s", response.Status, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hamstah/awstools/aws/dump/resources"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestDumpMetrics() *DumpMetrics {
+	metrics := NewDumpMetrics()
+	metrics.Add(resources.JobProgress{Service: "ec2", Resources: 10})
+	metrics.Add(resources.JobProgress{Service: "ec2", Resources: 5})
+	metrics.Add(resources.JobProgress{Service: "iam", Error: errors.New("AccessDenied")})
+	metrics.Throttles = 3
+	metrics.APICalls = 1200
+	metrics.EstimatedCost = 0.25
+	metrics.Duration = 90 * time.Second
+	metrics.Finished = time.Unix(1610000000, 0)
+	return metrics
+}
+
+const expectedMetrics = `# HELP aws_dump_resources Resources collected by the last dump.
+# TYPE aws_dump_resources gauge
+aws_dump_resources{service="ec2"} 15
+aws_dump_resources{service="iam"} 0
+# HELP aws_dump_report_errors Reports that failed in the last dump.
+# TYPE aws_dump_report_errors gauge
+aws_dump_report_errors{service="iam"} 1
+# HELP aws_dump_reports Reports run by the last dump.
+# TYPE aws_dump_reports gauge
+aws_dump_reports 3
+# HELP aws_dump_throttles Throttled API calls in the last dump.
+# TYPE aws_dump_throttles gauge
+aws_dump_throttles 3
+# HELP aws_dump_api_calls API calls of the last dump.
+# TYPE aws_dump_api_calls gauge
+aws_dump_api_calls 1200
+# HELP aws_dump_estimated_cost_dollars Estimated cost of the API calls of the last dump.
+# TYPE aws_dump_estimated_cost_dollars gauge
+aws_dump_estimated_cost_dollars 0.25
+# HELP aws_dump_success Whether all the reports of the last dump succeeded.
+# TYPE aws_dump_success gauge
+aws_dump_success 0
+# HELP aws_dump_duration_seconds Duration of the last dump.
+# TYPE aws_dump_duration_seconds gauge
+aws_dump_duration_seconds 90
+# HELP aws_dump_last_run_timestamp_seconds Time the last dump finished.
+# TYPE aws_dump_last_run_timestamp_seconds gauge
+aws_dump_last_run_timestamp_seconds 1610000000
+`
+
+func TestDumpMetricsWrite(t *testing.T) {
+	buffer := &bytes.Buffer{}
+	newTestDumpMetrics().Write(buffer)
+	require.Equal(t, expectedMetrics, buffer.String())
+
+	filename := filepath.Join(t.TempDir(), "aws-dump.prom")
+	require.NoError(t, newTestDumpMetrics().WriteFile(filename))
+	data, err := ioutil.ReadFile(filename)
+	require.NoError(t, err)
+	require.Equal(t, expectedMetrics, string(data))
+}
+
+func TestDumpMetricsPush(t *testing.T) {
+	var path, method, body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path, method = r.URL.Path, r.Method
+		data, _ := ioutil.ReadAll(r.Body)
+		body = string(data)
+	}))
+	defer server.Close()
+
+	require.NoError(t, newTestDumpMetrics().Push(server.URL+"/", "nightly dump"))
+	require.Equal(t, "/metrics/job/nightly dump", path)
+	require.Equal(t, http.MethodPut, method)
+	require.Equal(t, expectedMetrics, body)
+}
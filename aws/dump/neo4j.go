@@ -0,0 +1,396 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/hamstah/awstools/aws/dump/resources"
+)
+
+const (
+	// maximum number of nodes or relationships merged by a statement
+	neo4jBatchSize = 500
+
+	// the constraint also indexes the key for the merges
+	neo4jConstraint = "CREATE CONSTRAINT aws_resource_key IF NOT EXISTS FOR (n:Resource) REQUIRE n.key IS UNIQUE"
+)
+
+type cypherStatement struct {
+	Statement  string                 `json:"statement"`
+	Parameters map[string]interface{} `json:"parameters,omitempty"`
+}
+
+// neo4jLabel is the label of the nodes of a type of resources, e.g.
+// Ec2Instance for ec2:instance.
+func neo4jLabel(resourceType string) string {
+	label := ""
+	parts := strings.FieldsFunc(resourceType, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for _, part := range parts {
+		label += strings.ToUpper(part[:1]) + part[1:]
+	}
+	return label
+}
+
+// neo4jRelationshipType is the type of the relationships of a relation, e.g.
+// MEMBER_OF for member-of.
+func neo4jRelationshipType(relation string) string {
+	return strings.ToUpper(strings.Replace(relation, "-", "_", -1))
+}
+
+type neo4jEdge struct {
+	AccountID    string
+	Relationship resources.Relationship
+}
+
+// neo4jGraph turns the resources into statements merging them as nodes, and
+// the relationships derived from their metadata into statements merging the
+// edges once all the nodes are known.
+type neo4jGraph struct {
+	timestamp time.Time
+	index     resourceIndex
+	// rows of the nodes not merged yet, by label
+	nodes map[string][]map[string]interface{}
+	edges []neo4jEdge
+}
+
+func newNeo4jGraph(timestamp time.Time) *neo4jGraph {
+	return &neo4jGraph{
+		timestamp: timestamp,
+		index:     resourceIndex{},
+		nodes:     map[string][]map[string]interface{}{},
+	}
+}
+
+// Add adds the node of the resource and its relationships, and returns false
+// for the markers of the dump which are not part of the graph.
+func (g *neo4jGraph) Add(resource resources.Resource) (bool, error) {
+	if resource.Service == "aws-dump" {
+		return false, nil
+	}
+
+	// properties can't be maps, the metadata is stored as JSON
+	metadata, err := json.Marshal(resource.Metadata)
+	if err != nil {
+		return false, err
+	}
+
+	key := ResourceKey(resource)
+	resourceType := fmt.Sprintf("%s:%s", resource.Service, resource.Type)
+	label := neo4jLabel(resourceType)
+	g.nodes[label] = append(g.nodes[label], map[string]interface{}{
+		"key": key,
+		"properties": map[string]interface{}{
+			"id":               resource.ID,
+			"arn":              resource.ARN,
+			"service":          resource.Service,
+			"type":             resource.Type,
+			"account_id":       resource.AccountID,
+			"region":           resource.Region,
+			"name":             ResourceTags(resource)["Name"],
+			"metadata":         string(metadata),
+			"managed_by_type":  resource.ManagedBy["type"],
+			"managed_by_state": resource.ManagedBy["state"],
+			"external":         false,
+			"dumped_at":        g.timestamp.UTC().Format(time.RFC3339),
+		},
+	})
+
+	g.index.Add(resource, key)
+	for _, relationship := range resources.Relationships(resource) {
+		g.edges = append(g.edges, neo4jEdge{resource.AccountID, relationship})
+	}
+	return true, nil
+}
+
+func neo4jStatements(query string, rows []map[string]interface{}) []cypherStatement {
+	statements := []cypherStatement{}
+	for start := 0; start < len(rows); start += neo4jBatchSize {
+		end := start + neo4jBatchSize
+		if end > len(rows) {
+			end = len(rows)
+		}
+		statements = append(statements, cypherStatement{
+			Statement:  query,
+			Parameters: map[string]interface{}{"rows": rows[start:end]},
+		})
+	}
+	return statements
+}
+
+// NodeStatements returns the statements merging the nodes added since the
+// last call. The nodes of the resources referenced before being dumped are
+// updated and no longer external.
+func (g *neo4jGraph) NodeStatements() []cypherStatement {
+	labels := []string{}
+	for label := range g.nodes {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+
+	statements := []cypherStatement{}
+	for _, label := range labels {
+		query := fmt.Sprintf("UNWIND $rows AS row MERGE (n:Resource {key: row.key}) SET n += row.properties, n:%s", label)
+		statements = append(statements, neo4jStatements(query, g.nodes[label])...)
+	}
+	g.nodes = map[string][]map[string]interface{}{}
+	return statements
+}
+
+// endpoint returns the node of an end of a relationship, the resource of the
+// dump with this id or ARN, or an external node for the resources not dumped.
+func (g *neo4jGraph) endpoint(accountID, resourceType, ref string) map[string]interface{} {
+	if key, ok := g.index.Find(accountID, resourceType, ref); ok {
+		return map[string]interface{}{"key": key, "properties": map[string]interface{}{}}
+	}
+
+	parts := strings.SplitN(resourceType, ":", 2)
+	properties := map[string]interface{}{
+		"id":       ref,
+		"service":  parts[0],
+		"type":     "",
+		"external": true,
+	}
+	if len(parts) == 2 {
+		properties["type"] = parts[1]
+	}
+	if strings.HasPrefix(ref, "arn:") {
+		properties["arn"] = ref
+	}
+	return map[string]interface{}{"key": ref, "properties": properties}
+}
+
+// EdgeStatements returns the statements merging the relationships, to run
+// after the nodes are merged. The relationship types are the relations in
+// upper case, e.g. USES or MEMBER_OF.
+func (g *neo4jGraph) EdgeStatements() []cypherStatement {
+	rows := map[string][]map[string]interface{}{}
+	for _, edge := range g.edges {
+		relationship := edge.Relationship
+		relationshipType := neo4jRelationshipType(relationship.Relation)
+		rows[relationshipType] = append(rows[relationshipType], map[string]interface{}{
+			"from": g.endpoint(edge.AccountID, relationship.FromType, relationship.From),
+			"to":   g.endpoint(edge.AccountID, relationship.ToType, relationship.To),
+		})
+	}
+
+	relationshipTypes := []string{}
+	for relationshipType := range rows {
+		relationshipTypes = append(relationshipTypes, relationshipType)
+	}
+	sort.Strings(relationshipTypes)
+
+	statements := []cypherStatement{}
+	for _, relationshipType := range relationshipTypes {
+		query := fmt.Sprintf("UNWIND $rows AS row "+
+			"MERGE (a:Resource {key: row.from.key}) ON CREATE SET a += row.from.properties "+
+			"MERGE (b:Resource {key: row.to.key}) ON CREATE SET b += row.to.properties "+
+			"MERGE (a)-[:%s]->(b)", relationshipType)
+		statements = append(statements, neo4jStatements(query, rows[relationshipType])...)
+	}
+	g.edges = nil
+	return statements
+}
+
+// cypherLiteral returns the value as a Cypher literal, for the values of the
+// statement parameters.
+func cypherLiteral(value interface{}) string {
+	switch typed := value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`, "\n", `\n`, "\r", `\r`).Replace(typed) + "'"
+	case bool:
+		return strconv.FormatBool(typed)
+	case int:
+		return strconv.Itoa(typed)
+	case []map[string]interface{}:
+		items := []string{}
+		for _, item := range typed {
+			items = append(items, cypherLiteral(item))
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	case map[string]interface{}:
+		keys := []string{}
+		for key := range typed {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		items := []string{}
+		for _, key := range keys {
+			items = append(items, fmt.Sprintf("%s: %s", key, cypherLiteral(typed[key])))
+		}
+		return "{" + strings.Join(items, ", ") + "}"
+	}
+	return cypherLiteral(fmt.Sprint(value))
+}
+
+// WriteCypher writes the resources and the relationships derived from their
+// metadata as a Cypher script, to run with cypher-shell.
+func WriteCypher(writer io.Writer, resourceList []resources.Resource, timestamp time.Time) error {
+	graph := newNeo4jGraph(timestamp)
+	for _, resource := range resourceList {
+		if _, err := graph.Add(resource); err != nil {
+			return err
+		}
+	}
+
+	lines := []string{neo4jConstraint + ";"}
+	for _, statement := range append(graph.NodeStatements(), graph.EdgeStatements()...) {
+		rows := statement.Parameters["rows"]
+		lines = append(lines, strings.Replace(statement.Statement, "$rows", cypherLiteral(rows), 1)+";")
+	}
+
+	_, err := io.WriteString(writer, strings.Join(lines, "\n")+"\n")
+	return err
+}
+
+// Neo4jSink merges the resources as nodes in a Neo4j database with the HTTP
+// API as they are collected, and their relationships once all the nodes are
+// merged.
+type Neo4jSink struct {
+	client   *http.Client
+	endpoint *url.URL
+	database string
+	username string
+	password string
+	graph    *neo4jGraph
+
+	pending int
+	written int
+}
+
+// NewNeo4jSink returns a sink for the database of the server at endpoint,
+// with basic authentication if the endpoint has credentials.
+func NewNeo4jSink(endpoint, database string, timestamp time.Time) (*Neo4jSink, error) {
+	parsed, err := url.Parse(strings.TrimRight(endpoint, "/"))
+	if err != nil {
+		return nil, err
+	}
+
+	sink := &Neo4jSink{
+		client:   &http.Client{Timeout: time.Minute},
+		endpoint: parsed,
+		database: database,
+		graph:    newNeo4jGraph(timestamp),
+	}
+	if parsed.User != nil {
+		sink.username = parsed.User.Username()
+		sink.password, _ = parsed.User.Password()
+		parsed.User = nil
+	}
+	return sink, nil
+}
+
+type neo4jResponse struct {
+	Errors []struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+// commit runs the statements in a single transaction.
+func (s *Neo4jSink) commit(statements []cypherStatement) error {
+	if len(statements) == 0 {
+		return nil
+	}
+
+	body, err := json.Marshal(map[string]interface{}{"statements": statements})
+	if err != nil {
+		return err
+	}
+
+	path := fmt.Sprintf("/db/%s/tx/commit", url.PathEscape(s.database))
+	request, err := http.NewRequest(http.MethodPost, s.endpoint.String()+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Accept", "application/json")
+	if s.username != "" {
+		request.SetBasicAuth(s.username, s.password)
+	}
+
+	response, err := s.client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	data, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return err
+	}
+	if response.StatusCode >= 300 {
+		return fmt.Errorf("neo4j returned %d: %s", response.StatusCode, string(data))
+	}
+
+	// the statement errors are returned with a 200
+	result := &neo4jResponse{}
+	err = json.Unmarshal(data, result)
+	if err != nil {
+		return err
+	}
+	if len(result.Errors) > 0 {
+		return fmt.Errorf("neo4j returned %s: %s", result.Errors[0].Code, result.Errors[0].Message)
+	}
+	return nil
+}
+
+// CreateConstraint makes the key of the resources unique.
+func (s *Neo4jSink) CreateConstraint() error {
+	return s.commit([]cypherStatement{{Statement: neo4jConstraint}})
+}
+
+// Put adds the node of the resource to the current batch and merges it when
+// the batch is full.
+func (s *Neo4jSink) Put(resource resources.Resource) error {
+	added, err := s.graph.Add(resource)
+	if err != nil || !added {
+		return err
+	}
+	s.pending++
+
+	if s.pending >= neo4jBatchSize {
+		return s.flushNodes()
+	}
+	return nil
+}
+
+func (s *Neo4jSink) flushNodes() error {
+	pending := s.pending
+	s.pending = 0
+
+	err := s.commit(s.graph.NodeStatements())
+	if err != nil {
+		return err
+	}
+	s.written += pending
+	return nil
+}
+
+// Flush merges the nodes not merged yet, then the relationships of all the
+// resources put so far.
+func (s *Neo4jSink) Flush() error {
+	err := s.flushNodes()
+	if err != nil {
+		return err
+	}
+	return s.commit(s.graph.EdgeStatements())
+}
+
+func (s *Neo4jSink) Written() int {
+	return s.written
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hamstah/awstools/aws/dump/resources"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNeo4jLabel(t *testing.T) {
+	require.Equal(t, "Ec2Instance", neo4jLabel("ec2:instance"))
+	require.Equal(t, "Elbv2LoadBalancer", neo4jLabel("elbv2:load-balancer"))
+	require.Equal(t, "MEMBER_OF", neo4jRelationshipType(resources.RelationMemberOf))
+}
+
+func TestWriteCypher(t *testing.T) {
+	resourceList := []resources.Resource{
+		{
+			ID:        "i-1",
+			Service:   "ec2",
+			Type:      "instance",
+			AccountID: "123456789012",
+			Region:    "eu-west-1",
+			Metadata: map[string]interface{}{
+				"SecurityGroups": []map[string]string{{"GroupId": "sg-1"}},
+				"Tags":           []map[string]string{{"Key": "Name", "Value": "bob's"}},
+			},
+		},
+		{
+			ID:        "sg-1",
+			ARN:       "arn:aws:ec2:eu-west-1:123456789012:security-group/sg-1",
+			Service:   "ec2",
+			Type:      "security-group",
+			AccountID: "123456789012",
+			Region:    "eu-west-1",
+			Metadata:  map[string]interface{}{"VpcId": "vpc-1"},
+		},
+		{ID: "ec2:instances", Service: "aws-dump", Type: "error"},
+	}
+
+	buffer := &bytes.Buffer{}
+	require.NoError(t, WriteCypher(buffer, resourceList, time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)))
+
+	lines := strings.Split(strings.TrimSpace(buffer.String()), "\n")
+	require.Len(t, lines, 5)
+	require.Equal(t, neo4jConstraint+";", lines[0])
+	require.Equal(t, `UNWIND [{key: '123456789012/eu-west-1/ec2/instance/i-1', properties: {account_id: '123456789012', arn: '', dumped_at: '2021-01-02T03:04:05Z', external: false, id: 'i-1', managed_by_state: '', managed_by_type: '', metadata: '{"SecurityGroups":[{"GroupId":"sg-1"}],"Tags":[{"Key":"Name","Value":"bob\'s"}]}', name: 'bob\'s', region: 'eu-west-1', service: 'ec2', type: 'instance'}}] AS row MERGE (n:Resource {key: row.key}) SET n += row.properties, n:Ec2Instance;`, lines[1])
+	require.Contains(t, lines[2], "n:Ec2SecurityGroup;")
+
+	// the security group is in the dump, the VPC is external
+	require.Equal(t, `UNWIND [{from: {key: 'arn:aws:ec2:eu-west-1:123456789012:security-group/sg-1', properties: {}}, to: {key: 'vpc-1', properties: {external: true, id: 'vpc-1', service: 'ec2', type: 'vpc'}}}] AS row MERGE (a:Resource {key: row.from.key}) ON CREATE SET a += row.from.properties MERGE (b:Resource {key: row.to.key}) ON CREATE SET b += row.to.properties MERGE (a)-[:IN]->(b);`, lines[3])
+	require.Equal(t, `UNWIND [{from: {key: '123456789012/eu-west-1/ec2/instance/i-1', properties: {}}, to: {key: 'arn:aws:ec2:eu-west-1:123456789012:security-group/sg-1', properties: {}}}] AS row MERGE (a:Resource {key: row.from.key}) ON CREATE SET a += row.from.properties MERGE (b:Resource {key: row.to.key}) ON CREATE SET b += row.to.properties MERGE (a)-[:USES]->(b);`, lines[4])
+}
+
+func TestNeo4jSink(t *testing.T) {
+	requests := []map[string][]cypherStatement{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		username, password, _ := r.BasicAuth()
+		require.Equal(t, "neo4j", username)
+		require.Equal(t, "secret", password)
+		require.Equal(t, "/db/inventory/tx/commit", r.URL.Path)
+
+		body := map[string][]cypherStatement{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		requests = append(requests, body)
+
+		if strings.Contains(body["statements"][0].Statement, "[:USES]") {
+			w.Write([]byte(`{"results":[],"errors":[{"code":"Neo.ClientError.Statement.SyntaxError","message":"Invalid input"}]}`))
+			return
+		}
+		w.Write([]byte(`{"results":[{"columns":[],"data":[]}],"errors":[]}`))
+	}))
+	defer server.Close()
+
+	endpoint := strings.Replace(server.URL, "http://", "http://neo4j:secret@", 1)
+	sink, err := NewNeo4jSink(endpoint+"/", "inventory", time.Now())
+	require.NoError(t, err)
+
+	require.NoError(t, sink.CreateConstraint())
+	require.Equal(t, neo4jConstraint, requests[0]["statements"][0].Statement)
+
+	require.NoError(t, sink.Put(resources.Resource{
+		ID:        "i-1",
+		Service:   "ec2",
+		Type:      "instance",
+		AccountID: "123456789012",
+		Region:    "eu-west-1",
+		Metadata:  map[string]interface{}{"KeyName": "deploy"},
+	}))
+	require.NoError(t, sink.Put(resources.Resource{ID: "ec2:instances", Service: "aws-dump", Type: "error"}))
+
+	err = sink.Flush()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "Neo.ClientError.Statement.SyntaxError")
+	require.Equal(t, 1, sink.Written())
+
+	require.Len(t, requests, 3)
+	require.Len(t, requests[1]["statements"], 1)
+	require.Contains(t, requests[1]["statements"][0].Statement, "n:Ec2Instance")
+	require.Len(t, requests[1]["statements"][0].Parameters["rows"], 1)
+
+	// nothing left to send
+	require.NoError(t, sink.Flush())
+	require.Len(t, requests, 3)
+}
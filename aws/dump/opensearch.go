@@ -0,0 +1,281 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	v4 "github.com/aws/aws-sdk-go/aws/signer/v4"
+	"github.com/hamstah/awstools/aws/dump/resources"
+)
+
+const (
+	OpenSearchIndexPerDay     = "day"
+	OpenSearchIndexPerService = "service"
+
+	// maximum number of resources and size of a bulk request
+	openSearchBatchSize     = 500
+	openSearchMaxBatchBytes = 5 * 1024 * 1024
+	openSearchMaxAttempts   = 5
+)
+
+// the metadata differs between resource types and is not indexed to avoid
+// mapping conflicts and explosions, metadata_text has it for full text search
+var openSearchMappings = map[string]interface{}{
+	"properties": map[string]interface{}{
+		"@timestamp":    map[string]string{"type": "date"},
+		"id":            map[string]string{"type": "keyword"},
+		"arn":           map[string]string{"type": "keyword"},
+		"service":       map[string]string{"type": "keyword"},
+		"type":          map[string]string{"type": "keyword"},
+		"account_id":    map[string]string{"type": "keyword"},
+		"region":        map[string]string{"type": "keyword"},
+		"metadata":      map[string]interface{}{"type": "object", "enabled": false},
+		"metadata_text": map[string]string{"type": "text"},
+		"managed_by": map[string]interface{}{
+			"properties": map[string]interface{}{
+				"type":  map[string]string{"type": "keyword"},
+				"state": map[string]string{"type": "keyword"},
+			},
+		},
+	},
+}
+
+// OpenSearchSink bulk indexes the resources in an OpenSearch or Elasticsearch
+// cluster, in an index per day or per service.
+type OpenSearchSink struct {
+	client    *http.Client
+	endpoint  *url.URL
+	username  string
+	password  string
+	signer    *v4.Signer
+	region    string
+	index     string
+	indexPer  string
+	timestamp time.Time
+
+	buffer  *bytes.Buffer
+	pending int
+	written int
+}
+
+// NewOpenSearchSink returns a sink for the cluster at endpoint, with basic
+// authentication if the endpoint has credentials.
+func NewOpenSearchSink(endpoint, index, indexPer string, timestamp time.Time) (*OpenSearchSink, error) {
+	parsed, err := url.Parse(strings.TrimRight(endpoint, "/"))
+	if err != nil {
+		return nil, err
+	}
+	if indexPer != OpenSearchIndexPerDay && indexPer != OpenSearchIndexPerService {
+		return nil, fmt.Errorf("invalid index per %s, should be %s or %s", indexPer, OpenSearchIndexPerDay, OpenSearchIndexPerService)
+	}
+
+	sink := &OpenSearchSink{
+		client:    &http.Client{Timeout: time.Minute},
+		endpoint:  parsed,
+		index:     strings.ToLower(index),
+		indexPer:  indexPer,
+		timestamp: timestamp,
+		buffer:    &bytes.Buffer{},
+	}
+	if parsed.User != nil {
+		sink.username = parsed.User.Username()
+		sink.password, _ = parsed.User.Password()
+		parsed.User = nil
+	}
+	return sink, nil
+}
+
+// WithSigner signs the requests for the Amazon OpenSearch Service domains
+// using IAM authentication.
+func (s *OpenSearchSink) WithSigner(signer *v4.Signer, region string) *OpenSearchSink {
+	s.signer = signer
+	s.region = region
+	return s
+}
+
+func (s *OpenSearchSink) IndexName(resource resources.Resource) string {
+	if s.indexPer == OpenSearchIndexPerService {
+		return fmt.Sprintf("%s-%s", s.index, strings.ToLower(resource.Service))
+	}
+	return fmt.Sprintf("%s-%s", s.index, s.timestamp.UTC().Format("2006.01.02"))
+}
+
+func (s *OpenSearchSink) request(method, path string, body []byte) ([]byte, error) {
+	request, err := http.NewRequest(method, s.endpoint.String()+path, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	if s.username != "" {
+		request.SetBasicAuth(s.username, s.password)
+	}
+	if s.signer != nil {
+		_, err = s.signer.Sign(request, bytes.NewReader(body), "es", s.region, time.Now())
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	response, err := s.client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	data, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return nil, err
+	}
+	if response.StatusCode >= 300 {
+		return data, &openSearchError{StatusCode: response.StatusCode, Body: string(data)}
+	}
+	return data, nil
+}
+
+type openSearchError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *openSearchError) Error() string {
+	return fmt.Sprintf("opensearch returned %d: %s", e.StatusCode, e.Body)
+}
+
+// PutTemplate creates or updates the index template with the mappings of the
+// resources for the indexes of the sink.
+func (s *OpenSearchSink) PutTemplate() error {
+	template, err := json.Marshal(map[string]interface{}{
+		"index_patterns": []string{s.index + "-*"},
+		"template": map[string]interface{}{
+			"mappings": openSearchMappings,
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = s.request(http.MethodPut, "/_index_template/"+url.PathEscape(s.index), template)
+	return err
+}
+
+func (s *OpenSearchSink) document(resource resources.Resource) (map[string]interface{}, error) {
+	metadata, err := json.Marshal(resource.Metadata)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"@timestamp":    s.timestamp.UTC().Format(time.RFC3339),
+		"id":            resource.ID,
+		"arn":           resource.ARN,
+		"service":       resource.Service,
+		"type":          resource.Type,
+		"account_id":    resource.AccountID,
+		"region":        resource.Region,
+		"metadata":      resource.Metadata,
+		"metadata_text": string(metadata),
+		"managed_by":    resource.ManagedBy,
+	}, nil
+}
+
+// Put adds the resource to the current bulk request and sends it when it is
+// full. The id of the documents is the key of the resource so the resources
+// are updated when they are indexed again in the same index.
+func (s *OpenSearchSink) Put(resource resources.Resource) error {
+	document, err := s.document(resource)
+	if err != nil {
+		return err
+	}
+
+	action := map[string]interface{}{
+		"index": map[string]string{
+			"_index": s.IndexName(resource),
+			"_id":    ResourceKey(resource),
+		},
+	}
+
+	encoder := json.NewEncoder(s.buffer)
+	if err := encoder.Encode(action); err != nil {
+		return err
+	}
+	if err := encoder.Encode(document); err != nil {
+		return err
+	}
+	s.pending++
+
+	if s.pending >= openSearchBatchSize || s.buffer.Len() >= openSearchMaxBatchBytes {
+		return s.Flush()
+	}
+	return nil
+}
+
+type openSearchBulkResponse struct {
+	Errors bool `json:"errors"`
+	Items  []map[string]struct {
+		Status int             `json:"status"`
+		Error  json.RawMessage `json:"error"`
+	} `json:"items"`
+}
+
+// Flush sends the current bulk request, retrying when the cluster is
+// overloaded.
+func (s *OpenSearchSink) Flush() error {
+	if s.pending == 0 {
+		return nil
+	}
+
+	body := s.buffer.Bytes()
+	pending := s.pending
+	s.buffer = &bytes.Buffer{}
+	s.pending = 0
+
+	delay := time.Second
+	var data []byte
+	var err error
+	for attempt := 1; attempt <= openSearchMaxAttempts; attempt++ {
+		data, err = s.request(http.MethodPost, "/_bulk", body)
+		if requestErr, ok := err.(*openSearchError); !ok || requestErr.StatusCode != http.StatusTooManyRequests {
+			break
+		}
+		time.Sleep(delay)
+		delay *= 2
+	}
+	if err != nil {
+		return err
+	}
+
+	response := &openSearchBulkResponse{}
+	err = json.Unmarshal(data, response)
+	if err != nil {
+		return err
+	}
+
+	failed := 0
+	var firstError json.RawMessage
+	for _, item := range response.Items {
+		for _, result := range item {
+			if result.Status >= 300 {
+				failed++
+				if firstError == nil {
+					firstError = result.Error
+				}
+			}
+		}
+	}
+
+	s.written += pending - failed
+	if failed > 0 {
+		return fmt.Errorf("failed to index %d resources: %s", failed, string(firstError))
+	}
+	return nil
+}
+
+func (s *OpenSearchSink) Written() int {
+	return s.written
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hamstah/awstools/aws/dump/resources"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenSearchSink(t *testing.T) {
+	requests := map[string][]string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		username, password, _ := r.BasicAuth()
+		require.Equal(t, "admin", username)
+		require.Equal(t, "secret", password)
+
+		lines := []string{}
+		scanner := bufio.NewScanner(r.Body)
+		for scanner.Scan() {
+			lines = append(lines, scanner.Text())
+		}
+		requests[r.Method+" "+r.URL.Path] = lines
+
+		if r.URL.Path == "/_bulk" {
+			w.Write([]byte(`{"errors":true,"items":[{"index":{"status":201}},{"index":{"status":400,"error":{"type":"mapper_parsing_exception"}}}]}`))
+			return
+		}
+		w.Write([]byte(`{"acknowledged":true}`))
+	}))
+	defer server.Close()
+
+	endpoint := strings.Replace(server.URL, "http://", "http://admin:secret@", 1)
+	timestamp := time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)
+	sink, err := NewOpenSearchSink(endpoint+"/", "AWS-Dump", OpenSearchIndexPerDay, timestamp)
+	require.NoError(t, err)
+
+	require.NoError(t, sink.PutTemplate())
+	require.Contains(t, requests["PUT /_index_template/aws-dump"][0], `"index_patterns":["aws-dump-*"]`)
+
+	require.NoError(t, sink.Put(resources.Resource{
+		ID:        "i-0123456789abcdef0",
+		ARN:       "arn:aws:ec2:eu-west-1:123456789012:instance/i-0123456789abcdef0",
+		Service:   "ec2",
+		Type:      "instance",
+		AccountID: "123456789012",
+		Region:    "eu-west-1",
+		Metadata:  map[string]interface{}{"InstanceType": "t3.micro"},
+	}))
+	require.NoError(t, sink.Put(resources.Resource{ID: "deploy", Service: "ec2", Type: "key-pair", AccountID: "123456789012", Region: "eu-west-1"}))
+
+	err = sink.Flush()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to index 1 resources")
+	require.Equal(t, 1, sink.Written())
+
+	lines := requests["POST /_bulk"]
+	require.Len(t, lines, 4)
+	require.Equal(t, `{"index":{"_id":"arn:aws:ec2:eu-west-1:123456789012:instance/i-0123456789abcdef0","_index":"aws-dump-2021.01.02"}}`, lines[0])
+	require.Equal(t, `{"index":{"_id":"123456789012/eu-west-1/ec2/key-pair/deploy","_index":"aws-dump-2021.01.02"}}`, lines[2])
+
+	document := map[string]interface{}{}
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &document))
+	require.Equal(t, "2021-01-02T03:04:05Z", document["@timestamp"])
+	require.Equal(t, `{"InstanceType":"t3.micro"}`, document["metadata_text"])
+
+	// nothing left to send
+	require.NoError(t, sink.Flush())
+}
+
+func TestOpenSearchIndexName(t *testing.T) {
+	sink, err := NewOpenSearchSink("http://localhost:9200", "aws-dump", OpenSearchIndexPerService, time.Now())
+	require.NoError(t, err)
+	require.Equal(t, "aws-dump-ec2", sink.IndexName(resources.Resource{Service: "ec2"}))
+
+	_, err = NewOpenSearchSink("http://localhost:9200", "aws-dump", "week", time.Now())
+	require.Error(t, err)
+}
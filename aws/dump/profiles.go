@@ -0,0 +1,121 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/hamstah/awstools/aws/dump/resources"
+)
+
+// Profile is a named set of reports and enrichments for a common use of the
+// dump. Reports are patterns like the ones of --only.
+type Profile struct {
+	Description     string
+	Reports         []string
+	IncludeOptional bool
+	SSMInventory    bool
+	Rightsizing     bool
+	MonthlyCost     bool
+}
+
+var Profiles = map[string]Profile{
+	"security": {
+		Description: "IAM, KMS, network exposure, key pairs, sharing and security findings",
+		Reports: []string{
+			"iam:*",
+			"kms:*",
+			"accessanalyzer:*",
+			"securityhub:*",
+			"sharing:findings",
+			"ec2:security-groups",
+			"ec2:key-pair-audit",
+			"ec2:instance-access",
+			"s3:buckets",
+			"acm:certificates",
+			"cognito:*",
+			"shield:*",
+			"waf:*",
+			"waf-cloudfront:*",
+		},
+	},
+	"cost": {
+		Description: "resources billed by the hour or the GB, reservations and under-utilised instances",
+		Reports: []string{
+			"ec2:instances",
+			"ec2:nat-gateways",
+			"ec2:images",
+			"elbv2:load-balancers",
+			"rds:db-instances",
+			"rds:db-clusters",
+			"rds:db-snapshots",
+			"rds:reserved-db-instances",
+			"docdb:db-instances",
+			"neptune:db-instances",
+			"efs:file-systems",
+			"fsx:file-systems",
+			"backup:recovery-point-summaries",
+			"lightsail:*",
+			"sagemaker:endpoints",
+			"sagemaker:notebook-instances",
+			"msk:clusters",
+			"mq:brokers",
+			"kinesis:streams",
+			"dms:replication-instances",
+			"transitgateway:attachments",
+			"globalaccelerator:*",
+		},
+		Rightsizing: true,
+		MonthlyCost: true,
+	},
+	"networking": {
+		Description: "VPCs, routing, connectivity, load balancers and DNS",
+		Reports: []string{
+			"ec2:vpcs",
+			"ec2:subnets",
+			"ec2:security-groups",
+			"ec2:nat-gateways",
+			"transitgateway:*",
+			"directconnect:*",
+			"directconnect-gateway:*",
+			"elbv2:load-balancers",
+			"route53:zones-and-records",
+			"cloudfront:distributions",
+			"globalaccelerator:*",
+			"apigateway:*",
+		},
+	},
+	"full": {
+		Description:     "all the reports, including the optional ones, with the SSM inventory, rightsizing and monthly cost",
+		IncludeOptional: true,
+		SSMInventory:    true,
+		Rightsizing:     true,
+		MonthlyCost:     true,
+	},
+}
+
+func GetProfile(name string) (*Profile, error) {
+	profile, ok := Profiles[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown profile %s, should be one of %v", name, ProfileNames())
+	}
+	return &profile, nil
+}
+
+// ProfileReports returns the reports run by the profile.
+func ProfileReports(name string) ([]string, error) {
+	profile, err := GetProfile(name)
+	if err != nil {
+		return nil, err
+	}
+	selection := &ReportSelection{Profile: profile.Reports, IncludeOptional: profile.IncludeOptional}
+	return selection.Select(resources.AllServices())
+}
+
+func ProfileNames() []string {
+	names := []string{}
+	for name := range Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,24 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestProfiles(t *testing.T) {
+	for _, name := range ProfileNames() {
+		reports, err := ProfileReports(name)
+		require.NoError(t, err, name)
+		require.NotEmpty(t, reports, name)
+	}
+
+	reports, err := ProfileReports("security")
+	require.NoError(t, err)
+	require.Contains(t, reports, "iam:roles")
+	require.Contains(t, reports, "waf:classic-web-acls")
+	require.NotContains(t, reports, "ec2:instances")
+
+	_, err = GetProfile("everything")
+	require.EqualError(t, err, "unknown profile everything, should be one of [cost full networking security]")
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/hamstah/awstools/aws/dump/resources"
+)
+
+const (
+	ProgressAuto = "auto"
+	ProgressNone = "none"
+	ProgressText = "text"
+	ProgressJSON = "json"
+)
+
+// ProgressEvent is a line of the json progress.
+type ProgressEvent struct {
+	Event           string  `json:"event"`
+	Service         string  `json:"service,omitempty"`
+	Report          string  `json:"report,omitempty"`
+	AccountID       string  `json:"account_id,omitempty"`
+	Region          string  `json:"region,omitempty"`
+	Resources       int     `json:"resources"`
+	Error           string  `json:"error,omitempty"`
+	DurationSeconds float64 `json:"duration_seconds,omitempty"`
+	Completed       int     `json:"completed"`
+	Failed          int     `json:"failed"`
+	Total           int     `json:"total"`
+	ElapsedSeconds  float64 `json:"elapsed_seconds"`
+}
+
+// ProgressPrinter writes a line per completed report with the resources
+// collected, and a summary at the end.
+type ProgressPrinter struct {
+	Format string
+	Writer io.Writer
+
+	started   time.Time
+	total     int
+	completed int
+	failed    int
+	resources int
+	now       func() time.Time
+}
+
+func NewProgressPrinter(format string, writer io.Writer) *ProgressPrinter {
+	return &ProgressPrinter{Format: format, Writer: writer, now: time.Now}
+}
+
+// ResolveProgressFormat returns text for auto when stderr is a terminal.
+func ResolveProgressFormat(format string) string {
+	if format != ProgressAuto {
+		return format
+	}
+	if info, err := os.Stderr.Stat(); err == nil && info.Mode()&os.ModeCharDevice != 0 {
+		return ProgressText
+	}
+	return ProgressNone
+}
+
+func (p *ProgressPrinter) elapsed() time.Duration {
+	return p.now().Sub(p.started)
+}
+
+func (p *ProgressPrinter) write(event ProgressEvent, text string) {
+	if p.Format == ProgressJSON {
+		event.Completed = p.completed
+		event.Failed = p.failed
+		event.Total = p.total
+		event.ElapsedSeconds = p.elapsed().Seconds()
+		data, err := json.Marshal(event)
+		if err != nil {
+			return
+		}
+		fmt.Fprintln(p.Writer, string(data))
+		return
+	}
+	fmt.Fprintln(p.Writer, text)
+}
+
+func (p *ProgressPrinter) Start(total int) {
+	p.started = p.now()
+	p.total = total
+	p.write(ProgressEvent{Event: "start"}, fmt.Sprintf("Running %d reports", total))
+}
+
+func (p *ProgressPrinter) Report(progress resources.JobProgress) {
+	p.completed = progress.Completed
+	p.resources += progress.Resources
+
+	name := fmt.Sprintf("%s:%s", progress.Service, progress.Report)
+	location := progress.AccountID
+	if progress.Region != "" {
+		location = fmt.Sprintf("%s %s", location, progress.Region)
+	}
+	prefix := fmt.Sprintf("[%*d/%d] %s %s %s:", len(fmt.Sprint(p.total)), p.completed, p.total, p.elapsed().Round(time.Second), name, location)
+	duration := progress.Duration.Round(100 * time.Millisecond)
+
+	event := ProgressEvent{
+		Event:           "report",
+		Service:         progress.Service,
+		Report:          progress.Report,
+		AccountID:       progress.AccountID,
+		Region:          progress.Region,
+		Resources:       progress.Resources,
+		DurationSeconds: progress.Duration.Seconds(),
+	}
+	if progress.Error != nil {
+		p.failed++
+		message := progress.Error.Error()
+		if jobError, ok := progress.Error.(*resources.JobError); ok {
+			message = jobError.Message
+		}
+		event.Error = message
+		p.write(event, fmt.Sprintf("%s failed after %s: %s", prefix, duration, message))
+		return
+	}
+	p.write(event, fmt.Sprintf("%s %d resources in %s", prefix, progress.Resources, duration))
+}
+
+func (p *ProgressPrinter) Done() {
+	p.write(ProgressEvent{Event: "done", Resources: p.resources},
+		fmt.Sprintf("Done in %s: %d resources from %d reports, %d failed", p.elapsed().Round(time.Second), p.resources, p.completed, p.failed))
+}
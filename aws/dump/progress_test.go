@@ -0,0 +1,58 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hamstah/awstools/aws/dump/resources"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestProgressPrinter(format string) (*ProgressPrinter, *bytes.Buffer, *time.Time) {
+	buffer := &bytes.Buffer{}
+	now := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	printer := NewProgressPrinter(format, buffer)
+	printer.now = func() time.Time { return now }
+	return printer, buffer, &now
+}
+
+func TestProgressPrinterText(t *testing.T) {
+	printer, buffer, now := newTestProgressPrinter(ProgressText)
+
+	printer.Start(12)
+	*now = now.Add(62 * time.Second)
+	printer.Report(resources.JobProgress{Service: "iam", Report: "roles", AccountID: "123456789012", Region: "us-east-1", Resources: 250, Duration: 4210 * time.Millisecond, Completed: 1, Total: 12})
+	printer.Report(resources.JobProgress{Service: "s3", Report: "buckets", AccountID: "123456789012", Region: "eu-west-1", Error: &resources.JobError{Message: "AccessDenied"}, Duration: time.Second, Completed: 2, Total: 12})
+	printer.Done()
+
+	require.Equal(t, []string{
+		"Running 12 reports",
+		"[ 1/12] 1m2s iam:roles 123456789012 us-east-1: 250 resources in 4.2s",
+		"[ 2/12] 1m2s s3:buckets 123456789012 eu-west-1: failed after 1s: AccessDenied",
+		"Done in 1m2s: 250 resources from 2 reports, 1 failed",
+	}, strings.Split(strings.TrimSpace(buffer.String()), "\n"))
+}
+
+func TestProgressPrinterJSON(t *testing.T) {
+	printer, buffer, now := newTestProgressPrinter(ProgressJSON)
+
+	printer.Start(2)
+	*now = now.Add(3 * time.Second)
+	printer.Report(resources.JobProgress{Service: "iam", Report: "roles", AccountID: "123456789012", Resources: 5, Duration: time.Second, Completed: 1, Total: 2})
+	printer.Done()
+
+	events := []ProgressEvent{}
+	for _, line := range strings.Split(strings.TrimSpace(buffer.String()), "\n") {
+		event := ProgressEvent{}
+		require.NoError(t, json.Unmarshal([]byte(line), &event))
+		events = append(events, event)
+	}
+	require.Equal(t, []ProgressEvent{
+		{Event: "start", Total: 2},
+		{Event: "report", Service: "iam", Report: "roles", AccountID: "123456789012", Resources: 5, DurationSeconds: 1, Completed: 1, Total: 2, ElapsedSeconds: 3},
+		{Event: "done", Resources: 5, Completed: 1, Total: 2, ElapsedSeconds: 3},
+	}, events)
+}
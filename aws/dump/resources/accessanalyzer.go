@@ -0,0 +1,101 @@
+package resources
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/accessanalyzer"
+	"github.com/fatih/structs"
+)
+
+var (
+	AccessAnalyzerService = Service{
+		Name: "accessanalyzer",
+		Reports: map[string]Report{
+			"analyzers": AccessAnalyzerListAnalyzers,
+			"findings":  AccessAnalyzerListFindings,
+		},
+	}
+)
+
+func accessAnalyzerListAnalyzers(client *accessanalyzer.AccessAnalyzer) ([]*accessanalyzer.AnalyzerSummary, error) {
+	analyzers := []*accessanalyzer.AnalyzerSummary{}
+	err := client.ListAnalyzersPages(&accessanalyzer.ListAnalyzersInput{},
+		func(page *accessanalyzer.ListAnalyzersOutput, lastPage bool) bool {
+			analyzers = append(analyzers, page.Analyzers...)
+			return true
+		})
+	return analyzers, err
+}
+
+func AccessAnalyzerListAnalyzers(session *Session) *ReportResult {
+	client := accessanalyzer.New(session.Session, session.Config)
+
+	analyzers, err := accessAnalyzerListAnalyzers(client)
+	if err != nil {
+		return &ReportResult{nil, err}
+	}
+
+	result := &ReportResult{}
+	for _, analyzer := range analyzers {
+		result.Resources = append(result.Resources, Resource{
+			ID:        *analyzer.Name,
+			ARN:       *analyzer.Arn,
+			AccountID: session.AccountID,
+			Service:   "accessanalyzer",
+			Type:      "analyzer",
+			Region:    *session.Config.Region,
+			Metadata:  structs.Map(analyzer),
+		})
+	}
+
+	return result
+}
+
+// AccessAnalyzerListFindings lists the active findings of every active
+// analyzer, the resources shared outside of the zone of trust of the analyzer.
+// The ARN is left empty so the findings are not matched with the terraform
+// state of the shared resources, use the Resource metadata instead.
+func AccessAnalyzerListFindings(session *Session) *ReportResult {
+	client := accessanalyzer.New(session.Session, session.Config)
+
+	analyzers, err := accessAnalyzerListAnalyzers(client)
+	if err != nil {
+		return &ReportResult{nil, err}
+	}
+
+	result := &ReportResult{}
+	for _, analyzer := range analyzers {
+		if aws.StringValue(analyzer.Status) != accessanalyzer.AnalyzerStatusActive {
+			continue
+		}
+
+		input := &accessanalyzer.ListFindingsInput{
+			AnalyzerArn: analyzer.Arn,
+			Filter: map[string]*accessanalyzer.Criterion{
+				"status": {Eq: aws.StringSlice([]string{accessanalyzer.FindingStatusActive})},
+			},
+		}
+		err := client.ListFindingsPages(input,
+			func(page *accessanalyzer.ListFindingsOutput, lastPage bool) bool {
+				for _, finding := range page.Findings {
+					resource := Resource{
+						ID:        *finding.Id,
+						AccountID: session.AccountID,
+						Service:   "accessanalyzer",
+						Type:      "finding",
+						Region:    *session.Config.Region,
+						Metadata:  structs.Map(finding),
+					}
+					resource.Metadata["AnalyzerArn"] = *analyzer.Arn
+					resource.Metadata["AnalyzerType"] = aws.StringValue(analyzer.Type)
+					result.Resources = append(result.Resources, resource)
+				}
+				return true
+			})
+		if err != nil {
+			result.Error = err
+			return result
+		}
+	}
+
+	return result
+}
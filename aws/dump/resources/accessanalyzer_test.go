@@ -0,0 +1,24 @@
+package resources
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccessAnalyzerListFindings(t *testing.T) {
+	t.Parallel()
+
+	result := AccessAnalyzerListFindings(newTestFixtureSession(t, "accessanalyzer"))
+	require.NoError(t, result.Error)
+	require.Len(t, result.Resources, 1)
+
+	finding := result.Resources[0]
+	require.Equal(t, "11111111-2222-3333-4444-555555555555", finding.ID)
+	require.Equal(t, "", finding.ARN)
+	require.Equal(t, "finding", finding.Type)
+	require.Equal(t, "eu-west-1", finding.Region)
+	require.Equal(t, "arn:aws:access-analyzer:eu-west-1:123456789012:analyzer/account", finding.Metadata["AnalyzerArn"])
+	require.Equal(t, aws.String("arn:aws:s3:::shared-bucket"), finding.Metadata["Resource"])
+}
@@ -0,0 +1,363 @@
+//go:build ignore
+// +build ignore
+
+// actions_gen.go generates report_actions.go, the IAM actions needed by each
+// report and by the steps of the dump. The actions are the ones of the
+// <service>.<Operation>Input values created by the functions reachable from
+// the report, which is an over-approximation for the calls made conditionally.
+//
+//	go generate ./...
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const sdkServicePrefix = "github.com/aws/aws-sdk-go/service/"
+
+// iamPrefixes are the IAM service prefixes of the SDK packages that don't
+// use the package name.
+var iamPrefixes = map[string]string{
+	"apigatewayv2":             "apigateway",
+	"cloudwatchlogs":           "logs",
+	"cognitoidentity":          "cognito-identity",
+	"cognitoidentityprovider":  "cognito-idp",
+	"costexplorer":             "ce",
+	"databasemigrationservice": "dms",
+	"docdb":                    "rds",
+	"elbv2":                    "elasticloadbalancing",
+	"eventbridge":              "events",
+	"licensemanager":           "license-manager",
+	"neptune":                  "rds",
+	"resourcegroupstaggingapi": "tag",
+	"wafregional":              "waf-regional",
+}
+
+// iamActions are the IAM actions of the operations that don't use the name of
+// the operation, by IAM prefix:Operation.
+var iamActions = map[string]string{
+	"s3:GetBucketEncryption":             "s3:GetEncryptionConfiguration",
+	"s3:GetBucketLifecycleConfiguration": "s3:GetLifecycleConfiguration",
+	"s3:GetBucketReplication":            "s3:GetReplicationConfiguration",
+	"s3:GetObjectLockConfiguration":      "s3:GetBucketObjectLockConfiguration",
+	"s3:GetPublicAccessBlock":            "s3:GetBucketPublicAccessBlock",
+	"s3:HeadBucket":                      "s3:ListBucket",
+	"s3:HeadObject":                      "s3:GetObject",
+	"s3:ListBuckets":                     "s3:ListAllMyBuckets",
+	"s3:ListObjectVersions":              "s3:ListBucketVersions",
+	"s3:ListObjects":                     "s3:ListBucket",
+	"s3:ListObjectsV2":                   "s3:ListBucket",
+	"lambda:GetFunctionConfiguration":    "lambda:GetFunction",
+}
+
+// steps are the functions of the steps of the dump run outside of the
+// reports, by step name.
+var steps = map[string]string{
+	"sessions":      "OpenSessions",
+	"organization":  "NewAccountsFromOrganization",
+	"ssm-inventory": "AttachSSMInventory",
+	"rightsizing":   "AttachRightsizing",
+	"monthly-cost":  "AttachMonthlyCost",
+	"tag-unmanaged": "TagUnmanaged",
+}
+
+// node is a function or a package variable, with the actions of the inputs
+// it creates and the functions and variables it references.
+type node struct {
+	actions map[string]bool
+	refs    map[string]bool
+}
+
+type generator struct {
+	nodes map[string]*node
+	// methods are the node names of the methods by method name, methods are
+	// matched by name only
+	methods map[string][]string
+}
+
+// iamAction returns the IAM action of the operation of the SDK package.
+func iamAction(pkg, operation string) string {
+	prefix := pkg
+	if value, ok := iamPrefixes[pkg]; ok {
+		prefix = value
+	}
+	if prefix == "apigateway" {
+		// API Gateway is authorized by HTTP method
+		return "apigateway:GET"
+	}
+	action := prefix + ":" + operation
+	if value, ok := iamActions[action]; ok {
+		return value
+	}
+	return action
+}
+
+// collect adds the actions and references of the body to the node, imports
+// are the SDK service packages of the file by name.
+func (g *generator) collect(n *node, body ast.Node, imports map[string]string) {
+	ast.Inspect(body, func(child ast.Node) bool {
+		switch typed := child.(type) {
+		case *ast.TypeSwitchStmt:
+			// the input types of the cases are matched, not created
+			g.collect(n, typed.Assign, imports)
+			for _, clause := range typed.Body.List {
+				for _, statement := range clause.(*ast.CaseClause).Body {
+					g.collect(n, statement, imports)
+				}
+			}
+			return false
+		case *ast.TypeAssertExpr:
+			g.collect(n, typed.X, imports)
+			return false
+		case *ast.SelectorExpr:
+			if ident, ok := typed.X.(*ast.Ident); ok {
+				if pkg, ok := imports[ident.Name]; ok {
+					name := typed.Sel.Name
+					if strings.HasSuffix(name, "Input") && len(name) > len("Input") {
+						n.actions[iamAction(pkg, strings.TrimSuffix(name, "Input"))] = true
+					}
+					return false
+				}
+			}
+			for _, method := range g.methods[typed.Sel.Name] {
+				n.refs[method] = true
+			}
+		case *ast.Ident:
+			n.refs[typed.Name] = true
+		}
+		return true
+	})
+}
+
+func imports(file *ast.File) map[string]string {
+	result := map[string]string{}
+	for _, spec := range file.Imports {
+		path, _ := strconv.Unquote(spec.Path.Value)
+		if !strings.HasPrefix(path, sdkServicePrefix) || strings.Contains(strings.TrimPrefix(path, sdkServicePrefix), "/") {
+			continue
+		}
+		pkg := strings.TrimPrefix(path, sdkServicePrefix)
+		name := pkg
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+		result[name] = pkg
+	}
+	return result
+}
+
+// reachable returns the sorted actions of the node and of the nodes it
+// references.
+func (g *generator) reachable(name string) []string {
+	actions := map[string]bool{}
+	seen := map[string]bool{}
+	queue := []string{name}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		if seen[current] {
+			continue
+		}
+		seen[current] = true
+		n, ok := g.nodes[current]
+		if !ok {
+			continue
+		}
+		for action := range n.actions {
+			actions[action] = true
+		}
+		for ref := range n.refs {
+			queue = append(queue, ref)
+		}
+	}
+
+	result := []string{}
+	for action := range actions {
+		result = append(result, action)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// serviceReports returns the report expressions of the Service literal by
+// report name.
+func serviceReports(literal *ast.CompositeLit) map[string]ast.Expr {
+	result := map[string]ast.Expr{}
+	for _, element := range literal.Elts {
+		field, ok := element.(*ast.KeyValueExpr)
+		if !ok {
+			continue
+		}
+		key, ok := field.Key.(*ast.Ident)
+		if !ok || (key.Name != "Reports" && key.Name != "OptionalReports") {
+			continue
+		}
+		reports, ok := field.Value.(*ast.CompositeLit)
+		if !ok {
+			continue
+		}
+		for _, report := range reports.Elts {
+			pair := report.(*ast.KeyValueExpr)
+			name, _ := strconv.Unquote(pair.Key.(*ast.BasicLit).Value)
+			result[name] = pair.Value
+		}
+	}
+	return result
+}
+
+// allServices returns the service variables of AllServices by service name.
+func allServices(decl *ast.FuncDecl) map[string]string {
+	result := map[string]string{}
+	ast.Inspect(decl, func(child ast.Node) bool {
+		pair, ok := child.(*ast.KeyValueExpr)
+		if !ok {
+			return true
+		}
+		key, ok := pair.Key.(*ast.BasicLit)
+		value, isIdent := pair.Value.(*ast.Ident)
+		if ok && isIdent {
+			name, _ := strconv.Unquote(key.Value)
+			result[name] = value.Name
+		}
+		return true
+	})
+	return result
+}
+
+func main() {
+	fileSet := token.NewFileSet()
+	packages, err := parser.ParseDir(fileSet, ".", func(info os.FileInfo) bool {
+		return !strings.HasSuffix(info.Name(), "_test.go")
+	}, 0)
+	if err != nil {
+		log.Fatal(err)
+	}
+	pkg, ok := packages["resources"]
+	if !ok {
+		log.Fatal("resources package not found")
+	}
+
+	g := &generator{nodes: map[string]*node{}, methods: map[string][]string{}}
+	services := map[string]string{}
+	for _, file := range pkg.Files {
+		for _, decl := range file.Decls {
+			if fn, ok := decl.(*ast.FuncDecl); ok && fn.Recv != nil {
+				name := fmt.Sprintf("method %s.%s", fileSet.Position(fn.Pos()), fn.Name.Name)
+				g.methods[fn.Name.Name] = append(g.methods[fn.Name.Name], name)
+			}
+		}
+	}
+
+	for _, file := range pkg.Files {
+		fileImports := imports(file)
+		for _, decl := range file.Decls {
+			switch typed := decl.(type) {
+			case *ast.FuncDecl:
+				name := typed.Name.Name
+				if typed.Recv != nil {
+					name = fmt.Sprintf("method %s.%s", fileSet.Position(typed.Pos()), typed.Name.Name)
+				}
+				if name == "AllServices" {
+					services = allServices(typed)
+				}
+				n := &node{actions: map[string]bool{}, refs: map[string]bool{}}
+				g.collect(n, typed, fileImports)
+				g.nodes[name] = n
+			case *ast.GenDecl:
+				if typed.Tok != token.VAR {
+					continue
+				}
+				for _, spec := range typed.Specs {
+					valueSpec := spec.(*ast.ValueSpec)
+					for i, ident := range valueSpec.Names {
+						if i >= len(valueSpec.Values) {
+							continue
+						}
+						value := valueSpec.Values[i]
+						if literal, ok := value.(*ast.CompositeLit); ok {
+							if typeName, ok := literal.Type.(*ast.Ident); ok && typeName.Name == "Service" {
+								for report, expr := range serviceReports(literal) {
+									n := &node{actions: map[string]bool{}, refs: map[string]bool{}}
+									g.collect(n, expr, fileImports)
+									g.nodes[ident.Name+"/"+report] = n
+								}
+								continue
+							}
+						}
+						n := &node{actions: map[string]bool{}, refs: map[string]bool{}}
+						g.collect(n, value, fileImports)
+						g.nodes[ident.Name] = n
+					}
+				}
+			}
+		}
+	}
+
+	reports := map[string][]string{}
+	for serviceName, variable := range services {
+		for name := range g.nodes {
+			if strings.HasPrefix(name, variable+"/") {
+				reports[serviceName+":"+strings.TrimPrefix(name, variable+"/")] = g.reachable(name)
+			}
+		}
+	}
+
+	stepActions := map[string][]string{}
+	for step, function := range steps {
+		if _, ok := g.nodes[function]; !ok {
+			log.Fatalf("function %s of step %s not found", function, step)
+		}
+		stepActions[step] = g.reachable(function)
+	}
+
+	buffer := &bytes.Buffer{}
+	fmt.Fprintln(buffer, "// Code generated by go run actions_gen.go; DO NOT EDIT.")
+	fmt.Fprintln(buffer)
+	fmt.Fprintln(buffer, "package resources")
+	fmt.Fprintln(buffer)
+	fmt.Fprintln(buffer, "// ReportActions are the IAM actions of the API calls of the reports, by")
+	fmt.Fprintln(buffer, "// service:report.")
+	writeActions(buffer, "ReportActions", reports)
+	fmt.Fprintln(buffer)
+	fmt.Fprintln(buffer, "// StepActions are the IAM actions of the API calls of the steps of the dump")
+	fmt.Fprintln(buffer, "// run outside of the reports, by step.")
+	writeActions(buffer, "StepActions", stepActions)
+
+	source, err := format.Source(buffer.Bytes())
+	if err != nil {
+		log.Fatal(err)
+	}
+	err = ioutil.WriteFile("report_actions.go", source, 0644)
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+func writeActions(buffer *bytes.Buffer, name string, actions map[string][]string) {
+	keys := []string{}
+	for key := range actions {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	fmt.Fprintf(buffer, "var %s = map[string][]string{\n", name)
+	for _, key := range keys {
+		quoted := []string{}
+		for _, action := range actions[key] {
+			quoted = append(quoted, strconv.Quote(action))
+		}
+		fmt.Fprintf(buffer, "%q: {%s},\n", key, strings.Join(quoted, ", "))
+	}
+	fmt.Fprintln(buffer, "}")
+}
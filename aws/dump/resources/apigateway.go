@@ -0,0 +1,77 @@
+package resources
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/service/apigateway"
+	"github.com/aws/aws-sdk-go/service/apigatewayv2"
+	"github.com/fatih/structs"
+)
+
+var (
+	APIGatewayService = Service{
+		Name: "apigateway",
+		Reports: map[string]Report{
+			"rest-apis": APIGatewayListRestApis,
+			"apis":      APIGatewayListApis,
+		},
+	}
+)
+
+func APIGatewayListRestApis(session *Session) *ReportResult {
+	client := apigateway.New(session.Session, session.Config)
+
+	result := &ReportResult{}
+	result.Error = client.GetRestApisPages(&apigateway.GetRestApisInput{},
+		func(page *apigateway.GetRestApisOutput, lastPage bool) bool {
+			for _, restAPI := range page.Items {
+				result.Resources = append(result.Resources, Resource{
+					ID:        *restAPI.Id,
+					ARN:       fmt.Sprintf("arn:aws:apigateway:%s::/restapis/%s", *session.Config.Region, *restAPI.Id),
+					AccountID: session.AccountID,
+					Service:   "apigateway",
+					Type:      "restapi",
+					Region:    *session.Config.Region,
+					Metadata:  structs.Map(restAPI),
+				})
+			}
+
+			return true
+		})
+
+	return result
+}
+
+// APIGatewayListApis lists the HTTP and WebSocket APIs.
+func APIGatewayListApis(session *Session) *ReportResult {
+	client := apigatewayv2.New(session.Session, session.Config)
+
+	result := &ReportResult{}
+	input := &apigatewayv2.GetApisInput{}
+	for {
+		page, err := client.GetApis(input)
+		if err != nil {
+			result.Error = err
+			return result
+		}
+
+		for _, api := range page.Items {
+			result.Resources = append(result.Resources, Resource{
+				ID:        *api.ApiId,
+				ARN:       fmt.Sprintf("arn:aws:apigateway:%s::/apis/%s", *session.Config.Region, *api.ApiId),
+				AccountID: session.AccountID,
+				Service:   "apigateway",
+				Type:      "api",
+				Region:    *session.Config.Region,
+				Metadata:  structs.Map(api),
+			})
+		}
+
+		if page.NextToken == nil {
+			break
+		}
+		input.NextToken = page.NextToken
+	}
+
+	return result
+}
@@ -0,0 +1,76 @@
+package resources
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/apprunner"
+	"github.com/fatih/structs"
+)
+
+var (
+	AppRunnerService = Service{
+		Name: "apprunner",
+		Reports: map[string]Report{
+			"services": AppRunnerListServices,
+		},
+	}
+)
+
+func AppRunnerListServices(session *Session) *ReportResult {
+	client := apprunner.New(session.Session, session.Config)
+
+	result := &ReportResult{}
+	input := &apprunner.ListServicesInput{}
+	for {
+		page, err := client.ListServices(input)
+		if err != nil {
+			result.Error = err
+			return result
+		}
+
+		for _, summary := range page.ServiceSummaryList {
+			described, err := client.DescribeService(&apprunner.DescribeServiceInput{ServiceArn: summary.ServiceArn})
+			if err != nil {
+				result.Error = err
+				return result
+			}
+
+			service := described.Service
+			redactAppRunnerEnvironment(service.SourceConfiguration)
+
+			result.Resources = append(result.Resources, Resource{
+				ID:        *service.ServiceId,
+				ARN:       *service.ServiceArn,
+				AccountID: session.AccountID,
+				Service:   "apprunner",
+				Type:      "service",
+				Region:    *session.Config.Region,
+				Metadata:  structs.Map(service),
+			})
+		}
+
+		if page.NextToken == nil {
+			break
+		}
+		input.NextToken = page.NextToken
+	}
+
+	return result
+}
+
+// redactAppRunnerEnvironment keeps the environment variable names but drops
+// their values, which often contain credentials.
+func redactAppRunnerEnvironment(source *apprunner.SourceConfiguration) {
+	if source == nil {
+		return
+	}
+
+	if source.ImageRepository != nil && source.ImageRepository.ImageConfiguration != nil {
+		redactEnvironment(source.ImageRepository.ImageConfiguration.RuntimeEnvironmentVariables)
+	}
+
+	if source.CodeRepository != nil &&
+		source.CodeRepository.CodeConfiguration != nil &&
+		source.CodeRepository.CodeConfiguration.CodeConfigurationValues != nil {
+		redactEnvironment(source.CodeRepository.CodeConfiguration.CodeConfigurationValues.RuntimeEnvironmentVariables)
+	}
+}
@@ -0,0 +1,231 @@
+package resources
+
+import (
+	"crypto/sha256"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/appsync"
+	"github.com/fatih/structs"
+)
+
+var (
+	AppSyncService = Service{
+		Name: "appsync",
+		Reports: map[string]Report{
+			"graphql-apis": AppSyncListGraphqlApis,
+			"data-sources": AppSyncListDataSources,
+			"resolvers":    AppSyncListResolvers,
+			"functions":    AppSyncListFunctions,
+			"api-keys":     AppSyncListApiKeys,
+		},
+	}
+)
+
+func appSyncListGraphqlApis(client *appsync.AppSync) ([]*appsync.GraphqlApi, error) {
+	apis := []*appsync.GraphqlApi{}
+	input := &appsync.ListGraphqlApisInput{}
+	for {
+		page, err := client.ListGraphqlApis(input)
+		if err != nil {
+			return nil, err
+		}
+		apis = append(apis, page.GraphqlApis...)
+
+		if page.NextToken == nil {
+			break
+		}
+		input.NextToken = page.NextToken
+	}
+	return apis, nil
+}
+
+func newAppSyncResource(session *Session, apiID, id, arn, resourceType string, metadata interface{}) Resource {
+	resource := Resource{
+		ID:        id,
+		ARN:       arn,
+		AccountID: session.AccountID,
+		Service:   "appsync",
+		Type:      resourceType,
+		Region:    *session.Config.Region,
+		Metadata:  structs.Map(metadata),
+	}
+	resource.Metadata["ApiId"] = apiID
+	return resource
+}
+
+func AppSyncListGraphqlApis(session *Session) *ReportResult {
+	client := appsync.New(session.Session, session.Config)
+
+	apis, err := appSyncListGraphqlApis(client)
+	if err != nil {
+		return &ReportResult{nil, err}
+	}
+
+	result := &ReportResult{}
+	for _, api := range apis {
+		resource, err := NewResource(*api.Arn, api)
+		if err != nil {
+			result.Error = err
+			return result
+		}
+		resource.ID = *api.ApiId
+		result.Resources = append(result.Resources, *resource)
+	}
+	return result
+}
+
+func AppSyncListDataSources(session *Session) *ReportResult {
+	client := appsync.New(session.Session, session.Config)
+
+	apis, err := appSyncListGraphqlApis(client)
+	if err != nil {
+		return &ReportResult{nil, err}
+	}
+
+	result := &ReportResult{}
+	for _, api := range apis {
+		input := &appsync.ListDataSourcesInput{ApiId: api.ApiId}
+		for {
+			page, err := client.ListDataSources(input)
+			if err != nil {
+				result.Error = err
+				return result
+			}
+
+			for _, dataSource := range page.DataSources {
+				id := fmt.Sprintf("%s_%s", *api.ApiId, *dataSource.Name)
+				result.Resources = append(result.Resources, newAppSyncResource(session, *api.ApiId, id, aws.StringValue(dataSource.DataSourceArn), "data-source", dataSource))
+			}
+
+			if page.NextToken == nil {
+				break
+			}
+			input.NextToken = page.NextToken
+		}
+	}
+	return result
+}
+
+func AppSyncListResolvers(session *Session) *ReportResult {
+	client := appsync.New(session.Session, session.Config)
+
+	apis, err := appSyncListGraphqlApis(client)
+	if err != nil {
+		return &ReportResult{nil, err}
+	}
+
+	result := &ReportResult{}
+	for _, api := range apis {
+		typeNames := []*string{}
+		typesInput := &appsync.ListTypesInput{ApiId: api.ApiId, Format: aws.String(appsync.TypeDefinitionFormatSdl)}
+		for {
+			page, err := client.ListTypes(typesInput)
+			if err != nil {
+				result.Error = err
+				return result
+			}
+			for _, t := range page.Types {
+				typeNames = append(typeNames, t.Name)
+			}
+
+			if page.NextToken == nil {
+				break
+			}
+			typesInput.NextToken = page.NextToken
+		}
+
+		for _, typeName := range typeNames {
+			input := &appsync.ListResolversInput{ApiId: api.ApiId, TypeName: typeName}
+			for {
+				page, err := client.ListResolvers(input)
+				if err != nil {
+					result.Error = err
+					return result
+				}
+
+				for _, resolver := range page.Resolvers {
+					id := fmt.Sprintf("%s_%s_%s", *api.ApiId, *resolver.TypeName, *resolver.FieldName)
+					result.Resources = append(result.Resources, newAppSyncResource(session, *api.ApiId, id, aws.StringValue(resolver.ResolverArn), "resolver", resolver))
+				}
+
+				if page.NextToken == nil {
+					break
+				}
+				input.NextToken = page.NextToken
+			}
+		}
+	}
+	return result
+}
+
+func AppSyncListFunctions(session *Session) *ReportResult {
+	client := appsync.New(session.Session, session.Config)
+
+	apis, err := appSyncListGraphqlApis(client)
+	if err != nil {
+		return &ReportResult{nil, err}
+	}
+
+	result := &ReportResult{}
+	for _, api := range apis {
+		input := &appsync.ListFunctionsInput{ApiId: api.ApiId}
+		for {
+			page, err := client.ListFunctions(input)
+			if err != nil {
+				result.Error = err
+				return result
+			}
+
+			for _, function := range page.Functions {
+				id := fmt.Sprintf("%s_%s", *api.ApiId, *function.FunctionId)
+				result.Resources = append(result.Resources, newAppSyncResource(session, *api.ApiId, id, aws.StringValue(function.FunctionArn), "function", function))
+			}
+
+			if page.NextToken == nil {
+				break
+			}
+			input.NextToken = page.NextToken
+		}
+	}
+	return result
+}
+
+// AppSyncListApiKeys lists the API keys metadata. The key itself is its ID so
+// only a hash of it is kept.
+func AppSyncListApiKeys(session *Session) *ReportResult {
+	client := appsync.New(session.Session, session.Config)
+
+	apis, err := appSyncListGraphqlApis(client)
+	if err != nil {
+		return &ReportResult{nil, err}
+	}
+
+	result := &ReportResult{}
+	for _, api := range apis {
+		input := &appsync.ListApiKeysInput{ApiId: api.ApiId}
+		for {
+			page, err := client.ListApiKeys(input)
+			if err != nil {
+				result.Error = err
+				return result
+			}
+
+			for _, apiKey := range page.ApiKeys {
+				hash := fmt.Sprintf("%x", sha256.Sum256([]byte(*apiKey.Id)))[:16]
+				apiKey.Id = nil
+
+				id := fmt.Sprintf("%s_%s", *api.ApiId, hash)
+				resource := newAppSyncResource(session, *api.ApiId, id, "", "api-key", apiKey)
+				resource.Metadata["IdSha256"] = hash
+				result.Resources = append(result.Resources, resource)
+			}
+
+			if page.NextToken == nil {
+				break
+			}
+			input.NextToken = page.NextToken
+		}
+	}
+	return result
+}
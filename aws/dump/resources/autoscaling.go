@@ -1,7 +1,11 @@
 package resources
 
 import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/autoscaling"
+	"github.com/aws/aws-sdk-go/service/ec2"
 	"github.com/fatih/structs"
 )
 
@@ -11,6 +15,10 @@ var (
 		Reports: map[string]Report{
 			"groups":                AutoScalingListGroups,
 			"launch-configurations": AutoScalingListLaunchConfigurations,
+			"instances":             AutoScalingListInstances,
+			"launch-templates":      AutoScalingListLaunchTemplates,
+			"policies":              AutoScalingListPolicies,
+			"scheduled-actions":     AutoScalingListScheduledActions,
 		},
 	}
 )
@@ -32,6 +40,19 @@ func AutoScalingListGroups(session *Session) *ReportResult {
 					Region:    *session.Config.Region,
 					Metadata:  structs.Map(autoScalingGroup),
 				}
+
+				instanceIDs := []string{}
+				for _, instance := range autoScalingGroup.Instances {
+					instanceIDs = append(instanceIDs, *instance.InstanceId)
+				}
+				resource.Metadata["InstanceIds"] = instanceIDs
+
+				suspendedProcesses := []string{}
+				for _, process := range autoScalingGroup.SuspendedProcesses {
+					suspendedProcesses = append(suspendedProcesses, *process.ProcessName)
+				}
+				resource.Metadata["SuspendedProcessNames"] = suspendedProcesses
+
 				resources = append(resources, resource)
 			}
 
@@ -66,3 +87,124 @@ func AutoScalingListLaunchConfigurations(session *Session) *ReportResult {
 
 	return &ReportResult{resources, err}
 }
+
+func AutoScalingListInstances(session *Session) *ReportResult {
+	client := autoscaling.New(session.Session, session.Config)
+
+	resources := []Resource{}
+	err := client.DescribeAutoScalingInstancesPages(&autoscaling.DescribeAutoScalingInstancesInput{},
+		func(page *autoscaling.DescribeAutoScalingInstancesOutput, lastPage bool) bool {
+			for _, instance := range page.AutoScalingInstances {
+				resources = append(resources, Resource{
+					ID:        *instance.InstanceId,
+					AccountID: session.AccountID,
+					Service:   "autoscaling",
+					Type:      "instance",
+					Region:    *session.Config.Region,
+					Metadata:  structs.Map(instance),
+				})
+			}
+
+			return true
+		})
+
+	return &ReportResult{resources, err}
+}
+
+// AutoScalingListLaunchTemplates lists the launch templates with the data of
+// their latest and default versions. ec2:launch-templates lists all the versions.
+func AutoScalingListLaunchTemplates(session *Session) *ReportResult {
+	client := ec2.New(session.Session, session.Config)
+
+	result := &ReportResult{}
+	err := client.DescribeLaunchTemplatesPages(&ec2.DescribeLaunchTemplatesInput{},
+		func(page *ec2.DescribeLaunchTemplatesOutput, lastPage bool) bool {
+			for _, launchTemplate := range page.LaunchTemplates {
+				resource := Resource{
+					ID:        *launchTemplate.LaunchTemplateId,
+					ARN:       fmt.Sprintf("arn:aws:ec2:%s:%s:launch-template/%s", *session.Config.Region, session.AccountID, *launchTemplate.LaunchTemplateId),
+					AccountID: session.AccountID,
+					Service:   "autoscaling",
+					Type:      "launch-template",
+					Region:    *session.Config.Region,
+					Metadata:  structs.Map(launchTemplate),
+				}
+
+				versions, err := client.DescribeLaunchTemplateVersions(&ec2.DescribeLaunchTemplateVersionsInput{
+					LaunchTemplateId: launchTemplate.LaunchTemplateId,
+					Versions:         aws.StringSlice([]string{"$Latest", "$Default"}),
+				})
+				if err != nil {
+					result.Error = err
+					return false
+				}
+
+				for _, version := range versions.LaunchTemplateVersions {
+					if aws.Int64Value(version.VersionNumber) == aws.Int64Value(launchTemplate.LatestVersionNumber) {
+						resource.Metadata["LatestVersion"] = structs.Map(version)
+					}
+					if aws.BoolValue(version.DefaultVersion) {
+						resource.Metadata["DefaultVersion"] = structs.Map(version)
+					}
+				}
+
+				result.Resources = append(result.Resources, resource)
+			}
+
+			return true
+		})
+
+	if result.Error != nil {
+		return result
+	}
+	result.Error = err
+	return result
+}
+
+func AutoScalingListPolicies(session *Session) *ReportResult {
+	client := autoscaling.New(session.Session, session.Config)
+
+	resources := []Resource{}
+	err := client.DescribePoliciesPages(&autoscaling.DescribePoliciesInput{},
+		func(page *autoscaling.DescribePoliciesOutput, lastPage bool) bool {
+			for _, policy := range page.ScalingPolicies {
+				resources = append(resources, Resource{
+					ID:        *policy.PolicyName,
+					ARN:       *policy.PolicyARN,
+					AccountID: session.AccountID,
+					Service:   "autoscaling",
+					Type:      "policy",
+					Region:    *session.Config.Region,
+					Metadata:  structs.Map(policy),
+				})
+			}
+
+			return true
+		})
+
+	return &ReportResult{resources, err}
+}
+
+func AutoScalingListScheduledActions(session *Session) *ReportResult {
+	client := autoscaling.New(session.Session, session.Config)
+
+	resources := []Resource{}
+	err := client.DescribeScheduledActionsPages(&autoscaling.DescribeScheduledActionsInput{},
+		func(page *autoscaling.DescribeScheduledActionsOutput, lastPage bool) bool {
+			for _, action := range page.ScheduledUpdateGroupActions {
+				resources = append(resources, Resource{
+					ID:        *action.ScheduledActionName,
+					ARN:       *action.ScheduledActionARN,
+					AccountID: session.AccountID,
+					Service:   "autoscaling",
+					Type:      "scheduled-action",
+					Region:    *session.Config.Region,
+					Metadata:  structs.Map(action),
+				})
+			}
+
+			return true
+		})
+
+	return &ReportResult{resources, err}
+}
@@ -2,10 +2,14 @@ package resources
 
 import (
 	"encoding/json"
+	"fmt"
 	"io/ioutil"
+	"sort"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/client"
 	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/ec2"
 	"github.com/aws/aws-sdk-go/service/sts"
 	"github.com/hamstah/awstools/common"
 )
@@ -40,25 +44,99 @@ func NewAccountsFromFile(filename string) ([]*Account, error) {
 	return accounts, nil
 }
 
+const (
+	// AllRegions in the regions of an account is replaced by all the regions
+	// enabled in the account.
+	AllRegions = "all"
+
+	// region used to list the enabled regions
+	defaultRegion = "us-east-1"
+)
+
+func (account *Account) openSession(region string) (*session.Session, *aws.Config) {
+	return common.OpenSession(&common.SessionFlags{
+		RoleArn:         &account.RoleARN,
+		RoleExternalID:  &account.ExternalID,
+		RolePolicy:      &account.RolePolicy,
+		Region:          &region,
+		RoleSessionName: &account.SessionName,
+
+		MFASerialNumber: aws.String(""),
+		MFATokenCode:    aws.String(""),
+	})
+}
+
+// expandRegions replaces AllRegions with the regions enabled in the account.
+func (account *Account) expandRegions() error {
+	regions := []string{}
+	all := false
+	for _, region := range account.Regions {
+		if region == AllRegions {
+			all = true
+			continue
+		}
+		regions = append(regions, region)
+	}
+	if !all {
+		return nil
+	}
+
+	// the first region is used by global services
+	if len(regions) == 0 {
+		regions = []string{defaultRegion}
+	}
+
+	// without AllRegions only the enabled regions are returned
+	sess, conf := account.openSession(regions[0])
+	output, err := ec2.New(sess, conf).DescribeRegions(&ec2.DescribeRegionsInput{})
+	if err != nil {
+		return err
+	}
+
+	seen := map[string]bool{}
+	for _, region := range regions {
+		seen[region] = true
+	}
+	enabled := []string{}
+	for _, region := range output.Regions {
+		if !seen[*region.RegionName] {
+			seen[*region.RegionName] = true
+			enabled = append(enabled, *region.RegionName)
+		}
+	}
+	sort.Strings(enabled)
+
+	regions = append(regions, enabled...)
+	account.Regions = regions
+	return nil
+}
+
 func OpenSessions(accounts []*Account) error {
 	for _, account := range accounts {
+		err := account.expandRegions()
+		if err != nil {
+			return err
+		}
+
 		account.Sessions = []*Session{}
 		for _, region := range account.Regions {
-			sess, conf := common.OpenSession(&common.SessionFlags{
-				RoleArn:         &account.RoleARN,
-				RoleExternalID:  &account.ExternalID,
-				RolePolicy:      &account.RolePolicy,
-				Region:          &region,
-				RoleSessionName: &account.SessionName,
-
-				MFASerialNumber: aws.String(""),
-				MFATokenCode:    aws.String(""),
-			})
+			sess, conf := account.openSession(region)
+			if RecordFixtures != nil {
+				RecordFixtures.Record(conf)
+			}
+			if MaxRetries > 0 {
+				conf.MaxRetries = aws.Int(MaxRetries)
+				conf.Retryer = client.DefaultRetryer{NumMaxRetries: MaxRetries}
+			}
+			if RateLimits != nil {
+				RateLimits.Attach(&sess.Handlers, account.RoleARN)
+			}
+			APICalls.Attach(&sess.Handlers)
 
 			stsClient := sts.New(sess, conf)
 			identity, err := stsClient.GetCallerIdentity(&sts.GetCallerIdentityInput{})
 			if err != nil {
-				return err
+				return fmt.Errorf("failed to open a session with role %q in %s: %s", account.RoleARN, region, err)
 			}
 			session := &Session{
 				Session:   sess,
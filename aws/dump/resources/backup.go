@@ -0,0 +1,235 @@
+package resources
+
+import (
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/backup"
+	"github.com/fatih/structs"
+)
+
+var (
+	BackupService = Service{
+		Name: "backup",
+		Reports: map[string]Report{
+			"vaults":                   BackupListVaults,
+			"plans":                    BackupListPlans,
+			"recovery-points":          BackupListRecoveryPoints,
+			"recovery-point-summaries": BackupListRecoveryPointSummaries,
+			"coverage":                 BackupListCoverage,
+		},
+	}
+)
+
+func BackupListVaults(session *Session) *ReportResult {
+	client := backup.New(session.Session, session.Config)
+
+	vaults, err := backupListVaults(client)
+	if err != nil {
+		return &ReportResult{nil, err}
+	}
+
+	result := &ReportResult{}
+	for _, vault := range vaults {
+		resource, err := NewResource(*vault.BackupVaultArn, vault)
+		if err != nil {
+			result.Error = err
+			return result
+		}
+
+		res, err := client.GetBackupVaultAccessPolicy(&backup.GetBackupVaultAccessPolicyInput{
+			BackupVaultName: vault.BackupVaultName,
+		})
+		if err != nil {
+			if !IsErrorCode(err, backup.ErrCodeResourceNotFoundException) {
+				result.Error = err
+				return result
+			}
+		} else {
+			resource.Metadata["AccessPolicy"] = aws.StringValue(res.Policy)
+		}
+
+		result.Resources = append(result.Resources, *resource)
+	}
+
+	return result
+}
+
+func backupListVaults(client *backup.Backup) ([]*backup.VaultListMember, error) {
+	vaults := []*backup.VaultListMember{}
+	err := client.ListBackupVaultsPages(&backup.ListBackupVaultsInput{},
+		func(page *backup.ListBackupVaultsOutput, lastPage bool) bool {
+			vaults = append(vaults, page.BackupVaultList...)
+			return true
+		})
+	return vaults, err
+}
+
+func BackupListPlans(session *Session) *ReportResult {
+	client := backup.New(session.Session, session.Config)
+
+	result := &ReportResult{}
+	err := client.ListBackupPlansPages(&backup.ListBackupPlansInput{},
+		func(page *backup.ListBackupPlansOutput, lastPage bool) bool {
+			for _, plan := range page.BackupPlansList {
+				resource, err := NewResource(*plan.BackupPlanArn, plan)
+				if err != nil {
+					result.Error = err
+					return false
+				}
+
+				// includes the rules
+				described, err := client.GetBackupPlan(&backup.GetBackupPlanInput{BackupPlanId: plan.BackupPlanId})
+				if err != nil {
+					result.Error = err
+					return false
+				}
+				resource.Metadata["BackupPlan"] = structs.Map(described.BackupPlan)
+
+				selections, err := backupListSelections(client, plan.BackupPlanId)
+				if err != nil {
+					result.Error = err
+					return false
+				}
+				resource.Metadata["Selections"] = selections
+
+				result.Resources = append(result.Resources, *resource)
+			}
+			return true
+		})
+
+	if result.Error != nil {
+		return result
+	}
+	result.Error = err
+	return result
+}
+
+func backupListSelections(client *backup.Backup, planID *string) ([]map[string]interface{}, error) {
+	selections := []map[string]interface{}{}
+
+	var selectionErr error
+	err := client.ListBackupSelectionsPages(&backup.ListBackupSelectionsInput{BackupPlanId: planID},
+		func(page *backup.ListBackupSelectionsOutput, lastPage bool) bool {
+			for _, summary := range page.BackupSelectionsList {
+				// includes the resources and conditions
+				selection, err := client.GetBackupSelection(&backup.GetBackupSelectionInput{
+					BackupPlanId: planID,
+					SelectionId:  summary.SelectionId,
+				})
+				if err != nil {
+					selectionErr = err
+					return false
+				}
+				selections = append(selections, structs.Map(selection))
+			}
+			return true
+		})
+
+	if err != nil {
+		return nil, err
+	}
+	return selections, selectionErr
+}
+
+func BackupListRecoveryPoints(session *Session) *ReportResult {
+	client := backup.New(session.Session, session.Config)
+
+	vaults, err := backupListVaults(client)
+	if err != nil {
+		return &ReportResult{nil, err}
+	}
+
+	result := &ReportResult{}
+	for _, vault := range vaults {
+		err := client.ListRecoveryPointsByBackupVaultPages(&backup.ListRecoveryPointsByBackupVaultInput{
+			BackupVaultName: vault.BackupVaultName,
+		},
+			func(page *backup.ListRecoveryPointsByBackupVaultOutput, lastPage bool) bool {
+				for _, recoveryPoint := range page.RecoveryPoints {
+					result.Resources = append(result.Resources, Resource{
+						ID:        *recoveryPoint.RecoveryPointArn,
+						ARN:       *recoveryPoint.RecoveryPointArn,
+						AccountID: session.AccountID,
+						Service:   "backup",
+						Type:      "recovery-point",
+						Region:    *session.Config.Region,
+						Metadata:  structs.Map(recoveryPoint),
+					})
+				}
+				return true
+			})
+		if err != nil {
+			result.Error = err
+			return result
+		}
+	}
+
+	return result
+}
+
+// BackupListRecoveryPointSummaries summarises the recovery points of each vault
+// by resource type and status.
+func BackupListRecoveryPointSummaries(session *Session) *ReportResult {
+	client := backup.New(session.Session, session.Config)
+
+	vaults, err := backupListVaults(client)
+	if err != nil {
+		return &ReportResult{nil, err}
+	}
+
+	result := &ReportResult{}
+	for _, vault := range vaults {
+		count := 0
+		byResourceType := map[string]int{}
+		byStatus := map[string]int{}
+		protectedResources := map[string]bool{}
+		var oldest, newest *time.Time
+
+		err := client.ListRecoveryPointsByBackupVaultPages(&backup.ListRecoveryPointsByBackupVaultInput{
+			BackupVaultName: vault.BackupVaultName,
+		},
+			func(page *backup.ListRecoveryPointsByBackupVaultOutput, lastPage bool) bool {
+				for _, recoveryPoint := range page.RecoveryPoints {
+					count++
+					byResourceType[aws.StringValue(recoveryPoint.ResourceType)]++
+					byStatus[aws.StringValue(recoveryPoint.Status)]++
+					protectedResources[aws.StringValue(recoveryPoint.ResourceArn)] = true
+
+					creationDate := recoveryPoint.CreationDate
+					if creationDate == nil {
+						continue
+					}
+					if oldest == nil || creationDate.Before(*oldest) {
+						oldest = creationDate
+					}
+					newest = newestTime(newest, creationDate)
+				}
+				return true
+			})
+		if err != nil {
+			result.Error = err
+			return result
+		}
+
+		result.Resources = append(result.Resources, Resource{
+			ID:        *vault.BackupVaultName,
+			ARN:       *vault.BackupVaultArn,
+			AccountID: session.AccountID,
+			Service:   "backup",
+			Type:      "recovery-point-summary",
+			Region:    *session.Config.Region,
+			Metadata: map[string]interface{}{
+				"BackupVaultName":         *vault.BackupVaultName,
+				"RecoveryPoints":          count,
+				"ProtectedResources":      len(protectedResources),
+				"ByResourceType":          byResourceType,
+				"ByStatus":                byStatus,
+				"OldestRecoveryPointTime": oldest,
+				"NewestRecoveryPointTime": newest,
+			},
+		})
+	}
+
+	return result
+}
@@ -0,0 +1,280 @@
+package resources
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/backup"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/efs"
+	"github.com/aws/aws-sdk-go/service/rds"
+)
+
+var (
+	// BackupCoverageMaxAge is how old the latest recovery point of a resource
+	// can be before it is reported as not covered.
+	BackupCoverageMaxAge = 7 * 24 * time.Hour
+)
+
+type backupCandidate struct {
+	ID           string
+	ARN          string
+	ResourceType string
+
+	LastBackupTime   *time.Time
+	LastSnapshotTime *time.Time
+}
+
+// BackupListCoverage reports the latest recovery point, from AWS Backup or
+// native snapshots, of stateful resources (RDS, EBS, EFS and DynamoDB).
+func BackupListCoverage(session *Session) *ReportResult {
+	candidates := map[string]*backupCandidate{}
+
+	errors := ReportErrors{}
+	for _, fn := range []func(*Session, map[string]*backupCandidate) error{
+		backupCoverageRDS,
+		backupCoverageEBS,
+		backupCoverageEFS,
+		backupCoverageDynamoDB,
+		backupCoverageProtectedResources,
+	} {
+		err := fn(session, candidates)
+		if err != nil && !errors.Add(err) {
+			return &ReportResult{nil, err}
+		}
+	}
+
+	now := time.Now().UTC()
+	result := &ReportResult{Error: errors.ErrorOrNil()}
+	for _, candidate := range candidates {
+		result.Resources = append(result.Resources, Resource{
+			ID:        candidate.ARN,
+			AccountID: session.AccountID,
+			Service:   "backup",
+			Type:      "coverage",
+			Region:    *session.Config.Region,
+			Metadata:  backupCoverageMetadata(candidate, now, BackupCoverageMaxAge),
+		})
+	}
+	return result
+}
+
+// backupCoverageMetadata flags candidates without a recovery point newer than maxAge.
+func backupCoverageMetadata(candidate *backupCandidate, now time.Time, maxAge time.Duration) map[string]interface{} {
+	lastRecoveryPoint := newestTime(candidate.LastBackupTime, candidate.LastSnapshotTime)
+
+	return map[string]interface{}{
+		"ResourceId":            candidate.ID,
+		"ResourceArn":           candidate.ARN,
+		"ResourceType":          candidate.ResourceType,
+		"LastBackupTime":        candidate.LastBackupTime,
+		"LastSnapshotTime":      candidate.LastSnapshotTime,
+		"LastRecoveryPointTime": lastRecoveryPoint,
+		"Covered":               lastRecoveryPoint != nil && now.Sub(*lastRecoveryPoint) <= maxAge,
+	}
+}
+
+func newestTime(times ...*time.Time) *time.Time {
+	var newest *time.Time
+	for _, t := range times {
+		if t != nil && (newest == nil || t.After(*newest)) {
+			newest = t
+		}
+	}
+	return newest
+}
+
+func backupCoverageRDS(session *Session, candidates map[string]*backupCandidate) error {
+	client := rds.New(session.Session, session.Config)
+
+	instances := map[string]*backupCandidate{}
+	err := client.DescribeDBInstancesPages(&rds.DescribeDBInstancesInput{},
+		func(page *rds.DescribeDBInstancesOutput, lastPage bool) bool {
+			for _, instance := range page.DBInstances {
+				// aurora instances are backed up with their cluster
+				if instance.DBClusterIdentifier != nil {
+					continue
+				}
+				candidate := &backupCandidate{
+					ID:           *instance.DBInstanceIdentifier,
+					ARN:          *instance.DBInstanceArn,
+					ResourceType: "RDS",
+				}
+				instances[candidate.ID] = candidate
+				candidates[candidate.ARN] = candidate
+			}
+			return true
+		})
+	if err != nil {
+		return err
+	}
+
+	clusters := map[string]*backupCandidate{}
+	err = client.DescribeDBClustersPages(&rds.DescribeDBClustersInput{},
+		func(page *rds.DescribeDBClustersOutput, lastPage bool) bool {
+			for _, cluster := range page.DBClusters {
+				candidate := &backupCandidate{
+					ID:           *cluster.DBClusterIdentifier,
+					ARN:          *cluster.DBClusterArn,
+					ResourceType: "Aurora",
+				}
+				clusters[candidate.ID] = candidate
+				candidates[candidate.ARN] = candidate
+			}
+			return true
+		})
+	if err != nil {
+		return err
+	}
+
+	err = client.DescribeDBSnapshotsPages(&rds.DescribeDBSnapshotsInput{},
+		func(page *rds.DescribeDBSnapshotsOutput, lastPage bool) bool {
+			for _, snapshot := range page.DBSnapshots {
+				if candidate, ok := instances[aws.StringValue(snapshot.DBInstanceIdentifier)]; ok {
+					candidate.LastSnapshotTime = newestTime(candidate.LastSnapshotTime, snapshot.SnapshotCreateTime)
+				}
+			}
+			return true
+		})
+	if err != nil {
+		return err
+	}
+
+	return client.DescribeDBClusterSnapshotsPages(&rds.DescribeDBClusterSnapshotsInput{},
+		func(page *rds.DescribeDBClusterSnapshotsOutput, lastPage bool) bool {
+			for _, snapshot := range page.DBClusterSnapshots {
+				if candidate, ok := clusters[aws.StringValue(snapshot.DBClusterIdentifier)]; ok {
+					candidate.LastSnapshotTime = newestTime(candidate.LastSnapshotTime, snapshot.SnapshotCreateTime)
+				}
+			}
+			return true
+		})
+}
+
+func backupCoverageEBS(session *Session, candidates map[string]*backupCandidate) error {
+	client := ec2.New(session.Session, session.Config)
+
+	volumes := map[string]*backupCandidate{}
+	err := client.DescribeVolumesPages(&ec2.DescribeVolumesInput{},
+		func(page *ec2.DescribeVolumesOutput, lastPage bool) bool {
+			for _, volume := range page.Volumes {
+				candidate := &backupCandidate{
+					ID:           *volume.VolumeId,
+					ARN:          fmt.Sprintf("arn:aws:ec2:%s:%s:volume/%s", *session.Config.Region, session.AccountID, *volume.VolumeId),
+					ResourceType: "EBS",
+				}
+				volumes[candidate.ID] = candidate
+				candidates[candidate.ARN] = candidate
+			}
+			return true
+		})
+	if err != nil {
+		return err
+	}
+
+	return client.DescribeSnapshotsPages(&ec2.DescribeSnapshotsInput{OwnerIds: []*string{aws.String("self")}},
+		func(page *ec2.DescribeSnapshotsOutput, lastPage bool) bool {
+			for _, snapshot := range page.Snapshots {
+				if aws.StringValue(snapshot.State) != ec2.SnapshotStateCompleted {
+					continue
+				}
+				if candidate, ok := volumes[aws.StringValue(snapshot.VolumeId)]; ok {
+					candidate.LastSnapshotTime = newestTime(candidate.LastSnapshotTime, snapshot.StartTime)
+				}
+			}
+			return true
+		})
+}
+
+func backupCoverageEFS(session *Session, candidates map[string]*backupCandidate) error {
+	client := efs.New(session.Session, session.Config)
+
+	// EFS has no native snapshots, only AWS Backup
+	return client.DescribeFileSystemsPages(&efs.DescribeFileSystemsInput{},
+		func(page *efs.DescribeFileSystemsOutput, lastPage bool) bool {
+			for _, fileSystem := range page.FileSystems {
+				candidate := &backupCandidate{
+					ID:           *fileSystem.FileSystemId,
+					ARN:          aws.StringValue(fileSystem.FileSystemArn),
+					ResourceType: "EFS",
+				}
+				candidates[candidate.ARN] = candidate
+			}
+			return true
+		})
+}
+
+func backupCoverageDynamoDB(session *Session, candidates map[string]*backupCandidate) error {
+	client := dynamodb.New(session.Session, session.Config)
+
+	tables := map[string]*backupCandidate{}
+	var describeErr error
+	err := client.ListTablesPages(&dynamodb.ListTablesInput{},
+		func(page *dynamodb.ListTablesOutput, lastPage bool) bool {
+			for _, tableName := range page.TableNames {
+				candidate := &backupCandidate{
+					ID:           *tableName,
+					ARN:          fmt.Sprintf("arn:aws:dynamodb:%s:%s:table/%s", *session.Config.Region, session.AccountID, *tableName),
+					ResourceType: "DynamoDB",
+				}
+
+				// point in time recovery counts as a recent snapshot
+				continuousBackups, err := client.DescribeContinuousBackups(&dynamodb.DescribeContinuousBackupsInput{TableName: tableName})
+				if err != nil {
+					describeErr = err
+					return false
+				}
+				description := continuousBackups.ContinuousBackupsDescription
+				if description != nil && description.PointInTimeRecoveryDescription != nil {
+					candidate.LastSnapshotTime = description.PointInTimeRecoveryDescription.LatestRestorableDateTime
+				}
+
+				tables[candidate.ARN] = candidate
+				candidates[candidate.ARN] = candidate
+			}
+			return true
+		})
+	if err != nil {
+		return err
+	}
+	if describeErr != nil {
+		return describeErr
+	}
+
+	input := &dynamodb.ListBackupsInput{}
+	for {
+		page, err := client.ListBackups(input)
+		if err != nil {
+			return err
+		}
+
+		for _, summary := range page.BackupSummaries {
+			if candidate, ok := tables[aws.StringValue(summary.TableArn)]; ok {
+				candidate.LastSnapshotTime = newestTime(candidate.LastSnapshotTime, summary.BackupCreationDateTime)
+			}
+		}
+
+		if page.LastEvaluatedBackupArn == nil {
+			break
+		}
+		input.ExclusiveStartBackupArn = page.LastEvaluatedBackupArn
+	}
+
+	return nil
+}
+
+func backupCoverageProtectedResources(session *Session, candidates map[string]*backupCandidate) error {
+	client := backup.New(session.Session, session.Config)
+
+	return client.ListProtectedResourcesPages(&backup.ListProtectedResourcesInput{},
+		func(page *backup.ListProtectedResourcesOutput, lastPage bool) bool {
+			for _, protected := range page.Results {
+				if candidate, ok := candidates[aws.StringValue(protected.ResourceArn)]; ok {
+					candidate.LastBackupTime = newestTime(candidate.LastBackupTime, protected.LastBackupTime)
+				}
+			}
+			return true
+		})
+}
@@ -0,0 +1,37 @@
+package resources
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBackupCoverageMetadata(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2020, 6, 15, 12, 0, 0, 0, time.UTC)
+	recent := now.Add(-24 * time.Hour)
+	old := now.Add(-30 * 24 * time.Hour)
+
+	testCases := []struct {
+		backup   *time.Time
+		snapshot *time.Time
+		last     *time.Time
+		covered  bool
+	}{
+		{nil, nil, nil, false},
+		{&old, nil, &old, false},
+		{nil, &recent, &recent, true},
+		{&old, &recent, &recent, true},
+		{&recent, &old, &recent, true},
+	}
+	for _, testCase := range testCases {
+		metadata := backupCoverageMetadata(&backupCandidate{
+			LastBackupTime:   testCase.backup,
+			LastSnapshotTime: testCase.snapshot,
+		}, now, 7*24*time.Hour)
+		require.Equal(t, testCase.last, metadata["LastRecoveryPointTime"])
+		require.Equal(t, testCase.covered, metadata["Covered"])
+	}
+}
@@ -0,0 +1,91 @@
+package resources
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+var (
+	// APICalls counts the API calls of the sessions opened by OpenSessions
+	// and of the sessions it is attached to, e.g. the ones of the sinks
+	APICalls = NewCallCounter()
+)
+
+// CallCounter counts the API calls by service:Operation, and the units billed
+// for them: the metrics requested by GetMetricData, the items written by
+// BatchWriteItem, and one for the other calls. Retries are counted.
+type CallCounter struct {
+	mutex sync.Mutex
+	calls int64
+	units map[string]int64
+}
+
+func NewCallCounter() *CallCounter {
+	return &CallCounter{units: map[string]int64{}}
+}
+
+func (c *CallCounter) Attach(handlers *request.Handlers) {
+	handlers.Complete.PushBackNamed(request.NamedHandler{
+		Name: "awstools.CountCalls",
+		Fn: func(r *request.Request) {
+			operation := ""
+			if r.Operation != nil {
+				operation = r.Operation.Name
+			}
+			c.Add(fmt.Sprintf("%s:%s", serviceName(r), operation), int64(r.RetryCount+1), callUnits(r.Params))
+		},
+	})
+}
+
+// callUnits returns the billed units of a call.
+func callUnits(params interface{}) int64 {
+	switch input := params.(type) {
+	case *cloudwatch.GetMetricDataInput:
+		return int64(len(input.MetricDataQueries))
+	case *dynamodb.BatchWriteItemInput:
+		units := 0
+		for _, requests := range input.RequestItems {
+			units += len(requests)
+		}
+		return int64(units)
+	}
+	return 1
+}
+
+// Add counts attempts calls of the operation, each of the units.
+func (c *CallCounter) Add(operation string, attempts, units int64) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.calls += attempts
+	c.units[operation] += attempts * units
+}
+
+// Calls is the number of API calls.
+func (c *CallCounter) Calls() int64 {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.calls
+}
+
+// Units returns the units billed by service:Operation.
+func (c *CallCounter) Units() map[string]int64 {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	units := map[string]int64{}
+	for operation, count := range c.units {
+		units[operation] = count
+	}
+	return units
+}
+
+// Reset forgets the calls counted, for the lambda invocations not to add up.
+func (c *CallCounter) Reset() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.calls = 0
+	c.units = map[string]int64{}
+}
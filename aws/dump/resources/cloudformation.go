@@ -0,0 +1,226 @@
+package resources
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudformation"
+	"github.com/fatih/structs"
+)
+
+var (
+	CloudFormationService = Service{
+		Name: "cloudformation",
+		Reports: map[string]Report{
+			"stacks":          CloudFormationListStacks,
+			"stack-sets":      CloudFormationListStackSets,
+			"stack-instances": CloudFormationListStackInstances,
+			"exports":         CloudFormationListExports,
+		},
+		OptionalReports: map[string]Report{
+			"stack-resources": CloudFormationListStackResources,
+		},
+	}
+)
+
+// CloudFormationListStacks lists the stacks with their parameters, outputs,
+// capabilities and drift status.
+func CloudFormationListStacks(session *Session) *ReportResult {
+	client := cloudformation.New(session.Session, session.Config)
+
+	result := &ReportResult{}
+	err := client.DescribeStacksPages(&cloudformation.DescribeStacksInput{},
+		func(page *cloudformation.DescribeStacksOutput, lastPage bool) bool {
+			for _, stack := range page.Stacks {
+				resource, err := NewResource(*stack.StackId, stack)
+				if err != nil {
+					result.Error = err
+					return false
+				}
+				resource.ID = *stack.StackName
+				if stack.DriftInformation != nil {
+					resource.Metadata["DriftStatus"] = aws.StringValue(stack.DriftInformation.StackDriftStatus)
+				}
+				result.Resources = append(result.Resources, *resource)
+			}
+			return true
+		})
+
+	if result.Error != nil {
+		return result
+	}
+	result.Error = err
+	return result
+}
+
+func cloudFormationListStackSets(client *cloudformation.CloudFormation) ([]*cloudformation.StackSetSummary, error) {
+	stackSets := []*cloudformation.StackSetSummary{}
+	err := client.ListStackSetsPages(&cloudformation.ListStackSetsInput{Status: aws.String(cloudformation.StackSetStatusActive)},
+		func(page *cloudformation.ListStackSetsOutput, lastPage bool) bool {
+			stackSets = append(stackSets, page.Summaries...)
+			return true
+		})
+	return stackSets, err
+}
+
+func CloudFormationListStackSets(session *Session) *ReportResult {
+	client := cloudformation.New(session.Session, session.Config)
+
+	stackSets, err := cloudFormationListStackSets(client)
+	if err != nil {
+		return &ReportResult{nil, err}
+	}
+
+	result := &ReportResult{}
+	for _, summary := range stackSets {
+		res, err := client.DescribeStackSet(&cloudformation.DescribeStackSetInput{StackSetName: summary.StackSetName})
+		if err != nil {
+			result.Error = err
+			return result
+		}
+
+		// the template body is large and available from the stack set itself
+		res.StackSet.TemplateBody = nil
+
+		resource, err := NewResource(*res.StackSet.StackSetARN, res.StackSet)
+		if err != nil {
+			result.Error = err
+			return result
+		}
+		resource.ID = *summary.StackSetName
+		result.Resources = append(result.Resources, *resource)
+	}
+
+	return result
+}
+
+func CloudFormationListStackInstances(session *Session) *ReportResult {
+	client := cloudformation.New(session.Session, session.Config)
+
+	stackSets, err := cloudFormationListStackSets(client)
+	if err != nil {
+		return &ReportResult{nil, err}
+	}
+
+	result := &ReportResult{}
+	for _, stackSet := range stackSets {
+		err := client.ListStackInstancesPages(&cloudformation.ListStackInstancesInput{StackSetName: stackSet.StackSetName},
+			func(page *cloudformation.ListStackInstancesOutput, lastPage bool) bool {
+				for _, instance := range page.Summaries {
+					result.Resources = append(result.Resources, Resource{
+						ID:        fmt.Sprintf("%s_%s_%s", *instance.StackSetId, aws.StringValue(instance.Account), aws.StringValue(instance.Region)),
+						ARN:       aws.StringValue(instance.StackId),
+						AccountID: session.AccountID,
+						Service:   "cloudformation",
+						Type:      "stack-instance",
+						Region:    *session.Config.Region,
+						Metadata:  structs.Map(instance),
+					})
+				}
+				return true
+			})
+		if err != nil {
+			result.Error = err
+			return result
+		}
+	}
+
+	return result
+}
+
+func CloudFormationListExports(session *Session) *ReportResult {
+	client := cloudformation.New(session.Session, session.Config)
+
+	result := &ReportResult{}
+	result.Error = client.ListExportsPages(&cloudformation.ListExportsInput{},
+		func(page *cloudformation.ListExportsOutput, lastPage bool) bool {
+			for _, export := range page.Exports {
+				result.Resources = append(result.Resources, Resource{
+					ID:        *export.Name,
+					AccountID: session.AccountID,
+					Service:   "cloudformation",
+					Type:      "export",
+					Region:    *session.Config.Region,
+					Metadata:  structs.Map(export),
+				})
+			}
+			return true
+		})
+
+	return result
+}
+
+// CloudFormationListStackResources lists the physical resources of every stack
+// so they can be matched with the resources of the other reports.
+func CloudFormationListStackResources(session *Session) *ReportResult {
+	client := cloudformation.New(session.Session, session.Config)
+
+	stacks := []*cloudformation.StackSummary{}
+	err := client.ListStacksPages(&cloudformation.ListStacksInput{},
+		func(page *cloudformation.ListStacksOutput, lastPage bool) bool {
+			for _, stack := range page.StackSummaries {
+				if aws.StringValue(stack.StackStatus) != cloudformation.StackStatusDeleteComplete {
+					stacks = append(stacks, stack)
+				}
+			}
+			return true
+		})
+	if err != nil {
+		return &ReportResult{nil, err}
+	}
+
+	result := &ReportResult{}
+	for _, stack := range stacks {
+		if previous := previousStackResources(stack); len(previous) > 0 {
+			result.Resources = append(result.Resources, Previous.reuse(previous...)...)
+			continue
+		}
+
+		err := client.ListStackResourcesPages(&cloudformation.ListStackResourcesInput{StackName: stack.StackId},
+			func(page *cloudformation.ListStackResourcesOutput, lastPage bool) bool {
+				for _, stackResource := range page.StackResourceSummaries {
+					resource := Resource{
+						ID:        fmt.Sprintf("%s_%s", *stack.StackName, *stackResource.LogicalResourceId),
+						AccountID: session.AccountID,
+						Service:   "cloudformation",
+						Type:      "stack-resource",
+						Region:    *session.Config.Region,
+						Metadata:  structs.Map(stackResource),
+					}
+					resource.Metadata["StackName"] = *stack.StackName
+					resource.Metadata["StackId"] = *stack.StackId
+					result.Resources = append(result.Resources, resource)
+				}
+				return true
+			})
+		if err != nil {
+			result.Error = err
+			return result
+		}
+	}
+
+	return result
+}
+
+// previousStackResources returns the resources of the stack in the previous
+// dump if the stack wasn't updated or checked for drift since.
+func previousStackResources(stack *cloudformation.StackSummary) []Resource {
+	previous, ok := Previous.Get(aws.StringValue(stack.StackId))
+	if !ok {
+		return nil
+	}
+
+	var lastCheck *time.Time
+	if stack.DriftInformation != nil {
+		lastCheck = stack.DriftInformation.LastCheckTimestamp
+	}
+	unchanged := sameValue(previous.Metadata["StackStatus"], stack.StackStatus) &&
+		sameValue(previous.Metadata["CreationTime"], stack.CreationTime) &&
+		sameValue(previous.Metadata["LastUpdatedTime"], stack.LastUpdatedTime) &&
+		sameValue(nestedValue(previous.Metadata, "DriftInformation", "LastCheckTimestamp"), lastCheck)
+	if !unchanged {
+		return nil
+	}
+	return Previous.stackResources[aws.StringValue(stack.StackId)]
+}
@@ -0,0 +1,40 @@
+package resources
+
+import (
+	"github.com/aws/aws-sdk-go/service/cloudfront"
+)
+
+var (
+	CloudFrontService = Service{
+		Name:     "cloudfront",
+		IsGlobal: true,
+		Reports: map[string]Report{
+			"distributions": CloudFrontListDistributions,
+		},
+	}
+)
+
+func CloudFrontListDistributions(session *Session) *ReportResult {
+	client := cloudfront.New(session.Session, session.Config)
+
+	result := &ReportResult{}
+	err := client.ListDistributionsPages(&cloudfront.ListDistributionsInput{},
+		func(page *cloudfront.ListDistributionsOutput, lastPage bool) bool {
+			for _, distribution := range page.DistributionList.Items {
+				resource, err := NewResource(*distribution.ARN, distribution)
+				if err != nil {
+					result.Error = err
+					return false
+				}
+				result.Resources = append(result.Resources, *resource)
+			}
+
+			return true
+		})
+
+	if result.Error != nil {
+		return result
+	}
+	result.Error = err
+	return result
+}
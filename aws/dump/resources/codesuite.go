@@ -0,0 +1,213 @@
+package resources
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/codebuild"
+	"github.com/aws/aws-sdk-go/service/codedeploy"
+	"github.com/aws/aws-sdk-go/service/codepipeline"
+	"github.com/fatih/structs"
+)
+
+const (
+	// maximum number of names accepted by the BatchGet calls
+	codeSuiteBatchSize = 100
+)
+
+var (
+	CodeSuiteService = Service{
+		Name: "codesuite",
+		Reports: map[string]Report{
+			"pipelines":                    CodeSuiteListPipelines,
+			"codebuild-projects":           CodeSuiteListCodeBuildProjects,
+			"codedeploy-applications":      CodeSuiteListCodeDeployApplications,
+			"codedeploy-deployment-groups": CodeSuiteListCodeDeployDeploymentGroups,
+		},
+	}
+)
+
+// CodeSuiteListPipelines lists the pipelines with their stages and actions.
+func CodeSuiteListPipelines(session *Session) *ReportResult {
+	client := codepipeline.New(session.Session, session.Config)
+
+	result := &ReportResult{}
+	err := client.ListPipelinesPages(&codepipeline.ListPipelinesInput{},
+		func(page *codepipeline.ListPipelinesOutput, lastPage bool) bool {
+			for _, summary := range page.Pipelines {
+				pipeline, err := client.GetPipeline(&codepipeline.GetPipelineInput{Name: summary.Name})
+				if err != nil {
+					result.Error = err
+					return false
+				}
+
+				resource, err := NewResource(*pipeline.Metadata.PipelineArn, pipeline.Pipeline)
+				if err != nil {
+					result.Error = err
+					return false
+				}
+				resource.ID = *summary.Name
+				resource.Metadata["Created"] = pipeline.Metadata.Created
+				resource.Metadata["Updated"] = pipeline.Metadata.Updated
+				result.Resources = append(result.Resources, *resource)
+			}
+			return true
+		})
+
+	if result.Error != nil {
+		return result
+	}
+	result.Error = err
+	return result
+}
+
+func codeSuiteBatches(names []*string) [][]*string {
+	batches := [][]*string{}
+	for start := 0; start < len(names); start += codeSuiteBatchSize {
+		end := start + codeSuiteBatchSize
+		if end > len(names) {
+			end = len(names)
+		}
+		batches = append(batches, names[start:end])
+	}
+	return batches
+}
+
+// CodeSuiteListCodeBuildProjects lists the projects with their environment,
+// source and artifacts. Plain text environment variables are redacted.
+func CodeSuiteListCodeBuildProjects(session *Session) *ReportResult {
+	client := codebuild.New(session.Session, session.Config)
+
+	names := []*string{}
+	err := client.ListProjectsPages(&codebuild.ListProjectsInput{},
+		func(page *codebuild.ListProjectsOutput, lastPage bool) bool {
+			names = append(names, page.Projects...)
+			return true
+		})
+	if err != nil {
+		return &ReportResult{nil, err}
+	}
+
+	result := &ReportResult{}
+	for _, batch := range codeSuiteBatches(names) {
+		res, err := client.BatchGetProjects(&codebuild.BatchGetProjectsInput{Names: batch})
+		if err != nil {
+			result.Error = err
+			return result
+		}
+
+		for _, project := range res.Projects {
+			if project.Environment != nil {
+				for _, variable := range project.Environment.EnvironmentVariables {
+					if aws.StringValue(variable.Type) == codebuild.EnvironmentVariableTypePlaintext {
+						variable.Value = aws.String("REDACTED")
+					}
+				}
+			}
+
+			resource, err := NewResource(*project.Arn, project)
+			if err != nil {
+				result.Error = err
+				return result
+			}
+			result.Resources = append(result.Resources, *resource)
+		}
+	}
+
+	return result
+}
+
+func codeDeployListApplications(client *codedeploy.CodeDeploy) ([]*string, error) {
+	names := []*string{}
+	err := client.ListApplicationsPages(&codedeploy.ListApplicationsInput{},
+		func(page *codedeploy.ListApplicationsOutput, lastPage bool) bool {
+			names = append(names, page.Applications...)
+			return true
+		})
+	return names, err
+}
+
+func CodeSuiteListCodeDeployApplications(session *Session) *ReportResult {
+	client := codedeploy.New(session.Session, session.Config)
+
+	names, err := codeDeployListApplications(client)
+	if err != nil {
+		return &ReportResult{nil, err}
+	}
+
+	result := &ReportResult{}
+	for _, batch := range codeSuiteBatches(names) {
+		res, err := client.BatchGetApplications(&codedeploy.BatchGetApplicationsInput{ApplicationNames: batch})
+		if err != nil {
+			result.Error = err
+			return result
+		}
+
+		for _, application := range res.ApplicationsInfo {
+			result.Resources = append(result.Resources, Resource{
+				ID:        *application.ApplicationName,
+				ARN:       fmt.Sprintf("arn:aws:codedeploy:%s:%s:application:%s", *session.Config.Region, session.AccountID, *application.ApplicationName),
+				AccountID: session.AccountID,
+				Service:   "codedeploy",
+				Type:      "application",
+				Region:    *session.Config.Region,
+				Metadata:  structs.Map(application),
+			})
+		}
+	}
+
+	return result
+}
+
+func CodeSuiteListCodeDeployDeploymentGroups(session *Session) *ReportResult {
+	client := codedeploy.New(session.Session, session.Config)
+
+	applications, err := codeDeployListApplications(client)
+	if err != nil {
+		return &ReportResult{nil, err}
+	}
+
+	result := &ReportResult{}
+	for _, application := range applications {
+		names := []*string{}
+		err := client.ListDeploymentGroupsPages(&codedeploy.ListDeploymentGroupsInput{ApplicationName: application},
+			func(page *codedeploy.ListDeploymentGroupsOutput, lastPage bool) bool {
+				names = append(names, page.DeploymentGroups...)
+				return true
+			})
+		if err != nil {
+			result.Error = err
+			return result
+		}
+
+		for _, batch := range codeSuiteBatches(names) {
+			res, err := client.BatchGetDeploymentGroups(&codedeploy.BatchGetDeploymentGroupsInput{
+				ApplicationName:      application,
+				DeploymentGroupNames: batch,
+			})
+			if err != nil {
+				result.Error = err
+				return result
+			}
+
+			for _, deploymentGroup := range res.DeploymentGroupsInfo {
+				result.Resources = append(result.Resources, Resource{
+					ID: *deploymentGroup.DeploymentGroupId,
+					ARN: fmt.Sprintf("arn:aws:codedeploy:%s:%s:deploymentgroup:%s/%s",
+						*session.Config.Region,
+						session.AccountID,
+						*application,
+						*deploymentGroup.DeploymentGroupName,
+					),
+					AccountID: session.AccountID,
+					Service:   "codedeploy",
+					Type:      "deployment-group",
+					Region:    *session.Config.Region,
+					Metadata:  structs.Map(deploymentGroup),
+				})
+			}
+		}
+	}
+
+	return result
+}
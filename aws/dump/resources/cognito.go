@@ -0,0 +1,260 @@
+package resources
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cognitoidentity"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider"
+	"github.com/fatih/structs"
+)
+
+var (
+	CognitoService = Service{
+		Name: "cognito",
+		Reports: map[string]Report{
+			"user-pools":                   CognitoListUserPools,
+			"user-pool-clients":            CognitoListUserPoolClients,
+			"user-pool-identity-providers": CognitoListUserPoolIdentityProviders,
+			"identity-pools":               CognitoListIdentityPools,
+		},
+	}
+)
+
+func cognitoListUserPoolIDs(client *cognitoidentityprovider.CognitoIdentityProvider) ([]*string, error) {
+	ids := []*string{}
+	err := client.ListUserPoolsPages(&cognitoidentityprovider.ListUserPoolsInput{MaxResults: aws.Int64(60)},
+		func(page *cognitoidentityprovider.ListUserPoolsOutput, lastPage bool) bool {
+			for _, userPool := range page.UserPools {
+				ids = append(ids, userPool.Id)
+			}
+			return true
+		})
+	return ids, err
+}
+
+func CognitoListUserPools(session *Session) *ReportResult {
+	client := cognitoidentityprovider.New(session.Session, session.Config)
+
+	ids, err := cognitoListUserPoolIDs(client)
+	if err != nil {
+		return &ReportResult{nil, err}
+	}
+
+	result := &ReportResult{}
+	for _, id := range ids {
+		userPool, err := client.DescribeUserPool(&cognitoidentityprovider.DescribeUserPoolInput{UserPoolId: id})
+		if err != nil {
+			result.Error = err
+			return result
+		}
+
+		resource := Resource{
+			ID:        *id,
+			ARN:       aws.StringValue(userPool.UserPool.Arn),
+			AccountID: session.AccountID,
+			Service:   "cognito-idp",
+			Type:      "userpool",
+			Region:    *session.Config.Region,
+			Metadata:  structs.Map(userPool.UserPool),
+		}
+
+		mfaConfig, err := client.GetUserPoolMfaConfig(&cognitoidentityprovider.GetUserPoolMfaConfigInput{UserPoolId: id})
+		if err != nil {
+			result.Error = err
+			return result
+		}
+		resource.Metadata["MfaConfig"] = structs.Map(mfaConfig)
+
+		domains := []interface{}{}
+		for _, domain := range []*string{userPool.UserPool.Domain, userPool.UserPool.CustomDomain} {
+			if aws.StringValue(domain) == "" {
+				continue
+			}
+			description, err := client.DescribeUserPoolDomain(&cognitoidentityprovider.DescribeUserPoolDomainInput{Domain: domain})
+			if err != nil {
+				result.Error = err
+				return result
+			}
+			domains = append(domains, structs.Map(description.DomainDescription))
+		}
+		resource.Metadata["Domains"] = domains
+
+		result.Resources = append(result.Resources, resource)
+	}
+
+	return result
+}
+
+func CognitoListUserPoolClients(session *Session) *ReportResult {
+	client := cognitoidentityprovider.New(session.Session, session.Config)
+
+	ids, err := cognitoListUserPoolIDs(client)
+	if err != nil {
+		return &ReportResult{nil, err}
+	}
+
+	result := &ReportResult{}
+	for _, id := range ids {
+		err := client.ListUserPoolClientsPages(&cognitoidentityprovider.ListUserPoolClientsInput{
+			UserPoolId: id,
+			MaxResults: aws.Int64(60),
+		},
+			func(page *cognitoidentityprovider.ListUserPoolClientsOutput, lastPage bool) bool {
+				for _, userPoolClient := range page.UserPoolClients {
+					described, err := client.DescribeUserPoolClient(&cognitoidentityprovider.DescribeUserPoolClientInput{
+						UserPoolId: id,
+						ClientId:   userPoolClient.ClientId,
+					})
+					if err != nil {
+						result.Error = err
+						return false
+					}
+
+					// never dump the client secret
+					described.UserPoolClient.ClientSecret = nil
+
+					result.Resources = append(result.Resources, Resource{
+						ID:        fmt.Sprintf("%s_%s", *id, *userPoolClient.ClientId),
+						AccountID: session.AccountID,
+						Service:   "cognito-idp",
+						Type:      "userpool-client",
+						Region:    *session.Config.Region,
+						Metadata:  structs.Map(described.UserPoolClient),
+					})
+				}
+				return true
+			})
+		if result.Error != nil {
+			return result
+		}
+		if err != nil {
+			result.Error = err
+			return result
+		}
+	}
+
+	return result
+}
+
+func CognitoListUserPoolIdentityProviders(session *Session) *ReportResult {
+	client := cognitoidentityprovider.New(session.Session, session.Config)
+
+	ids, err := cognitoListUserPoolIDs(client)
+	if err != nil {
+		return &ReportResult{nil, err}
+	}
+
+	result := &ReportResult{}
+	for _, id := range ids {
+		err := client.ListIdentityProvidersPages(&cognitoidentityprovider.ListIdentityProvidersInput{
+			UserPoolId: id,
+			MaxResults: aws.Int64(60),
+		},
+			func(page *cognitoidentityprovider.ListIdentityProvidersOutput, lastPage bool) bool {
+				for _, provider := range page.Providers {
+					described, err := client.DescribeIdentityProvider(&cognitoidentityprovider.DescribeIdentityProviderInput{
+						UserPoolId:   id,
+						ProviderName: provider.ProviderName,
+					})
+					if err != nil {
+						result.Error = err
+						return false
+					}
+
+					// provider details can contain client secrets
+					details := map[string]string{}
+					for key, value := range described.IdentityProvider.ProviderDetails {
+						if strings.Contains(strings.ToLower(key), "secret") {
+							continue
+						}
+						details[key] = aws.StringValue(value)
+					}
+					described.IdentityProvider.ProviderDetails = nil
+
+					resource := Resource{
+						ID:        fmt.Sprintf("%s_%s", *id, *provider.ProviderName),
+						AccountID: session.AccountID,
+						Service:   "cognito-idp",
+						Type:      "userpool-identity-provider",
+						Region:    *session.Config.Region,
+						Metadata:  structs.Map(described.IdentityProvider),
+					}
+					resource.Metadata["ProviderDetails"] = details
+					result.Resources = append(result.Resources, resource)
+				}
+				return true
+			})
+		if result.Error != nil {
+			return result
+		}
+		if err != nil {
+			result.Error = err
+			return result
+		}
+	}
+
+	return result
+}
+
+func CognitoListIdentityPools(session *Session) *ReportResult {
+	client := cognitoidentity.New(session.Session, session.Config)
+
+	result := &ReportResult{}
+	input := &cognitoidentity.ListIdentityPoolsInput{MaxResults: aws.Int64(60)}
+	for {
+		page, err := client.ListIdentityPools(input)
+		if err != nil {
+			result.Error = err
+			return result
+		}
+
+		for _, identityPool := range page.IdentityPools {
+			described, err := client.DescribeIdentityPool(&cognitoidentity.DescribeIdentityPoolInput{
+				IdentityPoolId: identityPool.IdentityPoolId,
+			})
+			if err != nil {
+				result.Error = err
+				return result
+			}
+
+			resource := Resource{
+				ID: *identityPool.IdentityPoolId,
+				ARN: fmt.Sprintf("arn:aws:cognito-identity:%s:%s:identitypool/%s",
+					*session.Config.Region,
+					session.AccountID,
+					*identityPool.IdentityPoolId,
+				),
+				AccountID: session.AccountID,
+				Service:   "cognito-identity",
+				Type:      "identitypool",
+				Region:    *session.Config.Region,
+				Metadata:  structs.Map(described),
+			}
+
+			roles, err := client.GetIdentityPoolRoles(&cognitoidentity.GetIdentityPoolRolesInput{
+				IdentityPoolId: identityPool.IdentityPoolId,
+			})
+			if err != nil {
+				result.Error = err
+				return result
+			}
+			resource.Metadata["Roles"] = aws.StringValueMap(roles.Roles)
+			roleMappings := map[string]interface{}{}
+			for provider, roleMapping := range roles.RoleMappings {
+				roleMappings[provider] = structs.Map(roleMapping)
+			}
+			resource.Metadata["RoleMappings"] = roleMappings
+
+			result.Resources = append(result.Resources, resource)
+		}
+
+		if page.NextToken == nil {
+			break
+		}
+		input.NextToken = page.NextToken
+	}
+
+	return result
+}
@@ -0,0 +1,198 @@
+package resources
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/directconnect"
+	"github.com/fatih/structs"
+)
+
+var (
+	DirectConnectService = Service{
+		Name: "directconnect",
+		Reports: map[string]Report{
+			"connections":        DirectConnectListConnections,
+			"virtual-interfaces": DirectConnectListVirtualInterfaces,
+			"lags":               DirectConnectListLags,
+		},
+	}
+
+	// Direct Connect gateways are global, they are listed once from us-east-1
+	DirectConnectGatewayService = Service{
+		Name:     "directconnect-gateway",
+		IsGlobal: true,
+		Reports: map[string]Report{
+			"gateways":     DirectConnectListGateways,
+			"associations": DirectConnectListGatewayAssociations,
+		},
+	}
+)
+
+func newDirectConnectGatewayClient(session *Session) *directconnect.DirectConnect {
+	return directconnect.New(session.Session, session.Config.Copy(&aws.Config{Region: aws.String("us-east-1")}))
+}
+
+func DirectConnectListConnections(session *Session) *ReportResult {
+	client := directconnect.New(session.Session, session.Config)
+
+	res, err := client.DescribeConnections(&directconnect.DescribeConnectionsInput{})
+	if err != nil {
+		return &ReportResult{nil, err}
+	}
+
+	result := &ReportResult{}
+	for _, connection := range res.Connections {
+		result.Resources = append(result.Resources, Resource{
+			ID:        *connection.ConnectionId,
+			ARN:       fmt.Sprintf("arn:aws:directconnect:%s:%s:dxcon/%s", *connection.Region, *connection.OwnerAccount, *connection.ConnectionId),
+			AccountID: *connection.OwnerAccount,
+			Service:   "directconnect",
+			Type:      "connection",
+			Region:    *session.Config.Region,
+			Metadata:  structs.Map(connection),
+		})
+	}
+
+	return result
+}
+
+func DirectConnectListVirtualInterfaces(session *Session) *ReportResult {
+	client := directconnect.New(session.Session, session.Config)
+
+	res, err := client.DescribeVirtualInterfaces(&directconnect.DescribeVirtualInterfacesInput{})
+	if err != nil {
+		return &ReportResult{nil, err}
+	}
+
+	result := &ReportResult{}
+	for _, virtualInterface := range res.VirtualInterfaces {
+		// BGP authentication key
+		virtualInterface.AuthKey = nil
+		for _, peer := range virtualInterface.BgpPeers {
+			peer.AuthKey = nil
+		}
+
+		result.Resources = append(result.Resources, Resource{
+			ID:        *virtualInterface.VirtualInterfaceId,
+			ARN:       fmt.Sprintf("arn:aws:directconnect:%s:%s:dxvif/%s", *virtualInterface.Region, *virtualInterface.OwnerAccount, *virtualInterface.VirtualInterfaceId),
+			AccountID: *virtualInterface.OwnerAccount,
+			Service:   "directconnect",
+			Type:      "virtual-interface",
+			Region:    *session.Config.Region,
+			Metadata:  structs.Map(virtualInterface),
+		})
+	}
+
+	return result
+}
+
+func DirectConnectListLags(session *Session) *ReportResult {
+	client := directconnect.New(session.Session, session.Config)
+
+	res, err := client.DescribeLags(&directconnect.DescribeLagsInput{})
+	if err != nil {
+		return &ReportResult{nil, err}
+	}
+
+	result := &ReportResult{}
+	for _, lag := range res.Lags {
+		result.Resources = append(result.Resources, Resource{
+			ID:        *lag.LagId,
+			ARN:       fmt.Sprintf("arn:aws:directconnect:%s:%s:dxlag/%s", *lag.Region, *lag.OwnerAccount, *lag.LagId),
+			AccountID: *lag.OwnerAccount,
+			Service:   "directconnect",
+			Type:      "lag",
+			Region:    *session.Config.Region,
+			Metadata:  structs.Map(lag),
+		})
+	}
+
+	return result
+}
+
+func directConnectListGateways(client *directconnect.DirectConnect) ([]*directconnect.Gateway, error) {
+	gateways := []*directconnect.Gateway{}
+
+	input := &directconnect.DescribeDirectConnectGatewaysInput{}
+	for {
+		res, err := client.DescribeDirectConnectGateways(input)
+		if err != nil {
+			return nil, err
+		}
+
+		gateways = append(gateways, res.DirectConnectGateways...)
+
+		if res.NextToken == nil {
+			break
+		}
+		input.NextToken = res.NextToken
+	}
+
+	return gateways, nil
+}
+
+func DirectConnectListGateways(session *Session) *ReportResult {
+	client := newDirectConnectGatewayClient(session)
+
+	gateways, err := directConnectListGateways(client)
+	if err != nil {
+		return &ReportResult{nil, err}
+	}
+
+	result := &ReportResult{}
+	for _, gateway := range gateways {
+		result.Resources = append(result.Resources, Resource{
+			ID:        *gateway.DirectConnectGatewayId,
+			ARN:       fmt.Sprintf("arn:aws:directconnect::%s:dx-gateway/%s", *gateway.OwnerAccount, *gateway.DirectConnectGatewayId),
+			AccountID: *gateway.OwnerAccount,
+			Service:   "directconnect",
+			Type:      "gateway",
+			Metadata:  structs.Map(gateway),
+		})
+	}
+
+	return result
+}
+
+// DirectConnectListGatewayAssociations lists the virtual private gateways and
+// transit gateways associated with the Direct Connect gateways.
+func DirectConnectListGatewayAssociations(session *Session) *ReportResult {
+	client := newDirectConnectGatewayClient(session)
+
+	gateways, err := directConnectListGateways(client)
+	if err != nil {
+		return &ReportResult{nil, err}
+	}
+
+	result := &ReportResult{}
+	for _, gateway := range gateways {
+		input := &directconnect.DescribeDirectConnectGatewayAssociationsInput{
+			DirectConnectGatewayId: gateway.DirectConnectGatewayId,
+		}
+		for {
+			res, err := client.DescribeDirectConnectGatewayAssociations(input)
+			if err != nil {
+				result.Error = err
+				return result
+			}
+
+			for _, association := range res.DirectConnectGatewayAssociations {
+				result.Resources = append(result.Resources, Resource{
+					ID:        *association.AssociationId,
+					AccountID: *gateway.OwnerAccount,
+					Service:   "directconnect",
+					Type:      "gateway-association",
+					Metadata:  structs.Map(association),
+				})
+			}
+
+			if res.NextToken == nil {
+				break
+			}
+			input.NextToken = res.NextToken
+		}
+	}
+
+	return result
+}
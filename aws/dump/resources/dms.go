@@ -0,0 +1,166 @@
+package resources
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/databasemigrationservice"
+	"github.com/fatih/structs"
+)
+
+var (
+	DMSService = Service{
+		Name: "dms",
+		Reports: map[string]Report{
+			"replication-instances":     DMSListReplicationInstances,
+			"replication-tasks":         DMSListReplicationTasks,
+			"endpoints":                 DMSListEndpoints,
+			"replication-subnet-groups": DMSListReplicationSubnetGroups,
+		},
+	}
+)
+
+func DMSListReplicationInstances(session *Session) *ReportResult {
+	client := databasemigrationservice.New(session.Session, session.Config)
+
+	resources := []Resource{}
+	err := client.DescribeReplicationInstancesPages(&databasemigrationservice.DescribeReplicationInstancesInput{},
+		func(page *databasemigrationservice.DescribeReplicationInstancesOutput, lastPage bool) bool {
+			for _, replicationInstance := range page.ReplicationInstances {
+				resources = append(resources, Resource{
+					ID:        *replicationInstance.ReplicationInstanceIdentifier,
+					ARN:       *replicationInstance.ReplicationInstanceArn,
+					AccountID: session.AccountID,
+					Service:   "dms",
+					Type:      "replication-instance",
+					Region:    *session.Config.Region,
+					Metadata:  structs.Map(replicationInstance),
+				})
+			}
+			return true
+		})
+
+	return &ReportResult{resources, err}
+}
+
+// DMSListReplicationTasks lists the replication tasks with their table
+// mappings and settings decoded.
+func DMSListReplicationTasks(session *Session) *ReportResult {
+	client := databasemigrationservice.New(session.Session, session.Config)
+
+	result := &ReportResult{}
+	err := client.DescribeReplicationTasksPages(&databasemigrationservice.DescribeReplicationTasksInput{},
+		func(page *databasemigrationservice.DescribeReplicationTasksOutput, lastPage bool) bool {
+			for _, replicationTask := range page.ReplicationTasks {
+				resource := Resource{
+					ID:        *replicationTask.ReplicationTaskIdentifier,
+					ARN:       *replicationTask.ReplicationTaskArn,
+					AccountID: session.AccountID,
+					Service:   "dms",
+					Type:      "replication-task",
+					Region:    *session.Config.Region,
+					Metadata:  structs.Map(replicationTask),
+				}
+
+				for key, document := range map[string]*string{
+					"TableMappings":           replicationTask.TableMappings,
+					"ReplicationTaskSettings": replicationTask.ReplicationTaskSettings,
+				} {
+					if aws.StringValue(document) == "" {
+						continue
+					}
+					decoded := map[string]interface{}{}
+					err := json.Unmarshal([]byte(*document), &decoded)
+					if err != nil {
+						result.Error = err
+						return false
+					}
+					resource.Metadata[key] = decoded
+				}
+
+				result.Resources = append(result.Resources, resource)
+			}
+			return true
+		})
+
+	if err != nil {
+		result.Error = err
+	}
+	return result
+}
+
+// DMSListEndpoints lists the source and target endpoints, passwords in the
+// engine settings are redacted.
+func DMSListEndpoints(session *Session) *ReportResult {
+	client := databasemigrationservice.New(session.Session, session.Config)
+
+	resources := []Resource{}
+	err := client.DescribeEndpointsPages(&databasemigrationservice.DescribeEndpointsInput{},
+		func(page *databasemigrationservice.DescribeEndpointsOutput, lastPage bool) bool {
+			for _, endpoint := range page.Endpoints {
+				resource := Resource{
+					ID:        *endpoint.EndpointIdentifier,
+					ARN:       *endpoint.EndpointArn,
+					AccountID: session.AccountID,
+					Service:   "dms",
+					Type:      "endpoint",
+					Region:    *session.Config.Region,
+					Metadata:  structs.Map(endpoint),
+				}
+				redactPasswords(resource.Metadata)
+				resources = append(resources, resource)
+			}
+			return true
+		})
+
+	return &ReportResult{resources, err}
+}
+
+func DMSListReplicationSubnetGroups(session *Session) *ReportResult {
+	client := databasemigrationservice.New(session.Session, session.Config)
+
+	resources := []Resource{}
+	err := client.DescribeReplicationSubnetGroupsPages(&databasemigrationservice.DescribeReplicationSubnetGroupsInput{},
+		func(page *databasemigrationservice.DescribeReplicationSubnetGroupsOutput, lastPage bool) bool {
+			for _, subnetGroup := range page.ReplicationSubnetGroups {
+				resources = append(resources, Resource{
+					ID:        *subnetGroup.ReplicationSubnetGroupIdentifier,
+					AccountID: session.AccountID,
+					Service:   "dms",
+					Type:      "replication-subnet-group",
+					Region:    *session.Config.Region,
+					Metadata:  structs.Map(subnetGroup),
+				})
+			}
+			return true
+		})
+
+	return &ReportResult{resources, err}
+}
+
+// redactPasswords replaces in place the values of the keys containing
+// "Password" (e.g. PostgreSQLSettings.Password, KafkaSettings.SaslPassword).
+func redactPasswords(metadata map[string]interface{}) {
+	for key, value := range metadata {
+		switch value := value.(type) {
+		case map[string]interface{}:
+			redactPasswords(value)
+			continue
+		case []interface{}:
+			for _, item := range value {
+				if itemMap, ok := item.(map[string]interface{}); ok {
+					redactPasswords(itemMap)
+				}
+			}
+			continue
+		}
+
+		if strings.Contains(key, "Password") && value != nil {
+			if pointer, ok := value.(*string); ok && pointer == nil {
+				continue
+			}
+			metadata[key] = "REDACTED"
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package resources
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedactPasswords(t *testing.T) {
+	var missing *string
+	password := "secret"
+	metadata := map[string]interface{}{
+		"EndpointIdentifier": "source",
+		"Username":           "admin",
+		"PostgreSQLSettings": map[string]interface{}{
+			"Password":     &password,
+			"DatabaseName": "db",
+		},
+		"KafkaSettings": map[string]interface{}{
+			"SaslPassword": "secret",
+		},
+		"MongoDbSettings": map[string]interface{}{
+			"Password": missing,
+		},
+		"Items": []interface{}{
+			map[string]interface{}{"AuthPassword": "secret"},
+		},
+	}
+
+	redactPasswords(metadata)
+
+	require.Equal(t, map[string]interface{}{
+		"EndpointIdentifier": "source",
+		"Username":           "admin",
+		"PostgreSQLSettings": map[string]interface{}{
+			"Password":     "REDACTED",
+			"DatabaseName": "db",
+		},
+		"KafkaSettings": map[string]interface{}{
+			"SaslPassword": "REDACTED",
+		},
+		"MongoDbSettings": map[string]interface{}{
+			"Password": missing,
+		},
+		"Items": []interface{}{
+			map[string]interface{}{"AuthPassword": "REDACTED"},
+		},
+	}, metadata)
+}
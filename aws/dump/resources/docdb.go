@@ -0,0 +1,171 @@
+package resources
+
+import (
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/docdb"
+	"github.com/fatih/structs"
+)
+
+var (
+	DocDBService = Service{
+		Name: "docdb",
+		Reports: map[string]Report{
+			"db-clusters":                 DocDBListDBClusters,
+			"db-instances":                DocDBListDBInstances,
+			"db-cluster-snapshots":        DocDBListDBClusterSnapshots,
+			"db-cluster-parameter-groups": DocDBListDBClusterParameterGroups,
+		},
+	}
+)
+
+// The docdb API returns the RDS resources of every engine, only keep the docdb ones.
+func docdbEngineFilter() []*docdb.Filter {
+	return []*docdb.Filter{
+		{
+			Name:   aws.String("engine"),
+			Values: aws.StringSlice([]string{"docdb"}),
+		},
+	}
+}
+
+func DocDBListDBClusters(session *Session) *ReportResult {
+	client := docdb.New(session.Session, session.Config)
+
+	result := &ReportResult{}
+	input := &docdb.DescribeDBClustersInput{Filters: docdbEngineFilter()}
+	for {
+		page, err := client.DescribeDBClusters(input)
+		if err != nil {
+			result.Error = err
+			return result
+		}
+
+		for _, resource := range page.DBClusters {
+			result.Resources = append(result.Resources, Resource{
+				ID:        *resource.DBClusterIdentifier,
+				ARN:       *resource.DBClusterArn,
+				AccountID: session.AccountID,
+				Service:   "docdb",
+				Type:      "db-cluster",
+				Region:    *session.Config.Region,
+				Metadata:  structs.Map(resource),
+			})
+		}
+
+		if page.Marker == nil {
+			break
+		}
+		input.Marker = page.Marker
+	}
+
+	return result
+}
+
+func DocDBListDBInstances(session *Session) *ReportResult {
+	client := docdb.New(session.Session, session.Config)
+
+	result := &ReportResult{}
+	input := &docdb.DescribeDBInstancesInput{Filters: docdbEngineFilter()}
+	for {
+		page, err := client.DescribeDBInstances(input)
+		if err != nil {
+			result.Error = err
+			return result
+		}
+
+		for _, resource := range page.DBInstances {
+			result.Resources = append(result.Resources, Resource{
+				ID:        *resource.DBInstanceIdentifier,
+				ARN:       *resource.DBInstanceArn,
+				AccountID: session.AccountID,
+				Service:   "docdb",
+				Type:      "db-instance",
+				Region:    *session.Config.Region,
+				Metadata:  structs.Map(resource),
+			})
+		}
+
+		if page.Marker == nil {
+			break
+		}
+		input.Marker = page.Marker
+	}
+
+	return result
+}
+
+func DocDBListDBClusterSnapshots(session *Session) *ReportResult {
+	client := docdb.New(session.Session, session.Config)
+
+	result := &ReportResult{}
+	input := &docdb.DescribeDBClusterSnapshotsInput{}
+	for {
+		page, err := client.DescribeDBClusterSnapshots(input)
+		if err != nil {
+			result.Error = err
+			return result
+		}
+
+		for _, resource := range page.DBClusterSnapshots {
+			// filters are not supported by this API
+			if aws.StringValue(resource.Engine) != "docdb" {
+				continue
+			}
+			result.Resources = append(result.Resources, Resource{
+				ID:        *resource.DBClusterSnapshotIdentifier,
+				ARN:       *resource.DBClusterSnapshotArn,
+				AccountID: session.AccountID,
+				Service:   "docdb",
+				Type:      "db-cluster-snapshot",
+				Region:    *session.Config.Region,
+				Metadata:  structs.Map(resource),
+			})
+		}
+
+		if page.Marker == nil {
+			break
+		}
+		input.Marker = page.Marker
+	}
+
+	return result
+}
+
+func DocDBListDBClusterParameterGroups(session *Session) *ReportResult {
+	client := docdb.New(session.Session, session.Config)
+
+	result := &ReportResult{}
+	input := &docdb.DescribeDBClusterParameterGroupsInput{}
+	for {
+		page, err := client.DescribeDBClusterParameterGroups(input)
+		if err != nil {
+			result.Error = err
+			return result
+		}
+
+		for _, resource := range page.DBClusterParameterGroups {
+			// filters are not supported by this API
+			if !strings.HasPrefix(aws.StringValue(resource.DBParameterGroupFamily), "docdb") {
+				continue
+			}
+			result.Resources = append(result.Resources, Resource{
+				ID:        *resource.DBClusterParameterGroupArn,
+				ARN:       *resource.DBClusterParameterGroupArn,
+				AccountID: session.AccountID,
+				Service:   "docdb",
+				Type:      "db-cluster-parameter-group",
+				Region:    *session.Config.Region,
+				Metadata:  structs.Map(resource),
+			})
+		}
+
+		if page.Marker == nil {
+			break
+		}
+		input.Marker = page.Marker
+	}
+
+	return result
+}
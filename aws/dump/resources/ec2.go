@@ -14,12 +14,15 @@ var (
 		Name: "ec2",
 		Reports: map[string]Report{
 			"vpcs":             EC2ListVpcs,
+			"subnets":          EC2ListSubnets,
 			"security-groups":  EC2ListSecurityGroups,
 			"images":           EC2ListImages,
 			"instances":        EC2ListInstances,
 			"launch-templates": EC2ListLaunchTemplates,
 			"nat-gateways":     EC2ListNATGateways,
 			"key-pairs":        EC2ListKeyPairs,
+			"key-pair-audit":   EC2AuditKeyPairs,
+			"instance-access":  EC2ListInstanceAccess,
 		},
 	}
 )
@@ -52,6 +55,29 @@ func EC2ListVpcs(session *Session) *ReportResult {
 	return &ReportResult{vpcs, err}
 }
 
+func EC2ListSubnets(session *Session) *ReportResult {
+	client := ec2.New(session.Session, session.Config)
+
+	subnets := []Resource{}
+	err := client.DescribeSubnetsPages(&ec2.DescribeSubnetsInput{},
+		func(page *ec2.DescribeSubnetsOutput, lastPage bool) bool {
+			for _, subnet := range page.Subnets {
+				subnets = append(subnets, Resource{
+					ID:        *subnet.SubnetId,
+					ARN:       aws.StringValue(subnet.SubnetArn),
+					Service:   "ec2",
+					Type:      "subnet",
+					AccountID: *subnet.OwnerId,
+					Region:    *session.Config.Region,
+					Metadata:  structs.Map(subnet),
+				})
+			}
+			return true
+		})
+
+	return &ReportResult{subnets, err}
+}
+
 func EC2ListSecurityGroups(session *Session) *ReportResult {
 	client := ec2.New(session.Session, session.Config)
 	result := &ReportResult{}
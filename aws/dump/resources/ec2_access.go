@@ -0,0 +1,344 @@
+package resources
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ssm"
+)
+
+var (
+	// KeyPairMaxAge is how old a key pair can be before it is reported as
+	// ancient in ec2:key-pair-audit.
+	KeyPairMaxAge = 365 * 24 * time.Hour
+	// KeyPairSharedInstances is the number of instances from which a key
+	// pair is reported as shared in ec2:key-pair-audit.
+	KeyPairSharedInstances = 5
+)
+
+const (
+	sshPort = 22
+	rdpPort = 3389
+)
+
+// EC2AuditKeyPairs reports the key pairs of a region with their age and the
+// instances launched with them. Key pairs used by KeyPairSharedInstances
+// instances or more are shared, key pairs older than KeyPairMaxAge are
+// ancient. Key pairs deleted since the instances using them were launched are
+// reported too, they can't be rotated.
+func EC2AuditKeyPairs(session *Session) *ReportResult {
+	client := ec2.New(session.Session, session.Config)
+
+	res, err := client.DescribeKeyPairs(&ec2.DescribeKeyPairsInput{})
+	if err != nil {
+		return &ReportResult{nil, err}
+	}
+
+	instances := map[string][]string{}
+	err = client.DescribeInstancesPages(&ec2.DescribeInstancesInput{},
+		func(page *ec2.DescribeInstancesOutput, lastPage bool) bool {
+			for _, reservation := range page.Reservations {
+				for _, instance := range reservation.Instances {
+					if instance.KeyName == nil || isTerminated(instance) {
+						continue
+					}
+					instances[*instance.KeyName] = append(instances[*instance.KeyName], *instance.InstanceId)
+				}
+			}
+			return true
+		})
+	if err != nil {
+		return &ReportResult{nil, err}
+	}
+
+	now := time.Now().UTC()
+	result := &ReportResult{}
+	for _, keyPair := range res.KeyPairs {
+		keyName := aws.StringValue(keyPair.KeyName)
+		metadata := map[string]interface{}{
+			"KeyName":        keyName,
+			"KeyPairId":      aws.StringValue(keyPair.KeyPairId),
+			"KeyType":        aws.StringValue(keyPair.KeyType),
+			"KeyFingerprint": aws.StringValue(keyPair.KeyFingerprint),
+			"CreateTime":     keyPair.CreateTime,
+			"Deleted":        false,
+		}
+		addKeyPairFindings(metadata, keyPair.CreateTime, instances[keyName], now)
+
+		result.Resources = append(result.Resources, Resource{
+			ID:        keyName,
+			ARN:       fmt.Sprintf("arn:aws:ec2:%s:%s:key-pair/%s", *session.Config.Region, session.AccountID, aws.StringValue(keyPair.KeyPairId)),
+			AccountID: session.AccountID,
+			Service:   "ec2",
+			Type:      "key-pair-audit",
+			Region:    *session.Config.Region,
+			Metadata:  metadata,
+		})
+		delete(instances, keyName)
+	}
+
+	for keyName, instanceIDs := range instances {
+		metadata := map[string]interface{}{
+			"KeyName": keyName,
+			"Deleted": true,
+		}
+		addKeyPairFindings(metadata, nil, instanceIDs, now)
+
+		result.Resources = append(result.Resources, Resource{
+			ID:        keyName,
+			AccountID: session.AccountID,
+			Service:   "ec2",
+			Type:      "key-pair-audit",
+			Region:    *session.Config.Region,
+			Metadata:  metadata,
+		})
+	}
+	return result
+}
+
+// addKeyPairFindings adds the instances, the age and the findings of the key
+// pair to its metadata.
+func addKeyPairFindings(metadata map[string]interface{}, createTime *time.Time, instanceIDs []string, now time.Time) {
+	sort.Strings(instanceIDs)
+	if instanceIDs == nil {
+		instanceIDs = []string{}
+	}
+	metadata["Instances"] = instanceIDs
+	metadata["InstanceCount"] = len(instanceIDs)
+
+	findings := []string{}
+	severity := ""
+	if createTime != nil {
+		age := now.Sub(*createTime)
+		metadata["AgeDays"] = int(age.Hours() / 24)
+		if age > KeyPairMaxAge {
+			findings = append(findings, "ancient")
+			severity = "medium"
+		}
+	}
+	if len(instanceIDs) >= KeyPairSharedInstances {
+		findings = append(findings, "shared")
+		severity = "medium"
+	}
+	if deleted, _ := metadata["Deleted"].(bool); deleted {
+		findings = append(findings, "deleted")
+		severity = "medium"
+	}
+	if len(instanceIDs) == 0 {
+		findings = append(findings, "unused")
+		if severity == "" {
+			severity = "low"
+		}
+	}
+	metadata["Findings"] = findings
+	metadata["Severity"] = severity
+}
+
+// EC2ListInstanceAccess reports how the instances of a region can be logged
+// into: key pair, SSH or RDP allowed by their security groups (from anywhere
+// or from EC2 Instance Connect), Session Manager when the instance is managed
+// by SSM, and Windows password data retrievable with the key pair.
+func EC2ListInstanceAccess(session *Session) *ReportResult {
+	client := ec2.New(session.Session, session.Config)
+	region := *session.Config.Region
+
+	instanceConnectPrefixLists := map[string]bool{}
+	err := client.DescribeManagedPrefixListsPages(&ec2.DescribeManagedPrefixListsInput{
+		Filters: []*ec2.Filter{{
+			Name:   aws.String("prefix-list-name"),
+			Values: aws.StringSlice([]string{fmt.Sprintf("com.amazonaws.%s.ec2-instance-connect", region)}),
+		}},
+	}, func(page *ec2.DescribeManagedPrefixListsOutput, lastPage bool) bool {
+		for _, prefixList := range page.PrefixLists {
+			instanceConnectPrefixLists[aws.StringValue(prefixList.PrefixListId)] = true
+		}
+		return true
+	})
+	if err != nil {
+		return &ReportResult{nil, err}
+	}
+
+	permissions := map[string][]*ec2.IpPermission{}
+	err = client.DescribeSecurityGroupsPages(&ec2.DescribeSecurityGroupsInput{},
+		func(page *ec2.DescribeSecurityGroupsOutput, lastPage bool) bool {
+			for _, securityGroup := range page.SecurityGroups {
+				permissions[*securityGroup.GroupId] = securityGroup.IpPermissions
+			}
+			return true
+		})
+	if err != nil {
+		return &ReportResult{nil, err}
+	}
+
+	managed := map[string]string{}
+	err = ssm.New(session.Session, session.Config).DescribeInstanceInformationPages(&ssm.DescribeInstanceInformationInput{},
+		func(page *ssm.DescribeInstanceInformationOutput, lastPage bool) bool {
+			for _, information := range page.InstanceInformationList {
+				managed[aws.StringValue(information.InstanceId)] = aws.StringValue(information.PingStatus)
+			}
+			return true
+		})
+	if err != nil {
+		return &ReportResult{nil, err}
+	}
+
+	result := &ReportResult{}
+	var passwordErr error
+	err = client.DescribeInstancesPages(&ec2.DescribeInstancesInput{},
+		func(page *ec2.DescribeInstancesOutput, lastPage bool) bool {
+			for _, reservation := range page.Reservations {
+				for _, instance := range reservation.Instances {
+					if isTerminated(instance) {
+						continue
+					}
+
+					windows := aws.StringValue(instance.Platform) == ec2.PlatformValuesWindows
+					port := int64(sshPort)
+					if windows {
+						port = rdpPort
+					}
+
+					instancePermissions := []*ec2.IpPermission{}
+					for _, group := range instance.SecurityGroups {
+						instancePermissions = append(instancePermissions, permissions[aws.StringValue(group.GroupId)]...)
+					}
+					sources := remoteAccessSources(instancePermissions, port)
+
+					metadata := map[string]interface{}{
+						"KeyName":             aws.StringValue(instance.KeyName),
+						"Platform":            aws.StringValue(instance.PlatformDetails),
+						"State":               aws.StringValue(instance.State.Name),
+						"RemoteAccessPort":    port,
+						"RemoteAccessSources": sources,
+						"OpenToWorld":         openToWorld(sources),
+						"InstanceConnect":     !windows && matchesAnySource(sources, instanceConnectPrefixLists),
+						"SSMManaged":          false,
+						"SSMPingStatus":       nil,
+						"PasswordData":        false,
+					}
+					if pingStatus, ok := managed[*instance.InstanceId]; ok {
+						metadata["SSMManaged"] = true
+						metadata["SSMPingStatus"] = pingStatus
+					}
+
+					if windows && instance.KeyName != nil {
+						res, err := client.GetPasswordData(&ec2.GetPasswordDataInput{InstanceId: instance.InstanceId})
+						if err != nil {
+							passwordErr = err
+							return false
+						}
+						metadata["PasswordData"] = aws.StringValue(res.PasswordData) != ""
+					}
+					addInstanceAccessFindings(metadata)
+
+					result.Resources = append(result.Resources, Resource{
+						ID:        *instance.InstanceId,
+						ARN:       fmt.Sprintf("arn:aws:ec2:%s:%s:instance/%s", region, session.AccountID, *instance.InstanceId),
+						AccountID: session.AccountID,
+						Service:   "ec2",
+						Type:      "instance-access",
+						Region:    region,
+						Metadata:  metadata,
+					})
+				}
+			}
+			return true
+		})
+	if err == nil {
+		err = passwordErr
+	}
+	result.Error = err
+	return result
+}
+
+// addInstanceAccessFindings adds the findings of the access metadata of an
+// instance: remote access open to the world is high, a key pair or password
+// login medium and Instance Connect or Session Manager low, they are
+// controlled by IAM.
+func addInstanceAccessFindings(metadata map[string]interface{}) {
+	findings := []string{}
+	severity := ""
+	raise := func(finding, level string) {
+		findings = append(findings, finding)
+		if findingSeverities[level] > findingSeverities[severity] {
+			severity = level
+		}
+	}
+
+	if open, _ := metadata["OpenToWorld"].(bool); open {
+		raise("open-to-world", "high")
+	}
+	if keyName, _ := metadata["KeyName"].(string); keyName != "" {
+		raise("key-pair", "medium")
+	}
+	if password, _ := metadata["PasswordData"].(bool); password {
+		raise("windows-password", "medium")
+	}
+	if instanceConnect, _ := metadata["InstanceConnect"].(bool); instanceConnect {
+		raise("instance-connect", "low")
+	}
+	if managed, _ := metadata["SSMManaged"].(bool); managed {
+		raise("session-manager", "low")
+	}
+	metadata["Findings"] = findings
+	metadata["Severity"] = severity
+}
+
+var findingSeverities = map[string]int{"": 0, "low": 1, "medium": 2, "high": 3}
+
+// remoteAccessSources returns the CIDRs, prefix lists and security groups
+// allowed to connect to the port, sorted.
+func remoteAccessSources(permissions []*ec2.IpPermission, port int64) []string {
+	sources := map[string]bool{}
+	for _, permission := range permissions {
+		protocol := aws.StringValue(permission.IpProtocol)
+		if protocol != "-1" {
+			if protocol != "tcp" && protocol != "6" {
+				continue
+			}
+			if permission.FromPort != nil && permission.ToPort != nil &&
+				(port < *permission.FromPort || port > *permission.ToPort) {
+				continue
+			}
+		}
+
+		for _, ipRange := range permission.IpRanges {
+			sources[aws.StringValue(ipRange.CidrIp)] = true
+		}
+		for _, ipRange := range permission.Ipv6Ranges {
+			sources[aws.StringValue(ipRange.CidrIpv6)] = true
+		}
+		for _, prefixList := range permission.PrefixListIds {
+			sources[aws.StringValue(prefixList.PrefixListId)] = true
+		}
+		for _, pair := range permission.UserIdGroupPairs {
+			sources[aws.StringValue(pair.GroupId)] = true
+		}
+	}
+	return sortedKeys(sources)
+}
+
+func openToWorld(sources []string) bool {
+	for _, source := range sources {
+		if source == "0.0.0.0/0" || source == "::/0" {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesAnySource(sources []string, values map[string]bool) bool {
+	for _, source := range sources {
+		if values[source] {
+			return true
+		}
+	}
+	return false
+}
+
+func isTerminated(instance *ec2.Instance) bool {
+	return instance.State != nil && aws.StringValue(instance.State.Name) == ec2.InstanceStateNameTerminated
+}
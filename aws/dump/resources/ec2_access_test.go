@@ -0,0 +1,91 @@
+package resources
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAddKeyPairFindings(t *testing.T) {
+	now := time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC)
+	old := now.AddDate(-2, 0, 0)
+	recent := now.AddDate(0, -1, 0)
+
+	metadata := map[string]interface{}{"Deleted": false}
+	addKeyPairFindings(metadata, &old, []string{"i-2", "i-1", "i-3", "i-4", "i-5"}, now)
+	require.Equal(t, []string{"ancient", "shared"}, metadata["Findings"])
+	require.Equal(t, "medium", metadata["Severity"])
+	require.Equal(t, []string{"i-1", "i-2", "i-3", "i-4", "i-5"}, metadata["Instances"])
+	require.Equal(t, 730, metadata["AgeDays"])
+
+	metadata = map[string]interface{}{"Deleted": false}
+	addKeyPairFindings(metadata, &recent, nil, now)
+	require.Equal(t, []string{"unused"}, metadata["Findings"])
+	require.Equal(t, "low", metadata["Severity"])
+	require.Equal(t, []string{}, metadata["Instances"])
+
+	metadata = map[string]interface{}{"Deleted": false}
+	addKeyPairFindings(metadata, &recent, []string{"i-1"}, now)
+	require.Equal(t, []string{}, metadata["Findings"])
+	require.Equal(t, "", metadata["Severity"])
+
+	metadata = map[string]interface{}{"Deleted": true}
+	addKeyPairFindings(metadata, nil, []string{"i-1"}, now)
+	require.Equal(t, []string{"deleted"}, metadata["Findings"])
+	require.NotContains(t, metadata, "AgeDays")
+}
+
+func TestRemoteAccessSources(t *testing.T) {
+	permissions := []*ec2.IpPermission{
+		{
+			IpProtocol:    aws.String("tcp"),
+			FromPort:      aws.Int64(22),
+			ToPort:        aws.Int64(22),
+			IpRanges:      []*ec2.IpRange{{CidrIp: aws.String("10.0.0.0/8")}},
+			PrefixListIds: []*ec2.PrefixListId{{PrefixListId: aws.String("pl-instance-connect")}},
+		},
+		{
+			IpProtocol: aws.String("tcp"),
+			FromPort:   aws.Int64(443),
+			ToPort:     aws.Int64(443),
+			IpRanges:   []*ec2.IpRange{{CidrIp: aws.String("0.0.0.0/0")}},
+		},
+		{
+			IpProtocol:       aws.String("-1"),
+			UserIdGroupPairs: []*ec2.UserIdGroupPair{{GroupId: aws.String("sg-bastion")}},
+		},
+		{
+			IpProtocol: aws.String("udp"),
+			FromPort:   aws.Int64(0),
+			ToPort:     aws.Int64(65535),
+			Ipv6Ranges: []*ec2.Ipv6Range{{CidrIpv6: aws.String("::/0")}},
+		},
+	}
+
+	sources := remoteAccessSources(permissions, sshPort)
+	require.Equal(t, []string{"10.0.0.0/8", "pl-instance-connect", "sg-bastion"}, sources)
+	require.False(t, openToWorld(sources))
+	require.True(t, matchesAnySource(sources, map[string]bool{"pl-instance-connect": true}))
+	require.True(t, openToWorld(remoteAccessSources(permissions, 443)))
+}
+
+func TestAddInstanceAccessFindings(t *testing.T) {
+	metadata := map[string]interface{}{
+		"KeyName":         "deploy",
+		"OpenToWorld":     true,
+		"InstanceConnect": false,
+		"SSMManaged":      true,
+		"PasswordData":    false,
+	}
+	addInstanceAccessFindings(metadata)
+	require.Equal(t, []string{"open-to-world", "key-pair", "session-manager"}, metadata["Findings"])
+	require.Equal(t, "high", metadata["Severity"])
+
+	metadata = map[string]interface{}{"KeyName": "", "SSMManaged": false}
+	addInstanceAccessFindings(metadata)
+	require.Equal(t, []string{}, metadata["Findings"])
+	require.Equal(t, "", metadata["Severity"])
+}
@@ -0,0 +1,144 @@
+package resources
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/efs"
+	"github.com/fatih/structs"
+)
+
+var (
+	EFSService = Service{
+		Name: "efs",
+		Reports: map[string]Report{
+			"file-systems":  EFSListFileSystems,
+			"mount-targets": EFSListMountTargets,
+			"access-points": EFSListAccessPoints,
+		},
+	}
+)
+
+func EFSListFileSystems(session *Session) *ReportResult {
+	client := efs.New(session.Session, session.Config)
+
+	result := &ReportResult{}
+	err := client.DescribeFileSystemsPages(&efs.DescribeFileSystemsInput{},
+		func(page *efs.DescribeFileSystemsOutput, lastPage bool) bool {
+			for _, fileSystem := range page.FileSystems {
+				resource := Resource{
+					ID:        *fileSystem.FileSystemId,
+					ARN:       aws.StringValue(fileSystem.FileSystemArn),
+					AccountID: session.AccountID,
+					Service:   "elasticfilesystem",
+					Type:      "file-system",
+					Region:    *session.Config.Region,
+					Metadata:  structs.Map(fileSystem),
+				}
+
+				backupPolicy, err := client.DescribeBackupPolicy(&efs.DescribeBackupPolicyInput{FileSystemId: fileSystem.FileSystemId})
+				if err != nil {
+					if !IsErrorCode(err, efs.ErrCodePolicyNotFound) {
+						result.Error = err
+						return false
+					}
+					resource.Metadata["BackupPolicy"] = nil
+				} else {
+					resource.Metadata["BackupPolicy"] = structs.Map(backupPolicy.BackupPolicy)
+				}
+
+				result.Resources = append(result.Resources, resource)
+			}
+
+			return true
+		})
+
+	if result.Error != nil {
+		return result
+	}
+	result.Error = err
+	return result
+}
+
+func EFSListMountTargets(session *Session) *ReportResult {
+	client := efs.New(session.Session, session.Config)
+
+	fileSystems := []*efs.FileSystemDescription{}
+	err := client.DescribeFileSystemsPages(&efs.DescribeFileSystemsInput{},
+		func(page *efs.DescribeFileSystemsOutput, lastPage bool) bool {
+			fileSystems = append(fileSystems, page.FileSystems...)
+			return true
+		})
+	if err != nil {
+		return &ReportResult{nil, err}
+	}
+
+	result := &ReportResult{}
+	for _, fileSystem := range fileSystems {
+		input := &efs.DescribeMountTargetsInput{FileSystemId: fileSystem.FileSystemId}
+		for {
+			page, err := client.DescribeMountTargets(input)
+			if err != nil {
+				result.Error = err
+				return result
+			}
+
+			for _, mountTarget := range page.MountTargets {
+				resource := Resource{
+					ID:        *mountTarget.MountTargetId,
+					AccountID: session.AccountID,
+					Service:   "elasticfilesystem",
+					Type:      "mount-target",
+					Region:    *session.Config.Region,
+					Metadata:  structs.Map(mountTarget),
+				}
+
+				securityGroups, err := client.DescribeMountTargetSecurityGroups(&efs.DescribeMountTargetSecurityGroupsInput{
+					MountTargetId: mountTarget.MountTargetId,
+				})
+				if err != nil {
+					result.Error = err
+					return result
+				}
+				resource.Metadata["SecurityGroups"] = aws.StringValueSlice(securityGroups.SecurityGroups)
+
+				result.Resources = append(result.Resources, resource)
+			}
+
+			if page.NextMarker == nil {
+				break
+			}
+			input.Marker = page.NextMarker
+		}
+	}
+
+	return result
+}
+
+func EFSListAccessPoints(session *Session) *ReportResult {
+	client := efs.New(session.Session, session.Config)
+
+	result := &ReportResult{}
+	input := &efs.DescribeAccessPointsInput{}
+	for {
+		page, err := client.DescribeAccessPoints(input)
+		if err != nil {
+			result.Error = err
+			return result
+		}
+
+		for _, accessPoint := range page.AccessPoints {
+			resource, err := NewResource(*accessPoint.AccessPointArn, accessPoint)
+			if err != nil {
+				result.Error = err
+				return result
+			}
+			result.Resources = append(result.Resources, *resource)
+		}
+
+		if page.NextToken == nil {
+			break
+		}
+		input.NextToken = page.NextToken
+	}
+
+	return result
+}
@@ -0,0 +1,178 @@
+package resources
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/elasticbeanstalk"
+	"github.com/fatih/structs"
+)
+
+const (
+	elasticBeanstalkEnvironmentNamespace = "aws:elasticbeanstalk:application:environment"
+)
+
+var (
+	ElasticBeanstalkService = Service{
+		Name: "elasticbeanstalk",
+		Reports: map[string]Report{
+			"applications":         ElasticBeanstalkListApplications,
+			"application-versions": ElasticBeanstalkListApplicationVersions,
+			"environments":         ElasticBeanstalkListEnvironments,
+			"saved-configurations": ElasticBeanstalkListSavedConfigurations,
+		},
+	}
+)
+
+func ElasticBeanstalkListApplications(session *Session) *ReportResult {
+	client := elasticbeanstalk.New(session.Session, session.Config)
+
+	res, err := client.DescribeApplications(&elasticbeanstalk.DescribeApplicationsInput{})
+	if err != nil {
+		return &ReportResult{nil, err}
+	}
+
+	result := &ReportResult{}
+	for _, application := range res.Applications {
+		resource, err := NewResource(*application.ApplicationArn, application)
+		if err != nil {
+			result.Error = err
+			return result
+		}
+		result.Resources = append(result.Resources, *resource)
+	}
+
+	return result
+}
+
+func ElasticBeanstalkListApplicationVersions(session *Session) *ReportResult {
+	client := elasticbeanstalk.New(session.Session, session.Config)
+
+	result := &ReportResult{}
+	input := &elasticbeanstalk.DescribeApplicationVersionsInput{}
+	for {
+		page, err := client.DescribeApplicationVersions(input)
+		if err != nil {
+			result.Error = err
+			return result
+		}
+
+		for _, version := range page.ApplicationVersions {
+			resource, err := NewResource(*version.ApplicationVersionArn, version)
+			if err != nil {
+				result.Error = err
+				return result
+			}
+			result.Resources = append(result.Resources, *resource)
+		}
+
+		if page.NextToken == nil {
+			break
+		}
+		input.NextToken = page.NextToken
+	}
+
+	return result
+}
+
+// ElasticBeanstalkListEnvironments lists the environments with their
+// configuration option settings, environment properties are redacted.
+func ElasticBeanstalkListEnvironments(session *Session) *ReportResult {
+	client := elasticbeanstalk.New(session.Session, session.Config)
+
+	result := &ReportResult{}
+	input := &elasticbeanstalk.DescribeEnvironmentsInput{}
+	for {
+		page, err := client.DescribeEnvironments(input)
+		if err != nil {
+			result.Error = err
+			return result
+		}
+
+		for _, environment := range page.Environments {
+			resource, err := NewResource(*environment.EnvironmentArn, environment)
+			if err != nil {
+				result.Error = err
+				return result
+			}
+
+			settings, err := elasticBeanstalkOptionSettings(client, &elasticbeanstalk.DescribeConfigurationSettingsInput{
+				ApplicationName: environment.ApplicationName,
+				EnvironmentName: environment.EnvironmentName,
+			})
+			if err != nil {
+				result.Error = err
+				return result
+			}
+			resource.Metadata["OptionSettings"] = settings
+
+			result.Resources = append(result.Resources, *resource)
+		}
+
+		if page.NextToken == nil {
+			break
+		}
+		input.NextToken = page.NextToken
+	}
+
+	return result
+}
+
+// ElasticBeanstalkListSavedConfigurations lists the configuration templates of
+// every application.
+func ElasticBeanstalkListSavedConfigurations(session *Session) *ReportResult {
+	client := elasticbeanstalk.New(session.Session, session.Config)
+
+	res, err := client.DescribeApplications(&elasticbeanstalk.DescribeApplicationsInput{})
+	if err != nil {
+		return &ReportResult{nil, err}
+	}
+
+	result := &ReportResult{}
+	for _, application := range res.Applications {
+		for _, templateName := range application.ConfigurationTemplates {
+			settings, err := elasticBeanstalkOptionSettings(client, &elasticbeanstalk.DescribeConfigurationSettingsInput{
+				ApplicationName: application.ApplicationName,
+				TemplateName:    templateName,
+			})
+			if err != nil {
+				result.Error = err
+				return result
+			}
+
+			result.Resources = append(result.Resources, Resource{
+				ID:        fmt.Sprintf("%s_%s", *application.ApplicationName, *templateName),
+				AccountID: session.AccountID,
+				Service:   "elasticbeanstalk",
+				Type:      "saved-configuration",
+				Region:    *session.Config.Region,
+				Metadata: map[string]interface{}{
+					"ApplicationName": *application.ApplicationName,
+					"TemplateName":    *templateName,
+					"OptionSettings":  settings,
+				},
+			})
+		}
+	}
+
+	return result
+}
+
+func elasticBeanstalkOptionSettings(client *elasticbeanstalk.ElasticBeanstalk, input *elasticbeanstalk.DescribeConfigurationSettingsInput) ([]map[string]interface{}, error) {
+	res, err := client.DescribeConfigurationSettings(input)
+	if err != nil {
+		return nil, err
+	}
+
+	settings := []map[string]interface{}{}
+	for _, configuration := range res.ConfigurationSettings {
+		for _, option := range configuration.OptionSettings {
+			if aws.StringValue(option.Namespace) == elasticBeanstalkEnvironmentNamespace {
+				option.Value = aws.String("REDACTED")
+			}
+			settings = append(settings, structs.Map(option))
+		}
+	}
+
+	return settings, nil
+}
@@ -0,0 +1,55 @@
+package resources
+
+import (
+	"github.com/aws/aws-sdk-go/service/elbv2"
+	"github.com/fatih/structs"
+)
+
+var (
+	ELBv2Service = Service{
+		Name: "elbv2",
+		Reports: map[string]Report{
+			"load-balancers": ELBv2ListLoadBalancers,
+		},
+	}
+)
+
+func ELBv2ListLoadBalancers(session *Session) *ReportResult {
+	client := elbv2.New(session.Session, session.Config)
+
+	result := &ReportResult{}
+	err := client.DescribeLoadBalancersPages(&elbv2.DescribeLoadBalancersInput{},
+		func(page *elbv2.DescribeLoadBalancersOutput, lastPage bool) bool {
+			for _, loadBalancer := range page.LoadBalancers {
+				resource, err := NewResource(*loadBalancer.LoadBalancerArn, loadBalancer)
+				if err != nil {
+					result.Error = err
+					return false
+				}
+
+				listeners := []interface{}{}
+				err = client.DescribeListenersPages(&elbv2.DescribeListenersInput{LoadBalancerArn: loadBalancer.LoadBalancerArn},
+					func(page *elbv2.DescribeListenersOutput, lastPage bool) bool {
+						for _, listener := range page.Listeners {
+							listeners = append(listeners, structs.Map(listener))
+						}
+						return true
+					})
+				if err != nil {
+					result.Error = err
+					return false
+				}
+				resource.Metadata["Listeners"] = listeners
+
+				result.Resources = append(result.Resources, *resource)
+			}
+
+			return true
+		})
+
+	if result.Error != nil {
+		return result
+	}
+	result.Error = err
+	return result
+}
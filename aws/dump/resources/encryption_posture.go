@@ -0,0 +1,469 @@
+package resources
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/efs"
+	"github.com/aws/aws-sdk-go/service/kms"
+	"github.com/aws/aws-sdk-go/service/rds"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/sns"
+	"github.com/aws/aws-sdk-go/service/sqs"
+)
+
+const (
+	EncryptionNone               = "none"
+	EncryptionServiceManaged     = "service-managed"
+	EncryptionAWSManagedKMS      = "aws-managed-kms"
+	EncryptionCustomerManagedKMS = "customer-managed-kms"
+	// the key couldn't be described, e.g. a key of another account
+	EncryptionUnknownKMS = "unknown-kms"
+)
+
+type encryptionCandidate struct {
+	ID           string
+	ARN          string
+	ResourceType string
+
+	Encrypted bool
+	// KMS key, empty when encrypted with a key owned by the service
+	KeyID      string
+	KeyManager string
+}
+
+// Encryption returns how the resource is encrypted.
+func (c *encryptionCandidate) Encryption() string {
+	switch {
+	case !c.Encrypted:
+		return EncryptionNone
+	case c.KeyID == "":
+		return EncryptionServiceManaged
+	case c.KeyManager == kms.KeyManagerTypeAws:
+		return EncryptionAWSManagedKMS
+	case c.KeyManager == kms.KeyManagerTypeCustomer:
+		return EncryptionCustomerManagedKMS
+	}
+	return EncryptionUnknownKMS
+}
+
+// KMSListEncryptionPosture reports whether the EBS volumes, S3 buckets, RDS
+// instances, Aurora clusters, SQS queues, SNS topics, DynamoDB tables, EFS
+// file systems and log groups of a region are encrypted and with which KMS
+// key, and a summary per resource type.
+func KMSListEncryptionPosture(session *Session) *ReportResult {
+	candidates := []*encryptionCandidate{}
+
+	errors := ReportErrors{}
+	for _, fn := range []func(*Session) ([]*encryptionCandidate, error){
+		encryptionPostureEBS,
+		encryptionPostureS3,
+		encryptionPostureRDS,
+		encryptionPostureSQS,
+		encryptionPostureSNS,
+		encryptionPostureDynamoDB,
+		encryptionPostureEFS,
+		encryptionPostureLogGroups,
+	} {
+		found, err := fn(session)
+		if err != nil && !errors.Add(err) {
+			return &ReportResult{nil, err}
+		}
+		candidates = append(candidates, found...)
+	}
+
+	err := encryptionPostureKeyManagers(session, candidates)
+	if err != nil && !errors.Add(err) {
+		return &ReportResult{nil, err}
+	}
+
+	result := &ReportResult{Error: errors.ErrorOrNil()}
+	for _, candidate := range candidates {
+		result.Resources = append(result.Resources, Resource{
+			ID:        candidate.ARN,
+			AccountID: session.AccountID,
+			Service:   "kms",
+			Type:      "encryption-posture",
+			Region:    *session.Config.Region,
+			Metadata: map[string]interface{}{
+				"ResourceId":   candidate.ID,
+				"ResourceArn":  candidate.ARN,
+				"ResourceType": candidate.ResourceType,
+				"Encrypted":    candidate.Encrypted,
+				"Encryption":   candidate.Encryption(),
+				"KeyId":        candidate.KeyID,
+				"KeyManager":   candidate.KeyManager,
+			},
+		})
+	}
+
+	for _, summary := range summariseEncryptionPosture(candidates) {
+		result.Resources = append(result.Resources, Resource{
+			ID:        summary["ResourceType"].(string),
+			AccountID: session.AccountID,
+			Service:   "kms",
+			Type:      "encryption-posture-summary",
+			Region:    *session.Config.Region,
+			Metadata:  summary,
+		})
+	}
+	return result
+}
+
+// summariseEncryptionPosture counts the resources of each type by encryption,
+// sorted by resource type.
+func summariseEncryptionPosture(candidates []*encryptionCandidate) []map[string]interface{} {
+	counts := map[string]map[string]int{}
+	keys := map[string]map[string]bool{}
+	for _, candidate := range candidates {
+		if counts[candidate.ResourceType] == nil {
+			counts[candidate.ResourceType] = map[string]int{}
+			keys[candidate.ResourceType] = map[string]bool{}
+		}
+		counts[candidate.ResourceType][candidate.Encryption()]++
+		if candidate.Encryption() == EncryptionCustomerManagedKMS {
+			keys[candidate.ResourceType][candidate.KeyID] = true
+		}
+	}
+
+	resourceTypes := []string{}
+	for resourceType := range counts {
+		resourceTypes = append(resourceTypes, resourceType)
+	}
+	sort.Strings(resourceTypes)
+
+	summaries := []map[string]interface{}{}
+	for _, resourceType := range resourceTypes {
+		count := counts[resourceType]
+		total := 0
+		for _, n := range count {
+			total += n
+		}
+		summaries = append(summaries, map[string]interface{}{
+			"ResourceType":        resourceType,
+			"Total":               total,
+			"Encrypted":           total - count[EncryptionNone],
+			"Unencrypted":         count[EncryptionNone],
+			"ServiceManaged":      count[EncryptionServiceManaged],
+			"AWSManagedKMS":       count[EncryptionAWSManagedKMS],
+			"CustomerManagedKMS":  count[EncryptionCustomerManagedKMS],
+			"UnknownKMS":          count[EncryptionUnknownKMS],
+			"CustomerManagedKeys": sortedKeys(keys[resourceType]),
+		})
+	}
+	return summaries
+}
+
+// encryptionPostureKeyManagers sets whether the KMS keys of the candidates are
+// AWS managed or customer managed. The keys that can't be described are left
+// unknown, the candidates can use keys of other accounts.
+func encryptionPostureKeyManagers(session *Session, candidates []*encryptionCandidate) error {
+	client := kms.New(session.Session, session.Config)
+
+	managers := map[string]string{}
+	for _, candidate := range candidates {
+		if !candidate.Encrypted || candidate.KeyID == "" {
+			continue
+		}
+		if strings.HasPrefix(candidate.KeyID, "alias/aws/") {
+			candidate.KeyManager = kms.KeyManagerTypeAws
+			continue
+		}
+
+		manager, ok := managers[candidate.KeyID]
+		if !ok {
+			res, err := client.DescribeKey(&kms.DescribeKeyInput{KeyId: aws.String(candidate.KeyID)})
+			if err != nil {
+				if !IsErrorCode(err, kms.ErrCodeNotFoundException) && !IsErrorCode(err, "AccessDeniedException") {
+					return err
+				}
+			} else {
+				manager = aws.StringValue(res.KeyMetadata.KeyManager)
+			}
+			managers[candidate.KeyID] = manager
+		}
+		candidate.KeyManager = manager
+	}
+	return nil
+}
+
+func encryptionPostureEBS(session *Session) ([]*encryptionCandidate, error) {
+	client := ec2.New(session.Session, session.Config)
+
+	candidates := []*encryptionCandidate{}
+	err := client.DescribeVolumesPages(&ec2.DescribeVolumesInput{},
+		func(page *ec2.DescribeVolumesOutput, lastPage bool) bool {
+			for _, volume := range page.Volumes {
+				candidates = append(candidates, &encryptionCandidate{
+					ID:           *volume.VolumeId,
+					ARN:          fmt.Sprintf("arn:aws:ec2:%s:%s:volume/%s", *session.Config.Region, session.AccountID, *volume.VolumeId),
+					ResourceType: "EBS",
+					Encrypted:    aws.BoolValue(volume.Encrypted),
+					KeyID:        aws.StringValue(volume.KmsKeyId),
+				})
+			}
+			return true
+		})
+	return candidates, err
+}
+
+// encryptionPostureS3 reports the default encryption of the buckets of the
+// region.
+func encryptionPostureS3(session *Session) ([]*encryptionCandidate, error) {
+	client := s3.New(session.Session, session.Config)
+
+	res, err := client.ListBuckets(&s3.ListBucketsInput{})
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := []*encryptionCandidate{}
+	for _, bucket := range res.Buckets {
+		location, err := client.GetBucketLocation(&s3.GetBucketLocationInput{Bucket: bucket.Name})
+		if err != nil {
+			return nil, err
+		}
+		// the location of the buckets in us-east-1 is empty
+		region := aws.StringValue(location.LocationConstraint)
+		if region == "" {
+			region = "us-east-1"
+		}
+		if region != *session.Config.Region {
+			continue
+		}
+
+		candidate := &encryptionCandidate{
+			ID:           *bucket.Name,
+			ARN:          fmt.Sprintf("arn:aws:s3:::%s", *bucket.Name),
+			ResourceType: "S3",
+		}
+		candidates = append(candidates, candidate)
+
+		encryption, err := client.GetBucketEncryption(&s3.GetBucketEncryptionInput{Bucket: bucket.Name})
+		if err != nil {
+			if IsErrorCode(err, "ServerSideEncryptionConfigurationNotFoundError") {
+				continue
+			}
+			return nil, err
+		}
+		if encryption.ServerSideEncryptionConfiguration == nil {
+			continue
+		}
+		for _, rule := range encryption.ServerSideEncryptionConfiguration.Rules {
+			if rule.ApplyServerSideEncryptionByDefault == nil {
+				continue
+			}
+			candidate.Encrypted = true
+			if aws.StringValue(rule.ApplyServerSideEncryptionByDefault.SSEAlgorithm) != s3.ServerSideEncryptionAes256 {
+				// aws:kms without a key is the AWS managed key of S3
+				candidate.KeyID = aws.StringValue(rule.ApplyServerSideEncryptionByDefault.KMSMasterKeyID)
+				if candidate.KeyID == "" {
+					candidate.KeyID = "alias/aws/s3"
+				}
+			}
+		}
+	}
+	return candidates, nil
+}
+
+func encryptionPostureRDS(session *Session) ([]*encryptionCandidate, error) {
+	client := rds.New(session.Session, session.Config)
+
+	candidates := []*encryptionCandidate{}
+	err := client.DescribeDBInstancesPages(&rds.DescribeDBInstancesInput{},
+		func(page *rds.DescribeDBInstancesOutput, lastPage bool) bool {
+			for _, instance := range page.DBInstances {
+				// the storage of aurora instances is the one of their cluster
+				if instance.DBClusterIdentifier != nil {
+					continue
+				}
+				candidates = append(candidates, &encryptionCandidate{
+					ID:           *instance.DBInstanceIdentifier,
+					ARN:          *instance.DBInstanceArn,
+					ResourceType: "RDS",
+					Encrypted:    aws.BoolValue(instance.StorageEncrypted),
+					KeyID:        aws.StringValue(instance.KmsKeyId),
+				})
+			}
+			return true
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	err = client.DescribeDBClustersPages(&rds.DescribeDBClustersInput{},
+		func(page *rds.DescribeDBClustersOutput, lastPage bool) bool {
+			for _, cluster := range page.DBClusters {
+				candidates = append(candidates, &encryptionCandidate{
+					ID:           *cluster.DBClusterIdentifier,
+					ARN:          *cluster.DBClusterArn,
+					ResourceType: "Aurora",
+					Encrypted:    aws.BoolValue(cluster.StorageEncrypted),
+					KeyID:        aws.StringValue(cluster.KmsKeyId),
+				})
+			}
+			return true
+		})
+	return candidates, err
+}
+
+func encryptionPostureSQS(session *Session) ([]*encryptionCandidate, error) {
+	client := sqs.New(session.Session, session.Config)
+
+	urls := []*string{}
+	err := client.ListQueuesPages(&sqs.ListQueuesInput{},
+		func(page *sqs.ListQueuesOutput, lastPage bool) bool {
+			urls = append(urls, page.QueueUrls...)
+			return true
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := []*encryptionCandidate{}
+	for _, url := range urls {
+		res, err := client.GetQueueAttributes(&sqs.GetQueueAttributesInput{
+			QueueUrl:       url,
+			AttributeNames: aws.StringSlice([]string{"QueueArn", "KmsMasterKeyId", "SqsManagedSseEnabled"}),
+		})
+		if err != nil {
+			if IsErrorCode(err, sqs.ErrCodeQueueDoesNotExist) {
+				continue
+			}
+			return nil, err
+		}
+
+		keyID := aws.StringValue(res.Attributes["KmsMasterKeyId"])
+		candidates = append(candidates, &encryptionCandidate{
+			ID:           aws.StringValue(url),
+			ARN:          aws.StringValue(res.Attributes["QueueArn"]),
+			ResourceType: "SQS",
+			Encrypted:    keyID != "" || aws.StringValue(res.Attributes["SqsManagedSseEnabled"]) == "true",
+			KeyID:        keyID,
+		})
+	}
+	return candidates, nil
+}
+
+func encryptionPostureSNS(session *Session) ([]*encryptionCandidate, error) {
+	client := sns.New(session.Session, session.Config)
+
+	arns := []*string{}
+	err := client.ListTopicsPages(&sns.ListTopicsInput{},
+		func(page *sns.ListTopicsOutput, lastPage bool) bool {
+			for _, topic := range page.Topics {
+				arns = append(arns, topic.TopicArn)
+			}
+			return true
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := []*encryptionCandidate{}
+	for _, arn := range arns {
+		res, err := client.GetTopicAttributes(&sns.GetTopicAttributesInput{TopicArn: arn})
+		if err != nil {
+			if IsErrorCode(err, sns.ErrCodeNotFoundException) {
+				continue
+			}
+			return nil, err
+		}
+
+		keyID := aws.StringValue(res.Attributes["KmsMasterKeyId"])
+		candidates = append(candidates, &encryptionCandidate{
+			ID:           aws.StringValue(arn),
+			ARN:          aws.StringValue(arn),
+			ResourceType: "SNS",
+			Encrypted:    keyID != "",
+			KeyID:        keyID,
+		})
+	}
+	return candidates, nil
+}
+
+// encryptionPostureDynamoDB reports the tables, which are always encrypted,
+// with a key owned by DynamoDB when there is no SSE description.
+func encryptionPostureDynamoDB(session *Session) ([]*encryptionCandidate, error) {
+	client := dynamodb.New(session.Session, session.Config)
+
+	names := []*string{}
+	err := client.ListTablesPages(&dynamodb.ListTablesInput{},
+		func(page *dynamodb.ListTablesOutput, lastPage bool) bool {
+			names = append(names, page.TableNames...)
+			return true
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	candidates := []*encryptionCandidate{}
+	for _, name := range names {
+		res, err := client.DescribeTable(&dynamodb.DescribeTableInput{TableName: name})
+		if err != nil {
+			if IsErrorCode(err, dynamodb.ErrCodeResourceNotFoundException) {
+				continue
+			}
+			return nil, err
+		}
+
+		candidate := &encryptionCandidate{
+			ID:           aws.StringValue(name),
+			ARN:          aws.StringValue(res.Table.TableArn),
+			ResourceType: "DynamoDB",
+			Encrypted:    true,
+		}
+		if sse := res.Table.SSEDescription; sse != nil && aws.StringValue(sse.Status) == dynamodb.SSEStatusEnabled {
+			candidate.KeyID = aws.StringValue(sse.KMSMasterKeyArn)
+		}
+		candidates = append(candidates, candidate)
+	}
+	return candidates, nil
+}
+
+func encryptionPostureEFS(session *Session) ([]*encryptionCandidate, error) {
+	client := efs.New(session.Session, session.Config)
+
+	candidates := []*encryptionCandidate{}
+	err := client.DescribeFileSystemsPages(&efs.DescribeFileSystemsInput{},
+		func(page *efs.DescribeFileSystemsOutput, lastPage bool) bool {
+			for _, fileSystem := range page.FileSystems {
+				candidates = append(candidates, &encryptionCandidate{
+					ID:           *fileSystem.FileSystemId,
+					ARN:          aws.StringValue(fileSystem.FileSystemArn),
+					ResourceType: "EFS",
+					Encrypted:    aws.BoolValue(fileSystem.Encrypted),
+					KeyID:        aws.StringValue(fileSystem.KmsKeyId),
+				})
+			}
+			return true
+		})
+	return candidates, err
+}
+
+// encryptionPostureLogGroups reports the log groups, which are always
+// encrypted, with a key owned by CloudWatch Logs when they have no KMS key.
+func encryptionPostureLogGroups(session *Session) ([]*encryptionCandidate, error) {
+	client := cloudwatchlogs.New(session.Session, session.Config)
+
+	candidates := []*encryptionCandidate{}
+	err := client.DescribeLogGroupsPages(&cloudwatchlogs.DescribeLogGroupsInput{},
+		func(page *cloudwatchlogs.DescribeLogGroupsOutput, lastPage bool) bool {
+			for _, logGroup := range page.LogGroups {
+				candidates = append(candidates, &encryptionCandidate{
+					ID:           aws.StringValue(logGroup.LogGroupName),
+					ARN:          strings.TrimSuffix(aws.StringValue(logGroup.Arn), ":*"),
+					ResourceType: "LogGroup",
+					Encrypted:    true,
+					KeyID:        aws.StringValue(logGroup.KmsKeyId),
+				})
+			}
+			return true
+		})
+	return candidates, err
+}
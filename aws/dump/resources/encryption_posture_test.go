@@ -0,0 +1,54 @@
+package resources
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/service/kms"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncryptionCandidateEncryption(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		candidate  encryptionCandidate
+		encryption string
+	}{
+		{encryptionCandidate{}, EncryptionNone},
+		{encryptionCandidate{Encrypted: true}, EncryptionServiceManaged},
+		{encryptionCandidate{Encrypted: true, KeyID: "alias/aws/sqs", KeyManager: kms.KeyManagerTypeAws}, EncryptionAWSManagedKMS},
+		{encryptionCandidate{Encrypted: true, KeyID: "key-1", KeyManager: kms.KeyManagerTypeCustomer}, EncryptionCustomerManagedKMS},
+		{encryptionCandidate{Encrypted: true, KeyID: "arn:aws:kms:eu-west-1:123456789012:key/key-2"}, EncryptionUnknownKMS},
+	}
+	for _, testCase := range testCases {
+		require.Equal(t, testCase.encryption, testCase.candidate.Encryption())
+	}
+}
+
+func TestSummariseEncryptionPosture(t *testing.T) {
+	t.Parallel()
+
+	summaries := summariseEncryptionPosture([]*encryptionCandidate{
+		{ResourceType: "SQS"},
+		{ResourceType: "SQS", Encrypted: true},
+		{ResourceType: "EBS", Encrypted: true, KeyID: "key-2", KeyManager: kms.KeyManagerTypeCustomer},
+		{ResourceType: "EBS", Encrypted: true, KeyID: "key-1", KeyManager: kms.KeyManagerTypeCustomer},
+		{ResourceType: "EBS", Encrypted: true, KeyID: "key-1", KeyManager: kms.KeyManagerTypeCustomer},
+		{ResourceType: "EBS", Encrypted: true, KeyID: "alias/aws/ebs", KeyManager: kms.KeyManagerTypeAws},
+		{ResourceType: "EBS"},
+	})
+
+	require.Len(t, summaries, 2)
+	require.Equal(t, "EBS", summaries[0]["ResourceType"])
+	require.Equal(t, 5, summaries[0]["Total"])
+	require.Equal(t, 4, summaries[0]["Encrypted"])
+	require.Equal(t, 1, summaries[0]["Unencrypted"])
+	require.Equal(t, 1, summaries[0]["AWSManagedKMS"])
+	require.Equal(t, 3, summaries[0]["CustomerManagedKMS"])
+	require.Equal(t, []string{"key-1", "key-2"}, summaries[0]["CustomerManagedKeys"])
+
+	require.Equal(t, "SQS", summaries[1]["ResourceType"])
+	require.Equal(t, 2, summaries[1]["Total"])
+	require.Equal(t, 1, summaries[1]["ServiceManaged"])
+	require.Equal(t, 1, summaries[1]["Unencrypted"])
+}
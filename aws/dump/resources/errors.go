@@ -0,0 +1,90 @@
+package resources
+
+import (
+	"fmt"
+	"strings"
+)
+
+var (
+	// PartialResults makes the reports made of several sub-reports run all
+	// of them and return the resources of the ones that succeeded with the
+	// errors of the others, instead of stopping at the first error.
+	PartialResults bool
+
+	// MaxRetries is the number of retries of each API call of the sessions
+	// opened by OpenSessions, with exponential backoff. The SDK default is
+	// used when 0.
+	MaxRetries int
+)
+
+// JobError is the error of the report of a job.
+type JobError struct {
+	Service   string `json:"service"`
+	Report    string `json:"report"`
+	AccountID string `json:"account_id"`
+	Region    string `json:"region"`
+	Message   string `json:"error"`
+}
+
+func NewJobError(job Job, err error) *JobError {
+	jobError := &JobError{
+		Service: job.Service,
+		Report:  job.Name,
+		Message: err.Error(),
+	}
+	if job.Session != nil {
+		jobError.AccountID = job.Session.AccountID
+		jobError.Region = *job.Session.Config.Region
+	}
+	return jobError
+}
+
+func (e *JobError) Error() string {
+	if e.AccountID == "" {
+		return fmt.Sprintf("%s:%s: %s", e.Service, e.Report, e.Message)
+	}
+	return fmt.Sprintf("%s:%s in %s %s: %s", e.Service, e.Report, e.AccountID, e.Region, e.Message)
+}
+
+// Resource returns the marker added to the output for the error.
+func (e *JobError) Resource() Resource {
+	return Resource{
+		ID:        fmt.Sprintf("%s:%s", e.Service, e.Report),
+		Service:   "aws-dump",
+		Type:      "error",
+		AccountID: e.AccountID,
+		Region:    e.Region,
+		Metadata: map[string]interface{}{
+			"Service": e.Service,
+			"Report":  e.Report,
+			"Error":   e.Message,
+		},
+	}
+}
+
+// ReportErrors are the errors of the sub-reports of a report.
+type ReportErrors []error
+
+func (e ReportErrors) Error() string {
+	messages := []string{}
+	for _, err := range e {
+		messages = append(messages, err.Error())
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Add returns whether the report should go on after the error of a
+// sub-report, only with PartialResults.
+func (e *ReportErrors) Add(err error) bool {
+	*e = append(*e, err)
+	return PartialResults
+}
+
+// ErrorOrNil returns nil when there is no error, to be used as the error of
+// the ReportResult.
+func (e ReportErrors) ErrorOrNil() error {
+	if len(e) == 0 {
+		return nil
+	}
+	return e
+}
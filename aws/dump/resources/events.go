@@ -0,0 +1,241 @@
+package resources
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/arn"
+	"github.com/aws/aws-sdk-go/service/eventbridge"
+	"github.com/aws/aws-sdk-go/service/scheduler"
+	"github.com/fatih/structs"
+)
+
+var (
+	EventsService = Service{
+		Name: "events",
+		Reports: map[string]Report{
+			"rules":          EventsListRules,
+			"schedules":      EventsListSchedules,
+			"scheduled-jobs": EventsListScheduledJobs,
+		},
+	}
+)
+
+type eventsRule struct {
+	Rule    *eventbridge.Rule
+	Targets []*eventbridge.Target
+}
+
+// eventsListRules lists the rules of every event bus with their targets.
+func eventsListRules(client *eventbridge.EventBridge) ([]*eventsRule, error) {
+	busNames := []*string{}
+	busesInput := &eventbridge.ListEventBusesInput{}
+	for {
+		page, err := client.ListEventBuses(busesInput)
+		if err != nil {
+			return nil, err
+		}
+		for _, bus := range page.EventBuses {
+			busNames = append(busNames, bus.Name)
+		}
+		if page.NextToken == nil {
+			break
+		}
+		busesInput.NextToken = page.NextToken
+	}
+
+	rules := []*eventsRule{}
+	for _, busName := range busNames {
+		rulesInput := &eventbridge.ListRulesInput{EventBusName: busName}
+		for {
+			page, err := client.ListRules(rulesInput)
+			if err != nil {
+				return nil, err
+			}
+
+			for _, rule := range page.Rules {
+				targets := []*eventbridge.Target{}
+				targetsInput := &eventbridge.ListTargetsByRuleInput{Rule: rule.Name, EventBusName: busName}
+				for {
+					targetsPage, err := client.ListTargetsByRule(targetsInput)
+					if err != nil {
+						return nil, err
+					}
+					targets = append(targets, targetsPage.Targets...)
+					if targetsPage.NextToken == nil {
+						break
+					}
+					targetsInput.NextToken = targetsPage.NextToken
+				}
+				rules = append(rules, &eventsRule{Rule: rule, Targets: targets})
+			}
+
+			if page.NextToken == nil {
+				break
+			}
+			rulesInput.NextToken = page.NextToken
+		}
+	}
+	return rules, nil
+}
+
+// eventsListSchedules lists the EventBridge Scheduler schedules of every group.
+func eventsListSchedules(client *scheduler.Scheduler) ([]*scheduler.GetScheduleOutput, error) {
+	schedules := []*scheduler.GetScheduleOutput{}
+	input := &scheduler.ListSchedulesInput{}
+	for {
+		page, err := client.ListSchedules(input)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, summary := range page.Schedules {
+			// the summary doesn't include the expression
+			schedule, err := client.GetSchedule(&scheduler.GetScheduleInput{Name: summary.Name, GroupName: summary.GroupName})
+			if err != nil {
+				return nil, err
+			}
+			schedules = append(schedules, schedule)
+		}
+
+		if page.NextToken == nil {
+			break
+		}
+		input.NextToken = page.NextToken
+	}
+	return schedules, nil
+}
+
+func EventsListRules(session *Session) *ReportResult {
+	client := eventbridge.New(session.Session, session.Config)
+
+	rules, err := eventsListRules(client)
+	if err != nil {
+		return &ReportResult{nil, err}
+	}
+
+	result := &ReportResult{}
+	for _, rule := range rules {
+		resource := Resource{
+			ID:        *rule.Rule.Arn,
+			ARN:       *rule.Rule.Arn,
+			AccountID: session.AccountID,
+			Service:   "events",
+			Type:      "rule",
+			Region:    *session.Config.Region,
+			Metadata:  structs.Map(rule.Rule),
+		}
+		targets := []map[string]interface{}{}
+		for _, target := range rule.Targets {
+			targets = append(targets, structs.Map(target))
+		}
+		resource.Metadata["Targets"] = targets
+		result.Resources = append(result.Resources, resource)
+	}
+	return result
+}
+
+func EventsListSchedules(session *Session) *ReportResult {
+	client := scheduler.New(session.Session, session.Config)
+
+	schedules, err := eventsListSchedules(client)
+	if err != nil {
+		return &ReportResult{nil, err}
+	}
+
+	result := &ReportResult{}
+	for _, schedule := range schedules {
+		result.Resources = append(result.Resources, Resource{
+			ID:        *schedule.Arn,
+			ARN:       *schedule.Arn,
+			AccountID: session.AccountID,
+			Service:   "events",
+			Type:      "schedule",
+			Region:    *session.Config.Region,
+			Metadata:  structs.Map(schedule),
+		})
+	}
+	return result
+}
+
+// EventsListScheduledJobs returns one resource per target of the rules with a
+// schedule expression and of the EventBridge Scheduler schedules, as a single
+// inventory of the periodic jobs.
+func EventsListScheduledJobs(session *Session) *ReportResult {
+	rules, err := eventsListRules(eventbridge.New(session.Session, session.Config))
+	if err != nil {
+		return &ReportResult{nil, err}
+	}
+
+	schedules, err := eventsListSchedules(scheduler.New(session.Session, session.Config))
+	if err != nil {
+		return &ReportResult{nil, err}
+	}
+
+	result := &ReportResult{}
+	newJob := func(id string, metadata map[string]interface{}) Resource {
+		return Resource{
+			ID:        id,
+			AccountID: session.AccountID,
+			Service:   "events",
+			Type:      "scheduled-job",
+			Region:    *session.Config.Region,
+			Metadata:  metadata,
+		}
+	}
+
+	for _, rule := range rules {
+		if aws.StringValue(rule.Rule.ScheduleExpression) == "" {
+			continue
+		}
+		for _, target := range rule.Targets {
+			result.Resources = append(result.Resources, newJob(
+				fmt.Sprintf("%s/%s", *rule.Rule.Arn, *target.Id),
+				scheduledJobMetadata("eventbridge-rule", *rule.Rule.Arn, *rule.Rule.ScheduleExpression, "UTC", aws.StringValue(rule.Rule.State), *target.Arn),
+			))
+		}
+	}
+
+	for _, schedule := range schedules {
+		timezone := aws.StringValue(schedule.ScheduleExpressionTimezone)
+		if timezone == "" {
+			timezone = "UTC"
+		}
+		result.Resources = append(result.Resources, newJob(
+			*schedule.Arn,
+			scheduledJobMetadata("eventbridge-scheduler", *schedule.Arn, aws.StringValue(schedule.ScheduleExpression), timezone, aws.StringValue(schedule.State), aws.StringValue(schedule.Target.Arn)),
+		))
+	}
+
+	return result
+}
+
+func scheduledJobMetadata(source, scheduleARN, expression, timezone, state, targetARN string) map[string]interface{} {
+	return map[string]interface{}{
+		"Source":             source,
+		"ScheduleArn":        scheduleARN,
+		"ScheduleExpression": expression,
+		"Timezone":           timezone,
+		"State":              state,
+		"TargetArn":          targetARN,
+		"TargetService":      scheduledJobTargetService(targetARN),
+	}
+}
+
+// scheduledJobTargetService returns the service of the target, e.g. lambda or
+// ecs, or the called service for EventBridge Scheduler universal targets
+// (arn:aws:scheduler:::aws-sdk:service:apiAction).
+func scheduledJobTargetService(targetARN string) string {
+	parsed, err := arn.Parse(targetARN)
+	if err != nil {
+		return ""
+	}
+	if parsed.Service == "scheduler" && strings.HasPrefix(parsed.Resource, "aws-sdk:") {
+		parts := strings.Split(parsed.Resource, ":")
+		if len(parts) >= 2 {
+			return parts[1]
+		}
+	}
+	return parsed.Service
+}
@@ -0,0 +1,14 @@
+package resources
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestScheduledJobTargetService(t *testing.T) {
+	require.Equal(t, "lambda", scheduledJobTargetService("arn:aws:lambda:eu-west-1:123456789012:function:cleanup"))
+	require.Equal(t, "ecs", scheduledJobTargetService("arn:aws:ecs:eu-west-1:123456789012:cluster/jobs"))
+	require.Equal(t, "sqs", scheduledJobTargetService("arn:aws:scheduler:::aws-sdk:sqs:sendMessage"))
+	require.Equal(t, "", scheduledJobTargetService("not-an-arn"))
+}
@@ -0,0 +1,57 @@
+package resources
+
+import (
+	"github.com/aws/aws-sdk-go/service/firehose"
+	"github.com/fatih/structs"
+)
+
+var (
+	FirehoseService = Service{
+		Name: "firehose",
+		Reports: map[string]Report{
+			"delivery-streams": FirehoseListDeliveryStreams,
+		},
+	}
+)
+
+func FirehoseListDeliveryStreams(session *Session) *ReportResult {
+	client := firehose.New(session.Session, session.Config)
+
+	result := &ReportResult{}
+	input := &firehose.ListDeliveryStreamsInput{}
+	for {
+		page, err := client.ListDeliveryStreams(input)
+		if err != nil {
+			result.Error = err
+			return result
+		}
+
+		for _, deliveryStreamName := range page.DeliveryStreamNames {
+			deliveryStream, err := client.DescribeDeliveryStream(&firehose.DescribeDeliveryStreamInput{
+				DeliveryStreamName: deliveryStreamName,
+			})
+			if err != nil {
+				result.Error = err
+				return result
+			}
+
+			description := deliveryStream.DeliveryStreamDescription
+			result.Resources = append(result.Resources, Resource{
+				ID:        *description.DeliveryStreamName,
+				ARN:       *description.DeliveryStreamARN,
+				AccountID: session.AccountID,
+				Service:   "firehose",
+				Type:      "delivery-stream",
+				Region:    *session.Config.Region,
+				Metadata:  structs.Map(description),
+			})
+		}
+
+		if !*page.HasMoreDeliveryStreams || len(page.DeliveryStreamNames) == 0 {
+			break
+		}
+		input.ExclusiveStartDeliveryStreamName = page.DeliveryStreamNames[len(page.DeliveryStreamNames)-1]
+	}
+
+	return result
+}
@@ -0,0 +1,327 @@
+package resources
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+)
+
+var (
+	// RecordFixtures records the API calls of the sessions opened by
+	// OpenSessions when set.
+	RecordFixtures *FixtureRecorder
+)
+
+// FixtureRequest identifies the request of a fixture. Service and Region come
+// from the credential scope of the signature so requests to different
+// services on the same endpoint can be told apart. An empty Region matches
+// all the regions.
+type FixtureRequest struct {
+	Service string `json:"service"`
+	Region  string `json:"region,omitempty"`
+	Method  string `json:"method"`
+	Path    string `json:"path"`
+	Query   string `json:"query,omitempty"`
+	Target  string `json:"target,omitempty"`
+	Body    string `json:"body,omitempty"`
+}
+
+type FixtureResponse struct {
+	StatusCode int               `json:"status_code"`
+	Headers    map[string]string `json:"headers,omitempty"`
+	Body       string            `json:"body"`
+}
+
+type Fixture struct {
+	Request  FixtureRequest  `json:"request"`
+	Response FixtureResponse `json:"response"`
+}
+
+// headers of the responses that are not recorded
+var ignoredFixtureHeaders = map[string]bool{
+	"Connection":        true,
+	"Content-Length":    true,
+	"Date":              true,
+	"Server":            true,
+	"Transfer-Encoding": true,
+	"X-Amz-Id-2":        true,
+	"X-Amz-Request-Id":  true,
+	"X-Amzn-Requestid":  true,
+}
+
+func newFixtureRequest(request *http.Request, body []byte) FixtureRequest {
+	fixtureRequest := FixtureRequest{
+		Method: request.Method,
+		Path:   request.URL.Path,
+		Query:  request.URL.RawQuery,
+		Target: request.Header.Get("X-Amz-Target"),
+		Body:   string(body),
+	}
+
+	// Credential=AKID/20210101/eu-west-1/ec2/aws4_request
+	authorization := request.Header.Get("Authorization")
+	if index := strings.Index(authorization, "Credential="); index >= 0 {
+		scope := strings.Split(strings.SplitN(authorization[index+len("Credential="):], ",", 2)[0], "/")
+		if len(scope) >= 4 {
+			fixtureRequest.Region = scope[2]
+			fixtureRequest.Service = scope[3]
+		}
+	}
+
+	return fixtureRequest
+}
+
+// equalBodies compares JSON bodies and form encoded bodies (query protocol)
+// regardless of the order of their keys.
+func equalBodies(a, b string) bool {
+	if a == b {
+		return true
+	}
+
+	var jsonA, jsonB interface{}
+	if json.Unmarshal([]byte(a), &jsonA) == nil && json.Unmarshal([]byte(b), &jsonB) == nil {
+		return reflect.DeepEqual(jsonA, jsonB)
+	}
+
+	formA, errA := url.ParseQuery(a)
+	formB, errB := url.ParseQuery(b)
+	return errA == nil && errB == nil && reflect.DeepEqual(formA, formB)
+}
+
+func (r FixtureRequest) Matches(other FixtureRequest) bool {
+	if r.Service != other.Service || r.Method != other.Method || r.Path != other.Path || r.Target != other.Target {
+		return false
+	}
+	if r.Region != "" && other.Region != "" && r.Region != other.Region {
+		return false
+	}
+
+	queryA, errA := url.ParseQuery(r.Query)
+	queryB, errB := url.ParseQuery(other.Query)
+	if errA != nil || errB != nil || !reflect.DeepEqual(queryA, queryB) {
+		return false
+	}
+
+	return equalBodies(r.Body, other.Body)
+}
+
+// Operation is a name for the request, used in the fixture filenames.
+func (r FixtureRequest) Operation() string {
+	if r.Target != "" {
+		parts := strings.Split(r.Target, ".")
+		return parts[len(parts)-1]
+	}
+
+	if form, err := url.ParseQuery(r.Body); err == nil && form.Get("Action") != "" {
+		return form.Get("Action")
+	}
+
+	parts := []string{r.Method}
+	for _, part := range strings.Split(r.Path, "/") {
+		if part == "" {
+			continue
+		}
+		parts = append(parts, strings.Map(func(c rune) rune {
+			if (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9') || c == '-' {
+				return c
+			}
+			return '_'
+		}, part))
+	}
+	return strings.Join(parts, "-")
+}
+
+// FixtureRecorder is an http.RoundTripper saving each request and response as
+// a fixture in Directory/<service>/<operation>-<n>.json.
+type FixtureRecorder struct {
+	Directory string
+	Transport http.RoundTripper
+
+	lock   sync.Mutex
+	counts map[string]int
+}
+
+func NewFixtureRecorder(directory string) *FixtureRecorder {
+	return &FixtureRecorder{
+		Directory: directory,
+		Transport: http.DefaultTransport,
+		counts:    map[string]int{},
+	}
+}
+
+func (r *FixtureRecorder) RoundTrip(request *http.Request) (*http.Response, error) {
+	body := []byte{}
+	if request.Body != nil {
+		var err error
+		body, err = ioutil.ReadAll(request.Body)
+		if err != nil {
+			return nil, err
+		}
+		request.Body.Close()
+		request.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+
+	response, err := r.Transport.RoundTrip(request)
+	if err != nil {
+		return nil, err
+	}
+
+	responseBody, err := ioutil.ReadAll(response.Body)
+	response.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	response.Body = ioutil.NopCloser(bytes.NewReader(responseBody))
+
+	fixture := Fixture{
+		Request: newFixtureRequest(request, body),
+		Response: FixtureResponse{
+			StatusCode: response.StatusCode,
+			Headers:    map[string]string{},
+			Body:       string(responseBody),
+		},
+	}
+	for key := range response.Header {
+		if !ignoredFixtureHeaders[key] {
+			fixture.Response.Headers[key] = response.Header.Get(key)
+		}
+	}
+
+	return response, r.save(fixture)
+}
+
+func (r *FixtureRecorder) save(fixture Fixture) error {
+	dir := filepath.Join(r.Directory, fixture.Request.Service)
+	operation := fixture.Request.Operation()
+
+	r.lock.Lock()
+	r.counts[dir+operation]++
+	filename := filepath.Join(dir, fmt.Sprintf("%s-%03d.json", operation, r.counts[dir+operation]))
+	r.lock.Unlock()
+
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(fixture, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filename, data, 0644)
+}
+
+// Record makes the API calls of the config go through the recorder.
+func (r *FixtureRecorder) Record(config *aws.Config) {
+	config.HTTPClient = &http.Client{Transport: r}
+	// the bucket is in the path when replaying
+	config.S3ForcePathStyle = aws.Bool(true)
+}
+
+// LoadFixtures loads the fixtures of dir and its subdirectories, in order of
+// their filenames.
+func LoadFixtures(dir string) ([]Fixture, error) {
+	filenames := []string{}
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() && strings.HasSuffix(path, ".json") {
+			filenames = append(filenames, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(filenames)
+
+	fixtures := []Fixture{}
+	for _, filename := range filenames {
+		data, err := ioutil.ReadFile(filename)
+		if err != nil {
+			return nil, err
+		}
+
+		fixture := Fixture{}
+		if err := json.Unmarshal(data, &fixture); err != nil {
+			return nil, fmt.Errorf("invalid fixture %s: %s", filename, err)
+		}
+		fixtures = append(fixtures, fixture)
+	}
+
+	return fixtures, nil
+}
+
+// NewFixtureServer replays the fixtures of dir. Requests without a fixture get
+// a 404 with the request in the body to help writing the missing fixture.
+func NewFixtureServer(dir string) (*httptest.Server, error) {
+	fixtures, err := LoadFixtures(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	handler := http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		body, err := ioutil.ReadAll(request.Body)
+		if err != nil {
+			http.Error(writer, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		fixtureRequest := newFixtureRequest(request, body)
+		for _, fixture := range fixtures {
+			if !fixture.Request.Matches(fixtureRequest) {
+				continue
+			}
+
+			for key, value := range fixture.Response.Headers {
+				writer.Header().Set(key, value)
+			}
+			writer.WriteHeader(fixture.Response.StatusCode)
+			writer.Write([]byte(fixture.Response.Body))
+			return
+		}
+
+		data, _ := json.Marshal(fixtureRequest)
+		http.Error(writer, fmt.Sprintf("no fixture for %s", data), http.StatusNotFound)
+	})
+
+	return httptest.NewServer(handler), nil
+}
+
+// NewFixtureSession returns a session sending all the API calls to endpoint,
+// usually a fixture server.
+func NewFixtureSession(endpoint, region, accountID string) (*Session, error) {
+	config := &aws.Config{
+		Region:                    aws.String(region),
+		Endpoint:                  aws.String(endpoint),
+		Credentials:               credentials.NewStaticCredentials("AKIAFIXTURE", "fixture", ""),
+		S3ForcePathStyle:          aws.Bool(true),
+		DisableEndpointHostPrefix: aws.Bool(true),
+		MaxRetries:                aws.Int(0),
+	}
+
+	sess, err := session.NewSession(config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Session{
+		Session:   sess,
+		Config:    config,
+		AccountID: accountID,
+	}, nil
+}
@@ -0,0 +1,41 @@
+package resources
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// newTestFixtureSession replays the fixtures of testdata/fixtures/<name>.
+func newTestFixtureSession(t *testing.T, name string) *Session {
+	server, err := NewFixtureServer("testdata/fixtures/" + name)
+	require.NoError(t, err)
+	t.Cleanup(server.Close)
+
+	session, err := NewFixtureSession(server.URL, "eu-west-1", "123456789012")
+	require.NoError(t, err)
+	return session
+}
+
+func TestFixtureRequestMatches(t *testing.T) {
+	t.Parallel()
+
+	recorded := FixtureRequest{
+		Service: "ec2",
+		Region:  "eu-west-1",
+		Method:  "POST",
+		Path:    "/",
+		Body:    "Action=DescribeVpcs&Version=2016-11-15",
+	}
+	require.True(t, recorded.Matches(FixtureRequest{Service: "ec2", Region: "eu-west-1", Method: "POST", Path: "/", Body: "Version=2016-11-15&Action=DescribeVpcs"}))
+	require.False(t, recorded.Matches(FixtureRequest{Service: "ec2", Region: "us-east-1", Method: "POST", Path: "/", Body: "Action=DescribeVpcs&Version=2016-11-15"}))
+	require.False(t, recorded.Matches(FixtureRequest{Service: "rds", Region: "eu-west-1", Method: "POST", Path: "/", Body: "Action=DescribeVpcs&Version=2016-11-15"}))
+	require.Equal(t, "DescribeVpcs", recorded.Operation())
+
+	anyRegion := FixtureRequest{Service: "kinesis", Method: "POST", Path: "/", Target: "Kinesis_20131202.ListStreams", Body: `{"Limit":10,"ExclusiveStartStreamName":"a"}`}
+	require.True(t, anyRegion.Matches(FixtureRequest{Service: "kinesis", Region: "eu-west-1", Method: "POST", Path: "/", Target: "Kinesis_20131202.ListStreams", Body: `{"ExclusiveStartStreamName":"a","Limit":10}`}))
+	require.False(t, anyRegion.Matches(FixtureRequest{Service: "kinesis", Method: "POST", Path: "/", Target: "Kinesis_20131202.ListStreams", Body: `{"Limit":10}`}))
+	require.Equal(t, "ListStreams", anyRegion.Operation())
+
+	require.Equal(t, "GET-2015-03-31-functions", FixtureRequest{Method: "GET", Path: "/2015-03-31/functions/"}.Operation())
+}
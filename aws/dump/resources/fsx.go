@@ -0,0 +1,115 @@
+package resources
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/fsx"
+	"github.com/fatih/structs"
+)
+
+var (
+	FSxService = Service{
+		Name: "fsx",
+		Reports: map[string]Report{
+			"file-systems": FSxListFileSystems,
+			"backups":      FSxListBackups,
+		},
+	}
+)
+
+func FSxListFileSystems(session *Session) *ReportResult {
+	client := fsx.New(session.Session, session.Config)
+
+	result := &ReportResult{}
+	result.Error = client.DescribeFileSystemsPages(&fsx.DescribeFileSystemsInput{},
+		func(page *fsx.DescribeFileSystemsOutput, lastPage bool) bool {
+			for _, fileSystem := range page.FileSystems {
+				resource := Resource{
+					ID:        *fileSystem.FileSystemId,
+					ARN:       *fileSystem.ResourceARN,
+					AccountID: session.AccountID,
+					Service:   "fsx",
+					Type:      "file-system",
+					Region:    *session.Config.Region,
+					Metadata:  structs.Map(fileSystem),
+				}
+				resource.Metadata["Summary"] = fsxFileSystemSummary(fileSystem)
+				result.Resources = append(result.Resources, resource)
+			}
+
+			return true
+		})
+
+	return result
+}
+
+// fsxFileSystemSummary returns the capacity, throughput, backup and maintenance
+// settings which live in a different configuration for each file system type.
+func fsxFileSystemSummary(fileSystem *fsx.FileSystem) map[string]interface{} {
+	summary := map[string]interface{}{
+		"FileSystemType":  aws.StringValue(fileSystem.FileSystemType),
+		"StorageCapacity": aws.Int64Value(fileSystem.StorageCapacity),
+		"StorageType":     aws.StringValue(fileSystem.StorageType),
+	}
+
+	switch {
+	case fileSystem.WindowsConfiguration != nil:
+		config := fileSystem.WindowsConfiguration
+		summary["ThroughputCapacity"] = aws.Int64Value(config.ThroughputCapacity)
+		summary["AutomaticBackupRetentionDays"] = aws.Int64Value(config.AutomaticBackupRetentionDays)
+		summary["DailyAutomaticBackupStartTime"] = aws.StringValue(config.DailyAutomaticBackupStartTime)
+		summary["WeeklyMaintenanceStartTime"] = aws.StringValue(config.WeeklyMaintenanceStartTime)
+	case fileSystem.LustreConfiguration != nil:
+		config := fileSystem.LustreConfiguration
+		summary["PerUnitStorageThroughput"] = aws.Int64Value(config.PerUnitStorageThroughput)
+		summary["AutomaticBackupRetentionDays"] = aws.Int64Value(config.AutomaticBackupRetentionDays)
+		summary["DailyAutomaticBackupStartTime"] = aws.StringValue(config.DailyAutomaticBackupStartTime)
+		summary["WeeklyMaintenanceStartTime"] = aws.StringValue(config.WeeklyMaintenanceStartTime)
+	case fileSystem.OntapConfiguration != nil:
+		config := fileSystem.OntapConfiguration
+		summary["ThroughputCapacity"] = aws.Int64Value(config.ThroughputCapacity)
+		summary["AutomaticBackupRetentionDays"] = aws.Int64Value(config.AutomaticBackupRetentionDays)
+		summary["DailyAutomaticBackupStartTime"] = aws.StringValue(config.DailyAutomaticBackupStartTime)
+		summary["WeeklyMaintenanceStartTime"] = aws.StringValue(config.WeeklyMaintenanceStartTime)
+	case fileSystem.OpenZFSConfiguration != nil:
+		config := fileSystem.OpenZFSConfiguration
+		summary["ThroughputCapacity"] = aws.Int64Value(config.ThroughputCapacity)
+		summary["AutomaticBackupRetentionDays"] = aws.Int64Value(config.AutomaticBackupRetentionDays)
+		summary["DailyAutomaticBackupStartTime"] = aws.StringValue(config.DailyAutomaticBackupStartTime)
+		summary["WeeklyMaintenanceStartTime"] = aws.StringValue(config.WeeklyMaintenanceStartTime)
+	}
+
+	return summary
+}
+
+func FSxListBackups(session *Session) *ReportResult {
+	client := fsx.New(session.Session, session.Config)
+
+	result := &ReportResult{}
+	result.Error = client.DescribeBackupsPages(&fsx.DescribeBackupsInput{},
+		func(page *fsx.DescribeBackupsOutput, lastPage bool) bool {
+			for _, backup := range page.Backups {
+				fileSystemID := ""
+				if backup.FileSystem != nil {
+					fileSystemID = aws.StringValue(backup.FileSystem.FileSystemId)
+				}
+				// the full file system description is already in fsx:file-systems
+				backup.FileSystem = nil
+
+				resource := Resource{
+					ID:        *backup.BackupId,
+					ARN:       aws.StringValue(backup.ResourceARN),
+					AccountID: session.AccountID,
+					Service:   "fsx",
+					Type:      "backup",
+					Region:    *session.Config.Region,
+					Metadata:  structs.Map(backup),
+				}
+				resource.Metadata["FileSystemId"] = fileSystemID
+				result.Resources = append(result.Resources, resource)
+			}
+
+			return true
+		})
+
+	return result
+}
@@ -0,0 +1,267 @@
+package resources
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/globalaccelerator"
+	"github.com/fatih/structs"
+)
+
+var (
+	GlobalAcceleratorService = Service{
+		Name:     "globalaccelerator",
+		IsGlobal: true,
+		Reports: map[string]Report{
+			"accelerators":                GlobalAcceleratorListAccelerators,
+			"custom-routing-accelerators": GlobalAcceleratorListCustomRoutingAccelerators,
+			"byoip-cidrs":                 GlobalAcceleratorListByoipCidrs,
+		},
+	}
+)
+
+// The Global Accelerator API is only available in us-west-2
+func newGlobalAcceleratorClient(session *Session) *globalaccelerator.GlobalAccelerator {
+	return globalaccelerator.New(session.Session, session.Config.Copy(&aws.Config{Region: aws.String("us-west-2")}))
+}
+
+func newGlobalAcceleratorResource(session *Session, arn, resourceType string, metadata interface{}) Resource {
+	return Resource{
+		ID:        arn,
+		ARN:       arn,
+		AccountID: session.AccountID,
+		Service:   "globalaccelerator",
+		Type:      resourceType,
+		Metadata:  structs.Map(metadata),
+	}
+}
+
+func GlobalAcceleratorListAccelerators(session *Session) *ReportResult {
+	client := newGlobalAcceleratorClient(session)
+
+	result := &ReportResult{}
+	input := &globalaccelerator.ListAcceleratorsInput{}
+	for {
+		page, err := client.ListAccelerators(input)
+		if err != nil {
+			result.Error = err
+			return result
+		}
+
+		for _, accelerator := range page.Accelerators {
+			result.Resources = append(result.Resources, newGlobalAcceleratorResource(session, *accelerator.AcceleratorArn, "accelerator", accelerator))
+
+			listeners := GlobalAcceleratorListListeners(session, client, *accelerator.AcceleratorArn)
+			if listeners.Error != nil {
+				result.Error = listeners.Error
+				return result
+			}
+			result.Resources = append(result.Resources, listeners.Resources...)
+		}
+
+		if page.NextToken == nil {
+			break
+		}
+		input.NextToken = page.NextToken
+	}
+
+	return result
+}
+
+func GlobalAcceleratorListListeners(session *Session, client *globalaccelerator.GlobalAccelerator, acceleratorARN string) *ReportResult {
+	result := &ReportResult{}
+	input := &globalaccelerator.ListListenersInput{AcceleratorArn: aws.String(acceleratorARN)}
+	for {
+		page, err := client.ListListeners(input)
+		if err != nil {
+			result.Error = err
+			return result
+		}
+
+		for _, listener := range page.Listeners {
+			resource := newGlobalAcceleratorResource(session, *listener.ListenerArn, "listener", listener)
+			resource.Metadata["AcceleratorArn"] = acceleratorARN
+			result.Resources = append(result.Resources, resource)
+
+			endpointGroups := GlobalAcceleratorListEndpointGroups(session, client, *listener.ListenerArn)
+			if endpointGroups.Error != nil {
+				result.Error = endpointGroups.Error
+				return result
+			}
+			result.Resources = append(result.Resources, endpointGroups.Resources...)
+		}
+
+		if page.NextToken == nil {
+			break
+		}
+		input.NextToken = page.NextToken
+	}
+
+	return result
+}
+
+func GlobalAcceleratorListEndpointGroups(session *Session, client *globalaccelerator.GlobalAccelerator, listenerARN string) *ReportResult {
+	result := &ReportResult{}
+	input := &globalaccelerator.ListEndpointGroupsInput{ListenerArn: aws.String(listenerARN)}
+	for {
+		page, err := client.ListEndpointGroups(input)
+		if err != nil {
+			result.Error = err
+			return result
+		}
+
+		for _, endpointGroup := range page.EndpointGroups {
+			resource := newGlobalAcceleratorResource(session, *endpointGroup.EndpointGroupArn, "endpoint-group", endpointGroup)
+			resource.Metadata["ListenerArn"] = listenerARN
+			resource.Metadata["EndpointHealth"] = globalAcceleratorEndpointHealth(endpointGroup.EndpointDescriptions)
+			result.Resources = append(result.Resources, resource)
+		}
+
+		if page.NextToken == nil {
+			break
+		}
+		input.NextToken = page.NextToken
+	}
+
+	return result
+}
+
+// globalAcceleratorEndpointHealth counts the endpoints of an endpoint group by
+// health state and lists the unhealthy ones with the reason.
+func globalAcceleratorEndpointHealth(endpoints []*globalaccelerator.EndpointDescription) map[string]interface{} {
+	states := map[string]int{}
+	unhealthy := []map[string]string{}
+	for _, endpoint := range endpoints {
+		state := aws.StringValue(endpoint.HealthState)
+		states[state]++
+		if state == globalaccelerator.HealthStateUnhealthy {
+			unhealthy = append(unhealthy, map[string]string{
+				"EndpointId":   aws.StringValue(endpoint.EndpointId),
+				"HealthReason": aws.StringValue(endpoint.HealthReason),
+			})
+		}
+	}
+
+	return map[string]interface{}{
+		"States":             states,
+		"UnhealthyEndpoints": unhealthy,
+	}
+}
+
+func GlobalAcceleratorListCustomRoutingAccelerators(session *Session) *ReportResult {
+	client := newGlobalAcceleratorClient(session)
+
+	result := &ReportResult{}
+	input := &globalaccelerator.ListCustomRoutingAcceleratorsInput{}
+	for {
+		page, err := client.ListCustomRoutingAccelerators(input)
+		if err != nil {
+			result.Error = err
+			return result
+		}
+
+		for _, accelerator := range page.Accelerators {
+			result.Resources = append(result.Resources, newGlobalAcceleratorResource(session, *accelerator.AcceleratorArn, "custom-routing-accelerator", accelerator))
+
+			listeners := GlobalAcceleratorListCustomRoutingListeners(session, client, *accelerator.AcceleratorArn)
+			if listeners.Error != nil {
+				result.Error = listeners.Error
+				return result
+			}
+			result.Resources = append(result.Resources, listeners.Resources...)
+		}
+
+		if page.NextToken == nil {
+			break
+		}
+		input.NextToken = page.NextToken
+	}
+
+	return result
+}
+
+func GlobalAcceleratorListCustomRoutingListeners(session *Session, client *globalaccelerator.GlobalAccelerator, acceleratorARN string) *ReportResult {
+	result := &ReportResult{}
+	input := &globalaccelerator.ListCustomRoutingListenersInput{AcceleratorArn: aws.String(acceleratorARN)}
+	for {
+		page, err := client.ListCustomRoutingListeners(input)
+		if err != nil {
+			result.Error = err
+			return result
+		}
+
+		for _, listener := range page.Listeners {
+			resource := newGlobalAcceleratorResource(session, *listener.ListenerArn, "custom-routing-listener", listener)
+			resource.Metadata["AcceleratorArn"] = acceleratorARN
+			result.Resources = append(result.Resources, resource)
+
+			endpointGroups := GlobalAcceleratorListCustomRoutingEndpointGroups(session, client, *listener.ListenerArn)
+			if endpointGroups.Error != nil {
+				result.Error = endpointGroups.Error
+				return result
+			}
+			result.Resources = append(result.Resources, endpointGroups.Resources...)
+		}
+
+		if page.NextToken == nil {
+			break
+		}
+		input.NextToken = page.NextToken
+	}
+
+	return result
+}
+
+func GlobalAcceleratorListCustomRoutingEndpointGroups(session *Session, client *globalaccelerator.GlobalAccelerator, listenerARN string) *ReportResult {
+	result := &ReportResult{}
+	input := &globalaccelerator.ListCustomRoutingEndpointGroupsInput{ListenerArn: aws.String(listenerARN)}
+	for {
+		page, err := client.ListCustomRoutingEndpointGroups(input)
+		if err != nil {
+			result.Error = err
+			return result
+		}
+
+		for _, endpointGroup := range page.EndpointGroups {
+			resource := newGlobalAcceleratorResource(session, *endpointGroup.EndpointGroupArn, "custom-routing-endpoint-group", endpointGroup)
+			resource.Metadata["ListenerArn"] = listenerARN
+			result.Resources = append(result.Resources, resource)
+		}
+
+		if page.NextToken == nil {
+			break
+		}
+		input.NextToken = page.NextToken
+	}
+
+	return result
+}
+
+func GlobalAcceleratorListByoipCidrs(session *Session) *ReportResult {
+	client := newGlobalAcceleratorClient(session)
+
+	result := &ReportResult{}
+	input := &globalaccelerator.ListByoipCidrsInput{}
+	for {
+		page, err := client.ListByoipCidrs(input)
+		if err != nil {
+			result.Error = err
+			return result
+		}
+
+		for _, byoipCidr := range page.ByoipCidrs {
+			result.Resources = append(result.Resources, Resource{
+				ID:        *byoipCidr.Cidr,
+				AccountID: session.AccountID,
+				Service:   "globalaccelerator",
+				Type:      "byoip-cidr",
+				Metadata:  structs.Map(byoipCidr),
+			})
+		}
+
+		if page.NextToken == nil {
+			break
+		}
+		input.NextToken = page.NextToken
+	}
+
+	return result
+}
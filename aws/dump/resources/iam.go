@@ -20,6 +20,14 @@ var (
 			"groups":                        IAMListGroups,
 			"instance-profiles":             IAMListInstanceProfiles,
 			"account-authorization-details": IAMListAccountAuthorizationDetails,
+			"saml-providers":                IAMListSAMLProviders,
+			"oidc-providers":                IAMListOpenIDConnectProviders,
+			"server-certificates":           IAMListServerCertificates,
+			"virtual-mfa-devices":           IAMListVirtualMFADevices,
+			"account-password-policy":       IAMGetAccountPasswordPolicy,
+			"account-summary":               IAMGetAccountSummary,
+			"credential-report":             IAMGetCredentialReport,
+			"root-account":                  IAMGetRootAccount,
 		},
 	}
 )
@@ -27,8 +35,13 @@ var (
 type PolicyFetchFunc func(*Session, *iam.IAM, string, string) *ReportResult
 
 func IAMListUserAttachedPolicies(session *Session, client *iam.IAM, userARN, userName string) *ReportResult {
+	registry, err := getIAMPolicyRegistry(session, client)
+	if err != nil {
+		return &ReportResult{nil, err}
+	}
+
 	result := &ReportResult{}
-	err := client.ListAttachedUserPoliciesPages(&iam.ListAttachedUserPoliciesInput{UserName: aws.String(userName)},
+	err = client.ListAttachedUserPoliciesPages(&iam.ListAttachedUserPoliciesInput{UserName: aws.String(userName)},
 		func(page *iam.ListAttachedUserPoliciesOutput, lastPage bool) bool {
 			for _, policy := range page.AttachedPolicies {
 				r := Resource{
@@ -41,6 +54,7 @@ func IAMListUserAttachedPolicies(session *Session, client *iam.IAM, userARN, use
 					Metadata:  structs.Map(policy),
 				}
 				r.Metadata["UserArn"] = userARN
+				r.Metadata["Policy"] = registry.reference(*policy.PolicyArn)
 				result.Resources = append(result.Resources, r)
 			}
 			return true
@@ -133,15 +147,20 @@ func IAMListUsersAndAccessKeys(session *Session) *ReportResult {
 		result.Error = err
 		return result
 	}
-	AttachServiceLastAccessedDetails(client, result, jobIds)
+	AttachServiceLastAccessedDetails(client, result, arns, jobIds)
 
 	result.Resources = append(result.Resources, accessKeys...)
 	return result
 }
 
 func IAMListGroupAttachedPolicies(session *Session, client *iam.IAM, groupARN, groupName string) *ReportResult {
+	registry, err := getIAMPolicyRegistry(session, client)
+	if err != nil {
+		return &ReportResult{nil, err}
+	}
+
 	result := &ReportResult{}
-	err := client.ListAttachedGroupPoliciesPages(&iam.ListAttachedGroupPoliciesInput{GroupName: aws.String(groupName)},
+	err = client.ListAttachedGroupPoliciesPages(&iam.ListAttachedGroupPoliciesInput{GroupName: aws.String(groupName)},
 		func(page *iam.ListAttachedGroupPoliciesOutput, lastPage bool) bool {
 			for _, policy := range page.AttachedPolicies {
 				r := Resource{
@@ -154,6 +173,7 @@ func IAMListGroupAttachedPolicies(session *Session, client *iam.IAM, groupARN, g
 					Metadata:  structs.Map(policy),
 				}
 				r.Metadata["GroupArn"] = groupARN
+				r.Metadata["Policy"] = registry.reference(*policy.PolicyArn)
 				result.Resources = append(result.Resources, r)
 			}
 			return true
@@ -239,7 +259,7 @@ func IAMListGroups(session *Session) *ReportResult {
 		result.Error = err
 		return result
 	}
-	AttachServiceLastAccessedDetails(client, result, jobIds)
+	AttachServiceLastAccessedDetails(client, result, arns, jobIds)
 
 	return result
 }
@@ -365,8 +385,13 @@ func IAMListAccountAuthorizationDetails(session *Session) *ReportResult {
 }
 
 func IAMListRoleAttachedPolicies(session *Session, client *iam.IAM, roleARN, roleName string) *ReportResult {
+	registry, err := getIAMPolicyRegistry(session, client)
+	if err != nil {
+		return &ReportResult{nil, err}
+	}
+
 	result := &ReportResult{}
-	err := client.ListAttachedRolePoliciesPages(&iam.ListAttachedRolePoliciesInput{RoleName: aws.String(roleName)},
+	err = client.ListAttachedRolePoliciesPages(&iam.ListAttachedRolePoliciesInput{RoleName: aws.String(roleName)},
 		func(page *iam.ListAttachedRolePoliciesOutput, lastPage bool) bool {
 			for _, policy := range page.AttachedPolicies {
 				r := Resource{
@@ -379,6 +404,7 @@ func IAMListRoleAttachedPolicies(session *Session, client *iam.IAM, roleARN, rol
 					Metadata:  structs.Map(policy),
 				}
 				r.Metadata["RoleArn"] = roleARN
+				r.Metadata["Policy"] = registry.reference(*policy.PolicyArn)
 				result.Resources = append(result.Resources, r)
 			}
 			return true
@@ -450,13 +476,6 @@ func IAMListRoles(session *Session) *ReportResult {
 				arns = append(arns, role.Arn)
 				result.Resources = append(result.Resources, *resource)
 
-				policies := IAMListRolePolicies(session, client, *role.Arn, *role.RoleName)
-				if policies.Error != nil {
-					result.Error = policies.Error
-					return false
-				}
-				result.Resources = append(result.Resources, policies.Resources...)
-
 				for _, fn := range policiesFunctions {
 					policies := fn(session, client, *role.Arn, *role.RoleName)
 					if policies.Error != nil {
@@ -479,7 +498,7 @@ func IAMListRoles(session *Session) *ReportResult {
 		result.Error = err
 		return result
 	}
-	AttachServiceLastAccessedDetails(client, result, jobIds)
+	AttachServiceLastAccessedDetails(client, result, arns, jobIds)
 
 	return result
 }
@@ -489,6 +508,18 @@ func IAMListPolicyVersions(session *Session, client *iam.IAM, policyArn string)
 	err := client.ListPolicyVersionsPages(&iam.ListPolicyVersionsInput{PolicyArn: aws.String(policyArn)},
 		func(page *iam.ListPolicyVersionsOutput, lastPage bool) bool {
 			for _, resource := range page.Versions {
+				// the versions can't be modified, only which one is the default
+				previous, ok := Previous.Get(fmt.Sprintf("%s:%s", policyArn, *resource.VersionId))
+				if ok && previous.Metadata != nil {
+					metadata := map[string]interface{}{}
+					for key, value := range previous.Metadata {
+						metadata[key] = value
+					}
+					metadata["IsDefaultVersion"] = aws.BoolValue(resource.IsDefaultVersion)
+					previous.Metadata = metadata
+					result.Resources = append(result.Resources, Previous.reuse(previous)...)
+					continue
+				}
 
 				policyVersion, err := client.GetPolicyVersion(&iam.GetPolicyVersionInput{PolicyArn: aws.String(policyArn), VersionId: resource.VersionId})
 				if err != nil {
@@ -496,26 +527,12 @@ func IAMListPolicyVersions(session *Session, client *iam.IAM, policyArn string)
 					return false
 				}
 
-				document, err := DecodeInlinePolicyDocument(*policyVersion.PolicyVersion.Document)
+				r, err := newIAMPolicyVersionResource(session, policyArn, policyVersion.PolicyVersion)
 				if err != nil {
 					result.Error = err
 					return false
 				}
-
-				metadata := structs.Map(policyVersion.PolicyVersion)
-				metadata["Document"] = document
-
-				arn := fmt.Sprintf("%s:%s", policyArn, *resource.VersionId)
-				r := Resource{
-					ID:        arn,
-					ARN:       arn,
-					AccountID: session.AccountID,
-					Service:   "iam",
-					Type:      "policy-version",
-					Region:    *session.Config.Region,
-					Metadata:  metadata,
-				}
-				result.Resources = append(result.Resources, r)
+				result.Resources = append(result.Resources, *r)
 			}
 			return true
 		})
@@ -560,12 +577,21 @@ func IAMListPolicies(session *Session) *ReportResult {
 		return result
 	}
 
+	if IAMIncludeAWSManagedPolicies {
+		awsManaged := IAMListAWSManagedPolicies(session, client)
+		if awsManaged.Error != nil {
+			result.Error = awsManaged.Error
+			return result
+		}
+		result.Resources = append(result.Resources, awsManaged.Resources...)
+	}
+
 	jobIds, err := GenerateServiceLastAccessedDetails(client, arns)
 	if err != nil {
 		result.Error = err
 		return result
 	}
-	AttachServiceLastAccessedDetails(client, result, jobIds)
+	AttachServiceLastAccessedDetails(client, result, arns, jobIds)
 	return result
 }
 
@@ -614,7 +640,14 @@ func GenerateServiceLastAccessedDetails(client *iam.IAM, arns []*string) ([]*str
 	return jobIds, nil
 }
 
-func AttachServiceLastAccessedDetails(client *iam.IAM, result *ReportResult, jobIds []*string) {
+// AttachServiceLastAccessedDetails adds the result of the job of arns[i] from
+// jobIds[i] to the resource with that ARN.
+func AttachServiceLastAccessedDetails(client *iam.IAM, result *ReportResult, arns []*string, jobIds []*string) {
+	resourcesByARN := map[string]*Resource{}
+	for i := range result.Resources {
+		resourcesByARN[result.Resources[i].ARN] = &result.Resources[i]
+	}
+
 	for i := 0; i < len(jobIds); {
 		jobId := jobIds[i]
 		lastUsed, err := client.GetServiceLastAccessedDetails(&iam.GetServiceLastAccessedDetailsInput{JobId: jobId})
@@ -626,8 +659,9 @@ func AttachServiceLastAccessedDetails(client *iam.IAM, result *ReportResult, job
 			time.Sleep(1 * time.Second)
 			continue
 		}
-		if *lastUsed.JobStatus == "COMPLETED" {
-			result.Resources[i].Metadata["ServiceLastAccessed"] = lastUsed.ServicesLastAccessed
+		resource, ok := resourcesByARN[*arns[i]]
+		if *lastUsed.JobStatus == "COMPLETED" && ok {
+			resource.Metadata["ServiceLastAccessed"] = lastUsed.ServicesLastAccessed
 			var lastUsedAt *time.Time
 			for _, serviceLastAccessed := range lastUsed.ServicesLastAccessed {
 				if serviceLastAccessed.LastAuthenticated == nil {
@@ -637,7 +671,7 @@ func AttachServiceLastAccessedDetails(client *iam.IAM, result *ReportResult, job
 					lastUsedAt = serviceLastAccessed.LastAuthenticated
 				}
 			}
-			result.Resources[i].Metadata["LastUsed"] = lastUsedAt
+			resource.Metadata["LastUsed"] = lastUsedAt
 
 		}
 		i++
@@ -685,3 +719,140 @@ func IAMListInstanceProfiles(session *Session) *ReportResult {
 	result.Error = err
 	return result
 }
+
+func newIAMResource(session *Session, id, arn, resourceType string, metadata map[string]interface{}) Resource {
+	return Resource{
+		ID:        id,
+		ARN:       arn,
+		AccountID: session.AccountID,
+		Service:   "iam",
+		Type:      resourceType,
+		Region:    *session.Config.Region,
+		Metadata:  metadata,
+	}
+}
+
+// IAMListSAMLProviders lists the SAML identity providers without their metadata document.
+func IAMListSAMLProviders(session *Session) *ReportResult {
+	client := iam.New(session.Session, session.Config)
+
+	res, err := client.ListSAMLProviders(&iam.ListSAMLProvidersInput{})
+	if err != nil {
+		return &ReportResult{nil, err}
+	}
+
+	result := &ReportResult{}
+	now := time.Now().UTC()
+	for _, provider := range res.SAMLProviderList {
+		resource := newIAMResource(session, *provider.Arn, *provider.Arn, "saml-provider", structs.Map(provider))
+		if provider.ValidUntil != nil {
+			resource.Metadata["Expired"] = provider.ValidUntil.Before(now)
+		}
+		result.Resources = append(result.Resources, resource)
+	}
+	return result
+}
+
+func IAMListOpenIDConnectProviders(session *Session) *ReportResult {
+	client := iam.New(session.Session, session.Config)
+
+	res, err := client.ListOpenIDConnectProviders(&iam.ListOpenIDConnectProvidersInput{})
+	if err != nil {
+		return &ReportResult{nil, err}
+	}
+
+	result := &ReportResult{}
+	for _, provider := range res.OpenIDConnectProviderList {
+		// includes the URL, client ids and thumbprints
+		described, err := client.GetOpenIDConnectProvider(&iam.GetOpenIDConnectProviderInput{OpenIDConnectProviderArn: provider.Arn})
+		if err != nil {
+			result.Error = err
+			return result
+		}
+		result.Resources = append(result.Resources, newIAMResource(session, *provider.Arn, *provider.Arn, "oidc-provider", structs.Map(described)))
+	}
+	return result
+}
+
+func IAMListServerCertificates(session *Session) *ReportResult {
+	client := iam.New(session.Session, session.Config)
+
+	result := &ReportResult{}
+	now := time.Now().UTC()
+	err := client.ListServerCertificatesPages(&iam.ListServerCertificatesInput{},
+		func(page *iam.ListServerCertificatesOutput, lastPage bool) bool {
+			for _, certificate := range page.ServerCertificateMetadataList {
+				resource := newIAMResource(session, *certificate.ServerCertificateId, *certificate.Arn, "server-certificate", structs.Map(certificate))
+				if certificate.Expiration != nil {
+					resource.Metadata["Expired"] = certificate.Expiration.Before(now)
+				}
+				result.Resources = append(result.Resources, resource)
+			}
+			return true
+		})
+
+	result.Error = err
+	return result
+}
+
+// IAMListVirtualMFADevices lists the virtual MFA devices without their seed.
+func IAMListVirtualMFADevices(session *Session) *ReportResult {
+	client := iam.New(session.Session, session.Config)
+
+	result := &ReportResult{}
+	err := client.ListVirtualMFADevicesPages(&iam.ListVirtualMFADevicesInput{},
+		func(page *iam.ListVirtualMFADevicesOutput, lastPage bool) bool {
+			for _, device := range page.VirtualMFADevices {
+				device.Base32StringSeed = nil
+				device.QRCodePNG = nil
+
+				resource := newIAMResource(session, *device.SerialNumber, *device.SerialNumber, "virtual-mfa-device", structs.Map(device))
+				// unassigned devices have no user
+				resource.Metadata["Assigned"] = device.User != nil
+				result.Resources = append(result.Resources, resource)
+			}
+			return true
+		})
+
+	result.Error = err
+	return result
+}
+
+// IAMGetAccountPasswordPolicy returns the password policy of the account,
+// Configured is false when the account uses the default policy.
+func IAMGetAccountPasswordPolicy(session *Session) *ReportResult {
+	client := iam.New(session.Session, session.Config)
+
+	metadata := map[string]interface{}{"Configured": false}
+	res, err := client.GetAccountPasswordPolicy(&iam.GetAccountPasswordPolicyInput{})
+	if err != nil {
+		if !IsErrorCode(err, iam.ErrCodeNoSuchEntityException) {
+			return &ReportResult{nil, err}
+		}
+	} else {
+		metadata = structs.Map(res.PasswordPolicy)
+		metadata["Configured"] = true
+	}
+
+	return &ReportResult{
+		Resources: []Resource{newIAMResource(session, session.AccountID, "", "account-password-policy", metadata)},
+	}
+}
+
+func IAMGetAccountSummary(session *Session) *ReportResult {
+	client := iam.New(session.Session, session.Config)
+
+	res, err := client.GetAccountSummary(&iam.GetAccountSummaryInput{})
+	if err != nil {
+		return &ReportResult{nil, err}
+	}
+
+	metadata := map[string]interface{}{}
+	for key, value := range res.SummaryMap {
+		metadata[key] = aws.Int64Value(value)
+	}
+
+	return &ReportResult{
+		Resources: []Resource{newIAMResource(session, session.AccountID, "", "account-summary", metadata)},
+	}
+}
@@ -0,0 +1,128 @@
+package resources
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/iam"
+)
+
+const (
+	credentialReportPollInterval = 2 * time.Second
+	credentialReportMaxAttempts  = 30
+)
+
+// IAMGetCredentialReport returns one resource per user (and the root user) from
+// the credential report, with the password, access keys, certificates and MFA
+// columns. It is much cheaper than listing the access keys of every user.
+func IAMGetCredentialReport(session *Session) *ReportResult {
+	client := iam.New(session.Session, session.Config)
+
+	content, err := iamGetCredentialReport(client)
+	if err != nil {
+		return &ReportResult{nil, err}
+	}
+
+	rows, err := parseCredentialReport(content)
+	if err != nil {
+		return &ReportResult{nil, err}
+	}
+
+	result := &ReportResult{}
+	for _, row := range rows {
+		result.Resources = append(result.Resources, Resource{
+			ID:        row["User"].(string),
+			ARN:       row["Arn"].(string),
+			AccountID: session.AccountID,
+			Service:   "iam",
+			Type:      "credential-report-user",
+			Region:    *session.Config.Region,
+			Metadata:  row,
+		})
+	}
+	return result
+}
+
+func iamGetCredentialReport(client *iam.IAM) ([]byte, error) {
+	for attempt := 0; attempt < credentialReportMaxAttempts; attempt++ {
+		res, err := client.GenerateCredentialReport(&iam.GenerateCredentialReportInput{})
+		if err != nil {
+			return nil, err
+		}
+
+		if aws.StringValue(res.State) == iam.ReportStateTypeComplete {
+			report, err := client.GetCredentialReport(&iam.GetCredentialReportInput{})
+			if err != nil {
+				return nil, err
+			}
+			return report.Content, nil
+		}
+
+		time.Sleep(credentialReportPollInterval)
+	}
+	return nil, errors.New("credential report not ready")
+}
+
+// parseCredentialReport returns the rows of the credential report with the
+// columns in CamelCase (e.g. password_last_used is PasswordLastUsed), booleans
+// and dates parsed and nil for the N/A, no_information and not_supported values.
+func parseCredentialReport(content []byte) ([]map[string]interface{}, error) {
+	records, err := csv.NewReader(bytes.NewReader(content)).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 || len(records[0]) < 2 || records[0][0] != "user" || records[0][1] != "arn" {
+		return nil, errors.New("invalid credential report header")
+	}
+
+	columns := []string{}
+	for _, column := range records[0] {
+		columns = append(columns, credentialReportColumn(column))
+	}
+
+	rows := []map[string]interface{}{}
+	for _, record := range records[1:] {
+		row := map[string]interface{}{}
+		for i, value := range record {
+			if i >= len(columns) {
+				break
+			}
+			row[columns[i]] = credentialReportValue(value)
+		}
+		// user and arn are always strings
+		row["User"] = record[0]
+		row["Arn"] = record[1]
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+func credentialReportColumn(column string) string {
+	parts := strings.Split(column, "_")
+	for i, part := range parts {
+		if part != "" {
+			parts[i] = strings.ToUpper(part[:1]) + part[1:]
+		}
+	}
+	return strings.Join(parts, "")
+}
+
+func credentialReportValue(value string) interface{} {
+	switch value {
+	case "N/A", "no_information", "not_supported":
+		return nil
+	case "true":
+		return true
+	case "false":
+		return false
+	}
+
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t
+	}
+	return value
+}
@@ -0,0 +1,31 @@
+package resources
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCredentialReport(t *testing.T) {
+	rows, err := parseCredentialReport([]byte(`user,arn,user_creation_time,password_enabled,password_last_used,mfa_active,access_key_1_active,access_key_1_last_used_date,access_key_1_last_used_region
+<root_account>,arn:aws:iam::123456789012:root,2020-01-01T00:00:00+00:00,not_supported,2021-01-10T08:00:00+00:00,true,false,N/A,N/A
+alice,arn:aws:iam::123456789012:user/alice,2020-01-01T00:00:00+00:00,true,no_information,false,true,2021-01-10T08:00:00+00:00,eu-west-1
+`))
+	require.NoError(t, err)
+	require.Len(t, rows, 2)
+
+	require.Equal(t, "<root_account>", rows[0]["User"])
+	require.Nil(t, rows[0]["PasswordEnabled"])
+	require.Equal(t, true, rows[0]["MfaActive"])
+
+	require.Equal(t, "arn:aws:iam::123456789012:user/alice", rows[1]["Arn"])
+	require.Equal(t, true, rows[1]["PasswordEnabled"])
+	require.Nil(t, rows[1]["PasswordLastUsed"])
+	require.Equal(t, false, rows[1]["MfaActive"])
+	require.Equal(t, time.Date(2021, 1, 10, 8, 0, 0, 0, time.UTC), rows[1]["AccessKey1LastUsedDate"].(time.Time).UTC())
+	require.Equal(t, "eu-west-1", rows[1]["AccessKey1LastUsedRegion"])
+
+	_, err = parseCredentialReport([]byte("a,b\n"))
+	require.Error(t, err)
+}
@@ -0,0 +1,141 @@
+package resources
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/fatih/structs"
+)
+
+var (
+	// IAMIncludeAWSManagedPolicies adds the AWS managed policies attached to a
+	// user, group or role to iam:policies.
+	IAMIncludeAWSManagedPolicies = false
+
+	iamPolicyRegistriesMutex sync.Mutex
+	iamPolicyRegistries      = map[*Session]*iamPolicyRegistry{}
+)
+
+// iamPolicyRegistry holds the attached managed policies of an account. It is
+// listed once per session and shared by the users, groups, roles and policies
+// reports instead of fetching the policy for every attachment.
+type iamPolicyRegistry struct {
+	once     sync.Once
+	policies map[string]*iam.Policy
+	err      error
+}
+
+func getIAMPolicyRegistry(session *Session, client *iam.IAM) (*iamPolicyRegistry, error) {
+	iamPolicyRegistriesMutex.Lock()
+	registry, ok := iamPolicyRegistries[session]
+	if !ok {
+		registry = &iamPolicyRegistry{}
+		iamPolicyRegistries[session] = registry
+	}
+	iamPolicyRegistriesMutex.Unlock()
+
+	registry.once.Do(func() {
+		registry.policies = map[string]*iam.Policy{}
+		registry.err = client.ListPoliciesPages(&iam.ListPoliciesInput{OnlyAttached: aws.Bool(true)},
+			func(page *iam.ListPoliciesOutput, lastPage bool) bool {
+				for _, policy := range page.Policies {
+					registry.policies[*policy.Arn] = policy
+				}
+				return true
+			})
+	})
+
+	return registry, registry.err
+}
+
+// reference returns what attachments need to find the policy in iam:policies.
+func (r *iamPolicyRegistry) reference(policyARN string) map[string]interface{} {
+	reference := map[string]interface{}{
+		"PolicyArn":    policyARN,
+		"IsAWSManaged": isAWSManagedPolicy(policyARN),
+	}
+
+	if policy, ok := r.policies[policyARN]; ok {
+		reference["PolicyId"] = aws.StringValue(policy.PolicyId)
+		reference["DefaultVersionId"] = aws.StringValue(policy.DefaultVersionId)
+	}
+
+	return reference
+}
+
+func (r *iamPolicyRegistry) awsManagedPolicies() []*iam.Policy {
+	policies := []*iam.Policy{}
+	for arn, policy := range r.policies {
+		if isAWSManagedPolicy(arn) {
+			policies = append(policies, policy)
+		}
+	}
+	return policies
+}
+
+func isAWSManagedPolicy(policyARN string) bool {
+	return strings.Contains(policyARN, ":iam::aws:policy/")
+}
+
+func newIAMPolicyVersionResource(session *Session, policyARN string, policyVersion *iam.PolicyVersion) (*Resource, error) {
+	document, err := DecodeInlinePolicyDocument(*policyVersion.Document)
+	if err != nil {
+		return nil, err
+	}
+
+	metadata := structs.Map(policyVersion)
+	metadata["Document"] = document
+
+	arn := fmt.Sprintf("%s:%s", policyARN, *policyVersion.VersionId)
+	return &Resource{
+		ID:        arn,
+		ARN:       arn,
+		AccountID: session.AccountID,
+		Service:   "iam",
+		Type:      "policy-version",
+		Region:    *session.Config.Region,
+		Metadata:  metadata,
+	}, nil
+}
+
+// IAMListAWSManagedPolicies returns the attached AWS managed policies with
+// their default version only.
+func IAMListAWSManagedPolicies(session *Session, client *iam.IAM) *ReportResult {
+	registry, err := getIAMPolicyRegistry(session, client)
+	if err != nil {
+		return &ReportResult{nil, err}
+	}
+
+	result := &ReportResult{}
+	for _, policy := range registry.awsManagedPolicies() {
+		resource, err := NewResource(*policy.Arn, policy)
+		if err != nil {
+			result.Error = err
+			return result
+		}
+		// AWS managed policies are not owned by the account
+		resource.AccountID = session.AccountID
+		result.Resources = append(result.Resources, *resource)
+
+		policyVersion, err := client.GetPolicyVersion(&iam.GetPolicyVersionInput{
+			PolicyArn: policy.Arn,
+			VersionId: policy.DefaultVersionId,
+		})
+		if err != nil {
+			result.Error = err
+			return result
+		}
+
+		versionResource, err := newIAMPolicyVersionResource(session, *policy.Arn, policyVersion.PolicyVersion)
+		if err != nil {
+			result.Error = err
+			return result
+		}
+		result.Resources = append(result.Resources, *versionResource)
+	}
+
+	return result
+}
@@ -0,0 +1,194 @@
+package resources
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/account"
+	"github.com/aws/aws-sdk-go/service/cloudtrail"
+	"github.com/aws/aws-sdk-go/service/iam"
+)
+
+const (
+	// root activity looked up in CloudTrail, it only keeps 90 days of events
+	rootActivityDays      = 90
+	rootActivityMaxEvents = 1000
+	// the console sign-ins and the calls to the global services are logged
+	// in us-east-1
+	rootActivityRegion = "us-east-1"
+)
+
+var rootAlternateContactTypes = []string{
+	account.AlternateContactTypeBilling,
+	account.AlternateContactTypeOperations,
+	account.AlternateContactTypeSecurity,
+}
+
+// IAMGetRootAccount returns the security posture of the root user of the
+// account: MFA, access keys, last use, recent activity in CloudTrail and
+// alternate contacts. The security challenge questions are not available
+// through the APIs and are always nil.
+func IAMGetRootAccount(session *Session) *ReportResult {
+	client := iam.New(session.Session, session.Config)
+
+	summary, err := client.GetAccountSummary(&iam.GetAccountSummaryInput{})
+	if err != nil {
+		return &ReportResult{nil, err}
+	}
+	mfaEnabled := aws.Int64Value(summary.SummaryMap[iam.SummaryKeyTypeAccountMfaenabled]) == 1
+
+	metadata := map[string]interface{}{
+		"MFAEnabled":                 mfaEnabled,
+		"MFAType":                    nil,
+		"AccessKeysPresent":          aws.Int64Value(summary.SummaryMap[iam.SummaryKeyTypeAccountAccessKeysPresent]) == 1,
+		"SecurityChallengeQuestions": nil,
+	}
+
+	if mfaEnabled {
+		virtual, err := iamRootHasVirtualMFA(client, session.AccountID)
+		if err != nil {
+			return &ReportResult{nil, err}
+		}
+		metadata["MFAType"] = "hardware"
+		if virtual {
+			metadata["MFAType"] = "virtual"
+		}
+	}
+
+	content, err := iamGetCredentialReport(client)
+	if err != nil {
+		return &ReportResult{nil, err}
+	}
+	rows, err := parseCredentialReport(content)
+	if err != nil {
+		return &ReportResult{nil, err}
+	}
+	for _, row := range rows {
+		if row["User"] == "<root_account>" {
+			for _, column := range []string{"PasswordLastUsed", "AccessKey1Active", "AccessKey1LastUsedDate", "AccessKey2Active", "AccessKey2LastUsedDate"} {
+				metadata[column] = row[column]
+			}
+		}
+	}
+
+	// CloudTrail and the account API are often denied to the dump role
+	errors := ReportErrors{}
+	activity, err := rootRecentActivity(session)
+	if err != nil && !errors.Add(err) {
+		return &ReportResult{nil, err}
+	}
+	metadata["RecentActivity"] = activity
+
+	contacts, err := rootAlternateContacts(session)
+	if err != nil && !errors.Add(err) {
+		return &ReportResult{nil, err}
+	}
+	metadata["AlternateContacts"] = contacts
+
+	return &ReportResult{
+		Resources: []Resource{newIAMResource(session, session.AccountID, fmt.Sprintf("arn:aws:iam::%s:root", session.AccountID), "root-account", metadata)},
+		Error:     errors.ErrorOrNil(),
+	}
+}
+
+// iamRootHasVirtualMFA returns true if a virtual MFA device is assigned to the
+// root user, the root user has a hardware device otherwise.
+func iamRootHasVirtualMFA(client *iam.IAM, accountID string) (bool, error) {
+	rootARN := fmt.Sprintf("arn:aws:iam::%s:root", accountID)
+	virtual := false
+	err := client.ListVirtualMFADevicesPages(&iam.ListVirtualMFADevicesInput{AssignmentStatus: aws.String(iam.AssignmentStatusTypeAssigned)},
+		func(page *iam.ListVirtualMFADevicesOutput, lastPage bool) bool {
+			for _, device := range page.VirtualMFADevices {
+				if device.User != nil && aws.StringValue(device.User.Arn) == rootARN {
+					virtual = true
+					return false
+				}
+			}
+			return true
+		})
+	return virtual, err
+}
+
+func rootRecentActivity(session *Session) (map[string]interface{}, error) {
+	regions := []string{rootActivityRegion}
+	if region := aws.StringValue(session.Config.Region); region != rootActivityRegion {
+		regions = append(regions, region)
+	}
+
+	events := []*cloudtrail.Event{}
+	for _, region := range regions {
+		client := cloudtrail.New(session.Session, session.Config.Copy(&aws.Config{Region: aws.String(region)}))
+		err := client.LookupEventsPages(&cloudtrail.LookupEventsInput{
+			LookupAttributes: []*cloudtrail.LookupAttribute{{
+				AttributeKey:   aws.String(cloudtrail.LookupAttributeKeyUsername),
+				AttributeValue: aws.String("root"),
+			}},
+			StartTime: aws.Time(time.Now().AddDate(0, 0, -rootActivityDays)),
+		}, func(page *cloudtrail.LookupEventsOutput, lastPage bool) bool {
+			events = append(events, page.Events...)
+			return len(events) < rootActivityMaxEvents
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return summariseRootActivity(events), nil
+}
+
+// summariseRootActivity returns the number of events, the time of the last
+// one, and the event names and source IP addresses seen.
+func summariseRootActivity(events []*cloudtrail.Event) map[string]interface{} {
+	var lastEventTime *time.Time
+	eventNames := map[string]bool{}
+	sourceIPAddresses := map[string]bool{}
+	for _, event := range events {
+		if event.EventTime != nil && (lastEventTime == nil || event.EventTime.After(*lastEventTime)) {
+			lastEventTime = event.EventTime
+		}
+		eventNames[fmt.Sprintf("%s:%s", aws.StringValue(event.EventSource), aws.StringValue(event.EventName))] = true
+
+		record := struct {
+			SourceIPAddress string `json:"sourceIPAddress"`
+		}{}
+		if json.Unmarshal([]byte(aws.StringValue(event.CloudTrailEvent)), &record) == nil && record.SourceIPAddress != "" {
+			sourceIPAddresses[record.SourceIPAddress] = true
+		}
+	}
+
+	return map[string]interface{}{
+		"Days":              rootActivityDays,
+		"Events":            len(events),
+		"Truncated":         len(events) >= rootActivityMaxEvents,
+		"LastEventTime":     lastEventTime,
+		"EventNames":        sortedKeys(eventNames),
+		"SourceIPAddresses": sortedKeys(sourceIPAddresses),
+	}
+}
+
+// rootAlternateContacts returns which alternate contacts are set.
+func rootAlternateContacts(session *Session) (map[string]interface{}, error) {
+	client := account.New(session.Session, session.Config)
+
+	contacts := map[string]interface{}{}
+	for _, contactType := range rootAlternateContactTypes {
+		_, err := client.GetAlternateContact(&account.GetAlternateContactInput{AlternateContactType: aws.String(contactType)})
+		if err != nil && !IsErrorCode(err, account.ErrCodeResourceNotFoundException) {
+			return nil, err
+		}
+		contacts[contactType] = err == nil
+	}
+	return contacts, nil
+}
+
+func sortedKeys(values map[string]bool) []string {
+	keys := []string{}
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
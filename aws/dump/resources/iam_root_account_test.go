@@ -0,0 +1,40 @@
+package resources
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudtrail"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSummariseRootActivity(t *testing.T) {
+	first := time.Date(2021, 1, 10, 8, 0, 0, 0, time.UTC)
+	last := time.Date(2021, 1, 12, 9, 30, 0, 0, time.UTC)
+
+	activity := summariseRootActivity([]*cloudtrail.Event{
+		{
+			EventTime:       aws.Time(last),
+			EventSource:     aws.String("iam.amazonaws.com"),
+			EventName:       aws.String("CreateAccessKey"),
+			CloudTrailEvent: aws.String(`{"sourceIPAddress": "203.0.113.10"}`),
+		},
+		{
+			EventTime:       aws.Time(first),
+			EventSource:     aws.String("signin.amazonaws.com"),
+			EventName:       aws.String("ConsoleLogin"),
+			CloudTrailEvent: aws.String(`{"sourceIPAddress": "203.0.113.10"}`),
+		},
+	})
+
+	require.Equal(t, 2, activity["Events"])
+	require.Equal(t, false, activity["Truncated"])
+	require.Equal(t, last, *activity["LastEventTime"].(*time.Time))
+	require.Equal(t, []string{"iam.amazonaws.com:CreateAccessKey", "signin.amazonaws.com:ConsoleLogin"}, activity["EventNames"])
+	require.Equal(t, []string{"203.0.113.10"}, activity["SourceIPAddresses"])
+
+	activity = summariseRootActivity([]*cloudtrail.Event{})
+	require.Equal(t, 0, activity["Events"])
+	require.Nil(t, activity["LastEventTime"])
+}
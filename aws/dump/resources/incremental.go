@@ -0,0 +1,101 @@
+package resources
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sync/atomic"
+)
+
+// PreviousDump has the resources of the previous dump of an incremental dump.
+// The reports whose listing APIs tell when a resource last changed reuse the
+// details of the unchanged resources instead of fetching them again.
+type PreviousDump struct {
+	resources      map[string]Resource
+	stackResources map[string][]Resource
+	reused         int64
+}
+
+// Previous is set for incremental dumps.
+var Previous *PreviousDump
+
+// Key is the ARN of the resource, or its account, region, service, type and
+// id when it doesn't have one.
+func (r *Resource) Key() string {
+	if r.ARN != "" {
+		return r.ARN
+	}
+	return fmt.Sprintf("%s/%s/%s/%s/%s", r.AccountID, r.Region, r.Service, r.Type, r.ID)
+}
+
+func NewPreviousDump(resourceList []Resource) *PreviousDump {
+	previous := &PreviousDump{
+		resources:      map[string]Resource{},
+		stackResources: map[string][]Resource{},
+	}
+	for _, resource := range resourceList {
+		previous.resources[resource.Key()] = resource
+		if resource.Service == "cloudformation" && resource.Type == "stack-resource" {
+			stackID, _ := resource.Metadata["StackId"].(string)
+			previous.stackResources[stackID] = append(previous.stackResources[stackID], resource)
+		}
+	}
+	return previous
+}
+
+func (p *PreviousDump) Get(key string) (Resource, bool) {
+	if p == nil {
+		return Resource{}, false
+	}
+	resource, ok := p.resources[key]
+	return resource, ok
+}
+
+// Unchanged returns true if the resource was in the previous dump with the
+// same metadata.
+func (p *PreviousDump) Unchanged(resource Resource) bool {
+	previous, ok := p.Get(resource.Key())
+	return ok && sameValue(previous.Metadata, resource.Metadata)
+}
+
+// Reused is the number of resources reused from the previous dump instead of
+// being fetched.
+func (p *PreviousDump) Reused() int {
+	if p == nil {
+		return 0
+	}
+	return int(atomic.LoadInt64(&p.reused))
+}
+
+func (p *PreviousDump) reuse(resources ...Resource) []Resource {
+	atomic.AddInt64(&p.reused, int64(len(resources)))
+	return resources
+}
+
+// sameValue compares the values as JSON, the values of the previous dump are
+// loaded from JSON and don't have the types of the SDK.
+func sameValue(a, b interface{}) bool {
+	dataA, err := json.Marshal(a)
+	if err != nil {
+		return false
+	}
+	dataB, err := json.Marshal(b)
+	if err != nil {
+		return false
+	}
+	return bytes.Equal(dataA, dataB)
+}
+
+// nestedValue returns the value of the nested keys of the metadata, nil if it
+// doesn't exist.
+func nestedValue(metadata map[string]interface{}, keys ...string) interface{} {
+	var value interface{} = metadata
+	for _, key := range keys {
+		current, ok := value.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		value = current[key]
+	}
+	return value
+}
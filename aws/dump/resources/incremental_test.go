@@ -0,0 +1,60 @@
+package resources
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPreviousDump(t *testing.T) {
+	t.Parallel()
+
+	createDate := time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)
+	current := []Resource{
+		{ARN: "arn:aws:iam::123456789012:policy/deploy", Service: "iam", Type: "policy", Metadata: map[string]interface{}{
+			"CreateDate":      &createDate,
+			"AttachmentCount": int64(2),
+		}},
+		{ID: "stack_Bucket", AccountID: "123456789012", Region: "eu-west-1", Service: "cloudformation", Type: "stack-resource", Metadata: map[string]interface{}{
+			"StackId": "arn:aws:cloudformation:eu-west-1:123456789012:stack/web/1",
+		}},
+	}
+
+	// the previous dump is loaded from JSON
+	data, err := json.Marshal(current)
+	require.NoError(t, err)
+	previousResources := []Resource{}
+	require.NoError(t, json.Unmarshal(data, &previousResources))
+
+	previous := NewPreviousDump(previousResources)
+	require.True(t, previous.Unchanged(current[0]))
+	require.True(t, previous.Unchanged(current[1]))
+	require.Len(t, previous.stackResources["arn:aws:cloudformation:eu-west-1:123456789012:stack/web/1"], 1)
+
+	current[0].Metadata["AttachmentCount"] = int64(3)
+	require.False(t, previous.Unchanged(current[0]))
+	require.False(t, previous.Unchanged(Resource{ARN: "arn:aws:iam::123456789012:policy/new"}))
+
+	_, ok := previous.Get("123456789012/eu-west-1/cloudformation/stack-resource/stack_Bucket")
+	require.True(t, ok)
+
+	previous.reuse(previousResources...)
+	require.Equal(t, 2, previous.Reused())
+
+	var none *PreviousDump
+	require.False(t, none.Unchanged(current[0]))
+	require.Equal(t, 0, none.Reused())
+}
+
+func TestNestedValue(t *testing.T) {
+	t.Parallel()
+
+	metadata := map[string]interface{}{
+		"DriftInformation": map[string]interface{}{"LastCheckTimestamp": "2021-01-02T03:04:05Z"},
+	}
+	require.Equal(t, "2021-01-02T03:04:05Z", nestedValue(metadata, "DriftInformation", "LastCheckTimestamp"))
+	require.Nil(t, nestedValue(metadata, "DriftInformation", "StackDriftStatus"))
+	require.Nil(t, nestedValue(metadata, "LastUpdatedTime", "Missing"))
+}
@@ -0,0 +1,231 @@
+package resources
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/iot"
+	"github.com/fatih/structs"
+)
+
+var (
+	IoTService = Service{
+		Name: "iot",
+		Reports: map[string]Report{
+			"things":       IoTListThings,
+			"thing-types":  IoTListThingTypes,
+			"thing-groups": IoTListThingGroups,
+			"certificates": IoTListCertificates,
+			"policies":     IoTListPolicies,
+			"topic-rules":  IoTListTopicRules,
+		},
+	}
+)
+
+func newIoTResource(session *Session, id, arn, resourceType string, metadata interface{}) Resource {
+	return Resource{
+		ID:        id,
+		ARN:       arn,
+		AccountID: session.AccountID,
+		Service:   "iot",
+		Type:      resourceType,
+		Region:    *session.Config.Region,
+		Metadata:  structs.Map(metadata),
+	}
+}
+
+func IoTListThings(session *Session) *ReportResult {
+	client := iot.New(session.Session, session.Config)
+
+	result := &ReportResult{}
+	input := &iot.ListThingsInput{}
+	for {
+		page, err := client.ListThings(input)
+		if err != nil {
+			result.Error = err
+			return result
+		}
+
+		for _, thing := range page.Things {
+			result.Resources = append(result.Resources, newIoTResource(session, *thing.ThingName, aws.StringValue(thing.ThingArn), "thing", thing))
+		}
+
+		if page.NextToken == nil {
+			break
+		}
+		input.NextToken = page.NextToken
+	}
+
+	return result
+}
+
+func IoTListThingTypes(session *Session) *ReportResult {
+	client := iot.New(session.Session, session.Config)
+
+	result := &ReportResult{}
+	input := &iot.ListThingTypesInput{}
+	for {
+		page, err := client.ListThingTypes(input)
+		if err != nil {
+			result.Error = err
+			return result
+		}
+
+		for _, thingType := range page.ThingTypes {
+			result.Resources = append(result.Resources, newIoTResource(session, *thingType.ThingTypeName, aws.StringValue(thingType.ThingTypeArn), "thing-type", thingType))
+		}
+
+		if page.NextToken == nil {
+			break
+		}
+		input.NextToken = page.NextToken
+	}
+
+	return result
+}
+
+func IoTListThingGroups(session *Session) *ReportResult {
+	client := iot.New(session.Session, session.Config)
+
+	result := &ReportResult{}
+	input := &iot.ListThingGroupsInput{}
+	for {
+		page, err := client.ListThingGroups(input)
+		if err != nil {
+			result.Error = err
+			return result
+		}
+
+		for _, group := range page.ThingGroups {
+			// includes the properties and the query of dynamic groups
+			thingGroup, err := client.DescribeThingGroup(&iot.DescribeThingGroupInput{ThingGroupName: group.GroupName})
+			if err != nil {
+				result.Error = err
+				return result
+			}
+			result.Resources = append(result.Resources, newIoTResource(session, *group.GroupName, aws.StringValue(group.GroupArn), "thing-group", thingGroup))
+		}
+
+		if page.NextToken == nil {
+			break
+		}
+		input.NextToken = page.NextToken
+	}
+
+	return result
+}
+
+// IoTListCertificates lists the certificates with their status and expiry,
+// without the PEM.
+func IoTListCertificates(session *Session) *ReportResult {
+	client := iot.New(session.Session, session.Config)
+
+	result := &ReportResult{}
+	input := &iot.ListCertificatesInput{}
+	now := time.Now().UTC()
+	for {
+		page, err := client.ListCertificates(input)
+		if err != nil {
+			result.Error = err
+			return result
+		}
+
+		for _, certificate := range page.Certificates {
+			res, err := client.DescribeCertificate(&iot.DescribeCertificateInput{CertificateId: certificate.CertificateId})
+			if err != nil {
+				result.Error = err
+				return result
+			}
+
+			description := res.CertificateDescription
+			description.CertificatePem = nil
+
+			resource := newIoTResource(session, *certificate.CertificateId, aws.StringValue(certificate.CertificateArn), "certificate", description)
+			if description.Validity != nil && description.Validity.NotAfter != nil {
+				resource.Metadata["ExpiresAt"] = *description.Validity.NotAfter
+				resource.Metadata["Expired"] = description.Validity.NotAfter.Before(now)
+			}
+			result.Resources = append(result.Resources, resource)
+		}
+
+		if page.NextMarker == nil {
+			break
+		}
+		input.Marker = page.NextMarker
+	}
+
+	return result
+}
+
+// IoTListPolicies lists the policies with their default version document decoded.
+func IoTListPolicies(session *Session) *ReportResult {
+	client := iot.New(session.Session, session.Config)
+
+	result := &ReportResult{}
+	input := &iot.ListPoliciesInput{}
+	for {
+		page, err := client.ListPolicies(input)
+		if err != nil {
+			result.Error = err
+			return result
+		}
+
+		for _, policy := range page.Policies {
+			res, err := client.GetPolicy(&iot.GetPolicyInput{PolicyName: policy.PolicyName})
+			if err != nil {
+				result.Error = err
+				return result
+			}
+
+			document := map[string]interface{}{}
+			err = json.Unmarshal([]byte(aws.StringValue(res.PolicyDocument)), &document)
+			if err != nil {
+				result.Error = err
+				return result
+			}
+
+			resource := newIoTResource(session, *policy.PolicyName, aws.StringValue(policy.PolicyArn), "policy", res)
+			resource.Metadata["PolicyDocument"] = document
+			result.Resources = append(result.Resources, resource)
+		}
+
+		if page.NextMarker == nil {
+			break
+		}
+		input.Marker = page.NextMarker
+	}
+
+	return result
+}
+
+func IoTListTopicRules(session *Session) *ReportResult {
+	client := iot.New(session.Session, session.Config)
+
+	result := &ReportResult{}
+	input := &iot.ListTopicRulesInput{}
+	for {
+		page, err := client.ListTopicRules(input)
+		if err != nil {
+			result.Error = err
+			return result
+		}
+
+		for _, rule := range page.Rules {
+			// includes the SQL and the actions
+			res, err := client.GetTopicRule(&iot.GetTopicRuleInput{RuleName: rule.RuleName})
+			if err != nil {
+				result.Error = err
+				return result
+			}
+			result.Resources = append(result.Resources, newIoTResource(session, *rule.RuleName, aws.StringValue(rule.RuleArn), "topic-rule", res.Rule))
+		}
+
+		if page.NextToken == nil {
+			break
+		}
+		input.NextToken = page.NextToken
+	}
+
+	return result
+}
@@ -2,7 +2,9 @@ package resources
 
 import (
 	"fmt"
+	"time"
 
+	"github.com/aws/aws-sdk-go/aws"
 	"github.com/fatih/structs"
 	"github.com/hamstah/awstools/common"
 )
@@ -16,6 +18,9 @@ type Resource struct {
 	Region    string                 `json:"region"`
 	Metadata  map[string]interface{} `json:"metadata"`
 	ManagedBy map[string]string      `json:"managed_by"`
+	// Unchanged is set by incremental dumps when the metadata is the same as
+	// in the previous dump
+	Unchanged bool `json:"unchanged,omitempty"`
 }
 
 func (r *Resource) UniqueID() string {
@@ -52,9 +57,12 @@ type Service struct {
 	Name     string
 	IsGlobal bool
 	Reports  map[string]Report
+	// OptionalReports are only run when requested explicitly or when
+	// optional reports are included.
+	OptionalReports map[string]Report
 }
 
-func (s *Service) GenerateAllJobs(account *Account) ([]Job, error) {
+func (s *Service) GenerateAllJobs(account *Account, includeOptional bool) ([]Job, error) {
 	jobs := []Job{}
 	for resource := range s.Reports {
 		newJobs, err := s.GenerateJobs(account, resource)
@@ -63,23 +71,40 @@ func (s *Service) GenerateAllJobs(account *Account) ([]Job, error) {
 		}
 		jobs = append(jobs, newJobs...)
 	}
+	if includeOptional {
+		for resource := range s.OptionalReports {
+			newJobs, err := s.GenerateJobs(account, resource)
+			if err != nil {
+				return nil, err
+			}
+			jobs = append(jobs, newJobs...)
+		}
+	}
 	return jobs, nil
 }
 
 func (s *Service) GenerateJobs(account *Account, resource string) ([]Job, error) {
 	Report, ok := s.Reports[resource]
+	if !ok {
+		Report, ok = s.OptionalReports[resource]
+	}
 	if !ok {
 		return nil, fmt.Errorf("Unknown resource %s for service %s", resource, s.Name)
 	}
 	jobs := []Job{}
 	if s.IsGlobal {
 		jobs = append(jobs, Job{
-			Report:  Report,
-			Session: account.Sessions[0],
+			Service:  s.Name,
+			Name:     resource,
+			IsGlobal: true,
+			Report:   Report,
+			Session:  account.Sessions[0],
 		})
 	} else {
 		for _, session := range account.Sessions {
 			jobs = append(jobs, Job{
+				Service: s.Name,
+				Name:    resource,
 				Report:  Report,
 				Session: session,
 			})
@@ -96,22 +121,94 @@ type ReportResult struct {
 type Report func(*Session) *ReportResult
 
 type Job struct {
-	Report  Report
-	Session *Session
+	Service  string
+	Name     string
+	IsGlobal bool
+	Report   Report
+	Session  *Session
+}
+
+const DefaultConcurrency = 10
+
+// RunOptions limits how many reports run at the same time, in total and per
+// service (e.g. to avoid the throttling of the IAM API).
+type RunOptions struct {
+	Concurrency        int
+	ServiceConcurrency map[string]int
+	// Sink receives the resources of each report as soon as it completes
+	// instead of Run returning them.
+	Sink func([]Resource)
+	// PartialResults keeps the resources returned by the reports that failed.
+	PartialResults bool
+	// Progress is called after each report completes.
+	Progress func(JobProgress)
 }
 
-func worker(id int, jobs <-chan Job, results chan<- *ReportResult) {
+// JobProgress is the outcome of a job and the progress of the run.
+type JobProgress struct {
+	Service   string
+	Report    string
+	AccountID string
+	Region    string
+	Resources int
+	Error     error
+	Duration  time.Duration
+	Completed int
+	Total     int
+}
+
+type jobResult struct {
+	job      Job
+	result   *ReportResult
+	duration time.Duration
+}
+
+func worker(id int, jobs <-chan Job, results chan<- *jobResult, limits map[string]chan struct{}) {
 	for job := range jobs {
-		results <- job.Report(job.Session)
+		limit, ok := limits[job.Service]
+		if ok {
+			limit <- struct{}{}
+		}
+		started := time.Now()
+		result := job.Report(job.Session)
+		duration := time.Since(started)
+		if ok {
+			<-limit
+		}
+
+		// resources of regional services are in the region of the session
+		if !job.IsGlobal && job.Session != nil {
+			for i := range result.Resources {
+				if result.Resources[i].Region == "" {
+					result.Resources[i].Region = aws.StringValue(job.Session.Config.Region)
+				}
+			}
+		}
+		if result.Error != nil {
+			result.Error = NewJobError(job, result.Error)
+		}
+		results <- &jobResult{job, result, duration}
 	}
 }
 
-func Run(jobs []Job) ([]Resource, []error) {
+func Run(jobs []Job, options RunOptions) ([]Resource, []error) {
 	jobsChan := make(chan Job, len(jobs))
-	results := make(chan *ReportResult, len(jobs))
+	results := make(chan *jobResult, len(jobs))
+
+	concurrency := options.Concurrency
+	if concurrency <= 0 {
+		concurrency = DefaultConcurrency
+	}
+
+	limits := map[string]chan struct{}{}
+	for service, limit := range options.ServiceConcurrency {
+		if limit > 0 {
+			limits[service] = make(chan struct{}, limit)
+		}
+	}
 
-	for w := 0; w < 10; w++ {
-		go worker(w, jobsChan, results)
+	for w := 0; w < concurrency; w++ {
+		go worker(w, jobsChan, results, limits)
 	}
 
 	for _, job := range jobs {
@@ -122,11 +219,35 @@ func Run(jobs []Job) ([]Resource, []error) {
 	resources := []Resource{}
 	errors := []error{}
 	for i := 0; i < len(jobs); i++ {
-		result := <-results
-		if result.Error == nil {
-			resources = append(resources, result.Resources...)
-		} else {
+		completed := <-results
+		result := completed.result
+		if options.Progress != nil {
+			progress := JobProgress{
+				Service:   completed.job.Service,
+				Report:    completed.job.Name,
+				Resources: len(result.Resources),
+				Error:     result.Error,
+				Duration:  completed.duration,
+				Completed: i + 1,
+				Total:     len(jobs),
+			}
+			if completed.job.Session != nil {
+				progress.AccountID = completed.job.Session.AccountID
+				progress.Region = aws.StringValue(completed.job.Session.Config.Region)
+			}
+			options.Progress(progress)
+		}
+
+		if result.Error != nil {
 			errors = append(errors, result.Error)
+			if !options.PartialResults {
+				continue
+			}
+		}
+		if options.Sink != nil {
+			options.Sink(result.Resources)
+		} else {
+			resources = append(resources, result.Resources...)
 		}
 	}
 	return resources, errors
@@ -1,8 +1,11 @@
 package resources
 
 import (
+	"errors"
 	"fmt"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/hamstah/awstools/common"
 	"github.com/stretchr/testify/require"
@@ -27,3 +30,132 @@ func TestParseArn(t *testing.T) {
 	}
 
 }
+
+func TestRunServiceConcurrency(t *testing.T) {
+	t.Parallel()
+
+	lock := sync.Mutex{}
+	running := map[string]int{}
+	maxRunning := map[string]int{}
+	report := func(service string) Report {
+		return func(session *Session) *ReportResult {
+			lock.Lock()
+			running[service]++
+			if running[service] > maxRunning[service] {
+				maxRunning[service] = running[service]
+			}
+			lock.Unlock()
+
+			time.Sleep(10 * time.Millisecond)
+
+			lock.Lock()
+			running[service]--
+			lock.Unlock()
+
+			if service == "s3" {
+				return &ReportResult{Error: errors.New("failed")}
+			}
+			return &ReportResult{Resources: []Resource{{ID: service}}}
+		}
+	}
+
+	jobs := []Job{}
+	for i := 0; i < 6; i++ {
+		jobs = append(jobs, Job{Service: "iam", Report: report("iam")})
+		jobs = append(jobs, Job{Service: "ec2", Report: report("ec2")})
+	}
+	jobs = append(jobs, Job{Service: "s3", Report: report("s3")})
+
+	resources, errs := Run(jobs, RunOptions{Concurrency: 4, ServiceConcurrency: map[string]int{"iam": 1}})
+	require.Len(t, resources, 12)
+	require.Len(t, errs, 1)
+	require.Equal(t, 1, maxRunning["iam"])
+	require.LessOrEqual(t, maxRunning["ec2"], 4)
+}
+
+func TestRunSink(t *testing.T) {
+	t.Parallel()
+
+	jobs := []Job{}
+	for i := 0; i < 3; i++ {
+		id := fmt.Sprintf("resource-%d", i)
+		jobs = append(jobs, Job{Service: "ec2", Report: func(session *Session) *ReportResult {
+			return &ReportResult{Resources: []Resource{{ID: id}}}
+		}})
+	}
+
+	streamed := []string{}
+	resources, errs := Run(jobs, RunOptions{Sink: func(result []Resource) {
+		for _, resource := range result {
+			streamed = append(streamed, resource.ID)
+		}
+	}})
+	require.Empty(t, resources)
+	require.Empty(t, errs)
+	require.ElementsMatch(t, []string{"resource-0", "resource-1", "resource-2"}, streamed)
+}
+
+func TestRunPartialResults(t *testing.T) {
+	t.Parallel()
+
+	jobs := []Job{{Service: "sharing", Name: "findings", Report: func(session *Session) *ReportResult {
+		return &ReportResult{
+			Resources: []Resource{{ID: "snap-1"}},
+			Error:     ReportErrors{errors.New("AccessDenied")},
+		}
+	}}}
+
+	resources, errs := Run(jobs, RunOptions{})
+	require.Empty(t, resources)
+	require.Len(t, errs, 1)
+
+	resources, errs = Run(jobs, RunOptions{PartialResults: true})
+	require.Len(t, resources, 1)
+	require.Len(t, errs, 1)
+
+	jobError, ok := errs[0].(*JobError)
+	require.True(t, ok)
+	require.Equal(t, "sharing", jobError.Service)
+	require.Equal(t, "findings", jobError.Report)
+	require.Equal(t, "AccessDenied", jobError.Message)
+	require.Equal(t, "error", jobError.Resource().Type)
+}
+
+func TestReportErrors(t *testing.T) {
+	errs := ReportErrors{}
+	require.Nil(t, errs.ErrorOrNil())
+
+	PartialResults = false
+	require.False(t, errs.Add(errors.New("first")))
+	PartialResults = true
+	require.True(t, errs.Add(errors.New("second")))
+	PartialResults = false
+
+	require.EqualError(t, errs.ErrorOrNil(), "first; second")
+}
+
+func TestRunProgress(t *testing.T) {
+	t.Parallel()
+
+	jobs := []Job{
+		{Service: "iam", Name: "roles", Report: func(session *Session) *ReportResult {
+			return &ReportResult{Resources: []Resource{{ID: "role-1"}, {ID: "role-2"}}}
+		}},
+		{Service: "s3", Name: "buckets", Report: func(session *Session) *ReportResult {
+			return &ReportResult{Error: errors.New("AccessDenied")}
+		}},
+	}
+
+	progress := map[string]JobProgress{}
+	Run(jobs, RunOptions{Progress: func(jobProgress JobProgress) {
+		progress[jobProgress.Service] = jobProgress
+	}})
+
+	require.Len(t, progress, 2)
+	require.Equal(t, "roles", progress["iam"].Report)
+	require.Equal(t, 2, progress["iam"].Resources)
+	require.NoError(t, progress["iam"].Error)
+	require.Error(t, progress["s3"].Error)
+	require.Equal(t, 2, progress["s3"].Total)
+	require.ElementsMatch(t, []int{1, 2}, []int{progress["iam"].Completed, progress["s3"].Completed})
+}
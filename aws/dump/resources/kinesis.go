@@ -0,0 +1,50 @@
+package resources
+
+import (
+	"github.com/aws/aws-sdk-go/service/kinesis"
+	"github.com/fatih/structs"
+)
+
+var (
+	KinesisService = Service{
+		Name: "kinesis",
+		Reports: map[string]Report{
+			"streams": KinesisListStreams,
+		},
+	}
+)
+
+func KinesisListStreams(session *Session) *ReportResult {
+	client := kinesis.New(session.Session, session.Config)
+
+	result := &ReportResult{}
+	err := client.ListStreamsPages(&kinesis.ListStreamsInput{},
+		func(page *kinesis.ListStreamsOutput, lastPage bool) bool {
+			for _, streamName := range page.StreamNames {
+				stream, err := client.DescribeStreamSummary(&kinesis.DescribeStreamSummaryInput{StreamName: streamName})
+				if err != nil {
+					result.Error = err
+					return false
+				}
+
+				summary := stream.StreamDescriptionSummary
+				result.Resources = append(result.Resources, Resource{
+					ID:        *summary.StreamName,
+					ARN:       *summary.StreamARN,
+					AccountID: session.AccountID,
+					Service:   "kinesis",
+					Type:      "stream",
+					Region:    *session.Config.Region,
+					Metadata:  structs.Map(summary),
+				})
+			}
+
+			return true
+		})
+
+	if result.Error != nil {
+		return result
+	}
+	result.Error = err
+	return result
+}
@@ -11,8 +11,9 @@ var (
 	KMSService = Service{
 		Name: "kms",
 		Reports: map[string]Report{
-			"keys":    KMSListKeys,
-			"aliases": KMSListAliases,
+			"keys":               KMSListKeys,
+			"aliases":            KMSListAliases,
+			"encryption-posture": KMSListEncryptionPosture,
 		},
 	}
 )
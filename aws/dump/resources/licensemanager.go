@@ -0,0 +1,162 @@
+package resources
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/licensemanager"
+	"github.com/fatih/structs"
+)
+
+var (
+	LicenseManagerService = Service{
+		Name: "licensemanager",
+		Reports: map[string]Report{
+			"license-configurations": LicenseManagerListLicenseConfigurations,
+			"received-licenses":      LicenseManagerListReceivedLicenses,
+			"marketplace-instances":  LicenseManagerListMarketplaceInstances,
+		},
+	}
+)
+
+// LicenseManagerListLicenseConfigurations lists the license configurations
+// with the resources consuming them.
+func LicenseManagerListLicenseConfigurations(session *Session) *ReportResult {
+	client := licensemanager.New(session.Session, session.Config)
+
+	result := &ReportResult{}
+	input := &licensemanager.ListLicenseConfigurationsInput{}
+	for {
+		page, err := client.ListLicenseConfigurations(input)
+		if err != nil {
+			result.Error = err
+			return result
+		}
+
+		for _, configuration := range page.LicenseConfigurations {
+			resource, err := NewResource(*configuration.LicenseConfigurationArn, configuration)
+			if err != nil {
+				result.Error = err
+				return result
+			}
+
+			usage, err := licenseManagerListUsage(client, configuration.LicenseConfigurationArn)
+			if err != nil {
+				result.Error = err
+				return result
+			}
+			resource.Metadata["Usage"] = usage
+
+			result.Resources = append(result.Resources, *resource)
+		}
+
+		if page.NextToken == nil {
+			break
+		}
+		input.NextToken = page.NextToken
+	}
+
+	return result
+}
+
+func licenseManagerListUsage(client *licensemanager.LicenseManager, arn *string) ([]map[string]interface{}, error) {
+	usage := []map[string]interface{}{}
+
+	input := &licensemanager.ListUsageForLicenseConfigurationInput{LicenseConfigurationArn: arn}
+	for {
+		page, err := client.ListUsageForLicenseConfiguration(input)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, consumer := range page.LicenseConfigurationUsageList {
+			usage = append(usage, structs.Map(consumer))
+		}
+
+		if page.NextToken == nil {
+			break
+		}
+		input.NextToken = page.NextToken
+	}
+
+	return usage, nil
+}
+
+// LicenseManagerListReceivedLicenses lists the licenses granted to the
+// account, including the AWS Marketplace subscriptions.
+func LicenseManagerListReceivedLicenses(session *Session) *ReportResult {
+	client := licensemanager.New(session.Session, session.Config)
+
+	result := &ReportResult{}
+	input := &licensemanager.ListReceivedLicensesInput{}
+	for {
+		page, err := client.ListReceivedLicenses(input)
+		if err != nil {
+			result.Error = err
+			return result
+		}
+
+		for _, license := range page.Licenses {
+			resource, err := NewResource(*license.LicenseArn, license)
+			if err != nil {
+				result.Error = err
+				return result
+			}
+			if license.Issuer != nil {
+				resource.Metadata["IssuerName"] = aws.StringValue(license.Issuer.Name)
+			}
+			result.Resources = append(result.Resources, *resource)
+		}
+
+		if page.NextToken == nil {
+			break
+		}
+		input.NextToken = page.NextToken
+	}
+
+	return result
+}
+
+// LicenseManagerListMarketplaceInstances lists the instances running
+// AWS Marketplace products.
+func LicenseManagerListMarketplaceInstances(session *Session) *ReportResult {
+	client := ec2.New(session.Session, session.Config)
+
+	result := &ReportResult{}
+	result.Error = client.DescribeInstancesPages(&ec2.DescribeInstancesInput{},
+		func(page *ec2.DescribeInstancesOutput, lastPage bool) bool {
+			for _, reservation := range page.Reservations {
+				for _, instance := range reservation.Instances {
+					productCodes := []string{}
+					for _, productCode := range instance.ProductCodes {
+						if aws.StringValue(productCode.ProductCodeType) == ec2.ProductCodeValuesMarketplace {
+							productCodes = append(productCodes, aws.StringValue(productCode.ProductCodeId))
+						}
+					}
+					if len(productCodes) == 0 {
+						continue
+					}
+
+					result.Resources = append(result.Resources, Resource{
+						ID:        *instance.InstanceId,
+						ARN:       fmt.Sprintf("arn:aws:ec2:%s:%s:instance/%s", *session.Config.Region, *reservation.OwnerId, *instance.InstanceId),
+						AccountID: *reservation.OwnerId,
+						Service:   "licensemanager",
+						Type:      "marketplace-instance",
+						Region:    *session.Config.Region,
+						Metadata: map[string]interface{}{
+							"InstanceId":   *instance.InstanceId,
+							"InstanceType": aws.StringValue(instance.InstanceType),
+							"ImageId":      aws.StringValue(instance.ImageId),
+							"State":        aws.StringValue(instance.State.Name),
+							"ProductCodes": productCodes,
+						},
+					})
+				}
+			}
+			return true
+		})
+
+	return result
+}
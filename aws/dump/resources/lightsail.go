@@ -0,0 +1,157 @@
+package resources
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/lightsail"
+	"github.com/fatih/structs"
+)
+
+var (
+	LightsailService = Service{
+		Name: "lightsail",
+		Reports: map[string]Report{
+			"instances":      LightsailListInstances,
+			"databases":      LightsailListRelationalDatabases,
+			"load-balancers": LightsailListLoadBalancers,
+			"static-ips":     LightsailListStaticIps,
+			"disks":          LightsailListDisks,
+		},
+	}
+)
+
+func newLightsailResource(session *Session, name *string, arn *string, resourceType string, metadata interface{}) Resource {
+	return Resource{
+		ID:        *name,
+		ARN:       aws.StringValue(arn),
+		AccountID: session.AccountID,
+		Service:   "lightsail",
+		Type:      resourceType,
+		Region:    *session.Config.Region,
+		Metadata:  structs.Map(metadata),
+	}
+}
+
+func LightsailListInstances(session *Session) *ReportResult {
+	client := lightsail.New(session.Session, session.Config)
+
+	result := &ReportResult{}
+	input := &lightsail.GetInstancesInput{}
+	for {
+		page, err := client.GetInstances(input)
+		if err != nil {
+			result.Error = err
+			return result
+		}
+
+		for _, item := range page.Instances {
+			result.Resources = append(result.Resources, newLightsailResource(session, item.Name, item.Arn, "instance", item))
+		}
+
+		if page.NextPageToken == nil {
+			break
+		}
+		input.PageToken = page.NextPageToken
+	}
+
+	return result
+}
+
+func LightsailListRelationalDatabases(session *Session) *ReportResult {
+	client := lightsail.New(session.Session, session.Config)
+
+	result := &ReportResult{}
+	input := &lightsail.GetRelationalDatabasesInput{}
+	for {
+		page, err := client.GetRelationalDatabases(input)
+		if err != nil {
+			result.Error = err
+			return result
+		}
+
+		for _, item := range page.RelationalDatabases {
+			result.Resources = append(result.Resources, newLightsailResource(session, item.Name, item.Arn, "database", item))
+		}
+
+		if page.NextPageToken == nil {
+			break
+		}
+		input.PageToken = page.NextPageToken
+	}
+
+	return result
+}
+
+func LightsailListLoadBalancers(session *Session) *ReportResult {
+	client := lightsail.New(session.Session, session.Config)
+
+	result := &ReportResult{}
+	input := &lightsail.GetLoadBalancersInput{}
+	for {
+		page, err := client.GetLoadBalancers(input)
+		if err != nil {
+			result.Error = err
+			return result
+		}
+
+		for _, item := range page.LoadBalancers {
+			result.Resources = append(result.Resources, newLightsailResource(session, item.Name, item.Arn, "load-balancer", item))
+		}
+
+		if page.NextPageToken == nil {
+			break
+		}
+		input.PageToken = page.NextPageToken
+	}
+
+	return result
+}
+
+func LightsailListStaticIps(session *Session) *ReportResult {
+	client := lightsail.New(session.Session, session.Config)
+
+	result := &ReportResult{}
+	input := &lightsail.GetStaticIpsInput{}
+	for {
+		page, err := client.GetStaticIps(input)
+		if err != nil {
+			result.Error = err
+			return result
+		}
+
+		for _, item := range page.StaticIps {
+			result.Resources = append(result.Resources, newLightsailResource(session, item.Name, item.Arn, "static-ip", item))
+		}
+
+		if page.NextPageToken == nil {
+			break
+		}
+		input.PageToken = page.NextPageToken
+	}
+
+	return result
+}
+
+func LightsailListDisks(session *Session) *ReportResult {
+	client := lightsail.New(session.Session, session.Config)
+
+	result := &ReportResult{}
+	input := &lightsail.GetDisksInput{}
+	for {
+		page, err := client.GetDisks(input)
+		if err != nil {
+			result.Error = err
+			return result
+		}
+
+		for _, item := range page.Disks {
+			result.Resources = append(result.Resources, newLightsailResource(session, item.Name, item.Arn, "disk", item))
+		}
+
+		if page.NextPageToken == nil {
+			break
+		}
+		input.PageToken = page.NextPageToken
+	}
+
+	return result
+}
@@ -0,0 +1,422 @@
+package resources
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/costexplorer"
+	"github.com/aws/aws-sdk-go/service/pricing"
+)
+
+const (
+	hoursPerMonth = 730
+
+	// the Price List and Cost Explorer APIs are only available in a few
+	// regions, the prices are the same from all of them
+	pricingRegion = "us-east-1"
+)
+
+// priceQuery selects the on-demand price of a unit of a product with the
+// attributes of the Price List API.
+type priceQuery struct {
+	ServiceCode string
+	Filters     map[string]string
+	Unit        string
+}
+
+func (q priceQuery) key() string {
+	filters := []string{}
+	for name, value := range q.Filters {
+		filters = append(filters, fmt.Sprintf("%s=%s", name, value))
+	}
+	sort.Strings(filters)
+	return fmt.Sprintf("%s/%s/%s", q.ServiceCode, strings.Join(filters, ","), q.Unit)
+}
+
+// costComponent is a billed part of a resource, e.g. the compute or the
+// storage of a database.
+type costComponent struct {
+	Name     string
+	Query    priceQuery
+	Quantity float64
+}
+
+var (
+	rdsPricingEngines = map[string]string{
+		"mysql":             "MySQL",
+		"postgres":          "PostgreSQL",
+		"mariadb":           "MariaDB",
+		"aurora-mysql":      "Aurora MySQL",
+		"aurora-postgresql": "Aurora PostgreSQL",
+	}
+	rdsPricingVolumeTypes = map[string]string{
+		"gp2":      "General Purpose",
+		"gp3":      "General Purpose-GP3",
+		"io1":      "Provisioned IOPS",
+		"standard": "Magnetic",
+	}
+	elbPricingFamilies = map[string]string{
+		"application": "Load Balancer-Application",
+		"network":     "Load Balancer-Network",
+		"gateway":     "Load Balancer-Gateway",
+	}
+	ec2PricingTenancies = map[string]string{
+		"default":   "Shared",
+		"dedicated": "Dedicated",
+		"host":      "Host",
+	}
+)
+
+func firstMetadataValue(metadata interface{}, path string) string {
+	values := metadataValues(metadata, strings.Split(path, "."))
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+// costComponents returns the billed components of the resource, none when it
+// is stopped, or nil when its type is not supported.
+func costComponents(resource Resource, metadata map[string]interface{}) []costComponent {
+	hourly := func(serviceCode string, filters map[string]string) costComponent {
+		filters["regionCode"] = resource.Region
+		return costComponent{"hours", priceQuery{serviceCode, filters, "Hrs"}, hoursPerMonth}
+	}
+
+	switch fmt.Sprintf("%s:%s", resource.Service, resource.Type) {
+	case "ec2:instance":
+		if firstMetadataValue(metadata, "State.Name") != "running" {
+			return []costComponent{}
+		}
+		operatingSystem := "Linux"
+		if firstMetadataValue(metadata, "Platform") == "windows" {
+			operatingSystem = "Windows"
+		}
+		tenancy := ec2PricingTenancies[firstMetadataValue(metadata, "Placement.Tenancy")]
+		if tenancy == "" {
+			tenancy = "Shared"
+		}
+		return []costComponent{hourly("AmazonEC2", map[string]string{
+			"instanceType":    firstMetadataValue(metadata, "InstanceType"),
+			"operatingSystem": operatingSystem,
+			"tenancy":         tenancy,
+			"preInstalledSw":  "NA",
+			"capacitystatus":  "Used",
+			"licenseModel":    "No License required",
+		})}
+
+	case "ec2:nat-gateway":
+		if firstMetadataValue(metadata, "State") != "available" {
+			return []costComponent{}
+		}
+		return []costComponent{hourly("AmazonEC2", map[string]string{"productFamily": "NAT Gateway"})}
+
+	case "elasticloadbalancing:loadbalancer":
+		family, ok := elbPricingFamilies[firstMetadataValue(metadata, "Type")]
+		if !ok {
+			return nil
+		}
+		return []costComponent{hourly("AWSELB", map[string]string{"productFamily": family})}
+
+	case "rds:db-instance":
+		engine, ok := rdsPricingEngines[firstMetadataValue(metadata, "Engine")]
+		if !ok {
+			return nil
+		}
+		if firstMetadataValue(metadata, "DBInstanceStatus") == "stopped" {
+			return []costComponent{}
+		}
+		deploymentOption := "Single-AZ"
+		if multiAZ, _ := metadata["MultiAZ"].(bool); multiAZ {
+			deploymentOption = "Multi-AZ"
+		}
+
+		components := []costComponent{hourly("AmazonRDS", map[string]string{
+			"instanceType":     firstMetadataValue(metadata, "DBInstanceClass"),
+			"databaseEngine":   engine,
+			"deploymentOption": deploymentOption,
+		})}
+
+		// the storage of aurora is billed by the cluster
+		volumeType, ok := rdsPricingVolumeTypes[firstMetadataValue(metadata, "StorageType")]
+		storage, _ := metadata["AllocatedStorage"].(float64)
+		if ok && storage > 0 && !strings.HasPrefix(engine, "Aurora") {
+			components = append(components, costComponent{"storage", priceQuery{"AmazonRDS", map[string]string{
+				"regionCode":       resource.Region,
+				"productFamily":    "Database Storage",
+				"volumeType":       volumeType,
+				"databaseEngine":   engine,
+				"deploymentOption": deploymentOption,
+			}, "GB-Mo"}, storage})
+		}
+		return components
+	}
+	return nil
+}
+
+// onDemandPrice returns the price in USD of the unit from the products of
+// the Price List API.
+func onDemandPrice(priceList []aws.JSONValue, unit string) (float64, bool) {
+	for _, product := range priceList {
+		data, err := json.Marshal(product)
+		if err != nil {
+			continue
+		}
+		parsed := struct {
+			Terms struct {
+				OnDemand map[string]struct {
+					PriceDimensions map[string]struct {
+						Unit         string            `json:"unit"`
+						PricePerUnit map[string]string `json:"pricePerUnit"`
+					} `json:"priceDimensions"`
+				} `json:"OnDemand"`
+			} `json:"terms"`
+		}{}
+		if json.Unmarshal(data, &parsed) != nil {
+			continue
+		}
+
+		for _, term := range parsed.Terms.OnDemand {
+			for _, dimension := range term.PriceDimensions {
+				if dimension.Unit != unit {
+					continue
+				}
+				price, err := strconv.ParseFloat(dimension.PricePerUnit["USD"], 64)
+				if err == nil && price > 0 {
+					return price, true
+				}
+			}
+		}
+	}
+	return 0, false
+}
+
+type priceCache struct {
+	client *pricing.Pricing
+	prices map[string]*float64
+}
+
+// Price returns the price of the query, nil when there is no product matching
+// it.
+func (c *priceCache) Price(query priceQuery) (*float64, error) {
+	key := query.key()
+	if price, ok := c.prices[key]; ok {
+		return price, nil
+	}
+
+	input := &pricing.GetProductsInput{
+		ServiceCode:   aws.String(query.ServiceCode),
+		FormatVersion: aws.String("aws_v1"),
+	}
+	for name, value := range query.Filters {
+		input.Filters = append(input.Filters, &pricing.Filter{
+			Type:  aws.String(pricing.FilterTypeTermMatch),
+			Field: aws.String(name),
+			Value: aws.String(value),
+		})
+	}
+
+	priceList := []aws.JSONValue{}
+	err := c.client.GetProductsPages(input, func(page *pricing.GetProductsOutput, lastPage bool) bool {
+		priceList = append(priceList, page.PriceList...)
+		return true
+	})
+	// the failed queries are not retried to report their error once
+	c.prices[key] = nil
+	if err != nil {
+		return nil, err
+	}
+	if price, ok := onDemandPrice(priceList, query.Unit); ok {
+		c.prices[key] = &price
+	}
+	return c.prices[key], nil
+}
+
+func roundCents(value float64) float64 {
+	return math.Round(value*100) / 100
+}
+
+// AttachMonthlyCost adds the estimated monthly cost of the running instances,
+// NAT gateways, load balancers and RDS instances found in resources, from
+// their on-demand price in the Price List API. Discounts, savings plans,
+// reservations and usage based charges (data processed, I/O...) are not
+// included. When tagKey is set, the actual cost of the last month of the
+// resources with the same value of the tag in the account is added from Cost
+// Explorer.
+func AttachMonthlyCost(accounts []*Account, resources []Resource, tagKey string) []error {
+	errors := []error{}
+	if len(resources) == 0 {
+		return errors
+	}
+
+	var session *Session
+	for _, account := range accounts {
+		if len(account.Sessions) > 0 {
+			session = account.Sessions[0]
+			break
+		}
+	}
+	if session == nil {
+		return errors
+	}
+
+	cache := &priceCache{
+		client: pricing.New(session.Session, session.Config.Copy(&aws.Config{Region: aws.String(pricingRegion)})),
+		prices: map[string]*float64{},
+	}
+	for _, resource := range resources {
+		var metadata map[string]interface{}
+		data, err := json.Marshal(resource.Metadata)
+		if err != nil || json.Unmarshal(data, &metadata) != nil {
+			continue
+		}
+
+		components := costComponents(resource, metadata)
+		if components == nil {
+			continue
+		}
+
+		estimated := 0.0
+		items := []map[string]interface{}{}
+		missing := []string{}
+		for _, component := range components {
+			price, err := cache.Price(component.Query)
+			if err != nil {
+				errors = append(errors, fmt.Errorf("failed to get the price of %s %s: %s", resource.Type, resource.ID, err))
+				missing = append(missing, component.Name)
+				continue
+			}
+			if price == nil {
+				missing = append(missing, component.Name)
+				continue
+			}
+			cost := *price * component.Quantity
+			estimated += cost
+			items = append(items, map[string]interface{}{
+				"Name":      component.Name,
+				"Unit":      component.Query.Unit,
+				"Quantity":  component.Quantity,
+				"UnitPrice": *price,
+				"Cost":      roundCents(cost),
+			})
+		}
+
+		resource.Metadata["MonthlyCost"] = map[string]interface{}{
+			"Estimated":  roundCents(estimated),
+			"Currency":   "USD",
+			"Components": items,
+			"Missing":    missing,
+		}
+	}
+
+	if tagKey != "" {
+		errors = append(errors, attachTagCost(accounts, resources, tagKey)...)
+	}
+	return errors
+}
+
+// tagCosts returns the cost of each value of the tag in the account from the
+// groups of Cost Explorer, the resources without the tag have an empty value.
+func tagCosts(results []*costexplorer.ResultByTime, tagKey, accountID string) map[string]float64 {
+	costs := map[string]float64{}
+	for _, result := range results {
+		for _, group := range result.Groups {
+			if len(group.Keys) != 2 || aws.StringValue(group.Keys[1]) != accountID {
+				continue
+			}
+			value := strings.TrimPrefix(aws.StringValue(group.Keys[0]), tagKey+"$")
+			metric, ok := group.Metrics[costexplorer.MetricUnblendedCost]
+			if !ok {
+				continue
+			}
+			cost, err := strconv.ParseFloat(aws.StringValue(metric.Amount), 64)
+			if err == nil {
+				costs[value] += cost
+			}
+		}
+	}
+	return costs
+}
+
+// attachTagCost adds the cost of the last month of the tag value of the
+// resources in their account. The tag needs to be activated as a cost
+// allocation tag.
+func attachTagCost(accounts []*Account, resources []Resource, tagKey string) []error {
+	errors := []error{}
+
+	now := time.Now().UTC()
+	end := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	start := end.AddDate(0, -1, 0)
+
+	seen := map[string]bool{}
+	for _, account := range accounts {
+		for _, session := range account.Sessions {
+			if seen[session.AccountID] {
+				continue
+			}
+			seen[session.AccountID] = true
+
+			client := costexplorer.New(session.Session, session.Config.Copy(&aws.Config{Region: aws.String(pricingRegion)}))
+			input := &costexplorer.GetCostAndUsageInput{
+				TimePeriod: &costexplorer.DateInterval{
+					Start: aws.String(start.Format("2006-01-02")),
+					End:   aws.String(end.Format("2006-01-02")),
+				},
+				Granularity: aws.String(costexplorer.GranularityMonthly),
+				Metrics:     aws.StringSlice([]string{costexplorer.MetricUnblendedCost}),
+				GroupBy: []*costexplorer.GroupDefinition{
+					{Type: aws.String(costexplorer.GroupDefinitionTypeTag), Key: aws.String(tagKey)},
+					{Type: aws.String(costexplorer.GroupDefinitionTypeDimension), Key: aws.String(costexplorer.DimensionLinkedAccount)},
+				},
+			}
+
+			results := []*costexplorer.ResultByTime{}
+			for {
+				page, err := client.GetCostAndUsage(input)
+				if err != nil {
+					errors = append(errors, fmt.Errorf("failed to get the costs of %s: %s", session.AccountID, err))
+					break
+				}
+				results = append(results, page.ResultsByTime...)
+				if page.NextPageToken == nil {
+					break
+				}
+				input.NextPageToken = page.NextPageToken
+			}
+
+			costs := tagCosts(results, tagKey, session.AccountID)
+			for _, resource := range resources {
+				if resource.AccountID != session.AccountID {
+					continue
+				}
+				value, ok := resource.Tags()[tagKey]
+				if !ok {
+					continue
+				}
+				cost, ok := costs[value]
+				if !ok {
+					continue
+				}
+
+				monthlyCost, ok := resource.Metadata["MonthlyCost"].(map[string]interface{})
+				if !ok {
+					monthlyCost = map[string]interface{}{"Currency": "USD"}
+					resource.Metadata["MonthlyCost"] = monthlyCost
+				}
+				monthlyCost["Actual"] = map[string]interface{}{
+					"TagKey":   tagKey,
+					"TagValue": value,
+					"Period":   start.Format("2006-01"),
+					"Cost":     roundCents(cost),
+				}
+			}
+		}
+	}
+	return errors
+}
@@ -0,0 +1,116 @@
+package resources
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/costexplorer"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOnDemandPrice(t *testing.T) {
+	priceList := []aws.JSONValue{
+		{
+			"product": map[string]interface{}{"sku": "ABC"},
+			"terms": map[string]interface{}{
+				"OnDemand": map[string]interface{}{
+					"ABC.JRTCKXETXF": map[string]interface{}{
+						"priceDimensions": map[string]interface{}{
+							"ABC.JRTCKXETXF.6YS6EN2CT7": map[string]interface{}{
+								"unit":         "Hrs",
+								"pricePerUnit": map[string]interface{}{"USD": "0.0960000000"},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	price, ok := onDemandPrice(priceList, "Hrs")
+	require.True(t, ok)
+	require.InDelta(t, 0.096, price, 1e-9)
+
+	_, ok = onDemandPrice(priceList, "GB-Mo")
+	require.False(t, ok)
+	_, ok = onDemandPrice([]aws.JSONValue{}, "Hrs")
+	require.False(t, ok)
+}
+
+func TestCostComponents(t *testing.T) {
+	instance := Resource{Service: "ec2", Type: "instance", Region: "eu-west-1"}
+	components := costComponents(instance, map[string]interface{}{
+		"InstanceType": "m5.large",
+		"State":        map[string]interface{}{"Name": "running"},
+		"Placement":    map[string]interface{}{"Tenancy": "default"},
+	})
+	require.Len(t, components, 1)
+	require.Equal(t, "AmazonEC2", components[0].Query.ServiceCode)
+	require.Equal(t, "m5.large", components[0].Query.Filters["instanceType"])
+	require.Equal(t, "Linux", components[0].Query.Filters["operatingSystem"])
+	require.Equal(t, "Shared", components[0].Query.Filters["tenancy"])
+	require.Equal(t, "eu-west-1", components[0].Query.Filters["regionCode"])
+	require.Equal(t, float64(hoursPerMonth), components[0].Quantity)
+
+	// stopped instances are not billed
+	require.Equal(t, []costComponent{}, costComponents(instance, map[string]interface{}{
+		"InstanceType": "m5.large",
+		"State":        map[string]interface{}{"Name": "stopped"},
+	}))
+
+	database := Resource{Service: "rds", Type: "db-instance", Region: "eu-west-1"}
+	components = costComponents(database, map[string]interface{}{
+		"Engine":           "postgres",
+		"DBInstanceClass":  "db.t3.medium",
+		"DBInstanceStatus": "available",
+		"MultiAZ":          true,
+		"StorageType":      "gp3",
+		"AllocatedStorage": float64(100),
+	})
+	require.Len(t, components, 2)
+	require.Equal(t, "Multi-AZ", components[0].Query.Filters["deploymentOption"])
+	require.Equal(t, "PostgreSQL", components[0].Query.Filters["databaseEngine"])
+	require.Equal(t, "storage", components[1].Name)
+	require.Equal(t, "GB-Mo", components[1].Query.Unit)
+	require.Equal(t, "General Purpose-GP3", components[1].Query.Filters["volumeType"])
+	require.Equal(t, float64(100), components[1].Quantity)
+
+	// the storage of aurora is billed by the cluster
+	components = costComponents(database, map[string]interface{}{
+		"Engine":           "aurora-mysql",
+		"DBInstanceClass":  "db.r6g.large",
+		"StorageType":      "aurora",
+		"AllocatedStorage": float64(1),
+	})
+	require.Len(t, components, 1)
+
+	require.Nil(t, costComponents(database, map[string]interface{}{"Engine": "oracle-ee"}))
+	require.Nil(t, costComponents(Resource{Service: "s3", Type: "bucket"}, map[string]interface{}{}))
+}
+
+func TestPriceQueryKey(t *testing.T) {
+	first := priceQuery{"AmazonEC2", map[string]string{"a": "1", "b": "2"}, "Hrs"}
+	second := priceQuery{"AmazonEC2", map[string]string{"b": "2", "a": "1"}, "Hrs"}
+	require.Equal(t, first.key(), second.key())
+	require.NotEqual(t, first.key(), priceQuery{"AmazonEC2", map[string]string{"a": "1"}, "Hrs"}.key())
+}
+
+func TestTagCosts(t *testing.T) {
+	group := func(tag, accountID, amount string) *costexplorer.Group {
+		return &costexplorer.Group{
+			Keys: aws.StringSlice([]string{tag, accountID}),
+			Metrics: map[string]*costexplorer.MetricValue{
+				costexplorer.MetricUnblendedCost: {Amount: aws.String(amount), Unit: aws.String("USD")},
+			},
+		}
+	}
+
+	costs := tagCosts([]*costexplorer.ResultByTime{
+		{Groups: []*costexplorer.Group{
+			group("team$payments", "123456789012", "1834.12"),
+			group("team$", "123456789012", "12.5"),
+			group("team$payments", "210987654321", "99"),
+		}},
+	}, "team", "123456789012")
+	require.Equal(t, map[string]float64{"payments": 1834.12, "": 12.5}, costs)
+}
@@ -0,0 +1,115 @@
+package resources
+
+import (
+	"encoding/base64"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/mq"
+	"github.com/fatih/structs"
+)
+
+var (
+	MQService = Service{
+		Name: "mq",
+		Reports: map[string]Report{
+			"brokers":        MQListBrokers,
+			"configurations": MQListConfigurations,
+		},
+	}
+)
+
+func MQListBrokers(session *Session) *ReportResult {
+	client := mq.New(session.Session, session.Config)
+
+	result := &ReportResult{}
+	input := &mq.ListBrokersInput{}
+	for {
+		page, err := client.ListBrokers(input)
+		if err != nil {
+			result.Error = err
+			return result
+		}
+
+		for _, summary := range page.BrokerSummaries {
+			// includes PubliclyAccessible and the broker users
+			broker, err := client.DescribeBroker(&mq.DescribeBrokerInput{BrokerId: summary.BrokerId})
+			if err != nil {
+				result.Error = err
+				return result
+			}
+
+			result.Resources = append(result.Resources, Resource{
+				ID:        *broker.BrokerId,
+				ARN:       *broker.BrokerArn,
+				AccountID: session.AccountID,
+				Service:   "mq",
+				Type:      "broker",
+				Region:    *session.Config.Region,
+				Metadata:  structs.Map(broker),
+			})
+		}
+
+		if page.NextToken == nil {
+			break
+		}
+		input.NextToken = page.NextToken
+	}
+
+	return result
+}
+
+// MQListConfigurations lists the broker configurations with the decoded data
+// (XML for ActiveMQ) of their latest revision.
+func MQListConfigurations(session *Session) *ReportResult {
+	client := mq.New(session.Session, session.Config)
+
+	result := &ReportResult{}
+	input := &mq.ListConfigurationsInput{}
+	for {
+		page, err := client.ListConfigurations(input)
+		if err != nil {
+			result.Error = err
+			return result
+		}
+
+		for _, configuration := range page.Configurations {
+			resource := Resource{
+				ID:        *configuration.Id,
+				ARN:       aws.StringValue(configuration.Arn),
+				AccountID: session.AccountID,
+				Service:   "mq",
+				Type:      "configuration",
+				Region:    *session.Config.Region,
+				Metadata:  structs.Map(configuration),
+			}
+
+			if configuration.LatestRevision != nil && configuration.LatestRevision.Revision != nil {
+				revision, err := client.DescribeConfigurationRevision(&mq.DescribeConfigurationRevisionInput{
+					ConfigurationId:       configuration.Id,
+					ConfigurationRevision: aws.String(strconv.FormatInt(*configuration.LatestRevision.Revision, 10)),
+				})
+				if err != nil {
+					result.Error = err
+					return result
+				}
+
+				data, err := base64.StdEncoding.DecodeString(aws.StringValue(revision.Data))
+				if err != nil {
+					result.Error = err
+					return result
+				}
+				resource.Metadata["Data"] = string(data)
+			}
+
+			result.Resources = append(result.Resources, resource)
+		}
+
+		if page.NextToken == nil {
+			break
+		}
+		input.NextToken = page.NextToken
+	}
+
+	return result
+}
@@ -0,0 +1,165 @@
+package resources
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/kafka"
+	"github.com/fatih/structs"
+)
+
+var (
+	MSKService = Service{
+		Name: "msk",
+		Reports: map[string]Report{
+			"clusters":       MSKListClusters,
+			"configurations": MSKListConfigurations,
+		},
+	}
+)
+
+func MSKListClusters(session *Session) *ReportResult {
+	client := kafka.New(session.Session, session.Config)
+
+	result := &ReportResult{}
+	err := client.ListClustersPages(&kafka.ListClustersInput{},
+		func(page *kafka.ListClustersOutput, lastPage bool) bool {
+			for _, cluster := range page.ClusterInfoList {
+				resource := Resource{
+					ID:        *cluster.ClusterName,
+					ARN:       *cluster.ClusterArn,
+					AccountID: session.AccountID,
+					Service:   "kafka",
+					Type:      "cluster",
+					Region:    *session.Config.Region,
+					Metadata:  structs.Map(cluster),
+				}
+
+				brokers, err := client.GetBootstrapBrokers(&kafka.GetBootstrapBrokersInput{ClusterArn: cluster.ClusterArn})
+				if err != nil {
+					result.Error = err
+					return false
+				}
+				resource.Metadata["BootstrapBrokers"] = structs.Map(brokers)
+
+				nodes := []interface{}{}
+				err = client.ListNodesPages(&kafka.ListNodesInput{ClusterArn: cluster.ClusterArn},
+					func(page *kafka.ListNodesOutput, lastPage bool) bool {
+						for _, node := range page.NodeInfoList {
+							nodes = append(nodes, structs.Map(node))
+						}
+						return true
+					})
+				if err != nil {
+					result.Error = err
+					return false
+				}
+				resource.Metadata["Nodes"] = nodes
+				resource.Metadata["Security"] = mskSecuritySummary(cluster)
+
+				result.Resources = append(result.Resources, resource)
+			}
+
+			return true
+		})
+
+	if result.Error != nil {
+		return result
+	}
+	result.Error = err
+	return result
+}
+
+// mskSecuritySummary flattens the encryption and authentication settings.
+func mskSecuritySummary(cluster *kafka.ClusterInfo) map[string]interface{} {
+	summary := map[string]interface{}{}
+
+	if encryption := cluster.EncryptionInfo; encryption != nil {
+		if encryption.EncryptionAtRest != nil {
+			summary["EncryptionAtRestKmsKeyId"] = aws.StringValue(encryption.EncryptionAtRest.DataVolumeKMSKeyId)
+		}
+		if encryption.EncryptionInTransit != nil {
+			summary["EncryptionInTransitClientBroker"] = aws.StringValue(encryption.EncryptionInTransit.ClientBroker)
+			summary["EncryptionInTransitInCluster"] = aws.BoolValue(encryption.EncryptionInTransit.InCluster)
+		}
+	}
+
+	authentication := []string{}
+	if clientAuthentication := cluster.ClientAuthentication; clientAuthentication != nil {
+		if sasl := clientAuthentication.Sasl; sasl != nil {
+			if sasl.Iam != nil && aws.BoolValue(sasl.Iam.Enabled) {
+				authentication = append(authentication, "SASL/IAM")
+			}
+			if sasl.Scram != nil && aws.BoolValue(sasl.Scram.Enabled) {
+				authentication = append(authentication, "SASL/SCRAM")
+			}
+		}
+		if tls := clientAuthentication.Tls; tls != nil && aws.BoolValue(tls.Enabled) {
+			authentication = append(authentication, "TLS")
+		}
+		if unauthenticated := clientAuthentication.Unauthenticated; unauthenticated != nil && aws.BoolValue(unauthenticated.Enabled) {
+			authentication = append(authentication, "Unauthenticated")
+		}
+	}
+	summary["AuthenticationMethods"] = authentication
+
+	return summary
+}
+
+// MSKListConfigurations lists the configurations with the server properties
+// of each revision.
+func MSKListConfigurations(session *Session) *ReportResult {
+	client := kafka.New(session.Session, session.Config)
+
+	result := &ReportResult{}
+	err := client.ListConfigurationsPages(&kafka.ListConfigurationsInput{},
+		func(page *kafka.ListConfigurationsOutput, lastPage bool) bool {
+			for _, configuration := range page.Configurations {
+				resource := Resource{
+					ID:        *configuration.Name,
+					ARN:       *configuration.Arn,
+					AccountID: session.AccountID,
+					Service:   "kafka",
+					Type:      "configuration",
+					Region:    *session.Config.Region,
+					Metadata:  structs.Map(configuration),
+				}
+
+				revisions := []interface{}{}
+				err := client.ListConfigurationRevisionsPages(&kafka.ListConfigurationRevisionsInput{Arn: configuration.Arn},
+					func(page *kafka.ListConfigurationRevisionsOutput, lastPage bool) bool {
+						for _, revision := range page.Revisions {
+							described, err := client.DescribeConfigurationRevision(&kafka.DescribeConfigurationRevisionInput{
+								Arn:      configuration.Arn,
+								Revision: revision.Revision,
+							})
+							if err != nil {
+								result.Error = err
+								return false
+							}
+
+							metadata := structs.Map(revision)
+							metadata["ServerProperties"] = string(described.ServerProperties)
+							revisions = append(revisions, metadata)
+						}
+						return true
+					})
+				if result.Error != nil {
+					return false
+				}
+				if err != nil {
+					result.Error = err
+					return false
+				}
+				resource.Metadata["Revisions"] = revisions
+
+				result.Resources = append(result.Resources, resource)
+			}
+
+			return true
+		})
+
+	if result.Error != nil {
+		return result
+	}
+	result.Error = err
+	return result
+}
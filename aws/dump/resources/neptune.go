@@ -0,0 +1,209 @@
+package resources
+
+import (
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/neptune"
+	"github.com/fatih/structs"
+)
+
+var (
+	NeptuneService = Service{
+		Name: "neptune",
+		Reports: map[string]Report{
+			"db-clusters":                 NeptuneListDBClusters,
+			"db-instances":                NeptuneListDBInstances,
+			"db-cluster-snapshots":        NeptuneListDBClusterSnapshots,
+			"db-cluster-parameter-groups": NeptuneListDBClusterParameterGroups,
+			"db-parameter-groups":         NeptuneListDBParameterGroups,
+		},
+	}
+)
+
+// The neptune API returns the RDS resources of every engine, only keep the neptune ones.
+func neptuneEngineFilter() []*neptune.Filter {
+	return []*neptune.Filter{
+		{
+			Name:   aws.String("engine"),
+			Values: aws.StringSlice([]string{"neptune"}),
+		},
+	}
+}
+
+func NeptuneListDBClusters(session *Session) *ReportResult {
+	client := neptune.New(session.Session, session.Config)
+
+	result := &ReportResult{}
+	input := &neptune.DescribeDBClustersInput{Filters: neptuneEngineFilter()}
+	for {
+		page, err := client.DescribeDBClusters(input)
+		if err != nil {
+			result.Error = err
+			return result
+		}
+
+		for _, resource := range page.DBClusters {
+			result.Resources = append(result.Resources, Resource{
+				ID:        *resource.DBClusterIdentifier,
+				ARN:       *resource.DBClusterArn,
+				AccountID: session.AccountID,
+				Service:   "neptune",
+				Type:      "db-cluster",
+				Region:    *session.Config.Region,
+				Metadata:  structs.Map(resource),
+			})
+		}
+
+		if page.Marker == nil {
+			break
+		}
+		input.Marker = page.Marker
+	}
+
+	return result
+}
+
+func NeptuneListDBInstances(session *Session) *ReportResult {
+	client := neptune.New(session.Session, session.Config)
+
+	result := &ReportResult{}
+	input := &neptune.DescribeDBInstancesInput{Filters: neptuneEngineFilter()}
+	for {
+		page, err := client.DescribeDBInstances(input)
+		if err != nil {
+			result.Error = err
+			return result
+		}
+
+		for _, resource := range page.DBInstances {
+			result.Resources = append(result.Resources, Resource{
+				ID:        *resource.DBInstanceIdentifier,
+				ARN:       *resource.DBInstanceArn,
+				AccountID: session.AccountID,
+				Service:   "neptune",
+				Type:      "db-instance",
+				Region:    *session.Config.Region,
+				Metadata:  structs.Map(resource),
+			})
+		}
+
+		if page.Marker == nil {
+			break
+		}
+		input.Marker = page.Marker
+	}
+
+	return result
+}
+
+func NeptuneListDBClusterSnapshots(session *Session) *ReportResult {
+	client := neptune.New(session.Session, session.Config)
+
+	result := &ReportResult{}
+	input := &neptune.DescribeDBClusterSnapshotsInput{}
+	for {
+		page, err := client.DescribeDBClusterSnapshots(input)
+		if err != nil {
+			result.Error = err
+			return result
+		}
+
+		for _, resource := range page.DBClusterSnapshots {
+			// filters are not supported by this API
+			if aws.StringValue(resource.Engine) != "neptune" {
+				continue
+			}
+			result.Resources = append(result.Resources, Resource{
+				ID:        *resource.DBClusterSnapshotIdentifier,
+				ARN:       *resource.DBClusterSnapshotArn,
+				AccountID: session.AccountID,
+				Service:   "neptune",
+				Type:      "db-cluster-snapshot",
+				Region:    *session.Config.Region,
+				Metadata:  structs.Map(resource),
+			})
+		}
+
+		if page.Marker == nil {
+			break
+		}
+		input.Marker = page.Marker
+	}
+
+	return result
+}
+
+func NeptuneListDBClusterParameterGroups(session *Session) *ReportResult {
+	client := neptune.New(session.Session, session.Config)
+
+	result := &ReportResult{}
+	input := &neptune.DescribeDBClusterParameterGroupsInput{}
+	for {
+		page, err := client.DescribeDBClusterParameterGroups(input)
+		if err != nil {
+			result.Error = err
+			return result
+		}
+
+		for _, resource := range page.DBClusterParameterGroups {
+			// filters are not supported by this API
+			if !strings.HasPrefix(aws.StringValue(resource.DBParameterGroupFamily), "neptune") {
+				continue
+			}
+			result.Resources = append(result.Resources, Resource{
+				ID:        *resource.DBClusterParameterGroupArn,
+				ARN:       *resource.DBClusterParameterGroupArn,
+				AccountID: session.AccountID,
+				Service:   "neptune",
+				Type:      "db-cluster-parameter-group",
+				Region:    *session.Config.Region,
+				Metadata:  structs.Map(resource),
+			})
+		}
+
+		if page.Marker == nil {
+			break
+		}
+		input.Marker = page.Marker
+	}
+
+	return result
+}
+
+func NeptuneListDBParameterGroups(session *Session) *ReportResult {
+	client := neptune.New(session.Session, session.Config)
+
+	result := &ReportResult{}
+	input := &neptune.DescribeDBParameterGroupsInput{}
+	for {
+		page, err := client.DescribeDBParameterGroups(input)
+		if err != nil {
+			result.Error = err
+			return result
+		}
+
+		for _, resource := range page.DBParameterGroups {
+			// filters are not supported by this API
+			if !strings.HasPrefix(aws.StringValue(resource.DBParameterGroupFamily), "neptune") {
+				continue
+			}
+			result.Resources = append(result.Resources, Resource{
+				ID:        *resource.DBParameterGroupArn,
+				ARN:       *resource.DBParameterGroupArn,
+				AccountID: session.AccountID,
+				Service:   "neptune",
+				Type:      "db-parameter-group",
+				Region:    *session.Config.Region,
+				Metadata:  structs.Map(resource),
+			})
+		}
+
+		if page.Marker == nil {
+			break
+		}
+		input.Marker = page.Marker
+	}
+
+	return result
+}
@@ -0,0 +1,78 @@
+package resources
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/organizations"
+	"github.com/aws/aws-sdk-go/service/sts"
+	"github.com/hamstah/awstools/common"
+)
+
+// OrganizationConfig adds the active accounts of the organization to the
+// accounts to dump, using RoleName in each account.
+type OrganizationConfig struct {
+	RoleName          string   `json:"role_name"`
+	Regions           []string `json:"regions"`
+	ExcludeAccountIDs []string `json:"exclude_account_ids"`
+}
+
+// NewAccountsFromOrganization lists the active accounts of the organization
+// of the current credentials. The account of the current credentials (e.g.
+// the management account) uses them directly as the role usually doesn't
+// exist there.
+func NewAccountsFromOrganization(config *OrganizationConfig) ([]*Account, error) {
+	if config.RoleName == "" {
+		return nil, fmt.Errorf("the organization role name is empty")
+	}
+	if len(config.Regions) == 0 {
+		return nil, fmt.Errorf("the organization regions are empty")
+	}
+
+	// organizations is only available in us-east-1
+	sess, conf := common.OpenSession(&common.SessionFlags{
+		RoleArn:         aws.String(""),
+		RoleExternalID:  aws.String(""),
+		RolePolicy:      aws.String(""),
+		Region:          aws.String("us-east-1"),
+		RoleSessionName: aws.String(""),
+
+		MFASerialNumber: aws.String(""),
+		MFATokenCode:    aws.String(""),
+	})
+
+	identity, err := sts.New(sess, conf).GetCallerIdentity(&sts.GetCallerIdentityInput{})
+	if err != nil {
+		return nil, err
+	}
+
+	excluded := map[string]bool{}
+	for _, accountID := range config.ExcludeAccountIDs {
+		excluded[accountID] = true
+	}
+
+	accounts := []*Account{}
+	err = organizations.New(sess, conf).ListAccountsPages(&organizations.ListAccountsInput{},
+		func(page *organizations.ListAccountsOutput, lastPage bool) bool {
+			for _, organizationAccount := range page.Accounts {
+				accountID := *organizationAccount.Id
+				if aws.StringValue(organizationAccount.Status) != organizations.AccountStatusActive || excluded[accountID] {
+					continue
+				}
+
+				account := &Account{
+					Regions: config.Regions,
+				}
+				if accountID != *identity.Account {
+					account.RoleARN = fmt.Sprintf("arn:aws:iam::%s:role/%s", accountID, config.RoleName)
+				}
+				accounts = append(accounts, account)
+			}
+			return true
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	return accounts, nil
+}
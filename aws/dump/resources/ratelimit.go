@@ -0,0 +1,210 @@
+package resources
+
+import (
+	"math"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/request"
+)
+
+var (
+	// RateLimits limits the API calls of the sessions opened by OpenSessions
+	// when set.
+	RateLimits *RateLimiter
+)
+
+const (
+	// first pause of a service after a throttling error, doubled on each
+	// consecutive one
+	throttlePause    = 1 * time.Second
+	throttleMaxPause = 30 * time.Second
+)
+
+// RateLimiter is a token bucket per account and service. Limits are the
+// requests per second of the services, by their lowercase id without spaces
+// like iam, ec2 or cloudwatch, Default the one of the other services, 0 for no
+// limit. When a request is throttled the service is paused and its rate
+// halved, the rate then goes back to the limit as the requests succeed.
+type RateLimiter struct {
+	Default float64
+	Limits  map[string]float64
+
+	mutex     sync.Mutex
+	buckets   map[string]*rateBucket
+	throttled int64
+
+	now   func() time.Time
+	sleep func(time.Duration)
+}
+
+func NewRateLimiter(defaultLimit float64, limits map[string]float64) *RateLimiter {
+	if limits == nil {
+		limits = map[string]float64{}
+	}
+	return &RateLimiter{
+		Default: defaultLimit,
+		Limits:  limits,
+		buckets: map[string]*rateBucket{},
+		now:     time.Now,
+		sleep:   time.Sleep,
+	}
+}
+
+// Attach limits the requests of the handlers, the buckets of the services are
+// shared by the handlers of the same scope.
+func (l *RateLimiter) Attach(handlers *request.Handlers, scope string) {
+	handlers.Sign.PushFrontNamed(request.NamedHandler{
+		Name: "awstools.RateLimitWait",
+		Fn: func(r *request.Request) {
+			l.Wait(scope, serviceName(r))
+		},
+	})
+	handlers.Retry.PushFrontNamed(request.NamedHandler{
+		Name: "awstools.RateLimitThrottled",
+		Fn: func(r *request.Request) {
+			if request.IsErrorThrottle(r.Error) {
+				l.Throttled(scope, serviceName(r))
+			}
+		},
+	})
+	handlers.Complete.PushBackNamed(request.NamedHandler{
+		Name: "awstools.RateLimitSucceeded",
+		Fn: func(r *request.Request) {
+			if r.Error == nil {
+				l.Succeeded(scope, serviceName(r))
+			}
+		},
+	})
+}
+
+func serviceName(r *request.Request) string {
+	if r.ClientInfo.ServiceID != "" {
+		return strings.ToLower(strings.Replace(r.ClientInfo.ServiceID, " ", "", -1))
+	}
+	return r.ClientInfo.ServiceName
+}
+
+// Throttles is the number of throttling errors seen.
+func (l *RateLimiter) Throttles() int {
+	if l == nil {
+		return 0
+	}
+	return int(atomic.LoadInt64(&l.throttled))
+}
+
+// Wait blocks until the service can be called.
+func (l *RateLimiter) Wait(scope, service string) {
+	if delay := l.bucket(scope, service).reserve(l.now()); delay > 0 {
+		l.sleep(delay)
+	}
+}
+
+func (l *RateLimiter) Throttled(scope, service string) {
+	atomic.AddInt64(&l.throttled, 1)
+	l.bucket(scope, service).throttled(l.now())
+}
+
+func (l *RateLimiter) Succeeded(scope, service string) {
+	l.bucket(scope, service).succeeded()
+}
+
+func (l *RateLimiter) bucket(scope, service string) *rateBucket {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	key := scope + "/" + service
+	bucket, ok := l.buckets[key]
+	if !ok {
+		limit, ok := l.Limits[service]
+		if !ok {
+			limit = l.Default
+		}
+		bucket = &rateBucket{limit: limit, rate: limit, tokens: 1}
+		l.buckets[key] = bucket
+	}
+	return bucket
+}
+
+type rateBucket struct {
+	mutex sync.Mutex
+
+	// limit is the configured rate, rate the current one after throttling
+	limit  float64
+	rate   float64
+	tokens float64
+	last   time.Time
+
+	pausedUntil time.Time
+	throttles   int
+}
+
+// reserve takes a token and returns how long to wait before using it.
+func (b *rateBucket) reserve(now time.Time) time.Duration {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	delay := time.Duration(0)
+	if now.Before(b.pausedUntil) {
+		delay = b.pausedUntil.Sub(now)
+		now = b.pausedUntil
+	}
+	if b.rate <= 0 {
+		return delay
+	}
+
+	// the bucket holds up to a second of requests
+	if !b.last.IsZero() && now.After(b.last) {
+		b.tokens = math.Min(b.tokens+now.Sub(b.last).Seconds()*b.rate, math.Max(b.rate, 1))
+	}
+	if b.last.IsZero() || now.After(b.last) {
+		b.last = now
+	}
+
+	b.tokens--
+	if b.tokens < 0 {
+		delay += time.Duration(-b.tokens / b.rate * float64(time.Second))
+	}
+	return delay
+}
+
+// throttled pauses the service, for longer on consecutive throttling errors,
+// and halves its rate down to a tenth of the limit.
+func (b *rateBucket) throttled(now time.Time) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	pause := throttlePause << uint(b.throttles)
+	if pause > throttleMaxPause || pause <= 0 {
+		pause = throttleMaxPause
+	}
+	if b.throttles < 16 {
+		b.throttles++
+	}
+	if until := now.Add(pause); until.After(b.pausedUntil) {
+		b.pausedUntil = until
+	}
+
+	if b.limit > 0 {
+		b.rate = b.rate / 2
+		if minimum := b.limit / 10; b.rate < minimum {
+			b.rate = minimum
+		}
+	}
+}
+
+// succeeded increases the rate back by a tenth of the limit.
+func (b *rateBucket) succeeded() {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.throttles = 0
+	if b.limit > 0 && b.rate < b.limit {
+		b.rate += b.limit / 10
+		if b.rate > b.limit {
+			b.rate = b.limit
+		}
+	}
+}
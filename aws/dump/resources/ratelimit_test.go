@@ -0,0 +1,80 @@
+package resources
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type fakeClock struct {
+	now   time.Time
+	slept []time.Duration
+}
+
+func (c *fakeClock) sleep(delay time.Duration) {
+	c.slept = append(c.slept, delay)
+	c.now = c.now.Add(delay)
+}
+
+func newTestRateLimiter(defaultLimit float64, limits map[string]float64) (*RateLimiter, *fakeClock) {
+	clock := &fakeClock{now: time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)}
+	limiter := NewRateLimiter(defaultLimit, limits)
+	limiter.now = func() time.Time { return clock.now }
+	limiter.sleep = clock.sleep
+	return limiter, clock
+}
+
+func TestRateLimiterWait(t *testing.T) {
+	limiter, clock := newTestRateLimiter(0, map[string]float64{"iam": 2})
+
+	for i := 0; i < 5; i++ {
+		limiter.Wait("account", "iam")
+	}
+	require.Equal(t, []time.Duration{500 * time.Millisecond, 500 * time.Millisecond, 500 * time.Millisecond, 500 * time.Millisecond}, clock.slept)
+
+	// no limit for the other services and the other accounts have their bucket
+	clock.slept = nil
+	for i := 0; i < 5; i++ {
+		limiter.Wait("account", "ec2")
+	}
+	limiter.Wait("other", "iam")
+	require.Empty(t, clock.slept)
+}
+
+func TestRateLimiterThrottled(t *testing.T) {
+	limiter, clock := newTestRateLimiter(10, nil)
+
+	limiter.Wait("account", "ec2")
+	limiter.Throttled("account", "ec2")
+	limiter.Wait("account", "ec2")
+	require.Equal(t, []time.Duration{time.Second}, clock.slept)
+	require.Equal(t, 5.0, limiter.bucket("account", "ec2").rate)
+
+	limiter.Throttled("account", "ec2")
+	limiter.Throttled("account", "ec2")
+	bucket := limiter.bucket("account", "ec2")
+	require.Equal(t, clock.now.Add(4*time.Second), bucket.pausedUntil)
+	require.Equal(t, 1.25, bucket.rate)
+	require.Equal(t, 3, limiter.Throttles())
+
+	limiter.Throttled("account", "ec2")
+	require.Equal(t, 1.0, bucket.rate)
+
+	for i := 0; i < 20; i++ {
+		limiter.Succeeded("account", "ec2")
+	}
+	require.Equal(t, 10.0, bucket.rate)
+	require.Equal(t, 0, bucket.throttles)
+}
+
+func TestRateLimiterThrottledWithoutLimit(t *testing.T) {
+	limiter, clock := newTestRateLimiter(0, nil)
+
+	for i := 0; i < 10; i++ {
+		limiter.Throttled("account", "iam")
+	}
+	limiter.Wait("account", "iam")
+	require.Equal(t, []time.Duration{throttleMaxPause}, clock.slept)
+	require.Equal(t, 0.0, limiter.bucket("account", "iam").rate)
+}
@@ -0,0 +1,188 @@
+package resources
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+const (
+	RelationIn            = "in"
+	RelationUses          = "uses"
+	RelationContains      = "contains"
+	RelationMemberOf      = "member-of"
+	RelationHasPolicy     = "has-policy"
+	RelationEncryptedWith = "encrypted-with"
+)
+
+// Relationship is an edge from a resource to a resource referenced in its
+// metadata. From is the ARN of the resource, or its id when it has none, To is
+// the id or the ARN of the referenced resource, as referenced. The types are
+// service:type.
+type Relationship struct {
+	From     string
+	FromType string
+	Relation string
+	To       string
+	ToType   string
+}
+
+type relationshipRule struct {
+	// Path of the references in the metadata, dot separated. The lists on the
+	// way are flattened.
+	Path     string
+	ToType   string
+	Relation string
+	// FromPath and FromType are the source of the relationships when it is not
+	// the resource itself, e.g. the role of a role policy attachment
+	FromPath string
+	FromType string
+}
+
+var relationshipRules = map[string][]relationshipRule{
+	"ec2:instance": {
+		{Path: "VpcId", ToType: "ec2:vpc", Relation: RelationIn},
+		{Path: "SubnetId", ToType: "ec2:subnet", Relation: RelationIn},
+		{Path: "SecurityGroups.GroupId", ToType: "ec2:security-group", Relation: RelationUses},
+		{Path: "ImageId", ToType: "ec2:image", Relation: RelationUses},
+		{Path: "KeyName", ToType: "ec2:key-pair", Relation: RelationUses},
+		{Path: "IamInstanceProfile.Arn", ToType: "iam:instance-profile", Relation: RelationUses},
+	},
+	"ec2:subnet": {
+		{Path: "VpcId", ToType: "ec2:vpc", Relation: RelationIn},
+	},
+	"ec2:security-group": {
+		{Path: "VpcId", ToType: "ec2:vpc", Relation: RelationIn},
+	},
+	"ec2:nat-gateway": {
+		{Path: "VpcId", ToType: "ec2:vpc", Relation: RelationIn},
+		{Path: "SubnetId", ToType: "ec2:subnet", Relation: RelationIn},
+	},
+	"iam:instance-profile": {
+		{Path: "Roles.Arn", ToType: "iam:role", Relation: RelationContains},
+	},
+	"iam:user-policy-attachment": {
+		{Path: "PolicyArn", ToType: "iam:policy", Relation: RelationHasPolicy, FromPath: "UserArn", FromType: "iam:user"},
+	},
+	"iam:group-policy-attachment": {
+		{Path: "PolicyArn", ToType: "iam:policy", Relation: RelationHasPolicy, FromPath: "GroupArn", FromType: "iam:group"},
+	},
+	"iam:role-policy-attachment": {
+		{Path: "PolicyArn", ToType: "iam:policy", Relation: RelationHasPolicy, FromPath: "RoleArn", FromType: "iam:role"},
+	},
+	"lambda:function": {
+		{Path: "Role", ToType: "iam:role", Relation: RelationUses},
+		{Path: "VpcConfig.SubnetIds", ToType: "ec2:subnet", Relation: RelationIn},
+		{Path: "VpcConfig.SecurityGroupIds", ToType: "ec2:security-group", Relation: RelationUses},
+		{Path: "KMSKeyArn", ToType: "kms:key", Relation: RelationEncryptedWith},
+	},
+	"rds:db-instance": {
+		{Path: "DBSubnetGroup.VpcId", ToType: "ec2:vpc", Relation: RelationIn},
+		{Path: "DBSubnetGroup.Subnets.SubnetIdentifier", ToType: "ec2:subnet", Relation: RelationIn},
+		{Path: "VpcSecurityGroups.VpcSecurityGroupId", ToType: "ec2:security-group", Relation: RelationUses},
+		{Path: "DBClusterIdentifier", ToType: "rds:db-cluster", Relation: RelationMemberOf},
+		{Path: "KmsKeyId", ToType: "kms:key", Relation: RelationEncryptedWith},
+	},
+	"rds:db-cluster": {
+		{Path: "VpcSecurityGroups.VpcSecurityGroupId", ToType: "ec2:security-group", Relation: RelationUses},
+		{Path: "KmsKeyId", ToType: "kms:key", Relation: RelationEncryptedWith},
+	},
+	"elasticloadbalancing:loadbalancer": {
+		{Path: "VpcId", ToType: "ec2:vpc", Relation: RelationIn},
+		{Path: "AvailabilityZones.SubnetId", ToType: "ec2:subnet", Relation: RelationIn},
+		{Path: "SecurityGroups", ToType: "ec2:security-group", Relation: RelationUses},
+	},
+	"autoscaling:group": {
+		{Path: "Instances.InstanceId", ToType: "ec2:instance", Relation: RelationContains},
+		{Path: "LaunchConfigurationName", ToType: "autoscaling:launch-configuration", Relation: RelationUses},
+		{Path: "LaunchTemplate.LaunchTemplateId", ToType: "ec2:launch-template", Relation: RelationUses},
+		{Path: "TargetGroupARNs", ToType: "elasticloadbalancing:targetgroup", Relation: RelationUses},
+	},
+	"elasticfilesystem:file-system": {
+		{Path: "KmsKeyId", ToType: "kms:key", Relation: RelationEncryptedWith},
+	},
+	"elasticfilesystem:mount-target": {
+		{Path: "FileSystemId", ToType: "elasticfilesystem:file-system", Relation: RelationMemberOf},
+		{Path: "SubnetId", ToType: "ec2:subnet", Relation: RelationIn},
+		{Path: "VpcId", ToType: "ec2:vpc", Relation: RelationIn},
+	},
+}
+
+// Relationships returns the relationships of the resource to the resources
+// referenced in its metadata.
+func Relationships(resource Resource) []Relationship {
+	resourceType := fmt.Sprintf("%s:%s", resource.Service, resource.Type)
+	rules := relationshipRules[resourceType]
+	if len(rules) == 0 {
+		return nil
+	}
+
+	// go through JSON to handle the pointers and structs of the metadata
+	var metadata interface{}
+	data, err := json.Marshal(resource.Metadata)
+	if err != nil || json.Unmarshal(data, &metadata) != nil {
+		return nil
+	}
+
+	relationships := []Relationship{}
+	for _, rule := range rules {
+		from, fromType := []string{resource.UniqueID()}, resourceType
+		if rule.FromPath != "" {
+			from, fromType = metadataValues(metadata, strings.Split(rule.FromPath, ".")), rule.FromType
+		}
+		for _, source := range from {
+			for _, target := range metadataValues(metadata, strings.Split(rule.Path, ".")) {
+				relationships = append(relationships, Relationship{
+					From:     source,
+					FromType: fromType,
+					Relation: rule.Relation,
+					To:       target,
+					ToType:   rule.ToType,
+				})
+			}
+		}
+	}
+	return relationships
+}
+
+// metadataValues returns the non empty strings at the path of the value,
+// flattening the lists.
+func metadataValues(value interface{}, path []string) []string {
+	switch typed := value.(type) {
+	case []interface{}:
+		values := []string{}
+		for _, item := range typed {
+			values = append(values, metadataValues(item, path)...)
+		}
+		return values
+	case map[string]interface{}:
+		if len(path) == 0 {
+			return nil
+		}
+		return metadataValues(typed[path[0]], path[1:])
+	case string:
+		if len(path) == 0 && typed != "" {
+			return []string{typed}
+		}
+	}
+	return nil
+}
+
+// Resource returns the marker added to the output for the relationship, in the
+// account and region of the resource.
+func (r *Relationship) Resource(resource Resource) Resource {
+	return Resource{
+		ID:        fmt.Sprintf("%s %s %s", r.From, r.Relation, r.To),
+		Service:   "aws-dump",
+		Type:      "relationship",
+		AccountID: resource.AccountID,
+		Region:    resource.Region,
+		Metadata: map[string]interface{}{
+			"From":     r.From,
+			"FromType": r.FromType,
+			"Relation": r.Relation,
+			"To":       r.To,
+			"ToType":   r.ToType,
+		},
+	}
+}
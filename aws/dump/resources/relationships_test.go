@@ -0,0 +1,63 @@
+package resources
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRelationships(t *testing.T) {
+	t.Parallel()
+
+	instance := Resource{
+		ID:        "i-0123456789abcdef0",
+		Service:   "ec2",
+		Type:      "instance",
+		AccountID: "123456789012",
+		Region:    "eu-west-1",
+		Metadata: map[string]interface{}{
+			"VpcId":    aws.String("vpc-1"),
+			"SubnetId": aws.String("subnet-1"),
+			"KeyName":  nil,
+			"SecurityGroups": []interface{}{
+				map[string]interface{}{"GroupId": aws.String("sg-1"), "GroupName": aws.String("web")},
+				map[string]interface{}{"GroupId": aws.String("sg-2"), "GroupName": aws.String("ssh")},
+			},
+		},
+	}
+
+	require.Equal(t, []Relationship{
+		{"i-0123456789abcdef0", "ec2:instance", RelationIn, "vpc-1", "ec2:vpc"},
+		{"i-0123456789abcdef0", "ec2:instance", RelationIn, "subnet-1", "ec2:subnet"},
+		{"i-0123456789abcdef0", "ec2:instance", RelationUses, "sg-1", "ec2:security-group"},
+		{"i-0123456789abcdef0", "ec2:instance", RelationUses, "sg-2", "ec2:security-group"},
+	}, Relationships(instance))
+
+	attachment := Resource{
+		ID:      "app_ReadOnly",
+		Service: "iam",
+		Type:    "role-policy-attachment",
+		Metadata: map[string]interface{}{
+			"PolicyArn": aws.String("arn:aws:iam::aws:policy/ReadOnlyAccess"),
+			"RoleArn":   "arn:aws:iam::123456789012:role/app",
+		},
+	}
+	require.Equal(t, []Relationship{
+		{"arn:aws:iam::123456789012:role/app", "iam:role", RelationHasPolicy, "arn:aws:iam::aws:policy/ReadOnlyAccess", "iam:policy"},
+	}, Relationships(attachment))
+
+	require.Empty(t, Relationships(Resource{Service: "s3", Type: "bucket"}))
+}
+
+func TestRelationshipResource(t *testing.T) {
+	t.Parallel()
+
+	relationship := Relationship{"arn:aws:ec2:eu-west-1:123456789012:subnet/subnet-1", "ec2:subnet", RelationIn, "vpc-1", "ec2:vpc"}
+	marker := relationship.Resource(Resource{AccountID: "123456789012", Region: "eu-west-1"})
+	require.Equal(t, "aws-dump", marker.Service)
+	require.Equal(t, "relationship", marker.Type)
+	require.Equal(t, "123456789012", marker.AccountID)
+	require.Equal(t, "eu-west-1", marker.Region)
+	require.Equal(t, "vpc-1", marker.Metadata["To"])
+}
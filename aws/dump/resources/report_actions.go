@@ -0,0 +1,187 @@
+// Code generated by go run actions_gen.go; DO NOT EDIT.
+
+package resources
+
+// ReportActions are the IAM actions of the API calls of the reports, by
+// service:report.
+var ReportActions = map[string][]string{
+	"accessanalyzer:analyzers":                      {"accessanalyzer:ListAnalyzers"},
+	"accessanalyzer:findings":                       {"accessanalyzer:ListAnalyzers", "accessanalyzer:ListFindings"},
+	"acm:certificates":                              {"acm:ListCertificates"},
+	"apigateway:apis":                               {"apigateway:GET"},
+	"apigateway:rest-apis":                          {"apigateway:GET"},
+	"apprunner:services":                            {"apprunner:DescribeService", "apprunner:ListServices"},
+	"appsync:api-keys":                              {"appsync:ListApiKeys", "appsync:ListGraphqlApis"},
+	"appsync:data-sources":                          {"appsync:ListDataSources", "appsync:ListGraphqlApis"},
+	"appsync:functions":                             {"appsync:ListFunctions", "appsync:ListGraphqlApis"},
+	"appsync:graphql-apis":                          {"appsync:ListGraphqlApis"},
+	"appsync:resolvers":                             {"appsync:ListGraphqlApis", "appsync:ListResolvers", "appsync:ListTypes"},
+	"autoscaling:groups":                            {"autoscaling:DescribeAutoScalingGroups"},
+	"autoscaling:instances":                         {"autoscaling:DescribeAutoScalingInstances"},
+	"autoscaling:launch-configurations":             {"autoscaling:DescribeLaunchConfigurations"},
+	"autoscaling:launch-templates":                  {"ec2:DescribeLaunchTemplateVersions", "ec2:DescribeLaunchTemplates"},
+	"autoscaling:policies":                          {"autoscaling:DescribePolicies"},
+	"autoscaling:scheduled-actions":                 {"autoscaling:DescribeScheduledActions"},
+	"backup:coverage":                               {"backup:ListProtectedResources", "dynamodb:DescribeContinuousBackups", "dynamodb:ListBackups", "dynamodb:ListTables", "ec2:DescribeSnapshots", "ec2:DescribeVolumes", "efs:DescribeFileSystems", "rds:DescribeDBClusterSnapshots", "rds:DescribeDBClusters", "rds:DescribeDBInstances", "rds:DescribeDBSnapshots"},
+	"backup:plans":                                  {"backup:GetBackupPlan", "backup:GetBackupSelection", "backup:ListBackupPlans", "backup:ListBackupSelections"},
+	"backup:recovery-point-summaries":               {"backup:ListBackupVaults", "backup:ListRecoveryPointsByBackupVault"},
+	"backup:recovery-points":                        {"backup:ListBackupVaults", "backup:ListRecoveryPointsByBackupVault"},
+	"backup:vaults":                                 {"backup:GetBackupVaultAccessPolicy", "backup:ListBackupVaults"},
+	"cloudformation:exports":                        {"cloudformation:ListExports"},
+	"cloudformation:stack-instances":                {"cloudformation:ListStackInstances", "cloudformation:ListStackSets"},
+	"cloudformation:stack-resources":                {"cloudformation:ListStackResources", "cloudformation:ListStacks"},
+	"cloudformation:stack-sets":                     {"cloudformation:DescribeStackSet", "cloudformation:ListStackSets"},
+	"cloudformation:stacks":                         {"cloudformation:DescribeStacks"},
+	"cloudfront:distributions":                      {"cloudfront:ListDistributions"},
+	"cloudwatch:alarms":                             {"cloudwatch:DescribeAlarms"},
+	"codesuite:codebuild-projects":                  {"codebuild:BatchGetProjects", "codebuild:ListProjects"},
+	"codesuite:codedeploy-applications":             {"codedeploy:BatchGetApplications", "codedeploy:ListApplications"},
+	"codesuite:codedeploy-deployment-groups":        {"codedeploy:BatchGetDeploymentGroups", "codedeploy:ListApplications", "codedeploy:ListDeploymentGroups"},
+	"codesuite:pipelines":                           {"codepipeline:GetPipeline", "codepipeline:ListPipelines"},
+	"cognito:identity-pools":                        {"cognito-identity:DescribeIdentityPool", "cognito-identity:GetIdentityPoolRoles", "cognito-identity:ListIdentityPools"},
+	"cognito:user-pool-clients":                     {"cognito-idp:DescribeUserPoolClient", "cognito-idp:ListUserPoolClients", "cognito-idp:ListUserPools"},
+	"cognito:user-pool-identity-providers":          {"cognito-idp:DescribeIdentityProvider", "cognito-idp:ListIdentityProviders", "cognito-idp:ListUserPools"},
+	"cognito:user-pools":                            {"cognito-idp:DescribeUserPool", "cognito-idp:DescribeUserPoolDomain", "cognito-idp:GetUserPoolMfaConfig", "cognito-idp:ListUserPools"},
+	"directconnect-gateway:associations":            {"directconnect:DescribeDirectConnectGatewayAssociations", "directconnect:DescribeDirectConnectGateways"},
+	"directconnect-gateway:gateways":                {"directconnect:DescribeDirectConnectGateways"},
+	"directconnect:connections":                     {"directconnect:DescribeConnections"},
+	"directconnect:lags":                            {"directconnect:DescribeLags"},
+	"directconnect:virtual-interfaces":              {"directconnect:DescribeVirtualInterfaces"},
+	"dms:endpoints":                                 {"dms:DescribeEndpoints"},
+	"dms:replication-instances":                     {"dms:DescribeReplicationInstances"},
+	"dms:replication-subnet-groups":                 {"dms:DescribeReplicationSubnetGroups"},
+	"dms:replication-tasks":                         {"dms:DescribeReplicationTasks"},
+	"docdb:db-cluster-parameter-groups":             {"rds:DescribeDBClusterParameterGroups"},
+	"docdb:db-cluster-snapshots":                    {"rds:DescribeDBClusterSnapshots"},
+	"docdb:db-clusters":                             {"rds:DescribeDBClusters"},
+	"docdb:db-instances":                            {"rds:DescribeDBInstances"},
+	"ec2:images":                                    {"ec2:DescribeImages"},
+	"ec2:instance-access":                           {"ec2:DescribeInstances", "ec2:DescribeManagedPrefixLists", "ec2:DescribeSecurityGroups", "ec2:GetPasswordData", "ssm:DescribeInstanceInformation"},
+	"ec2:instances":                                 {"ec2:DescribeInstances"},
+	"ec2:key-pair-audit":                            {"ec2:DescribeInstances", "ec2:DescribeKeyPairs"},
+	"ec2:key-pairs":                                 {"ec2:DescribeKeyPairs"},
+	"ec2:launch-templates":                          {"ec2:DescribeLaunchTemplateVersions", "ec2:DescribeLaunchTemplates"},
+	"ec2:nat-gateways":                              {"ec2:DescribeNatGateways"},
+	"ec2:security-groups":                           {"ec2:DescribeNetworkInterfaces", "ec2:DescribeSecurityGroups"},
+	"ec2:subnets":                                   {"ec2:DescribeSubnets"},
+	"ec2:vpcs":                                      {"ec2:DescribeVpcs"},
+	"efs:access-points":                             {"efs:DescribeAccessPoints"},
+	"efs:file-systems":                              {"efs:DescribeBackupPolicy", "efs:DescribeFileSystems"},
+	"efs:mount-targets":                             {"efs:DescribeFileSystems", "efs:DescribeMountTargetSecurityGroups", "efs:DescribeMountTargets"},
+	"elasticbeanstalk:application-versions":         {"elasticbeanstalk:DescribeApplicationVersions"},
+	"elasticbeanstalk:applications":                 {"elasticbeanstalk:DescribeApplications"},
+	"elasticbeanstalk:environments":                 {"elasticbeanstalk:DescribeConfigurationSettings", "elasticbeanstalk:DescribeEnvironments"},
+	"elasticbeanstalk:saved-configurations":         {"elasticbeanstalk:DescribeApplications", "elasticbeanstalk:DescribeConfigurationSettings"},
+	"elbv2:load-balancers":                          {"elasticloadbalancing:DescribeListeners", "elasticloadbalancing:DescribeLoadBalancers"},
+	"events:rules":                                  {"events:ListEventBuses", "events:ListRules", "events:ListTargetsByRule"},
+	"events:scheduled-jobs":                         {"events:ListEventBuses", "events:ListRules", "events:ListTargetsByRule", "scheduler:GetSchedule", "scheduler:ListSchedules"},
+	"events:schedules":                              {"scheduler:GetSchedule", "scheduler:ListSchedules"},
+	"firehose:delivery-streams":                     {"firehose:DescribeDeliveryStream", "firehose:ListDeliveryStreams"},
+	"fsx:backups":                                   {"fsx:DescribeBackups"},
+	"fsx:file-systems":                              {"fsx:DescribeFileSystems"},
+	"globalaccelerator:accelerators":                {"globalaccelerator:ListAccelerators", "globalaccelerator:ListEndpointGroups", "globalaccelerator:ListListeners"},
+	"globalaccelerator:byoip-cidrs":                 {"globalaccelerator:ListByoipCidrs"},
+	"globalaccelerator:custom-routing-accelerators": {"globalaccelerator:ListCustomRoutingAccelerators", "globalaccelerator:ListCustomRoutingEndpointGroups", "globalaccelerator:ListCustomRoutingListeners"},
+	"iam:account-authorization-details":             {"iam:GetAccountAuthorizationDetails"},
+	"iam:account-password-policy":                   {"iam:GetAccountPasswordPolicy"},
+	"iam:account-summary":                           {"iam:GetAccountSummary"},
+	"iam:credential-report":                         {"iam:GenerateCredentialReport", "iam:GetCredentialReport"},
+	"iam:groups":                                    {"iam:GenerateServiceLastAccessedDetails", "iam:GetGroupPolicy", "iam:GetServiceLastAccessedDetails", "iam:ListAttachedGroupPolicies", "iam:ListGroupPolicies", "iam:ListGroups", "iam:ListPolicies"},
+	"iam:instance-profiles":                         {"iam:ListInstanceProfiles"},
+	"iam:oidc-providers":                            {"iam:GetOpenIDConnectProvider", "iam:ListOpenIDConnectProviders"},
+	"iam:policies":                                  {"iam:GenerateServiceLastAccessedDetails", "iam:GetPolicyVersion", "iam:GetServiceLastAccessedDetails", "iam:ListPolicies", "iam:ListPolicyVersions"},
+	"iam:roles":                                     {"iam:GenerateServiceLastAccessedDetails", "iam:GetRolePolicy", "iam:GetServiceLastAccessedDetails", "iam:ListAttachedRolePolicies", "iam:ListPolicies", "iam:ListRolePolicies", "iam:ListRoles"},
+	"iam:root-account":                              {"account:GetAlternateContact", "cloudtrail:LookupEvents", "iam:GenerateCredentialReport", "iam:GetAccountSummary", "iam:GetCredentialReport", "iam:ListVirtualMFADevices"},
+	"iam:saml-providers":                            {"iam:ListSAMLProviders"},
+	"iam:server-certificates":                       {"iam:ListServerCertificates"},
+	"iam:users-and-access-keys":                     {"iam:GenerateServiceLastAccessedDetails", "iam:GetAccessKeyLastUsed", "iam:GetServiceLastAccessedDetails", "iam:GetUserPolicy", "iam:ListAccessKeys", "iam:ListAttachedUserPolicies", "iam:ListPolicies", "iam:ListUserPolicies", "iam:ListUsers"},
+	"iam:virtual-mfa-devices":                       {"iam:ListVirtualMFADevices"},
+	"iot:certificates":                              {"iot:DescribeCertificate", "iot:ListCertificates"},
+	"iot:policies":                                  {"iot:GetPolicy", "iot:ListPolicies"},
+	"iot:thing-groups":                              {"iot:DescribeThingGroup", "iot:ListThingGroups"},
+	"iot:thing-types":                               {"iot:ListThingTypes"},
+	"iot:things":                                    {"iot:ListThings"},
+	"iot:topic-rules":                               {"iot:GetTopicRule", "iot:ListTopicRules"},
+	"kinesis:streams":                               {"kinesis:DescribeStreamSummary", "kinesis:ListStreams"},
+	"kms:aliases":                                   {"kms:ListAliases"},
+	"kms:encryption-posture":                        {"dynamodb:DescribeTable", "dynamodb:ListTables", "ec2:DescribeVolumes", "efs:DescribeFileSystems", "kms:DescribeKey", "logs:DescribeLogGroups", "rds:DescribeDBClusters", "rds:DescribeDBInstances", "s3:GetBucketLocation", "s3:GetEncryptionConfiguration", "s3:ListAllMyBuckets", "sns:GetTopicAttributes", "sns:ListTopics", "sqs:GetQueueAttributes", "sqs:ListQueues"},
+	"kms:keys":                                      {"kms:DescribeKey", "kms:ListKeys"},
+	"lambda:event-source-mappings":                  {"lambda:ListEventSourceMappings"},
+	"lambda:functions":                              {"lambda:ListFunctions"},
+	"licensemanager:license-configurations":         {"license-manager:ListLicenseConfigurations", "license-manager:ListUsageForLicenseConfiguration"},
+	"licensemanager:marketplace-instances":          {"ec2:DescribeInstances"},
+	"licensemanager:received-licenses":              {"license-manager:ListReceivedLicenses"},
+	"lightsail:databases":                           {"lightsail:GetRelationalDatabases"},
+	"lightsail:disks":                               {"lightsail:GetDisks"},
+	"lightsail:instances":                           {"lightsail:GetInstances"},
+	"lightsail:load-balancers":                      {"lightsail:GetLoadBalancers"},
+	"lightsail:static-ips":                          {"lightsail:GetStaticIps"},
+	"mq:brokers":                                    {"mq:DescribeBroker", "mq:ListBrokers"},
+	"mq:configurations":                             {"mq:DescribeConfigurationRevision", "mq:ListConfigurations"},
+	"msk:clusters":                                  {"kafka:GetBootstrapBrokers", "kafka:ListClusters", "kafka:ListNodes"},
+	"msk:configurations":                            {"kafka:DescribeConfigurationRevision", "kafka:ListConfigurationRevisions", "kafka:ListConfigurations"},
+	"neptune:db-cluster-parameter-groups":           {"rds:DescribeDBClusterParameterGroups"},
+	"neptune:db-cluster-snapshots":                  {"rds:DescribeDBClusterSnapshots"},
+	"neptune:db-clusters":                           {"rds:DescribeDBClusters"},
+	"neptune:db-instances":                          {"rds:DescribeDBInstances"},
+	"neptune:db-parameter-groups":                   {"rds:DescribeDBParameterGroups"},
+	"rds:db-clusters":                               {"rds:DescribeDBClusters"},
+	"rds:db-instance-automated-backups":             {"rds:DescribeDBInstanceAutomatedBackups"},
+	"rds:db-instances":                              {"rds:DescribeDBInstances"},
+	"rds:db-parameter-groups":                       {"rds:DescribeDBParameterGroups"},
+	"rds:db-security-groups":                        {"rds:DescribeDBSecurityGroups"},
+	"rds:db-snapshots":                              {"rds:DescribeDBSnapshots"},
+	"rds:db-subnet-groups":                          {"rds:DescribeDBSubnetGroups"},
+	"rds:event-subscriptions":                       {"rds:DescribeEventSubscriptions"},
+	"rds:events":                                    {"rds:DescribeEvents"},
+	"rds:global-clusters":                           {"rds:DescribeGlobalClusters"},
+	"rds:option-groups":                             {"rds:DescribeOptionGroups"},
+	"rds:reserved-db-instances":                     {"rds:DescribeReservedDBInstances"},
+	"route53:zones-and-records":                     {"route53:ListHostedZones", "route53:ListResourceRecordSets"},
+	"s3:buckets":                                    {"s3:GetBucketLocation", "s3:GetBucketPolicy", "s3:GetBucketPolicyStatus", "s3:GetBucketPublicAccessBlock", "s3:ListAllMyBuckets"},
+	"sagemaker:domains":                             {"sagemaker:DescribeDomain", "sagemaker:ListDomains"},
+	"sagemaker:endpoint-configs":                    {"sagemaker:DescribeEndpointConfig", "sagemaker:ListEndpointConfigs"},
+	"sagemaker:endpoints":                           {"sagemaker:DescribeEndpoint", "sagemaker:ListEndpoints"},
+	"sagemaker:models":                              {"sagemaker:ListModels"},
+	"sagemaker:notebook-instances":                  {"sagemaker:DescribeNotebookInstance", "sagemaker:ListNotebookInstances"},
+	"sagemaker:training-jobs":                       {"sagemaker:DescribeTrainingJob", "sagemaker:ListTrainingJobs"},
+	"securityhub:controls":                          {"securityhub:DescribeStandardsControls", "securityhub:GetEnabledStandards"},
+	"securityhub:findings":                          {"securityhub:GetFindings"},
+	"securityhub:hub":                               {"securityhub:DescribeHub"},
+	"securityhub:standards":                         {"securityhub:GetEnabledStandards"},
+	"servicecatalog:constraints":                    {"servicecatalog:DescribeConstraint", "servicecatalog:ListConstraintsForPortfolio", "servicecatalog:ListPortfolios"},
+	"servicecatalog:portfolios":                     {"servicecatalog:ListPortfolios"},
+	"servicecatalog:products":                       {"servicecatalog:SearchProductsAsAdmin"},
+	"servicecatalog:provisioned-products":           {"servicecatalog:SearchProvisionedProducts"},
+	"servicecatalog:provisioning-artifacts":         {"servicecatalog:ListProvisioningArtifacts", "servicecatalog:SearchProductsAsAdmin"},
+	"sharing:findings":                              {"ec2:DescribeImageAttribute", "ec2:DescribeImages", "ec2:DescribeSnapshotAttribute", "ec2:DescribeSnapshots", "ecr:DescribeRepositories", "ecr:GetRepositoryPolicy", "rds:DescribeDBClusterSnapshotAttributes", "rds:DescribeDBClusterSnapshots", "rds:DescribeDBSnapshotAttributes", "rds:DescribeDBSnapshots"},
+	"shield:attacks":                                {"shield:ListAttacks"},
+	"shield:protection-groups":                      {"shield:ListProtectionGroups"},
+	"shield:protections":                            {"shield:ListProtections"},
+	"shield:subscription":                           {"shield:DescribeSubscription", "shield:GetSubscriptionState"},
+	"transitgateway:attachments":                    {"ec2:DescribeTransitGatewayAttachments"},
+	"transitgateway:route-tables":                   {"ec2:DescribeTransitGatewayRouteTables", "ec2:GetTransitGatewayRouteTableAssociations", "ec2:GetTransitGatewayRouteTablePropagations"},
+	"transitgateway:transit-gateways":               {"ec2:DescribeTransitGateways"},
+	"waf-cloudfront:classic-web-acls":               {"waf:GetWebACL", "waf:ListWebACLs"},
+	"waf-cloudfront:ip-sets":                        {"wafv2:GetIPSet", "wafv2:ListIPSets"},
+	"waf-cloudfront:regex-pattern-sets":             {"wafv2:GetRegexPatternSet", "wafv2:ListRegexPatternSets"},
+	"waf-cloudfront:rule-groups":                    {"wafv2:GetRuleGroup", "wafv2:ListRuleGroups"},
+	"waf-cloudfront:web-acls":                       {"wafv2:GetWebACL", "wafv2:ListWebACLs"},
+	"waf:classic-web-acls":                          {"waf-regional:ListResourcesForWebACL", "waf:GetWebACL", "waf:ListWebACLs"},
+	"waf:ip-sets":                                   {"wafv2:GetIPSet", "wafv2:ListIPSets"},
+	"waf:regex-pattern-sets":                        {"wafv2:GetRegexPatternSet", "wafv2:ListRegexPatternSets"},
+	"waf:rule-groups":                               {"wafv2:GetRuleGroup", "wafv2:ListRuleGroups"},
+	"waf:web-acl-associations":                      {"wafv2:GetWebACL", "wafv2:ListResourcesForWebACL", "wafv2:ListWebACLs"},
+	"waf:web-acls":                                  {"wafv2:GetWebACL", "wafv2:ListWebACLs"},
+}
+
+// StepActions are the IAM actions of the API calls of the steps of the dump
+// run outside of the reports, by step.
+var StepActions = map[string][]string{
+	"monthly-cost":  {"ce:GetCostAndUsage", "pricing:GetProducts"},
+	"organization":  {"organizations:ListAccounts", "sts:GetCallerIdentity"},
+	"rightsizing":   {"cloudwatch:GetMetricData"},
+	"sessions":      {"ec2:DescribeRegions", "sts:GetCallerIdentity"},
+	"ssm-inventory": {"ssm:DescribeInstanceInformation", "ssm:ListInventoryEntries"},
+	"tag-unmanaged": {"tag:TagResources"},
+}
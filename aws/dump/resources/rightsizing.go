@@ -0,0 +1,225 @@
+package resources
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
+)
+
+const (
+	rightsizingPeriod       = 14 * 24 * time.Hour
+	rightsizingMetricPeriod = 3600
+	// GetMetricData accepts up to 500 queries, smaller batches keep the
+	// number of datapoints per call low
+	rightsizingBatchSize = 100
+
+	// instances are under-utilised when the p95 stays below these values
+	underUtilisedCPUPercent    = 10.0
+	underUtilisedMemoryPercent = 40.0
+)
+
+type rightsizingMetric struct {
+	Name       string
+	Namespace  string
+	MetricName string
+	Dimension  string
+}
+
+var (
+	rightsizingMetrics = map[string][]rightsizingMetric{
+		"ec2:instance": {
+			{"CPUUtilization", "AWS/EC2", "CPUUtilization", "InstanceId"},
+			// only available when the CloudWatch agent is installed
+			{"MemoryUtilization", "CWAgent", "mem_used_percent", "InstanceId"},
+		},
+		"rds:db-instance": {
+			{"CPUUtilization", "AWS/RDS", "CPUUtilization", "DBInstanceIdentifier"},
+			{"FreeableMemory", "AWS/RDS", "FreeableMemory", "DBInstanceIdentifier"},
+		},
+	}
+)
+
+type rightsizingQuery struct {
+	resource Resource
+	metric   rightsizingMetric
+}
+
+// AttachRightsizing adds the p50 and p95 of the utilisation over the last 14
+// days to the ec2 and rds instances found in resources and returns a finding
+// for each under-utilised instance.
+func AttachRightsizing(accounts []*Account, resources []Resource) ([]Resource, []error) {
+	findings := []Resource{}
+	errors := []error{}
+
+	for _, account := range accounts {
+		for _, session := range account.Sessions {
+			queries := []rightsizingQuery{}
+			for _, resource := range resources {
+				if resource.AccountID != session.AccountID || resource.Region != *session.Config.Region {
+					continue
+				}
+				for _, metric := range rightsizingMetrics[fmt.Sprintf("%s:%s", resource.Service, resource.Type)] {
+					queries = append(queries, rightsizingQuery{resource, metric})
+				}
+			}
+
+			if len(queries) == 0 {
+				continue
+			}
+
+			sessionFindings, err := attachSessionRightsizing(session, queries)
+			if err != nil {
+				errors = append(errors, err)
+			}
+			findings = append(findings, sessionFindings...)
+		}
+	}
+
+	return findings, errors
+}
+
+func attachSessionRightsizing(session *Session, queries []rightsizingQuery) ([]Resource, error) {
+	client := cloudwatch.New(session.Session, session.Config)
+
+	endTime := time.Now().UTC().Truncate(time.Hour)
+	startTime := endTime.Add(-rightsizingPeriod)
+
+	for start := 0; start < len(queries); start += rightsizingBatchSize {
+		end := start + rightsizingBatchSize
+		if end > len(queries) {
+			end = len(queries)
+		}
+		batch := queries[start:end]
+
+		input := &cloudwatch.GetMetricDataInput{
+			StartTime: aws.Time(startTime),
+			EndTime:   aws.Time(endTime),
+		}
+		for i, query := range batch {
+			input.MetricDataQueries = append(input.MetricDataQueries, &cloudwatch.MetricDataQuery{
+				Id: aws.String(fmt.Sprintf("m%d", i)),
+				MetricStat: &cloudwatch.MetricStat{
+					Metric: &cloudwatch.Metric{
+						Namespace:  aws.String(query.metric.Namespace),
+						MetricName: aws.String(query.metric.MetricName),
+						Dimensions: []*cloudwatch.Dimension{
+							{
+								Name:  aws.String(query.metric.Dimension),
+								Value: aws.String(query.resource.ID),
+							},
+						},
+					},
+					Period: aws.Int64(rightsizingMetricPeriod),
+					Stat:   aws.String("Average"),
+				},
+			})
+		}
+
+		values := map[string][]float64{}
+		err := client.GetMetricDataPages(input,
+			func(page *cloudwatch.GetMetricDataOutput, lastPage bool) bool {
+				for _, metricDataResult := range page.MetricDataResults {
+					values[*metricDataResult.Id] = append(values[*metricDataResult.Id], aws.Float64ValueSlice(metricDataResult.Values)...)
+				}
+				return true
+			})
+		if err != nil {
+			return nil, err
+		}
+
+		for i, query := range batch {
+			datapoints := values[fmt.Sprintf("m%d", i)]
+			if len(datapoints) == 0 {
+				continue
+			}
+
+			rightsizing, ok := query.resource.Metadata["Rightsizing"].(map[string]interface{})
+			if !ok {
+				rightsizing = map[string]interface{}{
+					"Days": int(rightsizingPeriod.Hours() / 24),
+				}
+				query.resource.Metadata["Rightsizing"] = rightsizing
+			}
+			rightsizing[query.metric.Name] = utilisationSummary(datapoints)
+		}
+	}
+
+	findings := []Resource{}
+	seen := map[string]bool{}
+	for _, query := range queries {
+		if seen[query.resource.UniqueID()] {
+			continue
+		}
+		seen[query.resource.UniqueID()] = true
+
+		rightsizing, ok := query.resource.Metadata["Rightsizing"].(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		underUtilised := isUnderUtilised(rightsizing)
+		rightsizing["UnderUtilised"] = underUtilised
+		if !underUtilised {
+			continue
+		}
+
+		findings = append(findings, Resource{
+			ID:        query.resource.UniqueID(),
+			AccountID: query.resource.AccountID,
+			Service:   "rightsizing",
+			Type:      "under-utilised",
+			Region:    query.resource.Region,
+			Metadata: map[string]interface{}{
+				"ResourceService": query.resource.Service,
+				"ResourceType":    query.resource.Type,
+				"ResourceId":      query.resource.ID,
+				"ResourceArn":     query.resource.ARN,
+				"Rightsizing":     rightsizing,
+			},
+		})
+	}
+
+	return findings, nil
+}
+
+func utilisationSummary(values []float64) map[string]interface{} {
+	return map[string]interface{}{
+		"Datapoints": len(values),
+		"p50":        percentile(values, 50),
+		"p95":        percentile(values, 95),
+	}
+}
+
+// percentile uses the nearest-rank method.
+func percentile(values []float64, p float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+
+	sorted := append([]float64{}, values...)
+	sort.Float64s(sorted)
+
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+// isUnderUtilised requires a low CPU p95 and, when the memory utilisation is
+// known, a low memory p95.
+func isUnderUtilised(rightsizing map[string]interface{}) bool {
+	cpu, ok := rightsizing["CPUUtilization"].(map[string]interface{})
+	if !ok || cpu["p95"].(float64) >= underUtilisedCPUPercent {
+		return false
+	}
+
+	if memory, ok := rightsizing["MemoryUtilization"].(map[string]interface{}); ok {
+		return memory["p95"].(float64) < underUtilisedMemoryPercent
+	}
+	return true
+}
@@ -0,0 +1,30 @@
+package resources
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPercentile(t *testing.T) {
+	t.Parallel()
+
+	values := []float64{15, 20, 35, 40, 50}
+	require.Equal(t, 35.0, percentile(values, 50))
+	require.Equal(t, 50.0, percentile(values, 95))
+	require.Equal(t, 15.0, percentile(values, 0))
+	require.Equal(t, 0.0, percentile(nil, 50))
+}
+
+func TestIsUnderUtilised(t *testing.T) {
+	t.Parallel()
+
+	low := utilisationSummary([]float64{1, 2, 3})
+	high := utilisationSummary([]float64{50, 60, 90})
+
+	require.True(t, isUnderUtilised(map[string]interface{}{"CPUUtilization": low}))
+	require.False(t, isUnderUtilised(map[string]interface{}{"CPUUtilization": high}))
+	require.False(t, isUnderUtilised(map[string]interface{}{"CPUUtilization": low, "MemoryUtilization": high}))
+	require.True(t, isUnderUtilised(map[string]interface{}{"CPUUtilization": low, "MemoryUtilization": low}))
+	require.False(t, isUnderUtilised(map[string]interface{}{"MemoryUtilization": low}))
+}
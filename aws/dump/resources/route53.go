@@ -11,7 +11,8 @@ import (
 
 var (
 	Route53Service = Service{
-		Name: "route53",
+		Name:     "route53",
+		IsGlobal: true,
 		Reports: map[string]Report{
 			"zones-and-records": Route53ListHostedZonesAndRecordSets,
 		},
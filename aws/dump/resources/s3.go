@@ -3,6 +3,7 @@ package resources
 import (
 	"fmt"
 
+	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/s3"
 	"github.com/fatih/structs"
 )
@@ -40,7 +41,7 @@ func S3ListBuckets(session *Session) *ReportResult {
 			continue
 		}
 
-		result.Resources = append(result.Resources, Resource{
+		resource := Resource{
 			ID:        *bucket.Name,
 			ARN:       fmt.Sprintf("arn:aws:s3:::%s", *bucket.Name),
 			AccountID: session.AccountID,
@@ -48,7 +49,15 @@ func S3ListBuckets(session *Session) *ReportResult {
 			Type:      "bucket",
 			Region:    *location.LocationConstraint,
 			Metadata:  structs.Map(bucket),
-		})
+		}
+
+		err = S3AttachPublicStatus(client, &resource)
+		if err != nil {
+			result.Error = err
+			return result
+		}
+
+		result.Resources = append(result.Resources, resource)
 
 		policy, err := client.GetBucketPolicy(&s3.GetBucketPolicyInput{
 			Bucket: bucket.Name,
@@ -78,3 +87,33 @@ func S3ListBuckets(session *Session) *ReportResult {
 
 	return result
 }
+
+// S3AttachPublicStatus adds the bucket policy status and public access block
+// to the bucket metadata. Both are nil when not configured.
+func S3AttachPublicStatus(client *s3.S3, resource *Resource) error {
+	resource.Metadata["PolicyStatus"] = nil
+	policyStatus, err := client.GetBucketPolicyStatus(&s3.GetBucketPolicyStatusInput{
+		Bucket: aws.String(resource.ID),
+	})
+	if err != nil {
+		if !IsErrorCode(err, "NoSuchBucketPolicy") {
+			return err
+		}
+	} else {
+		resource.Metadata["PolicyStatus"] = structs.Map(policyStatus.PolicyStatus)
+	}
+
+	resource.Metadata["PublicAccessBlock"] = nil
+	publicAccessBlock, err := client.GetPublicAccessBlock(&s3.GetPublicAccessBlockInput{
+		Bucket: aws.String(resource.ID),
+	})
+	if err != nil {
+		if !IsErrorCode(err, "NoSuchPublicAccessBlockConfiguration") {
+			return err
+		}
+	} else {
+		resource.Metadata["PublicAccessBlock"] = structs.Map(publicAccessBlock.PublicAccessBlockConfiguration)
+	}
+
+	return nil
+}
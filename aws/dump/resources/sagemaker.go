@@ -0,0 +1,210 @@
+package resources
+
+import (
+	"github.com/aws/aws-sdk-go/service/sagemaker"
+	"github.com/fatih/structs"
+)
+
+var (
+	SageMakerService = Service{
+		Name: "sagemaker",
+		Reports: map[string]Report{
+			"notebook-instances": SageMakerListNotebookInstances,
+			"endpoints":          SageMakerListEndpoints,
+			"endpoint-configs":   SageMakerListEndpointConfigs,
+			"models":             SageMakerListModels,
+			"training-jobs":      SageMakerListTrainingJobs,
+			"domains":            SageMakerListDomains,
+		},
+	}
+)
+
+func SageMakerListNotebookInstances(session *Session) *ReportResult {
+	client := sagemaker.New(session.Session, session.Config)
+
+	result := &ReportResult{}
+	err := client.ListNotebookInstancesPages(&sagemaker.ListNotebookInstancesInput{},
+		func(page *sagemaker.ListNotebookInstancesOutput, lastPage bool) bool {
+			for _, notebookInstance := range page.NotebookInstances {
+				described, err := client.DescribeNotebookInstance(&sagemaker.DescribeNotebookInstanceInput{
+					NotebookInstanceName: notebookInstance.NotebookInstanceName,
+				})
+				if err != nil {
+					result.Error = err
+					return false
+				}
+
+				resource, err := NewResource(*notebookInstance.NotebookInstanceArn, described)
+				if err != nil {
+					result.Error = err
+					return false
+				}
+				result.Resources = append(result.Resources, *resource)
+			}
+
+			return true
+		})
+
+	if result.Error != nil {
+		return result
+	}
+	result.Error = err
+	return result
+}
+
+func SageMakerListEndpoints(session *Session) *ReportResult {
+	client := sagemaker.New(session.Session, session.Config)
+
+	result := &ReportResult{}
+	err := client.ListEndpointsPages(&sagemaker.ListEndpointsInput{},
+		func(page *sagemaker.ListEndpointsOutput, lastPage bool) bool {
+			for _, endpoint := range page.Endpoints {
+				described, err := client.DescribeEndpoint(&sagemaker.DescribeEndpointInput{
+					EndpointName: endpoint.EndpointName,
+				})
+				if err != nil {
+					result.Error = err
+					return false
+				}
+
+				resource, err := NewResource(*endpoint.EndpointArn, described)
+				if err != nil {
+					result.Error = err
+					return false
+				}
+				result.Resources = append(result.Resources, *resource)
+			}
+
+			return true
+		})
+
+	if result.Error != nil {
+		return result
+	}
+	result.Error = err
+	return result
+}
+
+func SageMakerListEndpointConfigs(session *Session) *ReportResult {
+	client := sagemaker.New(session.Session, session.Config)
+
+	result := &ReportResult{}
+	err := client.ListEndpointConfigsPages(&sagemaker.ListEndpointConfigsInput{},
+		func(page *sagemaker.ListEndpointConfigsOutput, lastPage bool) bool {
+			for _, endpointConfig := range page.EndpointConfigs {
+				// includes the instance type of each production variant
+				described, err := client.DescribeEndpointConfig(&sagemaker.DescribeEndpointConfigInput{
+					EndpointConfigName: endpointConfig.EndpointConfigName,
+				})
+				if err != nil {
+					result.Error = err
+					return false
+				}
+
+				resource, err := NewResource(*endpointConfig.EndpointConfigArn, described)
+				if err != nil {
+					result.Error = err
+					return false
+				}
+				result.Resources = append(result.Resources, *resource)
+			}
+
+			return true
+		})
+
+	if result.Error != nil {
+		return result
+	}
+	result.Error = err
+	return result
+}
+
+func SageMakerListModels(session *Session) *ReportResult {
+	client := sagemaker.New(session.Session, session.Config)
+
+	result := &ReportResult{}
+	result.Error = client.ListModelsPages(&sagemaker.ListModelsInput{},
+		func(page *sagemaker.ListModelsOutput, lastPage bool) bool {
+			for _, model := range page.Models {
+				resource, err := NewResource(*model.ModelArn, model)
+				if err != nil {
+					result.Error = err
+					return false
+				}
+				result.Resources = append(result.Resources, *resource)
+			}
+
+			return true
+		})
+
+	return result
+}
+
+func SageMakerListTrainingJobs(session *Session) *ReportResult {
+	client := sagemaker.New(session.Session, session.Config)
+
+	result := &ReportResult{}
+	err := client.ListTrainingJobsPages(&sagemaker.ListTrainingJobsInput{},
+		func(page *sagemaker.ListTrainingJobsOutput, lastPage bool) bool {
+			for _, trainingJob := range page.TrainingJobSummaries {
+				resource, err := NewResource(*trainingJob.TrainingJobArn, trainingJob)
+				if err != nil {
+					result.Error = err
+					return false
+				}
+
+				// only running jobs incur costs, describe them to get their instance type
+				if *trainingJob.TrainingJobStatus == sagemaker.TrainingJobStatusInProgress {
+					described, err := client.DescribeTrainingJob(&sagemaker.DescribeTrainingJobInput{
+						TrainingJobName: trainingJob.TrainingJobName,
+					})
+					if err != nil {
+						result.Error = err
+						return false
+					}
+					resource.Metadata["ResourceConfig"] = structs.Map(described.ResourceConfig)
+				}
+
+				result.Resources = append(result.Resources, *resource)
+			}
+
+			return true
+		})
+
+	if result.Error != nil {
+		return result
+	}
+	result.Error = err
+	return result
+}
+
+func SageMakerListDomains(session *Session) *ReportResult {
+	client := sagemaker.New(session.Session, session.Config)
+
+	result := &ReportResult{}
+	err := client.ListDomainsPages(&sagemaker.ListDomainsInput{},
+		func(page *sagemaker.ListDomainsOutput, lastPage bool) bool {
+			for _, domain := range page.Domains {
+				described, err := client.DescribeDomain(&sagemaker.DescribeDomainInput{DomainId: domain.DomainId})
+				if err != nil {
+					result.Error = err
+					return false
+				}
+
+				resource, err := NewResource(*domain.DomainArn, described)
+				if err != nil {
+					result.Error = err
+					return false
+				}
+				result.Resources = append(result.Resources, *resource)
+			}
+
+			return true
+		})
+
+	if result.Error != nil {
+		return result
+	}
+	result.Error = err
+	return result
+}
@@ -0,0 +1,199 @@
+package resources
+
+import (
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/securityhub"
+	"github.com/fatih/structs"
+)
+
+var (
+	SecurityHubService = Service{
+		Name: "securityhub",
+		Reports: map[string]Report{
+			"hub":       SecurityHubDescribeHub,
+			"standards": SecurityHubListStandards,
+			"controls":  SecurityHubListControls,
+			"findings":  SecurityHubListFindings,
+		},
+	}
+
+	// SecurityHubFindingSeverities only reports the findings with one of
+	// these severity labels (e.g. CRITICAL) in securityhub:findings, all the
+	// findings are reported when empty.
+	SecurityHubFindingSeverities = []string{}
+)
+
+// Security Hub returns InvalidAccessException when it is not enabled in the
+// account and region.
+func securityHubNotEnabled(err error) bool {
+	return IsErrorCode(err, securityhub.ErrCodeInvalidAccessException)
+}
+
+func securityHubListStandards(client *securityhub.SecurityHub) ([]*securityhub.StandardsSubscription, error) {
+	subscriptions := []*securityhub.StandardsSubscription{}
+	err := client.GetEnabledStandardsPages(&securityhub.GetEnabledStandardsInput{},
+		func(page *securityhub.GetEnabledStandardsOutput, lastPage bool) bool {
+			subscriptions = append(subscriptions, page.StandardsSubscriptions...)
+			return true
+		})
+	return subscriptions, err
+}
+
+func SecurityHubDescribeHub(session *Session) *ReportResult {
+	client := securityhub.New(session.Session, session.Config)
+
+	resource := Resource{
+		ID:        session.AccountID,
+		AccountID: session.AccountID,
+		Service:   "securityhub",
+		Type:      "hub",
+		Region:    *session.Config.Region,
+		Metadata: map[string]interface{}{
+			"Enabled": false,
+		},
+	}
+
+	hub, err := client.DescribeHub(&securityhub.DescribeHubInput{})
+	if err != nil {
+		if !securityHubNotEnabled(err) {
+			return &ReportResult{nil, err}
+		}
+		return &ReportResult{[]Resource{resource}, nil}
+	}
+
+	for key, value := range structs.Map(hub) {
+		resource.Metadata[key] = value
+	}
+	resource.Metadata["Enabled"] = true
+	resource.ARN = aws.StringValue(hub.HubArn)
+
+	return &ReportResult{[]Resource{resource}, nil}
+}
+
+func SecurityHubListStandards(session *Session) *ReportResult {
+	client := securityhub.New(session.Session, session.Config)
+
+	subscriptions, err := securityHubListStandards(client)
+	if err != nil {
+		if securityHubNotEnabled(err) {
+			return &ReportResult{}
+		}
+		return &ReportResult{nil, err}
+	}
+
+	result := &ReportResult{}
+	for _, subscription := range subscriptions {
+		result.Resources = append(result.Resources, Resource{
+			ID:        *subscription.StandardsSubscriptionArn,
+			ARN:       *subscription.StandardsSubscriptionArn,
+			AccountID: session.AccountID,
+			Service:   "securityhub",
+			Type:      "standard-subscription",
+			Region:    *session.Config.Region,
+			Metadata:  structs.Map(subscription),
+		})
+	}
+
+	return result
+}
+
+// SecurityHubListControls lists the controls of the enabled standards with
+// their status (ENABLED or DISABLED and the reason).
+func SecurityHubListControls(session *Session) *ReportResult {
+	client := securityhub.New(session.Session, session.Config)
+
+	subscriptions, err := securityHubListStandards(client)
+	if err != nil {
+		if securityHubNotEnabled(err) {
+			return &ReportResult{}
+		}
+		return &ReportResult{nil, err}
+	}
+
+	result := &ReportResult{}
+	for _, subscription := range subscriptions {
+		if aws.StringValue(subscription.StandardsStatus) != securityhub.StandardsStatusReady {
+			continue
+		}
+
+		input := &securityhub.DescribeStandardsControlsInput{StandardsSubscriptionArn: subscription.StandardsSubscriptionArn}
+		err := client.DescribeStandardsControlsPages(input,
+			func(page *securityhub.DescribeStandardsControlsOutput, lastPage bool) bool {
+				for _, control := range page.Controls {
+					resource := Resource{
+						ID:        *control.StandardsControlArn,
+						ARN:       *control.StandardsControlArn,
+						AccountID: session.AccountID,
+						Service:   "securityhub",
+						Type:      "control",
+						Region:    *session.Config.Region,
+						Metadata:  structs.Map(control),
+					}
+					resource.Metadata["StandardsArn"] = aws.StringValue(subscription.StandardsArn)
+					result.Resources = append(result.Resources, resource)
+				}
+				return true
+			})
+		if err != nil {
+			result.Error = err
+			return result
+		}
+	}
+
+	return result
+}
+
+func securityHubStringFilters(values ...string) []*securityhub.StringFilter {
+	filters := []*securityhub.StringFilter{}
+	for _, value := range values {
+		filters = append(filters, &securityhub.StringFilter{
+			Comparison: aws.String(securityhub.StringFilterComparisonEquals),
+			Value:      aws.String(strings.ToUpper(value)),
+		})
+	}
+	return filters
+}
+
+// SecurityHubListFindings lists the active findings that are not resolved or
+// suppressed, filtered by SecurityHubFindingSeverities. The findings have no
+// ARN so they are not matched with the terraform state of their resources.
+func SecurityHubListFindings(session *Session) *ReportResult {
+	client := securityhub.New(session.Session, session.Config)
+
+	filters := &securityhub.AwsSecurityFindingFilters{
+		RecordState: securityHubStringFilters(securityhub.RecordStateActive),
+		WorkflowStatus: securityHubStringFilters(
+			securityhub.WorkflowStatusNew,
+			securityhub.WorkflowStatusNotified,
+		),
+	}
+	if len(SecurityHubFindingSeverities) > 0 {
+		filters.SeverityLabel = securityHubStringFilters(SecurityHubFindingSeverities...)
+	}
+
+	result := &ReportResult{}
+	err := client.GetFindingsPages(&securityhub.GetFindingsInput{Filters: filters},
+		func(page *securityhub.GetFindingsOutput, lastPage bool) bool {
+			for _, finding := range page.Findings {
+				result.Resources = append(result.Resources, Resource{
+					ID:        *finding.Id,
+					AccountID: aws.StringValue(finding.AwsAccountId),
+					Service:   "securityhub",
+					Type:      "finding",
+					Region:    *session.Config.Region,
+					Metadata:  structs.Map(finding),
+				})
+			}
+			return true
+		})
+	if err != nil {
+		if securityHubNotEnabled(err) {
+			return &ReportResult{}
+		}
+		result.Error = err
+	}
+
+	return result
+}
@@ -0,0 +1,207 @@
+package resources
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/servicecatalog"
+	"github.com/fatih/structs"
+)
+
+var (
+	ServiceCatalogService = Service{
+		Name: "servicecatalog",
+		Reports: map[string]Report{
+			"portfolios":             ServiceCatalogListPortfolios,
+			"products":               ServiceCatalogListProducts,
+			"provisioning-artifacts": ServiceCatalogListProvisioningArtifacts,
+			"constraints":            ServiceCatalogListConstraints,
+			"provisioned-products":   ServiceCatalogListProvisionedProducts,
+		},
+	}
+)
+
+func serviceCatalogListPortfolios(client *servicecatalog.ServiceCatalog) ([]*servicecatalog.PortfolioDetail, error) {
+	portfolios := []*servicecatalog.PortfolioDetail{}
+	err := client.ListPortfoliosPages(&servicecatalog.ListPortfoliosInput{},
+		func(page *servicecatalog.ListPortfoliosOutput, lastPage bool) bool {
+			portfolios = append(portfolios, page.PortfolioDetails...)
+			return true
+		})
+	return portfolios, err
+}
+
+func serviceCatalogListProducts(client *servicecatalog.ServiceCatalog) ([]*servicecatalog.ProductViewDetail, error) {
+	products := []*servicecatalog.ProductViewDetail{}
+	err := client.SearchProductsAsAdminPages(&servicecatalog.SearchProductsAsAdminInput{},
+		func(page *servicecatalog.SearchProductsAsAdminOutput, lastPage bool) bool {
+			products = append(products, page.ProductViewDetails...)
+			return true
+		})
+	return products, err
+}
+
+func ServiceCatalogListPortfolios(session *Session) *ReportResult {
+	client := servicecatalog.New(session.Session, session.Config)
+
+	portfolios, err := serviceCatalogListPortfolios(client)
+	if err != nil {
+		return &ReportResult{nil, err}
+	}
+
+	result := &ReportResult{}
+	for _, portfolio := range portfolios {
+		resource, err := NewResource(*portfolio.ARN, portfolio)
+		if err != nil {
+			result.Error = err
+			return result
+		}
+		resource.ID = *portfolio.Id
+		result.Resources = append(result.Resources, *resource)
+	}
+
+	return result
+}
+
+func ServiceCatalogListProducts(session *Session) *ReportResult {
+	client := servicecatalog.New(session.Session, session.Config)
+
+	products, err := serviceCatalogListProducts(client)
+	if err != nil {
+		return &ReportResult{nil, err}
+	}
+
+	result := &ReportResult{}
+	for _, product := range products {
+		resource, err := NewResource(*product.ProductARN, product)
+		if err != nil {
+			result.Error = err
+			return result
+		}
+		resource.ID = aws.StringValue(product.ProductViewSummary.ProductId)
+		result.Resources = append(result.Resources, *resource)
+	}
+
+	return result
+}
+
+func ServiceCatalogListProvisioningArtifacts(session *Session) *ReportResult {
+	client := servicecatalog.New(session.Session, session.Config)
+
+	products, err := serviceCatalogListProducts(client)
+	if err != nil {
+		return &ReportResult{nil, err}
+	}
+
+	result := &ReportResult{}
+	for _, product := range products {
+		productID := product.ProductViewSummary.ProductId
+
+		res, err := client.ListProvisioningArtifacts(&servicecatalog.ListProvisioningArtifactsInput{ProductId: productID})
+		if err != nil {
+			result.Error = err
+			return result
+		}
+
+		for _, artifact := range res.ProvisioningArtifactDetails {
+			resource := Resource{
+				ID:        *artifact.Id,
+				AccountID: session.AccountID,
+				Service:   "servicecatalog",
+				Type:      "provisioning-artifact",
+				Region:    *session.Config.Region,
+				Metadata:  structs.Map(artifact),
+			}
+			resource.Metadata["ProductId"] = *productID
+			result.Resources = append(result.Resources, resource)
+		}
+	}
+
+	return result
+}
+
+// ServiceCatalogListConstraints lists the constraints of every portfolio, with
+// the parameters (e.g. the launch role) of launch constraints.
+func ServiceCatalogListConstraints(session *Session) *ReportResult {
+	client := servicecatalog.New(session.Session, session.Config)
+
+	portfolios, err := serviceCatalogListPortfolios(client)
+	if err != nil {
+		return &ReportResult{nil, err}
+	}
+
+	result := &ReportResult{}
+	for _, portfolio := range portfolios {
+		err := client.ListConstraintsForPortfolioPages(&servicecatalog.ListConstraintsForPortfolioInput{PortfolioId: portfolio.Id},
+			func(page *servicecatalog.ListConstraintsForPortfolioOutput, lastPage bool) bool {
+				for _, constraint := range page.ConstraintDetails {
+					resource := Resource{
+						ID:        *constraint.ConstraintId,
+						AccountID: session.AccountID,
+						Service:   "servicecatalog",
+						Type:      "constraint",
+						Region:    *session.Config.Region,
+						Metadata:  structs.Map(constraint),
+					}
+
+					if aws.StringValue(constraint.Type) == "LAUNCH" {
+						described, err := client.DescribeConstraint(&servicecatalog.DescribeConstraintInput{Id: constraint.ConstraintId})
+						if err != nil {
+							result.Error = err
+							return false
+						}
+						resource.Metadata["ConstraintParameters"] = aws.StringValue(described.ConstraintParameters)
+					}
+
+					result.Resources = append(result.Resources, resource)
+				}
+				return true
+			})
+
+		if result.Error != nil {
+			return result
+		}
+		if err != nil {
+			result.Error = err
+			return result
+		}
+	}
+
+	return result
+}
+
+func ServiceCatalogListProvisionedProducts(session *Session) *ReportResult {
+	client := servicecatalog.New(session.Session, session.Config)
+
+	result := &ReportResult{}
+	input := &servicecatalog.SearchProvisionedProductsInput{
+		AccessLevelFilter: &servicecatalog.AccessLevelFilter{
+			Key:   aws.String(servicecatalog.AccessLevelFilterKeyAccount),
+			Value: aws.String("self"),
+		},
+	}
+	for {
+		page, err := client.SearchProvisionedProducts(input)
+		if err != nil {
+			result.Error = err
+			return result
+		}
+
+		for _, provisionedProduct := range page.ProvisionedProducts {
+			result.Resources = append(result.Resources, Resource{
+				ID:        *provisionedProduct.Id,
+				ARN:       aws.StringValue(provisionedProduct.Arn),
+				AccountID: session.AccountID,
+				Service:   "servicecatalog",
+				Type:      "provisioned-product",
+				Region:    *session.Config.Region,
+				Metadata:  structs.Map(provisionedProduct),
+			})
+		}
+
+		if page.NextPageToken == nil {
+			break
+		}
+		input.PageToken = page.NextPageToken
+	}
+
+	return result
+}
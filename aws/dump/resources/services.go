@@ -5,18 +5,55 @@ import (
 	"sort"
 )
 
+//go:generate go run actions_gen.go
+
 func AllServices() map[string]Service {
 	return map[string]Service{
-		"acm":         ACMService,
-		"autoscaling": AutoScalingService,
-		"cloudwatch":  CloudwatchService,
-		"ec2":         EC2Service,
-		"iam":         IAMService,
-		"kms":         KMSService,
-		"lambda":      LambdaService,
-		"route53":     Route53Service,
-		"s3":          S3Service,
-		"rds":         RDSService,
+		"accessanalyzer":        AccessAnalyzerService,
+		"acm":                   ACMService,
+		"apigateway":            APIGatewayService,
+		"apprunner":             AppRunnerService,
+		"appsync":               AppSyncService,
+		"autoscaling":           AutoScalingService,
+		"backup":                BackupService,
+		"cloudformation":        CloudFormationService,
+		"cloudfront":            CloudFrontService,
+		"cloudwatch":            CloudwatchService,
+		"codesuite":             CodeSuiteService,
+		"cognito":               CognitoService,
+		"directconnect":         DirectConnectService,
+		"directconnect-gateway": DirectConnectGatewayService,
+		"dms":                   DMSService,
+		"docdb":                 DocDBService,
+		"ec2":                   EC2Service,
+		"efs":                   EFSService,
+		"elasticbeanstalk":      ElasticBeanstalkService,
+		"elbv2":                 ELBv2Service,
+		"events":                EventsService,
+		"firehose":              FirehoseService,
+		"fsx":                   FSxService,
+		"globalaccelerator":     GlobalAcceleratorService,
+		"iam":                   IAMService,
+		"iot":                   IoTService,
+		"kinesis":               KinesisService,
+		"kms":                   KMSService,
+		"lambda":                LambdaService,
+		"licensemanager":        LicenseManagerService,
+		"lightsail":             LightsailService,
+		"mq":                    MQService,
+		"msk":                   MSKService,
+		"neptune":               NeptuneService,
+		"route53":               Route53Service,
+		"s3":                    S3Service,
+		"sagemaker":             SageMakerService,
+		"securityhub":           SecurityHubService,
+		"servicecatalog":        ServiceCatalogService,
+		"sharing":               SharingService,
+		"shield":                ShieldService,
+		"rds":                   RDSService,
+		"transitgateway":        TransitGatewayService,
+		"waf":                   WAFService,
+		"waf-cloudfront":        WAFCloudFrontService,
 	}
 }
 
@@ -26,6 +63,9 @@ func AllReports() []string {
 		for reportName, _ := range service.Reports {
 			reports = append(reports, fmt.Sprintf("%s:%s", service.Name, reportName))
 		}
+		for reportName, _ := range service.OptionalReports {
+			reports = append(reports, fmt.Sprintf("%s:%s", service.Name, reportName))
+		}
 	}
 	sort.Strings(reports)
 	return reports
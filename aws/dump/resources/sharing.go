@@ -0,0 +1,349 @@
+package resources
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ecr"
+	"github.com/aws/aws-sdk-go/service/rds"
+)
+
+var (
+	SharingService = Service{
+		Name: "sharing",
+		Reports: map[string]Report{
+			"findings": SharingListFindings,
+		},
+	}
+)
+
+// SharingListFindings reports the EBS snapshots, AMIs, RDS snapshots and ECR
+// repositories that are public or shared with other accounts.
+func SharingListFindings(session *Session) *ReportResult {
+	result := &ReportResult{}
+	errors := ReportErrors{}
+	for _, report := range []Report{
+		sharingEBSSnapshots,
+		sharingImages,
+		sharingDBSnapshots,
+		sharingDBClusterSnapshots,
+		sharingECRRepositories,
+	} {
+		reportResult := report(session)
+		result.Resources = append(result.Resources, reportResult.Resources...)
+		if reportResult.Error != nil && !errors.Add(reportResult.Error) {
+			break
+		}
+	}
+	result.Error = errors.ErrorOrNil()
+	return result
+}
+
+func newSharingFinding(session *Session, resourceType string, id string, arn string, principals []string) *Resource {
+	public, sharedWith := classifyPrincipals(principals, session.AccountID)
+	if !public && len(sharedWith) == 0 {
+		return nil
+	}
+
+	return &Resource{
+		ID:        id,
+		ARN:       arn,
+		AccountID: session.AccountID,
+		Service:   "sharing",
+		Type:      resourceType,
+		Region:    *session.Config.Region,
+		Metadata: map[string]interface{}{
+			"Severity":   "high",
+			"Public":     public,
+			"SharedWith": sharedWith,
+		},
+	}
+}
+
+// classifyPrincipals returns whether the principals include everyone and the
+// other accounts (or organisations) they include.
+func classifyPrincipals(principals []string, accountID string) (bool, []string) {
+	public := false
+	accounts := map[string]bool{}
+	for _, principal := range principals {
+		switch {
+		case principal == "":
+			continue
+		case principal == "all" || principal == "*":
+			public = true
+		case strings.HasPrefix(principal, "arn:"):
+			parts := strings.SplitN(principal, ":", 6)
+			if len(parts) == 6 && (parts[2] == "iam" || parts[2] == "sts") {
+				principal = parts[4]
+			}
+			if principal != accountID {
+				accounts[principal] = true
+			}
+		case principal != accountID:
+			accounts[principal] = true
+		}
+	}
+
+	sharedWith := []string{}
+	for account := range accounts {
+		sharedWith = append(sharedWith, account)
+	}
+	sort.Strings(sharedWith)
+	return public, sharedWith
+}
+
+type policyStatement struct {
+	Effect    string
+	Principal interface{}
+}
+
+// policyPrincipals returns the AWS principals allowed by the statements of a
+// resource policy. Conditions are ignored.
+func policyPrincipals(document string) ([]string, error) {
+	policy := struct {
+		Statement json.RawMessage
+	}{}
+	err := json.Unmarshal([]byte(document), &policy)
+	if err != nil {
+		return nil, err
+	}
+
+	statements := []policyStatement{}
+	if err := json.Unmarshal(policy.Statement, &statements); err != nil {
+		statement := policyStatement{}
+		err = json.Unmarshal(policy.Statement, &statement)
+		if err != nil {
+			return nil, err
+		}
+		statements = append(statements, statement)
+	}
+
+	principals := []string{}
+	for _, statement := range statements {
+		if statement.Effect != "Allow" {
+			continue
+		}
+
+		switch principal := statement.Principal.(type) {
+		case string:
+			principals = append(principals, principal)
+		case map[string]interface{}:
+			switch awsPrincipal := principal["AWS"].(type) {
+			case string:
+				principals = append(principals, awsPrincipal)
+			case []interface{}:
+				for _, item := range awsPrincipal {
+					if value, ok := item.(string); ok {
+						principals = append(principals, value)
+					}
+				}
+			}
+		}
+	}
+	return principals, nil
+}
+
+func sharingEBSSnapshots(session *Session) *ReportResult {
+	client := ec2.New(session.Session, session.Config)
+
+	result := &ReportResult{}
+	snapshots := []*ec2.Snapshot{}
+	err := client.DescribeSnapshotsPages(&ec2.DescribeSnapshotsInput{OwnerIds: aws.StringSlice([]string{"self"})},
+		func(page *ec2.DescribeSnapshotsOutput, lastPage bool) bool {
+			snapshots = append(snapshots, page.Snapshots...)
+			return true
+		})
+	if err != nil {
+		result.Error = err
+		return result
+	}
+
+	for _, snapshot := range snapshots {
+		res, err := client.DescribeSnapshotAttribute(&ec2.DescribeSnapshotAttributeInput{
+			SnapshotId: snapshot.SnapshotId,
+			Attribute:  aws.String(ec2.SnapshotAttributeNameCreateVolumePermission),
+		})
+		if err != nil {
+			result.Error = err
+			return result
+		}
+
+		principals := []string{}
+		for _, permission := range res.CreateVolumePermissions {
+			principals = append(principals, aws.StringValue(permission.Group), aws.StringValue(permission.UserId))
+		}
+
+		finding := newSharingFinding(session, "ebs-snapshot", *snapshot.SnapshotId, "", principals)
+		if finding != nil {
+			result.Resources = append(result.Resources, *finding)
+		}
+	}
+
+	return result
+}
+
+func sharingImages(session *Session) *ReportResult {
+	client := ec2.New(session.Session, session.Config)
+
+	result := &ReportResult{}
+	res, err := client.DescribeImages(&ec2.DescribeImagesInput{Owners: aws.StringSlice([]string{"self"})})
+	if err != nil {
+		result.Error = err
+		return result
+	}
+
+	for _, image := range res.Images {
+		attribute, err := client.DescribeImageAttribute(&ec2.DescribeImageAttributeInput{
+			ImageId:   image.ImageId,
+			Attribute: aws.String(ec2.ImageAttributeNameLaunchPermission),
+		})
+		if err != nil {
+			result.Error = err
+			return result
+		}
+
+		principals := []string{}
+		if aws.BoolValue(image.Public) {
+			principals = append(principals, "all")
+		}
+		for _, permission := range attribute.LaunchPermissions {
+			principals = append(principals,
+				aws.StringValue(permission.Group),
+				aws.StringValue(permission.UserId),
+				aws.StringValue(permission.OrganizationArn),
+				aws.StringValue(permission.OrganizationalUnitArn),
+			)
+		}
+
+		finding := newSharingFinding(session, "image", *image.ImageId, "", principals)
+		if finding != nil {
+			result.Resources = append(result.Resources, *finding)
+		}
+	}
+
+	return result
+}
+
+// only manual snapshots can be shared
+func sharingDBSnapshots(session *Session) *ReportResult {
+	client := rds.New(session.Session, session.Config)
+
+	result := &ReportResult{}
+	snapshots := []*rds.DBSnapshot{}
+	err := client.DescribeDBSnapshotsPages(&rds.DescribeDBSnapshotsInput{SnapshotType: aws.String("manual")},
+		func(page *rds.DescribeDBSnapshotsOutput, lastPage bool) bool {
+			snapshots = append(snapshots, page.DBSnapshots...)
+			return true
+		})
+	if err != nil {
+		result.Error = err
+		return result
+	}
+
+	for _, snapshot := range snapshots {
+		res, err := client.DescribeDBSnapshotAttributes(&rds.DescribeDBSnapshotAttributesInput{
+			DBSnapshotIdentifier: snapshot.DBSnapshotIdentifier,
+		})
+		if err != nil {
+			result.Error = err
+			return result
+		}
+
+		principals := []string{}
+		for _, attribute := range res.DBSnapshotAttributesResult.DBSnapshotAttributes {
+			if aws.StringValue(attribute.AttributeName) == "restore" {
+				principals = append(principals, aws.StringValueSlice(attribute.AttributeValues)...)
+			}
+		}
+
+		finding := newSharingFinding(session, "db-snapshot", *snapshot.DBSnapshotIdentifier, aws.StringValue(snapshot.DBSnapshotArn), principals)
+		if finding != nil {
+			result.Resources = append(result.Resources, *finding)
+		}
+	}
+
+	return result
+}
+
+func sharingDBClusterSnapshots(session *Session) *ReportResult {
+	client := rds.New(session.Session, session.Config)
+
+	result := &ReportResult{}
+	snapshots := []*rds.DBClusterSnapshot{}
+	err := client.DescribeDBClusterSnapshotsPages(&rds.DescribeDBClusterSnapshotsInput{SnapshotType: aws.String("manual")},
+		func(page *rds.DescribeDBClusterSnapshotsOutput, lastPage bool) bool {
+			snapshots = append(snapshots, page.DBClusterSnapshots...)
+			return true
+		})
+	if err != nil {
+		result.Error = err
+		return result
+	}
+
+	for _, snapshot := range snapshots {
+		res, err := client.DescribeDBClusterSnapshotAttributes(&rds.DescribeDBClusterSnapshotAttributesInput{
+			DBClusterSnapshotIdentifier: snapshot.DBClusterSnapshotIdentifier,
+		})
+		if err != nil {
+			result.Error = err
+			return result
+		}
+
+		principals := []string{}
+		for _, attribute := range res.DBClusterSnapshotAttributesResult.DBClusterSnapshotAttributes {
+			if aws.StringValue(attribute.AttributeName) == "restore" {
+				principals = append(principals, aws.StringValueSlice(attribute.AttributeValues)...)
+			}
+		}
+
+		finding := newSharingFinding(session, "db-cluster-snapshot", *snapshot.DBClusterSnapshotIdentifier, aws.StringValue(snapshot.DBClusterSnapshotArn), principals)
+		if finding != nil {
+			result.Resources = append(result.Resources, *finding)
+		}
+	}
+
+	return result
+}
+
+func sharingECRRepositories(session *Session) *ReportResult {
+	client := ecr.New(session.Session, session.Config)
+
+	result := &ReportResult{}
+	repositories := []*ecr.Repository{}
+	err := client.DescribeRepositoriesPages(&ecr.DescribeRepositoriesInput{},
+		func(page *ecr.DescribeRepositoriesOutput, lastPage bool) bool {
+			repositories = append(repositories, page.Repositories...)
+			return true
+		})
+	if err != nil {
+		result.Error = err
+		return result
+	}
+
+	for _, repository := range repositories {
+		res, err := client.GetRepositoryPolicy(&ecr.GetRepositoryPolicyInput{RepositoryName: repository.RepositoryName})
+		if err != nil {
+			if IsErrorCode(err, ecr.ErrCodeRepositoryPolicyNotFoundException) {
+				continue
+			}
+			result.Error = err
+			return result
+		}
+
+		principals, err := policyPrincipals(aws.StringValue(res.PolicyText))
+		if err != nil {
+			result.Error = err
+			return result
+		}
+
+		finding := newSharingFinding(session, "ecr-repository", *repository.RepositoryName, aws.StringValue(repository.RepositoryArn), principals)
+		if finding != nil {
+			result.Resources = append(result.Resources, *finding)
+		}
+	}
+
+	return result
+}
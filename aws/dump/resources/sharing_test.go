@@ -0,0 +1,43 @@
+package resources
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClassifyPrincipals(t *testing.T) {
+	public, sharedWith := classifyPrincipals([]string{"", "123456789012", "all"}, "123456789012")
+	require.True(t, public)
+	require.Equal(t, []string{}, sharedWith)
+
+	public, sharedWith = classifyPrincipals([]string{
+		"arn:aws:iam::210987654321:root",
+		"arn:aws:iam::123456789012:role/Deploy",
+		"210987654321",
+		"arn:aws:organizations::111111111111:organization/o-abcdefghij",
+	}, "123456789012")
+	require.False(t, public)
+	require.Equal(t, []string{"210987654321", "arn:aws:organizations::111111111111:organization/o-abcdefghij"}, sharedWith)
+}
+
+func TestPolicyPrincipals(t *testing.T) {
+	principals, err := policyPrincipals(`{
+		"Version": "2012-10-17",
+		"Statement": [
+			{"Effect": "Allow", "Principal": "*", "Action": "ecr:BatchGetImage"},
+			{"Effect": "Allow", "Principal": {"AWS": ["arn:aws:iam::210987654321:root", "333333333333"]}, "Action": "ecr:BatchGetImage"},
+			{"Effect": "Allow", "Principal": {"Service": "codebuild.amazonaws.com"}, "Action": "ecr:BatchGetImage"},
+			{"Effect": "Deny", "Principal": {"AWS": "444444444444"}, "Action": "ecr:*"}
+		]
+	}`)
+	require.NoError(t, err)
+	require.Equal(t, []string{"*", "arn:aws:iam::210987654321:root", "333333333333"}, principals)
+
+	principals, err = policyPrincipals(`{"Statement": {"Effect": "Allow", "Principal": {"AWS": "*"}}}`)
+	require.NoError(t, err)
+	require.Equal(t, []string{"*"}, principals)
+
+	_, err = policyPrincipals("not json")
+	require.Error(t, err)
+}
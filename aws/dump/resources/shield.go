@@ -0,0 +1,155 @@
+package resources
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/shield"
+	"github.com/fatih/structs"
+)
+
+var (
+	ShieldService = Service{
+		Name:     "shield",
+		IsGlobal: true,
+		Reports: map[string]Report{
+			"subscription":      ShieldDescribeSubscription,
+			"protections":       ShieldListProtections,
+			"protection-groups": ShieldListProtectionGroups,
+			"attacks":           ShieldListAttacks,
+		},
+	}
+)
+
+// Shield Advanced is only available in us-east-1
+func newShieldClient(session *Session) *shield.Shield {
+	return shield.New(session.Session, session.Config.Copy(&aws.Config{Region: aws.String("us-east-1")}))
+}
+
+func ShieldDescribeSubscription(session *Session) *ReportResult {
+	client := newShieldClient(session)
+
+	state, err := client.GetSubscriptionState(&shield.GetSubscriptionStateInput{})
+	if err != nil {
+		return &ReportResult{nil, err}
+	}
+
+	resource := Resource{
+		ID:        session.AccountID,
+		AccountID: session.AccountID,
+		Service:   "shield",
+		Type:      "subscription",
+		Metadata: map[string]interface{}{
+			"SubscriptionState": *state.SubscriptionState,
+		},
+	}
+
+	if *state.SubscriptionState == shield.SubscriptionStateActive {
+		subscription, err := client.DescribeSubscription(&shield.DescribeSubscriptionInput{})
+		if err != nil {
+			return &ReportResult{nil, err}
+		}
+		for key, value := range structs.Map(subscription.Subscription) {
+			resource.Metadata[key] = value
+		}
+		resource.ARN = aws.StringValue(subscription.Subscription.SubscriptionArn)
+	}
+
+	return &ReportResult{[]Resource{resource}, nil}
+}
+
+func ShieldListProtections(session *Session) *ReportResult {
+	client := newShieldClient(session)
+
+	result := &ReportResult{}
+	input := &shield.ListProtectionsInput{}
+	for {
+		page, err := client.ListProtections(input)
+		if err != nil {
+			// raised when there are no protections
+			if IsErrorCode(err, shield.ErrCodeResourceNotFoundException) {
+				break
+			}
+			result.Error = err
+			return result
+		}
+
+		for _, protection := range page.Protections {
+			resource, err := NewResource(*protection.ProtectionArn, protection)
+			if err != nil {
+				result.Error = err
+				return result
+			}
+			result.Resources = append(result.Resources, *resource)
+		}
+
+		if page.NextToken == nil {
+			break
+		}
+		input.NextToken = page.NextToken
+	}
+
+	return result
+}
+
+func ShieldListProtectionGroups(session *Session) *ReportResult {
+	client := newShieldClient(session)
+
+	result := &ReportResult{}
+	input := &shield.ListProtectionGroupsInput{}
+	for {
+		page, err := client.ListProtectionGroups(input)
+		if err != nil {
+			if IsErrorCode(err, shield.ErrCodeResourceNotFoundException) {
+				break
+			}
+			result.Error = err
+			return result
+		}
+
+		for _, protectionGroup := range page.ProtectionGroups {
+			resource, err := NewResource(*protectionGroup.ProtectionGroupArn, protectionGroup)
+			if err != nil {
+				result.Error = err
+				return result
+			}
+			result.Resources = append(result.Resources, *resource)
+		}
+
+		if page.NextToken == nil {
+			break
+		}
+		input.NextToken = page.NextToken
+	}
+
+	return result
+}
+
+func ShieldListAttacks(session *Session) *ReportResult {
+	client := newShieldClient(session)
+
+	result := &ReportResult{}
+	input := &shield.ListAttacksInput{}
+	for {
+		page, err := client.ListAttacks(input)
+		if err != nil {
+			result.Error = err
+			return result
+		}
+
+		for _, attack := range page.AttackSummaries {
+			result.Resources = append(result.Resources, Resource{
+				ID:        *attack.AttackId,
+				AccountID: session.AccountID,
+				Service:   "shield",
+				Type:      "attack",
+				Metadata:  structs.Map(attack),
+			})
+		}
+
+		if page.NextToken == nil {
+			break
+		}
+		input.NextToken = page.NextToken
+	}
+
+	return result
+}
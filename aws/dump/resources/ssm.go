@@ -0,0 +1,108 @@
+package resources
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/fatih/structs"
+)
+
+const (
+	ssmInventoryTypeApplication = "AWS:Application"
+)
+
+// AttachSSMInventory adds the SSM agent, OS and installed applications
+// summary to the ec2 instances found in resources.
+func AttachSSMInventory(accounts []*Account, resources []Resource) []error {
+	errors := []error{}
+
+	instances := map[string]Resource{}
+	for _, resource := range resources {
+		if resource.Service != "ec2" || resource.Type != "instance" {
+			continue
+		}
+		instances[fmt.Sprintf("%s_%s_%s", resource.AccountID, resource.Region, resource.ID)] = resource
+	}
+
+	if len(instances) == 0 {
+		return errors
+	}
+
+	for _, account := range accounts {
+		for _, session := range account.Sessions {
+			err := attachSessionSSMInventory(session, instances)
+			if err != nil {
+				errors = append(errors, err)
+			}
+		}
+	}
+
+	return errors
+}
+
+func attachSessionSSMInventory(session *Session, instances map[string]Resource) error {
+	client := ssm.New(session.Session, session.Config)
+
+	var inventoryErr error
+	err := client.DescribeInstanceInformationPages(&ssm.DescribeInstanceInformationInput{},
+		func(page *ssm.DescribeInstanceInformationOutput, lastPage bool) bool {
+			for _, information := range page.InstanceInformationList {
+				key := fmt.Sprintf("%s_%s_%s", session.AccountID, *session.Config.Region, *information.InstanceId)
+				instance, ok := instances[key]
+				if !ok {
+					// on-premises managed instance or not part of the dump
+					continue
+				}
+
+				applications, err := listSSMInventoryApplications(client, *information.InstanceId)
+				if err != nil {
+					inventoryErr = err
+					return false
+				}
+
+				inventory := structs.Map(information)
+				inventory["Applications"] = map[string]interface{}{
+					"Count": len(applications),
+					"Names": applications,
+				}
+				instance.Metadata["SSMInventory"] = inventory
+			}
+			return true
+		})
+
+	if err != nil {
+		return err
+	}
+	return inventoryErr
+}
+
+func listSSMInventoryApplications(client *ssm.SSM, instanceID string) ([]string, error) {
+	applications := []string{}
+
+	input := &ssm.ListInventoryEntriesInput{
+		InstanceId: aws.String(instanceID),
+		TypeName:   aws.String(ssmInventoryTypeApplication),
+	}
+	for {
+		res, err := client.ListInventoryEntries(input)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, entry := range res.Entries {
+			name := aws.StringValue(entry["Name"])
+			if version := aws.StringValue(entry["Version"]); version != "" {
+				name = fmt.Sprintf("%s %s", name, version)
+			}
+			applications = append(applications, name)
+		}
+
+		if res.NextToken == nil {
+			break
+		}
+		input.NextToken = res.NextToken
+	}
+
+	return applications, nil
+}
@@ -0,0 +1,96 @@
+package resources
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/resourcegroupstaggingapi"
+)
+
+const (
+	UnmanagedTagKey       = "managed-by"
+	UnmanagedTagValue     = "unknown"
+	UnmanagedCheckedAtKey = "managed-by-checked-at"
+
+	// TagResources accepts at most 20 ARNs per call
+	tagResourcesBatchSize = 20
+)
+
+// UnmanagedTags are the tags added to the resources not managed by terraform.
+func UnmanagedTags(timestamp time.Time) map[string]*string {
+	return map[string]*string{
+		UnmanagedTagKey:       aws.String(UnmanagedTagValue),
+		UnmanagedCheckedAtKey: aws.String(timestamp.UTC().Format(time.RFC3339)),
+	}
+}
+
+// TagUnmanaged tags the unmanaged resources with UnmanagedTags using the
+// resource groups tagging API of their account and region and returns the
+// ARNs of the tagged resources. Resources without an ARN are skipped and
+// global resources use the first region of the account. With dryRun nothing is
+// tagged and the ARNs that would be tagged are returned.
+func TagUnmanaged(accounts []*Account, unmanaged []Resource, timestamp time.Time, dryRun bool) ([]string, []error) {
+	sessions := map[string]*Session{}
+	for _, account := range accounts {
+		for i, session := range account.Sessions {
+			if i == 0 {
+				sessions[fmt.Sprintf("%s_", session.AccountID)] = session
+			}
+			sessions[fmt.Sprintf("%s_%s", session.AccountID, *session.Config.Region)] = session
+		}
+	}
+
+	arns := map[*Session][]string{}
+	errors := []error{}
+	for _, resource := range unmanaged {
+		if resource.ARN == "" {
+			continue
+		}
+
+		session, ok := sessions[fmt.Sprintf("%s_%s", resource.AccountID, resource.Region)]
+		if !ok {
+			errors = append(errors, fmt.Errorf("no session to tag %s", resource.ARN))
+			continue
+		}
+		arns[session] = append(arns[session], resource.ARN)
+	}
+
+	tagged := []string{}
+	tags := UnmanagedTags(timestamp)
+	for session, sessionARNs := range arns {
+		if dryRun {
+			tagged = append(tagged, sessionARNs...)
+			continue
+		}
+
+		client := resourcegroupstaggingapi.New(session.Session, session.Config)
+		for start := 0; start < len(sessionARNs); start += tagResourcesBatchSize {
+			end := start + tagResourcesBatchSize
+			if end > len(sessionARNs) {
+				end = len(sessionARNs)
+			}
+			batch := sessionARNs[start:end]
+
+			output, err := client.TagResources(&resourcegroupstaggingapi.TagResourcesInput{
+				ResourceARNList: aws.StringSlice(batch),
+				Tags:            tags,
+			})
+			if err != nil {
+				errors = append(errors, err)
+				continue
+			}
+
+			for _, arn := range batch {
+				failure, failed := output.FailedResourcesMap[arn]
+				if failed {
+					errors = append(errors, fmt.Errorf("failed to tag %s: %s", arn, aws.StringValue(failure.ErrorMessage)))
+					continue
+				}
+				tagged = append(tagged, arn)
+			}
+		}
+	}
+
+	return tagged, errors
+}
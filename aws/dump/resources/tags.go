@@ -0,0 +1,65 @@
+package resources
+
+import "encoding/json"
+
+// the metadata keys of the tags depend on the service
+var tagsMetadataKeys = []string{"Tags", "TagList", "TagSet"}
+
+// Tags returns the tags of the metadata, either a list of Key and Value (or
+// key and value) or a map.
+func (r *Resource) Tags() map[string]string {
+	tags, _ := r.metadataTags()
+	return tags
+}
+
+// Taggable returns whether the metadata has a tags key, even without tags.
+func (r *Resource) Taggable() bool {
+	_, taggable := r.metadataTags()
+	return taggable
+}
+
+func (r *Resource) metadataTags() (map[string]string, bool) {
+	tags := map[string]string{}
+	taggable := false
+
+	// go through JSON to handle the pointers and structs of the metadata
+	var metadata map[string]interface{}
+	data, err := json.Marshal(r.Metadata)
+	if err != nil || json.Unmarshal(data, &metadata) != nil {
+		return tags, taggable
+	}
+
+	for _, metadataKey := range tagsMetadataKeys {
+		value, ok := metadata[metadataKey]
+		if !ok {
+			continue
+		}
+		taggable = true
+
+		switch typed := value.(type) {
+		case map[string]interface{}:
+			for key, value := range typed {
+				if value, ok := value.(string); ok {
+					tags[key] = value
+				}
+			}
+		case []interface{}:
+			for _, item := range typed {
+				tag, ok := item.(map[string]interface{})
+				if !ok {
+					continue
+				}
+				key, _ := tag["Key"].(string)
+				value, _ := tag["Value"].(string)
+				if key == "" {
+					key, _ = tag["key"].(string)
+					value, _ = tag["value"].(string)
+				}
+				if key != "" {
+					tags[key] = value
+				}
+			}
+		}
+	}
+	return tags, taggable
+}
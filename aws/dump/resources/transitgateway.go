@@ -0,0 +1,166 @@
+package resources
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/fatih/structs"
+)
+
+var (
+	TransitGatewayService = Service{
+		Name: "transitgateway",
+		Reports: map[string]Report{
+			"transit-gateways": TransitGatewayListTransitGateways,
+			"attachments":      TransitGatewayListAttachments,
+			"route-tables":     TransitGatewayListRouteTables,
+		},
+	}
+)
+
+func TransitGatewayListTransitGateways(session *Session) *ReportResult {
+	client := ec2.New(session.Session, session.Config)
+
+	result := &ReportResult{}
+	result.Error = client.DescribeTransitGatewaysPages(&ec2.DescribeTransitGatewaysInput{},
+		func(page *ec2.DescribeTransitGatewaysOutput, lastPage bool) bool {
+			for _, transitGateway := range page.TransitGateways {
+				result.Resources = append(result.Resources, Resource{
+					ID:        *transitGateway.TransitGatewayId,
+					ARN:       *transitGateway.TransitGatewayArn,
+					AccountID: *transitGateway.OwnerId,
+					Service:   "ec2",
+					Type:      "transit-gateway",
+					Region:    *session.Config.Region,
+					Metadata:  structs.Map(transitGateway),
+				})
+			}
+
+			return true
+		})
+
+	return result
+}
+
+// TransitGatewayListAttachments lists the VPC, VPN, peering and Direct
+// Connect gateway attachments.
+func TransitGatewayListAttachments(session *Session) *ReportResult {
+	client := ec2.New(session.Session, session.Config)
+
+	result := &ReportResult{}
+	result.Error = client.DescribeTransitGatewayAttachmentsPages(&ec2.DescribeTransitGatewayAttachmentsInput{},
+		func(page *ec2.DescribeTransitGatewayAttachmentsOutput, lastPage bool) bool {
+			for _, attachment := range page.TransitGatewayAttachments {
+				result.Resources = append(result.Resources, Resource{
+					ID: *attachment.TransitGatewayAttachmentId,
+					ARN: fmt.Sprintf("arn:aws:ec2:%s:%s:transit-gateway-attachment/%s",
+						*session.Config.Region,
+						*attachment.TransitGatewayOwnerId,
+						*attachment.TransitGatewayAttachmentId,
+					),
+					AccountID: session.AccountID,
+					Service:   "ec2",
+					Type:      "transit-gateway-attachment",
+					Region:    *session.Config.Region,
+					Metadata:  structs.Map(attachment),
+				})
+			}
+
+			return true
+		})
+
+	return result
+}
+
+func TransitGatewayListRouteTables(session *Session) *ReportResult {
+	client := ec2.New(session.Session, session.Config)
+
+	result := &ReportResult{}
+	err := client.DescribeTransitGatewayRouteTablesPages(&ec2.DescribeTransitGatewayRouteTablesInput{},
+		func(page *ec2.DescribeTransitGatewayRouteTablesOutput, lastPage bool) bool {
+			for _, routeTable := range page.TransitGatewayRouteTables {
+				result.Resources = append(result.Resources, Resource{
+					ID: *routeTable.TransitGatewayRouteTableId,
+					ARN: fmt.Sprintf("arn:aws:ec2:%s:%s:transit-gateway-route-table/%s",
+						*session.Config.Region,
+						session.AccountID,
+						*routeTable.TransitGatewayRouteTableId,
+					),
+					AccountID: session.AccountID,
+					Service:   "ec2",
+					Type:      "transit-gateway-route-table",
+					Region:    *session.Config.Region,
+					Metadata:  structs.Map(routeTable),
+				})
+
+				associations := TransitGatewayListRouteTableAssociations(session, client, *routeTable.TransitGatewayRouteTableId)
+				if associations.Error != nil {
+					result.Error = associations.Error
+					return false
+				}
+				result.Resources = append(result.Resources, associations.Resources...)
+
+				propagations := TransitGatewayListRouteTablePropagations(session, client, *routeTable.TransitGatewayRouteTableId)
+				if propagations.Error != nil {
+					result.Error = propagations.Error
+					return false
+				}
+				result.Resources = append(result.Resources, propagations.Resources...)
+			}
+
+			return true
+		})
+
+	if result.Error != nil {
+		return result
+	}
+	result.Error = err
+	return result
+}
+
+func TransitGatewayListRouteTableAssociations(session *Session, client *ec2.EC2, routeTableID string) *ReportResult {
+	result := &ReportResult{}
+	result.Error = client.GetTransitGatewayRouteTableAssociationsPages(&ec2.GetTransitGatewayRouteTableAssociationsInput{
+		TransitGatewayRouteTableId: aws.String(routeTableID),
+	},
+		func(page *ec2.GetTransitGatewayRouteTableAssociationsOutput, lastPage bool) bool {
+			for _, association := range page.Associations {
+				resource := Resource{
+					ID:        fmt.Sprintf("%s_%s", routeTableID, *association.TransitGatewayAttachmentId),
+					AccountID: session.AccountID,
+					Service:   "ec2",
+					Type:      "transit-gateway-route-table-association",
+					Region:    *session.Config.Region,
+					Metadata:  structs.Map(association),
+				}
+				resource.Metadata["TransitGatewayRouteTableId"] = routeTableID
+				result.Resources = append(result.Resources, resource)
+			}
+			return true
+		})
+	return result
+}
+
+func TransitGatewayListRouteTablePropagations(session *Session, client *ec2.EC2, routeTableID string) *ReportResult {
+	result := &ReportResult{}
+	result.Error = client.GetTransitGatewayRouteTablePropagationsPages(&ec2.GetTransitGatewayRouteTablePropagationsInput{
+		TransitGatewayRouteTableId: aws.String(routeTableID),
+	},
+		func(page *ec2.GetTransitGatewayRouteTablePropagationsOutput, lastPage bool) bool {
+			for _, propagation := range page.TransitGatewayRouteTablePropagations {
+				resource := Resource{
+					ID:        fmt.Sprintf("%s_%s", routeTableID, *propagation.TransitGatewayAttachmentId),
+					AccountID: session.AccountID,
+					Service:   "ec2",
+					Type:      "transit-gateway-route-table-propagation",
+					Region:    *session.Config.Region,
+					Metadata:  structs.Map(propagation),
+				}
+				resource.Metadata["TransitGatewayRouteTableId"] = routeTableID
+				result.Resources = append(result.Resources, resource)
+			}
+			return true
+		})
+	return result
+}
@@ -0,0 +1,188 @@
+package resources
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+const (
+	// room left for the truncation marker
+	truncationMarkerSize = 4096
+)
+
+// TruncationRule lists metadata keys which can be dropped from resources of a
+// type before stripping metadata or dropping resources. Type "*" matches every
+// type of the service.
+type TruncationRule struct {
+	Service string
+	Type    string
+	Keys    []string
+}
+
+var (
+	// TruncationRules are applied in order until the output fits
+	TruncationRules = []TruncationRule{
+		{"iam", "*", []string{"ServiceLastAccessed"}},
+		{"iam", "policy-version", []string{"Document"}},
+		{"iam", "account-authorization-details-policy", []string{"PolicyVersionList"}},
+		{"ec2", "launch-template-version", []string{"LaunchTemplateData"}},
+		{"ec2", "instance", []string{"SSMInventory"}},
+		{"wafv2", "*", []string{"Rules", "Addresses", "RegularExpressionList"}},
+		{"cloudformation", "*", []string{"Parameters", "Outputs"}},
+		{"elasticbeanstalk", "*", []string{"OptionSettings"}},
+	}
+)
+
+// TruncationSummary describes what was removed to fit the output size.
+type TruncationSummary struct {
+	MaxOutputSize    int64          `json:"max_output_size"`
+	OriginalSize     int64          `json:"original_size"`
+	TruncatedKeys    map[string]int `json:"truncated_keys"`
+	StrippedMetadata map[string]int `json:"stripped_metadata"`
+	DroppedResources map[string]int `json:"dropped_resources"`
+}
+
+// Resource returns the marker added to the truncated output.
+func (s *TruncationSummary) Resource() Resource {
+	return Resource{
+		ID:      "output-truncation",
+		Service: "aws-dump",
+		Type:    "truncation",
+		Metadata: map[string]interface{}{
+			"MaxOutputSize":    s.MaxOutputSize,
+			"OriginalSize":     s.OriginalSize,
+			"TruncatedKeys":    s.TruncatedKeys,
+			"StrippedMetadata": s.StrippedMetadata,
+			"DroppedResources": s.DroppedResources,
+		},
+	}
+}
+
+func resourceSize(resource Resource) int64 {
+	data, err := json.MarshalIndent(resource, "  ", "  ")
+	if err != nil {
+		return 0
+	}
+	// indentation and separator in the output list
+	return int64(len(data) + 4)
+}
+
+func resourceKey(resource Resource) string {
+	return fmt.Sprintf("%s:%s", resource.Service, resource.Type)
+}
+
+func (r TruncationRule) matches(resource Resource) bool {
+	return r.Service == resource.Service && (r.Type == "*" || r.Type == resource.Type)
+}
+
+// Truncate removes data from resources until their serialised size is below
+// maxSize, first the keys of TruncationRules, then the metadata of the largest
+// resources and finally the resources at the end of the list. Modified
+// resources list the removed keys in a Truncated metadata key.
+// It returns nil when nothing was removed.
+func Truncate(resources []Resource, maxSize int64) ([]Resource, *TruncationSummary) {
+	sizes := make([]int64, len(resources))
+	total := int64(2)
+	for i, resource := range resources {
+		sizes[i] = resourceSize(resource)
+		total += sizes[i]
+	}
+
+	budget := maxSize - truncationMarkerSize
+	if total <= maxSize {
+		return resources, nil
+	}
+
+	summary := &TruncationSummary{
+		MaxOutputSize:    maxSize,
+		OriginalSize:     total,
+		TruncatedKeys:    map[string]int{},
+		StrippedMetadata: map[string]int{},
+		DroppedResources: map[string]int{},
+	}
+
+	// copy the metadata maps, resources can be shared with other outputs
+	truncated := make([]Resource, len(resources))
+	copy(truncated, resources)
+
+	resize := func(i int) {
+		size := resourceSize(truncated[i])
+		total += size - sizes[i]
+		sizes[i] = size
+	}
+
+	for _, rule := range TruncationRules {
+		if total <= budget {
+			break
+		}
+
+		for i, resource := range truncated {
+			if !rule.matches(resource) {
+				continue
+			}
+
+			removed := []string{}
+			metadata := map[string]interface{}{}
+			for key, value := range resource.Metadata {
+				if containsString(rule.Keys, key) {
+					removed = append(removed, key)
+					continue
+				}
+				metadata[key] = value
+			}
+			if len(removed) == 0 {
+				continue
+			}
+
+			for _, key := range removed {
+				summary.TruncatedKeys[fmt.Sprintf("%s:%s", resourceKey(resource), key)]++
+			}
+
+			if previous, ok := metadata["Truncated"].([]string); ok {
+				removed = append(previous, removed...)
+			}
+			sort.Strings(removed)
+			metadata["Truncated"] = removed
+			truncated[i].Metadata = metadata
+			resize(i)
+		}
+	}
+
+	if total > budget {
+		bySize := make([]int, len(truncated))
+		for i := range bySize {
+			bySize[i] = i
+		}
+		sort.SliceStable(bySize, func(a, b int) bool {
+			return sizes[bySize[a]] > sizes[bySize[b]]
+		})
+
+		for _, i := range bySize {
+			if total <= budget {
+				break
+			}
+			truncated[i].Metadata = map[string]interface{}{"Truncated": "metadata"}
+			resize(i)
+			summary.StrippedMetadata[resourceKey(truncated[i])]++
+		}
+	}
+
+	end := len(truncated)
+	for end > 0 && total > budget {
+		end--
+		total -= sizes[end]
+		summary.DroppedResources[resourceKey(truncated[end])]++
+	}
+
+	return append(truncated[:end], summary.Resource()), summary
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,67 @@
+package resources
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func truncationTestResources() []Resource {
+	resources := []Resource{}
+	for i := 0; i < 10; i++ {
+		resources = append(resources, Resource{
+			ID:      fmt.Sprintf("policy-%d", i),
+			Service: "iam",
+			Type:    "policy-version",
+			Metadata: map[string]interface{}{
+				"VersionId": "v1",
+				"Document":  strings.Repeat("a", 1000),
+			},
+		})
+	}
+	return resources
+}
+
+func TestTruncateFits(t *testing.T) {
+	t.Parallel()
+
+	resources := truncationTestResources()
+	truncated, summary := Truncate(resources, 1024*1024)
+	require.Nil(t, summary)
+	require.Equal(t, resources, truncated)
+}
+
+func TestTruncateRules(t *testing.T) {
+	t.Parallel()
+
+	resources := truncationTestResources()
+	truncated, summary := Truncate(resources, 8000)
+	require.NotNil(t, summary)
+	require.Len(t, truncated, 11)
+	require.Equal(t, 10, summary.TruncatedKeys["iam:policy-version:Document"])
+	require.Empty(t, summary.DroppedResources)
+
+	require.Equal(t, []string{"Document"}, truncated[0].Metadata["Truncated"])
+	require.Equal(t, "v1", truncated[0].Metadata["VersionId"])
+	require.Equal(t, "truncation", truncated[10].Type)
+
+	// the input is not modified
+	require.Contains(t, resources[0].Metadata, "Document")
+}
+
+func TestTruncateDropsResources(t *testing.T) {
+	t.Parallel()
+
+	truncated, summary := Truncate(truncationTestResources(), truncationMarkerSize+500)
+	require.NotNil(t, summary)
+	require.NotEmpty(t, summary.DroppedResources)
+	require.Equal(t, "truncation", truncated[len(truncated)-1].Type)
+
+	size := int64(2)
+	for _, resource := range truncated {
+		size += resourceSize(resource)
+	}
+	require.LessOrEqual(t, size, int64(truncationMarkerSize+500))
+}
@@ -4,6 +4,8 @@ import (
 	"encoding/json"
 	"net/url"
 
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/pkg/errors"
 )
 
@@ -20,3 +22,18 @@ func DecodeInlinePolicyDocument(inlineDocument string) (map[string]interface{},
 	}
 	return document, nil
 }
+
+// IsErrorCode returns true if err is an AWS error with the given code.
+func IsErrorCode(err error, code string) bool {
+	if awsErr, ok := err.(awserr.Error); ok {
+		return awsErr.Code() == code
+	}
+	return false
+}
+
+// redactEnvironment replaces the values of environment variables in place.
+func redactEnvironment(environment map[string]*string) {
+	for key := range environment {
+		environment[key] = aws.String("REDACTED")
+	}
+}
@@ -0,0 +1,372 @@
+package resources
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/waf"
+	"github.com/aws/aws-sdk-go/service/wafregional"
+	"github.com/aws/aws-sdk-go/service/wafv2"
+	"github.com/fatih/structs"
+)
+
+var (
+	WAFService = Service{
+		Name: "waf",
+		Reports: map[string]Report{
+			"web-acls":             WAFListWebACLs,
+			"rule-groups":          WAFListRuleGroups,
+			"ip-sets":              WAFListIPSets,
+			"regex-pattern-sets":   WAFListRegexPatternSets,
+			"web-acl-associations": WAFListWebACLAssociations,
+		},
+		OptionalReports: map[string]Report{
+			"classic-web-acls": WAFRegionalListClassicWebACLs,
+		},
+	}
+
+	// CloudFront resources live in us-east-1 only
+	WAFCloudFrontService = Service{
+		Name:     "waf-cloudfront",
+		IsGlobal: true,
+		Reports: map[string]Report{
+			"web-acls":           WAFCloudFrontListWebACLs,
+			"rule-groups":        WAFCloudFrontListRuleGroups,
+			"ip-sets":            WAFCloudFrontListIPSets,
+			"regex-pattern-sets": WAFCloudFrontListRegexPatternSets,
+		},
+		OptionalReports: map[string]Report{
+			"classic-web-acls": WAFListClassicWebACLs,
+		},
+	}
+
+	// resource types that can be associated with a regional web ACL
+	wafv2AssociatedResourceTypes = []string{
+		wafv2.ResourceTypeApplicationLoadBalancer,
+		wafv2.ResourceTypeApiGateway,
+	}
+)
+
+func newWAFv2Client(session *Session, scope string) *wafv2.WAFV2 {
+	if scope == wafv2.ScopeCloudfront {
+		return wafv2.New(session.Session, session.Config.Copy(&aws.Config{Region: aws.String("us-east-1")}))
+	}
+	return wafv2.New(session.Session, session.Config)
+}
+
+func newWAFResource(session *Session, arn, id, resourceType string, scope string, metadata interface{}) Resource {
+	resource := Resource{
+		ID:        id,
+		ARN:       arn,
+		AccountID: session.AccountID,
+		Service:   "wafv2",
+		Type:      resourceType,
+		Region:    *session.Config.Region,
+		Metadata:  structs.Map(metadata),
+	}
+	if scope == wafv2.ScopeCloudfront {
+		resource.Region = ""
+	}
+	resource.Metadata["Scope"] = scope
+	return resource
+}
+
+func WAFListWebACLs(session *Session) *ReportResult {
+	return WAFv2ListWebACLs(session, wafv2.ScopeRegional)
+}
+
+func WAFCloudFrontListWebACLs(session *Session) *ReportResult {
+	return WAFv2ListWebACLs(session, wafv2.ScopeCloudfront)
+}
+
+func WAFv2ListWebACLs(session *Session, scope string) *ReportResult {
+	client := newWAFv2Client(session, scope)
+
+	result := &ReportResult{}
+	input := &wafv2.ListWebACLsInput{Scope: aws.String(scope)}
+	for {
+		page, err := client.ListWebACLs(input)
+		if err != nil {
+			result.Error = err
+			return result
+		}
+
+		for _, summary := range page.WebACLs {
+			webACL, err := client.GetWebACL(&wafv2.GetWebACLInput{
+				Id:    summary.Id,
+				Name:  summary.Name,
+				Scope: aws.String(scope),
+			})
+			if err != nil {
+				result.Error = err
+				return result
+			}
+
+			result.Resources = append(result.Resources, newWAFResource(session, *summary.ARN, *summary.Id, "web-acl", scope, webACL.WebACL))
+		}
+
+		if page.NextMarker == nil {
+			break
+		}
+		input.NextMarker = page.NextMarker
+	}
+
+	return result
+}
+
+func WAFListRuleGroups(session *Session) *ReportResult {
+	return WAFv2ListRuleGroups(session, wafv2.ScopeRegional)
+}
+
+func WAFCloudFrontListRuleGroups(session *Session) *ReportResult {
+	return WAFv2ListRuleGroups(session, wafv2.ScopeCloudfront)
+}
+
+func WAFv2ListRuleGroups(session *Session, scope string) *ReportResult {
+	client := newWAFv2Client(session, scope)
+
+	result := &ReportResult{}
+	input := &wafv2.ListRuleGroupsInput{Scope: aws.String(scope)}
+	for {
+		page, err := client.ListRuleGroups(input)
+		if err != nil {
+			result.Error = err
+			return result
+		}
+
+		for _, summary := range page.RuleGroups {
+			ruleGroup, err := client.GetRuleGroup(&wafv2.GetRuleGroupInput{
+				Id:    summary.Id,
+				Name:  summary.Name,
+				Scope: aws.String(scope),
+			})
+			if err != nil {
+				result.Error = err
+				return result
+			}
+
+			result.Resources = append(result.Resources, newWAFResource(session, *summary.ARN, *summary.Id, "rule-group", scope, ruleGroup.RuleGroup))
+		}
+
+		if page.NextMarker == nil {
+			break
+		}
+		input.NextMarker = page.NextMarker
+	}
+
+	return result
+}
+
+func WAFListIPSets(session *Session) *ReportResult {
+	return WAFv2ListIPSets(session, wafv2.ScopeRegional)
+}
+
+func WAFCloudFrontListIPSets(session *Session) *ReportResult {
+	return WAFv2ListIPSets(session, wafv2.ScopeCloudfront)
+}
+
+func WAFv2ListIPSets(session *Session, scope string) *ReportResult {
+	client := newWAFv2Client(session, scope)
+
+	result := &ReportResult{}
+	input := &wafv2.ListIPSetsInput{Scope: aws.String(scope)}
+	for {
+		page, err := client.ListIPSets(input)
+		if err != nil {
+			result.Error = err
+			return result
+		}
+
+		for _, summary := range page.IPSets {
+			ipSet, err := client.GetIPSet(&wafv2.GetIPSetInput{
+				Id:    summary.Id,
+				Name:  summary.Name,
+				Scope: aws.String(scope),
+			})
+			if err != nil {
+				result.Error = err
+				return result
+			}
+
+			result.Resources = append(result.Resources, newWAFResource(session, *summary.ARN, *summary.Id, "ip-set", scope, ipSet.IPSet))
+		}
+
+		if page.NextMarker == nil {
+			break
+		}
+		input.NextMarker = page.NextMarker
+	}
+
+	return result
+}
+
+func WAFListRegexPatternSets(session *Session) *ReportResult {
+	return WAFv2ListRegexPatternSets(session, wafv2.ScopeRegional)
+}
+
+func WAFCloudFrontListRegexPatternSets(session *Session) *ReportResult {
+	return WAFv2ListRegexPatternSets(session, wafv2.ScopeCloudfront)
+}
+
+func WAFv2ListRegexPatternSets(session *Session, scope string) *ReportResult {
+	client := newWAFv2Client(session, scope)
+
+	result := &ReportResult{}
+	input := &wafv2.ListRegexPatternSetsInput{Scope: aws.String(scope)}
+	for {
+		page, err := client.ListRegexPatternSets(input)
+		if err != nil {
+			result.Error = err
+			return result
+		}
+
+		for _, summary := range page.RegexPatternSets {
+			regexPatternSet, err := client.GetRegexPatternSet(&wafv2.GetRegexPatternSetInput{
+				Id:    summary.Id,
+				Name:  summary.Name,
+				Scope: aws.String(scope),
+			})
+			if err != nil {
+				result.Error = err
+				return result
+			}
+
+			result.Resources = append(result.Resources, newWAFResource(session, *summary.ARN, *summary.Id, "regex-pattern-set", scope, regexPatternSet.RegexPatternSet))
+		}
+
+		if page.NextMarker == nil {
+			break
+		}
+		input.NextMarker = page.NextMarker
+	}
+
+	return result
+}
+
+// WAFListWebACLAssociations lists the resources protected by regional web ACLs.
+// CloudFront distributions reference their web ACL directly.
+func WAFListWebACLAssociations(session *Session) *ReportResult {
+	client := newWAFv2Client(session, wafv2.ScopeRegional)
+
+	webACLs := WAFv2ListWebACLs(session, wafv2.ScopeRegional)
+	if webACLs.Error != nil {
+		return webACLs
+	}
+
+	result := &ReportResult{}
+	for _, webACL := range webACLs.Resources {
+		for _, resourceType := range wafv2AssociatedResourceTypes {
+			res, err := client.ListResourcesForWebACL(&wafv2.ListResourcesForWebACLInput{
+				WebACLArn:    aws.String(webACL.ARN),
+				ResourceType: aws.String(resourceType),
+			})
+			if err != nil {
+				result.Error = err
+				return result
+			}
+
+			for _, resourceARN := range res.ResourceArns {
+				result.Resources = append(result.Resources, Resource{
+					ID:        fmt.Sprintf("%s_%s", webACL.ID, *resourceARN),
+					AccountID: session.AccountID,
+					Service:   "wafv2",
+					Type:      "web-acl-association",
+					Region:    *session.Config.Region,
+					Metadata: map[string]interface{}{
+						"WebACLArn":    webACL.ARN,
+						"ResourceArn":  *resourceARN,
+						"ResourceType": resourceType,
+					},
+				})
+			}
+		}
+	}
+
+	return result
+}
+
+func WAFListClassicWebACLs(session *Session) *ReportResult {
+	client := waf.New(session.Session, session.Config.Copy(&aws.Config{Region: aws.String("us-east-1")}))
+
+	result := &ReportResult{}
+	input := &waf.ListWebACLsInput{}
+	for {
+		page, err := client.ListWebACLs(input)
+		if err != nil {
+			result.Error = err
+			return result
+		}
+
+		for _, summary := range page.WebACLs {
+			webACL, err := client.GetWebACL(&waf.GetWebACLInput{WebACLId: summary.WebACLId})
+			if err != nil {
+				result.Error = err
+				return result
+			}
+
+			result.Resources = append(result.Resources, Resource{
+				ID:        *summary.WebACLId,
+				ARN:       aws.StringValue(webACL.WebACL.WebACLArn),
+				AccountID: session.AccountID,
+				Service:   "waf",
+				Type:      "web-acl",
+				Metadata:  structs.Map(webACL.WebACL),
+			})
+		}
+
+		if page.NextMarker == nil {
+			break
+		}
+		input.NextMarker = page.NextMarker
+	}
+
+	return result
+}
+
+func WAFRegionalListClassicWebACLs(session *Session) *ReportResult {
+	client := wafregional.New(session.Session, session.Config)
+
+	result := &ReportResult{}
+	input := &waf.ListWebACLsInput{}
+	for {
+		page, err := client.ListWebACLs(input)
+		if err != nil {
+			result.Error = err
+			return result
+		}
+
+		for _, summary := range page.WebACLs {
+			webACL, err := client.GetWebACL(&waf.GetWebACLInput{WebACLId: summary.WebACLId})
+			if err != nil {
+				result.Error = err
+				return result
+			}
+
+			resource := Resource{
+				ID:        *summary.WebACLId,
+				ARN:       aws.StringValue(webACL.WebACL.WebACLArn),
+				AccountID: session.AccountID,
+				Service:   "waf-regional",
+				Type:      "web-acl",
+				Region:    *session.Config.Region,
+				Metadata:  structs.Map(webACL.WebACL),
+			}
+
+			associated, err := client.ListResourcesForWebACL(&wafregional.ListResourcesForWebACLInput{WebACLId: summary.WebACLId})
+			if err != nil {
+				result.Error = err
+				return result
+			}
+			resource.Metadata["ResourceArns"] = aws.StringValueSlice(associated.ResourceArns)
+
+			result.Resources = append(result.Resources, resource)
+		}
+
+		if page.NextMarker == nil {
+			break
+		}
+		input.NextMarker = page.NextMarker
+	}
+
+	return result
+}
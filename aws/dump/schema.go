@@ -0,0 +1,116 @@
+package main
+
+import (
+	"time"
+
+	"github.com/hamstah/awstools/aws/dump/resources"
+	"github.com/hamstah/awstools/common"
+)
+
+const (
+	OutputSchemaNone = "none"
+	OutputSchemaV1   = "v1"
+
+	// OutputSchemaVersion is the version of the output with --output-schema
+	// v1, the fields are only added to a version, changing or removing one
+	// needs a new version
+	OutputSchemaVersion = 1
+)
+
+// RunMetadata describes the run of the dump that collected the resources.
+type RunMetadata struct {
+	Tool       string                `json:"tool"`
+	Version    string                `json:"version"`
+	CommitHash string                `json:"commit_hash"`
+	StartedAt  time.Time             `json:"started_at"`
+	FinishedAt time.Time             `json:"finished_at"`
+	AccountIDs []string              `json:"account_ids"`
+	Regions    []string              `json:"regions"`
+	Errors     []*resources.JobError `json:"errors"`
+}
+
+// VersionedOutput is the output of the dump with --output-schema v1, the
+// resources wrapped with the metadata of the run.
+type VersionedOutput struct {
+	SchemaVersion int                  `json:"schema_version"`
+	Run           RunMetadata          `json:"run"`
+	Resources     []resources.Resource `json:"resources"`
+}
+
+func NewVersionedOutput(output *Output) *VersionedOutput {
+	errors := output.Errors
+	if errors == nil {
+		errors = []*resources.JobError{}
+	}
+	return &VersionedOutput{
+		SchemaVersion: OutputSchemaVersion,
+		Run: RunMetadata{
+			Tool:       "aws-dump",
+			Version:    common.Version,
+			CommitHash: common.CommitHash,
+			StartedAt:  output.StartedAt,
+			FinishedAt: output.FinishedAt,
+			AccountIDs: output.AccountIDs,
+			Regions:    output.Regions,
+			Errors:     errors,
+		},
+		Resources: output.Resources,
+	}
+}
+
+// OutputSchema is the JSON schema of the output with --output-schema v1,
+// printed by aws-dump schema.
+const OutputSchema = `{
+  "$schema": "http://json-schema.org/draft-07/schema#",
+  "$id": "https://github.com/hamstah/awstools/aws/dump/schema/v1.json",
+  "title": "aws-dump output v1",
+  "type": "object",
+  "required": ["schema_version", "run", "resources"],
+  "properties": {
+    "schema_version": {"const": 1},
+    "run": {
+      "type": "object",
+      "required": ["tool", "version", "commit_hash", "started_at", "finished_at", "account_ids", "regions", "errors"],
+      "properties": {
+        "tool": {"type": "string"},
+        "version": {"type": "string"},
+        "commit_hash": {"type": "string"},
+        "started_at": {"type": "string", "format": "date-time"},
+        "finished_at": {"type": "string", "format": "date-time"},
+        "account_ids": {"type": "array", "items": {"type": "string"}},
+        "regions": {"type": "array", "items": {"type": "string"}},
+        "errors": {"type": "array", "items": {"$ref": "#/definitions/error"}}
+      }
+    },
+    "resources": {"type": "array", "items": {"$ref": "#/definitions/resource"}}
+  },
+  "definitions": {
+    "resource": {
+      "type": "object",
+      "required": ["id", "arn", "service", "type", "account_id", "region", "metadata", "managed_by"],
+      "properties": {
+        "id": {"type": "string"},
+        "arn": {"type": "string"},
+        "service": {"type": "string"},
+        "type": {"type": "string"},
+        "account_id": {"type": "string"},
+        "region": {"type": "string"},
+        "metadata": {"type": ["object", "null"]},
+        "managed_by": {"type": ["object", "null"], "additionalProperties": {"type": "string"}},
+        "unchanged": {"type": "boolean"}
+      }
+    },
+    "error": {
+      "type": "object",
+      "required": ["service", "report", "account_id", "region", "error"],
+      "properties": {
+        "service": {"type": "string"},
+        "report": {"type": "string"},
+        "account_id": {"type": "string"},
+        "region": {"type": "string"},
+        "error": {"type": "string"}
+      }
+    }
+  }
+}
+`
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"encoding/json"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/hamstah/awstools/aws/dump/resources"
+	"github.com/stretchr/testify/require"
+)
+
+// jsonKeys returns the keys of the JSON object of value, sorted.
+func jsonKeys(t *testing.T, value interface{}) []string {
+	data, err := json.Marshal(value)
+	require.NoError(t, err)
+	object := map[string]interface{}{}
+	require.NoError(t, json.Unmarshal(data, &object))
+
+	keys := []string{}
+	for key := range object {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func schemaRequired(t *testing.T, definition map[string]interface{}) []string {
+	required := []string{}
+	for _, key := range definition["required"].([]interface{}) {
+		required = append(required, key.(string))
+	}
+	sort.Strings(required)
+	return required
+}
+
+func TestOutputSchema(t *testing.T) {
+	schema := map[string]interface{}{}
+	require.NoError(t, json.Unmarshal([]byte(OutputSchema), &schema))
+
+	output := NewVersionedOutput(&Output{
+		Resources:  []resources.Resource{{ID: "a", Service: "s3", Type: "bucket"}},
+		AccountIDs: []string{"123456789012"},
+		Regions:    []string{"eu-west-1"},
+		StartedAt:  time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC),
+		FinishedAt: time.Date(2026, 10, 1, 12, 5, 0, 0, time.UTC),
+	})
+	require.Equal(t, OutputSchemaVersion, output.SchemaVersion)
+	require.Equal(t, []*resources.JobError{}, output.Run.Errors)
+
+	// the fields of the output are the required ones of the schema
+	properties := schema["properties"].(map[string]interface{})
+	definitions := schema["definitions"].(map[string]interface{})
+	require.Equal(t, schemaRequired(t, schema), jsonKeys(t, output))
+	require.Equal(t, schemaRequired(t, properties["run"].(map[string]interface{})), jsonKeys(t, output.Run))
+	require.Equal(t, schemaRequired(t, definitions["resource"].(map[string]interface{})), jsonKeys(t, output.Resources[0]))
+	require.Equal(t, schemaRequired(t, definitions["error"].(map[string]interface{})), jsonKeys(t, resources.JobError{}))
+}
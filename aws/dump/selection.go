@@ -0,0 +1,134 @@
+package main
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/hamstah/awstools/aws/dump/resources"
+)
+
+// ReportSelection selects the reports to run. Reports are service:report
+// names, Profile, Only and Exclude are patterns with * wildcards like iam:*.
+type ReportSelection struct {
+	Reports         []string
+	Profile         []string
+	Only            []string
+	Exclude         []string
+	IncludeOptional bool
+}
+
+func matchesAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, name); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// Select returns the service:report names to run, sorted. The reports need to
+// match the Profile and Only patterns when set. The optional reports are only
+// selected when they match Profile or Only, or when optional reports are
+// included. Patterns that don't match any report are an error, to catch typos.
+func (s *ReportSelection) Select(services map[string]resources.Service) ([]string, error) {
+	all := map[string]bool{}
+	optional := map[string]bool{}
+	for serviceName, service := range services {
+		for report := range service.Reports {
+			all[fmt.Sprintf("%s:%s", serviceName, report)] = true
+		}
+		for report := range service.OptionalReports {
+			name := fmt.Sprintf("%s:%s", serviceName, report)
+			all[name] = true
+			optional[name] = true
+		}
+	}
+
+	for _, patterns := range [][]string{s.Profile, s.Only, s.Exclude} {
+		for _, pattern := range patterns {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return nil, fmt.Errorf("invalid report pattern %s: %s", pattern, err)
+			}
+			matched := false
+			for name := range all {
+				if ok, _ := path.Match(pattern, name); ok {
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				return nil, fmt.Errorf("no report matches %s", pattern)
+			}
+		}
+	}
+
+	candidates := []string{}
+	if len(s.Reports) > 0 {
+		for _, name := range s.Reports {
+			parts := strings.Split(name, ":")
+			if len(parts) != 2 {
+				return nil, fmt.Errorf("invalid report format %s, should be service:resource", name)
+			}
+			if _, ok := services[parts[0]]; !ok {
+				return nil, fmt.Errorf("invalid service %s", parts[0])
+			}
+			if !all[name] {
+				return nil, fmt.Errorf("unknown resource %s for service %s", parts[1], parts[0])
+			}
+			candidates = append(candidates, name)
+		}
+	} else {
+		for name := range all {
+			if !optional[name] || s.IncludeOptional || matchesAny(s.Profile, name) || matchesAny(s.Only, name) {
+				candidates = append(candidates, name)
+			}
+		}
+	}
+
+	selected := []string{}
+	seen := map[string]bool{}
+	for _, name := range candidates {
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		if len(s.Profile) > 0 && !matchesAny(s.Profile, name) {
+			continue
+		}
+		if len(s.Only) > 0 && !matchesAny(s.Only, name) {
+			continue
+		}
+		if matchesAny(s.Exclude, name) {
+			continue
+		}
+		selected = append(selected, name)
+	}
+	sort.Strings(selected)
+	return selected, nil
+}
+
+// SelectReports returns the service:report names to run for the event and
+// enables the enrichments of its profile.
+func SelectReports(event *Input, services map[string]resources.Service) ([]string, error) {
+	selection := &ReportSelection{
+		Reports:         event.Reports,
+		Only:            event.Only,
+		Exclude:         event.Exclude,
+		IncludeOptional: event.IncludeOptionalReports,
+	}
+	if event.Profile != "" {
+		profile, err := GetProfile(event.Profile)
+		if err != nil {
+			return nil, err
+		}
+		selection.Profile = profile.Reports
+		selection.IncludeOptional = selection.IncludeOptional || profile.IncludeOptional
+		event.SSMInventory = event.SSMInventory || profile.SSMInventory
+		event.Rightsizing = event.Rightsizing || profile.Rightsizing
+		event.MonthlyCost = event.MonthlyCost || profile.MonthlyCost
+	}
+	return selection.Select(services)
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/hamstah/awstools/aws/dump/resources"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReportSelection(t *testing.T) {
+	services := map[string]resources.Service{
+		"iam": {
+			Name: "iam",
+			Reports: map[string]resources.Report{
+				"roles":                         nil,
+				"policies":                      nil,
+				"account-authorization-details": nil,
+			},
+		},
+		"s3": {
+			Name:    "s3",
+			Reports: map[string]resources.Report{"buckets": nil},
+		},
+		"waf": {
+			Name:            "waf",
+			OptionalReports: map[string]resources.Report{"web-acls": nil},
+		},
+	}
+
+	testCases := []struct {
+		selection *ReportSelection
+		expected  []string
+	}{
+		{&ReportSelection{}, []string{"iam:account-authorization-details", "iam:policies", "iam:roles", "s3:buckets"}},
+		{&ReportSelection{IncludeOptional: true}, []string{"iam:account-authorization-details", "iam:policies", "iam:roles", "s3:buckets", "waf:web-acls"}},
+		{&ReportSelection{Only: []string{"iam:*"}, Exclude: []string{"iam:account-authorization-details"}}, []string{"iam:policies", "iam:roles"}},
+		{&ReportSelection{Exclude: []string{"iam:*"}}, []string{"s3:buckets"}},
+		{&ReportSelection{Only: []string{"waf:*"}}, []string{"waf:web-acls"}},
+		{&ReportSelection{Only: []string{"waf:web-acls", "s3:*"}}, []string{"s3:buckets", "waf:web-acls"}},
+		{&ReportSelection{Reports: []string{"iam:roles", "waf:web-acls"}, Exclude: []string{"waf:*"}}, []string{"iam:roles"}},
+		{&ReportSelection{Profile: []string{"iam:*", "waf:*"}, Exclude: []string{"iam:policies"}}, []string{"iam:account-authorization-details", "iam:roles", "waf:web-acls"}},
+		{&ReportSelection{Profile: []string{"iam:*", "s3:buckets"}, Only: []string{"s3:*"}}, []string{"s3:buckets"}},
+	}
+	for _, testCase := range testCases {
+		selected, err := testCase.selection.Select(services)
+		require.NoError(t, err)
+		require.Equal(t, testCase.expected, selected)
+	}
+
+	for selection, expected := range map[*ReportSelection]string{
+		{Only: []string{"iam:rolez"}}:   "no report matches iam:rolez",
+		{Exclude: []string{"ec2:*"}}:    "no report matches ec2:*",
+		{Only: []string{"iam:["}}:       "invalid report pattern iam:[: syntax error in pattern",
+		{Reports: []string{"iam"}}:      "invalid report format iam, should be service:resource",
+		{Reports: []string{"ec2:vpcs"}}: "invalid service ec2",
+		{Reports: []string{"iam:vpcs"}}: "unknown resource vpcs for service iam",
+	} {
+		_, err := selection.Select(services)
+		require.EqualError(t, err, expected)
+	}
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	v4 "github.com/aws/aws-sdk-go/aws/signer/v4"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/hamstah/awstools/aws/dump/resources"
+	log "github.com/sirupsen/logrus"
+)
+
+// Sink receives the resources as they are collected instead of them being
+// written to the output.
+type Sink interface {
+	Put(resource resources.Resource) error
+	// Flush writes the resources not written yet
+	Flush() error
+	// Written is the number of resources written so far
+	Written() int
+}
+
+// ResourceKey is the ARN of the resource, or its account, region, service,
+// type and id when it doesn't have one.
+func ResourceKey(resource resources.Resource) string {
+	return resource.Key()
+}
+
+// NewSink returns the sink configured in the event, or nil if there is none.
+func NewSink(event Input, sess *session.Session, conf *aws.Config) (Sink, error) {
+	now := time.Now()
+
+	if event.DynamoDBTable != "" {
+		ttl := time.Duration(event.DynamoDBTTLDays) * 24 * time.Hour
+		return NewDynamoDBSink(dynamodb.New(sess, conf), event.DynamoDBTable, now, ttl), nil
+	}
+
+	if event.OpenSearchURL != "" {
+		index := event.OpenSearchIndex
+		if index == "" {
+			index = "aws-dump"
+		}
+		indexPer := event.OpenSearchIndexPer
+		if indexPer == "" {
+			indexPer = OpenSearchIndexPerDay
+		}
+
+		sink, err := NewOpenSearchSink(event.OpenSearchURL, index, indexPer, now)
+		if err != nil {
+			return nil, err
+		}
+		if event.OpenSearchAWSAuth {
+			region := aws.StringValue(conf.Region)
+			if region == "" {
+				region = aws.StringValue(sess.Config.Region)
+			}
+			sink.WithSigner(v4.NewSigner(sess.Config.Credentials), region)
+		}
+
+		err = sink.PutTemplate()
+		if err != nil {
+			return nil, fmt.Errorf("failed to create the index template: %s", err)
+		}
+		return sink, nil
+	}
+
+	if event.Neo4jURL != "" {
+		database := event.Neo4jDatabase
+		if database == "" {
+			database = "neo4j"
+		}
+
+		sink, err := NewNeo4jSink(event.Neo4jURL, database, now)
+		if err != nil {
+			return nil, err
+		}
+
+		err = sink.CreateConstraint()
+		if err != nil {
+			return nil, fmt.Errorf("failed to create the constraint: %s", err)
+		}
+		return sink, nil
+	}
+
+	return nil, nil
+}
+
+// DumpToSink writes the resources to the sink as they are collected instead
+// of returning them.
+func DumpToSink(event Input, sink Sink) (*Output, error) {
+	output, err := Dump(event, sink.Put)
+	if err != nil {
+		return nil, err
+	}
+
+	err = sink.Flush()
+	if err != nil {
+		return nil, err
+	}
+
+	log.WithField("resources", sink.Written()).Info("Resources written to the sink")
+	return output, nil
+}
@@ -0,0 +1,19 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/hamstah/awstools/aws/dump/resources"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResourceKey(t *testing.T) {
+	require.Equal(t, "arn:aws:s3:::bucket", ResourceKey(resources.Resource{ARN: "arn:aws:s3:::bucket", ID: "bucket"}))
+	require.Equal(t, "123456789012/eu-west-1/ec2/key-pair/deploy", ResourceKey(resources.Resource{
+		ID:        "deploy",
+		AccountID: "123456789012",
+		Region:    "eu-west-1",
+		Service:   "ec2",
+		Type:      "key-pair",
+	}))
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/hamstah/awstools/aws/dump/resources"
+)
+
+const (
+	SummaryNone   = "none"
+	SummaryAppend = "append"
+	SummaryOnly   = "only"
+
+	// terraform status of the resources counted, empty when they are not
+	// compared with terraform
+	SummaryManaged   = "managed"
+	SummaryUnmanaged = "unmanaged"
+)
+
+type summaryKey struct {
+	AccountID string
+	Region    string
+	Service   string
+	Type      string
+}
+
+type summaryCount struct {
+	Resources int
+	Managed   int
+	Unmanaged int
+}
+
+// Summary counts the resources of the dump per account, region, service and
+// type, and the managed and unmanaged ones when compared with terraform.
+type Summary struct {
+	compared bool
+	counts   map[summaryKey]*summaryCount
+}
+
+func NewSummary(compared bool) *Summary {
+	return &Summary{compared: compared, counts: map[summaryKey]*summaryCount{}}
+}
+
+// Add counts the resource, status is SummaryManaged, SummaryUnmanaged or
+// empty for the resources not compared.
+func (s *Summary) Add(resource resources.Resource, status string) {
+	key := summaryKey{resource.AccountID, resource.Region, resource.Service, resource.Type}
+	count, ok := s.counts[key]
+	if !ok {
+		count = &summaryCount{}
+		s.counts[key] = count
+	}
+
+	count.Resources++
+	switch status {
+	case SummaryManaged:
+		count.Managed++
+	case SummaryUnmanaged:
+		count.Unmanaged++
+	}
+}
+
+// Resources returns a summary marker per account, region, service and type,
+// sorted.
+func (s *Summary) Resources() []resources.Resource {
+	keys := []summaryKey{}
+	for key := range s.counts {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := keys[i], keys[j]
+		if a.AccountID != b.AccountID {
+			return a.AccountID < b.AccountID
+		}
+		if a.Region != b.Region {
+			return a.Region < b.Region
+		}
+		if a.Service != b.Service {
+			return a.Service < b.Service
+		}
+		return a.Type < b.Type
+	})
+
+	result := []resources.Resource{}
+	for _, key := range keys {
+		count := s.counts[key]
+		metadata := map[string]interface{}{
+			"Service":   key.Service,
+			"Type":      key.Type,
+			"Resources": count.Resources,
+		}
+		if s.compared {
+			metadata["Managed"] = count.Managed
+			metadata["Unmanaged"] = count.Unmanaged
+		}
+
+		result = append(result, resources.Resource{
+			ID:        fmt.Sprintf("%s:%s", key.Service, key.Type),
+			Service:   "aws-dump",
+			Type:      "summary",
+			AccountID: key.AccountID,
+			Region:    key.Region,
+			Metadata:  metadata,
+		})
+	}
+	return result
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/hamstah/awstools/aws/dump/resources"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSummary(t *testing.T) {
+	instance := resources.Resource{Service: "ec2", Type: "instance", AccountID: "123456789012", Region: "eu-west-1"}
+	bucket := resources.Resource{Service: "s3", Type: "bucket", AccountID: "123456789012", Region: "us-east-1"}
+
+	summary := NewSummary(true)
+	summary.Add(bucket, "")
+	summary.Add(instance, SummaryManaged)
+	summary.Add(instance, SummaryUnmanaged)
+	summary.Add(instance, SummaryManaged)
+
+	result := summary.Resources()
+	require.Len(t, result, 2)
+	require.Equal(t, "ec2:instance", result[0].ID)
+	require.Equal(t, "aws-dump", result[0].Service)
+	require.Equal(t, "summary", result[0].Type)
+	require.Equal(t, "eu-west-1", result[0].Region)
+	require.Equal(t, map[string]interface{}{
+		"Service":   "ec2",
+		"Type":      "instance",
+		"Resources": 3,
+		"Managed":   2,
+		"Unmanaged": 1,
+	}, result[0].Metadata)
+
+	// the resources not compared are neither managed nor unmanaged
+	require.Equal(t, 1, result[1].Metadata["Resources"])
+	require.Equal(t, 0, result[1].Metadata["Managed"])
+	require.Equal(t, 0, result[1].Metadata["Unmanaged"])
+
+	summary = NewSummary(false)
+	summary.Add(bucket, "")
+	require.NotContains(t, summary.Resources()[0].Metadata, "Managed")
+}
@@ -0,0 +1,187 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hamstah/awstools/aws/dump/resources"
+)
+
+// TagPolicy is the tags the resources need to have, by key with their allowed
+// values, any value is allowed when a key has none.
+type TagPolicy struct {
+	Required map[string][]string `json:"required"`
+	// OwnerTag is the tag used to group the violations by owner
+	OwnerTag string `json:"owner_tag"`
+	// OnlyViolations outputs the violations instead of the resources
+	OnlyViolations bool `json:"only_violations"`
+}
+
+// NewTagPolicy returns the policy of the --required-tag values, key or
+// key=value1,value2 for the allowed values.
+func NewTagPolicy(requiredTags []string, ownerTag string, onlyViolations bool) (*TagPolicy, error) {
+	policy := &TagPolicy{Required: map[string][]string{}, OwnerTag: ownerTag, OnlyViolations: onlyViolations}
+	for _, requiredTag := range requiredTags {
+		parts := strings.SplitN(requiredTag, "=", 2)
+		key := strings.TrimSpace(parts[0])
+		if key == "" {
+			return nil, fmt.Errorf("invalid required tag %s, should be key or key=value1,value2", requiredTag)
+		}
+
+		values := policy.Required[key]
+		if len(parts) == 2 {
+			for _, value := range strings.Split(parts[1], ",") {
+				if value = strings.TrimSpace(value); value != "" {
+					values = append(values, value)
+				}
+			}
+		}
+		policy.Required[key] = values
+	}
+	return policy, nil
+}
+
+// Check returns the required tags missing, sorted, and the tags with a value
+// that is not allowed.
+func (p *TagPolicy) Check(tags map[string]string) ([]string, map[string]string) {
+	missing := []string{}
+	invalid := map[string]string{}
+	for key, allowed := range p.Required {
+		value, ok := tags[key]
+		if !ok || value == "" {
+			missing = append(missing, key)
+			continue
+		}
+		if len(allowed) == 0 {
+			continue
+		}
+
+		found := false
+		for _, allowedValue := range allowed {
+			if value == allowedValue {
+				found = true
+				break
+			}
+		}
+		if !found {
+			invalid[key] = value
+		}
+	}
+	sort.Strings(missing)
+	return missing, invalid
+}
+
+type tagComplianceKey struct {
+	Service string
+	Owner   string
+}
+
+type tagComplianceCount struct {
+	Resources  int
+	Violations int
+}
+
+// TagCompliance evaluates the tag policy on the resources of the dump with
+// tags, and counts the violations per service and owner.
+type TagCompliance struct {
+	policy     *TagPolicy
+	violations []resources.Resource
+	counts     map[tagComplianceKey]*tagComplianceCount
+}
+
+func NewTagCompliance(policy *TagPolicy) *TagCompliance {
+	return &TagCompliance{
+		policy:     policy,
+		violations: []resources.Resource{},
+		counts:     map[tagComplianceKey]*tagComplianceCount{},
+	}
+}
+
+// Add checks the resource, the aws-dump resources and the resources without
+// tags in their metadata are skipped.
+func (c *TagCompliance) Add(resource resources.Resource) {
+	if resource.Service == "aws-dump" || !resource.Taggable() {
+		return
+	}
+
+	tags := resource.Tags()
+	owner := ""
+	if c.policy.OwnerTag != "" {
+		owner = tags[c.policy.OwnerTag]
+	}
+
+	key := tagComplianceKey{resource.Service, owner}
+	count, ok := c.counts[key]
+	if !ok {
+		count = &tagComplianceCount{}
+		c.counts[key] = count
+	}
+	count.Resources++
+
+	missing, invalid := c.policy.Check(tags)
+	if len(missing) == 0 && len(invalid) == 0 {
+		return
+	}
+	count.Violations++
+
+	c.violations = append(c.violations, resources.Resource{
+		ID:        resource.ID,
+		ARN:       resource.ARN,
+		Service:   "aws-dump",
+		Type:      "tag-violation",
+		AccountID: resource.AccountID,
+		Region:    resource.Region,
+		Metadata: map[string]interface{}{
+			"Service": resource.Service,
+			"Type":    resource.Type,
+			"Owner":   owner,
+			"Missing": missing,
+			"Invalid": invalid,
+		},
+	})
+}
+
+// Resources returns a tag-violation marker per resource violating the policy
+// sorted by service, owner and id, followed by a tag-compliance marker per
+// service and owner with the number of resources and violations.
+func (c *TagCompliance) Resources() []resources.Resource {
+	result := append([]resources.Resource{}, c.violations...)
+	sort.SliceStable(result, func(i, j int) bool {
+		a, b := result[i], result[j]
+		if a.Metadata["Service"] != b.Metadata["Service"] {
+			return a.Metadata["Service"].(string) < b.Metadata["Service"].(string)
+		}
+		if a.Metadata["Owner"] != b.Metadata["Owner"] {
+			return a.Metadata["Owner"].(string) < b.Metadata["Owner"].(string)
+		}
+		return a.ID < b.ID
+	})
+
+	keys := []tagComplianceKey{}
+	for key := range c.counts {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].Service != keys[j].Service {
+			return keys[i].Service < keys[j].Service
+		}
+		return keys[i].Owner < keys[j].Owner
+	})
+
+	for _, key := range keys {
+		count := c.counts[key]
+		result = append(result, resources.Resource{
+			ID:      fmt.Sprintf("%s:%s", key.Service, key.Owner),
+			Service: "aws-dump",
+			Type:    "tag-compliance",
+			Metadata: map[string]interface{}{
+				"Service":    key.Service,
+				"Owner":      key.Owner,
+				"Resources":  count.Resources,
+				"Violations": count.Violations,
+			},
+		})
+	}
+	return result
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/hamstah/awstools/aws/dump/resources"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewTagPolicy(t *testing.T) {
+	policy, err := NewTagPolicy([]string{"team", "env=prod, staging", "env=dev"}, "team", false)
+	require.NoError(t, err)
+	require.Equal(t, map[string][]string{"team": nil, "env": {"prod", "staging", "dev"}}, policy.Required)
+
+	_, err = NewTagPolicy([]string{"=prod"}, "", false)
+	require.Error(t, err)
+}
+
+func TestTagPolicyCheck(t *testing.T) {
+	policy := &TagPolicy{Required: map[string][]string{"team": nil, "env": {"prod", "dev"}, "cost-center": nil}}
+
+	missing, invalid := policy.Check(map[string]string{"team": "payments", "env": "prod", "cost-center": "42"})
+	require.Empty(t, missing)
+	require.Empty(t, invalid)
+
+	missing, invalid = policy.Check(map[string]string{"team": "", "env": "production"})
+	require.Equal(t, []string{"cost-center", "team"}, missing)
+	require.Equal(t, map[string]string{"env": "production"}, invalid)
+}
+
+func TestTagCompliance(t *testing.T) {
+	tagged := func(id string, tags ...string) resources.Resource {
+		list := []interface{}{}
+		for i := 0; i < len(tags); i += 2 {
+			list = append(list, map[string]interface{}{"Key": tags[i], "Value": tags[i+1]})
+		}
+		return resources.Resource{ID: id, Service: "ec2", Type: "instance", Metadata: map[string]interface{}{"Tags": list}}
+	}
+
+	compliance := NewTagCompliance(&TagPolicy{Required: map[string][]string{"team": nil, "env": {"prod"}}, OwnerTag: "team"})
+	compliance.Add(tagged("i-1", "team", "payments", "env", "prod"))
+	compliance.Add(tagged("i-3", "team", "payments", "env", "dev"))
+	compliance.Add(tagged("i-2"))
+	// no tags in the metadata
+	compliance.Add(resources.Resource{ID: "summary", Service: "iam", Type: "account-summary", Metadata: map[string]interface{}{}})
+	compliance.Add(resources.Resource{ID: "marker", Service: "aws-dump", Type: "summary", Metadata: map[string]interface{}{"Tags": []interface{}{}}})
+
+	result := compliance.Resources()
+	require.Len(t, result, 4)
+
+	require.Equal(t, "i-2", result[0].ID)
+	require.Equal(t, "tag-violation", result[0].Type)
+	require.Equal(t, map[string]interface{}{
+		"Service": "ec2",
+		"Type":    "instance",
+		"Owner":   "",
+		"Missing": []string{"env", "team"},
+		"Invalid": map[string]string{},
+	}, result[0].Metadata)
+
+	require.Equal(t, "i-3", result[1].ID)
+	require.Equal(t, map[string]string{"env": "dev"}, result[1].Metadata["Invalid"])
+
+	require.Equal(t, "ec2:", result[2].ID)
+	require.Equal(t, "tag-compliance", result[2].Type)
+	require.Equal(t, 1, result[2].Metadata["Violations"])
+	require.Equal(t, "ec2:payments", result[3].ID)
+	require.Equal(t, 2, result[3].Metadata["Resources"])
+	require.Equal(t, 1, result[3].Metadata["Violations"])
+}
@@ -1,20 +1,27 @@
 package main
 
 import (
+	"bytes"
+	"crypto/md5"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"strings"
+	"sync"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/service/s3"
-	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
 	"github.com/hamstah/awstools/aws/dump/resources"
 	"github.com/hamstah/awstools/common"
 	"github.com/hashicorp/terraform/states/statefile"
+	log "github.com/sirupsen/logrus"
 )
 
 type Substitution struct {
@@ -23,10 +30,14 @@ type Substitution struct {
 }
 
 type Options struct {
-	PathSubstitutions []Substitution `json:"path_substitutions"`
-	Overwrite         bool           `json:"overwrite"`
+	PathSubstitutions []Substitution    `json:"path_substitutions"`
+	Overwrite         bool              `json:"overwrite"`
+	Concurrency       int               `json:"concurrency"`
+	Comparison        *ComparisonFilter `json:"comparison"`
 }
 
+const defaultDownloadConcurrency = 10
+
 type S3Backend struct {
 	Bucket      string   `json:"bucket"`
 	Keys        []string `json:"keys"`
@@ -36,7 +47,132 @@ type S3Backend struct {
 	SessionName string   `json:"session_name"`
 }
 
-func (s3Backend *S3Backend) Download(destination string, options *Options) (map[string]string, error) {
+// DownloadSummary counts the state files by outcome, Failed has the error for
+// each state file that couldn't be downloaded.
+type DownloadSummary struct {
+	Downloaded int
+	Updated    int
+	Skipped    int
+	Failed     map[string]error
+}
+
+func NewDownloadSummary() *DownloadSummary {
+	return &DownloadSummary{Failed: map[string]error{}}
+}
+
+func (summary *DownloadSummary) Add(other *DownloadSummary) {
+	summary.Downloaded += other.Downloaded
+	summary.Updated += other.Updated
+	summary.Skipped += other.Skipped
+	for s3Path, err := range other.Failed {
+		summary.Failed[s3Path] = err
+	}
+}
+
+const (
+	stateDownloaded = "downloaded"
+	stateUpdated    = "updated"
+	stateSkipped    = "skipped"
+)
+
+type stateDownload struct {
+	Key      string
+	Filename string
+	S3Path   string
+	Exists   bool
+}
+
+// etagMD5 returns the MD5 of an object from its ETag. The ETag is not the MD5
+// of the content for multipart uploads and objects encrypted with KMS.
+func etagMD5(etag, serverSideEncryption string) (string, bool) {
+	if serverSideEncryption == s3.ServerSideEncryptionAwsKms {
+		return "", false
+	}
+
+	checksum := strings.Trim(etag, "\"")
+	if len(checksum) != 32 || strings.Contains(checksum, "-") {
+		return "", false
+	}
+	return checksum, true
+}
+
+func fileMD5(filename string) (string, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hash := md5.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// downloadState downloads a state file to a temporary file next to it and
+// replaces it once the checksum is verified. Existing state files are only
+// downloaded again when their checksum changed.
+func downloadState(client s3iface.S3API, bucket string, download stateDownload) (string, error) {
+	if download.Exists {
+		head, err := client.HeadObject(&s3.HeadObjectInput{
+			Bucket: aws.String(bucket),
+			Key:    aws.String(download.Key),
+		})
+		if err != nil {
+			return "", err
+		}
+
+		expected, ok := etagMD5(aws.StringValue(head.ETag), aws.StringValue(head.ServerSideEncryption))
+		if ok {
+			checksum, err := fileMD5(download.Filename)
+			if err == nil && checksum == expected {
+				return stateSkipped, nil
+			}
+		}
+	}
+
+	object, err := client.GetObject(&s3.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(download.Key),
+	})
+	if err != nil {
+		return "", err
+	}
+	defer object.Body.Close()
+
+	dir, name := filepath.Split(download.Filename)
+	file, err := ioutil.TempFile(dir, name+".*.tmp")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(file.Name())
+
+	hash := md5.New()
+	_, err = io.Copy(io.MultiWriter(file, hash), object.Body)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", err
+	}
+
+	expected, ok := etagMD5(aws.StringValue(object.ETag), aws.StringValue(object.ServerSideEncryption))
+	if checksum := hex.EncodeToString(hash.Sum(nil)); ok && checksum != expected {
+		return "", fmt.Errorf("checksum mismatch, expected %s got %s", expected, checksum)
+	}
+
+	if err := os.Rename(file.Name(), download.Filename); err != nil {
+		return "", err
+	}
+
+	if download.Exists {
+		return stateUpdated, nil
+	}
+	return stateDownloaded, nil
+}
+
+func (s3Backend *S3Backend) Download(destination string, options *Options) (map[string]string, *DownloadSummary, error) {
 	sess, conf := common.OpenSession(&common.SessionFlags{
 		RoleArn:         &s3Backend.RoleARN,
 		RoleExternalID:  &s3Backend.ExternalID,
@@ -48,8 +184,9 @@ func (s3Backend *S3Backend) Download(destination string, options *Options) (map[
 		MFATokenCode:    aws.String(""),
 	})
 
+	summary := NewDownloadSummary()
 	filenames := make(map[string]string, len(s3Backend.Keys))
-	objects := make([]s3manager.BatchDownloadObject, 0, len(s3Backend.Keys))
+	downloads := make([]stateDownload, 0, len(s3Backend.Keys))
 	for _, key := range s3Backend.Keys {
 
 		transformed := key
@@ -62,47 +199,166 @@ func (s3Backend *S3Backend) Download(destination string, options *Options) (map[
 		dir, transformed := filepath.Split(transformed)
 		err := os.MkdirAll(filepath.Join(destination, s3Backend.Bucket, dir), os.ModePerm)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 
-		filename := filepath.Join(destination, s3Backend.Bucket, dir, transformed)
-		filenames[filename] = fmt.Sprintf("arn:aws:s3:::%s/%s", s3Backend.Bucket, key)
+		download := stateDownload{
+			Key:      key,
+			Filename: filepath.Join(destination, s3Backend.Bucket, dir, transformed),
+			S3Path:   fmt.Sprintf("arn:aws:s3:::%s/%s", s3Backend.Bucket, key),
+		}
 
-		if _, err := os.Stat(filename); !os.IsNotExist(err) && !options.Overwrite {
-			// file already exists
-			continue
+		if _, err := os.Stat(download.Filename); !os.IsNotExist(err) {
+			download.Exists = true
+			if !options.Overwrite {
+				summary.Skipped++
+				filenames[download.Filename] = download.S3Path
+				continue
+			}
 		}
 
-		file, err := os.OpenFile(filename, os.O_WRONLY|os.O_CREATE, 0666)
+		downloads = append(downloads, download)
+	}
+
+	concurrency := options.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultDownloadConcurrency
+	}
+
+	client := s3.New(sess, conf)
+	queue := make(chan stateDownload)
+	lock := sync.Mutex{}
+	wg := sync.WaitGroup{}
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for download := range queue {
+				outcome, err := downloadState(client, s3Backend.Bucket, download)
+
+				lock.Lock()
+				switch {
+				case err != nil:
+					summary.Failed[download.S3Path] = err
+				case outcome == stateDownloaded:
+					summary.Downloaded++
+				case outcome == stateUpdated:
+					summary.Updated++
+				default:
+					summary.Skipped++
+				}
+				if err == nil {
+					filenames[download.Filename] = download.S3Path
+				}
+				lock.Unlock()
+			}
+		}()
+	}
+
+	for _, download := range downloads {
+		queue <- download
+	}
+	close(queue)
+	wg.Wait()
+
+	return filenames, summary, nil
+}
+
+// WorkingDirectory is an initialised terraform working directory, its states
+// are read with `terraform state pull` so terraform handles the backend.
+type WorkingDirectory struct {
+	Path          string   `json:"path"`
+	Workspaces    []string `json:"workspaces"`
+	AllWorkspaces bool     `json:"all_workspaces"`
+}
+
+func (workingDirectory *WorkingDirectory) terraform(binary, workspace string, args ...string) ([]byte, error) {
+	cmd := exec.Command(binary, args...)
+	cmd.Dir = workingDirectory.Path
+	cmd.Env = os.Environ()
+	if workspace != "" {
+		cmd.Env = append(cmd.Env, "TF_WORKSPACE="+workspace)
+	}
+
+	stderr := &bytes.Buffer{}
+	cmd.Stderr = stderr
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("terraform %s failed: %s: %s", strings.Join(args, " "), err, strings.TrimSpace(stderr.String()))
+	}
+	return output, nil
+}
+
+// parseWorkspaces parses the output of `terraform workspace list`.
+func parseWorkspaces(output string) []string {
+	workspaces := []string{}
+	for _, line := range strings.Split(output, "\n") {
+		workspace := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(line), "*"))
+		if workspace != "" {
+			workspaces = append(workspaces, workspace)
+		}
+	}
+	return workspaces
+}
+
+// Pull writes the state of each workspace to destination, the default
+// workspace is used when no workspace is configured.
+func (workingDirectory *WorkingDirectory) Pull(destination, binary string) (map[string]string, *DownloadSummary, error) {
+	workspaces := workingDirectory.Workspaces
+	if workingDirectory.AllWorkspaces {
+		output, err := workingDirectory.terraform(binary, "", "workspace", "list")
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
+		workspaces = parseWorkspaces(string(output))
+	}
+	if len(workspaces) == 0 {
+		workspaces = []string{"default"}
+	}
 
-		objects = append(objects, s3manager.BatchDownloadObject{
-			Object: &s3.GetObjectInput{
-				Bucket: aws.String(s3Backend.Bucket),
-				Key:    aws.String(key),
-			},
-			Writer: file,
-		})
+	name := strings.Trim(strings.Replace(filepath.ToSlash(filepath.Clean(workingDirectory.Path)), "/", "-", -1), "-.")
+	dir := filepath.Join(destination, "working-directories", name)
+	err := os.MkdirAll(dir, os.ModePerm)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	if len(objects) > 0 {
-		client := s3.New(sess, conf)
-		manager := s3manager.NewDownloaderWithClient(client)
-		iter := &s3manager.DownloadObjectsIterator{Objects: objects}
-		if err := manager.DownloadWithIterator(aws.BackgroundContext(), iter); err != nil {
-			return nil, err
+	summary := NewDownloadSummary()
+	filenames := map[string]string{}
+	for _, workspace := range workspaces {
+		statePath := fmt.Sprintf("%s#%s", workingDirectory.Path, workspace)
+
+		state, err := workingDirectory.terraform(binary, workspace, "state", "pull")
+		if err != nil {
+			summary.Failed[statePath] = err
+			continue
 		}
+		if len(bytes.TrimSpace(state)) == 0 {
+			// no state yet
+			summary.Skipped++
+			continue
+		}
+
+		filename := filepath.Join(dir, workspace+".tfstate")
+		err = ioutil.WriteFile(filename, state, 0644)
+		if err != nil {
+			summary.Failed[statePath] = err
+			continue
+		}
+
+		summary.Downloaded++
+		filenames[filename] = statePath
 	}
 
-	return filenames, nil
+	return filenames, summary, nil
 }
 
 type TerraformBackends struct {
-	Destination string       `json:"destination"`
-	Options     *Options     `json:"options"`
-	S3          []*S3Backend `json:"s3"`
+	Destination        string              `json:"destination"`
+	Options            *Options            `json:"options"`
+	S3                 []*S3Backend        `json:"s3"`
+	WorkingDirectories []*WorkingDirectory `json:"working_directories"`
+	TerraformBinary    string              `json:"terraform_binary"`
 
 	StateFilenames map[string]string
 }
@@ -112,8 +368,12 @@ func (t *TerraformBackends) Verify() error {
 		return errors.New("Destination field is empty")
 	}
 
-	if len(t.S3) == 0 {
-		return errors.New("s3 field is empty")
+	if len(t.S3) == 0 && len(t.WorkingDirectories) == 0 {
+		return errors.New("s3 and working_directories fields are empty")
+	}
+
+	if t.TerraformBinary == "" {
+		t.TerraformBinary = "terraform"
 	}
 
 	if t.Options == nil {
@@ -133,15 +393,43 @@ func (t *TerraformBackends) Pull() error {
 	}
 
 	t.StateFilenames = map[string]string{}
+	summary := NewDownloadSummary()
 	for _, backend := range t.S3 {
-		filenames, err := backend.Download(t.Destination, t.Options)
+		filenames, backendSummary, err := backend.Download(t.Destination, t.Options)
 		if err != nil {
 			return err
 		}
+		summary.Add(backendSummary)
 		for filename, s3 := range filenames {
 			t.StateFilenames[filename] = s3
 		}
 	}
+
+	for _, workingDirectory := range t.WorkingDirectories {
+		filenames, workingDirectorySummary, err := workingDirectory.Pull(t.Destination, t.TerraformBinary)
+		if err != nil {
+			return err
+		}
+		summary.Add(workingDirectorySummary)
+		for filename, statePath := range filenames {
+			t.StateFilenames[filename] = statePath
+		}
+	}
+
+	log.WithFields(log.Fields{
+		"downloaded": summary.Downloaded,
+		"updated":    summary.Updated,
+		"skipped":    summary.Skipped,
+		"failed":     len(summary.Failed),
+	}).Info("Pulled terraform state files")
+
+	for s3Path, err := range summary.Failed {
+		log.WithField("state", s3Path).Error(err)
+	}
+
+	if len(summary.Failed) > 0 {
+		return fmt.Errorf("failed to download %d terraform state files", len(summary.Failed))
+	}
 	return nil
 }
 
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/stretchr/testify/require"
+)
+
+type stubS3 struct {
+	s3iface.S3API
+	content []byte
+	etag    string
+	gets    int
+}
+
+func (client *stubS3) HeadObject(input *s3.HeadObjectInput) (*s3.HeadObjectOutput, error) {
+	return &s3.HeadObjectOutput{ETag: aws.String(client.etag)}, nil
+}
+
+func (client *stubS3) GetObject(input *s3.GetObjectInput) (*s3.GetObjectOutput, error) {
+	client.gets++
+	return &s3.GetObjectOutput{
+		ETag: aws.String(client.etag),
+		Body: ioutil.NopCloser(bytes.NewReader(client.content)),
+	}, nil
+}
+
+func md5Hex(content []byte) string {
+	checksum := md5.Sum(content)
+	return hex.EncodeToString(checksum[:])
+}
+
+func TestEtagMD5(t *testing.T) {
+	checksum, ok := etagMD5("\"9e107d9d372bb6826bd81d3542a419d6\"", "")
+	require.True(t, ok)
+	require.Equal(t, "9e107d9d372bb6826bd81d3542a419d6", checksum)
+
+	_, ok = etagMD5("\"9e107d9d372bb6826bd81d3542a419d6-2\"", "")
+	require.False(t, ok)
+
+	_, ok = etagMD5("\"9e107d9d372bb6826bd81d3542a419d6\"", s3.ServerSideEncryptionAwsKms)
+	require.False(t, ok)
+}
+
+func TestDownloadState(t *testing.T) {
+	dir, err := ioutil.TempDir("", "terraform-states")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	content := []byte(`{"version": 4}`)
+	client := &stubS3{content: content, etag: "\"" + md5Hex(content) + "\""}
+	download := stateDownload{Key: "test.tfstate", Filename: filepath.Join(dir, "test.tfstate")}
+
+	outcome, err := downloadState(client, "bucket", download)
+	require.NoError(t, err)
+	require.Equal(t, stateDownloaded, outcome)
+
+	data, err := ioutil.ReadFile(download.Filename)
+	require.NoError(t, err)
+	require.Equal(t, content, data)
+
+	download.Exists = true
+	outcome, err = downloadState(client, "bucket", download)
+	require.NoError(t, err)
+	require.Equal(t, stateSkipped, outcome)
+	require.Equal(t, 1, client.gets)
+
+	client.content = []byte(`{"version": 4, "serial": 2}`)
+	client.etag = "\"" + md5Hex(client.content) + "\""
+	outcome, err = downloadState(client, "bucket", download)
+	require.NoError(t, err)
+	require.Equal(t, stateUpdated, outcome)
+
+	client.content = []byte("corrupted")
+	client.etag = "\"" + md5Hex([]byte(`{"version": 4, "serial": 3}`)) + "\""
+	_, err = downloadState(client, "bucket", download)
+	require.Error(t, err)
+
+	data, err = ioutil.ReadFile(download.Filename)
+	require.NoError(t, err)
+	require.Equal(t, `{"version": 4, "serial": 2}`, string(data))
+}
+
+func TestParseWorkspaces(t *testing.T) {
+	require.Equal(t, []string{"default", "prod", "staging"}, parseWorkspaces("  default\n* prod\n  staging\n\n"))
+	require.Equal(t, []string{}, parseWorkspaces(""))
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager/s3manageriface"
+)
+
+var outputContentTypes = map[string]string{
+	"json":   "application/json",
+	"jsonl":  "application/x-ndjson",
+	"csv":    "text/csv",
+	"tar.gz": "application/gzip",
+}
+
+type S3Upload struct {
+	Bucket   string
+	Prefix   string
+	KMSKeyID string
+}
+
+// Key returns <prefix>/<yyyy>/<mm>/<dd>/<account>/aws-dump-<time>.<extension>
+// with the account id when a single account was dumped, multi-account
+// otherwise.
+func (u *S3Upload) Key(accountIDs []string, now time.Time, extension string) string {
+	account := "multi-account"
+	if len(accountIDs) == 1 {
+		account = accountIDs[0]
+	}
+
+	now = now.UTC()
+	return path.Join(
+		u.Prefix,
+		now.Format("2006/01/02"),
+		account,
+		fmt.Sprintf("aws-dump-%s.%s", now.Format("20060102T150405Z"), extension),
+	)
+}
+
+// Upload uploads the file to the key, encrypted with the KMS key if there is
+// one, and returns its location.
+func (u *S3Upload) Upload(uploader s3manageriface.UploaderAPI, filename, key, extension string) (string, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	input := &s3manager.UploadInput{
+		Bucket:      aws.String(u.Bucket),
+		Key:         aws.String(key),
+		Body:        file,
+		ContentType: aws.String(outputContentTypes[extension]),
+	}
+	if u.KMSKeyID != "" {
+		input.ServerSideEncryption = aws.String(s3.ServerSideEncryptionAwsKms)
+		input.SSEKMSKeyId = aws.String(u.KMSKeyID)
+	}
+
+	output, err := uploader.Upload(input)
+	if err != nil {
+		return "", err
+	}
+	return output.Location, nil
+}
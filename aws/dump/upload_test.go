@@ -0,0 +1,19 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestS3UploadKey(t *testing.T) {
+	now := time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	upload := &S3Upload{Bucket: "dumps", Prefix: "aws-dump/"}
+	require.Equal(t, "aws-dump/2021/01/02/123456789012/aws-dump-20210102T030405Z.json", upload.Key([]string{"123456789012"}, now, "json"))
+	require.Equal(t, "aws-dump/2021/01/02/multi-account/aws-dump-20210102T030405Z.tar.gz", upload.Key([]string{"123456789012", "210987654321"}, now, "tar.gz"))
+
+	upload = &S3Upload{Bucket: "dumps"}
+	require.Equal(t, "2021/01/02/multi-account/aws-dump-20210102T030405Z.csv", upload.Key([]string{}, now, "csv"))
+}
@@ -0,0 +1,295 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/route53"
+	"github.com/hamstah/awstools/common"
+	kingpin "gopkg.in/alecthomas/kingpin.v2"
+)
+
+var (
+	eip             = kingpin.Flag("eip", "Allocation id or public IP of the Elastic IP to move.").String()
+	primary         = kingpin.Flag("primary", "Instance or network interface id the Elastic IP is moved from.").String()
+	standby         = kingpin.Flag("standby", "Instance or network interface id the Elastic IP is moved to.").String()
+	hostedZoneID    = kingpin.Flag("hosted-zone-id", "Hosted zone of the record sets to switch.").String()
+	recordName      = kingpin.Flag("record-name", "Name of the weighted or failover record sets to switch.").String()
+	recordType      = kingpin.Flag("record-type", "Type of the record sets to switch.").Default("A").String()
+	primarySetID    = kingpin.Flag("primary-set-id", "Set identifier of the record set the traffic is moved from.").String()
+	standbySetID    = kingpin.Flag("standby-set-id", "Set identifier of the record set the traffic is moved to.").String()
+	skipHealthCheck = kingpin.Flag("skip-health-check", "Switch even if the standby is not healthy.").Default("false").Bool()
+	yes             = kingpin.Flag("yes", "Switch without asking for confirmation.").Default("false").Bool()
+	dryRun          = kingpin.Flag("dry-run", "Print the switch without applying it.").Default("false").Bool()
+)
+
+func promptConfirm(text string) bool {
+	var response string
+	fmt.Print(text)
+	fmt.Scanln(&response)
+	fmt.Println()
+	return response == "y"
+}
+
+// confirm returns whether the switch should be applied.
+func confirm(text string) bool {
+	if *dryRun {
+		fmt.Println("Dry run, not switching")
+		return false
+	}
+	if *yes {
+		return true
+	}
+	if !promptConfirm(fmt.Sprintf("%s? (y/n) [n]: ", text)) {
+		fmt.Println("Not switching")
+		return false
+	}
+	return true
+}
+
+// checkHealth exits when the standby is not healthy, unless
+// --skip-health-check is set.
+func checkHealth(healthy bool, message string) {
+	if healthy {
+		fmt.Printf("Standby healthy: %s\n", message)
+		return
+	}
+	if *skipHealthCheck {
+		fmt.Printf("Standby not healthy, switching anyway: %s\n", message)
+		return
+	}
+	common.Fatalln(fmt.Sprintf("Standby not healthy: %s, use --skip-health-check to switch anyway", message))
+}
+
+func summaryStatus(summary *ec2.InstanceStatusSummary) string {
+	if summary == nil {
+		return ""
+	}
+	return aws.StringValue(summary.Status)
+}
+
+// targetHealth returns whether the standby instance is running with its
+// status checks passing, or the standby network interface is attached to one.
+func targetHealth(client *ec2.EC2, target *Target) (bool, string, error) {
+	instanceID := target.InstanceID
+	if target.NetworkInterfaceID != "" {
+		res, err := client.DescribeNetworkInterfaces(&ec2.DescribeNetworkInterfacesInput{
+			NetworkInterfaceIds: aws.StringSlice([]string{target.NetworkInterfaceID}),
+		})
+		if err != nil {
+			return false, "", err
+		}
+		if len(res.NetworkInterfaces) == 0 {
+			return false, "", fmt.Errorf("network interface %s not found", target.NetworkInterfaceID)
+		}
+		networkInterface := res.NetworkInterfaces[0]
+		if networkInterface.Attachment == nil || networkInterface.Attachment.InstanceId == nil {
+			return false, fmt.Sprintf("%s is %s", target.NetworkInterfaceID, aws.StringValue(networkInterface.Status)), nil
+		}
+		instanceID = aws.StringValue(networkInterface.Attachment.InstanceId)
+	}
+
+	res, err := client.DescribeInstanceStatus(&ec2.DescribeInstanceStatusInput{
+		InstanceIds:         aws.StringSlice([]string{instanceID}),
+		IncludeAllInstances: aws.Bool(true),
+	})
+	if err != nil {
+		return false, "", err
+	}
+	if len(res.InstanceStatuses) == 0 {
+		return false, "", fmt.Errorf("instance %s not found", instanceID)
+	}
+
+	status := res.InstanceStatuses[0]
+	state := ""
+	if status.InstanceState != nil {
+		state = aws.StringValue(status.InstanceState.Name)
+	}
+	instanceStatus := summaryStatus(status.InstanceStatus)
+	systemStatus := summaryStatus(status.SystemStatus)
+	message := fmt.Sprintf("%s is %s, instance status %s, system status %s", instanceID, state, instanceStatus, systemStatus)
+	return state == ec2.InstanceStateNameRunning && instanceStatus == ec2.SummaryStatusOk && systemStatus == ec2.SummaryStatusOk, message, nil
+}
+
+func switchEIP(sess *session.Session, conf *aws.Config) {
+	from, err := NewTarget(*primary)
+	common.FatalOnError(err)
+	to, err := NewTarget(*standby)
+	common.FatalOnError(err)
+
+	client := ec2.New(sess, conf)
+	input := &ec2.DescribeAddressesInput{}
+	if strings.HasPrefix(*eip, "eipalloc-") {
+		input.AllocationIds = aws.StringSlice([]string{*eip})
+	} else {
+		input.PublicIps = aws.StringSlice([]string{*eip})
+	}
+	res, err := client.DescribeAddresses(input)
+	common.FatalOnErrorW(err, "failed to describe the Elastic IP")
+	if len(res.Addresses) == 0 {
+		common.Fatalln(fmt.Sprintf("Elastic IP %s not found", *eip))
+	}
+	address := res.Addresses[0]
+	publicIP := aws.StringValue(address.PublicIp)
+	instanceID := aws.StringValue(address.InstanceId)
+	networkInterfaceID := aws.StringValue(address.NetworkInterfaceId)
+
+	if to.Is(instanceID, networkInterfaceID) {
+		fmt.Printf("%s is already associated with the standby %s\n", publicIP, to)
+		return
+	}
+	if !from.Is(instanceID, networkInterfaceID) {
+		current := instanceID
+		if current == "" {
+			current = networkInterfaceID
+		}
+		if current == "" {
+			current = "nothing"
+		}
+		common.Fatalln(fmt.Sprintf("%s is associated with %s, not the primary %s", publicIP, current, from))
+	}
+
+	healthy, message, err := targetHealth(client, to)
+	common.FatalOnErrorW(err, "failed to check the health of the standby")
+	checkHealth(healthy, message)
+
+	fmt.Printf("Moving %s from %s to %s\n", publicIP, from, to)
+	if !confirm("Switch") {
+		return
+	}
+
+	associate := &ec2.AssociateAddressInput{
+		AllocationId:       address.AllocationId,
+		AllowReassociation: aws.Bool(true),
+	}
+	if to.InstanceID != "" {
+		associate.InstanceId = aws.String(to.InstanceID)
+	} else {
+		associate.NetworkInterfaceId = aws.String(to.NetworkInterfaceID)
+	}
+	_, err = client.AssociateAddress(associate)
+	common.FatalOnErrorW(err, "failed to associate the Elastic IP")
+	fmt.Printf("%s is now associated with %s\n", publicIP, to)
+}
+
+// listRecordSets returns the record sets with the name and type.
+func listRecordSets(client *route53.Route53) ([]*route53.ResourceRecordSet, error) {
+	name := *recordName
+	if !strings.HasSuffix(name, ".") {
+		name += "."
+	}
+
+	recordSets := []*route53.ResourceRecordSet{}
+	input := &route53.ListResourceRecordSetsInput{
+		HostedZoneId:    hostedZoneID,
+		StartRecordName: aws.String(name),
+		StartRecordType: recordType,
+	}
+	err := client.ListResourceRecordSetsPages(input, func(page *route53.ListResourceRecordSetsOutput, lastPage bool) bool {
+		for _, recordSet := range page.ResourceRecordSets {
+			// the record sets are sorted by name and type
+			if !strings.EqualFold(aws.StringValue(recordSet.Name), name) || aws.StringValue(recordSet.Type) != *recordType {
+				return false
+			}
+			recordSets = append(recordSets, recordSet)
+		}
+		return true
+	})
+	return recordSets, err
+}
+
+func recordSetValue(recordSet *route53.ResourceRecordSet) string {
+	if recordSet.AliasTarget != nil {
+		return fmt.Sprintf("alias %s", aws.StringValue(recordSet.AliasTarget.DNSName))
+	}
+	values := []string{}
+	for _, record := range recordSet.ResourceRecords {
+		values = append(values, aws.StringValue(record.Value))
+	}
+	return strings.Join(values, ",")
+}
+
+func describeRecordSet(recordSet *route53.ResourceRecordSet) string {
+	routing := aws.StringValue(recordSet.Failover)
+	if recordSet.Weight != nil {
+		routing = fmt.Sprintf("weight %d", aws.Int64Value(recordSet.Weight))
+	}
+	return fmt.Sprintf("%s (%s, %s)", aws.StringValue(recordSet.SetIdentifier), recordSetValue(recordSet), routing)
+}
+
+func switchRecord(sess *session.Session, conf *aws.Config) {
+	client := route53.New(sess, conf)
+
+	recordSets, err := listRecordSets(client)
+	common.FatalOnErrorW(err, "failed to list the record sets")
+
+	plan, err := PlanRecordSwitch(recordSets, *primarySetID, *standbySetID)
+	common.FatalOnErrorW(err, "invalid record sets")
+	if len(plan.Changes) == 0 {
+		fmt.Printf("The traffic of %s %s is already on the standby %s\n", *recordName, *recordType, describeRecordSet(plan.Standby))
+		return
+	}
+
+	healthCheckID := aws.StringValue(plan.Standby.HealthCheckId)
+	if healthCheckID == "" {
+		checkHealth(false, fmt.Sprintf("no health check on %s", *standbySetID))
+	} else {
+		res, err := client.GetHealthCheckStatus(&route53.GetHealthCheckStatusInput{HealthCheckId: aws.String(healthCheckID)})
+		common.FatalOnErrorW(err, "failed to get the status of the health check")
+		healthy, healthyCheckers := HealthCheckHealthy(res.HealthCheckObservations)
+		checkHealth(healthy, fmt.Sprintf("%d/%d checkers of %s report healthy", healthyCheckers, len(res.HealthCheckObservations), healthCheckID))
+	}
+
+	fmt.Printf("Switching the %s record sets of %s %s\n", plan.Routing, *recordName, *recordType)
+	for i, change := range plan.Changes {
+		before := plan.Primary
+		if i == 1 {
+			before = plan.Standby
+		}
+		fmt.Printf("  %s -> %s\n", describeRecordSet(before), describeRecordSet(change.ResourceRecordSet))
+	}
+	if !confirm("Switch") {
+		return
+	}
+
+	res, err := client.ChangeResourceRecordSets(&route53.ChangeResourceRecordSetsInput{
+		HostedZoneId: hostedZoneID,
+		ChangeBatch: &route53.ChangeBatch{
+			Comment: aws.String(fmt.Sprintf("failover from %s to %s", *primarySetID, *standbySetID)),
+			Changes: plan.Changes,
+		},
+	})
+	common.FatalOnErrorW(err, "failed to change the record sets")
+
+	err = client.WaitUntilResourceRecordSetsChanged(&route53.GetChangeInput{Id: res.ChangeInfo.Id})
+	common.FatalOnErrorW(err, "failed to wait for the change")
+	fmt.Printf("Switched, change %s is in sync\n", aws.StringValue(res.ChangeInfo.Id))
+}
+
+func main() {
+	kingpin.CommandLine.Name = "aws-failover-switch"
+	kingpin.CommandLine.Help = "Move an Elastic IP or the traffic of Route53 record sets from a primary to a standby."
+	flags := common.HandleFlags()
+
+	eipMode := *eip != "" || *primary != "" || *standby != ""
+	recordMode := *hostedZoneID != "" || *recordName != "" || *primarySetID != "" || *standbySetID != ""
+	if eipMode == recordMode {
+		common.Fatalln("Use either --eip, --primary and --standby, or --hosted-zone-id, --record-name, --primary-set-id and --standby-set-id")
+	}
+	if eipMode && (*eip == "" || *primary == "" || *standby == "") {
+		common.Fatalln("--eip, --primary and --standby are required to move an Elastic IP")
+	}
+	if recordMode && (*hostedZoneID == "" || *recordName == "" || *primarySetID == "" || *standbySetID == "") {
+		common.Fatalln("--hosted-zone-id, --record-name, --primary-set-id and --standby-set-id are required to switch record sets")
+	}
+
+	sess, conf := common.OpenSession(flags)
+	if eipMode {
+		switchEIP(sess, conf)
+	} else {
+		switchRecord(sess, conf)
+	}
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/route53"
+)
+
+const (
+	RoutingWeighted = "weighted"
+	RoutingFailover = "failover"
+
+	// Route53 considers an endpoint healthy when more than 18% of its
+	// checkers report it healthy
+	healthyCheckersRatio = 0.18
+)
+
+// Target is an instance or a network interface an Elastic IP is associated
+// with.
+type Target struct {
+	InstanceID         string
+	NetworkInterfaceID string
+}
+
+func NewTarget(id string) (*Target, error) {
+	switch {
+	case strings.HasPrefix(id, "i-"):
+		return &Target{InstanceID: id}, nil
+	case strings.HasPrefix(id, "eni-"):
+		return &Target{NetworkInterfaceID: id}, nil
+	}
+	return nil, fmt.Errorf("invalid target %s, should be an instance id (i-) or a network interface id (eni-)", id)
+}
+
+func (t *Target) String() string {
+	if t.InstanceID != "" {
+		return t.InstanceID
+	}
+	return t.NetworkInterfaceID
+}
+
+// Is returns whether the Elastic IP association is on the target, an
+// association with a network interface of an instance matches the instance.
+func (t *Target) Is(instanceID, networkInterfaceID string) bool {
+	if t.InstanceID != "" {
+		return t.InstanceID == instanceID
+	}
+	return t.NetworkInterfaceID == networkInterfaceID
+}
+
+// RecordSwitch is the change of the record sets moving the traffic from the
+// primary to the standby set identifier, Changes is empty when the traffic is
+// already on the standby.
+type RecordSwitch struct {
+	Routing string
+	Primary *route53.ResourceRecordSet
+	Standby *route53.ResourceRecordSet
+	Changes []*route53.Change
+}
+
+func findRecordSet(recordSets []*route53.ResourceRecordSet, setIdentifier string) (*route53.ResourceRecordSet, error) {
+	for _, recordSet := range recordSets {
+		if aws.StringValue(recordSet.SetIdentifier) == setIdentifier {
+			return recordSet, nil
+		}
+	}
+	return nil, fmt.Errorf("record set %s not found", setIdentifier)
+}
+
+// copyRecordSet returns a copy of the record set, only its routing fields are
+// changed by the switch.
+func copyRecordSet(recordSet *route53.ResourceRecordSet) *route53.ResourceRecordSet {
+	result := *recordSet
+	return &result
+}
+
+// PlanRecordSwitch returns the changes moving the traffic of the weighted or
+// failover record sets of a name and type from the primary to the standby set
+// identifier. The weight of the primary moves to the standby, and the failover
+// roles of the record sets are swapped.
+func PlanRecordSwitch(recordSets []*route53.ResourceRecordSet, primaryID, standbyID string) (*RecordSwitch, error) {
+	primary, err := findRecordSet(recordSets, primaryID)
+	if err != nil {
+		return nil, err
+	}
+	standby, err := findRecordSet(recordSets, standbyID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &RecordSwitch{Primary: primary, Standby: standby, Changes: []*route53.Change{}}
+	newPrimary := copyRecordSet(primary)
+	newStandby := copyRecordSet(standby)
+
+	switch {
+	case primary.Weight != nil && standby.Weight != nil:
+		result.Routing = RoutingWeighted
+		primaryWeight := aws.Int64Value(primary.Weight)
+		if primaryWeight == 0 && aws.Int64Value(standby.Weight) > 0 {
+			return result, nil
+		}
+		if primaryWeight == 0 {
+			primaryWeight = 1
+		}
+		newPrimary.Weight = aws.Int64(0)
+		newStandby.Weight = aws.Int64(primaryWeight)
+
+	case primary.Failover != nil && standby.Failover != nil:
+		result.Routing = RoutingFailover
+		primaryRole := aws.StringValue(primary.Failover)
+		standbyRole := aws.StringValue(standby.Failover)
+		if primaryRole == route53.ResourceRecordSetFailoverSecondary && standbyRole == route53.ResourceRecordSetFailoverPrimary {
+			return result, nil
+		}
+		if primaryRole != route53.ResourceRecordSetFailoverPrimary || standbyRole != route53.ResourceRecordSetFailoverSecondary {
+			return nil, fmt.Errorf("%s is %s and %s is %s, expected PRIMARY and SECONDARY", primaryID, primaryRole, standbyID, standbyRole)
+		}
+		newPrimary.Failover = aws.String(route53.ResourceRecordSetFailoverSecondary)
+		newStandby.Failover = aws.String(route53.ResourceRecordSetFailoverPrimary)
+
+	default:
+		return nil, fmt.Errorf("%s and %s are not both weighted or failover record sets", primaryID, standbyID)
+	}
+
+	// the record sets are changed in the same batch, which Route53 applies
+	// atomically
+	for _, recordSet := range []*route53.ResourceRecordSet{newPrimary, newStandby} {
+		result.Changes = append(result.Changes, &route53.Change{
+			Action:            aws.String(route53.ChangeActionUpsert),
+			ResourceRecordSet: recordSet,
+		})
+	}
+	return result, nil
+}
+
+// HealthCheckHealthy returns whether the observations of the Route53 checkers
+// report the endpoint healthy, and the number of healthy checkers.
+func HealthCheckHealthy(observations []*route53.HealthCheckObservation) (bool, int) {
+	healthy := 0
+	for _, observation := range observations {
+		if observation.StatusReport != nil && strings.HasPrefix(aws.StringValue(observation.StatusReport.Status), "Success") {
+			healthy++
+		}
+	}
+	return len(observations) > 0 && float64(healthy) > healthyCheckersRatio*float64(len(observations)), healthy
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/route53"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTarget(t *testing.T) {
+	target, err := NewTarget("i-0123")
+	require.NoError(t, err)
+	require.True(t, target.Is("i-0123", "eni-0456"))
+	require.False(t, target.Is("", "eni-0456"))
+
+	target, err = NewTarget("eni-0456")
+	require.NoError(t, err)
+	require.True(t, target.Is("i-0123", "eni-0456"))
+	require.Equal(t, "eni-0456", target.String())
+
+	_, err = NewTarget("sg-0123")
+	require.Error(t, err)
+}
+
+func TestPlanRecordSwitchWeighted(t *testing.T) {
+	recordSets := []*route53.ResourceRecordSet{
+		{SetIdentifier: aws.String("eu-west-1"), Weight: aws.Int64(100), TTL: aws.Int64(60)},
+		{SetIdentifier: aws.String("eu-central-1"), Weight: aws.Int64(0), TTL: aws.Int64(60)},
+	}
+
+	plan, err := PlanRecordSwitch(recordSets, "eu-west-1", "eu-central-1")
+	require.NoError(t, err)
+	require.Equal(t, RoutingWeighted, plan.Routing)
+	require.Len(t, plan.Changes, 2)
+	require.Equal(t, int64(0), aws.Int64Value(plan.Changes[0].ResourceRecordSet.Weight))
+	require.Equal(t, int64(100), aws.Int64Value(plan.Changes[1].ResourceRecordSet.Weight))
+	require.Equal(t, int64(60), aws.Int64Value(plan.Changes[1].ResourceRecordSet.TTL))
+	// the record sets listed are not modified
+	require.Equal(t, int64(100), aws.Int64Value(recordSets[0].Weight))
+
+	// already switched
+	plan, err = PlanRecordSwitch(recordSets, "eu-central-1", "eu-west-1")
+	require.NoError(t, err)
+	require.Empty(t, plan.Changes)
+
+	_, err = PlanRecordSwitch(recordSets, "eu-west-1", "us-east-1")
+	require.EqualError(t, err, "record set us-east-1 not found")
+}
+
+func TestPlanRecordSwitchFailover(t *testing.T) {
+	recordSets := []*route53.ResourceRecordSet{
+		{SetIdentifier: aws.String("primary"), Failover: aws.String("PRIMARY")},
+		{SetIdentifier: aws.String("standby"), Failover: aws.String("SECONDARY")},
+		{SetIdentifier: aws.String("weighted"), Weight: aws.Int64(1)},
+	}
+
+	plan, err := PlanRecordSwitch(recordSets, "primary", "standby")
+	require.NoError(t, err)
+	require.Equal(t, RoutingFailover, plan.Routing)
+	require.Equal(t, "SECONDARY", aws.StringValue(plan.Changes[0].ResourceRecordSet.Failover))
+	require.Equal(t, "PRIMARY", aws.StringValue(plan.Changes[1].ResourceRecordSet.Failover))
+
+	plan, err = PlanRecordSwitch(recordSets, "standby", "primary")
+	require.NoError(t, err)
+	require.Empty(t, plan.Changes)
+
+	_, err = PlanRecordSwitch(recordSets, "primary", "weighted")
+	require.Error(t, err)
+}
+
+func TestHealthCheckHealthy(t *testing.T) {
+	observation := func(status string) *route53.HealthCheckObservation {
+		return &route53.HealthCheckObservation{StatusReport: &route53.StatusReport{Status: aws.String(status)}}
+	}
+
+	healthy, count := HealthCheckHealthy([]*route53.HealthCheckObservation{
+		observation("Success: HTTP Status Code 200, OK"),
+		observation("Failure: Connection timed out."),
+		observation("Failure: Connection timed out."),
+		observation("Failure: Connection timed out."),
+	})
+	require.True(t, healthy)
+	require.Equal(t, 1, count)
+
+	healthy, _ = HealthCheckHealthy([]*route53.HealthCheckObservation{
+		observation("Success: HTTP Status Code 200, OK"),
+		observation("Failure: Connection timed out."),
+		observation("Failure: Connection timed out."),
+		observation("Failure: Connection timed out."),
+		observation("Failure: Connection timed out."),
+		observation("Failure: Connection timed out."),
+	})
+	require.False(t, healthy)
+
+	healthy, _ = HealthCheckHealthy(nil)
+	require.False(t, healthy)
+}
@@ -0,0 +1,327 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+type Priority int
+
+const (
+	PriorityLow Priority = iota
+	PriorityMedium
+	PriorityHigh
+)
+
+func (p Priority) String() string {
+	switch p {
+	case PriorityHigh:
+		return "high"
+	case PriorityMedium:
+		return "medium"
+	default:
+		return "low"
+	}
+}
+
+func (p Priority) MarshalText() ([]byte, error) {
+	return []byte(p.String()), nil
+}
+
+// Resource is the subset of the aws-dump output used to find exposed resources.
+type Resource struct {
+	ID        string                 `json:"id"`
+	ARN       string                 `json:"arn"`
+	Service   string                 `json:"service"`
+	Type      string                 `json:"type"`
+	AccountID string                 `json:"account_id"`
+	Region    string                 `json:"region"`
+	Metadata  map[string]interface{} `json:"metadata"`
+}
+
+type Finding struct {
+	Priority   Priority `json:"priority"`
+	Kind       string   `json:"kind"`
+	AccountID  string   `json:"account_id"`
+	Region     string   `json:"region"`
+	ResourceID string   `json:"resource_id"`
+	ARN        string   `json:"arn"`
+	Endpoint   string   `json:"endpoint"`
+	Reason     string   `json:"reason"`
+}
+
+var (
+	// ports that should never be open to the internet
+	sensitivePorts = []int{22, 23, 445, 1433, 1521, 2375, 3306, 3389, 5432, 5601, 6379, 9200, 11211, 27017}
+
+	// ports commonly exposed on purpose
+	webPorts = []int{80, 443}
+)
+
+// FindExposures returns the internet reachable endpoints, highest priority first.
+func FindExposures(resources []Resource) []Finding {
+	findings := []Finding{}
+
+	openGroups := map[string]Priority{}
+	for _, resource := range resources {
+		if resource.Service == "ec2" && resource.Type == "security-group" {
+			finding := securityGroupExposure(resource)
+			if finding != nil {
+				openGroups[resource.ID] = finding.Priority
+				findings = append(findings, *finding)
+			}
+		}
+	}
+
+	for _, resource := range resources {
+		var finding *Finding
+		switch fmt.Sprintf("%s:%s", resource.Service, resource.Type) {
+		case "ec2:instance":
+			finding = instanceExposure(resource, openGroups)
+		case "elasticloadbalancing:loadbalancer":
+			finding = loadBalancerExposure(resource)
+		case "apigateway:restapi":
+			finding = restAPIExposure(resource)
+		case "apigateway:api":
+			finding = apiExposure(resource)
+		case "s3:bucket":
+			finding = bucketExposure(resource)
+		case "cloudfront:distribution":
+			finding = distributionExposure(resource)
+		case "apprunner:service":
+			finding = appRunnerExposure(resource)
+		}
+		if finding != nil {
+			findings = append(findings, *finding)
+		}
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool {
+		a, b := findings[i], findings[j]
+		if a.Priority != b.Priority {
+			return a.Priority > b.Priority
+		}
+		if a.AccountID != b.AccountID {
+			return a.AccountID < b.AccountID
+		}
+		if a.Region != b.Region {
+			return a.Region < b.Region
+		}
+		return a.ResourceID < b.ResourceID
+	})
+
+	return findings
+}
+
+func newFinding(resource Resource, priority Priority, kind, endpoint, reason string) *Finding {
+	return &Finding{
+		Priority:   priority,
+		Kind:       kind,
+		AccountID:  resource.AccountID,
+		Region:     resource.Region,
+		ResourceID: resource.ID,
+		ARN:        resource.ARN,
+		Endpoint:   endpoint,
+		Reason:     reason,
+	}
+}
+
+func securityGroupExposure(resource Resource) *Finding {
+	var worst *Priority
+	ports := []string{}
+
+	for _, permissionI := range getSlice(resource.Metadata, "IpPermissions") {
+		permission, ok := permissionI.(map[string]interface{})
+		if !ok || !isOpenToInternet(permission) {
+			continue
+		}
+
+		protocol := getString(permission, "IpProtocol")
+		fromPort, toPort := getInt(permission, "FromPort"), getInt(permission, "ToPort")
+
+		priority := PriorityMedium
+		switch {
+		case protocol == "-1":
+			priority = PriorityHigh
+			ports = append(ports, "all")
+		case rangeContainsAny(fromPort, toPort, sensitivePorts):
+			priority = PriorityHigh
+			ports = append(ports, portRange(protocol, fromPort, toPort))
+		case rangeOnlyContains(fromPort, toPort, webPorts):
+			priority = PriorityLow
+			ports = append(ports, portRange(protocol, fromPort, toPort))
+		default:
+			ports = append(ports, portRange(protocol, fromPort, toPort))
+		}
+
+		if worst == nil || priority > *worst {
+			worst = &priority
+		}
+	}
+
+	if worst == nil {
+		return nil
+	}
+	return newFinding(resource, *worst, "open-security-group", "", fmt.Sprintf("open to the internet on %s", strings.Join(ports, ", ")))
+}
+
+func isOpenToInternet(permission map[string]interface{}) bool {
+	for _, rangeI := range getSlice(permission, "IpRanges") {
+		if r, ok := rangeI.(map[string]interface{}); ok && getString(r, "CidrIp") == "0.0.0.0/0" {
+			return true
+		}
+	}
+	for _, rangeI := range getSlice(permission, "Ipv6Ranges") {
+		if r, ok := rangeI.(map[string]interface{}); ok && getString(r, "CidrIpv6") == "::/0" {
+			return true
+		}
+	}
+	return false
+}
+
+func instanceExposure(resource Resource, openGroups map[string]Priority) *Finding {
+	publicIP := getString(resource.Metadata, "PublicIpAddress")
+	if publicIP == "" {
+		return nil
+	}
+
+	priority := PriorityMedium
+	reason := "instance has a public IP"
+	for _, groupI := range getSlice(resource.Metadata, "SecurityGroups") {
+		group, ok := groupI.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		groupID := getString(group, "GroupId")
+		if groupPriority, open := openGroups[groupID]; open && groupPriority == PriorityHigh {
+			priority = PriorityHigh
+			reason = fmt.Sprintf("instance has a public IP and security group %s is open to the internet", groupID)
+		}
+	}
+
+	return newFinding(resource, priority, "ec2-public-ip", publicIP, reason)
+}
+
+func loadBalancerExposure(resource Resource) *Finding {
+	if getString(resource.Metadata, "Scheme") != "internet-facing" {
+		return nil
+	}
+
+	listeners := []string{}
+	for _, listenerI := range getSlice(resource.Metadata, "Listeners") {
+		if listener, ok := listenerI.(map[string]interface{}); ok {
+			listeners = append(listeners, fmt.Sprintf("%s:%d", getString(listener, "Protocol"), getInt(listener, "Port")))
+		}
+	}
+
+	return newFinding(resource, PriorityMedium, fmt.Sprintf("public-%s-load-balancer", getString(resource.Metadata, "Type")),
+		getString(resource.Metadata, "DNSName"), fmt.Sprintf("internet-facing with listeners %s", strings.Join(listeners, ", ")))
+}
+
+func restAPIExposure(resource Resource) *Finding {
+	if getBool(resource.Metadata, "DisableExecuteApiEndpoint") {
+		return nil
+	}
+
+	endpointConfiguration, _ := resource.Metadata["EndpointConfiguration"].(map[string]interface{})
+	for _, typeI := range getSlice(endpointConfiguration, "Types") {
+		if typeI == "PRIVATE" {
+			return nil
+		}
+	}
+
+	endpoint := fmt.Sprintf("https://%s.execute-api.%s.amazonaws.com", resource.ID, resource.Region)
+	return newFinding(resource, PriorityMedium, "api-gateway", endpoint, "REST API with a public execute-api endpoint")
+}
+
+func apiExposure(resource Resource) *Finding {
+	if getBool(resource.Metadata, "DisableExecuteApiEndpoint") {
+		return nil
+	}
+
+	return newFinding(resource, PriorityMedium, "api-gateway", getString(resource.Metadata, "ApiEndpoint"),
+		fmt.Sprintf("%s API with a public endpoint", getString(resource.Metadata, "ProtocolType")))
+}
+
+func bucketExposure(resource Resource) *Finding {
+	policyStatus, _ := resource.Metadata["PolicyStatus"].(map[string]interface{})
+	if !getBool(policyStatus, "IsPublic") {
+		return nil
+	}
+
+	endpoint := fmt.Sprintf("https://%s.s3.amazonaws.com", resource.ID)
+	return newFinding(resource, PriorityHigh, "public-bucket", endpoint, "bucket policy allows public access")
+}
+
+func distributionExposure(resource Resource) *Finding {
+	if !getBool(resource.Metadata, "Enabled") {
+		return nil
+	}
+
+	priority := PriorityLow
+	reason := "CloudFront distribution"
+	if getString(resource.Metadata, "WebACLId") == "" {
+		priority = PriorityMedium
+		reason = "CloudFront distribution without a web ACL"
+	}
+
+	return newFinding(resource, priority, "cloudfront", getString(resource.Metadata, "DomainName"), reason)
+}
+
+func appRunnerExposure(resource Resource) *Finding {
+	networkConfiguration, _ := resource.Metadata["NetworkConfiguration"].(map[string]interface{})
+	ingressConfiguration, _ := networkConfiguration["IngressConfiguration"].(map[string]interface{})
+	// services are public unless configured otherwise
+	if public, ok := ingressConfiguration["IsPubliclyAccessible"].(bool); ok && !public {
+		return nil
+	}
+
+	endpoint := fmt.Sprintf("https://%s", getString(resource.Metadata, "ServiceUrl"))
+	return newFinding(resource, PriorityMedium, "app-runner", endpoint, "App Runner service with a public endpoint")
+}
+
+func portRange(protocol string, from, to int) string {
+	if from == to {
+		return fmt.Sprintf("%s/%d", protocol, from)
+	}
+	return fmt.Sprintf("%s/%d-%d", protocol, from, to)
+}
+
+func rangeContainsAny(from, to int, ports []int) bool {
+	for _, port := range ports {
+		if port >= from && port <= to {
+			return true
+		}
+	}
+	return false
+}
+
+func rangeOnlyContains(from, to int, ports []int) bool {
+	for port := from; port <= to; port++ {
+		if !rangeContainsAny(port, port, ports) {
+			return false
+		}
+	}
+	return true
+}
+
+func getString(m map[string]interface{}, key string) string {
+	value, _ := m[key].(string)
+	return value
+}
+
+func getBool(m map[string]interface{}, key string) bool {
+	value, _ := m[key].(bool)
+	return value
+}
+
+func getInt(m map[string]interface{}, key string) int {
+	value, _ := m[key].(float64)
+	return int(value)
+}
+
+func getSlice(m map[string]interface{}, key string) []interface{} {
+	value, _ := m[key].([]interface{})
+	return value
+}
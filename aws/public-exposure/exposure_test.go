@@ -0,0 +1,123 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const dump = `[
+  {
+    "id": "sg-web",
+    "service": "ec2",
+    "type": "security-group",
+    "account_id": "123456789012",
+    "region": "eu-west-1",
+    "metadata": {
+      "IpPermissions": [
+        {"IpProtocol": "tcp", "FromPort": 443, "ToPort": 443, "IpRanges": [{"CidrIp": "0.0.0.0/0"}]}
+      ]
+    }
+  },
+  {
+    "id": "sg-ssh",
+    "service": "ec2",
+    "type": "security-group",
+    "account_id": "123456789012",
+    "region": "eu-west-1",
+    "metadata": {
+      "IpPermissions": [
+        {"IpProtocol": "tcp", "FromPort": 443, "ToPort": 443, "IpRanges": [{"CidrIp": "0.0.0.0/0"}]},
+        {"IpProtocol": "tcp", "FromPort": 22, "ToPort": 22, "Ipv6Ranges": [{"CidrIpv6": "::/0"}]}
+      ]
+    }
+  },
+  {
+    "id": "sg-private",
+    "service": "ec2",
+    "type": "security-group",
+    "account_id": "123456789012",
+    "region": "eu-west-1",
+    "metadata": {
+      "IpPermissions": [
+        {"IpProtocol": "-1", "IpRanges": [{"CidrIp": "10.0.0.0/8"}]}
+      ]
+    }
+  },
+  {
+    "id": "i-public",
+    "service": "ec2",
+    "type": "instance",
+    "account_id": "123456789012",
+    "region": "eu-west-1",
+    "metadata": {
+      "PublicIpAddress": "203.0.113.10",
+      "SecurityGroups": [{"GroupId": "sg-ssh"}]
+    }
+  },
+  {
+    "id": "i-private",
+    "service": "ec2",
+    "type": "instance",
+    "account_id": "123456789012",
+    "region": "eu-west-1",
+    "metadata": {
+      "PublicIpAddress": null,
+      "SecurityGroups": [{"GroupId": "sg-private"}]
+    }
+  },
+  {
+    "id": "public-bucket",
+    "service": "s3",
+    "type": "bucket",
+    "account_id": "123456789012",
+    "metadata": {"PolicyStatus": {"IsPublic": true}}
+  },
+  {
+    "id": "private-bucket",
+    "service": "s3",
+    "type": "bucket",
+    "account_id": "123456789012",
+    "metadata": {"PolicyStatus": null}
+  },
+  {
+    "id": "abcdef1234",
+    "service": "apigateway",
+    "type": "restapi",
+    "account_id": "123456789012",
+    "region": "eu-west-1",
+    "metadata": {"EndpointConfiguration": {"Types": ["PRIVATE"]}}
+  },
+  {
+    "id": "E123",
+    "service": "cloudfront",
+    "type": "distribution",
+    "account_id": "123456789012",
+    "metadata": {"Enabled": true, "DomainName": "d111.cloudfront.net", "WebACLId": "arn:aws:wafv2:acl"}
+  }
+]`
+
+func TestFindExposures(t *testing.T) {
+	resources := []Resource{}
+	require.NoError(t, json.Unmarshal([]byte(dump), &resources))
+
+	findings := FindExposures(resources)
+
+	summary := []string{}
+	for _, finding := range findings {
+		summary = append(summary, finding.Priority.String()+" "+finding.Kind+" "+finding.ResourceID)
+	}
+
+	assert.Equal(t, []string{
+		"high public-bucket public-bucket",
+		"high ec2-public-ip i-public",
+		"high open-security-group sg-ssh",
+		"low cloudfront E123",
+		"low open-security-group sg-web",
+	}, summary)
+
+	assert.Equal(t, "203.0.113.10", findings[1].Endpoint)
+	assert.Equal(t, "open to the internet on tcp/443, tcp/22", findings[2].Reason)
+}
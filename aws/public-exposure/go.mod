@@ -0,0 +1,9 @@
+module github.com/hamstah/awstools/aws/public-exposure
+
+go 1.15
+
+require (
+	github.com/hamstah/awstools/common v0.0.0-20210118215825-d772cda2a155
+	github.com/stretchr/testify v1.6.1
+	gopkg.in/alecthomas/kingpin.v2 v2.2.6
+)
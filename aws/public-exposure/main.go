@@ -0,0 +1,54 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"text/tabwriter"
+
+	"github.com/hamstah/awstools/common"
+	kingpin "gopkg.in/alecthomas/kingpin.v2"
+)
+
+var (
+	input  = kingpin.Flag("input", "aws-dump output file to analyse.").Short('i').Required().String()
+	format = kingpin.Flag("format", "Output format.").Default("text").Enum("text", "json")
+)
+
+func main() {
+	kingpin.CommandLine.Name = "aws-public-exposure"
+	kingpin.CommandLine.Help = "List the internet reachable endpoints found in an aws-dump output."
+	common.HandleFlags()
+
+	data, err := ioutil.ReadFile(*input)
+	common.FatalOnErrorW(err, "failed to read the dump")
+
+	resources := []Resource{}
+	err = json.Unmarshal(data, &resources)
+	common.FatalOnErrorW(err, "failed to parse the dump")
+
+	findings := FindExposures(resources)
+
+	if *format == "json" {
+		output, err := json.MarshalIndent(findings, "", "  ")
+		common.FatalOnErrorW(err, "failed to serialise the findings")
+		fmt.Println(string(output))
+		return
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(writer, "PRIORITY\tKIND\tACCOUNT\tREGION\tRESOURCE\tENDPOINT\tREASON")
+	for _, finding := range findings {
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			finding.Priority,
+			finding.Kind,
+			finding.AccountID,
+			finding.Region,
+			finding.ResourceID,
+			finding.Endpoint,
+			finding.Reason,
+		)
+	}
+	writer.Flush()
+}
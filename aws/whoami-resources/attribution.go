@@ -0,0 +1,141 @@
+package main
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// Resource is the subset of the aws-dump output used to find the owned resources.
+type Resource struct {
+	ID        string                 `json:"id"`
+	ARN       string                 `json:"arn"`
+	Service   string                 `json:"service"`
+	Type      string                 `json:"type"`
+	AccountID string                 `json:"account_id"`
+	Region    string                 `json:"region"`
+	Metadata  map[string]interface{} `json:"metadata"`
+}
+
+// Attribution is a resource attributed to the owner and the reason why.
+type Attribution struct {
+	ID           string     `json:"id"`
+	ARN          string     `json:"arn,omitempty"`
+	Service      string     `json:"service"`
+	Type         string     `json:"type"`
+	AccountID    string     `json:"account_id"`
+	Region       string     `json:"region"`
+	AttributedBy string     `json:"attributed_by"`
+	EventTime    *time.Time `json:"event_time,omitempty"`
+}
+
+// creation events start with one of these prefixes
+var creationEventPrefixes = []string{"Create", "Run", "Allocate", "Register", "Import", "Copy", "Launch", "Request"}
+
+// IsCreationEvent returns true if the CloudTrail event name creates a resource.
+func IsCreationEvent(eventName string) bool {
+	for _, prefix := range creationEventPrefixes {
+		if strings.HasPrefix(eventName, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Tags returns the tags of a resource from the aws-dump metadata, either a list
+// of Key/Value pairs (e.g. EC2) or a map.
+func Tags(metadata map[string]interface{}) map[string]string {
+	tags := map[string]string{}
+	for _, key := range []string{"Tags", "TagList", "TagSet"} {
+		switch value := metadata[key].(type) {
+		case []interface{}:
+			for _, item := range value {
+				tag, ok := item.(map[string]interface{})
+				if !ok {
+					continue
+				}
+				tagKey, _ := tag["Key"].(string)
+				tagValue, _ := tag["Value"].(string)
+				if tagKey != "" {
+					tags[tagKey] = tagValue
+				}
+			}
+		case map[string]interface{}:
+			for tagKey, tagValue := range value {
+				if tagValue, ok := tagValue.(string); ok {
+					tags[tagKey] = tagValue
+				}
+			}
+		}
+	}
+	return tags
+}
+
+// MatchOwnerTag returns the value of the first tag whose key is one of tagKeys
+// (case insensitive) and whose value is owner (case insensitive).
+func MatchOwnerTag(tags map[string]string, owner string, tagKeys []string) (string, bool) {
+	for key, value := range tags {
+		for _, tagKey := range tagKeys {
+			if strings.EqualFold(key, tagKey) && strings.EqualFold(value, owner) {
+				return key, true
+			}
+		}
+	}
+	return "", false
+}
+
+// AttributeDumpResources returns the resources of an aws-dump output tagged with the owner.
+func AttributeDumpResources(resources []Resource, owner string, tagKeys []string) []Attribution {
+	attributions := []Attribution{}
+	for _, resource := range resources {
+		if resource.Metadata == nil {
+			continue
+		}
+
+		key, ok := MatchOwnerTag(Tags(resource.Metadata), owner, tagKeys)
+		if !ok {
+			continue
+		}
+
+		attributions = append(attributions, Attribution{
+			ID:           resource.ID,
+			ARN:          resource.ARN,
+			Service:      resource.Service,
+			Type:         resource.Type,
+			AccountID:    resource.AccountID,
+			Region:       resource.Region,
+			AttributedBy: "tag:" + key,
+		})
+	}
+	return attributions
+}
+
+// SortAttributions sorts by account, region, service, type and id.
+func SortAttributions(attributions []Attribution) {
+	sort.SliceStable(attributions, func(i, j int) bool {
+		a, b := attributions[i], attributions[j]
+		if a.AccountID != b.AccountID {
+			return a.AccountID < b.AccountID
+		}
+		if a.Region != b.Region {
+			return a.Region < b.Region
+		}
+		if a.Service != b.Service {
+			return a.Service < b.Service
+		}
+		if a.Type != b.Type {
+			return a.Type < b.Type
+		}
+		return a.ID < b.ID
+	})
+}
+
+// PrincipalUsername returns the CloudTrail user name of an IAM principal, the
+// last part of its ARN (user name or role session name).
+func PrincipalUsername(principal string) string {
+	if !strings.HasPrefix(principal, "arn:") {
+		return principal
+	}
+	parts := strings.Split(principal, "/")
+	return parts[len(parts)-1]
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTags(t *testing.T) {
+	assert.Equal(t, map[string]string{"Owner": "alice", "Name": "web"}, Tags(map[string]interface{}{
+		"Tags": []interface{}{
+			map[string]interface{}{"Key": "Owner", "Value": "alice"},
+			map[string]interface{}{"Key": "Name", "Value": "web"},
+		},
+	}))
+	assert.Equal(t, map[string]string{"owner": "bob"}, Tags(map[string]interface{}{
+		"Tags": map[string]interface{}{"owner": "bob"},
+	}))
+	assert.Equal(t, map[string]string{}, Tags(map[string]interface{}{}))
+}
+
+func TestAttributeDumpResources(t *testing.T) {
+	resources := []Resource{
+		{ID: "i-1", Service: "ec2", Type: "instance", Metadata: map[string]interface{}{
+			"Tags": []interface{}{map[string]interface{}{"Key": "Owner", "Value": "Alice"}},
+		}},
+		{ID: "i-2", Service: "ec2", Type: "instance", Metadata: map[string]interface{}{
+			"Tags": []interface{}{map[string]interface{}{"Key": "Owner", "Value": "bob"}},
+		}},
+		{ID: "bucket", Service: "s3", Type: "bucket", Metadata: map[string]interface{}{
+			"TagSet": []interface{}{map[string]interface{}{"Key": "team-owner", "Value": "alice"}},
+		}},
+		{ID: "bucket-2", Service: "s3", Type: "bucket"},
+	}
+
+	attributions := AttributeDumpResources(resources, "alice", []string{"owner", "team-owner"})
+	assert.Len(t, attributions, 2)
+	assert.Equal(t, "i-1", attributions[0].ID)
+	assert.Equal(t, "tag:Owner", attributions[0].AttributedBy)
+	assert.Equal(t, "bucket", attributions[1].ID)
+	assert.Equal(t, "tag:team-owner", attributions[1].AttributedBy)
+}
+
+func TestIsCreationEvent(t *testing.T) {
+	assert.True(t, IsCreationEvent("RunInstances"))
+	assert.True(t, IsCreationEvent("CreateBucket"))
+	assert.False(t, IsCreationEvent("DescribeInstances"))
+	assert.False(t, IsCreationEvent("DeleteBucket"))
+}
+
+func TestPrincipalUsername(t *testing.T) {
+	assert.Equal(t, "alice", PrincipalUsername("alice"))
+	assert.Equal(t, "alice", PrincipalUsername("arn:aws:iam::123456789012:user/alice"))
+	assert.Equal(t, "alice@example.com", PrincipalUsername("arn:aws:sts::123456789012:assumed-role/Admin/alice@example.com"))
+}
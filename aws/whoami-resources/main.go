@@ -0,0 +1,188 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/arn"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/cloudtrail"
+	"github.com/aws/aws-sdk-go/service/resourcegroupstaggingapi"
+	"github.com/aws/aws-sdk-go/service/sts"
+	"github.com/hamstah/awstools/common"
+	log "github.com/sirupsen/logrus"
+	kingpin "gopkg.in/alecthomas/kingpin.v2"
+)
+
+var (
+	owner     = kingpin.Flag("owner", "Value of the owner tag.").String()
+	tagKeys   = kingpin.Flag("tag-key", "Key of the owner tag. Can be repeated.").Default("owner").Strings()
+	principal = kingpin.Flag("principal", "IAM user name or ARN (user or assumed role) to find the resources created by in CloudTrail.").String()
+	days      = kingpin.Flag("days", "Number of days of CloudTrail events to look at, up to 90.").Default("90").Int()
+	inputs    = kingpin.Flag("input", "aws-dump output file to find the tagged resources in instead of the API. Can be repeated.").Short('i').Strings()
+	format    = kingpin.Flag("format", "Output format.").Default("text").Enum("text", "json")
+)
+
+func main() {
+	kingpin.CommandLine.Name = "aws-whoami-resources"
+	kingpin.CommandLine.Help = "List the resources owned by a tag owner or created by an IAM principal."
+	flags := common.HandleFlags()
+
+	if *owner == "" && *principal == "" {
+		common.Fatalln("--owner or --principal is required")
+	}
+
+	attributions := []Attribution{}
+
+	if *owner != "" && len(*inputs) > 0 {
+		for _, filename := range *inputs {
+			data, err := ioutil.ReadFile(filename)
+			common.FatalOnErrorW(err, "failed to read the dump")
+
+			resources := []Resource{}
+			err = json.Unmarshal(data, &resources)
+			common.FatalOnErrorW(err, "failed to parse the dump")
+
+			attributions = append(attributions, AttributeDumpResources(resources, *owner, *tagKeys)...)
+		}
+	}
+
+	if *principal != "" || len(*inputs) == 0 {
+		sess, conf := common.OpenSession(flags)
+
+		if *owner != "" && len(*inputs) == 0 {
+			tagged, err := listTaggedResources(sess, conf)
+			common.FatalOnErrorW(err, "failed to list the tagged resources")
+			attributions = append(attributions, tagged...)
+		}
+
+		if *principal != "" {
+			created, err := listCreatedResources(sess, conf)
+			common.FatalOnErrorW(err, "failed to lookup the CloudTrail events")
+			attributions = append(attributions, created...)
+		}
+	}
+
+	SortAttributions(attributions)
+
+	if *format == "json" {
+		output, err := json.MarshalIndent(attributions, "", "  ")
+		common.FatalOnErrorW(err, "failed to serialise the resources")
+		fmt.Println(string(output))
+		return
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(writer, "ACCOUNT\tREGION\tSERVICE\tTYPE\tID\tATTRIBUTED BY")
+	for _, attribution := range attributions {
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			attribution.AccountID,
+			attribution.Region,
+			attribution.Service,
+			attribution.Type,
+			attribution.ID,
+			attribution.AttributedBy,
+		)
+	}
+	writer.Flush()
+}
+
+// listTaggedResources uses the tagging API, tag keys and values are case sensitive.
+func listTaggedResources(sess *session.Session, conf *aws.Config) ([]Attribution, error) {
+	client := resourcegroupstaggingapi.New(sess, conf)
+
+	attributions := []Attribution{}
+	for _, tagKey := range *tagKeys {
+		err := client.GetResourcesPages(&resourcegroupstaggingapi.GetResourcesInput{
+			TagFilters: []*resourcegroupstaggingapi.TagFilter{
+				{
+					Key:    aws.String(tagKey),
+					Values: aws.StringSlice([]string{*owner}),
+				},
+			},
+		},
+			func(page *resourcegroupstaggingapi.GetResourcesOutput, lastPage bool) bool {
+				for _, mapping := range page.ResourceTagMappingList {
+					attribution := Attribution{
+						ID:           *mapping.ResourceARN,
+						ARN:          *mapping.ResourceARN,
+						AttributedBy: "tag:" + tagKey,
+					}
+					parsed, err := arn.Parse(*mapping.ResourceARN)
+					if err == nil {
+						attribution.Service = parsed.Service
+						attribution.AccountID = parsed.AccountID
+						attribution.Region = parsed.Region
+						attribution.ID = parsed.Resource
+						if parts := strings.SplitN(parsed.Resource, "/", 2); len(parts) == 2 {
+							attribution.Type = parts[0]
+							attribution.ID = parts[1]
+						}
+					}
+					attributions = append(attributions, attribution)
+				}
+				return true
+			})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return attributions, nil
+}
+
+// listCreatedResources looks up the creation events of the principal in the
+// CloudTrail event history of the region.
+func listCreatedResources(sess *session.Session, conf *aws.Config) ([]Attribution, error) {
+	identity, err := sts.New(sess, conf).GetCallerIdentity(&sts.GetCallerIdentityInput{})
+	if err != nil {
+		return nil, err
+	}
+
+	client := cloudtrail.New(sess, conf)
+
+	attributions := []Attribution{}
+	err = client.LookupEventsPages(&cloudtrail.LookupEventsInput{
+		StartTime: aws.Time(time.Now().UTC().AddDate(0, 0, -*days)),
+		LookupAttributes: []*cloudtrail.LookupAttribute{
+			{
+				AttributeKey:   aws.String(cloudtrail.LookupAttributeKeyUsername),
+				AttributeValue: aws.String(PrincipalUsername(*principal)),
+			},
+		},
+	},
+		func(page *cloudtrail.LookupEventsOutput, lastPage bool) bool {
+			for _, event := range page.Events {
+				eventName := aws.StringValue(event.EventName)
+				if !IsCreationEvent(eventName) {
+					continue
+				}
+				if len(event.Resources) == 0 {
+					log.WithField("event_id", aws.StringValue(event.EventId)).WithField("event_name", eventName).Debug("Creation event without resources")
+					continue
+				}
+
+				for _, resource := range event.Resources {
+					attributions = append(attributions, Attribution{
+						ID:           aws.StringValue(resource.ResourceName),
+						Service:      strings.TrimSuffix(aws.StringValue(event.EventSource), ".amazonaws.com"),
+						Type:         aws.StringValue(resource.ResourceType),
+						AccountID:    *identity.Account,
+						Region:       aws.StringValue(conf.Region),
+						AttributedBy: "cloudtrail:" + eventName,
+						EventTime:    event.EventTime,
+					})
+				}
+			}
+			return true
+		})
+	if err != nil {
+		return nil, err
+	}
+	return attributions, nil
+}
@@ -0,0 +1,265 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/athena"
+	"github.com/aws/aws-sdk-go/service/cloudtrail"
+	"github.com/aws/aws-sdk-go/service/organizations"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+	"github.com/hamstah/awstools/common"
+	kingpin "gopkg.in/alecthomas/kingpin.v2"
+)
+
+var (
+	trailName      = kingpin.Flag("trail", "Name or ARN of the trail to read the bucket, prefix and organization from.").String()
+	bucket         = kingpin.Flag("bucket", "Bucket of the CloudTrail logs, overrides the bucket of --trail.").String()
+	prefix         = kingpin.Flag("prefix", "Prefix of the CloudTrail logs in the bucket, overrides the prefix of --trail.").String()
+	organizationID = kingpin.Flag("organization-id", "Organization of an organization trail, overrides the organization of --trail.").String()
+	accounts       = kingpin.Flag("account", "Account to query the logs of. Can be repeated, defaults to the accounts in the bucket.").Strings()
+	logRegions     = kingpin.Flag("log-region", "Region to query the logs of. Can be repeated, defaults to the regions in the bucket.").Strings()
+	startDate      = kingpin.Flag("start-date", "First day of logs to query, yyyy/mm/dd. Defaults to a year ago.").String()
+	database       = kingpin.Flag("database", "Athena database of the table.").Default("cloudtrail").String()
+	table          = kingpin.Flag("table", "Name of the Athena table.").Default("cloudtrail_logs").String()
+	workgroup      = kingpin.Flag("workgroup", "Athena workgroup to run the queries in.").Default("primary").String()
+	outputLocation = kingpin.Flag("output-location", "S3 location of the query results, e.g. s3://bucket/athena/. Required unless the workgroup has one.").String()
+	dryRun         = kingpin.Flag("dry-run", "Only print the queries creating the table.").Default("false").Bool()
+	listQueries    = kingpin.Flag("list-queries", "List the canned queries and exit.").Default("false").Bool()
+	printQuery     = kingpin.Flag("print-query", "Print the SQL of this canned query for the table and exit.").String()
+	runQuery       = kingpin.Flag("query", "Run this canned query on the table and print the results.").String()
+	days           = kingpin.Flag("days", "Number of days of logs the canned queries look at.").Default("7").Int()
+	threshold      = kingpin.Flag("threshold", "Minimum number of errors in an hour reported by access-denied-spikes.").Default("50").Int()
+	format         = kingpin.Flag("format", "Output format of the query results.").Default("text").Enum("text", "json")
+)
+
+func main() {
+	kingpin.CommandLine.Name = "cloudtrail-athena-bootstrap"
+	kingpin.CommandLine.Help = "Create the Athena table of the CloudTrail logs and run canned queries on it."
+	flags := common.HandleFlags()
+
+	logsTable := &Table{Database: *database, Name: *table}
+	parameters := QueryParameters{Days: *days, Threshold: *threshold}
+
+	if *listQueries {
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "NAME\tDESCRIPTION")
+		for _, query := range Queries {
+			fmt.Fprintf(w, "%s\t%s\n", query.Name, query.RenderDescription(logsTable, parameters))
+		}
+		w.Flush()
+		return
+	}
+
+	if *printQuery != "" {
+		query, err := FindQuery(*printQuery)
+		common.FatalOnError(err)
+		fmt.Println(query.Render(logsTable, parameters))
+		return
+	}
+
+	sess, conf := common.OpenSession(flags)
+	client := athena.New(sess, conf)
+
+	if *runQuery != "" {
+		query, err := FindQuery(*runQuery)
+		common.FatalOnError(err)
+
+		executionID, err := execute(client, query.Render(logsTable, parameters))
+		common.FatalOnErrorW(err, "query failed")
+		printResults(client, executionID)
+		return
+	}
+
+	bootstrap(sess, conf, client, logsTable)
+}
+
+func bootstrap(sess *session.Session, conf *aws.Config, client *athena.Athena, logsTable *Table) {
+	if *trailName != "" {
+		res, err := cloudtrail.New(sess, conf).DescribeTrails(&cloudtrail.DescribeTrailsInput{
+			TrailNameList:       []*string{trailName},
+			IncludeShadowTrails: aws.Bool(true),
+		})
+		common.FatalOnErrorW(err, "failed to describe the trail")
+		if len(res.TrailList) == 0 {
+			common.Fatalln(fmt.Sprintf("trail %s not found", *trailName))
+		}
+		trail := res.TrailList[0]
+
+		logsTable.Bucket = aws.StringValue(trail.S3BucketName)
+		logsTable.Prefix = aws.StringValue(trail.S3KeyPrefix)
+		if aws.BoolValue(trail.IsOrganizationTrail) {
+			organization, err := organizations.New(sess, conf).DescribeOrganization(&organizations.DescribeOrganizationInput{})
+			common.FatalOnErrorW(err, "failed to describe the organization of the trail")
+			logsTable.OrganizationID = aws.StringValue(organization.Organization.Id)
+		}
+	}
+	if *bucket != "" {
+		logsTable.Bucket = *bucket
+	}
+	if *prefix != "" {
+		logsTable.Prefix = *prefix
+	}
+	if *organizationID != "" {
+		logsTable.OrganizationID = *organizationID
+	}
+	if logsTable.Bucket == "" {
+		common.Fatalln("--trail or --bucket is required")
+	}
+
+	logsTable.StartDate = *startDate
+	if logsTable.StartDate == "" {
+		logsTable.StartDate = time.Now().UTC().AddDate(-1, 0, 0).Format("2006/01/02")
+	}
+	if _, err := time.Parse("2006/01/02", logsTable.StartDate); err != nil {
+		common.Fatalln(fmt.Sprintf("invalid --start-date %s, should be yyyy/mm/dd", logsTable.StartDate))
+	}
+
+	logsTable.Accounts = *accounts
+	logsTable.Regions = *logRegions
+	if len(logsTable.Accounts) == 0 || len(logsTable.Regions) == 0 {
+		discover(sess, conf, logsTable)
+	}
+	if len(logsTable.Accounts) == 0 || len(logsTable.Regions) == 0 {
+		common.Fatalln(fmt.Sprintf("no CloudTrail logs found in %s", logsTable.Location()))
+	}
+
+	queries := []string{logsTable.CreateDatabaseQuery(), logsTable.CreateQuery(), logsTable.UpdateQuery()}
+	if *dryRun {
+		fmt.Println(strings.Join(queries, ";\n\n") + ";")
+		return
+	}
+
+	for _, query := range queries {
+		_, err := execute(client, query)
+		common.FatalOnErrorW(err, "failed to create the table")
+	}
+	fmt.Printf("table %s.%s is ready with %d accounts and %d regions from %s\n", logsTable.Database, logsTable.Name, len(logsTable.Accounts), len(logsTable.Regions), logsTable.StartDate)
+}
+
+// discover lists the accounts and regions with logs in the bucket.
+func discover(sess *session.Session, conf *aws.Config, logsTable *Table) {
+	region, err := s3manager.GetBucketRegion(aws.BackgroundContext(), sess, logsTable.Bucket, aws.StringValue(conf.Region))
+	common.FatalOnErrorW(err, "failed to get the region of the bucket")
+	client := s3.New(sess, conf.Copy(&aws.Config{Region: aws.String(region)}))
+
+	location := strings.TrimPrefix(logsTable.Location(), "s3://"+logsTable.Bucket+"/")
+	accountPrefixes, err := listPrefixes(client, logsTable.Bucket, location)
+	common.FatalOnErrorW(err, "failed to list the accounts of the logs")
+	found := ParsePrefixes(accountPrefixes, accountIDPattern)
+	if len(logsTable.Accounts) == 0 {
+		logsTable.Accounts = found
+	}
+
+	if len(logsTable.Regions) > 0 {
+		return
+	}
+	regionPrefixes := []string{}
+	for _, account := range logsTable.Accounts {
+		prefixes, err := listPrefixes(client, logsTable.Bucket, location+account+"/CloudTrail/")
+		common.FatalOnErrorW(err, "failed to list the regions of the logs")
+		regionPrefixes = append(regionPrefixes, prefixes...)
+	}
+	logsTable.Regions = ParsePrefixes(regionPrefixes, regionPattern)
+}
+
+func listPrefixes(client *s3.S3, bucket, prefix string) ([]string, error) {
+	prefixes := []string{}
+	err := client.ListObjectsV2Pages(&s3.ListObjectsV2Input{
+		Bucket:    aws.String(bucket),
+		Prefix:    aws.String(prefix),
+		Delimiter: aws.String("/"),
+	}, func(page *s3.ListObjectsV2Output, lastPage bool) bool {
+		for _, commonPrefix := range page.CommonPrefixes {
+			prefixes = append(prefixes, aws.StringValue(commonPrefix.Prefix))
+		}
+		return true
+	})
+	return prefixes, err
+}
+
+// execute runs the query and waits for it to complete. The table names are
+// qualified with the database so no database is set on the execution.
+func execute(client *athena.Athena, query string) (string, error) {
+	input := &athena.StartQueryExecutionInput{
+		QueryString: aws.String(query),
+		WorkGroup:   workgroup,
+	}
+	if *outputLocation != "" {
+		input.ResultConfiguration = &athena.ResultConfiguration{OutputLocation: outputLocation}
+	}
+
+	res, err := client.StartQueryExecution(input)
+	if err != nil {
+		return "", err
+	}
+
+	for {
+		execution, err := client.GetQueryExecution(&athena.GetQueryExecutionInput{QueryExecutionId: res.QueryExecutionId})
+		if err != nil {
+			return "", err
+		}
+
+		status := execution.QueryExecution.Status
+		switch aws.StringValue(status.State) {
+		case athena.QueryExecutionStateSucceeded:
+			return aws.StringValue(res.QueryExecutionId), nil
+		case athena.QueryExecutionStateFailed, athena.QueryExecutionStateCancelled:
+			return "", fmt.Errorf("query %s %s: %s", aws.StringValue(res.QueryExecutionId), strings.ToLower(aws.StringValue(status.State)), aws.StringValue(status.StateChangeReason))
+		}
+		time.Sleep(time.Second)
+	}
+}
+
+func printResults(client *athena.Athena, executionID string) {
+	columns := []string{}
+	rows := [][]string{}
+	err := client.GetQueryResultsPages(&athena.GetQueryResultsInput{QueryExecutionId: aws.String(executionID)},
+		func(page *athena.GetQueryResultsOutput, lastPage bool) bool {
+			for _, row := range page.ResultSet.Rows {
+				values := []string{}
+				for _, datum := range row.Data {
+					values = append(values, aws.StringValue(datum.VarCharValue))
+				}
+				// the first row has the names of the columns
+				if len(columns) == 0 {
+					columns = values
+					continue
+				}
+				rows = append(rows, values)
+			}
+			return true
+		})
+	common.FatalOnErrorW(err, "failed to get the query results")
+
+	if *format == "json" {
+		results := []map[string]string{}
+		for _, row := range rows {
+			result := map[string]string{}
+			for index, column := range columns {
+				if index < len(row) {
+					result[column] = row[index]
+				}
+			}
+			results = append(results, result)
+		}
+		data, err := json.MarshalIndent(results, "", "  ")
+		common.FatalOnErrorW(err, "failed to serialise the results")
+		fmt.Println(string(data))
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, strings.ToUpper(strings.Join(columns, "\t")))
+	for _, row := range rows {
+		fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+	w.Flush()
+}
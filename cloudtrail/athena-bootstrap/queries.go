@@ -0,0 +1,91 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Query is a canned query on the table, {table}, {days} and {threshold} are
+// replaced when it is rendered.
+type Query struct {
+	Name        string
+	Description string
+	SQL         string
+}
+
+type QueryParameters struct {
+	Days      int
+	Threshold int
+}
+
+// the partition filter keeps the queries to the days scanned
+const recentPartitions = "\"timestamp\" >= date_format(current_date - interval '{days}' day, '%Y/%m/%d')"
+
+var Queries = []*Query{
+	{
+		Name:        "console-logins-without-mfa",
+		Description: "Successful console logins of IAM users without MFA.",
+		SQL: `SELECT eventtime, recipientaccountid, useridentity.arn, sourceipaddress, useragent
+FROM {table}
+WHERE ` + recentPartitions + `
+  AND eventname = 'ConsoleLogin'
+  AND useridentity.type = 'IAMUser'
+  AND json_extract_scalar(responseelements, '$.ConsoleLogin') = 'Success'
+  AND json_extract_scalar(additionaleventdata, '$.MFAUsed') = 'No'
+ORDER BY eventtime DESC`,
+	},
+	{
+		Name:        "root-usage",
+		Description: "Calls made with the root user of the accounts.",
+		SQL: `SELECT eventtime, recipientaccountid, eventsource, eventname, sourceipaddress, useragent, errorcode
+FROM {table}
+WHERE ` + recentPartitions + `
+  AND useridentity.type = 'Root'
+  AND useridentity.invokedby IS NULL
+  AND eventtype <> 'AwsServiceEvent'
+ORDER BY eventtime DESC`,
+	},
+	{
+		Name:        "access-denied-spikes",
+		Description: "Principals with at least {threshold} access denied errors in an hour.",
+		SQL: `SELECT date_trunc('hour', from_iso8601_timestamp(eventtime)) AS hour,
+  recipientaccountid,
+  coalesce(useridentity.sessioncontext.sessionissuer.arn, useridentity.arn) AS principal,
+  count(*) AS errors,
+  array_join(slice(array_agg(DISTINCT eventsource || ':' || eventname), 1, 10), ', ') AS calls
+FROM {table}
+WHERE ` + recentPartitions + `
+  AND errorcode IN ('AccessDenied', 'AccessDeniedException', 'UnauthorizedOperation', 'Client.UnauthorizedOperation')
+GROUP BY 1, 2, 3
+HAVING count(*) >= {threshold}
+ORDER BY errors DESC`,
+	},
+}
+
+func FindQuery(name string) (*Query, error) {
+	names := []string{}
+	for _, query := range Queries {
+		if query.Name == name {
+			return query, nil
+		}
+		names = append(names, query.Name)
+	}
+	return nil, fmt.Errorf("unknown query %s, should be one of %s", name, strings.Join(names, ", "))
+}
+
+func (q *Query) replacer(table *Table, parameters QueryParameters) *strings.Replacer {
+	return strings.NewReplacer(
+		"{table}", table.QualifiedName(),
+		"{days}", strconv.Itoa(parameters.Days),
+		"{threshold}", strconv.Itoa(parameters.Threshold),
+	)
+}
+
+func (q *Query) Render(table *Table, parameters QueryParameters) string {
+	return q.replacer(table, parameters).Replace(q.SQL)
+}
+
+func (q *Query) RenderDescription(table *Table, parameters QueryParameters) string {
+	return q.replacer(table, parameters).Replace(q.Description)
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// the columns of the CloudTrail records, as documented in
+// https://docs.aws.amazon.com/athena/latest/ug/cloudtrail-logs.html
+const cloudTrailColumns = `  eventversion STRING,
+  useridentity STRUCT<
+    type: STRING,
+    principalid: STRING,
+    arn: STRING,
+    accountid: STRING,
+    invokedby: STRING,
+    accesskeyid: STRING,
+    username: STRING,
+    sessioncontext: STRUCT<
+      attributes: STRUCT<
+        mfaauthenticated: STRING,
+        creationdate: STRING>,
+      sessionissuer: STRUCT<
+        type: STRING,
+        principalid: STRING,
+        arn: STRING,
+        accountid: STRING,
+        username: STRING>>>,
+  eventtime STRING,
+  eventsource STRING,
+  eventname STRING,
+  awsregion STRING,
+  sourceipaddress STRING,
+  useragent STRING,
+  errorcode STRING,
+  errormessage STRING,
+  requestparameters STRING,
+  responseelements STRING,
+  additionaleventdata STRING,
+  requestid STRING,
+  eventid STRING,
+  readonly STRING,
+  resources ARRAY<STRUCT<
+    arn: STRING,
+    accountid: STRING,
+    type: STRING>>,
+  eventtype STRING,
+  apiversion STRING,
+  recipientaccountid STRING,
+  serviceeventdetails STRING,
+  sharedeventid STRING,
+  vpcendpointid STRING`
+
+var (
+	accountIDPattern = regexp.MustCompile(`^[0-9]{12}$`)
+	regionPattern    = regexp.MustCompile(`^[a-z]{2}(-[a-z]+)+-[0-9]+$`)
+)
+
+// Table is the Athena table of the logs of a trail. The partitions are
+// projected from the accounts, regions and dates instead of being added, so
+// new days don't need any maintenance.
+type Table struct {
+	Database       string
+	Name           string
+	Bucket         string
+	Prefix         string
+	OrganizationID string
+	Accounts       []string
+	Regions        []string
+	StartDate      string
+}
+
+// Location returns the S3 location of the logs of the accounts, with the
+// organization id for organization trails.
+func (t *Table) Location() string {
+	parts := []string{t.Bucket}
+	if t.Prefix != "" {
+		parts = append(parts, t.Prefix)
+	}
+	parts = append(parts, "AWSLogs")
+	if t.OrganizationID != "" {
+		parts = append(parts, t.OrganizationID)
+	}
+	return "s3://" + path.Join(parts...) + "/"
+}
+
+func (t *Table) QualifiedName() string {
+	return fmt.Sprintf("`%s`.`%s`", t.Database, t.Name)
+}
+
+func (t *Table) properties() [][2]string {
+	return [][2]string{
+		{"projection.enabled", "true"},
+		{"projection.account.type", "enum"},
+		{"projection.account.values", strings.Join(t.Accounts, ",")},
+		{"projection.region.type", "enum"},
+		{"projection.region.values", strings.Join(t.Regions, ",")},
+		{"projection.timestamp.type", "date"},
+		{"projection.timestamp.format", "yyyy/MM/dd"},
+		{"projection.timestamp.range", t.StartDate + ",NOW"},
+		{"projection.timestamp.interval", "1"},
+		{"projection.timestamp.interval.unit", "DAYS"},
+		{"storage.location.template", t.Location() + "${account}/CloudTrail/${region}/${timestamp}"},
+	}
+}
+
+func (t *Table) formatProperties() string {
+	lines := []string{}
+	for _, property := range t.properties() {
+		lines = append(lines, fmt.Sprintf("  '%s'='%s'", property[0], property[1]))
+	}
+	return strings.Join(lines, ",\n")
+}
+
+func (t *Table) CreateDatabaseQuery() string {
+	return fmt.Sprintf("CREATE DATABASE IF NOT EXISTS `%s`", t.Database)
+}
+
+func (t *Table) CreateQuery() string {
+	return fmt.Sprintf(`CREATE EXTERNAL TABLE IF NOT EXISTS %s (
+%s
+)
+PARTITIONED BY (account STRING, region STRING, `+"`timestamp`"+` STRING)
+ROW FORMAT SERDE 'com.amazon.emr.hive.serde.CloudTrailSerde'
+STORED AS INPUTFORMAT 'com.amazon.emr.cloudtrail.CloudTrailInputFormat'
+OUTPUTFORMAT 'org.apache.hadoop.hive.ql.io.HiveIgnoreKeyTextOutputFormat'
+LOCATION '%s'
+TBLPROPERTIES (
+%s
+)`, t.QualifiedName(), cloudTrailColumns, t.Location(), t.formatProperties())
+}
+
+// UpdateQuery sets the partition projection of an existing table, for the
+// accounts and regions added since it was created.
+func (t *Table) UpdateQuery() string {
+	return fmt.Sprintf("ALTER TABLE %s SET TBLPROPERTIES (\n%s\n)", t.QualifiedName(), t.formatProperties())
+}
+
+// ParsePrefixes returns the last component of the common prefixes matching
+// pattern, sorted, e.g. the accounts of AWSLogs/123456789012/.
+func ParsePrefixes(prefixes []string, pattern *regexp.Regexp) []string {
+	seen := map[string]bool{}
+	result := []string{}
+	for _, prefix := range prefixes {
+		name := path.Base(strings.TrimSuffix(prefix, "/"))
+		if pattern.MatchString(name) && !seen[name] {
+			seen[name] = true
+			result = append(result, name)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTableLocation(t *testing.T) {
+	logsTable := &Table{Bucket: "trail-logs"}
+	require.Equal(t, "s3://trail-logs/AWSLogs/", logsTable.Location())
+
+	logsTable = &Table{Bucket: "trail-logs", Prefix: "org/", OrganizationID: "o-abc123"}
+	require.Equal(t, "s3://trail-logs/org/AWSLogs/o-abc123/", logsTable.Location())
+}
+
+func TestCreateQuery(t *testing.T) {
+	logsTable := &Table{
+		Database:       "cloudtrail",
+		Name:           "logs",
+		Bucket:         "trail-logs",
+		OrganizationID: "o-abc123",
+		Accounts:       []string{"111111111111", "222222222222"},
+		Regions:        []string{"eu-west-1", "us-east-1"},
+		StartDate:      "2021/01/01",
+	}
+
+	query := logsTable.CreateQuery()
+	require.True(t, strings.HasPrefix(query, "CREATE EXTERNAL TABLE IF NOT EXISTS `cloudtrail`.`logs` ("))
+	require.Contains(t, query, "LOCATION 's3://trail-logs/AWSLogs/o-abc123/'")
+	require.Contains(t, query, "'projection.account.values'='111111111111,222222222222'")
+	require.Contains(t, query, "'projection.timestamp.range'='2021/01/01,NOW'")
+	require.Contains(t, query, "'storage.location.template'='s3://trail-logs/AWSLogs/o-abc123/${account}/CloudTrail/${region}/${timestamp}'")
+
+	require.True(t, strings.HasPrefix(logsTable.UpdateQuery(), "ALTER TABLE `cloudtrail`.`logs` SET TBLPROPERTIES (\n  'projection.enabled'='true',"))
+}
+
+func TestParsePrefixes(t *testing.T) {
+	require.Equal(t, []string{"111111111111", "222222222222"}, ParsePrefixes([]string{
+		"AWSLogs/o-abc123/222222222222/",
+		"AWSLogs/o-abc123/111111111111/",
+		"AWSLogs/o-abc123/_not-an-account/",
+	}, accountIDPattern))
+
+	require.Equal(t, []string{"eu-west-1", "us-gov-west-1"}, ParsePrefixes([]string{
+		"AWSLogs/111111111111/CloudTrail/us-gov-west-1/",
+		"AWSLogs/111111111111/CloudTrail/eu-west-1/",
+		"AWSLogs/222222222222/CloudTrail/eu-west-1/",
+	}, regionPattern))
+}
+
+func TestQueries(t *testing.T) {
+	logsTable := &Table{Database: "cloudtrail", Name: "logs"}
+	parameters := QueryParameters{Days: 3, Threshold: 20}
+
+	for _, query := range Queries {
+		rendered := query.Render(logsTable, parameters)
+		require.Contains(t, rendered, "FROM `cloudtrail`.`logs`")
+		require.Contains(t, rendered, "interval '3' day")
+		require.NotContains(t, rendered, "{")
+	}
+
+	query, err := FindQuery("access-denied-spikes")
+	require.NoError(t, err)
+	require.Contains(t, query.Render(logsTable, parameters), "HAVING count(*) >= 20")
+	require.Equal(t, "Principals with at least 20 access denied errors in an hour.", query.RenderDescription(logsTable, parameters))
+
+	_, err = FindQuery("unknown")
+	require.EqualError(t, err, "unknown query unknown, should be one of console-logins-without-mfa, root-usage, access-denied-spikes")
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	SourceKinesis = "kinesis"
+	SourceMSK     = "msk"
+
+	UnitMilliseconds = "milliseconds"
+	UnitSeconds      = "seconds"
+	UnitCount        = "count"
+)
+
+// LagMetric is a CloudWatch metric of the lag of the consumers of a stream or
+// a cluster, with the dimensions identifying them.
+type LagMetric struct {
+	Source            string
+	Namespace         string
+	Name              string
+	Unit              string
+	ResourceDimension string
+	ConsumerDimension string
+	TopicDimension    string
+}
+
+var LagMetrics = []LagMetric{
+	// age of the last record read by the shared throughput consumers
+	{SourceKinesis, "AWS/Kinesis", "GetRecords.IteratorAgeMilliseconds", UnitMilliseconds, "StreamName", "", ""},
+	// enhanced fan-out consumers
+	{SourceKinesis, "AWS/Kinesis", "SubscribeToShardEvent.MillisBehindLatest", UnitMilliseconds, "StreamName", "ConsumerName", ""},
+	// consumer groups, published by MSK at the DEFAULT monitoring level
+	{SourceMSK, "AWS/Kafka", "SumOffsetLag", UnitCount, "Cluster Name", "Consumer Group", "Topic"},
+	{SourceMSK, "AWS/Kafka", "EstimatedMaxTimeLag", UnitSeconds, "Cluster Name", "Consumer Group", "Topic"},
+}
+
+func (m *LagMetric) dimensions() []string {
+	dimensions := []string{m.ResourceDimension}
+	for _, dimension := range []string{m.ConsumerDimension, m.TopicDimension} {
+		if dimension != "" {
+			dimensions = append(dimensions, dimension)
+		}
+	}
+	return dimensions
+}
+
+// Thresholds above which the lag is reported, 0 to never report it.
+type Thresholds struct {
+	Kinesis   time.Duration
+	OffsetLag float64
+	TimeLag   time.Duration
+}
+
+// Threshold returns the threshold of the metric in its unit.
+func (t *Thresholds) Threshold(metric *LagMetric) float64 {
+	switch metric.Unit {
+	case UnitMilliseconds:
+		return float64(t.Kinesis / time.Millisecond)
+	case UnitSeconds:
+		return t.TimeLag.Seconds()
+	}
+	return t.OffsetLag
+}
+
+type Lag struct {
+	Region    string   `json:"region"`
+	Source    string   `json:"source"`
+	Resource  string   `json:"resource"`
+	Consumer  string   `json:"consumer"`
+	Topic     string   `json:"topic,omitempty"`
+	Metric    string   `json:"metric"`
+	Unit      string   `json:"unit"`
+	Value     *float64 `json:"value"`
+	Threshold float64  `json:"threshold"`
+	Exceeded  bool     `json:"exceeded"`
+
+	metric *LagMetric
+	// dimensions of the CloudWatch metric
+	dimensions map[string]string
+}
+
+// NewLag returns the lag of the metric with the dimensions, false if the
+// dimensions are not the ones of the metric, e.g. the per shard or per
+// partition metrics.
+func NewLag(region string, metric *LagMetric, dimensions map[string]string) (*Lag, bool) {
+	expected := metric.dimensions()
+	if len(dimensions) != len(expected) {
+		return nil, false
+	}
+	for _, dimension := range expected {
+		if _, ok := dimensions[dimension]; !ok {
+			return nil, false
+		}
+	}
+
+	return &Lag{
+		Region:     region,
+		Source:     metric.Source,
+		Resource:   dimensions[metric.ResourceDimension],
+		Consumer:   dimensions[metric.ConsumerDimension],
+		Topic:      dimensions[metric.TopicDimension],
+		Metric:     metric.Name,
+		Unit:       metric.Unit,
+		metric:     metric,
+		dimensions: dimensions,
+	}, true
+}
+
+// Evaluate sets the threshold of the lag and whether the value exceeds it.
+func (l *Lag) Evaluate(thresholds *Thresholds) {
+	l.Threshold = thresholds.Threshold(l.metric)
+	l.Exceeded = l.Value != nil && l.Threshold > 0 && *l.Value > l.Threshold
+}
+
+// FormatValue formats the value in its unit, durations are rounded to the
+// second.
+func FormatValue(value float64, unit string) string {
+	switch unit {
+	case UnitMilliseconds:
+		return (time.Duration(value) * time.Millisecond).Round(time.Second).String()
+	case UnitSeconds:
+		return (time.Duration(value) * time.Second).String()
+	}
+	return fmt.Sprintf("%.0f", value)
+}
+
+// SortLags sorts the lags by region, source, resource, consumer, topic and
+// metric.
+func SortLags(lags []*Lag) {
+	sort.Slice(lags, func(i, j int) bool {
+		a := strings.Join([]string{lags[i].Region, lags[i].Source, lags[i].Resource, lags[i].Consumer, lags[i].Topic, lags[i].Metric}, "\x00")
+		b := strings.Join([]string{lags[j].Region, lags[j].Source, lags[j].Resource, lags[j].Consumer, lags[j].Topic, lags[j].Metric}, "\x00")
+		return a < b
+	})
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewLag(t *testing.T) {
+	msk := &LagMetrics[2]
+
+	lag, ok := NewLag("eu-west-1", msk, map[string]string{"Cluster Name": "events", "Consumer Group": "billing", "Topic": "orders"})
+	assert.True(t, ok)
+	assert.Equal(t, SourceMSK, lag.Source)
+	assert.Equal(t, "events", lag.Resource)
+	assert.Equal(t, "billing", lag.Consumer)
+	assert.Equal(t, "orders", lag.Topic)
+
+	// per partition metric
+	_, ok = NewLag("eu-west-1", msk, map[string]string{"Cluster Name": "events", "Consumer Group": "billing", "Topic": "orders", "Partition": "0"})
+	assert.False(t, ok)
+
+	// per shard metric
+	_, ok = NewLag("eu-west-1", &LagMetrics[0], map[string]string{"StreamName": "clicks", "ShardId": "shardId-000000000000"})
+	assert.False(t, ok)
+
+	lag, ok = NewLag("eu-west-1", &LagMetrics[1], map[string]string{"StreamName": "clicks", "ConsumerName": "analytics"})
+	assert.True(t, ok)
+	assert.Equal(t, "analytics", lag.Consumer)
+	assert.Equal(t, "", lag.Topic)
+}
+
+func TestEvaluate(t *testing.T) {
+	thresholds := &Thresholds{Kinesis: time.Minute, OffsetLag: 1000, TimeLag: 0}
+
+	kinesis, _ := NewLag("eu-west-1", &LagMetrics[0], map[string]string{"StreamName": "clicks"})
+	kinesis.Value = aws.Float64(90000)
+	kinesis.Evaluate(thresholds)
+	assert.Equal(t, 60000.0, kinesis.Threshold)
+	assert.True(t, kinesis.Exceeded)
+
+	offset, _ := NewLag("eu-west-1", &LagMetrics[2], map[string]string{"Cluster Name": "events", "Consumer Group": "billing", "Topic": "orders"})
+	offset.Value = aws.Float64(500)
+	offset.Evaluate(thresholds)
+	assert.False(t, offset.Exceeded)
+
+	// disabled threshold
+	timeLag, _ := NewLag("eu-west-1", &LagMetrics[3], map[string]string{"Cluster Name": "events", "Consumer Group": "billing", "Topic": "orders"})
+	timeLag.Value = aws.Float64(3600)
+	timeLag.Evaluate(thresholds)
+	assert.False(t, timeLag.Exceeded)
+
+	// no datapoint
+	noData, _ := NewLag("eu-west-1", &LagMetrics[0], map[string]string{"StreamName": "idle"})
+	noData.Evaluate(thresholds)
+	assert.False(t, noData.Exceeded)
+}
+
+func TestFormatValue(t *testing.T) {
+	assert.Equal(t, "1m30s", FormatValue(90400, UnitMilliseconds))
+	assert.Equal(t, "5m0s", FormatValue(300, UnitSeconds))
+	assert.Equal(t, "12345", FormatValue(12345, UnitCount))
+}
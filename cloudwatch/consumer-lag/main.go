@@ -0,0 +1,227 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
+	"github.com/hamstah/awstools/common"
+	kingpin "gopkg.in/alecthomas/kingpin.v2"
+)
+
+const (
+	// maximum number of queries of a GetMetricData call
+	metricDataBatchSize = 500
+)
+
+var (
+	scanRegions   = kingpin.Flag("scan-region", "Region of the streams and clusters. Can be repeated, defaults to the session region.").Strings()
+	sources       = kingpin.Flag("source", "Only report the lag of this source. Can be repeated, defaults to all.").Enums(SourceKinesis, SourceMSK)
+	streams       = kingpin.Flag("stream", "Only report the lag of this Kinesis stream. Can be repeated.").Strings()
+	clusters      = kingpin.Flag("cluster", "Only report the lag of this MSK cluster. Can be repeated.").Strings()
+	maxKinesisLag = kingpin.Flag("max-kinesis-lag", "Alert when the iterator age or the time behind latest of a Kinesis consumer is above, 0 to disable.").Default("5m").Duration()
+	maxOffsetLag  = kingpin.Flag("max-offset-lag", "Alert when the offset lag of an MSK consumer group on a topic is above, 0 to disable.").Default("10000").Float64()
+	maxTimeLag    = kingpin.Flag("max-time-lag", "Alert when the estimated time lag of an MSK consumer group on a topic is above, 0 to disable.").Default("5m").Duration()
+	window        = kingpin.Flag("window", "Look for the latest datapoint of the metrics in this window.").Default("15m").Duration()
+	period        = kingpin.Flag("period", "Period of the datapoints.").Default("1m").Duration()
+	onlyExceeded  = kingpin.Flag("only-exceeded", "Only report the lags above their threshold.").Default("false").Bool()
+	format        = kingpin.Flag("format", "Output format.").Default("text").Enum("text", "json")
+)
+
+func contains(values []string, value string) bool {
+	for _, item := range values {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+func selected(lag *Lag) bool {
+	if len(*sources) > 0 && !contains(*sources, lag.Source) {
+		return false
+	}
+	if lag.Source == SourceKinesis && len(*streams) > 0 && !contains(*streams, lag.Resource) {
+		return false
+	}
+	if lag.Source == SourceMSK && len(*clusters) > 0 && !contains(*clusters, lag.Resource) {
+		return false
+	}
+	return true
+}
+
+// listLags returns the consumers with lag metrics in CloudWatch. ListMetrics
+// only returns the metrics with datapoints in the last 2 weeks.
+func listLags(client *cloudwatch.CloudWatch, region string) ([]*Lag, error) {
+	lags := []*Lag{}
+	for i := range LagMetrics {
+		metric := &LagMetrics[i]
+		if len(*sources) > 0 && !contains(*sources, metric.Source) {
+			continue
+		}
+
+		err := client.ListMetricsPages(&cloudwatch.ListMetricsInput{
+			Namespace:  aws.String(metric.Namespace),
+			MetricName: aws.String(metric.Name),
+		}, func(page *cloudwatch.ListMetricsOutput, lastPage bool) bool {
+			for _, item := range page.Metrics {
+				dimensions := map[string]string{}
+				for _, dimension := range item.Dimensions {
+					dimensions[aws.StringValue(dimension.Name)] = aws.StringValue(dimension.Value)
+				}
+				if lag, ok := NewLag(region, metric, dimensions); ok && selected(lag) {
+					lags = append(lags, lag)
+				}
+			}
+			return true
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return lags, nil
+}
+
+// getValues sets the value of the lags to the maximum of their latest
+// datapoint in the window.
+func getValues(client *cloudwatch.CloudWatch, lags []*Lag) error {
+	end := time.Now()
+	start := end.Add(-*window)
+
+	for offset := 0; offset < len(lags); offset += metricDataBatchSize {
+		batch := lags[offset:]
+		if len(batch) > metricDataBatchSize {
+			batch = batch[:metricDataBatchSize]
+		}
+
+		byID := map[string]*Lag{}
+		queries := []*cloudwatch.MetricDataQuery{}
+		for i, lag := range batch {
+			id := fmt.Sprintf("m%d", i)
+			byID[id] = lag
+
+			dimensions := []*cloudwatch.Dimension{}
+			for name, value := range lag.dimensions {
+				dimensions = append(dimensions, &cloudwatch.Dimension{Name: aws.String(name), Value: aws.String(value)})
+			}
+			queries = append(queries, &cloudwatch.MetricDataQuery{
+				Id: aws.String(id),
+				MetricStat: &cloudwatch.MetricStat{
+					Metric: &cloudwatch.Metric{
+						Namespace:  aws.String(lag.metric.Namespace),
+						MetricName: aws.String(lag.Metric),
+						Dimensions: dimensions,
+					},
+					Period: aws.Int64(int64(period.Seconds())),
+					Stat:   aws.String(cloudwatch.StatisticMaximum),
+				},
+				ReturnData: aws.Bool(true),
+			})
+		}
+
+		err := client.GetMetricDataPages(&cloudwatch.GetMetricDataInput{
+			MetricDataQueries: queries,
+			StartTime:         aws.Time(start),
+			EndTime:           aws.Time(end),
+			ScanBy:            aws.String(cloudwatch.ScanByTimestampDescending),
+		}, func(page *cloudwatch.GetMetricDataOutput, lastPage bool) bool {
+			for _, result := range page.MetricDataResults {
+				lag, ok := byID[aws.StringValue(result.Id)]
+				if !ok || lag.Value != nil || len(result.Values) == 0 {
+					continue
+				}
+				lag.Value = result.Values[0]
+			}
+			return true
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func main() {
+	kingpin.CommandLine.Name = "cloudwatch-consumer-lag"
+	kingpin.CommandLine.Help = "Report the lag of the Kinesis stream consumers and MSK consumer groups, and alert when it is above thresholds."
+	flags := common.HandleFlags()
+
+	sess, conf := common.OpenSession(flags)
+
+	regions := *scanRegions
+	if len(regions) == 0 {
+		regions = []string{aws.StringValue(conf.Region)}
+	}
+
+	thresholds := &Thresholds{
+		Kinesis:   *maxKinesisLag,
+		OffsetLag: *maxOffsetLag,
+		TimeLag:   *maxTimeLag,
+	}
+
+	lags := []*Lag{}
+	exceeded := false
+	for _, region := range regions {
+		client := cloudwatch.New(sess, conf.Copy(&aws.Config{Region: aws.String(region)}))
+
+		regionLags, err := listLags(client, region)
+		common.FatalOnErrorW(err, fmt.Sprintf("failed to list the lag metrics of %s", region))
+
+		err = getValues(client, regionLags)
+		common.FatalOnErrorW(err, fmt.Sprintf("failed to get the lag metrics of %s", region))
+
+		for _, lag := range regionLags {
+			lag.Evaluate(thresholds)
+			if lag.Exceeded {
+				exceeded = true
+			} else if *onlyExceeded {
+				continue
+			}
+			lags = append(lags, lag)
+		}
+	}
+	SortLags(lags)
+
+	if *format == "json" {
+		output, err := json.MarshalIndent(lags, "", "  ")
+		common.FatalOnErrorW(err, "failed to serialise the lags")
+		fmt.Println(string(output))
+	} else {
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "REGION\tSOURCE\tRESOURCE\tCONSUMER\tTOPIC\tMETRIC\tLAG\tTHRESHOLD\tALERT")
+		for _, lag := range lags {
+			value := "no data"
+			if lag.Value != nil {
+				value = FormatValue(*lag.Value, lag.Unit)
+			}
+			threshold := "-"
+			if lag.Threshold > 0 {
+				threshold = FormatValue(lag.Threshold, lag.Unit)
+			}
+			alert := ""
+			if lag.Exceeded {
+				alert = "yes"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+				lag.Region,
+				lag.Source,
+				lag.Resource,
+				lag.Consumer,
+				lag.Topic,
+				lag.Metric,
+				value,
+				threshold,
+				alert,
+			)
+		}
+		w.Flush()
+	}
+
+	if exceeded {
+		os.Exit(1)
+	}
+}
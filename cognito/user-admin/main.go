@@ -0,0 +1,202 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/cognitoidentityprovider"
+	"github.com/hamstah/awstools/common"
+	log "github.com/sirupsen/logrus"
+	kingpin "gopkg.in/alecthomas/kingpin.v2"
+)
+
+const (
+	ActionExport           = "export"
+	ActionDisable          = "disable"
+	ActionDelete           = "delete"
+	ActionResendInvitation = "resend-invitation"
+	ActionSetMFA           = "set-mfa"
+)
+
+var (
+	userPoolID = kingpin.Flag("user-pool-id", "ID of the user pool.").Required().String()
+	action     = kingpin.Flag("action", "Action to run.").Required().Enum(ActionExport, ActionDisable, ActionDelete, ActionResendInvitation, ActionSetMFA)
+	usersFile  = kingpin.Flag("users", "File with the usernames to disable, delete or resend the invitation to, one per line or a CSV from export. - for stdin.").String()
+	mfa        = kingpin.Flag("mfa", "MFA configuration of the user pool for set-mfa.").Enum(cognitoidentityprovider.UserPoolMfaTypeOff, cognitoidentityprovider.UserPoolMfaTypeOn, cognitoidentityprovider.UserPoolMfaTypeOptional)
+	rate       = kingpin.Flag("rate", "Maximum number of API calls per second.").Default("5").Float64()
+	dryRun     = kingpin.Flag("dry-run", "Print the users that would be changed without changing them.").Default("false").Bool()
+	output     = kingpin.Flag("output", "File to write the export to, defaults to stdout.").Short('o').String()
+)
+
+// limiter spaces the API calls to respect the Cognito admin API quotas.
+type limiter struct {
+	ticker *time.Ticker
+}
+
+func newLimiter(rate float64) *limiter {
+	return &limiter{ticker: time.NewTicker(time.Duration(float64(time.Second) / rate))}
+}
+
+func (l *limiter) Wait() {
+	<-l.ticker.C
+}
+
+func main() {
+	kingpin.CommandLine.Name = "cognito-user-admin"
+	kingpin.CommandLine.Help = "Bulk operations on the users of a Cognito user pool."
+	flags := common.HandleFlags()
+
+	if *rate <= 0 {
+		common.Fatalln("--rate must be positive")
+	}
+
+	session, conf := common.OpenSession(flags)
+	client := cognitoidentityprovider.New(session, conf)
+	limit := newLimiter(*rate)
+
+	switch *action {
+	case ActionExport:
+		exportUsers(client, limit)
+	case ActionSetMFA:
+		setMFA(client)
+	default:
+		updateUsers(client, limit)
+	}
+}
+
+func exportUsers(client *cognitoidentityprovider.CognitoIdentityProvider, limit *limiter) {
+	users := []User{}
+	input := &cognitoidentityprovider.ListUsersInput{UserPoolId: userPoolID}
+	for {
+		limit.Wait()
+		page, err := client.ListUsers(input)
+		common.FatalOnErrorW(err, "failed to list the users")
+
+		for _, user := range page.Users {
+			attributes := map[string]string{}
+			for _, attribute := range user.Attributes {
+				attributes[*attribute.Name] = aws.StringValue(attribute.Value)
+			}
+			users = append(users, User{
+				Username:   *user.Username,
+				Status:     aws.StringValue(user.UserStatus),
+				Enabled:    aws.BoolValue(user.Enabled),
+				Created:    aws.TimeValue(user.UserCreateDate),
+				Modified:   aws.TimeValue(user.UserLastModifiedDate),
+				Attributes: attributes,
+			})
+		}
+
+		if page.PaginationToken == nil {
+			break
+		}
+		input.PaginationToken = page.PaginationToken
+	}
+
+	writer := io.Writer(os.Stdout)
+	if *output != "" {
+		file, err := os.Create(*output)
+		common.FatalOnErrorW(err, "failed to create the output file")
+		defer file.Close()
+		writer = file
+	}
+
+	err := WriteUsersCSV(writer, users)
+	common.FatalOnErrorW(err, "failed to write the users")
+}
+
+func readUsernames() []string {
+	if *usersFile == "" {
+		common.Fatalln(fmt.Sprintf("--users is required for %s", *action))
+	}
+
+	reader := io.Reader(os.Stdin)
+	if *usersFile != "-" {
+		file, err := os.Open(*usersFile)
+		common.FatalOnErrorW(err, "failed to open the users file")
+		defer file.Close()
+		reader = file
+	}
+
+	usernames, err := ReadUsernames(reader)
+	common.FatalOnErrorW(err, "failed to read the users file")
+	return usernames
+}
+
+func updateUser(client *cognitoidentityprovider.CognitoIdentityProvider, username string) error {
+	var err error
+	switch *action {
+	case ActionDisable:
+		_, err = client.AdminDisableUser(&cognitoidentityprovider.AdminDisableUserInput{
+			UserPoolId: userPoolID,
+			Username:   aws.String(username),
+		})
+	case ActionDelete:
+		_, err = client.AdminDeleteUser(&cognitoidentityprovider.AdminDeleteUserInput{
+			UserPoolId: userPoolID,
+			Username:   aws.String(username),
+		})
+	case ActionResendInvitation:
+		_, err = client.AdminCreateUser(&cognitoidentityprovider.AdminCreateUserInput{
+			UserPoolId:    userPoolID,
+			Username:      aws.String(username),
+			MessageAction: aws.String(cognitoidentityprovider.MessageActionTypeResend),
+		})
+	}
+	return err
+}
+
+func updateUsers(client *cognitoidentityprovider.CognitoIdentityProvider, limit *limiter) {
+	usernames := readUsernames()
+
+	failed := 0
+	for _, username := range usernames {
+		if *dryRun {
+			fmt.Printf("%s %s (dry run)\n", *action, username)
+			continue
+		}
+
+		limit.Wait()
+		err := updateUser(client, username)
+		if err != nil {
+			failed++
+			log.WithFields(log.Fields{
+				"username": username,
+				"action":   *action,
+			}).Error(err)
+			continue
+		}
+		fmt.Printf("%s %s\n", *action, username)
+	}
+
+	if failed > 0 {
+		common.Fatalln(fmt.Sprintf("%s failed for %d of %d users", *action, failed, len(usernames)))
+	}
+}
+
+// setMFA changes the MFA configuration of the user pool, keeping its SMS and
+// software token settings.
+func setMFA(client *cognitoidentityprovider.CognitoIdentityProvider) {
+	if *mfa == "" {
+		common.Fatalln("--mfa is required for set-mfa")
+	}
+
+	current, err := client.GetUserPoolMfaConfig(&cognitoidentityprovider.GetUserPoolMfaConfigInput{UserPoolId: userPoolID})
+	common.FatalOnErrorW(err, "failed to get the MFA configuration")
+
+	fmt.Printf("MFA %s -> %s\n", aws.StringValue(current.MfaConfiguration), *mfa)
+	if *dryRun {
+		return
+	}
+
+	_, err = client.SetUserPoolMfaConfig(&cognitoidentityprovider.SetUserPoolMfaConfigInput{
+		UserPoolId:                    userPoolID,
+		MfaConfiguration:              mfa,
+		SmsMfaConfiguration:           current.SmsMfaConfiguration,
+		SoftwareTokenMfaConfiguration: current.SoftwareTokenMfaConfiguration,
+	})
+	common.FatalOnErrorW(err, "failed to set the MFA configuration")
+}
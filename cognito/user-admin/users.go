@@ -0,0 +1,88 @@
+package main
+
+import (
+	"encoding/csv"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+type User struct {
+	Username   string
+	Status     string
+	Enabled    bool
+	Created    time.Time
+	Modified   time.Time
+	Attributes map[string]string
+}
+
+// ReadUsernames reads a list of usernames, one per line. A CSV exported by the
+// tool can be used as well, the username is in the first column.
+func ReadUsernames(reader io.Reader) ([]string, error) {
+	csvReader := csv.NewReader(reader)
+	csvReader.FieldsPerRecord = -1
+	csvReader.Comment = '#'
+
+	usernames := []string{}
+	for i := 0; ; i++ {
+		record, err := csvReader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		username := strings.TrimSpace(record[0])
+		if username == "" || (i == 0 && username == "username") {
+			continue
+		}
+		usernames = append(usernames, username)
+	}
+	return usernames, nil
+}
+
+// WriteUsersCSV writes the users with a column per attribute of any of them.
+func WriteUsersCSV(writer io.Writer, users []User) error {
+	names := map[string]bool{}
+	for _, user := range users {
+		for name := range user.Attributes {
+			names[name] = true
+		}
+	}
+	attributes := []string{}
+	for name := range names {
+		attributes = append(attributes, name)
+	}
+	sort.Strings(attributes)
+
+	csvWriter := csv.NewWriter(writer)
+	header := append([]string{"username", "status", "enabled", "created", "modified"}, attributes...)
+	if err := csvWriter.Write(header); err != nil {
+		return err
+	}
+
+	for _, user := range users {
+		enabled := "false"
+		if user.Enabled {
+			enabled = "true"
+		}
+		row := []string{
+			user.Username,
+			user.Status,
+			enabled,
+			user.Created.UTC().Format(time.RFC3339),
+			user.Modified.UTC().Format(time.RFC3339),
+		}
+		for _, name := range attributes {
+			row = append(row, user.Attributes[name])
+		}
+		if err := csvWriter.Write(row); err != nil {
+			return err
+		}
+	}
+
+	csvWriter.Flush()
+	return csvWriter.Error()
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadUsernames(t *testing.T) {
+	usernames, err := ReadUsernames(strings.NewReader("alice\n\n# comment\n bob \n"))
+	require.NoError(t, err)
+	require.Equal(t, []string{"alice", "bob"}, usernames)
+
+	usernames, err = ReadUsernames(strings.NewReader("username,status,enabled\nalice,CONFIRMED,true\nbob,\"FORCE_CHANGE_PASSWORD\",false\n"))
+	require.NoError(t, err)
+	require.Equal(t, []string{"alice", "bob"}, usernames)
+}
+
+func TestWriteUsersCSV(t *testing.T) {
+	created := time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)
+	buffer := &bytes.Buffer{}
+	err := WriteUsersCSV(buffer, []User{
+		{
+			Username:   "alice",
+			Status:     "CONFIRMED",
+			Enabled:    true,
+			Created:    created,
+			Modified:   created,
+			Attributes: map[string]string{"email": "alice@example.com", "sub": "1"},
+		},
+		{
+			Username:   "bob",
+			Status:     "FORCE_CHANGE_PASSWORD",
+			Created:    created,
+			Modified:   created,
+			Attributes: map[string]string{"phone_number": "+441234567890", "sub": "2"},
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, `username,status,enabled,created,modified,email,phone_number,sub
+alice,CONFIRMED,true,2021-01-02T03:04:05Z,2021-01-02T03:04:05Z,alice@example.com,,1
+bob,FORCE_CHANGE_PASSWORD,false,2021-01-02T03:04:05Z,2021-01-02T03:04:05Z,,+441234567890,2
+`, buffer.String())
+}
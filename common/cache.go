@@ -0,0 +1,85 @@
+package common
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+// Cache memoizes the results of read-only calls during a run, e.g. the
+// versions of AWS managed policies attached to many roles.
+// Concurrent calls for the same key wait for the first one to complete.
+// Errors are not cached so the next call for the key is retried.
+type Cache struct {
+	mutex   sync.Mutex
+	entries map[string]*cacheEntry
+
+	hits   int64
+	misses int64
+}
+
+type cacheEntry struct {
+	done  chan struct{}
+	value interface{}
+	err   error
+}
+
+func NewCache() *Cache {
+	return &Cache{
+		entries: map[string]*cacheEntry{},
+	}
+}
+
+// CacheKey returns a key for an operation and its input, identical inputs
+// return identical keys.
+func CacheKey(operation string, input interface{}) (string, error) {
+	serialized, err := json.Marshal(input)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s:%s", operation, serialized), nil
+}
+
+// Do returns the cached value for key or calls fn to get it.
+func (c *Cache) Do(key string, fn func() (interface{}, error)) (interface{}, error) {
+	c.mutex.Lock()
+	entry, ok := c.entries[key]
+	if ok {
+		c.mutex.Unlock()
+		<-entry.done
+		atomic.AddInt64(&c.hits, 1)
+		return entry.value, entry.err
+	}
+
+	entry = &cacheEntry{done: make(chan struct{})}
+	c.entries[key] = entry
+	c.mutex.Unlock()
+
+	atomic.AddInt64(&c.misses, 1)
+	entry.value, entry.err = fn()
+
+	if entry.err != nil {
+		c.mutex.Lock()
+		delete(c.entries, key)
+		c.mutex.Unlock()
+	}
+	close(entry.done)
+
+	return entry.value, entry.err
+}
+
+// DoRequest caches fn by the operation name and input.
+func (c *Cache) DoRequest(operation string, input interface{}, fn func() (interface{}, error)) (interface{}, error) {
+	key, err := CacheKey(operation, input)
+	if err != nil {
+		return nil, err
+	}
+	return c.Do(key, fn)
+}
+
+// Stats returns the number of calls served from the cache and the number of
+// calls made.
+func (c *Cache) Stats() (int64, int64) {
+	return atomic.LoadInt64(&c.hits), atomic.LoadInt64(&c.misses)
+}
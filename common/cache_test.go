@@ -0,0 +1,84 @@
+package common
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCacheDo(t *testing.T) {
+	cache := NewCache()
+
+	calls := 0
+	fn := func() (interface{}, error) {
+		calls++
+		return "value", nil
+	}
+
+	for i := 0; i < 3; i++ {
+		value, err := cache.Do("key", fn)
+		require.NoError(t, err)
+		assert.Equal(t, "value", value)
+	}
+	assert.Equal(t, 1, calls)
+
+	hits, misses := cache.Stats()
+	assert.Equal(t, int64(2), hits)
+	assert.Equal(t, int64(1), misses)
+}
+
+func TestCacheDoDoesNotCacheErrors(t *testing.T) {
+	cache := NewCache()
+
+	_, err := cache.Do("key", func() (interface{}, error) {
+		return nil, errors.New("throttled")
+	})
+	require.Error(t, err)
+
+	value, err := cache.Do("key", func() (interface{}, error) {
+		return "value", nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "value", value)
+}
+
+func TestCacheDoConcurrent(t *testing.T) {
+	cache := NewCache()
+
+	var mutex sync.Mutex
+	calls := 0
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			value, err := cache.DoRequest("GetPolicyVersion", map[string]string{"VersionId": "v1"}, func() (interface{}, error) {
+				mutex.Lock()
+				calls++
+				mutex.Unlock()
+				return "v1", nil
+			})
+			assert.NoError(t, err)
+			assert.Equal(t, "v1", value)
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, 1, calls)
+}
+
+func TestCacheKey(t *testing.T) {
+	a, err := CacheKey("GetPolicy", map[string]string{"PolicyArn": "arn:aws:iam::aws:policy/ReadOnlyAccess"})
+	require.NoError(t, err)
+	b, err := CacheKey("GetPolicy", map[string]string{"PolicyArn": "arn:aws:iam::aws:policy/ReadOnlyAccess"})
+	require.NoError(t, err)
+	c, err := CacheKey("GetPolicyVersion", map[string]string{"PolicyArn": "arn:aws:iam::aws:policy/ReadOnlyAccess"})
+	require.NoError(t, err)
+
+	assert.Equal(t, a, b)
+	assert.NotEqual(t, a, c)
+}
@@ -0,0 +1,152 @@
+package common
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+)
+
+// Deprecation maps a renamed flag or config key to its new name. Config keys
+// of nested objects are separated by dots, e.g. options.overwrite.
+type Deprecation struct {
+	Old string
+	New string
+}
+
+// RewriteDeprecatedFlags replaces the deprecated flags in args with their new
+// name, including --flag=value and --no-flag forms, and returns a warning for
+// each of them. Arguments after -- are left as they are.
+func RewriteDeprecatedFlags(args []string, deprecations []Deprecation) ([]string, []string) {
+	renames := map[string]string{}
+	for _, deprecation := range deprecations {
+		renames[deprecation.Old] = deprecation.New
+	}
+
+	result := make([]string, 0, len(args))
+	warnings := []string{}
+	for i, arg := range args {
+		if arg == "--" {
+			result = append(result, args[i:]...)
+			break
+		}
+		if !strings.HasPrefix(arg, "--") {
+			result = append(result, arg)
+			continue
+		}
+
+		name, value := arg[2:], ""
+		if index := strings.Index(name, "="); index >= 0 {
+			name, value = name[:index], name[index:]
+		}
+
+		prefix := "--"
+		if _, ok := renames[name]; !ok && strings.HasPrefix(name, "no-") {
+			prefix, name = "--no-", strings.TrimPrefix(name, "no-")
+		}
+
+		newName, ok := renames[name]
+		if !ok {
+			result = append(result, arg)
+			continue
+		}
+
+		warnings = append(warnings, fmt.Sprintf("%s%s is deprecated, use %s%s instead", prefix, name, prefix, newName))
+		result = append(result, prefix+newName+value)
+	}
+
+	return result, warnings
+}
+
+func splitConfigKey(key string) ([]string, string) {
+	parts := strings.Split(key, ".")
+	return parts[:len(parts)-1], parts[len(parts)-1]
+}
+
+// configObject returns the nested object at path, nil if it doesn't exist.
+func configObject(config map[string]interface{}, path []string, create bool) map[string]interface{} {
+	object := config
+	for _, key := range path {
+		child, ok := object[key].(map[string]interface{})
+		if !ok {
+			if !create || object[key] != nil {
+				return nil
+			}
+			child = map[string]interface{}{}
+			object[key] = child
+		}
+		object = child
+	}
+	return object
+}
+
+// MigrateConfig renames the deprecated keys of a config in place and returns
+// a description of each change. Values already set with the new key are kept.
+func MigrateConfig(config map[string]interface{}, deprecations []Deprecation) []string {
+	changes := []string{}
+	for _, deprecation := range deprecations {
+		oldPath, oldKey := splitConfigKey(deprecation.Old)
+		object := configObject(config, oldPath, false)
+		if object == nil {
+			continue
+		}
+		value, ok := object[oldKey]
+		if !ok {
+			continue
+		}
+		delete(object, oldKey)
+
+		newPath, newKey := splitConfigKey(deprecation.New)
+		newObject := configObject(config, newPath, true)
+		if newObject == nil {
+			changes = append(changes, fmt.Sprintf("removed %s, %s is not an object", deprecation.Old, strings.Join(newPath, ".")))
+			continue
+		}
+		if _, exists := newObject[newKey]; exists {
+			changes = append(changes, fmt.Sprintf("removed %s, %s is already set", deprecation.Old, deprecation.New))
+			continue
+		}
+
+		newObject[newKey] = value
+		changes = append(changes, fmt.Sprintf("renamed %s to %s", deprecation.Old, deprecation.New))
+	}
+	return changes
+}
+
+// MigrateConfigFile migrates a JSON config file, the original file is kept
+// with a .bak suffix when it is changed.
+func MigrateConfigFile(filename string, deprecations []Deprecation) ([]string, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	config := map[string]interface{}{}
+	err = json.Unmarshal(data, &config)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := MigrateConfig(config, deprecations)
+	if len(changes) == 0 {
+		return changes, nil
+	}
+
+	info, err := os.Stat(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	err = ioutil.WriteFile(filename+".bak", data, info.Mode())
+	if err != nil {
+		return nil, err
+	}
+
+	migrated, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return changes, ioutil.WriteFile(filename, append(migrated, '\n'), info.Mode())
+}
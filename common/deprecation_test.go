@@ -0,0 +1,88 @@
+package common
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+var testDeprecations = []Deprecation{
+	{Old: "role", New: "assume-role-arn"},
+	{Old: "dry", New: "dry-run"},
+}
+
+func TestRewriteDeprecatedFlags(t *testing.T) {
+	args, warnings := RewriteDeprecatedFlags([]string{
+		"--role=arn:aws:iam::123456789012:role/Deploy",
+		"--no-dry",
+		"--region", "eu-west-1",
+		"--",
+		"--dry",
+	}, testDeprecations)
+
+	require.Equal(t, []string{
+		"--assume-role-arn=arn:aws:iam::123456789012:role/Deploy",
+		"--no-dry-run",
+		"--region", "eu-west-1",
+		"--",
+		"--dry",
+	}, args)
+	require.Equal(t, []string{
+		"--role is deprecated, use --assume-role-arn instead",
+		"--no-dry is deprecated, use --no-dry-run instead",
+	}, warnings)
+}
+
+func TestMigrateConfig(t *testing.T) {
+	config := map[string]interface{}{
+		"bucket": "states",
+		"options": map[string]interface{}{
+			"overwrite": true,
+			"workers":   4,
+		},
+		"concurrency": 2,
+	}
+
+	changes := MigrateConfig(config, []Deprecation{
+		{Old: "bucket", New: "s3.bucket"},
+		{Old: "options.overwrite", New: "options.replace"},
+		{Old: "options.workers", New: "concurrency"},
+		{Old: "missing", New: "other"},
+	})
+
+	require.Equal(t, []string{
+		"renamed bucket to s3.bucket",
+		"renamed options.overwrite to options.replace",
+		"removed options.workers, concurrency is already set",
+	}, changes)
+	require.Equal(t, map[string]interface{}{
+		"s3":          map[string]interface{}{"bucket": "states"},
+		"options":     map[string]interface{}{"replace": true},
+		"concurrency": 2,
+	}, config)
+}
+
+func TestMigrateConfigFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "migrate-config")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	filename := filepath.Join(dir, "config.json")
+	original := []byte(`{"dry": true}`)
+	require.NoError(t, ioutil.WriteFile(filename, original, 0600))
+
+	changes, err := MigrateConfigFile(filename, testDeprecations)
+	require.NoError(t, err)
+	require.Equal(t, []string{"renamed dry to dry-run"}, changes)
+
+	migrated, err := ioutil.ReadFile(filename)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"dry-run": true}`, string(migrated))
+
+	backup, err := ioutil.ReadFile(filename + ".bak")
+	require.NoError(t, err)
+	require.Equal(t, original, backup)
+}
@@ -1,14 +1,48 @@
 package common
 
-import kingpin "gopkg.in/alecthomas/kingpin.v2"
+import (
+	"fmt"
+	"os"
+
+	log "github.com/sirupsen/logrus"
+	kingpin "gopkg.in/alecthomas/kingpin.v2"
+)
 
 func HandleFlags() *SessionFlags {
+	return HandleFlagsWithDeprecations(nil, nil)
+}
+
+// HandleFlagsWithDeprecations is HandleFlags for tools that renamed some of
+// their flags or config keys. The deprecated flags keep working with a
+// warning, and with config keys --migrate-config rewrites a config file with
+// the new keys and exits.
+func HandleFlagsWithDeprecations(flags []Deprecation, configKeys []Deprecation) *SessionFlags {
+	var migrateConfig *string
+	if len(configKeys) > 0 {
+		migrateConfig = kingpin.Flag("migrate-config", "Rewrite this config file with the new keys and exit.").String()
+	}
+
 	sessionFlags := KingpinSessionFlags()
 	infoFlags := KingpinInfoFlags()
 	logFlags := KingpinLogFlags()
 
-	kingpin.Parse()
+	args, warnings := RewriteDeprecatedFlags(os.Args[1:], flags)
+	kingpin.MustParse(kingpin.CommandLine.Parse(args))
 	HandleInfoFlags(infoFlags)
 	HandleLogFlags(logFlags)
+
+	for _, warning := range warnings {
+		log.Warn(warning)
+	}
+
+	if migrateConfig != nil && *migrateConfig != "" {
+		changes, err := MigrateConfigFile(*migrateConfig, configKeys)
+		FatalOnErrorW(err, "failed to migrate the config")
+		for _, change := range changes {
+			fmt.Println(change)
+		}
+		os.Exit(0)
+	}
+
 	return sessionFlags
 }
@@ -0,0 +1,70 @@
+package main
+
+// Resource is the subset of the aws-dump output used to find the VPC and subnet CIDRs.
+type Resource struct {
+	ID        string                 `json:"id"`
+	Service   string                 `json:"service"`
+	Type      string                 `json:"type"`
+	AccountID string                 `json:"account_id"`
+	Region    string                 `json:"region"`
+	Metadata  map[string]interface{} `json:"metadata"`
+}
+
+// NetworksFromDump returns the CIDR blocks of the ec2:vpcs and ec2:subnets
+// resources of an aws-dump output.
+func NetworksFromDump(resources []Resource) []Network {
+	networks := []Network{}
+	for _, resource := range resources {
+		if resource.Service != "ec2" || resource.Metadata == nil {
+			continue
+		}
+
+		switch resource.Type {
+		case "vpc":
+			cidrs := []string{}
+			associations, _ := resource.Metadata["CidrBlockAssociationSet"].([]interface{})
+			for _, item := range associations {
+				association, ok := item.(map[string]interface{})
+				if !ok {
+					continue
+				}
+				if state, ok := association["CidrBlockState"].(map[string]interface{}); ok && state["State"] != "associated" {
+					continue
+				}
+				if cidr, ok := association["CidrBlock"].(string); ok {
+					cidrs = append(cidrs, cidr)
+				}
+			}
+			if len(cidrs) == 0 {
+				if cidr, ok := resource.Metadata["CidrBlock"].(string); ok {
+					cidrs = append(cidrs, cidr)
+				}
+			}
+
+			for _, cidr := range cidrs {
+				networks = append(networks, Network{
+					AccountID: resource.AccountID,
+					Region:    resource.Region,
+					VpcID:     resource.ID,
+					CIDR:      cidr,
+				})
+			}
+		case "subnet":
+			cidr, ok := resource.Metadata["CidrBlock"].(string)
+			if !ok {
+				continue
+			}
+			vpcID, _ := resource.Metadata["VpcId"].(string)
+			available, _ := resource.Metadata["AvailableIpAddressCount"].(float64)
+			networks = append(networks, Network{
+				AccountID:    resource.AccountID,
+				Region:       resource.Region,
+				VpcID:        vpcID,
+				SubnetID:     resource.ID,
+				CIDR:         cidr,
+				AvailableIPs: int64(available),
+			})
+		}
+	}
+	return networks
+}
@@ -0,0 +1,228 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"text/tabwriter"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/hamstah/awstools/common"
+	kingpin "gopkg.in/alecthomas/kingpin.v2"
+)
+
+var (
+	inputs      = kingpin.Flag("input", "aws-dump output file with ec2:vpcs and ec2:subnets to read the CIDRs from instead of the API. Can be repeated.").Short('i').Strings()
+	roleARNs    = kingpin.Flag("role-arn", "Role to assume to read the CIDRs of another account. Can be repeated.").Strings()
+	scanRegions = kingpin.Flag("scan-region", "Region to read the CIDRs from. Can be repeated, defaults to the session region.").Strings()
+	prefix      = kingpin.Flag("prefix", "Prefix length of the CIDR blocks to suggest (e.g. 16 for a VPC, 24 for a subnet).").Int()
+	pool        = kingpin.Flag("pool", "CIDR block to suggest VPC CIDR blocks from.").Default("10.0.0.0/8").String()
+	vpcID       = kingpin.Flag("vpc-id", "Suggest subnet CIDR blocks in this VPC instead of VPC CIDR blocks.").String()
+	count       = kingpin.Flag("count", "Number of CIDR blocks to suggest.").Default("3").Int()
+	format      = kingpin.Flag("format", "Output format.").Default("text").Enum("text", "json")
+)
+
+type Output struct {
+	Utilisation []SubnetUtilisation `json:"utilisation"`
+	Suggestions []string            `json:"suggestions"`
+}
+
+func main() {
+	kingpin.CommandLine.Name = "ec2-cidr-planner"
+	kingpin.CommandLine.Help = "Show the subnets utilisation and suggest free CIDR blocks."
+	flags := common.HandleFlags()
+
+	networks := []Network{}
+	if len(*inputs) > 0 {
+		for _, filename := range *inputs {
+			data, err := ioutil.ReadFile(filename)
+			common.FatalOnErrorW(err, "failed to read the dump")
+
+			resources := []Resource{}
+			err = json.Unmarshal(data, &resources)
+			common.FatalOnErrorW(err, "failed to parse the dump")
+
+			networks = append(networks, NetworksFromDump(resources)...)
+		}
+	} else {
+		sess, conf := common.OpenSession(flags)
+
+		configs := []*aws.Config{conf}
+		if len(*roleARNs) > 0 {
+			configs = []*aws.Config{}
+			for _, roleARN := range *roleARNs {
+				configs = append(configs, conf.Copy(&aws.Config{
+					Credentials: stscreds.NewCredentials(sess, roleARN),
+				}))
+			}
+		}
+
+		regions := *scanRegions
+		if len(regions) == 0 {
+			regions = []string{aws.StringValue(conf.Region)}
+		}
+
+		for _, config := range configs {
+			for _, region := range regions {
+				regionNetworks, err := listNetworks(sess, config.Copy(&aws.Config{Region: aws.String(region)}))
+				common.FatalOnErrorW(err, "failed to list the VPCs and subnets")
+				networks = append(networks, regionNetworks...)
+			}
+		}
+	}
+
+	utilisation, err := Utilisation(networks)
+	common.FatalOnErrorW(err, "failed to compute the subnets utilisation")
+
+	output := Output{
+		Utilisation: utilisation,
+		Suggestions: []string{},
+	}
+
+	if *prefix > 0 {
+		suggestions, err := suggest(networks)
+		common.FatalOnErrorW(err, "failed to suggest CIDR blocks")
+		output.Suggestions = suggestions
+	}
+
+	if *format == "json" {
+		data, err := json.MarshalIndent(output, "", "  ")
+		common.FatalOnErrorW(err, "failed to serialise the output")
+		fmt.Println(string(data))
+		return
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(writer, "ACCOUNT\tREGION\tVPC\tSUBNET\tCIDR\tUSED\tAVAILABLE\tPERCENT")
+	for _, subnet := range output.Utilisation {
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%s\t%d\t%d\t%.2f%%\n",
+			subnet.AccountID,
+			subnet.Region,
+			subnet.VpcID,
+			subnet.SubnetID,
+			subnet.CIDR,
+			subnet.Used,
+			subnet.Available,
+			subnet.Percent,
+		)
+	}
+	writer.Flush()
+
+	if *prefix > 0 {
+		fmt.Println()
+		if len(output.Suggestions) == 0 {
+			fmt.Printf("No free /%d CIDR block\n", *prefix)
+		}
+		for _, suggestion := range output.Suggestions {
+			fmt.Println(suggestion)
+		}
+	}
+}
+
+// suggest returns free VPC CIDR blocks in the pool, or free subnet CIDR blocks
+// in the VPC CIDR blocks when --vpc-id is set.
+func suggest(networks []Network) ([]string, error) {
+	if *vpcID == "" {
+		used := []string{}
+		for _, network := range networks {
+			if !network.IsSubnet() {
+				used = append(used, network.CIDR)
+			}
+		}
+		return Suggest(*pool, used, *prefix, *count)
+	}
+
+	pools := []string{}
+	used := []string{}
+	for _, network := range networks {
+		if network.VpcID != *vpcID {
+			continue
+		}
+		if network.IsSubnet() {
+			used = append(used, network.CIDR)
+		} else {
+			pools = append(pools, network.CIDR)
+		}
+	}
+	if len(pools) == 0 {
+		return nil, errors.New("VPC not found")
+	}
+
+	suggestions := []string{}
+	for _, vpcPool := range pools {
+		if len(suggestions) == *count {
+			break
+		}
+		// skip the IPv6 and too small VPC CIDR blocks
+		vpcRange, err := parseIPv4Range(vpcPool)
+		if err != nil {
+			return nil, err
+		}
+		if vpcRange == nil || vpcRange.prefix > *prefix {
+			continue
+		}
+
+		vpcSuggestions, err := Suggest(vpcPool, used, *prefix, *count-len(suggestions))
+		if err != nil {
+			return nil, err
+		}
+		suggestions = append(suggestions, vpcSuggestions...)
+	}
+	return suggestions, nil
+}
+
+func listNetworks(sess *session.Session, conf *aws.Config) ([]Network, error) {
+	client := ec2.New(sess, conf)
+	region := aws.StringValue(conf.Region)
+
+	networks := []Network{}
+	err := client.DescribeVpcsPages(&ec2.DescribeVpcsInput{},
+		func(page *ec2.DescribeVpcsOutput, lastPage bool) bool {
+			for _, vpc := range page.Vpcs {
+				for _, association := range vpc.CidrBlockAssociationSet {
+					if aws.StringValue(association.CidrBlockState.State) != ec2.VpcCidrBlockStateCodeAssociated {
+						continue
+					}
+					networks = append(networks, Network{
+						AccountID: aws.StringValue(vpc.OwnerId),
+						Region:    region,
+						VpcID:     *vpc.VpcId,
+						CIDR:      *association.CidrBlock,
+					})
+				}
+			}
+			return true
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	err = client.DescribeSubnetsPages(&ec2.DescribeSubnetsInput{},
+		func(page *ec2.DescribeSubnetsOutput, lastPage bool) bool {
+			for _, subnet := range page.Subnets {
+				// IPv6 only subnets
+				if subnet.CidrBlock == nil {
+					continue
+				}
+				networks = append(networks, Network{
+					AccountID:    aws.StringValue(subnet.OwnerId),
+					Region:       region,
+					VpcID:        *subnet.VpcId,
+					SubnetID:     *subnet.SubnetId,
+					CIDR:         *subnet.CidrBlock,
+					AvailableIPs: aws.Int64Value(subnet.AvailableIpAddressCount),
+				})
+			}
+			return true
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	return networks, nil
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"sort"
+)
+
+// AWS reserves the first four and the last IP addresses of every subnet.
+const reservedIPs = 5
+
+// Network is a VPC or subnet CIDR block.
+type Network struct {
+	AccountID    string `json:"account_id"`
+	Region       string `json:"region"`
+	VpcID        string `json:"vpc_id"`
+	SubnetID     string `json:"subnet_id,omitempty"`
+	CIDR         string `json:"cidr"`
+	AvailableIPs int64  `json:"available_ips,omitempty"`
+}
+
+// IsSubnet returns true if the network is a subnet, false for a VPC CIDR block.
+func (n Network) IsSubnet() bool {
+	return n.SubnetID != ""
+}
+
+// SubnetUtilisation is the number of IP addresses used in a subnet.
+type SubnetUtilisation struct {
+	AccountID string  `json:"account_id"`
+	Region    string  `json:"region"`
+	VpcID     string  `json:"vpc_id"`
+	SubnetID  string  `json:"subnet_id"`
+	CIDR      string  `json:"cidr"`
+	Usable    int64   `json:"usable"`
+	Used      int64   `json:"used"`
+	Available int64   `json:"available"`
+	Percent   float64 `json:"percent"`
+}
+
+// Utilisation returns the utilisation of the IPv4 subnets, the most used first.
+func Utilisation(networks []Network) ([]SubnetUtilisation, error) {
+	utilisations := []SubnetUtilisation{}
+	for _, network := range networks {
+		if !network.IsSubnet() {
+			continue
+		}
+
+		_, ipNet, err := net.ParseCIDR(network.CIDR)
+		if err != nil {
+			return nil, err
+		}
+		ones, bits := ipNet.Mask.Size()
+		if bits != 32 {
+			continue
+		}
+
+		usable := int64(1)<<uint(bits-ones) - reservedIPs
+		utilisation := SubnetUtilisation{
+			AccountID: network.AccountID,
+			Region:    network.Region,
+			VpcID:     network.VpcID,
+			SubnetID:  network.SubnetID,
+			CIDR:      network.CIDR,
+			Usable:    usable,
+			Used:      usable - network.AvailableIPs,
+			Available: network.AvailableIPs,
+		}
+		if usable > 0 {
+			utilisation.Percent = math.Round(float64(utilisation.Used)*10000/float64(usable)) / 100
+		}
+		utilisations = append(utilisations, utilisation)
+	}
+
+	sort.SliceStable(utilisations, func(i, j int) bool {
+		if utilisations[i].Percent != utilisations[j].Percent {
+			return utilisations[i].Percent > utilisations[j].Percent
+		}
+		return utilisations[i].SubnetID < utilisations[j].SubnetID
+	})
+
+	return utilisations, nil
+}
+
+type ipRange struct {
+	first  uint32
+	last   uint32
+	prefix int
+}
+
+func parseIPv4Range(cidr string) (*ipRange, error) {
+	_, ipNet, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return nil, err
+	}
+	ip := ipNet.IP.To4()
+	if ip == nil {
+		return nil, nil
+	}
+	ones, _ := ipNet.Mask.Size()
+	first := binary.BigEndian.Uint32(ip)
+	return &ipRange{first, first + uint32(uint64(1)<<uint(32-ones)-1), ones}, nil
+}
+
+func formatCIDR(first uint32, prefix int) string {
+	ip := make(net.IP, 4)
+	binary.BigEndian.PutUint32(ip, first)
+	return fmt.Sprintf("%s/%d", ip, prefix)
+}
+
+// Suggest returns up to count CIDR blocks of the given prefix length inside pool
+// that don't overlap any of the used CIDR blocks, lowest addresses first.
+// IPv6 CIDR blocks in used are ignored.
+func Suggest(pool string, used []string, prefix int, count int) ([]string, error) {
+	poolRange, err := parseIPv4Range(pool)
+	if err != nil {
+		return nil, err
+	}
+	if poolRange == nil {
+		return nil, errors.New("only IPv4 pools are supported")
+	}
+
+	if prefix < poolRange.prefix || prefix > 32 {
+		return nil, fmt.Errorf("prefix /%d doesn't fit in %s", prefix, pool)
+	}
+
+	usedRanges := []ipRange{}
+	for _, cidr := range used {
+		usedRange, err := parseIPv4Range(cidr)
+		if err != nil {
+			return nil, err
+		}
+		if usedRange != nil {
+			usedRanges = append(usedRanges, *usedRange)
+		}
+	}
+	sort.Slice(usedRanges, func(i, j int) bool {
+		return usedRanges[i].first < usedRanges[j].first
+	})
+
+	size := uint64(1) << uint(32-prefix)
+	suggestions := []string{}
+	candidate := uint64(poolRange.first)
+	for candidate+size-1 <= uint64(poolRange.last) && len(suggestions) < count {
+		last := candidate + size - 1
+
+		overlap := false
+		for _, usedRange := range usedRanges {
+			if uint64(usedRange.first) <= last && uint64(usedRange.last) >= candidate {
+				// skip to the first aligned block after the used range
+				candidate = (uint64(usedRange.last)/size + 1) * size
+				overlap = true
+				break
+			}
+		}
+		if overlap {
+			continue
+		}
+
+		suggestions = append(suggestions, formatCIDR(uint32(candidate), prefix))
+		candidate += size
+	}
+
+	return suggestions, nil
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSuggest(t *testing.T) {
+	suggestions, err := Suggest("10.0.0.0/8", []string{"10.0.0.0/16", "10.1.128.0/17", "10.3.0.0/16", "2600:1f18::/56"}, 16, 3)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"10.2.0.0/16", "10.4.0.0/16", "10.5.0.0/16"}, suggestions)
+
+	// used blocks larger than the requested size
+	suggestions, err = Suggest("10.0.0.0/16", []string{"10.0.0.0/17"}, 24, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"10.0.128.0/24", "10.0.129.0/24"}, suggestions)
+
+	// full pool
+	suggestions, err = Suggest("10.0.0.0/24", []string{"10.0.0.0/25", "10.0.0.128/25"}, 26, 3)
+	require.NoError(t, err)
+	assert.Empty(t, suggestions)
+
+	// last block of the address space
+	suggestions, err = Suggest("0.0.0.0/0", []string{"0.0.0.0/1"}, 1, 3)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"128.0.0.0/1"}, suggestions)
+
+	_, err = Suggest("10.0.0.0/16", nil, 8, 1)
+	assert.Error(t, err)
+}
+
+func TestUtilisation(t *testing.T) {
+	utilisation, err := Utilisation([]Network{
+		{VpcID: "vpc-1", CIDR: "10.0.0.0/16"},
+		{VpcID: "vpc-1", SubnetID: "subnet-1", CIDR: "10.0.0.0/24", AvailableIPs: 251},
+		{VpcID: "vpc-1", SubnetID: "subnet-2", CIDR: "10.0.1.0/28", AvailableIPs: 2},
+	})
+	require.NoError(t, err)
+	require.Len(t, utilisation, 2)
+
+	assert.Equal(t, "subnet-2", utilisation[0].SubnetID)
+	assert.Equal(t, int64(11), utilisation[0].Usable)
+	assert.Equal(t, int64(9), utilisation[0].Used)
+	assert.Equal(t, 81.82, utilisation[0].Percent)
+
+	assert.Equal(t, "subnet-1", utilisation[1].SubnetID)
+	assert.Equal(t, int64(0), utilisation[1].Used)
+	assert.Equal(t, float64(0), utilisation[1].Percent)
+}
+
+func TestNetworksFromDump(t *testing.T) {
+	networks := NetworksFromDump([]Resource{
+		{
+			ID: "vpc-1", Service: "ec2", Type: "vpc", AccountID: "123456789012", Region: "eu-west-1",
+			Metadata: map[string]interface{}{
+				"CidrBlock": "10.0.0.0/16",
+				"CidrBlockAssociationSet": []interface{}{
+					map[string]interface{}{"CidrBlock": "10.0.0.0/16", "CidrBlockState": map[string]interface{}{"State": "associated"}},
+					map[string]interface{}{"CidrBlock": "10.1.0.0/16", "CidrBlockState": map[string]interface{}{"State": "disassociated"}},
+				},
+			},
+		},
+		{
+			ID: "subnet-1", Service: "ec2", Type: "subnet", AccountID: "123456789012", Region: "eu-west-1",
+			Metadata: map[string]interface{}{"CidrBlock": "10.0.0.0/24", "VpcId": "vpc-1", "AvailableIpAddressCount": float64(200)},
+		},
+		{ID: "sg-1", Service: "ec2", Type: "security-group"},
+	})
+
+	assert.Equal(t, []Network{
+		{AccountID: "123456789012", Region: "eu-west-1", VpcID: "vpc-1", CIDR: "10.0.0.0/16"},
+		{AccountID: "123456789012", Region: "eu-west-1", VpcID: "vpc-1", SubnetID: "subnet-1", CIDR: "10.0.0.0/24", AvailableIPs: 200},
+	}, networks)
+}
@@ -0,0 +1,259 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"text/tabwriter"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/hamstah/awstools/common"
+	kingpin "gopkg.in/alecthomas/kingpin.v2"
+)
+
+var (
+	launchTemplateID   = kingpin.Flag("launch-template-id", "Id of the launch template to render.").String()
+	launchTemplateName = kingpin.Flag("launch-template-name", "Name of the launch template to render.").String()
+	templateVersion    = kingpin.Flag("template-version", "Version of the launch template, a number, $Default or $Latest.").Default("$Default").String()
+	templateFile       = kingpin.Flag("template-file", "Render this JSON file of launch template data instead of an existing launch template.").ExistingFile()
+	vars               = kingpin.Flag("var", "Value of a {{var:name}} placeholder, name=value. Can be repeated.").StringMap()
+	vpcID              = kingpin.Flag("vpc-id", "VPC of the {{sg:name}} security groups, defaults to the VPC of --subnet-id.").String()
+	subnetID           = kingpin.Flag("subnet-id", "Subnet to validate the launch in, when the launch template doesn't have one.").String()
+	validate           = kingpin.Flag("validate", "Validate the rendered launch template with a dry run of RunInstances.").Default("true").Bool()
+	outputFilename     = kingpin.Flag("output", "Write the rendered launch template data to this file, - for stdout.").Short('o').String()
+	format             = kingpin.Flag("format", "Output format.").Default("text").Enum("text", "json")
+)
+
+type Validation struct {
+	Valid   bool   `json:"valid"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+type Output struct {
+	LaunchTemplateData map[string]interface{} `json:"launch_template_data"`
+	Substitutions      []Substitution         `json:"substitutions"`
+	Validation         *Validation            `json:"validation,omitempty"`
+}
+
+// dropNulls removes the nil fields of the SDK structs serialised to JSON.
+func dropNulls(value interface{}) interface{} {
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		for key, child := range typed {
+			if child == nil {
+				delete(typed, key)
+				continue
+			}
+			typed[key] = dropNulls(child)
+		}
+	case []interface{}:
+		for i, child := range typed {
+			typed[i] = dropNulls(child)
+		}
+	}
+	return value
+}
+
+// loadData returns the launch template data of --template-file or of the
+// version of the launch template.
+func loadData(client *ec2.EC2) (map[string]interface{}, error) {
+	var content []byte
+	var err error
+	if *templateFile != "" {
+		content, err = ioutil.ReadFile(*templateFile)
+	} else {
+		input := &ec2.DescribeLaunchTemplateVersionsInput{Versions: aws.StringSlice([]string{*templateVersion})}
+		if *launchTemplateID != "" {
+			input.LaunchTemplateId = launchTemplateID
+		} else {
+			input.LaunchTemplateName = launchTemplateName
+		}
+
+		var res *ec2.DescribeLaunchTemplateVersionsOutput
+		res, err = client.DescribeLaunchTemplateVersions(input)
+		if err != nil {
+			return nil, err
+		}
+		if len(res.LaunchTemplateVersions) == 0 {
+			return nil, fmt.Errorf("version %s not found", *templateVersion)
+		}
+		content, err = json.Marshal(res.LaunchTemplateVersions[0].LaunchTemplateData)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	data := map[string]interface{}{}
+	err = json.Unmarshal(content, &data)
+	if err != nil {
+		return nil, err
+	}
+	return dropNulls(data).(map[string]interface{}), nil
+}
+
+func resolveSSM(client *ssm.SSM) func(string) (string, error) {
+	return func(name string) (string, error) {
+		res, err := client.GetParameter(&ssm.GetParameterInput{Name: aws.String(name), WithDecryption: aws.Bool(true)})
+		if err != nil {
+			return "", err
+		}
+		return aws.StringValue(res.Parameter.Value), nil
+	}
+}
+
+// resolveSecurityGroup returns the id of the security group with this name
+// in the VPC, the name needs to be unique without a VPC.
+func resolveSecurityGroup(client *ec2.EC2, vpcID string) func(string) (string, error) {
+	return func(name string) (string, error) {
+		filters := []*ec2.Filter{{Name: aws.String("group-name"), Values: aws.StringSlice([]string{name})}}
+		if vpcID != "" {
+			filters = append(filters, &ec2.Filter{Name: aws.String("vpc-id"), Values: aws.StringSlice([]string{vpcID})})
+		}
+
+		ids := []string{}
+		err := client.DescribeSecurityGroupsPages(&ec2.DescribeSecurityGroupsInput{Filters: filters},
+			func(page *ec2.DescribeSecurityGroupsOutput, lastPage bool) bool {
+				for _, group := range page.SecurityGroups {
+					ids = append(ids, aws.StringValue(group.GroupId))
+				}
+				return true
+			})
+		if err != nil {
+			return "", err
+		}
+		if len(ids) == 0 {
+			return "", fmt.Errorf("security group not found")
+		}
+		if len(ids) > 1 {
+			return "", fmt.Errorf("%d security groups have this name, use --vpc-id", len(ids))
+		}
+		return ids[0], nil
+	}
+}
+
+// validateData runs the launch of an instance with the launch template data
+// in dry run mode, the dry run fails with DryRunOperation when the launch
+// would succeed.
+func validateData(client *ec2.EC2, data map[string]interface{}) (*Validation, error) {
+	content, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	// the fields of RunInstances have the same names as the ones of the
+	// launch template data
+	input := &ec2.RunInstancesInput{}
+	err = json.Unmarshal(content, input)
+	if err != nil {
+		return nil, err
+	}
+	input.DryRun = aws.Bool(true)
+	input.MinCount = aws.Int64(1)
+	input.MaxCount = aws.Int64(1)
+
+	if *subnetID != "" {
+		if len(input.NetworkInterfaces) == 0 {
+			input.SubnetId = subnetID
+		}
+		for _, networkInterface := range input.NetworkInterfaces {
+			if aws.Int64Value(networkInterface.DeviceIndex) == 0 && networkInterface.SubnetId == nil {
+				networkInterface.SubnetId = subnetID
+			}
+		}
+	}
+
+	_, err = client.RunInstances(input)
+	awsErr, ok := err.(awserr.Error)
+	if !ok {
+		if err == nil {
+			return nil, fmt.Errorf("the dry run launched an instance")
+		}
+		return nil, err
+	}
+	return &Validation{
+		Valid:   awsErr.Code() == "DryRunOperation",
+		Code:    awsErr.Code(),
+		Message: awsErr.Message(),
+	}, nil
+}
+
+func main() {
+	kingpin.CommandLine.Name = "ec2-launch-template-render"
+	kingpin.CommandLine.Help = "Render a launch template with its placeholders resolved and validate it with a dry run launch."
+	flags := common.HandleFlags()
+
+	if (*launchTemplateID == "" && *launchTemplateName == "") == (*templateFile == "") {
+		common.Fatalln("Use one of --launch-template-id, --launch-template-name or --template-file")
+	}
+
+	sess, conf := common.OpenSession(flags)
+	client := ec2.New(sess, conf)
+
+	data, err := loadData(client)
+	common.FatalOnErrorW(err, "failed to load the launch template")
+
+	groupsVPCID := *vpcID
+	if groupsVPCID == "" && *subnetID != "" {
+		res, err := client.DescribeSubnets(&ec2.DescribeSubnetsInput{SubnetIds: aws.StringSlice([]string{*subnetID})})
+		common.FatalOnErrorW(err, "failed to describe the subnet")
+		if len(res.Subnets) > 0 {
+			groupsVPCID = aws.StringValue(res.Subnets[0].VpcId)
+		}
+	}
+
+	renderer := &Renderer{
+		Vars:                 *vars,
+		ResolveSSM:           resolveSSM(ssm.New(sess, conf)),
+		ResolveSecurityGroup: resolveSecurityGroup(client, groupsVPCID),
+	}
+	output := &Output{}
+	output.LaunchTemplateData, output.Substitutions, err = renderer.Render(data)
+	common.FatalOnErrorW(err, "failed to render the launch template")
+
+	if *validate {
+		output.Validation, err = validateData(client, output.LaunchTemplateData)
+		common.FatalOnErrorW(err, "failed to validate the launch template")
+	}
+
+	if *outputFilename != "" {
+		content, err := json.MarshalIndent(output.LaunchTemplateData, "", "  ")
+		common.FatalOnErrorW(err, "failed to serialise the launch template data")
+		content = append(content, '\n')
+		if *outputFilename == "-" {
+			_, err = os.Stdout.Write(content)
+		} else {
+			err = ioutil.WriteFile(*outputFilename, content, 0644)
+		}
+		common.FatalOnErrorW(err, "failed to write the launch template data")
+	}
+
+	if *format == "json" {
+		content, err := json.MarshalIndent(output, "", "  ")
+		common.FatalOnErrorW(err, "failed to serialise the output")
+		fmt.Println(string(content))
+	} else if *outputFilename != "-" {
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "PLACEHOLDER\tVALUE")
+		for _, substitution := range output.Substitutions {
+			fmt.Fprintf(w, "%s\t%s\n", substitution.Placeholder, substitution.Value)
+		}
+		w.Flush()
+
+		if output.Validation != nil {
+			fmt.Println()
+			if output.Validation.Valid {
+				fmt.Println("Validation succeeded: the dry run launch would have succeeded")
+			} else {
+				fmt.Printf("Validation failed: %s: %s\n", output.Validation.Code, output.Validation.Message)
+			}
+		}
+	}
+
+	if output.Validation != nil && !output.Validation.Valid {
+		os.Exit(1)
+	}
+}
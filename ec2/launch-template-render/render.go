@@ -0,0 +1,144 @@
+package main
+
+import (
+	"encoding/base64"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	PlaceholderVar           = "var"
+	PlaceholderSSM           = "ssm"
+	PlaceholderSecurityGroup = "sg"
+
+	// AMI aliases supported by EC2 in the ImageId of launch templates
+	resolveSSMPrefix = "resolve:ssm:"
+)
+
+var placeholderPattern = regexp.MustCompile(`\{\{\s*(var|ssm|sg):([^}]+?)\s*\}\}`)
+
+// Substitution is a placeholder replaced in the launch template data.
+type Substitution struct {
+	Placeholder string `json:"placeholder"`
+	Value       string `json:"value"`
+}
+
+// Renderer replaces the placeholders of the launch template data:
+// {{var:name}} with the variables, {{ssm:/parameter}} with the value of the
+// SSM parameter, {{sg:name}} with the id of the security group, and the
+// resolve:ssm: AMI aliases of ImageId with the AMI id.
+type Renderer struct {
+	Vars                 map[string]string
+	ResolveSSM           func(name string) (string, error)
+	ResolveSecurityGroup func(name string) (string, error)
+
+	resolved map[string]string
+}
+
+func (r *Renderer) resolve(kind, name string) (string, error) {
+	placeholder := fmt.Sprintf("%s:%s", kind, name)
+	if value, ok := r.resolved[placeholder]; ok {
+		return value, nil
+	}
+
+	var value string
+	var err error
+	switch kind {
+	case PlaceholderVar:
+		var ok bool
+		value, ok = r.Vars[name]
+		if !ok {
+			err = fmt.Errorf("undefined variable %s", name)
+		}
+	case PlaceholderSSM:
+		value, err = r.ResolveSSM(name)
+	case PlaceholderSecurityGroup:
+		value, err = r.ResolveSecurityGroup(name)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s: %s", placeholder, err)
+	}
+
+	r.resolved[placeholder] = value
+	return value, nil
+}
+
+func (r *Renderer) renderString(value string) (string, error) {
+	var err error
+	rendered := placeholderPattern.ReplaceAllStringFunc(value, func(match string) string {
+		parts := placeholderPattern.FindStringSubmatch(match)
+		resolved, resolveErr := r.resolve(parts[1], parts[2])
+		if resolveErr != nil && err == nil {
+			err = resolveErr
+		}
+		return resolved
+	})
+	return rendered, err
+}
+
+func (r *Renderer) render(key string, value interface{}) (interface{}, error) {
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		result := map[string]interface{}{}
+		for childKey, child := range typed {
+			rendered, err := r.render(childKey, child)
+			if err != nil {
+				return nil, err
+			}
+			result[childKey] = rendered
+		}
+		return result, nil
+
+	case []interface{}:
+		result := []interface{}{}
+		for _, child := range typed {
+			rendered, err := r.render(key, child)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, rendered)
+		}
+		return result, nil
+
+	case string:
+		if key == "ImageId" && strings.HasPrefix(typed, resolveSSMPrefix) {
+			return r.resolve(PlaceholderSSM, strings.TrimPrefix(typed, resolveSSMPrefix))
+		}
+
+		// the user data is base64 encoded
+		if key == "UserData" {
+			decoded, err := base64.StdEncoding.DecodeString(typed)
+			if err != nil {
+				return nil, fmt.Errorf("invalid user data: %s", err)
+			}
+			rendered, err := r.renderString(string(decoded))
+			if err != nil {
+				return nil, err
+			}
+			return base64.StdEncoding.EncodeToString([]byte(rendered)), nil
+		}
+		return r.renderString(typed)
+	}
+	return value, nil
+}
+
+// Render returns the launch template data with the placeholders replaced,
+// and the substitutions made sorted by placeholder.
+func (r *Renderer) Render(data map[string]interface{}) (map[string]interface{}, []Substitution, error) {
+	r.resolved = map[string]string{}
+	rendered, err := r.render("", data)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	substitutions := []Substitution{}
+	for placeholder, value := range r.resolved {
+		substitutions = append(substitutions, Substitution{placeholder, value})
+	}
+	sort.Slice(substitutions, func(i, j int) bool {
+		return substitutions[i].Placeholder < substitutions[j].Placeholder
+	})
+	return rendered.(map[string]interface{}), substitutions, nil
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"encoding/base64"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func testRenderer() *Renderer {
+	return &Renderer{
+		Vars: map[string]string{"env": "production"},
+		ResolveSSM: func(name string) (string, error) {
+			if name == "/aws/service/ami-amazon-linux-latest/al2023-ami-kernel-default-x86_64" {
+				return "ami-0123456789abcdef0", nil
+			}
+			return "", fmt.Errorf("ParameterNotFound")
+		},
+		ResolveSecurityGroup: func(name string) (string, error) {
+			return "sg-" + name, nil
+		},
+	}
+}
+
+func TestRender(t *testing.T) {
+	userData := base64.StdEncoding.EncodeToString([]byte("#!/bin/sh\necho {{var:env}} > /etc/environment\n"))
+	data := map[string]interface{}{
+		"ImageId":          "resolve:ssm:/aws/service/ami-amazon-linux-latest/al2023-ami-kernel-default-x86_64",
+		"InstanceType":     "t3.micro",
+		"SecurityGroupIds": []interface{}{"{{sg:web}}", "{{ sg:ssh }}", "sg-0123"},
+		"UserData":         userData,
+		"TagSpecifications": []interface{}{
+			map[string]interface{}{
+				"ResourceType": "instance",
+				"Tags":         []interface{}{map[string]interface{}{"Key": "Name", "Value": "web-{{var:env}}"}},
+			},
+		},
+		"DisableApiTermination": true,
+	}
+
+	rendered, substitutions, err := testRenderer().Render(data)
+	require.NoError(t, err)
+	require.Equal(t, "ami-0123456789abcdef0", rendered["ImageId"])
+	require.Equal(t, []interface{}{"sg-web", "sg-ssh", "sg-0123"}, rendered["SecurityGroupIds"])
+	require.Equal(t, true, rendered["DisableApiTermination"])
+	require.Equal(t, "web-production", rendered["TagSpecifications"].([]interface{})[0].(map[string]interface{})["Tags"].([]interface{})[0].(map[string]interface{})["Value"])
+
+	decoded, err := base64.StdEncoding.DecodeString(rendered["UserData"].(string))
+	require.NoError(t, err)
+	require.Equal(t, "#!/bin/sh\necho production > /etc/environment\n", string(decoded))
+
+	require.Equal(t, []Substitution{
+		{"sg:ssh", "sg-ssh"},
+		{"sg:web", "sg-web"},
+		{"ssm:/aws/service/ami-amazon-linux-latest/al2023-ami-kernel-default-x86_64", "ami-0123456789abcdef0"},
+		{"var:env", "production"},
+	}, substitutions)
+
+	// the input is not modified
+	require.Equal(t, "{{sg:web}}", data["SecurityGroupIds"].([]interface{})[0])
+}
+
+func TestRenderErrors(t *testing.T) {
+	_, _, err := testRenderer().Render(map[string]interface{}{"KeyName": "{{var:key}}"})
+	require.EqualError(t, err, "failed to resolve var:key: undefined variable key")
+
+	_, _, err = testRenderer().Render(map[string]interface{}{"ImageId": "{{ssm:/missing}}"})
+	require.EqualError(t, err, "failed to resolve ssm:/missing: ParameterNotFound")
+
+	_, _, err = testRenderer().Render(map[string]interface{}{"UserData": "not base64!"})
+	require.Error(t, err)
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ecs"
+	"github.com/aws/aws-sdk-go/service/elbv2"
+)
+
+// DeregisterTargets removes the instance from the target group, on every port
+// it is registered with.
+func DeregisterTargets(client *elbv2.ELBV2, targetGroupARN, instanceID string) error {
+	res, err := client.DescribeTargetHealth(&elbv2.DescribeTargetHealthInput{
+		TargetGroupArn: aws.String(targetGroupARN),
+	})
+	if err != nil {
+		return err
+	}
+
+	targets := []*elbv2.TargetDescription{}
+	for _, description := range res.TargetHealthDescriptions {
+		if aws.StringValue(description.Target.Id) == instanceID {
+			targets = append(targets, description.Target)
+		}
+	}
+
+	if len(targets) == 0 {
+		return nil
+	}
+
+	_, err = client.DeregisterTargets(&elbv2.DeregisterTargetsInput{
+		TargetGroupArn: aws.String(targetGroupARN),
+		Targets:        targets,
+	})
+	return err
+}
+
+// DrainContainerInstance sets the ECS container instance running on the
+// instance to DRAINING so its tasks are rescheduled.
+func DrainContainerInstance(client *ecs.ECS, cluster, instanceID string) error {
+	res, err := client.ListContainerInstances(&ecs.ListContainerInstancesInput{
+		Cluster: aws.String(cluster),
+		Filter:  aws.String(fmt.Sprintf("ec2InstanceId == %s", instanceID)),
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(res.ContainerInstanceArns) == 0 {
+		return fmt.Errorf("no container instance for %s in cluster %s", instanceID, cluster)
+	}
+
+	_, err = client.UpdateContainerInstancesState(&ecs.UpdateContainerInstancesStateInput{
+		Cluster:            aws.String(cluster),
+		ContainerInstances: res.ContainerInstanceArns,
+		Status:             aws.String(ecs.ContainerInstanceStatusDraining),
+	})
+	return err
+}
+
+// RunCommand runs command with the notice details in the environment.
+func RunCommand(command, instanceID string, notice *Notice) error {
+	cmd := exec.Command("sh", "-c", command)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Env = append(os.Environ(),
+		fmt.Sprintf("INSTANCE_ID=%s", instanceID),
+		fmt.Sprintf("SPOT_NOTICE=%s", notice.Kind),
+		fmt.Sprintf("SPOT_ACTION=%s", notice.Action),
+		fmt.Sprintf("SPOT_TIME=%s", notice.Time.Format("2006-01-02T15:04:05Z07:00")),
+	)
+	return cmd.Run()
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/ec2metadata"
+	"github.com/aws/aws-sdk-go/service/ecs"
+	"github.com/aws/aws-sdk-go/service/elbv2"
+	"github.com/hamstah/awstools/common"
+	log "github.com/sirupsen/logrus"
+	kingpin "gopkg.in/alecthomas/kingpin.v2"
+)
+
+var (
+	interval         = kingpin.Flag("interval", "Interval between instance metadata checks.").Default("5s").Duration()
+	targetGroupARNs  = kingpin.Flag("target-group-arn", "Deregister the instance from this target group. Can be repeated.").Strings()
+	ecsCluster       = kingpin.Flag("ecs-cluster", "Drain the ECS container instance of this cluster.").String()
+	commands         = kingpin.Flag("exec", "Run this command with sh -c. Can be repeated.").Strings()
+	drainOnRebalance = kingpin.Flag("drain-on-rebalance", "Also drain on rebalance recommendations.").Default("false").Bool()
+	exitAfterDrain   = kingpin.Flag("exit-after-drain", "Exit once the hooks have run.").Default("false").Bool()
+)
+
+func main() {
+	kingpin.CommandLine.Name = "ec2-spot-interruption-drainer"
+	kingpin.CommandLine.Help = "Run drain hooks on spot interruption notices."
+	flags := common.HandleFlags()
+
+	session, conf := common.OpenSession(flags)
+
+	metadata := ec2metadata.New(session)
+	identity, err := metadata.GetInstanceIdentityDocument()
+	common.FatalOnErrorW(err, "failed to get the instance identity")
+
+	if aws.StringValue(conf.Region) == "" {
+		conf.Region = aws.String(identity.Region)
+	}
+
+	elbv2Client := elbv2.New(session, conf)
+	ecsClient := ecs.New(session, conf)
+
+	log.WithField("instance_id", identity.InstanceID).Info("Watching for spot notices")
+
+	drained := false
+	for {
+		notice, err := getNotice(metadata, *drainOnRebalance)
+		if err != nil {
+			log.WithError(err).Error("Failed to check the instance metadata")
+		}
+
+		if ShouldDrain(notice, *drainOnRebalance, drained) {
+			log.WithFields(log.Fields{
+				"notice": notice.Kind,
+				"action": notice.Action,
+				"time":   notice.Time,
+			}).Warn("Spot notice received, draining")
+
+			for _, targetGroupARN := range *targetGroupARNs {
+				err := DeregisterTargets(elbv2Client, targetGroupARN, identity.InstanceID)
+				logHook("deregister", targetGroupARN, err)
+			}
+
+			if *ecsCluster != "" {
+				err := DrainContainerInstance(ecsClient, *ecsCluster, identity.InstanceID)
+				logHook("ecs-drain", *ecsCluster, err)
+			}
+
+			for _, command := range *commands {
+				err := RunCommand(command, identity.InstanceID, notice)
+				logHook("exec", command, err)
+			}
+
+			drained = true
+			if *exitAfterDrain {
+				return
+			}
+		}
+
+		time.Sleep(*interval)
+	}
+}
+
+// getNotice returns the interruption notice first as it supersedes rebalance
+// recommendations, nil when there is no notice.
+func getNotice(metadata *ec2metadata.EC2Metadata, checkRebalance bool) (*Notice, error) {
+	data, err := getMetadata(metadata, "spot/instance-action")
+	if err != nil {
+		return nil, err
+	}
+	if data != nil {
+		return ParseInstanceAction(data)
+	}
+
+	if !checkRebalance {
+		return nil, nil
+	}
+
+	data, err = getMetadata(metadata, "events/recommendations/rebalance")
+	if err != nil || data == nil {
+		return nil, err
+	}
+	return ParseRebalanceRecommendation(data)
+}
+
+// getMetadata returns nil when the path doesn't exist.
+func getMetadata(metadata *ec2metadata.EC2Metadata, path string) ([]byte, error) {
+	value, err := metadata.GetMetadata(path)
+	if err != nil {
+		if requestErr, ok := err.(awserr.RequestFailure); ok && requestErr.StatusCode() == http.StatusNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return []byte(value), nil
+}
+
+func logHook(hook, target string, err error) {
+	entry := log.WithFields(log.Fields{"hook": hook, "target": target})
+	if err != nil {
+		entry.WithError(err).Error("Hook failed")
+		return
+	}
+	entry.Info("Hook succeeded")
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const (
+	NoticeInterruption = "interruption"
+	NoticeRebalance    = "rebalance"
+)
+
+// Notice is a spot interruption or rebalance recommendation from the instance
+// metadata.
+type Notice struct {
+	Kind   string
+	Action string
+	Time   time.Time
+}
+
+// ParseInstanceAction parses spot/instance-action, e.g.
+// {"action": "terminate", "time": "2017-09-18T08:22:00Z"}
+func ParseInstanceAction(data []byte) (*Notice, error) {
+	instanceAction := struct {
+		Action string    `json:"action"`
+		Time   time.Time `json:"time"`
+	}{}
+	err := json.Unmarshal(data, &instanceAction)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Notice{
+		Kind:   NoticeInterruption,
+		Action: instanceAction.Action,
+		Time:   instanceAction.Time,
+	}, nil
+}
+
+// ParseRebalanceRecommendation parses events/recommendations/rebalance, e.g.
+// {"noticeTime": "2020-10-27T08:22:00Z"}
+func ParseRebalanceRecommendation(data []byte) (*Notice, error) {
+	recommendation := struct {
+		NoticeTime time.Time `json:"noticeTime"`
+	}{}
+	err := json.Unmarshal(data, &recommendation)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Notice{
+		Kind: NoticeRebalance,
+		Time: recommendation.NoticeTime,
+	}, nil
+}
+
+// ShouldDrain returns whether the hooks must run for notice. Hooks only run
+// once, rebalance recommendations are ignored unless drainOnRebalance is set.
+func ShouldDrain(notice *Notice, drainOnRebalance bool, drained bool) bool {
+	if notice == nil || drained {
+		return false
+	}
+	if notice.Kind == NoticeRebalance {
+		return drainOnRebalance
+	}
+	// stop and hibernate interruptions need draining too
+	return true
+}
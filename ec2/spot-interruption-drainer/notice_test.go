@@ -0,0 +1,38 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseNotices(t *testing.T) {
+	t.Parallel()
+
+	notice, err := ParseInstanceAction([]byte(`{"action": "terminate", "time": "2017-09-18T08:22:00Z"}`))
+	require.NoError(t, err)
+	require.Equal(t, NoticeInterruption, notice.Kind)
+	require.Equal(t, "terminate", notice.Action)
+	require.Equal(t, time.Date(2017, 9, 18, 8, 22, 0, 0, time.UTC), notice.Time)
+
+	notice, err = ParseRebalanceRecommendation([]byte(`{"noticeTime": "2020-10-27T08:22:00Z"}`))
+	require.NoError(t, err)
+	require.Equal(t, NoticeRebalance, notice.Kind)
+
+	_, err = ParseInstanceAction([]byte(`<html>`))
+	require.Error(t, err)
+}
+
+func TestShouldDrain(t *testing.T) {
+	t.Parallel()
+
+	interruption := &Notice{Kind: NoticeInterruption, Action: "stop"}
+	rebalance := &Notice{Kind: NoticeRebalance}
+
+	require.False(t, ShouldDrain(nil, true, false))
+	require.True(t, ShouldDrain(interruption, false, false))
+	require.False(t, ShouldDrain(interruption, false, true))
+	require.False(t, ShouldDrain(rebalance, false, false))
+	require.True(t, ShouldDrain(rebalance, true, false))
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+type Instance struct {
+	ID               string
+	Name             string
+	State            string
+	AvailabilityZone string
+	PublicIP         string
+	PrivateIP        string
+	LaunchTime       time.Time
+}
+
+// SelectInstance returns the running instance, or the one at index in the
+// running instances sorted by launch time when several have the name.
+func SelectInstance(instances []*Instance, name string, index int) (*Instance, error) {
+	running := []*Instance{}
+	for _, instance := range instances {
+		if instance.State == "running" {
+			running = append(running, instance)
+		}
+	}
+	if len(running) == 0 {
+		return nil, fmt.Errorf("no running instance named %s", name)
+	}
+
+	sort.Slice(running, func(i, j int) bool {
+		if running[i].LaunchTime.Equal(running[j].LaunchTime) {
+			return running[i].ID < running[j].ID
+		}
+		return running[i].LaunchTime.Before(running[j].LaunchTime)
+	})
+
+	if index >= 0 {
+		if index >= len(running) {
+			return nil, fmt.Errorf("no instance at index %d, %d running instances named %s", index, len(running), name)
+		}
+		return running[index], nil
+	}
+
+	if len(running) > 1 {
+		lines := []string{}
+		for i, instance := range running {
+			lines = append(lines, fmt.Sprintf("  %d: %s %s %s", i, instance.ID, instance.PrivateIP, instance.LaunchTime.UTC().Format(time.RFC3339)))
+		}
+		return nil, fmt.Errorf("%d running instances named %s, use --index to pick one:\n%s", len(running), name, strings.Join(lines, "\n"))
+	}
+	return running[0], nil
+}
+
+// Address returns the public IP of the instance, or its private IP if it
+// doesn't have one or private is set.
+func Address(instance *Instance, private bool) (string, error) {
+	if !private && instance.PublicIP != "" {
+		return instance.PublicIP, nil
+	}
+	if instance.PrivateIP == "" {
+		return "", fmt.Errorf("instance %s has no IP address", instance.ID)
+	}
+	return instance.PrivateIP, nil
+}
+
+// SSHArgs returns the arguments of ssh to connect with the key pushed to the
+// instance only.
+func SSHArgs(identityFile, user, address string, port int, extra []string) []string {
+	args := []string{
+		"-i", identityFile,
+		"-o", "IdentitiesOnly=yes",
+	}
+	if port != 22 {
+		args = append(args, "-p", fmt.Sprintf("%d", port))
+	}
+	args = append(args, fmt.Sprintf("%s@%s", user, address))
+	return append(args, extra...)
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSelectInstance(t *testing.T) {
+	now := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	instances := []*Instance{
+		{ID: "i-new", State: "running", PrivateIP: "10.0.0.2", LaunchTime: now},
+		{ID: "i-stopped", State: "stopped", LaunchTime: now.Add(-2 * time.Hour)},
+		{ID: "i-old", State: "running", PrivateIP: "10.0.0.1", LaunchTime: now.Add(-time.Hour)},
+	}
+
+	_, err := SelectInstance(instances, "web", -1)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "2 running instances named web")
+	assert.Contains(t, err.Error(), "0: i-old 10.0.0.1")
+
+	instance, err := SelectInstance(instances, "web", 1)
+	require.NoError(t, err)
+	assert.Equal(t, "i-new", instance.ID)
+
+	_, err = SelectInstance(instances, "web", 2)
+	assert.Error(t, err)
+
+	instance, err = SelectInstance(instances[1:2], "web", -1)
+	assert.Nil(t, instance)
+	assert.EqualError(t, err, "no running instance named web")
+
+	instance, err = SelectInstance(instances[:2], "web", -1)
+	require.NoError(t, err)
+	assert.Equal(t, "i-new", instance.ID)
+}
+
+func TestAddress(t *testing.T) {
+	address, err := Address(&Instance{PublicIP: "1.2.3.4", PrivateIP: "10.0.0.1"}, false)
+	require.NoError(t, err)
+	assert.Equal(t, "1.2.3.4", address)
+
+	address, err = Address(&Instance{PublicIP: "1.2.3.4", PrivateIP: "10.0.0.1"}, true)
+	require.NoError(t, err)
+	assert.Equal(t, "10.0.0.1", address)
+
+	_, err = Address(&Instance{ID: "i-1"}, false)
+	assert.EqualError(t, err, "instance i-1 has no IP address")
+}
+
+func TestSSHArgs(t *testing.T) {
+	assert.Equal(t,
+		[]string{"-i", "/tmp/key", "-o", "IdentitiesOnly=yes", "ec2-user@10.0.0.1"},
+		SSHArgs("/tmp/key", "ec2-user", "10.0.0.1", 22, nil),
+	)
+	assert.Equal(t,
+		[]string{"-i", "/tmp/key", "-o", "IdentitiesOnly=yes", "-p", "2222", "ubuntu@10.0.0.1", "-L", "5432:db:5432"},
+		SSHArgs("/tmp/key", "ubuntu", "10.0.0.1", 2222, []string{"-L", "5432:db:5432"}),
+	)
+}
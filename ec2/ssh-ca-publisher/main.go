@@ -0,0 +1,171 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/ec2instanceconnect"
+	"github.com/hamstah/awstools/common"
+	kingpin "gopkg.in/alecthomas/kingpin.v2"
+)
+
+var (
+	name         = kingpin.Flag("name", "Name tag of the instance.").String()
+	instanceID   = kingpin.Flag("instance-id", "ID of the instance, instead of --name.").String()
+	index        = kingpin.Flag("index", "Index of the instance when several running instances have the name, by launch time.").Default("-1").Int()
+	user         = kingpin.Flag("user", "OS user to push the key for and to connect as.").Default("ec2-user").String()
+	identityFile = kingpin.Flag("identity-file", "Private key to use, the public key is read from the file with .pub appended. Defaults to a key generated for the connection.").String()
+	privateIP    = kingpin.Flag("private-ip", "Connect to the private IP even if the instance has a public one.").Default("false").Bool()
+	port         = kingpin.Flag("port", "SSH port.").Default("22").Int()
+	sshPath      = kingpin.Flag("ssh", "ssh command.").Default("ssh").String()
+	dryRun       = kingpin.Flag("dry-run", "Print the ssh command without pushing the key.").Default("false").Bool()
+	sshArgs      = kingpin.Arg("ssh-args", "Arguments passed to ssh, prefix with -- to pass flags.").Strings()
+)
+
+func findInstances(client *ec2.EC2) ([]*Instance, error) {
+	input := &ec2.DescribeInstancesInput{}
+	if *instanceID != "" {
+		input.InstanceIds = aws.StringSlice([]string{*instanceID})
+	} else {
+		input.Filters = []*ec2.Filter{{
+			Name:   aws.String("tag:Name"),
+			Values: aws.StringSlice([]string{*name}),
+		}}
+	}
+
+	instances := []*Instance{}
+	err := client.DescribeInstancesPages(input,
+		func(page *ec2.DescribeInstancesOutput, lastPage bool) bool {
+			for _, reservation := range page.Reservations {
+				for _, instance := range reservation.Instances {
+					result := &Instance{
+						ID:         aws.StringValue(instance.InstanceId),
+						PublicIP:   aws.StringValue(instance.PublicIpAddress),
+						PrivateIP:  aws.StringValue(instance.PrivateIpAddress),
+						LaunchTime: aws.TimeValue(instance.LaunchTime),
+					}
+					if instance.State != nil {
+						result.State = aws.StringValue(instance.State.Name)
+					}
+					if instance.Placement != nil {
+						result.AvailabilityZone = aws.StringValue(instance.Placement.AvailabilityZone)
+					}
+					for _, tag := range instance.Tags {
+						if aws.StringValue(tag.Key) == "Name" {
+							result.Name = aws.StringValue(tag.Value)
+						}
+					}
+					instances = append(instances, result)
+				}
+			}
+			return true
+		})
+	return instances, err
+}
+
+// generateKey creates a key pair with ssh-keygen in a temporary directory,
+// removed by the returned function.
+func generateKey() (string, func(), error) {
+	dir, err := ioutil.TempDir("", "ssh-ca-publisher")
+	if err != nil {
+		return "", nil, err
+	}
+	cleanup := func() { os.RemoveAll(dir) }
+
+	keyFile := filepath.Join(dir, "id_ed25519")
+	output, err := exec.Command("ssh-keygen", "-q", "-t", "ed25519", "-N", "", "-C", "ssh-ca-publisher", "-f", keyFile).CombinedOutput()
+	if err != nil {
+		cleanup()
+		return "", nil, fmt.Errorf("ssh-keygen failed: %s %s", err, strings.TrimSpace(string(output)))
+	}
+	return keyFile, cleanup, nil
+}
+
+// connect pushes the key and runs ssh, it returns the exit code of ssh.
+func connect(sess *session.Session, conf *aws.Config, instance *Instance, address string) int {
+	keyFile := *identityFile
+	if keyFile == "" {
+		generated, cleanup, err := generateKey()
+		common.FatalOnErrorW(err, "failed to generate the key")
+		defer cleanup()
+		keyFile = generated
+	}
+
+	// errors are returned instead of exiting for the generated key to be
+	// removed
+	publicKey, err := ioutil.ReadFile(keyFile + ".pub")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to read the public key: %s\n", err)
+		return 1
+	}
+
+	// the key is only valid for 60 seconds, long enough to connect
+	res, err := ec2instanceconnect.New(sess, conf).SendSSHPublicKey(&ec2instanceconnect.SendSSHPublicKeyInput{
+		InstanceId:       aws.String(instance.ID),
+		InstanceOSUser:   user,
+		SSHPublicKey:     aws.String(strings.TrimSpace(string(publicKey))),
+		AvailabilityZone: aws.String(instance.AvailabilityZone),
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to push the key to %s: %s\n", instance.ID, err)
+		return 1
+	}
+	if !aws.BoolValue(res.Success) {
+		fmt.Fprintf(os.Stderr, "failed to push the key to %s, request %s\n", instance.ID, aws.StringValue(res.RequestId))
+		return 1
+	}
+
+	p := exec.Command(*sshPath, SSHArgs(keyFile, *user, address, *port, *sshArgs)...)
+	p.Stdin = os.Stdin
+	p.Stderr = os.Stderr
+	p.Stdout = os.Stdout
+	err = p.Run()
+	if p.ProcessState == nil {
+		fmt.Fprintf(os.Stderr, "failed to run %s: %s\n", *sshPath, err)
+		return 1
+	}
+	return p.ProcessState.ExitCode()
+}
+
+func main() {
+	kingpin.CommandLine.Name = "ec2-ssh-ca-publisher"
+	kingpin.CommandLine.Help = "Push a short-lived SSH key to an instance with EC2 Instance Connect and connect to it."
+	flags := common.HandleFlags()
+
+	if (*name == "") == (*instanceID == "") {
+		common.Fatalln("Use one of --name or --instance-id")
+	}
+
+	session, conf := common.OpenSession(flags)
+
+	instances, err := findInstances(ec2.New(session, conf))
+	common.FatalOnErrorW(err, "failed to describe the instances")
+
+	target := *name
+	if target == "" {
+		target = *instanceID
+	}
+	instance, err := SelectInstance(instances, target, *index)
+	common.FatalOnError(err)
+
+	address, err := Address(instance, *privateIP)
+	common.FatalOnError(err)
+
+	if *dryRun {
+		keyFile := *identityFile
+		if keyFile == "" {
+			keyFile = "<generated key>"
+		}
+		fmt.Println(*sshPath, strings.Join(SSHArgs(keyFile, *user, address, *port, *sshArgs), " "))
+		return
+	}
+
+	os.Exit(connect(session, conf, instance, address))
+}
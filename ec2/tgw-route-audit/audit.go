@@ -0,0 +1,164 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"sort"
+)
+
+const (
+	SeverityHigh   = "high"
+	SeverityMedium = "medium"
+	SeverityLow    = "low"
+)
+
+type Attachment struct {
+	ID                     string   `json:"id"`
+	TransitGatewayID       string   `json:"transit_gateway_id"`
+	ResourceType           string   `json:"resource_type"`
+	ResourceID             string   `json:"resource_id"`
+	AssociatedRouteTableID string   `json:"associated_route_table_id"`
+	CIDRs                  []string `json:"cidrs"`
+}
+
+type Route struct {
+	CIDR          string   `json:"cidr"`
+	State         string   `json:"state"`
+	Type          string   `json:"type"`
+	AttachmentIDs []string `json:"attachment_ids"`
+}
+
+type RouteTable struct {
+	ID               string   `json:"id"`
+	TransitGatewayID string   `json:"transit_gateway_id"`
+	Routes           []Route  `json:"routes"`
+	Propagations     []string `json:"propagations"`
+}
+
+type Finding struct {
+	Severity         string `json:"severity"`
+	Kind             string `json:"kind"`
+	TransitGatewayID string `json:"transit_gateway_id"`
+	Resource         string `json:"resource"`
+	Details          string `json:"details"`
+}
+
+// Audit flags blackhole routes, attachments which can reach each other in one
+// direction only and attached VPCs with overlapping CIDRs.
+func Audit(attachments []Attachment, routeTables []RouteTable) []Finding {
+	findings := []Finding{}
+
+	for _, routeTable := range routeTables {
+		for _, route := range routeTable.Routes {
+			if route.State == "blackhole" {
+				findings = append(findings, Finding{
+					Severity:         SeverityMedium,
+					Kind:             "blackhole-route",
+					TransitGatewayID: routeTable.TransitGatewayID,
+					Resource:         routeTable.ID,
+					Details:          fmt.Sprintf("%s route to %s is a blackhole", route.Type, route.CIDR),
+				})
+			}
+		}
+	}
+
+	routeTablesByID := map[string]RouteTable{}
+	for _, routeTable := range routeTables {
+		routeTablesByID[routeTable.ID] = routeTable
+	}
+
+	for i, a := range attachments {
+		for _, b := range attachments[i+1:] {
+			if a.TransitGatewayID != b.TransitGatewayID {
+				continue
+			}
+
+			if a.AssociatedRouteTableID != "" && b.AssociatedRouteTableID != "" {
+				aToB := routes(routeTablesByID[a.AssociatedRouteTableID], b.ID)
+				bToA := routes(routeTablesByID[b.AssociatedRouteTableID], a.ID)
+				if aToB != bToA {
+					from, to := a, b
+					if bToA {
+						from, to = b, a
+					}
+					findings = append(findings, Finding{
+						Severity:         SeverityHigh,
+						Kind:             "asymmetric-routing",
+						TransitGatewayID: a.TransitGatewayID,
+						Resource:         fmt.Sprintf("%s,%s", from.ID, to.ID),
+						Details: fmt.Sprintf("%s (%s) routes to %s (%s) but there is no route back through %s",
+							from.ID, from.ResourceID, to.ID, to.ResourceID, to.AssociatedRouteTableID),
+					})
+				}
+			}
+
+			for _, overlap := range overlappingCIDRs(a.CIDRs, b.CIDRs) {
+				findings = append(findings, Finding{
+					Severity:         SeverityHigh,
+					Kind:             "overlapping-cidrs",
+					TransitGatewayID: a.TransitGatewayID,
+					Resource:         fmt.Sprintf("%s,%s", a.ID, b.ID),
+					Details:          fmt.Sprintf("%s (%s) and %s (%s) overlap: %s", a.ResourceID, a.ID, b.ResourceID, b.ID, overlap),
+				})
+			}
+		}
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool {
+		return severityRank(findings[i].Severity) > severityRank(findings[j].Severity)
+	})
+
+	return findings
+}
+
+func severityRank(severity string) int {
+	switch severity {
+	case SeverityHigh:
+		return 2
+	case SeverityMedium:
+		return 1
+	}
+	return 0
+}
+
+// routes returns whether the route table has an active route, or a
+// propagation, to the attachment.
+func routes(routeTable RouteTable, attachmentID string) bool {
+	for _, propagation := range routeTable.Propagations {
+		if propagation == attachmentID {
+			return true
+		}
+	}
+
+	for _, route := range routeTable.Routes {
+		if route.State != "active" {
+			continue
+		}
+		for _, id := range route.AttachmentIDs {
+			if id == attachmentID {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func overlappingCIDRs(a, b []string) []string {
+	overlaps := []string{}
+	for _, cidrA := range a {
+		_, networkA, err := net.ParseCIDR(cidrA)
+		if err != nil {
+			continue
+		}
+		for _, cidrB := range b {
+			_, networkB, err := net.ParseCIDR(cidrB)
+			if err != nil {
+				continue
+			}
+			if networkA.Contains(networkB.IP) || networkB.Contains(networkA.IP) {
+				overlaps = append(overlaps, fmt.Sprintf("%s/%s", cidrA, cidrB))
+			}
+		}
+	}
+	return overlaps
+}
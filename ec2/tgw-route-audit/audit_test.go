@@ -0,0 +1,58 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAudit(t *testing.T) {
+	t.Parallel()
+
+	attachments := []Attachment{
+		{ID: "tgw-attach-a", TransitGatewayID: "tgw-1", ResourceID: "vpc-a", AssociatedRouteTableID: "tgw-rtb-1", CIDRs: []string{"10.0.0.0/16"}},
+		{ID: "tgw-attach-b", TransitGatewayID: "tgw-1", ResourceID: "vpc-b", AssociatedRouteTableID: "tgw-rtb-2", CIDRs: []string{"10.1.0.0/16"}},
+		{ID: "tgw-attach-c", TransitGatewayID: "tgw-1", ResourceID: "vpc-c", AssociatedRouteTableID: "tgw-rtb-2", CIDRs: []string{"10.0.128.0/24"}},
+		{ID: "tgw-attach-d", TransitGatewayID: "tgw-2", ResourceID: "vpc-d", CIDRs: []string{"10.0.0.0/16"}},
+	}
+
+	routeTables := []RouteTable{
+		{
+			ID:               "tgw-rtb-1",
+			TransitGatewayID: "tgw-1",
+			Propagations:     []string{"tgw-attach-b", "tgw-attach-c"},
+			Routes: []Route{
+				{CIDR: "10.1.0.0/16", State: "active", Type: "propagated", AttachmentIDs: []string{"tgw-attach-b"}},
+				{CIDR: "192.168.0.0/16", State: "blackhole", Type: "static"},
+			},
+		},
+		{
+			ID:               "tgw-rtb-2",
+			TransitGatewayID: "tgw-1",
+			Routes: []Route{
+				{CIDR: "10.0.0.0/16", State: "active", Type: "static", AttachmentIDs: []string{"tgw-attach-a"}},
+			},
+		},
+	}
+
+	findings := Audit(attachments, routeTables)
+
+	kinds := map[string][]string{}
+	for _, finding := range findings {
+		kinds[finding.Kind] = append(kinds[finding.Kind], finding.Resource)
+	}
+
+	// a <-> b and a <-> c are routed both ways, b and c share a route table without a route between them
+	require.Empty(t, kinds["asymmetric-routing"])
+	require.Equal(t, []string{"tgw-attach-a,tgw-attach-c"}, kinds["overlapping-cidrs"])
+	require.Equal(t, []string{"tgw-rtb-1"}, kinds["blackhole-route"])
+	require.Equal(t, SeverityMedium, findings[len(findings)-1].Severity)
+
+	routeTables[1].Routes = nil
+	findings = Audit(attachments, routeTables)
+	kinds = map[string][]string{}
+	for _, finding := range findings {
+		kinds[finding.Kind] = append(kinds[finding.Kind], finding.Resource)
+	}
+	require.Equal(t, []string{"tgw-attach-a,tgw-attach-b", "tgw-attach-a,tgw-attach-c"}, kinds["asymmetric-routing"])
+}
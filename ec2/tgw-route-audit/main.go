@@ -0,0 +1,211 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/hamstah/awstools/common"
+	log "github.com/sirupsen/logrus"
+	kingpin "gopkg.in/alecthomas/kingpin.v2"
+)
+
+var (
+	transitGatewayIDs = kingpin.Flag("transit-gateway-id", "Only audit this transit gateway. Can be repeated.").Strings()
+	format            = kingpin.Flag("format", "Output format, json includes the attachments and route tables.").Default("text").Enum("text", "json")
+)
+
+type Output struct {
+	Attachments []Attachment `json:"attachments"`
+	RouteTables []RouteTable `json:"route_tables"`
+	Findings    []Finding    `json:"findings"`
+}
+
+func main() {
+	kingpin.CommandLine.Name = "ec2-tgw-route-audit"
+	kingpin.CommandLine.Help = "Audit Transit Gateway route tables and attachments."
+	flags := common.HandleFlags()
+
+	session, conf := common.OpenSession(flags)
+	client := ec2.New(session, conf)
+
+	attachments, err := listAttachments(client)
+	common.FatalOnErrorW(err, "failed to list the attachments")
+
+	routeTables, err := listRouteTables(client)
+	common.FatalOnErrorW(err, "failed to list the route tables")
+
+	output := Output{
+		Attachments: attachments,
+		RouteTables: routeTables,
+		Findings:    Audit(attachments, routeTables),
+	}
+
+	if *format == "json" {
+		data, err := json.MarshalIndent(output, "", "  ")
+		common.FatalOnErrorW(err, "failed to serialise the output")
+		fmt.Println(string(data))
+		return
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(writer, "SEVERITY\tKIND\tTRANSIT GATEWAY\tRESOURCE\tDETAILS")
+	for _, finding := range output.Findings {
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%s\n",
+			finding.Severity,
+			finding.Kind,
+			finding.TransitGatewayID,
+			finding.Resource,
+			finding.Details,
+		)
+	}
+	writer.Flush()
+}
+
+func transitGatewayFilters() []*ec2.Filter {
+	if len(*transitGatewayIDs) == 0 {
+		return nil
+	}
+	return []*ec2.Filter{
+		{
+			Name:   aws.String("transit-gateway-id"),
+			Values: aws.StringSlice(*transitGatewayIDs),
+		},
+	}
+}
+
+func listAttachments(client *ec2.EC2) ([]Attachment, error) {
+	attachments := []Attachment{}
+	vpcAttachments := map[string][]int{}
+
+	err := client.DescribeTransitGatewayAttachmentsPages(&ec2.DescribeTransitGatewayAttachmentsInput{Filters: transitGatewayFilters()},
+		func(page *ec2.DescribeTransitGatewayAttachmentsOutput, lastPage bool) bool {
+			for _, transitGatewayAttachment := range page.TransitGatewayAttachments {
+				state := aws.StringValue(transitGatewayAttachment.State)
+				if state == ec2.TransitGatewayAttachmentStateDeleted || state == ec2.TransitGatewayAttachmentStateDeleting {
+					continue
+				}
+
+				attachment := Attachment{
+					ID:               *transitGatewayAttachment.TransitGatewayAttachmentId,
+					TransitGatewayID: *transitGatewayAttachment.TransitGatewayId,
+					ResourceType:     aws.StringValue(transitGatewayAttachment.ResourceType),
+					ResourceID:       aws.StringValue(transitGatewayAttachment.ResourceId),
+					CIDRs:            []string{},
+				}
+				if transitGatewayAttachment.Association != nil {
+					attachment.AssociatedRouteTableID = aws.StringValue(transitGatewayAttachment.Association.TransitGatewayRouteTableId)
+				}
+
+				if attachment.ResourceType == ec2.TransitGatewayAttachmentResourceTypeVpc {
+					vpcAttachments[attachment.ResourceID] = append(vpcAttachments[attachment.ResourceID], len(attachments))
+				}
+				attachments = append(attachments, attachment)
+			}
+			return true
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	for vpcID, indexes := range vpcAttachments {
+		res, err := client.DescribeVpcs(&ec2.DescribeVpcsInput{VpcIds: aws.StringSlice([]string{vpcID})})
+		if err != nil {
+			// VPCs shared from other accounts can't be described
+			log.WithError(err).WithField("vpc_id", vpcID).Warn("Failed to describe the VPC, overlapping CIDRs won't be checked")
+			continue
+		}
+
+		cidrs := []string{}
+		for _, vpc := range res.Vpcs {
+			for _, association := range vpc.CidrBlockAssociationSet {
+				if aws.StringValue(association.CidrBlockState.State) == ec2.VpcCidrBlockStateCodeAssociated {
+					cidrs = append(cidrs, *association.CidrBlock)
+				}
+			}
+		}
+
+		for _, i := range indexes {
+			attachments[i].CIDRs = cidrs
+		}
+	}
+
+	return attachments, nil
+}
+
+func listRouteTables(client *ec2.EC2) ([]RouteTable, error) {
+	routeTables := []RouteTable{}
+	err := client.DescribeTransitGatewayRouteTablesPages(&ec2.DescribeTransitGatewayRouteTablesInput{Filters: transitGatewayFilters()},
+		func(page *ec2.DescribeTransitGatewayRouteTablesOutput, lastPage bool) bool {
+			for _, routeTable := range page.TransitGatewayRouteTables {
+				if aws.StringValue(routeTable.State) != ec2.TransitGatewayRouteTableStateAvailable {
+					continue
+				}
+				routeTables = append(routeTables, RouteTable{
+					ID:               *routeTable.TransitGatewayRouteTableId,
+					TransitGatewayID: *routeTable.TransitGatewayId,
+				})
+			}
+			return true
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range routeTables {
+		routeTableID := aws.String(routeTables[i].ID)
+
+		res, err := client.SearchTransitGatewayRoutes(&ec2.SearchTransitGatewayRoutesInput{
+			TransitGatewayRouteTableId: routeTableID,
+			Filters: []*ec2.Filter{
+				{
+					Name:   aws.String("state"),
+					Values: aws.StringSlice([]string{ec2.TransitGatewayRouteStateActive, ec2.TransitGatewayRouteStateBlackhole}),
+				},
+			},
+		})
+		if err != nil {
+			return nil, err
+		}
+		if aws.BoolValue(res.AdditionalRoutesAvailable) {
+			log.WithField("route_table_id", routeTables[i].ID).Warn("Route table has more routes than returned by the API")
+		}
+
+		for _, transitGatewayRoute := range res.Routes {
+			route := Route{
+				CIDR:          aws.StringValue(transitGatewayRoute.DestinationCidrBlock),
+				State:         aws.StringValue(transitGatewayRoute.State),
+				Type:          aws.StringValue(transitGatewayRoute.Type),
+				AttachmentIDs: []string{},
+			}
+			if route.CIDR == "" {
+				route.CIDR = aws.StringValue(transitGatewayRoute.PrefixListId)
+			}
+			for _, attachment := range transitGatewayRoute.TransitGatewayAttachments {
+				route.AttachmentIDs = append(route.AttachmentIDs, aws.StringValue(attachment.TransitGatewayAttachmentId))
+			}
+			routeTables[i].Routes = append(routeTables[i].Routes, route)
+		}
+
+		routeTables[i].Propagations = []string{}
+		err = client.GetTransitGatewayRouteTablePropagationsPages(&ec2.GetTransitGatewayRouteTablePropagationsInput{
+			TransitGatewayRouteTableId: routeTableID,
+		},
+			func(page *ec2.GetTransitGatewayRouteTablePropagationsOutput, lastPage bool) bool {
+				for _, propagation := range page.TransitGatewayRouteTablePropagations {
+					if aws.StringValue(propagation.State) == ec2.TransitGatewayPropagationStateEnabled {
+						routeTables[i].Propagations = append(routeTables[i].Propagations, *propagation.TransitGatewayAttachmentId)
+					}
+				}
+				return true
+			})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return routeTables, nil
+}
@@ -1,7 +1,13 @@
 package main
 
 import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/service/ecs"
 	"github.com/hamstah/awstools/common"
 	kingpin "gopkg.in/alecthomas/kingpin.v2"
@@ -10,6 +16,9 @@ import (
 var (
 	taskDefinition = kingpin.Flag("task-definition", "ECS task definition").Required().String()
 	cluster        = kingpin.Flag("cluster", "ECS cluster").Required().String()
+	startedBy      = kingpin.Flag("started-by", "Value of startedBy for the task, up to 128 characters.").String()
+	clientToken    = kingpin.Flag("client-token", "Idempotency token, running again with the same token doesn't start another task.").String()
+	skipIfRunning  = kingpin.Flag("skip-if-running", "Don't start the task if a task with the same --started-by is already running in the cluster.").Default("false").Bool()
 )
 
 func main() {
@@ -17,14 +26,79 @@ func main() {
 	kingpin.CommandLine.Help = "Run a task on ECS."
 	flags := common.HandleFlags()
 
+	if *skipIfRunning && *startedBy == "" {
+		common.Fatalln("--skip-if-running requires --started-by")
+	}
+
 	session, conf := common.OpenSession(flags)
 
 	ecsClient := ecs.New(session, conf)
 
-	_, err := ecsClient.RunTask(&ecs.RunTaskInput{
+	if *skipIfRunning {
+		// desired status RUNNING includes the pending tasks
+		res, err := ecsClient.ListTasks(&ecs.ListTasksInput{
+			Cluster:       cluster,
+			StartedBy:     startedBy,
+			DesiredStatus: aws.String(ecs.DesiredStatusRunning),
+		})
+		common.FatalOnErrorW(err, "failed to list the running tasks")
+
+		if len(res.TaskArns) > 0 {
+			fmt.Fprintf(os.Stderr, "Task started by %s already running, not starting a new one: %s\n", *startedBy, *res.TaskArns[0])
+			return
+		}
+	}
+
+	input := &ecs.RunTaskInput{
 		TaskDefinition: taskDefinition,
 		Cluster:        cluster,
 		Count:          aws.Int64(1),
-	})
-	common.FatalOnError(err)
+	}
+	if *startedBy != "" {
+		input.StartedBy = startedBy
+	}
+
+	req, output := ecsClient.RunTaskRequest(input)
+	if *clientToken != "" {
+		req.Handlers.Build.PushBack(addClientToken(*clientToken))
+	}
+	common.FatalOnError(req.Send())
+
+	for _, failure := range output.Failures {
+		common.Fatalln(fmt.Sprintf("failed to run the task: %s %s", aws.StringValue(failure.Reason), aws.StringValue(failure.Detail)))
+	}
+	for _, task := range output.Tasks {
+		fmt.Println(*task.TaskArn)
+	}
+}
+
+// addClientToken adds clientToken to the RunTask request body, it is not part
+// of RunTaskInput in the aws-sdk-go version used.
+func addClientToken(token string) func(*request.Request) {
+	return func(r *request.Request) {
+		if r.Error != nil {
+			return
+		}
+
+		data, err := ioutil.ReadAll(r.GetBody())
+		if err != nil {
+			r.Error = err
+			return
+		}
+
+		body := map[string]interface{}{}
+		err = json.Unmarshal(data, &body)
+		if err != nil {
+			r.Error = err
+			return
+		}
+		body["clientToken"] = token
+
+		data, err = json.Marshal(body)
+		if err != nil {
+			r.Error = err
+			return
+		}
+		r.SetBufferBody(data)
+	}
 }
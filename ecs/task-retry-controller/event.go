@@ -0,0 +1,127 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+const maxStartedByLength = 128
+
+// TaskStateChange is an "ECS Task State Change" EventBridge event.
+type TaskStateChange struct {
+	DetailType string     `json:"detail-type"`
+	Source     string     `json:"source"`
+	Detail     TaskDetail `json:"detail"`
+}
+
+type TaskDetail struct {
+	TaskARN           string          `json:"taskArn"`
+	ClusterARN        string          `json:"clusterArn"`
+	TaskDefinitionARN string          `json:"taskDefinitionArn"`
+	LastStatus        string          `json:"lastStatus"`
+	StartedBy         string          `json:"startedBy"`
+	Group             string          `json:"group"`
+	LaunchType        string          `json:"launchType"`
+	PlatformVersion   string          `json:"platformVersion"`
+	StopCode          string          `json:"stopCode"`
+	StoppedReason     string          `json:"stoppedReason"`
+	Containers        []Container     `json:"containers"`
+	Overrides         json.RawMessage `json:"overrides"`
+}
+
+type Container struct {
+	Name     string `json:"name"`
+	ExitCode *int   `json:"exitCode"`
+	Reason   string `json:"reason"`
+}
+
+// ParseEvent parses an EventBridge event, it returns nil for other events.
+func ParseEvent(data []byte) (*TaskStateChange, error) {
+	event := &TaskStateChange{}
+	err := json.Unmarshal(data, event)
+	if err != nil {
+		return nil, err
+	}
+	if event.Source != "aws.ecs" || event.DetailType != "ECS Task State Change" {
+		return nil, nil
+	}
+	return event, nil
+}
+
+// Failed returns true if the task stopped without running or if one of its
+// containers exited with a non zero code, and the reason.
+func (d TaskDetail) Failed() (bool, string) {
+	if d.LastStatus != "STOPPED" {
+		return false, ""
+	}
+
+	if d.StopCode == "TaskFailedToStart" {
+		return true, d.StoppedReason
+	}
+
+	for _, container := range d.Containers {
+		if container.ExitCode == nil {
+			continue
+		}
+		if *container.ExitCode != 0 {
+			return true, fmt.Sprintf("container %s exited with %d", container.Name, *container.ExitCode)
+		}
+	}
+
+	// stopped before any container exited, e.g. out of memory or spot interruption
+	for _, container := range d.Containers {
+		if container.ExitCode == nil {
+			reason := d.StoppedReason
+			if container.Reason != "" {
+				reason = container.Reason
+			}
+			return true, reason
+		}
+	}
+
+	return false, ""
+}
+
+var retryStartedByPattern = regexp.MustCompile(`^task-retry-(\d+):(.*)$`)
+
+// ParseStartedBy returns the retry attempt and the original startedBy of a task.
+func ParseStartedBy(startedBy string) (int, string) {
+	matches := retryStartedByPattern.FindStringSubmatch(startedBy)
+	if matches == nil {
+		return 0, startedBy
+	}
+	attempt, err := strconv.Atoi(matches[1])
+	if err != nil {
+		return 0, startedBy
+	}
+	return attempt, matches[2]
+}
+
+// RetryStartedBy returns the startedBy of a retry, keeping the attempt number
+// and as much of the original startedBy as fits.
+func RetryStartedBy(attempt int, origin string) string {
+	startedBy := fmt.Sprintf("task-retry-%d:%s", attempt, origin)
+	if len(startedBy) > maxStartedByLength {
+		startedBy = startedBy[:maxStartedByLength]
+	}
+	return startedBy
+}
+
+// Backoff returns the delay before a retry attempt (starting at 1), doubling
+// from base up to max.
+func Backoff(attempt int, base time.Duration, max time.Duration) time.Duration {
+	delay := base
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+		if delay >= max {
+			return max
+		}
+	}
+	if delay > max {
+		return max
+	}
+	return delay
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const stoppedEvent = `{
+	"version": "0",
+	"detail-type": "ECS Task State Change",
+	"source": "aws.ecs",
+	"detail": {
+		"clusterArn": "arn:aws:ecs:eu-west-1:123456789012:cluster/jobs",
+		"taskArn": "arn:aws:ecs:eu-west-1:123456789012:task/jobs/0123456789abcdef",
+		"taskDefinitionArn": "arn:aws:ecs:eu-west-1:123456789012:task-definition/report:3",
+		"lastStatus": "STOPPED",
+		"startedBy": "events-rule/nightly-report",
+		"launchType": "FARGATE",
+		"stopCode": "EssentialContainerExited",
+		"stoppedReason": "Essential container in task exited",
+		"containers": [{"name": "report", "exitCode": 2}],
+		"overrides": {"containerOverrides": [{"name": "report", "command": ["run"]}]}
+	}
+}`
+
+func TestParseEvent(t *testing.T) {
+	event, err := ParseEvent([]byte(stoppedEvent))
+	require.NoError(t, err)
+	require.NotNil(t, event)
+	assert.Equal(t, "events-rule/nightly-report", event.Detail.StartedBy)
+
+	failed, reason := event.Detail.Failed()
+	assert.True(t, failed)
+	assert.Equal(t, "container report exited with 2", reason)
+
+	event, err = ParseEvent([]byte(`{"detail-type": "ECS Container Instance State Change", "source": "aws.ecs"}`))
+	require.NoError(t, err)
+	assert.Nil(t, event)
+}
+
+func TestFailed(t *testing.T) {
+	zero := 0
+
+	failed, _ := TaskDetail{LastStatus: "RUNNING"}.Failed()
+	assert.False(t, failed)
+
+	failed, _ = TaskDetail{LastStatus: "STOPPED", Containers: []Container{{Name: "app", ExitCode: &zero}}}.Failed()
+	assert.False(t, failed)
+
+	failed, reason := TaskDetail{LastStatus: "STOPPED", StopCode: "TaskFailedToStart", StoppedReason: "CannotPullContainerError"}.Failed()
+	assert.True(t, failed)
+	assert.Equal(t, "CannotPullContainerError", reason)
+
+	failed, reason = TaskDetail{LastStatus: "STOPPED", StoppedReason: "Task stopped", Containers: []Container{{Name: "app", Reason: "OutOfMemoryError"}}}.Failed()
+	assert.True(t, failed)
+	assert.Equal(t, "OutOfMemoryError", reason)
+}
+
+func TestStartedBy(t *testing.T) {
+	attempt, origin := ParseStartedBy("events-rule/nightly-report")
+	assert.Equal(t, 0, attempt)
+	assert.Equal(t, "events-rule/nightly-report", origin)
+
+	startedBy := RetryStartedBy(2, origin)
+	assert.Equal(t, "task-retry-2:events-rule/nightly-report", startedBy)
+
+	attempt, origin = ParseStartedBy(startedBy)
+	assert.Equal(t, 2, attempt)
+	assert.Equal(t, "events-rule/nightly-report", origin)
+
+	long := RetryStartedBy(1, string(make([]byte, 200)))
+	assert.Len(t, long, maxStartedByLength)
+}
+
+func TestBackoff(t *testing.T) {
+	assert.Equal(t, time.Minute, Backoff(1, time.Minute, 10*time.Minute))
+	assert.Equal(t, 2*time.Minute, Backoff(2, time.Minute, 10*time.Minute))
+	assert.Equal(t, 8*time.Minute, Backoff(4, time.Minute, 10*time.Minute))
+	assert.Equal(t, 10*time.Minute, Backoff(5, time.Minute, 10*time.Minute))
+	assert.Equal(t, 10*time.Minute, Backoff(100, time.Minute, 10*time.Minute))
+}
@@ -0,0 +1,203 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ecs"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/hamstah/awstools/common"
+	log "github.com/sirupsen/logrus"
+	kingpin "gopkg.in/alecthomas/kingpin.v2"
+)
+
+var (
+	queueURL        = kingpin.Flag("queue-url", "URL of the SQS queue receiving the ECS task state change events.").Required().String()
+	startedByPrefix = kingpin.Flag("started-by-prefix", "Only retry the tasks with a startedBy starting with this prefix.").Default("events-rule/").String()
+	maxRetries      = kingpin.Flag("max-retries", "Maximum number of retries of a failed task.").Default("3").Int()
+	backoff         = kingpin.Flag("backoff", "Delay before the first retry, doubled for each retry.").Default("1m").Duration()
+	maxBackoff      = kingpin.Flag("max-backoff", "Maximum delay before a retry.").Default("30m").Duration()
+	webhookURL      = kingpin.Flag("webhook-url", "URL to post to when the retries of a task are exhausted.").String()
+	subnets         = kingpin.Flag("subnet", "Subnet for tasks using the awsvpc network mode. Can be repeated.").Strings()
+	securityGroups  = kingpin.Flag("security-group", "Security group for tasks using the awsvpc network mode. Can be repeated.").Strings()
+	assignPublicIP  = kingpin.Flag("assign-public-ip", "Assign a public IP to tasks using the awsvpc network mode.").Default("false").Bool()
+)
+
+type Notification struct {
+	Text          string `json:"text"`
+	TaskARN       string `json:"task_arn"`
+	ClusterARN    string `json:"cluster_arn"`
+	StartedBy     string `json:"started_by"`
+	Attempts      int    `json:"attempts"`
+	FailureReason string `json:"failure_reason"`
+}
+
+func main() {
+	kingpin.CommandLine.Name = "ecs-task-retry-controller"
+	kingpin.CommandLine.Help = "Retry the failed scheduled ECS tasks."
+	flags := common.HandleFlags()
+
+	session, conf := common.OpenSession(flags)
+
+	sqsClient := sqs.New(session, conf)
+	ecsClient := ecs.New(session, conf)
+
+	for {
+		res, err := sqsClient.ReceiveMessage(&sqs.ReceiveMessageInput{
+			QueueUrl:            queueURL,
+			MaxNumberOfMessages: aws.Int64(10),
+			WaitTimeSeconds:     aws.Int64(20),
+		})
+		common.FatalOnErrorW(err, "failed to receive the events")
+
+		for _, message := range res.Messages {
+			handleEvent(ecsClient, aws.StringValue(message.Body))
+
+			_, err := sqsClient.DeleteMessage(&sqs.DeleteMessageInput{
+				QueueUrl:      queueURL,
+				ReceiptHandle: message.ReceiptHandle,
+			})
+			if err != nil {
+				log.WithError(err).Error("Failed to delete the event")
+			}
+		}
+	}
+}
+
+func handleEvent(ecsClient *ecs.ECS, body string) {
+	event, err := ParseEvent([]byte(body))
+	if err != nil {
+		log.WithError(err).Warn("Ignoring invalid event")
+		return
+	}
+	if event == nil {
+		return
+	}
+
+	detail := event.Detail
+	attempt, origin := ParseStartedBy(detail.StartedBy)
+	if !strings.HasPrefix(origin, *startedByPrefix) {
+		return
+	}
+
+	failed, reason := detail.Failed()
+	if !failed {
+		return
+	}
+
+	logger := log.WithFields(log.Fields{
+		"task_arn":   detail.TaskARN,
+		"started_by": origin,
+		"attempt":    attempt,
+		"reason":     reason,
+	})
+
+	if attempt >= *maxRetries {
+		logger.Error("Task failed, no retry left")
+		notify(detail, origin, attempt, reason)
+		return
+	}
+
+	delay := Backoff(attempt+1, *backoff, *maxBackoff)
+	logger.WithField("delay", delay).Warn("Task failed, retrying")
+
+	// pending retries are lost if the controller stops
+	time.AfterFunc(delay, func() {
+		taskARN, err := retry(ecsClient, detail, RetryStartedBy(attempt+1, origin))
+		if err != nil {
+			logger.WithError(err).Error("Failed to retry the task")
+			notify(detail, origin, attempt, err.Error())
+			return
+		}
+		logger.WithField("retry_task_arn", taskARN).Info("Task retried")
+	})
+}
+
+func retry(ecsClient *ecs.ECS, detail TaskDetail, startedBy string) (string, error) {
+	input := &ecs.RunTaskInput{
+		Cluster:        aws.String(detail.ClusterARN),
+		TaskDefinition: aws.String(detail.TaskDefinitionARN),
+		StartedBy:      aws.String(startedBy),
+		Count:          aws.Int64(1),
+	}
+	if detail.Group != "" {
+		input.Group = aws.String(detail.Group)
+	}
+	if detail.LaunchType != "" {
+		input.LaunchType = aws.String(detail.LaunchType)
+	}
+	if detail.LaunchType == ecs.LaunchTypeFargate && detail.PlatformVersion != "" {
+		input.PlatformVersion = aws.String(detail.PlatformVersion)
+	}
+
+	if len(detail.Overrides) > 0 {
+		// the event uses the same field names as the API
+		overrides := &ecs.TaskOverride{}
+		err := json.Unmarshal(detail.Overrides, overrides)
+		if err != nil {
+			return "", err
+		}
+		input.Overrides = overrides
+	}
+
+	if len(*subnets) > 0 {
+		assignPublicIPValue := ecs.AssignPublicIpDisabled
+		if *assignPublicIP {
+			assignPublicIPValue = ecs.AssignPublicIpEnabled
+		}
+		input.NetworkConfiguration = &ecs.NetworkConfiguration{
+			AwsvpcConfiguration: &ecs.AwsVpcConfiguration{
+				Subnets:        aws.StringSlice(*subnets),
+				SecurityGroups: aws.StringSlice(*securityGroups),
+				AssignPublicIp: aws.String(assignPublicIPValue),
+			},
+		}
+	}
+
+	res, err := ecsClient.RunTask(input)
+	if err != nil {
+		return "", err
+	}
+	if len(res.Failures) > 0 {
+		return "", fmt.Errorf("%s %s", aws.StringValue(res.Failures[0].Reason), aws.StringValue(res.Failures[0].Detail))
+	}
+	return aws.StringValue(res.Tasks[0].TaskArn), nil
+}
+
+func notify(detail TaskDetail, origin string, attempt int, reason string) {
+	if *webhookURL == "" {
+		return
+	}
+
+	notification := Notification{
+		Text:          fmt.Sprintf("ECS task %s failed after %d retries: %s", origin, attempt, reason),
+		TaskARN:       detail.TaskARN,
+		ClusterARN:    detail.ClusterARN,
+		StartedBy:     origin,
+		Attempts:      attempt,
+		FailureReason: reason,
+	}
+
+	data, err := json.Marshal(notification)
+	if err != nil {
+		log.WithError(err).Error("Failed to serialise the notification")
+		return
+	}
+
+	client := &http.Client{Timeout: 10 * time.Second}
+	res, err := client.Post(*webhookURL, "application/json", bytes.NewReader(data))
+	if err != nil {
+		log.WithError(err).Error("Failed to post to the webhook")
+		return
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= 300 {
+		log.WithField("status_code", res.StatusCode).Error("Webhook returned an error")
+	}
+}
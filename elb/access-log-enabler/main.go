@@ -0,0 +1,322 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/elb"
+	"github.com/aws/aws-sdk-go/service/elbv2"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/sts"
+	"github.com/hamstah/awstools/common"
+	kingpin "gopkg.in/alecthomas/kingpin.v2"
+)
+
+var (
+	scanRegions    = kingpin.Flag("scan-region", "Region of the load balancers. Can be repeated, defaults to the session region.").Strings()
+	bucketTemplate = kingpin.Flag("bucket", "Bucket to deliver the access logs to, {account} and {region} are replaced by the account id and the region.").Default("elb-access-logs-{account}-{region}").String()
+	prefix         = kingpin.Flag("prefix", "Prefix of the access logs in the bucket.").String()
+	createBucket   = kingpin.Flag("create-bucket", "Create the bucket if it doesn't exist, with public access blocked and SSE-S3 encryption.").Default("false").Bool()
+	dryRun         = kingpin.Flag("dry-run", "Only report the load balancers where logging is off.").Default("false").Bool()
+	format         = kingpin.Flag("format", "Output format.").Default("text").Enum("text", "json")
+)
+
+type LoadBalancer struct {
+	Region  string `json:"region"`
+	Type    string `json:"type"`
+	Name    string `json:"name"`
+	ARN     string `json:"arn,omitempty"`
+	Enabled bool   `json:"enabled"`
+	Bucket  string `json:"bucket"`
+	Action  string `json:"action"`
+}
+
+const (
+	attributeEnabled = "access_logs.s3.enabled"
+	attributeBucket  = "access_logs.s3.bucket"
+	attributePrefix  = "access_logs.s3.prefix"
+)
+
+func isErrorCode(err error, code string) bool {
+	if awsErr, ok := err.(awserr.Error); ok {
+		return awsErr.Code() == code
+	}
+	return false
+}
+
+func listLoadBalancersV2(client *elbv2.ELBV2, region string) ([]*LoadBalancer, error) {
+	loadBalancers := []*LoadBalancer{}
+	var attributesErr error
+	err := client.DescribeLoadBalancersPages(&elbv2.DescribeLoadBalancersInput{},
+		func(page *elbv2.DescribeLoadBalancersOutput, lastPage bool) bool {
+			for _, loadBalancer := range page.LoadBalancers {
+				// gateway load balancers don't have access logs
+				lbType := aws.StringValue(loadBalancer.Type)
+				if lbType == elbv2.LoadBalancerTypeEnumGateway {
+					continue
+				}
+
+				attributes, err := client.DescribeLoadBalancerAttributes(&elbv2.DescribeLoadBalancerAttributesInput{
+					LoadBalancerArn: loadBalancer.LoadBalancerArn,
+				})
+				if err != nil {
+					attributesErr = err
+					return false
+				}
+
+				result := &LoadBalancer{
+					Region: region,
+					Type:   map[string]string{elbv2.LoadBalancerTypeEnumApplication: "alb", elbv2.LoadBalancerTypeEnumNetwork: "nlb"}[lbType],
+					Name:   aws.StringValue(loadBalancer.LoadBalancerName),
+					ARN:    aws.StringValue(loadBalancer.LoadBalancerArn),
+				}
+				for _, attribute := range attributes.Attributes {
+					switch aws.StringValue(attribute.Key) {
+					case attributeEnabled:
+						result.Enabled = aws.StringValue(attribute.Value) == "true"
+					case attributeBucket:
+						result.Bucket = aws.StringValue(attribute.Value)
+					}
+				}
+				loadBalancers = append(loadBalancers, result)
+			}
+			return true
+		})
+	if attributesErr != nil {
+		return nil, attributesErr
+	}
+	return loadBalancers, err
+}
+
+func listClassicLoadBalancers(client *elb.ELB, region string) ([]*LoadBalancer, error) {
+	loadBalancers := []*LoadBalancer{}
+	var attributesErr error
+	err := client.DescribeLoadBalancersPages(&elb.DescribeLoadBalancersInput{},
+		func(page *elb.DescribeLoadBalancersOutput, lastPage bool) bool {
+			for _, loadBalancer := range page.LoadBalancerDescriptions {
+				attributes, err := client.DescribeLoadBalancerAttributes(&elb.DescribeLoadBalancerAttributesInput{
+					LoadBalancerName: loadBalancer.LoadBalancerName,
+				})
+				if err != nil {
+					attributesErr = err
+					return false
+				}
+
+				result := &LoadBalancer{
+					Region: region,
+					Type:   "clb",
+					Name:   aws.StringValue(loadBalancer.LoadBalancerName),
+				}
+				if accessLog := attributes.LoadBalancerAttributes.AccessLog; accessLog != nil {
+					result.Enabled = aws.BoolValue(accessLog.Enabled)
+					result.Bucket = aws.StringValue(accessLog.S3BucketName)
+				}
+				loadBalancers = append(loadBalancers, result)
+			}
+			return true
+		})
+	if attributesErr != nil {
+		return nil, attributesErr
+	}
+	return loadBalancers, err
+}
+
+// prepareBucket creates the bucket if needed and allowed, and adds the log
+// delivery statements to its policy.
+func prepareBucket(client *s3.S3, region, bucket, accountID string) error {
+	_, err := client.HeadBucket(&s3.HeadBucketInput{Bucket: aws.String(bucket)})
+	if err != nil {
+		if !isErrorCode(err, "NotFound") {
+			return err
+		}
+		if !*createBucket {
+			return fmt.Errorf("bucket %s doesn't exist, use --create-bucket to create it", bucket)
+		}
+
+		input := &s3.CreateBucketInput{Bucket: aws.String(bucket)}
+		if region != "us-east-1" {
+			input.CreateBucketConfiguration = &s3.CreateBucketConfiguration{LocationConstraint: aws.String(region)}
+		}
+		if _, err := client.CreateBucket(input); err != nil {
+			return err
+		}
+
+		_, err = client.PutPublicAccessBlock(&s3.PutPublicAccessBlockInput{
+			Bucket: aws.String(bucket),
+			PublicAccessBlockConfiguration: &s3.PublicAccessBlockConfiguration{
+				BlockPublicAcls:       aws.Bool(true),
+				BlockPublicPolicy:     aws.Bool(true),
+				IgnorePublicAcls:      aws.Bool(true),
+				RestrictPublicBuckets: aws.Bool(true),
+			},
+		})
+		if err != nil {
+			return err
+		}
+
+		// access logs only support SSE-S3
+		_, err = client.PutBucketEncryption(&s3.PutBucketEncryptionInput{
+			Bucket: aws.String(bucket),
+			ServerSideEncryptionConfiguration: &s3.ServerSideEncryptionConfiguration{
+				Rules: []*s3.ServerSideEncryptionRule{{
+					ApplyServerSideEncryptionByDefault: &s3.ServerSideEncryptionByDefault{
+						SSEAlgorithm: aws.String(s3.ServerSideEncryptionAes256),
+					},
+				}},
+			},
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	existing := ""
+	policy, err := client.GetBucketPolicy(&s3.GetBucketPolicyInput{Bucket: aws.String(bucket)})
+	if err != nil {
+		if !isErrorCode(err, "NoSuchBucketPolicy") {
+			return err
+		}
+	} else {
+		existing = aws.StringValue(policy.Policy)
+	}
+
+	merged, changed, err := MergePolicy(existing, DeliveryStatements(region, bucket, *prefix, accountID))
+	if err != nil {
+		return err
+	}
+	if !changed {
+		return nil
+	}
+
+	_, err = client.PutBucketPolicy(&s3.PutBucketPolicyInput{
+		Bucket: aws.String(bucket),
+		Policy: aws.String(merged),
+	})
+	return err
+}
+
+func enableLogging(sess *session.Session, conf *aws.Config, loadBalancer *LoadBalancer, bucket string) error {
+	if loadBalancer.Type == "clb" {
+		_, err := elb.New(sess, conf).ModifyLoadBalancerAttributes(&elb.ModifyLoadBalancerAttributesInput{
+			LoadBalancerName: aws.String(loadBalancer.Name),
+			LoadBalancerAttributes: &elb.LoadBalancerAttributes{
+				AccessLog: &elb.AccessLog{
+					Enabled:        aws.Bool(true),
+					S3BucketName:   aws.String(bucket),
+					S3BucketPrefix: prefix,
+					EmitInterval:   aws.Int64(60),
+				},
+			},
+		})
+		return err
+	}
+
+	_, err := elbv2.New(sess, conf).ModifyLoadBalancerAttributes(&elbv2.ModifyLoadBalancerAttributesInput{
+		LoadBalancerArn: aws.String(loadBalancer.ARN),
+		Attributes: []*elbv2.LoadBalancerAttribute{
+			{Key: aws.String(attributeEnabled), Value: aws.String("true")},
+			{Key: aws.String(attributeBucket), Value: aws.String(bucket)},
+			{Key: aws.String(attributePrefix), Value: prefix},
+		},
+	})
+	return err
+}
+
+func main() {
+	kingpin.CommandLine.Name = "elb-access-log-enabler"
+	kingpin.CommandLine.Help = "Enable the access logs of the load balancers."
+	flags := common.HandleFlags()
+
+	sess, conf := common.OpenSession(flags)
+
+	identity, err := sts.New(sess, conf).GetCallerIdentity(&sts.GetCallerIdentityInput{})
+	common.FatalOnErrorW(err, "failed to get the caller identity")
+	accountID := aws.StringValue(identity.Account)
+
+	regions := *scanRegions
+	if len(regions) == 0 {
+		regions = []string{aws.StringValue(conf.Region)}
+	}
+
+	loadBalancers := []*LoadBalancer{}
+	var failure error
+	for _, region := range regions {
+		regionConf := conf.Copy(&aws.Config{Region: aws.String(region)})
+		bucket := strings.NewReplacer("{account}", accountID, "{region}", region).Replace(*bucketTemplate)
+
+		v2, err := listLoadBalancersV2(elbv2.New(sess, regionConf), region)
+		common.FatalOnErrorW(err, fmt.Sprintf("failed to list the load balancers of %s", region))
+		classic, err := listClassicLoadBalancers(elb.New(sess, regionConf), region)
+		common.FatalOnErrorW(err, fmt.Sprintf("failed to list the classic load balancers of %s", region))
+
+		regionLoadBalancers := append(v2, classic...)
+		loadBalancers = append(loadBalancers, regionLoadBalancers...)
+
+		toEnable := []*LoadBalancer{}
+		for _, loadBalancer := range regionLoadBalancers {
+			if !loadBalancer.Enabled {
+				toEnable = append(toEnable, loadBalancer)
+			}
+		}
+		if len(toEnable) == 0 {
+			continue
+		}
+
+		if *dryRun {
+			for _, loadBalancer := range toEnable {
+				loadBalancer.Action = "would enable"
+				loadBalancer.Bucket = bucket
+			}
+			continue
+		}
+
+		err = prepareBucket(s3.New(sess, regionConf), region, bucket, accountID)
+		common.FatalOnErrorW(err, fmt.Sprintf("failed to prepare the bucket %s", bucket))
+
+		for _, loadBalancer := range toEnable {
+			err := enableLogging(sess, regionConf, loadBalancer, bucket)
+			if err != nil {
+				loadBalancer.Action = fmt.Sprintf("failed: %s", err)
+				failure = err
+				continue
+			}
+			loadBalancer.Action = "enabled"
+			loadBalancer.Enabled = true
+			loadBalancer.Bucket = bucket
+		}
+	}
+
+	if *format == "json" {
+		output, err := json.MarshalIndent(loadBalancers, "", "  ")
+		common.FatalOnErrorW(err, "failed to serialise the load balancers")
+		fmt.Println(string(output))
+	} else {
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "REGION\tTYPE\tNAME\tLOGGING\tBUCKET\tACTION")
+		for _, loadBalancer := range loadBalancers {
+			logging := "off"
+			if loadBalancer.Enabled {
+				logging = "on"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
+				loadBalancer.Region,
+				loadBalancer.Type,
+				loadBalancer.Name,
+				logging,
+				loadBalancer.Bucket,
+				loadBalancer.Action,
+			)
+		}
+		w.Flush()
+	}
+
+	if failure != nil {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// accounts of Elastic Load Balancing delivering the ALB and CLB access logs in
+// the regions available before August 2022, the newer regions use the
+// logdelivery.elasticloadbalancing.amazonaws.com service principal
+var elbAccountIDs = map[string]string{
+	"us-east-1":      "127311923021",
+	"us-east-2":      "033677994240",
+	"us-west-1":      "027434742980",
+	"us-west-2":      "797873946194",
+	"af-south-1":     "098369216593",
+	"ap-east-1":      "754344448648",
+	"ap-southeast-3": "589379963580",
+	"ap-south-1":     "718504428378",
+	"ap-northeast-3": "383597477331",
+	"ap-northeast-2": "600734575887",
+	"ap-southeast-1": "114774131450",
+	"ap-southeast-2": "783225319266",
+	"ap-northeast-1": "582318560864",
+	"ca-central-1":   "985666609251",
+	"eu-central-1":   "054676820928",
+	"eu-west-1":      "156460612806",
+	"eu-west-2":      "652711504416",
+	"eu-south-1":     "635631232127",
+	"eu-west-3":      "009996457667",
+	"eu-north-1":     "897822967062",
+	"me-south-1":     "076674570225",
+	"sa-east-1":      "507241528517",
+	"us-gov-west-1":  "048591011584",
+	"us-gov-east-1":  "190560391635",
+	"cn-north-1":     "638102146993",
+	"cn-northwest-1": "037604701340",
+}
+
+const sidPrefix = "ElbAccessLogs"
+
+func partition(region string) string {
+	switch {
+	case strings.HasPrefix(region, "cn-"):
+		return "aws-cn"
+	case strings.HasPrefix(region, "us-gov-"):
+		return "aws-us-gov"
+	}
+	return "aws"
+}
+
+// LogsARN is the ARN of the access logs of the account in the bucket.
+func LogsARN(region, bucket, prefix, accountID string) string {
+	key := fmt.Sprintf("AWSLogs/%s/*", accountID)
+	if prefix != "" {
+		key = strings.Trim(prefix, "/") + "/" + key
+	}
+	return fmt.Sprintf("arn:%s:s3:::%s/%s", partition(region), bucket, key)
+}
+
+// DeliveryStatements returns the bucket policy statements allowing Elastic
+// Load Balancing to deliver the access logs of the account in the region:
+// ALB and CLB logs are delivered by the ELB account of the region, NLB logs
+// by the log delivery service.
+func DeliveryStatements(region, bucket, prefix, accountID string) []map[string]interface{} {
+	logs := LogsARN(region, bucket, prefix, accountID)
+
+	principal := map[string]interface{}{"Service": "logdelivery.elasticloadbalancing.amazonaws.com"}
+	if elbAccountID, ok := elbAccountIDs[region]; ok {
+		principal = map[string]interface{}{"AWS": fmt.Sprintf("arn:%s:iam::%s:root", partition(region), elbAccountID)}
+	}
+
+	return []map[string]interface{}{
+		{
+			"Sid":       sidPrefix + "AlbClb",
+			"Effect":    "Allow",
+			"Principal": principal,
+			"Action":    "s3:PutObject",
+			"Resource":  logs,
+		},
+		{
+			"Sid":       sidPrefix + "NlbWrite",
+			"Effect":    "Allow",
+			"Principal": map[string]interface{}{"Service": "delivery.logs.amazonaws.com"},
+			"Action":    "s3:PutObject",
+			"Resource":  logs,
+			"Condition": map[string]interface{}{
+				"StringEquals": map[string]interface{}{
+					"s3:x-amz-acl":      "bucket-owner-full-control",
+					"aws:SourceAccount": accountID,
+				},
+			},
+		},
+		{
+			"Sid":       sidPrefix + "NlbAclCheck",
+			"Effect":    "Allow",
+			"Principal": map[string]interface{}{"Service": "delivery.logs.amazonaws.com"},
+			"Action":    "s3:GetBucketAcl",
+			"Resource":  fmt.Sprintf("arn:%s:s3:::%s", partition(region), bucket),
+			"Condition": map[string]interface{}{
+				"StringEquals": map[string]interface{}{
+					"aws:SourceAccount": accountID,
+				},
+			},
+		},
+	}
+}
+
+// MergePolicy adds the statements to the bucket policy, replacing the
+// statements with the same Sid, and returns the new policy and whether it
+// changed. The other statements are kept as they are.
+func MergePolicy(existing string, statements []map[string]interface{}) (string, bool, error) {
+	policy := map[string]interface{}{}
+	if existing != "" {
+		if err := json.Unmarshal([]byte(existing), &policy); err != nil {
+			return "", false, err
+		}
+	}
+	if _, ok := policy["Version"]; !ok {
+		policy["Version"] = "2012-10-17"
+	}
+
+	current := []interface{}{}
+	switch typed := policy["Statement"].(type) {
+	case []interface{}:
+		current = typed
+	case map[string]interface{}:
+		current = []interface{}{typed}
+	}
+
+	// compare in the same representation as the existing policy
+	data, err := json.Marshal(statements)
+	if err != nil {
+		return "", false, err
+	}
+	wanted := []interface{}{}
+	if err := json.Unmarshal(data, &wanted); err != nil {
+		return "", false, err
+	}
+
+	changed := false
+	for _, statement := range wanted {
+		sid := statement.(map[string]interface{})["Sid"]
+		found := false
+		for index, existingStatement := range current {
+			existingMap, ok := existingStatement.(map[string]interface{})
+			if !ok || existingMap["Sid"] != sid {
+				continue
+			}
+			found = true
+			if !reflect.DeepEqual(existingMap, statement) {
+				current[index] = statement
+				changed = true
+			}
+		}
+		if !found {
+			current = append(current, statement)
+			changed = true
+		}
+	}
+
+	policy["Statement"] = current
+	merged, err := json.Marshal(policy)
+	if err != nil {
+		return "", false, err
+	}
+	return string(merged), changed, nil
+}
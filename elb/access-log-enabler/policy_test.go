@@ -0,0 +1,53 @@
+package main
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogsARN(t *testing.T) {
+	require.Equal(t, "arn:aws:s3:::logs/AWSLogs/123456789012/*", LogsARN("eu-west-1", "logs", "", "123456789012"))
+	require.Equal(t, "arn:aws-cn:s3:::logs/elb/AWSLogs/123456789012/*", LogsARN("cn-north-1", "logs", "/elb/", "123456789012"))
+}
+
+func TestDeliveryStatements(t *testing.T) {
+	statements := DeliveryStatements("eu-west-1", "logs", "", "123456789012")
+	require.Len(t, statements, 3)
+	require.Equal(t, map[string]interface{}{"AWS": "arn:aws:iam::156460612806:root"}, statements[0]["Principal"])
+
+	statements = DeliveryStatements("eu-central-2", "logs", "", "123456789012")
+	require.Equal(t, map[string]interface{}{"Service": "logdelivery.elasticloadbalancing.amazonaws.com"}, statements[0]["Principal"])
+	require.Equal(t, "arn:aws:s3:::logs", statements[2]["Resource"])
+}
+
+func TestMergePolicy(t *testing.T) {
+	statements := DeliveryStatements("eu-west-1", "logs", "", "123456789012")
+
+	policy, changed, err := MergePolicy("", statements)
+	require.NoError(t, err)
+	require.True(t, changed)
+
+	_, changed, err = MergePolicy(policy, statements)
+	require.NoError(t, err)
+	require.False(t, changed)
+
+	existing := `{"Version":"2012-10-17","Statement":{"Sid":"DenyInsecureTransport","Effect":"Deny","Principal":"*","Action":"s3:*","Resource":"arn:aws:s3:::logs/*","Condition":{"Bool":{"aws:SecureTransport":"false"}}}}`
+	policy, changed, err = MergePolicy(existing, statements)
+	require.NoError(t, err)
+	require.True(t, changed)
+
+	parsed := map[string]interface{}{}
+	require.NoError(t, json.Unmarshal([]byte(policy), &parsed))
+	merged := parsed["Statement"].([]interface{})
+	require.Len(t, merged, 4)
+	require.Equal(t, "DenyInsecureTransport", merged[0].(map[string]interface{})["Sid"])
+
+	// a different prefix replaces the statements
+	policy, changed, err = MergePolicy(policy, DeliveryStatements("eu-west-1", "logs", "elb", "123456789012"))
+	require.NoError(t, err)
+	require.True(t, changed)
+	require.NoError(t, json.Unmarshal([]byte(policy), &parsed))
+	require.Len(t, parsed["Statement"].([]interface{}), 4)
+}
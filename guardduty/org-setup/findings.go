@@ -0,0 +1,100 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/guardduty"
+)
+
+// maximum number of findings of GetFindings
+const findingsBatchSize = 50
+
+// FindingCriteria selects the findings with at least minSeverity, updated
+// since updatedSince if it is set, and not archived unless includeArchived.
+func FindingCriteria(minSeverity int64, updatedSince time.Time, includeArchived bool) *guardduty.FindingCriteria {
+	criterion := map[string]*guardduty.Condition{}
+
+	if !includeArchived {
+		criterion["service.archived"] = &guardduty.Condition{Equals: aws.StringSlice([]string{"false"})}
+	}
+	if minSeverity > 0 {
+		criterion["severity"] = &guardduty.Condition{GreaterThanOrEqual: aws.Int64(minSeverity)}
+	}
+	if !updatedSince.IsZero() {
+		// milliseconds since the epoch
+		criterion["updatedAt"] = &guardduty.Condition{GreaterThanOrEqual: aws.Int64(updatedSince.UnixNano() / int64(time.Millisecond))}
+	}
+
+	return &guardduty.FindingCriteria{Criterion: criterion}
+}
+
+// Batches splits the ids in batches of at most size ids.
+func Batches(ids []*string, size int) [][]*string {
+	batches := [][]*string{}
+	for start := 0; start < len(ids); start += size {
+		end := start + size
+		if end > len(ids) {
+			end = len(ids)
+		}
+		batches = append(batches, ids[start:end])
+	}
+	return batches
+}
+
+// exportFindings writes the findings of the detector matching the criteria to
+// writer, one JSON object per line, and returns the number of findings.
+func exportFindings(client *guardduty.GuardDuty, detectorID string, criteria *guardduty.FindingCriteria, writer io.Writer) (int, error) {
+	ids := []*string{}
+	err := client.ListFindingsPages(&guardduty.ListFindingsInput{
+		DetectorId:      aws.String(detectorID),
+		FindingCriteria: criteria,
+	}, func(page *guardduty.ListFindingsOutput, lastPage bool) bool {
+		ids = append(ids, page.FindingIds...)
+		return true
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	encoder := json.NewEncoder(writer)
+	for _, batch := range Batches(ids, findingsBatchSize) {
+		res, err := client.GetFindings(&guardduty.GetFindingsInput{
+			DetectorId: aws.String(detectorID),
+			FindingIds: batch,
+		})
+		if err != nil {
+			return 0, err
+		}
+
+		for _, finding := range res.Findings {
+			if err := encoder.Encode(finding); err != nil {
+				return 0, err
+			}
+		}
+	}
+
+	return len(ids), nil
+}
+
+// ParseSeverity accepts a severity level (e.g. 7) or label (LOW, MEDIUM or
+// HIGH) and returns the minimum severity level.
+func ParseSeverity(value string) (int64, bool) {
+	switch value {
+	case "", "LOW":
+		return 0, true
+	case "MEDIUM":
+		return 4, true
+	case "HIGH":
+		return 7, true
+	}
+
+	severity, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || severity < 0 || severity > 10 {
+		return 0, false
+	}
+	return severity, true
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindingCriteria(t *testing.T) {
+	criteria := FindingCriteria(0, time.Time{}, false)
+	require.Len(t, criteria.Criterion, 1)
+	require.Equal(t, []string{"false"}, aws.StringValueSlice(criteria.Criterion["service.archived"].Equals))
+
+	since := time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)
+	criteria = FindingCriteria(7, since, true)
+	require.Len(t, criteria.Criterion, 2)
+	require.Equal(t, int64(7), aws.Int64Value(criteria.Criterion["severity"].GreaterThanOrEqual))
+	require.Equal(t, int64(1609556645000), aws.Int64Value(criteria.Criterion["updatedAt"].GreaterThanOrEqual))
+}
+
+func TestParseSeverity(t *testing.T) {
+	for value, expected := range map[string]int64{"LOW": 0, "MEDIUM": 4, "HIGH": 7, "5": 5} {
+		severity, ok := ParseSeverity(value)
+		require.True(t, ok, value)
+		require.Equal(t, expected, severity, value)
+	}
+
+	for _, value := range []string{"CRITICAL", "11", "-1"} {
+		_, ok := ParseSeverity(value)
+		require.False(t, ok, value)
+	}
+}
+
+func TestBatches(t *testing.T) {
+	ids := aws.StringSlice([]string{"1", "2", "3"})
+	batches := Batches(ids, 2)
+	require.Len(t, batches, 2)
+	require.Equal(t, []string{"1", "2"}, aws.StringValueSlice(batches[0]))
+	require.Equal(t, []string{"3"}, aws.StringValueSlice(batches[1]))
+}
@@ -0,0 +1,372 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/guardduty"
+	"github.com/hamstah/awstools/common"
+	log "github.com/sirupsen/logrus"
+	kingpin "gopkg.in/alecthomas/kingpin.v2"
+)
+
+var (
+	action      = kingpin.Flag("action", "setup configures GuardDuty for the organization, export-findings writes the current findings as JSON lines.").Default("setup").Enum("setup", "export-findings")
+	scanRegions = kingpin.Flag("scan-region", "Region to set up or export the findings of. Can be repeated, defaults to the session region.").Strings()
+
+	delegateAdminAccountID = kingpin.Flag("delegate-admin-account-id", "Make this account the GuardDuty delegated administrator of the organization. Run from the management account.").String()
+	autoEnable             = kingpin.Flag("auto-enable", "Enable GuardDuty automatically in the new accounts of the organization. Run from the delegated administrator account.").Default("false").Bool()
+	autoEnableS3Logs       = kingpin.Flag("auto-enable-s3-logs", "With --auto-enable, also enable S3 protection in the new accounts.").Default("false").Bool()
+	enableExisting         = kingpin.Flag("enable-existing", "Add the existing accounts of the organization that are not members yet.").Default("false").Bool()
+	destinationARN         = kingpin.Flag("destination-arn", "ARN of the S3 bucket, and optional prefix, to publish the findings to.").String()
+	kmsKeyARN              = kingpin.Flag("kms-key-arn", "ARN of the KMS key to encrypt the published findings with. Required with --destination-arn.").String()
+	dryRun                 = kingpin.Flag("dry-run", "Only print the changes.").Default("false").Bool()
+
+	output          = kingpin.Flag("output", "File to write the findings to, - for stdout.").Short('o').Default("-").String()
+	minSeverity     = kingpin.Flag("min-severity", "Minimum severity of the findings to export, LOW, MEDIUM, HIGH or a level from 0 to 10.").Default("LOW").String()
+	updatedSince    = kingpin.Flag("updated-since", "Only export the findings updated in this duration, e.g. 24h.").Duration()
+	includeArchived = kingpin.Flag("include-archived", "Also export the archived findings.").Default("false").Bool()
+)
+
+const (
+	statusOK      = "ok"
+	statusChanged = "changed"
+	statusPlanned = "would change"
+)
+
+type Step struct {
+	Region  string
+	Name    string
+	Status  string
+	Details string
+}
+
+func changeStatus() string {
+	if *dryRun {
+		return statusPlanned
+	}
+	return statusChanged
+}
+
+// findDetector returns the id of the detector of the region, creating one if
+// there isn't any and create is set.
+func findDetector(client *guardduty.GuardDuty, create bool) (string, error) {
+	detectors, err := client.ListDetectors(&guardduty.ListDetectorsInput{})
+	if err != nil {
+		return "", err
+	}
+	if len(detectors.DetectorIds) > 0 {
+		return *detectors.DetectorIds[0], nil
+	}
+	if !create || *dryRun {
+		return "", nil
+	}
+
+	detector, err := client.CreateDetector(&guardduty.CreateDetectorInput{Enable: aws.Bool(true)})
+	if err != nil {
+		return "", err
+	}
+	return *detector.DetectorId, nil
+}
+
+func delegateAdmin(client *guardduty.GuardDuty, region string) (*Step, error) {
+	step := &Step{Region: region, Name: "delegated-admin", Details: *delegateAdminAccountID}
+
+	admins, err := client.ListOrganizationAdminAccounts(&guardduty.ListOrganizationAdminAccountsInput{})
+	if err != nil {
+		return nil, err
+	}
+	for _, admin := range admins.AdminAccounts {
+		if aws.StringValue(admin.AdminAccountId) == *delegateAdminAccountID && aws.StringValue(admin.AdminStatus) == guardduty.AdminStatusEnabled {
+			step.Status = statusOK
+			return step, nil
+		}
+	}
+
+	step.Status = changeStatus()
+	if !*dryRun {
+		_, err = client.EnableOrganizationAdminAccount(&guardduty.EnableOrganizationAdminAccountInput{
+			AdminAccountId: delegateAdminAccountID,
+		})
+	}
+	return step, err
+}
+
+func setupAutoEnable(client *guardduty.GuardDuty, region, detectorID string) (*Step, error) {
+	step := &Step{Region: region, Name: "auto-enable", Details: fmt.Sprintf("s3 logs: %t", *autoEnableS3Logs)}
+
+	if detectorID != "" {
+		configuration, err := client.DescribeOrganizationConfiguration(&guardduty.DescribeOrganizationConfigurationInput{
+			DetectorId: aws.String(detectorID),
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		s3Logs := false
+		if configuration.DataSources != nil && configuration.DataSources.S3Logs != nil {
+			s3Logs = aws.BoolValue(configuration.DataSources.S3Logs.AutoEnable)
+		}
+		if aws.BoolValue(configuration.AutoEnable) && s3Logs == *autoEnableS3Logs {
+			step.Status = statusOK
+			return step, nil
+		}
+	}
+
+	step.Status = changeStatus()
+	if *dryRun {
+		return step, nil
+	}
+
+	_, err := client.UpdateOrganizationConfiguration(&guardduty.UpdateOrganizationConfigurationInput{
+		DetectorId: aws.String(detectorID),
+		AutoEnable: aws.Bool(true),
+		DataSources: &guardduty.OrganizationDataSourceConfigurations{
+			S3Logs: &guardduty.OrganizationS3LogsConfiguration{AutoEnable: autoEnableS3Logs},
+		},
+	})
+	return step, err
+}
+
+func addExistingMembers(client *guardduty.GuardDuty, region, detectorID string) (*Step, error) {
+	step := &Step{Region: region, Name: "members"}
+
+	accounts := []*guardduty.AccountDetail{}
+	if detectorID != "" {
+		err := client.ListMembersPages(&guardduty.ListMembersInput{
+			DetectorId:     aws.String(detectorID),
+			OnlyAssociated: aws.String("false"),
+		}, func(page *guardduty.ListMembersOutput, lastPage bool) bool {
+			for _, member := range page.Members {
+				if aws.StringValue(member.RelationshipStatus) != "Enabled" {
+					accounts = append(accounts, &guardduty.AccountDetail{AccountId: member.AccountId, Email: member.Email})
+				}
+			}
+			return true
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	step.Details = fmt.Sprintf("%d accounts to add", len(accounts))
+	if len(accounts) == 0 {
+		step.Status = statusOK
+		return step, nil
+	}
+
+	step.Status = changeStatus()
+	if *dryRun {
+		return step, nil
+	}
+
+	// CreateMembers takes at most 50 accounts
+	for start := 0; start < len(accounts); start += findingsBatchSize {
+		end := start + findingsBatchSize
+		if end > len(accounts) {
+			end = len(accounts)
+		}
+
+		res, err := client.CreateMembers(&guardduty.CreateMembersInput{
+			DetectorId:     aws.String(detectorID),
+			AccountDetails: accounts[start:end],
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, unprocessed := range res.UnprocessedAccounts {
+			log.WithFields(log.Fields{
+				"region":     region,
+				"account_id": aws.StringValue(unprocessed.AccountId),
+				"error":      aws.StringValue(unprocessed.Result),
+			}).Error("Failed to add the account")
+		}
+	}
+	return step, nil
+}
+
+func setupPublishingDestination(client *guardduty.GuardDuty, region, detectorID string) (*Step, error) {
+	step := &Step{Region: region, Name: "publishing-destination", Details: *destinationARN}
+	properties := &guardduty.DestinationProperties{
+		DestinationArn: destinationARN,
+		KmsKeyArn:      kmsKeyARN,
+	}
+
+	existingID := ""
+	if detectorID != "" {
+		destinations, err := client.ListPublishingDestinations(&guardduty.ListPublishingDestinationsInput{
+			DetectorId: aws.String(detectorID),
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, destination := range destinations.Destinations {
+			if aws.StringValue(destination.DestinationType) != guardduty.DestinationTypeS3 {
+				continue
+			}
+			existingID = aws.StringValue(destination.DestinationId)
+
+			existing, err := client.DescribePublishingDestination(&guardduty.DescribePublishingDestinationInput{
+				DetectorId:    aws.String(detectorID),
+				DestinationId: destination.DestinationId,
+			})
+			if err != nil {
+				return nil, err
+			}
+			if existing.DestinationProperties != nil &&
+				aws.StringValue(existing.DestinationProperties.DestinationArn) == *destinationARN &&
+				aws.StringValue(existing.DestinationProperties.KmsKeyArn) == *kmsKeyARN {
+				step.Status = statusOK
+				return step, nil
+			}
+		}
+	}
+
+	step.Status = changeStatus()
+	if *dryRun {
+		return step, nil
+	}
+
+	var err error
+	if existingID != "" {
+		_, err = client.UpdatePublishingDestination(&guardduty.UpdatePublishingDestinationInput{
+			DetectorId:            aws.String(detectorID),
+			DestinationId:         aws.String(existingID),
+			DestinationProperties: properties,
+		})
+	} else {
+		_, err = client.CreatePublishingDestination(&guardduty.CreatePublishingDestinationInput{
+			DetectorId:            aws.String(detectorID),
+			DestinationType:       aws.String(guardduty.DestinationTypeS3),
+			DestinationProperties: properties,
+		})
+	}
+	return step, err
+}
+
+func setup(client *guardduty.GuardDuty, region string) ([]*Step, error) {
+	steps := []*Step{}
+
+	if *delegateAdminAccountID != "" {
+		step, err := delegateAdmin(client, region)
+		if err != nil {
+			return steps, fmt.Errorf("failed to delegate the administration: %s", err)
+		}
+		steps = append(steps, step)
+	}
+
+	if !*autoEnable && !*enableExisting && *destinationARN == "" {
+		return steps, nil
+	}
+
+	detectorID, err := findDetector(client, true)
+	if err != nil {
+		return steps, fmt.Errorf("failed to find the detector: %s", err)
+	}
+
+	if *autoEnable {
+		step, err := setupAutoEnable(client, region, detectorID)
+		if err != nil {
+			return steps, fmt.Errorf("failed to set up auto-enable: %s", err)
+		}
+		steps = append(steps, step)
+	}
+
+	if *enableExisting {
+		step, err := addExistingMembers(client, region, detectorID)
+		if err != nil {
+			return steps, fmt.Errorf("failed to add the members: %s", err)
+		}
+		steps = append(steps, step)
+	}
+
+	if *destinationARN != "" {
+		step, err := setupPublishingDestination(client, region, detectorID)
+		if err != nil {
+			return steps, fmt.Errorf("failed to set up the publishing destination: %s", err)
+		}
+		steps = append(steps, step)
+	}
+
+	return steps, nil
+}
+
+func export(clients map[string]*guardduty.GuardDuty, regions []string, writer io.Writer) {
+	severity, ok := ParseSeverity(*minSeverity)
+	if !ok {
+		common.Fatalln(fmt.Sprintf("Invalid --min-severity %s", *minSeverity))
+	}
+
+	since := time.Time{}
+	if *updatedSince > 0 {
+		since = time.Now().Add(-*updatedSince)
+	}
+	criteria := FindingCriteria(severity, since, *includeArchived)
+
+	for _, region := range regions {
+		detectorID, err := findDetector(clients[region], false)
+		common.FatalOnErrorW(err, fmt.Sprintf("failed to find the detector in %s", region))
+		if detectorID == "" {
+			log.WithField("region", region).Warn("No GuardDuty detector")
+			continue
+		}
+
+		count, err := exportFindings(clients[region], detectorID, criteria, writer)
+		common.FatalOnErrorW(err, fmt.Sprintf("failed to export the findings of %s", region))
+		log.WithFields(log.Fields{"region": region, "findings": count}).Info("Exported findings")
+	}
+}
+
+func main() {
+	kingpin.CommandLine.Name = "guardduty-org-setup"
+	kingpin.CommandLine.Help = "Set up GuardDuty for the organization and export its findings."
+	flags := common.HandleFlags()
+
+	if *destinationARN != "" && *kmsKeyARN == "" {
+		common.Fatalln("--kms-key-arn is required with --destination-arn")
+	}
+
+	sess, conf := common.OpenSession(flags)
+
+	regions := *scanRegions
+	if len(regions) == 0 {
+		regions = []string{aws.StringValue(conf.Region)}
+	}
+
+	clients := map[string]*guardduty.GuardDuty{}
+	for _, region := range regions {
+		clients[region] = guardduty.New(sess, conf.Copy(&aws.Config{Region: aws.String(region)}))
+	}
+
+	if *action == "export-findings" {
+		writer := os.Stdout
+		if *output != "-" {
+			file, err := os.Create(*output)
+			common.FatalOnErrorW(err, "failed to create the output file")
+			defer file.Close()
+			writer = file
+		}
+
+		export(clients, regions, writer)
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "REGION\tSTEP\tSTATUS\tDETAILS")
+	for _, region := range regions {
+		steps, err := setup(clients[region], region)
+		for _, step := range steps {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", step.Region, step.Name, step.Status, step.Details)
+		}
+		if err != nil {
+			w.Flush()
+			common.FatalOnErrorW(err, fmt.Sprintf("failed to set up GuardDuty in %s", region))
+		}
+	}
+	w.Flush()
+}
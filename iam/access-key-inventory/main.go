@@ -0,0 +1,213 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/aws/aws-sdk-go/service/organizations"
+	"github.com/aws/aws-sdk-go/service/sts"
+	"github.com/hamstah/awstools/common"
+	log "github.com/sirupsen/logrus"
+	kingpin "gopkg.in/alecthomas/kingpin.v2"
+)
+
+var (
+	roleName    = kingpin.Flag("role-name", "Role to assume in the organization accounts.").Default("OrganizationAccountAccessRole").String()
+	accountIDs  = kingpin.Flag("account-id", "Only list the access keys of this account. Can be repeated.").Strings()
+	withKeyIDs  = kingpin.Flag("with-key-ids", "Add the access key ids, the credential report doesn't include them so this lists the access keys of every user.").Default("false").Bool()
+	concurrency = kingpin.Flag("concurrency", "Number of accounts to process in parallel.").Default("5").Int()
+	output      = kingpin.Flag("output", "File to write the CSV to, defaults to stdout.").Short('o').String()
+)
+
+const (
+	credentialReportPollInterval = 2 * time.Second
+	credentialReportMaxAttempts  = 60
+)
+
+func main() {
+	kingpin.CommandLine.Name = "iam-access-key-inventory"
+	kingpin.CommandLine.Help = "List the access keys of all the accounts of an organization to CSV."
+	flags := common.HandleFlags()
+
+	sess, conf := common.OpenSession(flags)
+
+	identity, err := sts.New(sess, conf).GetCallerIdentity(&sts.GetCallerIdentityInput{})
+	common.FatalOnErrorW(err, "failed to get the caller identity")
+
+	accounts, err := listAccounts(organizations.New(sess, conf))
+	common.FatalOnErrorW(err, "failed to list the organization accounts")
+
+	type job struct {
+		account *organizations.Account
+		keys    []AccessKey
+		err     error
+	}
+
+	jobs := make(chan *job)
+	results := []*job{}
+	wg := sync.WaitGroup{}
+	for i := 0; i < *concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				accountConf := conf
+				// no role to assume in the current account
+				if *j.account.Id != *identity.Account {
+					accountConf = conf.Copy(&aws.Config{
+						Credentials: stscreds.NewCredentials(sess, fmt.Sprintf("arn:aws:iam::%s:role/%s", *j.account.Id, *roleName)),
+					})
+				}
+				j.keys, j.err = listAccessKeys(sess, accountConf)
+			}
+		}()
+	}
+
+	for _, account := range accounts {
+		j := &job{account: account}
+		results = append(results, j)
+		jobs <- j
+	}
+	close(jobs)
+	wg.Wait()
+
+	keys := []AccessKey{}
+	failed := false
+	for _, j := range results {
+		if j.err != nil {
+			log.WithError(j.err).WithField("account_id", *j.account.Id).Error("Failed to list the access keys")
+			failed = true
+			continue
+		}
+		for _, key := range j.keys {
+			key.AccountID = *j.account.Id
+			key.AccountName = aws.StringValue(j.account.Name)
+			keys = append(keys, key)
+		}
+	}
+
+	var writer io.Writer = os.Stdout
+	if *output != "" {
+		file, err := os.Create(*output)
+		common.FatalOnErrorW(err, "failed to create the output file")
+		defer file.Close()
+		writer = file
+	}
+
+	err = WriteCSV(writer, keys, time.Now().UTC())
+	common.FatalOnErrorW(err, "failed to write the CSV")
+
+	if failed {
+		os.Exit(1)
+	}
+}
+
+func listAccounts(client *organizations.Organizations) ([]*organizations.Account, error) {
+	only := map[string]bool{}
+	for _, accountID := range *accountIDs {
+		only[accountID] = true
+	}
+
+	accounts := []*organizations.Account{}
+	err := client.ListAccountsPages(&organizations.ListAccountsInput{},
+		func(page *organizations.ListAccountsOutput, lastPage bool) bool {
+			for _, account := range page.Accounts {
+				if aws.StringValue(account.Status) != organizations.AccountStatusActive {
+					continue
+				}
+				if len(only) > 0 && !only[*account.Id] {
+					continue
+				}
+				accounts = append(accounts, account)
+			}
+			return true
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(accounts, func(i, j int) bool {
+		return *accounts[i].Id < *accounts[j].Id
+	})
+	return accounts, nil
+}
+
+func listAccessKeys(sess *session.Session, conf *aws.Config) ([]AccessKey, error) {
+	client := iam.New(sess, conf)
+
+	content, err := getCredentialReport(client)
+	if err != nil {
+		return nil, err
+	}
+
+	keys, err := ParseCredentialReport(content)
+	if err != nil {
+		return nil, err
+	}
+
+	if *withKeyIDs {
+		err = addAccessKeyIDs(client, keys)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return keys, nil
+}
+
+func getCredentialReport(client *iam.IAM) ([]byte, error) {
+	for attempt := 0; attempt < credentialReportMaxAttempts; attempt++ {
+		res, err := client.GenerateCredentialReport(&iam.GenerateCredentialReportInput{})
+		if err != nil {
+			return nil, err
+		}
+
+		if aws.StringValue(res.State) == iam.ReportStateTypeComplete {
+			report, err := client.GetCredentialReport(&iam.GetCredentialReportInput{})
+			if err != nil {
+				return nil, err
+			}
+			return report.Content, nil
+		}
+
+		time.Sleep(credentialReportPollInterval)
+	}
+	return nil, fmt.Errorf("credential report not ready after %s", credentialReportPollInterval*credentialReportMaxAttempts)
+}
+
+// addAccessKeyIDs sets the access key ids, matching the keys of a user by creation date.
+func addAccessKeyIDs(client *iam.IAM, keys []AccessKey) error {
+	users := map[string][]int{}
+	for i, key := range keys {
+		users[key.User] = append(users[key.User], i)
+	}
+
+	for user, indexes := range users {
+		// the keys of the root user can't be listed with IAM
+		if user == "<root_account>" {
+			continue
+		}
+
+		res, err := client.ListAccessKeys(&iam.ListAccessKeysInput{UserName: aws.String(user)})
+		if err != nil {
+			return err
+		}
+
+		for _, metadata := range res.AccessKeyMetadata {
+			for _, i := range indexes {
+				if keys[i].LastRotated != nil && metadata.CreateDate != nil && keys[i].LastRotated.Equal(metadata.CreateDate.Truncate(time.Second)) {
+					keys[i].AccessKeyID = *metadata.AccessKeyId
+				}
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"time"
+)
+
+// AccessKey is an access key from the credential report of an account.
+type AccessKey struct {
+	AccountID       string
+	AccountName     string
+	User            string
+	UserARN         string
+	Slot            int
+	AccessKeyID     string
+	Active          bool
+	LastRotated     *time.Time
+	LastUsed        *time.Time
+	LastUsedRegion  string
+	LastUsedService string
+}
+
+// AgeDays returns the number of days since the key was created or rotated.
+func (k AccessKey) AgeDays(now time.Time) int {
+	if k.LastRotated == nil {
+		return -1
+	}
+	return int(math.Floor(now.Sub(*k.LastRotated).Hours() / 24))
+}
+
+var CSVHeader = []string{
+	"account_id",
+	"account_name",
+	"user",
+	"user_arn",
+	"key",
+	"access_key_id",
+	"active",
+	"last_rotated",
+	"age_days",
+	"last_used",
+	"last_used_region",
+	"last_used_service",
+}
+
+// ParseCredentialReport returns the access keys of the users in a IAM
+// credential report. The credential report doesn't include the access key ids.
+func ParseCredentialReport(content []byte) ([]AccessKey, error) {
+	records, err := csv.NewReader(bytes.NewReader(content)).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("empty credential report")
+	}
+
+	columns := map[string]int{}
+	for i, name := range records[0] {
+		columns[name] = i
+	}
+	for _, name := range []string{"user", "arn"} {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("missing column %s in credential report", name)
+		}
+	}
+
+	value := func(record []string, name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return record[i]
+	}
+
+	keys := []AccessKey{}
+	for _, record := range records[1:] {
+		for slot := 1; slot <= 2; slot++ {
+			prefix := fmt.Sprintf("access_key_%d_", slot)
+
+			// keys that never existed have no rotation date
+			lastRotated, err := parseReportTime(value(record, prefix+"last_rotated"))
+			if err != nil {
+				return nil, err
+			}
+			if lastRotated == nil {
+				continue
+			}
+
+			lastUsed, err := parseReportTime(value(record, prefix+"last_used_date"))
+			if err != nil {
+				return nil, err
+			}
+
+			keys = append(keys, AccessKey{
+				User:            value(record, "user"),
+				UserARN:         value(record, "arn"),
+				Slot:            slot,
+				Active:          value(record, prefix+"active") == "true",
+				LastRotated:     lastRotated,
+				LastUsed:        lastUsed,
+				LastUsedRegion:  reportValue(value(record, prefix+"last_used_region")),
+				LastUsedService: reportValue(value(record, prefix+"last_used_service")),
+			})
+		}
+	}
+	return keys, nil
+}
+
+// The credential report uses N/A, no_information or not_supported for missing values.
+func reportValue(value string) string {
+	switch value {
+	case "N/A", "no_information", "not_supported":
+		return ""
+	}
+	return value
+}
+
+func parseReportTime(value string) (*time.Time, error) {
+	value = reportValue(value)
+	if value == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+// WriteCSV writes the access keys with CSVHeader.
+func WriteCSV(writer io.Writer, keys []AccessKey, now time.Time) error {
+	csvWriter := csv.NewWriter(writer)
+	err := csvWriter.Write(CSVHeader)
+	if err != nil {
+		return err
+	}
+
+	for _, key := range keys {
+		err := csvWriter.Write([]string{
+			key.AccountID,
+			key.AccountName,
+			key.User,
+			key.UserARN,
+			strconv.Itoa(key.Slot),
+			key.AccessKeyID,
+			strconv.FormatBool(key.Active),
+			formatTime(key.LastRotated),
+			strconv.Itoa(key.AgeDays(now)),
+			formatTime(key.LastUsed),
+			key.LastUsedRegion,
+			key.LastUsedService,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	csvWriter.Flush()
+	return csvWriter.Error()
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const credentialReport = `user,arn,user_creation_time,password_enabled,access_key_1_active,access_key_1_last_rotated,access_key_1_last_used_date,access_key_1_last_used_region,access_key_1_last_used_service,access_key_2_active,access_key_2_last_rotated,access_key_2_last_used_date,access_key_2_last_used_region,access_key_2_last_used_service
+<root_account>,arn:aws:iam::123456789012:root,2020-01-01T00:00:00+00:00,not_supported,false,N/A,N/A,N/A,N/A,false,N/A,N/A,N/A,N/A
+alice,arn:aws:iam::123456789012:user/alice,2020-01-01T00:00:00+00:00,true,true,2020-06-01T12:00:00+00:00,2021-01-10T08:00:00+00:00,eu-west-1,s3,false,2020-02-01T00:00:00+00:00,N/A,N/A,N/A
+bob,arn:aws:iam::123456789012:user/bob,2020-01-01T00:00:00+00:00,false,false,N/A,N/A,N/A,N/A,false,N/A,N/A,N/A,N/A
+`
+
+func TestParseCredentialReport(t *testing.T) {
+	keys, err := ParseCredentialReport([]byte(credentialReport))
+	require.NoError(t, err)
+	require.Len(t, keys, 2)
+
+	assert.Equal(t, "alice", keys[0].User)
+	assert.Equal(t, 1, keys[0].Slot)
+	assert.True(t, keys[0].Active)
+	assert.Equal(t, "eu-west-1", keys[0].LastUsedRegion)
+	assert.Equal(t, "s3", keys[0].LastUsedService)
+	require.NotNil(t, keys[0].LastUsed)
+
+	assert.Equal(t, 2, keys[1].Slot)
+	assert.False(t, keys[1].Active)
+	assert.Nil(t, keys[1].LastUsed)
+	assert.Equal(t, "", keys[1].LastUsedService)
+
+	now := time.Date(2021, 1, 18, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, 230, keys[0].AgeDays(now))
+
+	_, err = ParseCredentialReport([]byte("a,b\n1,2\n"))
+	assert.Error(t, err)
+}
+
+func TestWriteCSV(t *testing.T) {
+	keys, err := ParseCredentialReport([]byte(credentialReport))
+	require.NoError(t, err)
+	keys[0].AccountID = "123456789012"
+	keys[0].AccountName = "prod"
+
+	buffer := &bytes.Buffer{}
+	err = WriteCSV(buffer, keys[:1], time.Date(2021, 1, 18, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.Equal(t, "account_id,account_name,user,user_arn,key,access_key_id,active,last_rotated,age_days,last_used,last_used_region,last_used_service\n"+
+		"123456789012,prod,alice,arn:aws:iam::123456789012:user/alice,1,,true,2020-06-01T12:00:00Z,230,2021-01-10T08:00:00Z,eu-west-1,s3\n",
+		buffer.String())
+}
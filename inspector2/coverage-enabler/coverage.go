@@ -0,0 +1,81 @@
+package main
+
+import (
+	"sort"
+)
+
+const (
+	ResourceTypeEC2    = "EC2"
+	ResourceTypeECR    = "ECR"
+	ResourceTypeLambda = "LAMBDA"
+
+	statusEnabled = "ENABLED"
+)
+
+var ResourceTypes = []string{ResourceTypeEC2, ResourceTypeECR, ResourceTypeLambda}
+
+// AccountCoverage is the Inspector2 status of an account in a region.
+type AccountCoverage struct {
+	Region       string            `json:"region"`
+	AccountID    string            `json:"account_id"`
+	Relationship string            `json:"relationship"`
+	Status       map[string]string `json:"status"`
+	Missing      []string          `json:"missing"`
+}
+
+// UpdateMissing sets Missing to the resource types not enabled in the account.
+func (c *AccountCoverage) UpdateMissing(resourceTypes []string) {
+	c.Missing = []string{}
+	for _, resourceType := range resourceTypes {
+		if c.Status[resourceType] != statusEnabled {
+			c.Missing = append(c.Missing, resourceType)
+		}
+	}
+}
+
+// NeedsAssociation is true for the accounts of the organization that are not
+// members of the delegated administrator. Suspended accounts can't be
+// associated.
+func (c *AccountCoverage) NeedsAssociation(adminAccountID string) bool {
+	if c.AccountID == adminAccountID {
+		return false
+	}
+	switch c.Relationship {
+	case statusEnabled, "ACCOUNT_SUSPENDED", "REGION_DISABLED":
+		return false
+	}
+	return true
+}
+
+// Gaps returns the coverage of the accounts with missing resource types,
+// sorted by region and account.
+func Gaps(coverage []*AccountCoverage) []*AccountCoverage {
+	gaps := []*AccountCoverage{}
+	for _, account := range coverage {
+		if len(account.Missing) > 0 {
+			gaps = append(gaps, account)
+		}
+	}
+
+	sort.Slice(gaps, func(i, j int) bool {
+		if gaps[i].Region != gaps[j].Region {
+			return gaps[i].Region < gaps[j].Region
+		}
+		return gaps[i].AccountID < gaps[j].AccountID
+	})
+	return gaps
+}
+
+// Batches splits the account ids in batches of at most size ids, the APIs
+// taking lists of accounts have a maximum length.
+func Batches(accountIDs []string, size int) [][]string {
+	batches := [][]string{}
+	for start := 0; start < len(accountIDs); start += size {
+		end := start + size
+		if end > len(accountIDs) {
+			end = len(accountIDs)
+		}
+		batches = append(batches, accountIDs[start:end])
+	}
+	return batches
+}
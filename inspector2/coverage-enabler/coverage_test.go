@@ -0,0 +1,45 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccountCoverage(t *testing.T) {
+	coverage := &AccountCoverage{
+		AccountID:    "111111111111",
+		Relationship: "CREATED",
+		Status:       map[string]string{"EC2": "ENABLED", "ECR": "DISABLED"},
+	}
+	coverage.UpdateMissing(ResourceTypes)
+	require.Equal(t, []string{"ECR", "LAMBDA"}, coverage.Missing)
+	require.True(t, coverage.NeedsAssociation("999999999999"))
+	require.False(t, coverage.NeedsAssociation("111111111111"))
+
+	coverage.UpdateMissing([]string{"EC2"})
+	require.Equal(t, []string{}, coverage.Missing)
+
+	coverage.Relationship = "ACCOUNT_SUSPENDED"
+	require.False(t, coverage.NeedsAssociation("999999999999"))
+}
+
+func TestGaps(t *testing.T) {
+	coverage := []*AccountCoverage{
+		{Region: "eu-west-1", AccountID: "2", Missing: []string{"EC2"}},
+		{Region: "eu-west-1", AccountID: "1", Missing: []string{}},
+		{Region: "eu-central-1", AccountID: "3", Missing: []string{"ECR"}},
+		{Region: "eu-west-1", AccountID: "0", Missing: []string{"LAMBDA"}},
+	}
+
+	gaps := Gaps(coverage)
+	require.Len(t, gaps, 3)
+	require.Equal(t, "3", gaps[0].AccountID)
+	require.Equal(t, "0", gaps[1].AccountID)
+	require.Equal(t, "2", gaps[2].AccountID)
+}
+
+func TestBatches(t *testing.T) {
+	require.Equal(t, [][]string{}, Batches([]string{}, 10))
+	require.Equal(t, [][]string{{"1", "2"}, {"3", "4"}, {"5"}}, Batches([]string{"1", "2", "3", "4", "5"}, 2))
+}
@@ -0,0 +1,11 @@
+module github.com/hamstah/awstools/inspector2/coverage-enabler
+
+go 1.15
+
+require (
+	github.com/aws/aws-sdk-go v1.44.153
+	github.com/hamstah/awstools/common v0.0.0-20210118215825-d772cda2a155
+	github.com/sirupsen/logrus v1.7.0
+	github.com/stretchr/testify v1.6.1
+	gopkg.in/alecthomas/kingpin.v2 v2.2.6
+)
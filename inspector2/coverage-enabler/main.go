@@ -0,0 +1,245 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/inspector2"
+	"github.com/aws/aws-sdk-go/service/sts"
+	"github.com/hamstah/awstools/common"
+	log "github.com/sirupsen/logrus"
+	kingpin "gopkg.in/alecthomas/kingpin.v2"
+)
+
+var (
+	scanRegions       = kingpin.Flag("scan-region", "Region to check and enable Inspector2 in. Can be repeated, defaults to the session region.").Strings()
+	resourceTypes     = kingpin.Flag("resource-type", "Resource type to scan. Can be repeated, defaults to all of them.").Enums(ResourceTypes...)
+	excludeAccountIDs = kingpin.Flag("exclude-account-id", "Account not to enable Inspector2 in. Can be repeated.").Strings()
+	enable            = kingpin.Flag("enable", "Associate the accounts and enable the missing resource types instead of only reporting the gaps.").Default("false").Bool()
+	autoEnable        = kingpin.Flag("auto-enable", "With --enable, also enable the resource types automatically in the new accounts of the organization.").Default("false").Bool()
+	format            = kingpin.Flag("format", "Output format.").Default("text").Enum("text", "json")
+)
+
+const (
+	// maximum number of accounts of BatchGetAccountStatus
+	statusBatchSize = 10
+	// maximum number of accounts of Enable
+	enableBatchSize = 100
+)
+
+func stateStatus(state *inspector2.State) string {
+	if state == nil {
+		return ""
+	}
+	return aws.StringValue(state.Status)
+}
+
+// listCoverage returns the status of the admin account and of all the accounts
+// of the organization in the region.
+func listCoverage(client *inspector2.Inspector2, region, adminAccountID string) ([]*AccountCoverage, error) {
+	accounts := map[string]*AccountCoverage{
+		adminAccountID: {Region: region, AccountID: adminAccountID, Relationship: "ADMINISTRATOR"},
+	}
+	accountIDs := []string{adminAccountID}
+
+	err := client.ListMembersPages(&inspector2.ListMembersInput{OnlyAssociated: aws.Bool(false)},
+		func(page *inspector2.ListMembersOutput, lastPage bool) bool {
+			for _, member := range page.Members {
+				accountID := aws.StringValue(member.AccountId)
+				if _, ok := accounts[accountID]; ok {
+					continue
+				}
+				accounts[accountID] = &AccountCoverage{
+					Region:       region,
+					AccountID:    accountID,
+					Relationship: aws.StringValue(member.RelationshipStatus),
+				}
+				accountIDs = append(accountIDs, accountID)
+			}
+			return true
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, batch := range Batches(accountIDs, statusBatchSize) {
+		res, err := client.BatchGetAccountStatus(&inspector2.BatchGetAccountStatusInput{
+			AccountIds: aws.StringSlice(batch),
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, state := range res.Accounts {
+			account := accounts[aws.StringValue(state.AccountId)]
+			if account == nil || state.ResourceState == nil {
+				continue
+			}
+			account.Status = map[string]string{
+				ResourceTypeEC2:    stateStatus(state.ResourceState.Ec2),
+				ResourceTypeECR:    stateStatus(state.ResourceState.Ecr),
+				ResourceTypeLambda: stateStatus(state.ResourceState.Lambda),
+			}
+		}
+
+		for _, failed := range res.FailedAccounts {
+			log.WithFields(log.Fields{
+				"region":     region,
+				"account_id": aws.StringValue(failed.AccountId),
+				"error":      aws.StringValue(failed.ErrorMessage),
+			}).Warn("Failed to get the Inspector2 status")
+		}
+	}
+
+	coverage := []*AccountCoverage{}
+	for _, accountID := range accountIDs {
+		coverage = append(coverage, accounts[accountID])
+	}
+	return coverage, nil
+}
+
+// enableCoverage associates the accounts that are not members yet and enables
+// the missing resource types.
+func enableCoverage(client *inspector2.Inspector2, gaps []*AccountCoverage, adminAccountID string) error {
+	accountIDs := []string{}
+	for _, account := range gaps {
+		if account.NeedsAssociation(adminAccountID) {
+			_, err := client.AssociateMember(&inspector2.AssociateMemberInput{AccountId: aws.String(account.AccountID)})
+			if err != nil {
+				return fmt.Errorf("failed to associate %s: %s", account.AccountID, err)
+			}
+			log.WithFields(log.Fields{"region": account.Region, "account_id": account.AccountID}).Info("Associated account")
+		}
+		accountIDs = append(accountIDs, account.AccountID)
+	}
+
+	for _, batch := range Batches(accountIDs, enableBatchSize) {
+		res, err := client.Enable(&inspector2.EnableInput{
+			AccountIds:    aws.StringSlice(batch),
+			ResourceTypes: aws.StringSlice(*resourceTypes),
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, failed := range res.FailedAccounts {
+			log.WithFields(log.Fields{
+				"account_id": aws.StringValue(failed.AccountId),
+				"error":      aws.StringValue(failed.ErrorMessage),
+			}).Error("Failed to enable Inspector2")
+		}
+	}
+	return nil
+}
+
+func autoEnableConfiguration() *inspector2.AutoEnable {
+	enabled := map[string]bool{}
+	for _, resourceType := range *resourceTypes {
+		enabled[resourceType] = true
+	}
+	return &inspector2.AutoEnable{
+		Ec2:    aws.Bool(enabled[ResourceTypeEC2]),
+		Ecr:    aws.Bool(enabled[ResourceTypeECR]),
+		Lambda: aws.Bool(enabled[ResourceTypeLambda]),
+	}
+}
+
+func main() {
+	kingpin.CommandLine.Name = "inspector2-coverage-enabler"
+	kingpin.CommandLine.Help = "Report and enable Inspector2 scanning in the accounts of the organization."
+	flags := common.HandleFlags()
+
+	if len(*resourceTypes) == 0 {
+		*resourceTypes = ResourceTypes
+	}
+
+	sess, conf := common.OpenSession(flags)
+
+	identity, err := sts.New(sess, conf).GetCallerIdentity(&sts.GetCallerIdentityInput{})
+	common.FatalOnErrorW(err, "failed to get the caller identity")
+	adminAccountID := aws.StringValue(identity.Account)
+
+	excluded := map[string]bool{}
+	for _, accountID := range *excludeAccountIDs {
+		excluded[accountID] = true
+	}
+
+	regions := *scanRegions
+	if len(regions) == 0 {
+		regions = []string{aws.StringValue(conf.Region)}
+	}
+
+	coverage := []*AccountCoverage{}
+	clients := map[string]*inspector2.Inspector2{}
+	for _, region := range regions {
+		client := inspector2.New(sess, conf.Copy(&aws.Config{Region: aws.String(region)}))
+		clients[region] = client
+
+		regionCoverage, err := listCoverage(client, region, adminAccountID)
+		common.FatalOnErrorW(err, fmt.Sprintf("failed to list the Inspector2 coverage in %s", region))
+
+		for _, account := range regionCoverage {
+			if !excluded[account.AccountID] {
+				account.UpdateMissing(*resourceTypes)
+				coverage = append(coverage, account)
+			}
+		}
+	}
+
+	gaps := Gaps(coverage)
+
+	if *format == "json" {
+		output, err := json.MarshalIndent(gaps, "", "  ")
+		common.FatalOnErrorW(err, "failed to serialise the gaps")
+		fmt.Println(string(output))
+	} else {
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "REGION\tACCOUNT\tRELATIONSHIP\tEC2\tECR\tLAMBDA\tMISSING")
+		for _, account := range gaps {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+				account.Region,
+				account.AccountID,
+				account.Relationship,
+				account.Status[ResourceTypeEC2],
+				account.Status[ResourceTypeECR],
+				account.Status[ResourceTypeLambda],
+				strings.Join(account.Missing, ","),
+			)
+		}
+		w.Flush()
+	}
+
+	log.WithFields(log.Fields{
+		"accounts": len(coverage),
+		"gaps":     len(gaps),
+	}).Info("Inspector2 coverage")
+
+	if !*enable {
+		return
+	}
+
+	for _, region := range regions {
+		regionGaps := []*AccountCoverage{}
+		for _, account := range gaps {
+			if account.Region == region {
+				regionGaps = append(regionGaps, account)
+			}
+		}
+
+		if len(regionGaps) > 0 {
+			err := enableCoverage(clients[region], regionGaps, adminAccountID)
+			common.FatalOnErrorW(err, fmt.Sprintf("failed to enable Inspector2 in %s", region))
+		}
+
+		if *autoEnable {
+			_, err := clients[region].UpdateOrganizationConfiguration(&inspector2.UpdateOrganizationConfigurationInput{
+				AutoEnable: autoEnableConfiguration(),
+			})
+			common.FatalOnErrorW(err, fmt.Sprintf("failed to update the organization configuration in %s", region))
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	PrincipalService  = "service"
+	PrincipalAccount  = "account"
+	PrincipalRole     = "role"
+	PrincipalUser     = "user"
+	PrincipalUniqueID = "unique-id"
+	PrincipalUnknown  = "unknown"
+
+	FindingDeletedPrincipal = "deleted-principal"
+	FindingForeignAccount   = "foreign-account"
+)
+
+// IAM replaces the ARN of deleted users and roles with their unique id
+var uniqueIDRegexp = regexp.MustCompile(`^(AIDA|AROA)[A-Z0-9]{12,}$`)
+
+type Principal struct {
+	Value     string `json:"value"`
+	Kind      string `json:"kind"`
+	AccountID string `json:"account_id,omitempty"`
+	Name      string `json:"name,omitempty"`
+	// Exists is nil when the principal couldn't be checked
+	Exists *bool `json:"exists,omitempty"`
+}
+
+// ParsePrincipal returns the kind, account and name of a grant principal. The
+// principals that are unique ids are deleted users or roles.
+func ParsePrincipal(value string) *Principal {
+	principal := &Principal{Value: value, Kind: PrincipalUnknown}
+
+	if uniqueIDRegexp.MatchString(value) {
+		exists := false
+		principal.Kind = PrincipalUniqueID
+		principal.Exists = &exists
+		return principal
+	}
+
+	parts := strings.SplitN(value, ":", 6)
+	if len(parts) != 6 || parts[0] != "arn" {
+		if strings.HasSuffix(value, ".amazonaws.com") {
+			principal.Kind = PrincipalService
+		}
+		return principal
+	}
+
+	principal.AccountID = parts[4]
+	resource := strings.Split(parts[5], "/")
+	switch {
+	case parts[2] == "iam" && resource[0] == "root":
+		principal.Kind = PrincipalAccount
+	case parts[2] == "iam" && resource[0] == "role" && len(resource) > 1:
+		principal.Kind = PrincipalRole
+		principal.Name = resource[len(resource)-1]
+	case parts[2] == "iam" && resource[0] == "user" && len(resource) > 1:
+		principal.Kind = PrincipalUser
+		principal.Name = resource[len(resource)-1]
+	case parts[2] == "sts" && resource[0] == "assumed-role" && len(resource) > 1:
+		principal.Kind = PrincipalRole
+		principal.Name = resource[1]
+	}
+	return principal
+}
+
+type Grant struct {
+	Region            string     `json:"region"`
+	KeyID             string     `json:"key_id"`
+	KeyARN            string     `json:"key_arn"`
+	KeyAccountID      string     `json:"key_account_id"`
+	KeyAliases        []string   `json:"key_aliases"`
+	GrantID           string     `json:"grant_id"`
+	Name              string     `json:"name"`
+	Grantee           *Principal `json:"grantee"`
+	RetiringPrincipal *Principal `json:"retiring_principal,omitempty"`
+	Operations        []string   `json:"operations"`
+	CreationDate      *time.Time `json:"creation_date"`
+	Findings          []string   `json:"findings"`
+	Action            string     `json:"action,omitempty"`
+}
+
+// Evaluate flags the grants to deleted principals and to principals of
+// accounts other than the one of the key and the trusted ones.
+func (g *Grant) Evaluate(trustedAccountIDs []string) {
+	g.Findings = []string{}
+	if g.Grantee == nil {
+		return
+	}
+
+	if g.Grantee.Exists != nil && !*g.Grantee.Exists {
+		g.Findings = append(g.Findings, FindingDeletedPrincipal)
+	}
+
+	if accountID := g.Grantee.AccountID; accountID != "" && accountID != g.KeyAccountID {
+		for _, trusted := range trustedAccountIDs {
+			if accountID == trusted {
+				return
+			}
+		}
+		g.Findings = append(g.Findings, FindingForeignAccount)
+	}
+}
+
+// KeyAccountID returns the account of a key ARN.
+func KeyAccountID(keyARN string) string {
+	parts := strings.SplitN(keyARN, ":", 6)
+	if len(parts) != 6 {
+		return ""
+	}
+	return parts[4]
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePrincipal(t *testing.T) {
+	role := ParsePrincipal("arn:aws:iam::123456789012:role/service/app")
+	assert.Equal(t, PrincipalRole, role.Kind)
+	assert.Equal(t, "123456789012", role.AccountID)
+	assert.Equal(t, "app", role.Name)
+	assert.Nil(t, role.Exists)
+
+	assumed := ParsePrincipal("arn:aws:sts::123456789012:assumed-role/app/session")
+	assert.Equal(t, PrincipalRole, assumed.Kind)
+	assert.Equal(t, "app", assumed.Name)
+
+	assert.Equal(t, PrincipalUser, ParsePrincipal("arn:aws:iam::123456789012:user/alice").Kind)
+	assert.Equal(t, PrincipalAccount, ParsePrincipal("arn:aws:iam::210987654321:root").Kind)
+	assert.Equal(t, PrincipalService, ParsePrincipal("dynamodb.eu-west-1.amazonaws.com").Kind)
+	assert.Equal(t, PrincipalUnknown, ParsePrincipal("something").Kind)
+
+	deleted := ParsePrincipal("AROAJQABLZS4A3QDU576Q")
+	assert.Equal(t, PrincipalUniqueID, deleted.Kind)
+	assert.False(t, *deleted.Exists)
+}
+
+func TestEvaluate(t *testing.T) {
+	keyARN := "arn:aws:kms:eu-west-1:123456789012:key/1234abcd-12ab-34cd-56ef-1234567890ab"
+	assert.Equal(t, "123456789012", KeyAccountID(keyARN))
+
+	exists := true
+	grantee := ParsePrincipal("arn:aws:iam::123456789012:role/app")
+	grantee.Exists = &exists
+	grant := &Grant{KeyAccountID: "123456789012", Grantee: grantee}
+	grant.Evaluate(nil)
+	assert.Empty(t, grant.Findings)
+
+	grant = &Grant{KeyAccountID: "123456789012", Grantee: ParsePrincipal("AROAJQABLZS4A3QDU576Q")}
+	grant.Evaluate(nil)
+	assert.Equal(t, []string{FindingDeletedPrincipal}, grant.Findings)
+
+	grant = &Grant{KeyAccountID: "123456789012", Grantee: ParsePrincipal("arn:aws:iam::210987654321:root")}
+	grant.Evaluate(nil)
+	assert.Equal(t, []string{FindingForeignAccount}, grant.Findings)
+	grant.Evaluate([]string{"210987654321"})
+	assert.Empty(t, grant.Findings)
+}
@@ -0,0 +1,245 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/aws/aws-sdk-go/service/kms"
+	"github.com/hamstah/awstools/common"
+	kingpin "gopkg.in/alecthomas/kingpin.v2"
+)
+
+var (
+	scanRegions     = kingpin.Flag("scan-region", "Region of the keys. Can be repeated, defaults to the session region.").Strings()
+	keyIDs          = kingpin.Flag("key-id", "Only the grants of this key id, ARN or alias. Can be repeated.").Strings()
+	trustedAccounts = kingpin.Flag("trusted-account-id", "Account whose principals are not reported as foreign. Can be repeated.").Strings()
+	onlyFlagged     = kingpin.Flag("only-flagged", "Only report the grants to deleted principals or foreign accounts.").Default("false").Bool()
+	revokeGrantIDs  = kingpin.Flag("revoke", "Id of a grant to revoke. Can be repeated.").Strings()
+	revokeFlagged   = kingpin.Flag("revoke-flagged", "Revoke all the grants to deleted principals or foreign accounts.").Default("false").Bool()
+	dryRun          = kingpin.Flag("dry-run", "Only report the grants that would be revoked.").Default("false").Bool()
+	format          = kingpin.Flag("format", "Output format.").Default("text").Enum("text", "json")
+)
+
+type Key struct {
+	ID      string
+	ARN     string
+	Aliases []string
+}
+
+// listKeys returns the customer managed keys of the region, or the keys of
+// --key-id.
+func listKeys(client *kms.KMS) ([]*Key, error) {
+	ids := []string{}
+	if len(*keyIDs) > 0 {
+		ids = *keyIDs
+	} else {
+		err := client.ListKeysPages(&kms.ListKeysInput{},
+			func(page *kms.ListKeysOutput, lastPage bool) bool {
+				for _, key := range page.Keys {
+					ids = append(ids, aws.StringValue(key.KeyArn))
+				}
+				return true
+			})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	keys := []*Key{}
+	byID := map[string]*Key{}
+	for _, id := range ids {
+		res, err := client.DescribeKey(&kms.DescribeKeyInput{KeyId: aws.String(id)})
+		if err != nil {
+			return nil, fmt.Errorf("failed to describe %s: %s", id, err)
+		}
+		if aws.StringValue(res.KeyMetadata.KeyManager) != kms.KeyManagerTypeCustomer {
+			continue
+		}
+		key := &Key{
+			ID:      aws.StringValue(res.KeyMetadata.KeyId),
+			ARN:     aws.StringValue(res.KeyMetadata.Arn),
+			Aliases: []string{},
+		}
+		keys = append(keys, key)
+		byID[key.ID] = key
+	}
+
+	err := client.ListAliasesPages(&kms.ListAliasesInput{},
+		func(page *kms.ListAliasesOutput, lastPage bool) bool {
+			for _, alias := range page.Aliases {
+				if key, ok := byID[aws.StringValue(alias.TargetKeyId)]; ok {
+					key.Aliases = append(key.Aliases, aws.StringValue(alias.AliasName))
+				}
+			}
+			return true
+		})
+	return keys, err
+}
+
+func listGrants(client *kms.KMS, region string, key *Key) ([]*Grant, error) {
+	grants := []*Grant{}
+	err := client.ListGrantsPages(&kms.ListGrantsInput{KeyId: aws.String(key.ARN)},
+		func(page *kms.ListGrantsResponse, lastPage bool) bool {
+			for _, entry := range page.Grants {
+				grant := &Grant{
+					Region:       region,
+					KeyID:        key.ID,
+					KeyARN:       key.ARN,
+					KeyAccountID: KeyAccountID(key.ARN),
+					KeyAliases:   key.Aliases,
+					GrantID:      aws.StringValue(entry.GrantId),
+					Name:         aws.StringValue(entry.Name),
+					Grantee:      ParsePrincipal(aws.StringValue(entry.GranteePrincipal)),
+					Operations:   aws.StringValueSlice(entry.Operations),
+					CreationDate: entry.CreationDate,
+				}
+				if entry.RetiringPrincipal != nil {
+					grant.RetiringPrincipal = ParsePrincipal(aws.StringValue(entry.RetiringPrincipal))
+				}
+				grants = append(grants, grant)
+			}
+			return true
+		})
+	return grants, err
+}
+
+// PrincipalResolver checks that the users and roles of the account still
+// exist, the principals of other accounts can't be checked.
+type PrincipalResolver struct {
+	client    *iam.IAM
+	accountID string
+	cache     map[string]bool
+}
+
+func isNoSuchEntity(err error) bool {
+	if awsErr, ok := err.(awserr.Error); ok {
+		return awsErr.Code() == iam.ErrCodeNoSuchEntityException
+	}
+	return false
+}
+
+func (r *PrincipalResolver) Resolve(principal *Principal) error {
+	if principal.AccountID != r.accountID || (principal.Kind != PrincipalRole && principal.Kind != PrincipalUser) {
+		return nil
+	}
+
+	cacheKey := fmt.Sprintf("%s/%s", principal.Kind, principal.Name)
+	exists, ok := r.cache[cacheKey]
+	if !ok {
+		var err error
+		if principal.Kind == PrincipalRole {
+			_, err = r.client.GetRole(&iam.GetRoleInput{RoleName: aws.String(principal.Name)})
+		} else {
+			_, err = r.client.GetUser(&iam.GetUserInput{UserName: aws.String(principal.Name)})
+		}
+		if err != nil && !isNoSuchEntity(err) {
+			return err
+		}
+		exists = err == nil
+		r.cache[cacheKey] = exists
+	}
+	principal.Exists = &exists
+	return nil
+}
+
+func main() {
+	kingpin.CommandLine.Name = "kms-grant-audit"
+	kingpin.CommandLine.Help = "List the grants of the customer managed KMS keys, flag the grants to deleted principals or foreign accounts and revoke grants."
+	flags := common.HandleFlags()
+
+	sess, conf := common.OpenSession(flags)
+
+	regions := *scanRegions
+	if len(regions) == 0 {
+		regions = []string{aws.StringValue(conf.Region)}
+	}
+
+	revoke := map[string]bool{}
+	for _, grantID := range *revokeGrantIDs {
+		revoke[grantID] = true
+	}
+
+	resolvers := map[string]*PrincipalResolver{}
+	grants := []*Grant{}
+	failed := false
+	for _, region := range regions {
+		client := kms.New(sess, conf.Copy(&aws.Config{Region: aws.String(region)}))
+
+		keys, err := listKeys(client)
+		common.FatalOnErrorW(err, fmt.Sprintf("failed to list the keys of %s", region))
+
+		for _, key := range keys {
+			keyGrants, err := listGrants(client, region, key)
+			common.FatalOnErrorW(err, fmt.Sprintf("failed to list the grants of %s", key.ARN))
+
+			for _, grant := range keyGrants {
+				resolver, ok := resolvers[grant.KeyAccountID]
+				if !ok {
+					resolver = &PrincipalResolver{client: iam.New(sess, conf), accountID: grant.KeyAccountID, cache: map[string]bool{}}
+					resolvers[grant.KeyAccountID] = resolver
+				}
+				common.FatalOnErrorW(resolver.Resolve(grant.Grantee), fmt.Sprintf("failed to resolve %s", grant.Grantee.Value))
+				grant.Evaluate(*trustedAccounts)
+
+				if *onlyFlagged && len(grant.Findings) == 0 {
+					continue
+				}
+				grants = append(grants, grant)
+
+				if !revoke[grant.GrantID] && !(*revokeFlagged && len(grant.Findings) > 0) {
+					continue
+				}
+				delete(revoke, grant.GrantID)
+				if *dryRun {
+					grant.Action = "would revoke"
+					continue
+				}
+				_, err := client.RevokeGrant(&kms.RevokeGrantInput{KeyId: aws.String(grant.KeyARN), GrantId: aws.String(grant.GrantID)})
+				if err != nil {
+					grant.Action = fmt.Sprintf("failed: %s", err)
+					failed = true
+					continue
+				}
+				grant.Action = "revoked"
+			}
+		}
+	}
+
+	for grantID := range revoke {
+		fmt.Fprintf(os.Stderr, "grant %s to revoke not found\n", grantID)
+		failed = true
+	}
+
+	if *format == "json" {
+		output, err := json.MarshalIndent(grants, "", "  ")
+		common.FatalOnErrorW(err, "failed to serialise the grants")
+		fmt.Println(string(output))
+	} else {
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "REGION\tKEY\tALIASES\tGRANT\tNAME\tGRANTEE\tOPERATIONS\tFINDINGS\tACTION")
+		for _, grant := range grants {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+				grant.Region,
+				grant.KeyID,
+				strings.Join(grant.KeyAliases, ","),
+				grant.GrantID,
+				grant.Name,
+				grant.Grantee.Value,
+				strings.Join(grant.Operations, ","),
+				strings.Join(grant.Findings, ","),
+				grant.Action,
+			)
+		}
+		w.Flush()
+	}
+
+	if failed {
+		os.Exit(1)
+	}
+}
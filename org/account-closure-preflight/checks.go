@@ -0,0 +1,184 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	CheckRunningInstances = "running-instances"
+	CheckBuckets          = "buckets"
+	CheckReservations     = "reservations"
+	CheckSavingsPlans     = "savings-plans"
+	CheckCrossAccount     = "cross-account-references"
+
+	StatusOK      = "ok"
+	StatusBlocked = "blocked"
+	// the check failed, the account can't be considered ready
+	StatusUnknown = "unknown"
+
+	// maximum number of metadata paths reported per referencing resource
+	maxReferencePaths = 3
+)
+
+// Item is a resource blocking the closure of the account.
+type Item struct {
+	AccountID string `json:"account_id"`
+	Region    string `json:"region"`
+	ID        string `json:"id"`
+	Reason    string `json:"reason"`
+}
+
+// Check is a condition that needs to be true before closing the account.
+type Check struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Status      string   `json:"status"`
+	Items       []Item   `json:"items"`
+	Errors      []string `json:"errors"`
+}
+
+func NewCheck(name, description string) *Check {
+	return &Check{Name: name, Description: description, Items: []Item{}, Errors: []string{}}
+}
+
+func (c *Check) AddError(region string, err error) {
+	if region != "" {
+		c.Errors = append(c.Errors, fmt.Sprintf("%s: %s", region, err))
+		return
+	}
+	c.Errors = append(c.Errors, err.Error())
+}
+
+// Done sorts the items and sets the status, blocked when there are items
+// even if some regions failed.
+func (c *Check) Done() {
+	sort.SliceStable(c.Items, func(i, j int) bool {
+		if c.Items[i].AccountID != c.Items[j].AccountID {
+			return c.Items[i].AccountID < c.Items[j].AccountID
+		}
+		if c.Items[i].Region != c.Items[j].Region {
+			return c.Items[i].Region < c.Items[j].Region
+		}
+		return c.Items[i].ID < c.Items[j].ID
+	})
+
+	switch {
+	case len(c.Items) > 0:
+		c.Status = StatusBlocked
+	case len(c.Errors) > 0:
+		c.Status = StatusUnknown
+	default:
+		c.Status = StatusOK
+	}
+}
+
+// DumpResource is a resource of an aws-dump output, only the fields used to
+// find the references are read.
+type DumpResource struct {
+	ID        string      `json:"id"`
+	ARN       string      `json:"arn"`
+	Service   string      `json:"service"`
+	Type      string      `json:"type"`
+	AccountID string      `json:"account_id"`
+	Region    string      `json:"region"`
+	Metadata  interface{} `json:"metadata"`
+}
+
+// LoadDumpResources reads the resources of an aws-dump output in the json
+// output format, with or without --output-schema, or in the jsonl one.
+func LoadDumpResources(data []byte) ([]DumpResource, error) {
+	result := []DumpResource{}
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) > 0 && trimmed[0] == '[' {
+		err := json.Unmarshal(trimmed, &result)
+		return result, err
+	}
+
+	envelope := struct {
+		SchemaVersion int            `json:"schema_version"`
+		Resources     []DumpResource `json:"resources"`
+	}{}
+	if json.Unmarshal(trimmed, &envelope) == nil && envelope.SchemaVersion > 0 {
+		return envelope.Resources, nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(trimmed))
+	for {
+		resource := DumpResource{}
+		err := decoder.Decode(&resource)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, resource)
+	}
+	return result, nil
+}
+
+// referencePaths returns the paths of the values of the metadata containing
+// the pattern.
+func referencePaths(value interface{}, path string, pattern *regexp.Regexp, paths []string) []string {
+	if len(paths) >= maxReferencePaths {
+		return paths
+	}
+
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		keys := []string{}
+		for key := range typed {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			paths = referencePaths(typed[key], strings.TrimPrefix(path+"."+key, "."), pattern, paths)
+		}
+	case []interface{}:
+		for i, child := range typed {
+			paths = referencePaths(child, strings.TrimPrefix(fmt.Sprintf("%s.%d", path, i), "."), pattern, paths)
+		}
+	case string:
+		if pattern.MatchString(typed) {
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+// FindReferences returns the resources of the other accounts referencing the
+// account in their metadata, e.g. in the principals of a policy, the ARN of a
+// role or a peering connection.
+func FindReferences(resources []DumpResource, accountID string) []Item {
+	pattern := regexp.MustCompile(`(^|[^0-9])` + regexp.QuoteMeta(accountID) + `([^0-9]|$)`)
+
+	items := []Item{}
+	for _, resource := range resources {
+		if resource.AccountID == accountID || resource.Service == "aws-dump" {
+			continue
+		}
+
+		paths := referencePaths(resource.Metadata, "", pattern, []string{})
+		if len(paths) == 0 {
+			continue
+		}
+
+		id := resource.ARN
+		if id == "" {
+			id = resource.ID
+		}
+		items = append(items, Item{
+			AccountID: resource.AccountID,
+			Region:    resource.Region,
+			ID:        id,
+			Reason:    fmt.Sprintf("%s:%s references the account in %s", resource.Service, resource.Type, strings.Join(paths, ", ")),
+		})
+	}
+	return items
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckDone(t *testing.T) {
+	check := NewCheck(CheckBuckets, "")
+	check.Done()
+	require.Equal(t, StatusOK, check.Status)
+
+	check.AddError("eu-west-1", fmt.Errorf("AccessDenied"))
+	check.Done()
+	require.Equal(t, StatusUnknown, check.Status)
+	require.Equal(t, []string{"eu-west-1: AccessDenied"}, check.Errors)
+
+	check.Items = append(check.Items, Item{Region: "us-east-1", ID: "b"}, Item{Region: "eu-west-1", ID: "a"})
+	check.Done()
+	require.Equal(t, StatusBlocked, check.Status)
+	require.Equal(t, "a", check.Items[0].ID)
+}
+
+func TestLoadDumpResources(t *testing.T) {
+	resources, err := LoadDumpResources([]byte(`[{"id": "a", "account_id": "111111111111"}]`))
+	require.NoError(t, err)
+	require.Len(t, resources, 1)
+
+	resources, err = LoadDumpResources([]byte(`{"schema_version": 1, "run": {}, "resources": [{"id": "a"}, {"id": "b"}]}`))
+	require.NoError(t, err)
+	require.Len(t, resources, 2)
+
+	resources, err = LoadDumpResources([]byte("{\"id\": \"a\"}\n{\"id\": \"b\"}\n{\"id\": \"c\"}\n"))
+	require.NoError(t, err)
+	require.Len(t, resources, 3)
+	require.Equal(t, "c", resources[2].ID)
+}
+
+func TestFindReferences(t *testing.T) {
+	resources := []DumpResource{
+		{
+			ARN:       "arn:aws:s3:::shared-artifacts",
+			Service:   "s3",
+			Type:      "bucket",
+			AccountID: "222222222222",
+			Metadata: map[string]interface{}{
+				"Policy": `{"Statement":[{"Principal":{"AWS":"arn:aws:iam::123456789012:root"}}]}`,
+			},
+		},
+		{
+			ID:        "pcx-0123",
+			Service:   "ec2",
+			Type:      "vpc-peering-connection",
+			AccountID: "222222222222",
+			Region:    "eu-west-1",
+			Metadata: map[string]interface{}{
+				"AccepterVpcInfo": map[string]interface{}{"OwnerId": "123456789012"},
+				"Tags":            []interface{}{map[string]interface{}{"Key": "peer", "Value": "123456789012"}},
+			},
+		},
+		// the resources of the account itself
+		{ID: "i-0123", Service: "ec2", Type: "instance", AccountID: "123456789012", Metadata: map[string]interface{}{"OwnerId": "123456789012"}},
+		// a longer number containing the account id
+		{ID: "other", Service: "ec2", Type: "instance", AccountID: "222222222222", Metadata: map[string]interface{}{"Size": "91234567890123"}},
+	}
+
+	items := FindReferences(resources, "123456789012")
+	require.Len(t, items, 2)
+	require.Equal(t, "arn:aws:s3:::shared-artifacts", items[0].ID)
+	require.Equal(t, "s3:bucket references the account in Policy", items[0].Reason)
+	require.Equal(t, "pcx-0123", items[1].ID)
+	require.Equal(t, "eu-west-1", items[1].Region)
+	require.Equal(t, "ec2:vpc-peering-connection references the account in AccepterVpcInfo.OwnerId, Tags.0.Value", items[1].Reason)
+}
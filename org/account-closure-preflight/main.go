@@ -0,0 +1,314 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/ec2"
+	"github.com/aws/aws-sdk-go/service/rds"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/savingsplans"
+	"github.com/aws/aws-sdk-go/service/sts"
+	"github.com/hamstah/awstools/common"
+	kingpin "gopkg.in/alecthomas/kingpin.v2"
+)
+
+var (
+	regions   = kingpin.Flag("regions", "Regions to check, defaults to all the enabled regions. Can be repeated or comma separated.").Strings()
+	dumps     = kingpin.Flag("dump", "aws-dump output of other accounts to search for references to the account. Can be repeated.").ExistingFiles()
+	skipCheck = kingpin.Flag("skip-check", "Don't run this check. Can be repeated.").Enums(CheckRunningInstances, CheckBuckets, CheckReservations, CheckSavingsPlans, CheckCrossAccount)
+	format    = kingpin.Flag("format", "Output format.").Default("text").Enum("text", "json")
+)
+
+type Preflight struct {
+	AccountID string   `json:"account_id"`
+	Regions   []string `json:"regions"`
+	Blocked   bool     `json:"blocked"`
+	Checks    []*Check `json:"checks"`
+}
+
+func listRegions(sess *session.Session, conf *aws.Config) ([]string, error) {
+	result := []string{}
+	for _, value := range *regions {
+		for _, region := range strings.Split(value, ",") {
+			if region = strings.TrimSpace(region); region != "" {
+				result = append(result, region)
+			}
+		}
+	}
+	if len(result) > 0 {
+		return result, nil
+	}
+
+	// only the regions enabled in the account are returned
+	res, err := ec2.New(sess, conf).DescribeRegions(&ec2.DescribeRegionsInput{})
+	if err != nil {
+		return nil, err
+	}
+	for _, region := range res.Regions {
+		result = append(result, aws.StringValue(region.RegionName))
+	}
+	return result, nil
+}
+
+func regionConfig(conf *aws.Config, region string) *aws.Config {
+	return conf.Copy(&aws.Config{Region: aws.String(region)})
+}
+
+func checkRunningInstances(sess *session.Session, conf *aws.Config, accountID string, regionNames []string) *Check {
+	check := NewCheck(CheckRunningInstances, "No pending, running or stopping EC2 instances")
+	for _, region := range regionNames {
+		client := ec2.New(sess, regionConfig(conf, region))
+		input := &ec2.DescribeInstancesInput{Filters: []*ec2.Filter{{
+			Name:   aws.String("instance-state-name"),
+			Values: aws.StringSlice([]string{ec2.InstanceStateNamePending, ec2.InstanceStateNameRunning, ec2.InstanceStateNameStopping}),
+		}}}
+		err := client.DescribeInstancesPages(input, func(page *ec2.DescribeInstancesOutput, lastPage bool) bool {
+			for _, reservation := range page.Reservations {
+				for _, instance := range reservation.Instances {
+					state := ""
+					if instance.State != nil {
+						state = aws.StringValue(instance.State.Name)
+					}
+					check.Items = append(check.Items, Item{
+						AccountID: accountID,
+						Region:    region,
+						ID:        aws.StringValue(instance.InstanceId),
+						Reason:    fmt.Sprintf("%s %s", aws.StringValue(instance.InstanceType), state),
+					})
+				}
+			}
+			return true
+		})
+		if err != nil {
+			check.AddError(region, err)
+		}
+	}
+	return check
+}
+
+// bucketRegion returns the region of the bucket from its location constraint.
+func bucketRegion(client *s3.S3, bucket string) (string, error) {
+	res, err := client.GetBucketLocation(&s3.GetBucketLocationInput{Bucket: aws.String(bucket)})
+	if err != nil {
+		return "", err
+	}
+	switch location := aws.StringValue(res.LocationConstraint); location {
+	case "":
+		return "us-east-1", nil
+	case "EU":
+		return "eu-west-1", nil
+	default:
+		return location, nil
+	}
+}
+
+func checkBuckets(sess *session.Session, conf *aws.Config, accountID string) *Check {
+	check := NewCheck(CheckBuckets, "No S3 buckets with objects or object lock")
+	client := s3.New(sess, regionConfig(conf, "us-east-1"))
+	res, err := client.ListBuckets(&s3.ListBucketsInput{})
+	if err != nil {
+		check.AddError("", err)
+		return check
+	}
+
+	clients := map[string]*s3.S3{}
+	for _, bucket := range res.Buckets {
+		name := aws.StringValue(bucket.Name)
+		region, err := bucketRegion(client, name)
+		if err != nil {
+			check.AddError("", fmt.Errorf("%s: %s", name, err))
+			continue
+		}
+		regionClient, ok := clients[region]
+		if !ok {
+			regionClient = s3.New(sess, regionConfig(conf, region))
+			clients[region] = regionClient
+		}
+
+		// the versions include the noncurrent versions and the delete markers,
+		// which also need to be deleted
+		versions, err := regionClient.ListObjectVersions(&s3.ListObjectVersionsInput{Bucket: aws.String(name), MaxKeys: aws.Int64(1)})
+		if err != nil {
+			check.AddError(region, fmt.Errorf("%s: %s", name, err))
+		} else if len(versions.Versions) > 0 || len(versions.DeleteMarkers) > 0 {
+			check.Items = append(check.Items, Item{AccountID: accountID, Region: region, ID: name, Reason: "not empty"})
+		}
+
+		lock, err := regionClient.GetObjectLockConfiguration(&s3.GetObjectLockConfigurationInput{Bucket: aws.String(name)})
+		if err != nil {
+			if awsErr, ok := err.(awserr.Error); !ok || awsErr.Code() != "ObjectLockConfigurationNotFoundError" {
+				check.AddError(region, fmt.Errorf("%s: %s", name, err))
+			}
+		} else if lock.ObjectLockConfiguration != nil && aws.StringValue(lock.ObjectLockConfiguration.ObjectLockEnabled) == s3.ObjectLockEnabledEnabled {
+			check.Items = append(check.Items, Item{AccountID: accountID, Region: region, ID: name, Reason: "object lock enabled, objects under retention or legal hold can't be deleted"})
+		}
+	}
+	return check
+}
+
+func checkReservations(sess *session.Session, conf *aws.Config, accountID string, regionNames []string) *Check {
+	check := NewCheck(CheckReservations, "No active EC2 or RDS reservations")
+	for _, region := range regionNames {
+		res, err := ec2.New(sess, regionConfig(conf, region)).DescribeReservedInstances(&ec2.DescribeReservedInstancesInput{
+			Filters: []*ec2.Filter{{Name: aws.String("state"), Values: aws.StringSlice([]string{ec2.ReservedInstanceStateActive})}},
+		})
+		if err != nil {
+			check.AddError(region, err)
+		} else {
+			for _, reservation := range res.ReservedInstances {
+				check.Items = append(check.Items, Item{
+					AccountID: accountID,
+					Region:    region,
+					ID:        aws.StringValue(reservation.ReservedInstancesId),
+					Reason: fmt.Sprintf("%d %s EC2 reserved until %s", aws.Int64Value(reservation.InstanceCount),
+						aws.StringValue(reservation.InstanceType), aws.TimeValue(reservation.End).Format("2006-01-02")),
+				})
+			}
+		}
+
+		err = rds.New(sess, regionConfig(conf, region)).DescribeReservedDBInstancesPages(&rds.DescribeReservedDBInstancesInput{},
+			func(page *rds.DescribeReservedDBInstancesOutput, lastPage bool) bool {
+				for _, reservation := range page.ReservedDBInstances {
+					if aws.StringValue(reservation.State) != "active" {
+						continue
+					}
+					check.Items = append(check.Items, Item{
+						AccountID: accountID,
+						Region:    region,
+						ID:        aws.StringValue(reservation.ReservedDBInstanceId),
+						Reason: fmt.Sprintf("%d %s RDS reserved since %s for %d days", aws.Int64Value(reservation.DBInstanceCount),
+							aws.StringValue(reservation.DBInstanceClass), aws.TimeValue(reservation.StartTime).Format("2006-01-02"),
+							aws.Int64Value(reservation.Duration)/86400),
+					})
+				}
+				return true
+			})
+		if err != nil {
+			check.AddError(region, err)
+		}
+	}
+	return check
+}
+
+func checkSavingsPlans(sess *session.Session, conf *aws.Config, accountID string) *Check {
+	check := NewCheck(CheckSavingsPlans, "No active or queued Savings Plans")
+	client := savingsplans.New(sess, regionConfig(conf, "us-east-1"))
+	input := &savingsplans.DescribeSavingsPlansInput{States: aws.StringSlice([]string{"active", "queued", "payment-pending"})}
+	for {
+		res, err := client.DescribeSavingsPlans(input)
+		if err != nil {
+			check.AddError("", err)
+			break
+		}
+		for _, plan := range res.SavingsPlans {
+			check.Items = append(check.Items, Item{
+				AccountID: accountID,
+				ID:        aws.StringValue(plan.SavingsPlanId),
+				Reason: fmt.Sprintf("%s %s savings plan of %s/hour until %s", aws.StringValue(plan.State),
+					aws.StringValue(plan.SavingsPlanType), aws.StringValue(plan.Commitment), aws.StringValue(plan.End)),
+			})
+		}
+		if aws.StringValue(res.NextToken) == "" {
+			break
+		}
+		input.NextToken = res.NextToken
+	}
+	return check
+}
+
+func checkCrossAccount(accountID string) *Check {
+	check := NewCheck(CheckCrossAccount, "No resources of other accounts referencing the account in the dumps")
+	for _, filename := range *dumps {
+		data, err := ioutil.ReadFile(filename)
+		if err != nil {
+			check.AddError("", err)
+			continue
+		}
+		resources, err := LoadDumpResources(data)
+		if err != nil {
+			check.AddError("", fmt.Errorf("invalid dump %s: %s", filename, err))
+			continue
+		}
+		check.Items = append(check.Items, FindReferences(resources, accountID)...)
+	}
+	return check
+}
+
+func main() {
+	kingpin.CommandLine.Name = "org-account-closure-preflight"
+	kingpin.CommandLine.Help = "Check that an account can be closed and list what blocks it."
+	flags := common.HandleFlags()
+
+	sess, conf := common.OpenSession(flags)
+
+	identity, err := sts.New(sess, conf).GetCallerIdentity(&sts.GetCallerIdentityInput{})
+	common.FatalOnErrorW(err, "failed to get the account")
+	accountID := aws.StringValue(identity.Account)
+
+	regionNames, err := listRegions(sess, conf)
+	common.FatalOnErrorW(err, "failed to list the regions")
+
+	skipped := map[string]bool{}
+	for _, name := range *skipCheck {
+		skipped[name] = true
+	}
+
+	preflight := &Preflight{AccountID: accountID, Regions: regionNames, Checks: []*Check{}}
+	run := func(name string, check func() *Check) {
+		if skipped[name] {
+			return
+		}
+		result := check()
+		result.Done()
+		preflight.Checks = append(preflight.Checks, result)
+		preflight.Blocked = preflight.Blocked || result.Status != StatusOK
+	}
+	run(CheckRunningInstances, func() *Check { return checkRunningInstances(sess, conf, accountID, regionNames) })
+	run(CheckBuckets, func() *Check { return checkBuckets(sess, conf, accountID) })
+	run(CheckReservations, func() *Check { return checkReservations(sess, conf, accountID, regionNames) })
+	run(CheckSavingsPlans, func() *Check { return checkSavingsPlans(sess, conf, accountID) })
+	if len(*dumps) > 0 {
+		run(CheckCrossAccount, func() *Check { return checkCrossAccount(accountID) })
+	}
+
+	if *format == "json" {
+		data, err := json.MarshalIndent(preflight, "", "  ")
+		common.FatalOnErrorW(err, "failed to serialise the checks")
+		fmt.Println(string(data))
+	} else {
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "CHECK\tSTATUS\tITEMS\tDESCRIPTION")
+		for _, check := range preflight.Checks {
+			fmt.Fprintf(w, "%s\t%s\t%d\t%s\n", check.Name, check.Status, len(check.Items), check.Description)
+		}
+		w.Flush()
+
+		if preflight.Blocked {
+			fmt.Printf("\nBlocking items of %s:\n", accountID)
+			w = tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+			for _, check := range preflight.Checks {
+				for _, item := range check.Items {
+					fmt.Fprintf(w, "[ ] %s\t%s\t%s\t%s\t%s\n", check.Name, item.AccountID, item.Region, item.ID, item.Reason)
+				}
+				for _, message := range check.Errors {
+					fmt.Fprintf(w, "[ ] %s\t\t\terror\t%s\n", check.Name, message)
+				}
+			}
+			w.Flush()
+		} else {
+			fmt.Printf("\nNothing blocks the closure of %s\n", accountID)
+		}
+	}
+
+	if preflight.Blocked {
+		os.Exit(1)
+	}
+}
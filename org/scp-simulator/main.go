@@ -0,0 +1,241 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/organizations"
+	"github.com/hamstah/awstools/common"
+	kingpin "gopkg.in/alecthomas/kingpin.v2"
+)
+
+var (
+	accountID     = kingpin.Flag("account-id", "Account to simulate the requests in.").String()
+	actions       = kingpin.Flag("action", "Action to simulate, e.g. s3:PutObject. Can be repeated.").Required().Strings()
+	resourceARNs  = kingpin.Flag("resource", "ARN of the resource of the actions. Can be repeated.").Default("*").Strings()
+	principalARN  = kingpin.Flag("principal-arn", "ARN of the principal making the requests, sets aws:PrincipalArn.").String()
+	requestRegion = kingpin.Flag("request-region", "Region the requests are made to, sets aws:RequestedRegion.").String()
+	contextValues = kingpin.Flag("context", "Value of a condition key, key=value. Can be repeated, repeat the key for multiple values.").Strings()
+	pathFile      = kingpin.Flag("path-file", "Load the SCPs from the JSON output of a previous run instead of Organizations.").ExistingFile()
+	format        = kingpin.Flag("format", "Output format.").Default("text").Enum("text", "json")
+)
+
+type Simulation struct {
+	Path      *Path       `json:"path"`
+	Decisions []*Decision `json:"decisions"`
+}
+
+// fetchPath returns the SCPs attached to the account, its parent
+// organizational units and the root.
+func fetchPath(client *organizations.Organizations, accountID string) (*Path, error) {
+	organization, err := client.DescribeOrganization(&organizations.DescribeOrganizationInput{})
+	if err != nil {
+		return nil, err
+	}
+	path := &Path{
+		AccountID:         accountID,
+		ManagementAccount: aws.StringValue(organization.Organization.MasterAccountId) == accountID,
+	}
+
+	account, err := client.DescribeAccount(&organizations.DescribeAccountInput{AccountId: aws.String(accountID)})
+	if err != nil {
+		return nil, err
+	}
+	levels := []*Level{{Type: LevelAccount, ID: accountID, Name: aws.StringValue(account.Account.Name)}}
+
+	childID := accountID
+	for {
+		parents, err := client.ListParents(&organizations.ListParentsInput{ChildId: aws.String(childID)})
+		if err != nil {
+			return nil, err
+		}
+		if len(parents.Parents) == 0 {
+			break
+		}
+		parent := parents.Parents[0]
+		childID = aws.StringValue(parent.Id)
+
+		if aws.StringValue(parent.Type) == organizations.ParentTypeRoot {
+			roots, err := client.ListRoots(&organizations.ListRootsInput{})
+			if err != nil {
+				return nil, err
+			}
+			level := &Level{Type: LevelRoot, ID: childID}
+			for _, root := range roots.Roots {
+				if aws.StringValue(root.Id) != childID {
+					continue
+				}
+				level.Name = aws.StringValue(root.Name)
+				for _, policyType := range root.PolicyTypes {
+					if aws.StringValue(policyType.Type) == organizations.PolicyTypeServiceControlPolicy && aws.StringValue(policyType.Status) == organizations.PolicyTypeStatusEnabled {
+						path.SCPsEnabled = true
+					}
+				}
+			}
+			levels = append([]*Level{level}, levels...)
+			break
+		}
+
+		unit, err := client.DescribeOrganizationalUnit(&organizations.DescribeOrganizationalUnitInput{OrganizationalUnitId: aws.String(childID)})
+		if err != nil {
+			return nil, err
+		}
+		levels = append([]*Level{{Type: LevelOrganizationalUnit, ID: childID, Name: aws.StringValue(unit.OrganizationalUnit.Name)}}, levels...)
+	}
+
+	policies := map[string]*Policy{}
+	for _, level := range levels {
+		ids := []string{}
+		err := client.ListPoliciesForTargetPages(&organizations.ListPoliciesForTargetInput{
+			TargetId: aws.String(level.ID),
+			Filter:   aws.String(organizations.PolicyTypeServiceControlPolicy),
+		}, func(page *organizations.ListPoliciesForTargetOutput, lastPage bool) bool {
+			for _, policy := range page.Policies {
+				ids = append(ids, aws.StringValue(policy.Id))
+			}
+			return true
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		level.Policies = []*Policy{}
+		for _, id := range ids {
+			if _, ok := policies[id]; !ok {
+				res, err := client.DescribePolicy(&organizations.DescribePolicyInput{PolicyId: aws.String(id)})
+				if err != nil {
+					return nil, err
+				}
+				policies[id] = &Policy{
+					ID:      id,
+					Name:    aws.StringValue(res.Policy.PolicySummary.Name),
+					Content: aws.StringValue(res.Policy.Content),
+				}
+			}
+			level.Policies = append(level.Policies, policies[id])
+		}
+	}
+
+	path.Levels = levels
+	return path, nil
+}
+
+func loadPath(filename string) (*Path, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	simulation := &Simulation{}
+	err = json.Unmarshal(data, simulation)
+	if err != nil {
+		return nil, err
+	}
+	if simulation.Path == nil {
+		return nil, fmt.Errorf("no path in %s", filename)
+	}
+	return simulation.Path, nil
+}
+
+// requestContext returns the condition keys of the requests, in lower case.
+func requestContext(accountID string) (map[string][]string, error) {
+	context := map[string][]string{"aws:principalaccount": {accountID}}
+	if *principalARN != "" {
+		context["aws:principalarn"] = []string{*principalARN}
+	}
+	if *requestRegion != "" {
+		context["aws:requestedregion"] = []string{*requestRegion}
+	}
+	for _, value := range *contextValues {
+		parts := strings.SplitN(value, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid context %s, should be key=value", value)
+		}
+		key := strings.ToLower(parts[0])
+		context[key] = append(context[key], parts[1])
+	}
+	return context, nil
+}
+
+func policyNames(policies []*Policy) string {
+	names := []string{}
+	for _, policy := range policies {
+		names = append(names, policy.Name)
+	}
+	return strings.Join(names, ", ")
+}
+
+func main() {
+	kingpin.CommandLine.Name = "org-scp-simulator"
+	kingpin.CommandLine.Help = "Evaluate whether the SCPs attached from the root of the organization to an account block actions."
+	flags := common.HandleFlags()
+
+	var path *Path
+	var err error
+	if *pathFile != "" {
+		path, err = loadPath(*pathFile)
+		common.FatalOnErrorW(err, "failed to load the path")
+	} else {
+		if *accountID == "" {
+			common.Fatalln("--account-id is required without --path-file")
+		}
+		sess, conf := common.OpenSession(flags)
+		path, err = fetchPath(organizations.New(sess, conf), *accountID)
+		common.FatalOnErrorW(err, fmt.Sprintf("failed to get the SCPs of %s", *accountID))
+	}
+
+	context, err := requestContext(path.AccountID)
+	common.FatalOnErrorW(err, "invalid context")
+
+	simulation := &Simulation{Path: path, Decisions: []*Decision{}}
+	blocked := false
+	for _, action := range *actions {
+		for _, resource := range *resourceARNs {
+			decision, err := path.Evaluate(Request{Action: action, Resource: resource, Context: context})
+			common.FatalOnErrorW(err, fmt.Sprintf("failed to evaluate %s on %s", action, resource))
+			simulation.Decisions = append(simulation.Decisions, decision)
+			if decision.Decision == DecisionBlocked {
+				blocked = true
+			}
+		}
+	}
+
+	if *format == "json" {
+		output, err := json.MarshalIndent(simulation, "", "  ")
+		common.FatalOnErrorW(err, "failed to serialise the simulation")
+		fmt.Println(string(output))
+	} else {
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "LEVEL\tID\tNAME\tSCPS")
+		for _, level := range path.Levels {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", level.Type, level.ID, level.Name, policyNames(level.Policies))
+		}
+		w.Flush()
+
+		fmt.Println()
+		w = tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "ACTION\tRESOURCE\tDECISION\tLEVEL\tPOLICY\tSTATEMENT\tREASON\tMISSING CONTEXT")
+		for _, decision := range simulation.Decisions {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+				decision.Action,
+				decision.Resource,
+				decision.Decision,
+				decision.LevelID,
+				decision.Policy,
+				decision.Statement,
+				decision.Reason,
+				strings.Join(decision.MissingContext, ","),
+			)
+		}
+		w.Flush()
+	}
+
+	if blocked {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,321 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	LevelRoot               = "root"
+	LevelOrganizationalUnit = "organizational-unit"
+	LevelAccount            = "account"
+
+	DecisionAllowed = "allowed"
+	DecisionBlocked = "blocked"
+)
+
+// stringOrSlice is a policy element that can be a value or a list of values,
+// booleans and numbers are converted to strings.
+type stringOrSlice []string
+
+func (s *stringOrSlice) UnmarshalJSON(data []byte) error {
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+
+	values, ok := value.([]interface{})
+	if !ok {
+		values = []interface{}{value}
+	}
+
+	*s = []string{}
+	for _, value := range values {
+		*s = append(*s, fmt.Sprint(value))
+	}
+	return nil
+}
+
+type Statement struct {
+	Sid         string                              `json:"Sid"`
+	Effect      string                              `json:"Effect"`
+	Action      stringOrSlice                       `json:"Action"`
+	NotAction   stringOrSlice                       `json:"NotAction"`
+	Resource    stringOrSlice                       `json:"Resource"`
+	NotResource stringOrSlice                       `json:"NotResource"`
+	Condition   map[string]map[string]stringOrSlice `json:"Condition"`
+}
+
+type PolicyDocument struct {
+	Statement []Statement
+}
+
+func (d *PolicyDocument) UnmarshalJSON(data []byte) error {
+	raw := struct {
+		Statement json.RawMessage `json:"Statement"`
+	}{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	statement := Statement{}
+	if err := json.Unmarshal(raw.Statement, &statement); err == nil {
+		d.Statement = []Statement{statement}
+		return nil
+	}
+	return json.Unmarshal(raw.Statement, &d.Statement)
+}
+
+// Policy is a service control policy, Content is its JSON document.
+type Policy struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Content string `json:"content"`
+}
+
+// Level is the root, an organizational unit or the account, with the SCPs
+// attached to it.
+type Level struct {
+	Type     string    `json:"type"`
+	ID       string    `json:"id"`
+	Name     string    `json:"name"`
+	Policies []*Policy `json:"policies"`
+}
+
+// Path is the levels of the organization from the root to the account.
+type Path struct {
+	AccountID string `json:"account_id"`
+	// the management account is not affected by the SCPs
+	ManagementAccount bool     `json:"management_account"`
+	SCPsEnabled       bool     `json:"scps_enabled"`
+	Levels            []*Level `json:"levels"`
+}
+
+// Request is the action on the resource with the values of the condition
+// keys, the keys are in lower case.
+type Request struct {
+	Action   string
+	Resource string
+	Context  map[string][]string
+}
+
+type Decision struct {
+	Action    string `json:"action"`
+	Resource  string `json:"resource"`
+	Decision  string `json:"decision"`
+	Reason    string `json:"reason"`
+	LevelType string `json:"level_type,omitempty"`
+	LevelID   string `json:"level_id,omitempty"`
+	Policy    string `json:"policy,omitempty"`
+	Statement string `json:"statement,omitempty"`
+	// condition keys of the statements evaluated that were not in the request
+	MissingContext []string `json:"missing_context,omitempty"`
+}
+
+// wildcardMatch matches the value with a pattern with * and ? wildcards.
+func wildcardMatch(pattern, value string, ignoreCase bool) bool {
+	expression := regexp.QuoteMeta(pattern)
+	expression = strings.Replace(expression, `\*`, ".*", -1)
+	expression = strings.Replace(expression, `\?`, ".", -1)
+	if ignoreCase {
+		expression = "(?i)" + expression
+	}
+	matched, _ := regexp.MatchString("^"+expression+"$", value)
+	return matched
+}
+
+func matchesAny(patterns []string, value string, ignoreCase bool) bool {
+	for _, pattern := range patterns {
+		if wildcardMatch(pattern, value, ignoreCase) {
+			return true
+		}
+	}
+	return false
+}
+
+// evaluation evaluates the statements of the policies for a request and
+// keeps the condition keys missing from the request.
+type evaluation struct {
+	request Request
+	missing map[string]bool
+}
+
+func (e *evaluation) matchesCondition(operator, key string, values []string) (bool, error) {
+	contextValues, present := e.request.Context[strings.ToLower(key)]
+	if !present {
+		e.missing[key] = true
+	}
+
+	ifExists := strings.HasSuffix(operator, "IfExists")
+	operator = strings.TrimSuffix(operator, "IfExists")
+	forAllValues := strings.HasPrefix(operator, "ForAllValues:")
+	operator = strings.TrimPrefix(strings.TrimPrefix(operator, "ForAllValues:"), "ForAnyValue:")
+
+	if operator == "Null" {
+		return len(values) > 0 && strings.EqualFold(values[0], "true") != present, nil
+	}
+
+	var match func(pattern, value string) bool
+	negated := false
+	switch operator {
+	case "StringEquals", "StringNotEquals", "ArnEquals", "ArnNotEquals":
+		match = func(pattern, value string) bool { return pattern == value }
+	case "StringEqualsIgnoreCase", "StringNotEqualsIgnoreCase", "Bool":
+		match = strings.EqualFold
+	case "StringLike", "StringNotLike", "ArnLike", "ArnNotLike":
+		match = func(pattern, value string) bool { return wildcardMatch(pattern, value, false) }
+	default:
+		return false, fmt.Errorf("unsupported condition operator %s", operator)
+	}
+	if strings.Contains(operator, "Not") {
+		negated = true
+	}
+
+	// the negated operators and IfExists match when the key is missing,
+	// and so does ForAllValues with no values
+	if !present {
+		return negated || ifExists || forAllValues, nil
+	}
+
+	matched := 0
+	for _, contextValue := range contextValues {
+		for _, value := range values {
+			if match(value, contextValue) {
+				matched++
+				break
+			}
+		}
+	}
+	if negated {
+		return matched == 0, nil
+	}
+	if forAllValues {
+		return matched == len(contextValues), nil
+	}
+	return matched > 0, nil
+}
+
+// matches returns true when the statement applies to the request, regardless
+// of its effect. All the conditions need to match.
+func (e *evaluation) matches(statement Statement) (bool, error) {
+	if len(statement.Action) > 0 && !matchesAny(statement.Action, e.request.Action, true) {
+		return false, nil
+	}
+	if len(statement.NotAction) > 0 && matchesAny(statement.NotAction, e.request.Action, true) {
+		return false, nil
+	}
+	if len(statement.Resource) > 0 && !matchesAny(statement.Resource, e.request.Resource, false) {
+		return false, nil
+	}
+	if len(statement.NotResource) > 0 && matchesAny(statement.NotResource, e.request.Resource, false) {
+		return false, nil
+	}
+
+	for operator, conditions := range statement.Condition {
+		for key, values := range conditions {
+			matched, err := e.matchesCondition(operator, key, values)
+			if err != nil || !matched {
+				return false, err
+			}
+		}
+	}
+	return true, nil
+}
+
+func statementName(statement Statement, index int) string {
+	if statement.Sid != "" {
+		return statement.Sid
+	}
+	return fmt.Sprintf("#%d", index)
+}
+
+// Evaluate returns whether the SCPs block the request. A statement denying
+// the request at any level blocks it, and each level needs a statement
+// allowing it, SCPs don't grant permissions but bound the ones of IAM.
+func (p *Path) Evaluate(request Request) (*Decision, error) {
+	decision := &Decision{Action: request.Action, Resource: request.Resource, Decision: DecisionAllowed}
+	if p.ManagementAccount {
+		decision.Reason = "the management account is not affected by SCPs"
+		return decision, nil
+	}
+	if !p.SCPsEnabled {
+		decision.Reason = "SCPs are not enabled in the organization"
+		return decision, nil
+	}
+
+	e := &evaluation{request: request, missing: map[string]bool{}}
+	defer func() {
+		decision.MissingContext = []string{}
+		for key := range e.missing {
+			decision.MissingContext = append(decision.MissingContext, key)
+		}
+		sort.Strings(decision.MissingContext)
+	}()
+
+	documents := map[*Policy]*PolicyDocument{}
+	for _, level := range p.Levels {
+		for _, policy := range level.Policies {
+			document := &PolicyDocument{}
+			if err := json.Unmarshal([]byte(policy.Content), document); err != nil {
+				return nil, fmt.Errorf("invalid policy %s: %s", policy.Name, err)
+			}
+			documents[policy] = document
+		}
+	}
+
+	for _, level := range p.Levels {
+		for _, policy := range level.Policies {
+			for i, statement := range documents[policy].Statement {
+				if statement.Effect != "Deny" {
+					continue
+				}
+				matched, err := e.matches(statement)
+				if err != nil {
+					return nil, fmt.Errorf("%s of %s: %s", statementName(statement, i), policy.Name, err)
+				}
+				if matched {
+					decision.Decision = DecisionBlocked
+					decision.Reason = "explicitly denied"
+					decision.LevelType, decision.LevelID = level.Type, level.ID
+					decision.Policy, decision.Statement = policy.Name, statementName(statement, i)
+					return decision, nil
+				}
+			}
+		}
+	}
+
+	for _, level := range p.Levels {
+		allowed := false
+		for _, policy := range level.Policies {
+			for i, statement := range documents[policy].Statement {
+				if statement.Effect != "Allow" {
+					continue
+				}
+				matched, err := e.matches(statement)
+				if err != nil {
+					return nil, fmt.Errorf("%s of %s: %s", statementName(statement, i), policy.Name, err)
+				}
+				if matched {
+					allowed = true
+					break
+				}
+			}
+			if allowed {
+				break
+			}
+		}
+
+		if !allowed {
+			decision.Decision = DecisionBlocked
+			decision.Reason = "not allowed by any SCP of the level"
+			decision.LevelType, decision.LevelID = level.Type, level.ID
+			return decision, nil
+		}
+	}
+
+	decision.Reason = "allowed at every level"
+	return decision, nil
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	fullAccess  = `{"Version":"2012-10-17","Statement":{"Effect":"Allow","Action":"*","Resource":"*"}}`
+	denyRegions = `{
+		"Version": "2012-10-17",
+		"Statement": [{
+			"Sid": "DenyOutsideEU",
+			"Effect": "Deny",
+			"NotAction": ["iam:*", "sts:*"],
+			"Resource": "*",
+			"Condition": {
+				"StringNotEquals": {"aws:RequestedRegion": ["eu-west-1", "eu-central-1"]},
+				"ArnNotLike": {"aws:PrincipalArn": "arn:aws:iam::*:role/admin"}
+			}
+		}]
+	}`
+	allowS3 = `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":["s3:Get*","s3:List*"],"Resource":"*"}]}`
+)
+
+func testPath(accountPolicy string) *Path {
+	return &Path{
+		AccountID:   "123456789012",
+		SCPsEnabled: true,
+		Levels: []*Level{
+			{Type: LevelRoot, ID: "r-ab12", Policies: []*Policy{{Name: "FullAWSAccess", Content: fullAccess}}},
+			{Type: LevelOrganizationalUnit, ID: "ou-ab12-11111111", Policies: []*Policy{
+				{Name: "FullAWSAccess", Content: fullAccess},
+				{Name: "DenyRegions", Content: denyRegions},
+			}},
+			{Type: LevelAccount, ID: "123456789012", Policies: []*Policy{{Name: "Account", Content: accountPolicy}}},
+		},
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	path := testPath(fullAccess)
+
+	// the region is missing so StringNotEquals matches
+	decision, err := path.Evaluate(Request{Action: "ec2:RunInstances", Resource: "*", Context: map[string][]string{}})
+	require.NoError(t, err)
+	require.Equal(t, DecisionBlocked, decision.Decision)
+	require.Equal(t, "ou-ab12-11111111", decision.LevelID)
+	require.Equal(t, "DenyRegions", decision.Policy)
+	require.Equal(t, "DenyOutsideEU", decision.Statement)
+	require.Equal(t, []string{"aws:PrincipalArn", "aws:RequestedRegion"}, decision.MissingContext)
+
+	decision, err = path.Evaluate(Request{Action: "ec2:RunInstances", Resource: "*", Context: map[string][]string{"aws:requestedregion": {"eu-west-1"}}})
+	require.NoError(t, err)
+	require.Equal(t, DecisionAllowed, decision.Decision)
+	require.Equal(t, "allowed at every level", decision.Reason)
+
+	decision, err = path.Evaluate(Request{Action: "ec2:RunInstances", Resource: "*", Context: map[string][]string{
+		"aws:requestedregion": {"us-east-1"},
+		"aws:principalarn":    {"arn:aws:iam::123456789012:role/admin"},
+	}})
+	require.NoError(t, err)
+	require.Equal(t, DecisionAllowed, decision.Decision)
+
+	// NotAction
+	decision, err = path.Evaluate(Request{Action: "IAM:CreateRole", Resource: "*", Context: map[string][]string{"aws:requestedregion": {"us-east-1"}}})
+	require.NoError(t, err)
+	require.Equal(t, DecisionAllowed, decision.Decision)
+}
+
+func TestEvaluateImplicitDeny(t *testing.T) {
+	path := testPath(allowS3)
+	context := map[string][]string{"aws:requestedregion": {"eu-west-1"}}
+
+	decision, err := path.Evaluate(Request{Action: "s3:GetObject", Resource: "arn:aws:s3:::bucket/key", Context: context})
+	require.NoError(t, err)
+	require.Equal(t, DecisionAllowed, decision.Decision)
+
+	decision, err = path.Evaluate(Request{Action: "s3:PutObject", Resource: "arn:aws:s3:::bucket/key", Context: context})
+	require.NoError(t, err)
+	require.Equal(t, DecisionBlocked, decision.Decision)
+	require.Equal(t, "not allowed by any SCP of the level", decision.Reason)
+	require.Equal(t, LevelAccount, decision.LevelType)
+	require.Equal(t, "", decision.Policy)
+
+	path.ManagementAccount = true
+	decision, err = path.Evaluate(Request{Action: "s3:PutObject", Resource: "*", Context: context})
+	require.NoError(t, err)
+	require.Equal(t, DecisionAllowed, decision.Decision)
+}
+
+func TestEvaluateConditions(t *testing.T) {
+	e := &evaluation{
+		request: Request{Context: map[string][]string{
+			"aws:requestedregion": {"eu-west-1"},
+			"aws:tagkeys":         {"team", "env"},
+		}},
+		missing: map[string]bool{},
+	}
+
+	for _, test := range []struct {
+		operator string
+		key      string
+		values   []string
+		expected bool
+	}{
+		{"StringEquals", "aws:RequestedRegion", []string{"eu-west-1"}, true},
+		{"StringLike", "aws:RequestedRegion", []string{"eu-*"}, true},
+		{"StringNotLike", "aws:RequestedRegion", []string{"eu-*"}, false},
+		{"StringEquals", "aws:PrincipalTag/team", []string{"data"}, false},
+		{"StringEqualsIfExists", "aws:PrincipalTag/team", []string{"data"}, true},
+		{"Null", "aws:PrincipalTag/team", []string{"true"}, true},
+		{"Null", "aws:RequestedRegion", []string{"true"}, false},
+		{"ForAllValues:StringEquals", "aws:TagKeys", []string{"team", "env", "owner"}, true},
+		{"ForAllValues:StringEquals", "aws:TagKeys", []string{"team"}, false},
+		{"ForAnyValue:StringEquals", "aws:TagKeys", []string{"team"}, true},
+	} {
+		matched, err := e.matchesCondition(test.operator, test.key, test.values)
+		require.NoError(t, err)
+		require.Equal(t, test.expected, matched, "%s %s %v", test.operator, test.key, test.values)
+	}
+
+	_, err := e.matchesCondition("NumericLessThan", "s3:max-keys", []string{"10"})
+	require.Error(t, err)
+}
@@ -0,0 +1,231 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/hamstah/awstools/common"
+	kingpin "gopkg.in/alecthomas/kingpin.v2"
+)
+
+var (
+	buckets   = kingpin.Flag("bucket", "Terraform state bucket to verify. Can be repeated.").Required().Strings()
+	lockTable = kingpin.Flag("lock-table", "DynamoDB lock table of the state buckets, in --region.").String()
+	format    = kingpin.Flag("format", "Output format.").Default("text").Enum("text", "json")
+)
+
+// terraform uses a LockID string hash key for the lock table
+const lockTableHashKey = "LockID"
+
+type Check struct {
+	Resource string `json:"resource"`
+	Name     string `json:"name"`
+	Passed   bool   `json:"passed"`
+	Details  string `json:"details"`
+}
+
+func isErrorCode(err error, code string) bool {
+	if awsErr, ok := err.(awserr.Error); ok {
+		return awsErr.Code() == code
+	}
+	return false
+}
+
+// newBucketClient returns a client in the region of the bucket.
+func newBucketClient(sess *session.Session, conf *aws.Config, bucket string) (*s3.S3, error) {
+	location, err := s3.New(sess, conf).GetBucketLocation(&s3.GetBucketLocationInput{Bucket: aws.String(bucket)})
+	if err != nil {
+		return nil, err
+	}
+
+	region := s3.NormalizeBucketLocation(aws.StringValue(location.LocationConstraint))
+	return s3.New(sess, conf.Copy(&aws.Config{Region: aws.String(region)})), nil
+}
+
+func verifyBucket(client *s3.S3, bucket string) ([]Check, error) {
+	checks := []Check{}
+
+	versioning, err := client.GetBucketVersioning(&s3.GetBucketVersioningInput{Bucket: aws.String(bucket)})
+	if err != nil {
+		return nil, err
+	}
+	checks = append(checks, Check{
+		Resource: bucket,
+		Name:     "versioning",
+		Passed:   aws.StringValue(versioning.Status) == s3.BucketVersioningStatusEnabled,
+		Details:  fmt.Sprintf("status: %s", aws.StringValue(versioning.Status)),
+	})
+
+	objectLock := ""
+	lock, err := client.GetObjectLockConfiguration(&s3.GetObjectLockConfigurationInput{Bucket: aws.String(bucket)})
+	if err != nil {
+		if !isErrorCode(err, "ObjectLockConfigurationNotFoundError") {
+			return nil, err
+		}
+	} else if lock.ObjectLockConfiguration != nil {
+		objectLock = aws.StringValue(lock.ObjectLockConfiguration.ObjectLockEnabled)
+	}
+	mfaDelete := aws.StringValue(versioning.MFADelete)
+	checks = append(checks, Check{
+		Resource: bucket,
+		Name:     "mfa-delete-or-object-lock",
+		Passed:   mfaDelete == s3.MFADeleteStatusEnabled || objectLock == s3.ObjectLockEnabledEnabled,
+		Details:  fmt.Sprintf("mfa delete: %s, object lock: %s", mfaDelete, objectLock),
+	})
+
+	encryption := Check{Resource: bucket, Name: "encryption"}
+	rules, err := client.GetBucketEncryption(&s3.GetBucketEncryptionInput{Bucket: aws.String(bucket)})
+	if err != nil {
+		if !isErrorCode(err, "ServerSideEncryptionConfigurationNotFoundError") {
+			return nil, err
+		}
+		encryption.Details = "no default encryption"
+	} else {
+		algorithms := []string{}
+		for _, rule := range rules.ServerSideEncryptionConfiguration.Rules {
+			if rule.ApplyServerSideEncryptionByDefault == nil {
+				continue
+			}
+			algorithm := aws.StringValue(rule.ApplyServerSideEncryptionByDefault.SSEAlgorithm)
+			if key := aws.StringValue(rule.ApplyServerSideEncryptionByDefault.KMSMasterKeyID); key != "" {
+				algorithm = fmt.Sprintf("%s (%s)", algorithm, key)
+			}
+			algorithms = append(algorithms, algorithm)
+		}
+		encryption.Passed = len(algorithms) > 0
+		encryption.Details = strings.Join(algorithms, ", ")
+	}
+	checks = append(checks, encryption)
+
+	publicAccessBlock := Check{Resource: bucket, Name: "public-access-block"}
+	block, err := client.GetPublicAccessBlock(&s3.GetPublicAccessBlockInput{Bucket: aws.String(bucket)})
+	if err != nil {
+		if !isErrorCode(err, "NoSuchPublicAccessBlockConfiguration") {
+			return nil, err
+		}
+		publicAccessBlock.Details = "no public access block"
+	} else {
+		configuration := block.PublicAccessBlockConfiguration
+		publicAccessBlock.Passed = aws.BoolValue(configuration.BlockPublicAcls) &&
+			aws.BoolValue(configuration.BlockPublicPolicy) &&
+			aws.BoolValue(configuration.IgnorePublicAcls) &&
+			aws.BoolValue(configuration.RestrictPublicBuckets)
+		if !publicAccessBlock.Passed {
+			publicAccessBlock.Details = "not all the public access settings are blocked"
+		}
+	}
+	checks = append(checks, publicAccessBlock)
+
+	bucketPolicy := Check{Resource: bucket, Name: "bucket-policy"}
+	policy, err := client.GetBucketPolicy(&s3.GetBucketPolicyInput{Bucket: aws.String(bucket)})
+	if err != nil {
+		if !isErrorCode(err, "NoSuchBucketPolicy") {
+			return nil, err
+		}
+		bucketPolicy.Details = "no bucket policy"
+	} else {
+		document, err := ParsePolicy(aws.StringValue(policy.Policy))
+		if err != nil {
+			return nil, err
+		}
+
+		problems := []string{}
+		if !document.DeniesInsecureTransport() {
+			problems = append(problems, "insecure transport is not denied")
+		}
+		if public := document.PublicStatements(); len(public) > 0 {
+			problems = append(problems, fmt.Sprintf("public statements: %s", strings.Join(public, ", ")))
+		}
+		bucketPolicy.Passed = len(problems) == 0
+		bucketPolicy.Details = strings.Join(problems, ", ")
+	}
+	checks = append(checks, bucketPolicy)
+
+	return checks, nil
+}
+
+func verifyLockTable(client *dynamodb.DynamoDB, table string) (Check, error) {
+	check := Check{Resource: table, Name: "lock-table"}
+
+	output, err := client.DescribeTable(&dynamodb.DescribeTableInput{TableName: aws.String(table)})
+	if err != nil {
+		if !isErrorCode(err, dynamodb.ErrCodeResourceNotFoundException) {
+			return check, err
+		}
+		check.Details = "table not found"
+		return check, nil
+	}
+
+	hashKey := ""
+	for _, key := range output.Table.KeySchema {
+		if aws.StringValue(key.KeyType) == dynamodb.KeyTypeHash {
+			hashKey = aws.StringValue(key.AttributeName)
+		}
+	}
+	hashKeyType := ""
+	for _, attribute := range output.Table.AttributeDefinitions {
+		if aws.StringValue(attribute.AttributeName) == hashKey {
+			hashKeyType = aws.StringValue(attribute.AttributeType)
+		}
+	}
+
+	status := aws.StringValue(output.Table.TableStatus)
+	check.Passed = hashKey == lockTableHashKey && hashKeyType == dynamodb.ScalarAttributeTypeS && status == dynamodb.TableStatusActive
+	check.Details = fmt.Sprintf("status: %s, hash key: %s (%s)", status, hashKey, hashKeyType)
+	return check, nil
+}
+
+func main() {
+	kingpin.CommandLine.Name = "s3-terraform-state-verifier"
+	kingpin.CommandLine.Help = "Verify the configuration of terraform state buckets and lock tables."
+	flags := common.HandleFlags()
+
+	session, conf := common.OpenSession(flags)
+
+	checks := []Check{}
+	for _, bucket := range *buckets {
+		client, err := newBucketClient(session, conf, bucket)
+		common.FatalOnErrorW(err, fmt.Sprintf("failed to get the region of %s", bucket))
+
+		bucketChecks, err := verifyBucket(client, bucket)
+		common.FatalOnErrorW(err, fmt.Sprintf("failed to verify %s", bucket))
+		checks = append(checks, bucketChecks...)
+	}
+
+	if *lockTable != "" {
+		check, err := verifyLockTable(dynamodb.New(session, conf), *lockTable)
+		common.FatalOnErrorW(err, "failed to verify the lock table")
+		checks = append(checks, check)
+	}
+
+	if *format == "json" {
+		data, err := json.MarshalIndent(checks, "", "  ")
+		common.FatalOnErrorW(err, "failed to serialise the checks")
+		fmt.Println(string(data))
+	} else {
+		writer := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(writer, "RESOURCE\tCHECK\tRESULT\tDETAILS")
+		for _, check := range checks {
+			result := "pass"
+			if !check.Passed {
+				result = "FAIL"
+			}
+			fmt.Fprintf(writer, "%s\t%s\t%s\t%s\n", check.Resource, check.Name, result, check.Details)
+		}
+		writer.Flush()
+	}
+
+	for _, check := range checks {
+		if !check.Passed {
+			os.Exit(1)
+		}
+	}
+}
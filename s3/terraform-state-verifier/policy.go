@@ -0,0 +1,141 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// stringOrSlice is a policy element that can be a value or a list of values,
+// booleans and numbers are converted to strings.
+type stringOrSlice []string
+
+func (s *stringOrSlice) UnmarshalJSON(data []byte) error {
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+
+	values, ok := value.([]interface{})
+	if !ok {
+		values = []interface{}{value}
+	}
+
+	*s = []string{}
+	for _, value := range values {
+		*s = append(*s, fmt.Sprint(value))
+	}
+	return nil
+}
+
+// principal is either "*" or a map of principal type to principals.
+type principal struct {
+	Everyone   bool
+	Principals map[string]stringOrSlice
+}
+
+func (p *principal) UnmarshalJSON(data []byte) error {
+	var value string
+	if err := json.Unmarshal(data, &value); err == nil {
+		p.Everyone = value == "*"
+		return nil
+	}
+
+	if err := json.Unmarshal(data, &p.Principals); err != nil {
+		return err
+	}
+	for _, principal := range p.Principals["AWS"] {
+		if principal == "*" {
+			p.Everyone = true
+		}
+	}
+	return nil
+}
+
+type Statement struct {
+	Sid       string                              `json:"Sid"`
+	Effect    string                              `json:"Effect"`
+	Principal *principal                          `json:"Principal"`
+	Action    stringOrSlice                       `json:"Action"`
+	Condition map[string]map[string]stringOrSlice `json:"Condition"`
+}
+
+type PolicyDocument struct {
+	Statement []Statement
+}
+
+func (d *PolicyDocument) UnmarshalJSON(data []byte) error {
+	raw := struct {
+		Statement json.RawMessage `json:"Statement"`
+	}{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	statement := Statement{}
+	if err := json.Unmarshal(raw.Statement, &statement); err == nil {
+		d.Statement = []Statement{statement}
+		return nil
+	}
+	return json.Unmarshal(raw.Statement, &d.Statement)
+}
+
+func ParsePolicy(policy string) (*PolicyDocument, error) {
+	document := &PolicyDocument{}
+	err := json.Unmarshal([]byte(policy), document)
+	return document, err
+}
+
+func (s *Statement) coversAllActions() bool {
+	for _, action := range s.Action {
+		if action == "*" || strings.EqualFold(action, "s3:*") {
+			return true
+		}
+	}
+	return false
+}
+
+// DeniesInsecureTransport is true when the policy denies all the S3 actions
+// for everyone without TLS.
+func (d *PolicyDocument) DeniesInsecureTransport() bool {
+	for _, statement := range d.Statement {
+		if statement.Effect != "Deny" || statement.Principal == nil || !statement.Principal.Everyone {
+			continue
+		}
+		if !statement.coversAllActions() {
+			continue
+		}
+		for operator, conditions := range statement.Condition {
+			if !strings.EqualFold(operator, "Bool") {
+				continue
+			}
+			for key, values := range conditions {
+				if strings.EqualFold(key, "aws:SecureTransport") && len(values) == 1 && strings.EqualFold(values[0], "false") {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// PublicStatements returns the Sid (or index) of the statements allowing
+// everyone without any condition.
+func (d *PolicyDocument) PublicStatements() []string {
+	public := []string{}
+	for i, statement := range d.Statement {
+		if statement.Effect != "Allow" || statement.Principal == nil || !statement.Principal.Everyone {
+			continue
+		}
+		if len(statement.Condition) > 0 {
+			continue
+		}
+
+		sid := statement.Sid
+		if sid == "" {
+			sid = fmt.Sprintf("#%d", i)
+		}
+		public = append(public, sid)
+	}
+	return public
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPolicy(t *testing.T) {
+	document, err := ParsePolicy(`{
+		"Version": "2012-10-17",
+		"Statement": [
+			{
+				"Sid": "DenyInsecureTransport",
+				"Effect": "Deny",
+				"Principal": "*",
+				"Action": "s3:*",
+				"Resource": ["arn:aws:s3:::state", "arn:aws:s3:::state/*"],
+				"Condition": {"Bool": {"aws:SecureTransport": false}}
+			},
+			{
+				"Effect": "Allow",
+				"Principal": {"AWS": ["*"]},
+				"Action": ["s3:GetObject"],
+				"Resource": "arn:aws:s3:::state/*"
+			},
+			{
+				"Sid": "OrganizationRead",
+				"Effect": "Allow",
+				"Principal": {"AWS": "*"},
+				"Action": "s3:GetObject",
+				"Resource": "arn:aws:s3:::state/*",
+				"Condition": {"StringEquals": {"aws:PrincipalOrgID": "o-abcdefghij"}}
+			}
+		]
+	}`)
+	require.NoError(t, err)
+	require.True(t, document.DeniesInsecureTransport())
+	require.Equal(t, []string{"#1"}, document.PublicStatements())
+
+	document, err = ParsePolicy(`{
+		"Statement": {
+			"Effect": "Deny",
+			"Principal": {"AWS": "arn:aws:iam::123456789012:root"},
+			"Action": "s3:*",
+			"Condition": {"Bool": {"aws:SecureTransport": "false"}}
+		}
+	}`)
+	require.NoError(t, err)
+	require.False(t, document.DeniesInsecureTransport())
+	require.Equal(t, []string{}, document.PublicStatements())
+}
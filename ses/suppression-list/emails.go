@@ -0,0 +1,57 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// NormaliseEmail validates an address and returns it in lower case, the
+// suppression list is case insensitive.
+func NormaliseEmail(value string) (string, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	at := strings.LastIndex(value, "@")
+	if at <= 0 || at == len(value)-1 || strings.ContainsAny(value, " \t,;<>") {
+		return "", fmt.Errorf("invalid email address %s", value)
+	}
+	return value, nil
+}
+
+// NormaliseEmails returns the addresses normalised, sorted and without
+// duplicates.
+func NormaliseEmails(values []string) ([]string, error) {
+	seen := map[string]bool{}
+	result := []string{}
+	for _, value := range values {
+		email, err := NormaliseEmail(value)
+		if err != nil {
+			return nil, err
+		}
+		if !seen[email] {
+			seen[email] = true
+			result = append(result, email)
+		}
+	}
+	sort.Strings(result)
+	return result, nil
+}
+
+// ReadEmails reads one address per line, ignoring empty lines and comments
+// starting with #.
+func ReadEmails(reader io.Reader) ([]string, error) {
+	emails := []string{}
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if index := strings.Index(line, "#"); index >= 0 {
+			line = line[:index]
+		}
+		line = strings.TrimSpace(line)
+		if line != "" {
+			emails = append(emails, line)
+		}
+	}
+	return emails, scanner.Err()
+}
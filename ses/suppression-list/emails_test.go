@@ -0,0 +1,46 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormaliseEmails(t *testing.T) {
+	emails, err := NormaliseEmails([]string{" Alice@Example.com", "bob@example.com", "alice@example.com"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"alice@example.com", "bob@example.com"}, emails)
+
+	for _, invalid := range []string{"", "alice", "@example.com", "alice@", "alice bob@example.com", "a@example.com,b@example.com"} {
+		_, err := NormaliseEmail(invalid)
+		require.Error(t, err, invalid)
+	}
+}
+
+func TestReadEmails(t *testing.T) {
+	emails, err := ReadEmails(strings.NewReader("# bounced\nalice@example.com\n\nbob@example.com # complaint\n"))
+	require.NoError(t, err)
+	require.Equal(t, []string{"alice@example.com", "bob@example.com"}, emails)
+}
+
+func TestNewDailyMetrics(t *testing.T) {
+	first := time.Date(2021, 2, 1, 0, 0, 0, 0, time.UTC)
+	second := first.AddDate(0, 0, 1)
+
+	daily := NewDailyMetrics(map[string]map[time.Time]float64{
+		MetricSend:      {second: 1000, first: 200},
+		MetricDelivery:  {second: 990, first: 190},
+		MetricBounce:    {second: 10, first: 10},
+		MetricComplaint: {second: 2},
+	})
+
+	require.Equal(t, []*DailyMetrics{
+		{Date: "2021-02-01", Send: 200, Delivery: 190, Bounce: 10, BounceRate: 0.05, Review: true},
+		{Date: "2021-02-02", Send: 1000, Delivery: 990, Bounce: 10, Complaint: 2, BounceRate: 0.01, ComplaintRate: 0.002, Review: true},
+	}, daily)
+
+	daily = NewDailyMetrics(map[string]map[time.Time]float64{MetricBounce: {first: 1}})
+	require.Equal(t, []*DailyMetrics{{Date: "2021-02-01", Bounce: 1}}, daily)
+}
@@ -0,0 +1,272 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/cloudwatch"
+	"github.com/aws/aws-sdk-go/service/sesv2"
+	"github.com/hamstah/awstools/common"
+	kingpin "gopkg.in/alecthomas/kingpin.v2"
+)
+
+var (
+	add          = kingpin.Flag("add", "Address to add to the suppression list. Can be repeated.").Strings()
+	remove       = kingpin.Flag("remove", "Address to remove from the suppression list. Can be repeated.").Strings()
+	addFile      = kingpin.Flag("add-file", "File with the addresses to add, one per line.").String()
+	removeFile   = kingpin.Flag("remove-file", "File with the addresses to remove, one per line.").String()
+	reason       = kingpin.Flag("reason", "Reason of the addresses added.").Default(sesv2.SuppressionListReasonBounce).Enum(sesv2.SuppressionListReasonBounce, sesv2.SuppressionListReasonComplaint)
+	emails       = kingpin.Flag("email", "Only show this address instead of listing the suppression list. Can be repeated.").Strings()
+	filterReason = kingpin.Flag("filter-reason", "Only list the addresses suppressed for this reason. Can be repeated.").Enums(sesv2.SuppressionListReasonBounce, sesv2.SuppressionListReasonComplaint)
+	days         = kingpin.Flag("days", "Only list the addresses suppressed in the last days, 0 for all.").Default("0").Int()
+	metrics      = kingpin.Flag("metrics", "Export the daily send, delivery, bounce and complaint metrics of the account instead.").Default("false").Bool()
+	metricsDays  = kingpin.Flag("metrics-days", "Number of days of metrics to export.").Default("14").Int()
+	dryRun       = kingpin.Flag("dry-run", "Print the changes without applying them.").Default("false").Bool()
+	format       = kingpin.Flag("format", "Output format.").Default("text").Enum("text", "json", "csv")
+)
+
+type Destination struct {
+	Email          string     `json:"email"`
+	Reason         string     `json:"reason"`
+	LastUpdateTime *time.Time `json:"last_update_time"`
+	MessageID      string     `json:"message_id,omitempty"`
+	FeedbackID     string     `json:"feedback_id,omitempty"`
+}
+
+func isNotFound(err error) bool {
+	if awsErr, ok := err.(awserr.Error); ok {
+		return awsErr.Code() == sesv2.ErrCodeNotFoundException
+	}
+	return false
+}
+
+func readEmailsFile(filename string) []string {
+	if filename == "" {
+		return []string{}
+	}
+
+	file, err := os.Open(filename)
+	common.FatalOnErrorW(err, "failed to open the file")
+	defer file.Close()
+
+	result, err := ReadEmails(file)
+	common.FatalOnErrorW(err, fmt.Sprintf("failed to read %s", filename))
+	return result
+}
+
+func listDestinations(client *sesv2.SESV2) ([]*Destination, error) {
+	input := &sesv2.ListSuppressedDestinationsInput{}
+	if len(*filterReason) > 0 {
+		input.Reasons = aws.StringSlice(*filterReason)
+	}
+	if *days > 0 {
+		input.StartDate = aws.Time(time.Now().AddDate(0, 0, -*days))
+	}
+
+	destinations := []*Destination{}
+	for {
+		page, err := client.ListSuppressedDestinations(input)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, summary := range page.SuppressedDestinationSummaries {
+			destinations = append(destinations, &Destination{
+				Email:          aws.StringValue(summary.EmailAddress),
+				Reason:         aws.StringValue(summary.Reason),
+				LastUpdateTime: summary.LastUpdateTime,
+			})
+		}
+
+		if page.NextToken == nil {
+			break
+		}
+		input.NextToken = page.NextToken
+	}
+	return destinations, nil
+}
+
+func getDestinations(client *sesv2.SESV2, addresses []string) ([]*Destination, error) {
+	destinations := []*Destination{}
+	for _, address := range addresses {
+		res, err := client.GetSuppressedDestination(&sesv2.GetSuppressedDestinationInput{EmailAddress: aws.String(address)})
+		if isNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		destination := &Destination{
+			Email:          aws.StringValue(res.SuppressedDestination.EmailAddress),
+			Reason:         aws.StringValue(res.SuppressedDestination.Reason),
+			LastUpdateTime: res.SuppressedDestination.LastUpdateTime,
+		}
+		if attributes := res.SuppressedDestination.Attributes; attributes != nil {
+			destination.MessageID = aws.StringValue(attributes.MessageId)
+			destination.FeedbackID = aws.StringValue(attributes.FeedbackId)
+		}
+		destinations = append(destinations, destination)
+	}
+	return destinations, nil
+}
+
+func formatTime(value *time.Time) string {
+	if value == nil {
+		return ""
+	}
+	return value.UTC().Format(time.RFC3339)
+}
+
+func printDestinations(destinations []*Destination) {
+	switch *format {
+	case "json":
+		data, err := json.MarshalIndent(destinations, "", "  ")
+		common.FatalOnErrorW(err, "failed to serialise the addresses")
+		fmt.Println(string(data))
+	case "csv":
+		w := csv.NewWriter(os.Stdout)
+		w.Write([]string{"email", "reason", "last_update_time", "message_id", "feedback_id"})
+		for _, destination := range destinations {
+			w.Write([]string{destination.Email, destination.Reason, formatTime(destination.LastUpdateTime), destination.MessageID, destination.FeedbackID})
+		}
+		w.Flush()
+		common.FatalOnErrorW(w.Error(), "failed to write the addresses")
+	default:
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "EMAIL\tREASON\tLAST UPDATE")
+		for _, destination := range destinations {
+			fmt.Fprintf(w, "%s\t%s\t%s\n", destination.Email, destination.Reason, formatTime(destination.LastUpdateTime))
+		}
+		w.Flush()
+	}
+}
+
+func exportMetrics(client *cloudwatch.CloudWatch) {
+	end := time.Now().UTC().Truncate(24 * time.Hour).Add(24 * time.Hour)
+	start := end.AddDate(0, 0, -*metricsDays)
+
+	sums := map[string]map[time.Time]float64{}
+	for _, metric := range Metrics {
+		res, err := client.GetMetricStatistics(&cloudwatch.GetMetricStatisticsInput{
+			Namespace:  aws.String("AWS/SES"),
+			MetricName: aws.String(metric),
+			StartTime:  aws.Time(start),
+			EndTime:    aws.Time(end),
+			Period:     aws.Int64(24 * 60 * 60),
+			Statistics: aws.StringSlice([]string{cloudwatch.StatisticSum}),
+		})
+		common.FatalOnErrorW(err, fmt.Sprintf("failed to get the %s metric", metric))
+
+		sums[metric] = map[time.Time]float64{}
+		for _, datapoint := range res.Datapoints {
+			sums[metric][aws.TimeValue(datapoint.Timestamp)] = aws.Float64Value(datapoint.Sum)
+		}
+	}
+
+	daily := NewDailyMetrics(sums)
+	formatRate := func(rate float64) string {
+		return strconv.FormatFloat(rate*100, 'f', 2, 64) + "%"
+	}
+
+	switch *format {
+	case "json":
+		data, err := json.MarshalIndent(daily, "", "  ")
+		common.FatalOnErrorW(err, "failed to serialise the metrics")
+		fmt.Println(string(data))
+	case "csv":
+		w := csv.NewWriter(os.Stdout)
+		w.Write([]string{"date", "send", "delivery", "bounce", "complaint", "bounce_rate", "complaint_rate", "review"})
+		for _, day := range daily {
+			w.Write([]string{
+				day.Date,
+				strconv.FormatFloat(day.Send, 'f', -1, 64),
+				strconv.FormatFloat(day.Delivery, 'f', -1, 64),
+				strconv.FormatFloat(day.Bounce, 'f', -1, 64),
+				strconv.FormatFloat(day.Complaint, 'f', -1, 64),
+				strconv.FormatFloat(day.BounceRate, 'f', -1, 64),
+				strconv.FormatFloat(day.ComplaintRate, 'f', -1, 64),
+				strconv.FormatBool(day.Review),
+			})
+		}
+		w.Flush()
+		common.FatalOnErrorW(w.Error(), "failed to write the metrics")
+	default:
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "DATE\tSEND\tDELIVERY\tBOUNCE\tCOMPLAINT\tBOUNCE RATE\tCOMPLAINT RATE\tREVIEW")
+		for _, day := range daily {
+			review := ""
+			if day.Review {
+				review = "yes"
+			}
+			fmt.Fprintf(w, "%s\t%.0f\t%.0f\t%.0f\t%.0f\t%s\t%s\t%s\n", day.Date, day.Send, day.Delivery, day.Bounce, day.Complaint, formatRate(day.BounceRate), formatRate(day.ComplaintRate), review)
+		}
+		w.Flush()
+	}
+}
+
+func main() {
+	kingpin.CommandLine.Name = "ses-suppression-list"
+	kingpin.CommandLine.Help = "Query, add or remove addresses in the SES account-level suppression list."
+	flags := common.HandleFlags()
+
+	session, conf := common.OpenSession(flags)
+
+	if *metrics {
+		exportMetrics(cloudwatch.New(session, conf))
+		return
+	}
+
+	client := sesv2.New(session, conf)
+
+	additions, err := NormaliseEmails(append(*add, readEmailsFile(*addFile)...))
+	common.FatalOnError(err)
+	removals, err := NormaliseEmails(append(*remove, readEmailsFile(*removeFile)...))
+	common.FatalOnError(err)
+
+	if len(additions) == 0 && len(removals) == 0 {
+		var destinations []*Destination
+		if len(*emails) > 0 {
+			addresses, err := NormaliseEmails(*emails)
+			common.FatalOnError(err)
+			destinations, err = getDestinations(client, addresses)
+			common.FatalOnErrorW(err, "failed to get the addresses")
+		} else {
+			destinations, err = listDestinations(client)
+			common.FatalOnErrorW(err, "failed to list the suppression list")
+		}
+		printDestinations(destinations)
+		return
+	}
+
+	for _, address := range removals {
+		fmt.Printf("- %s\n", address)
+		if *dryRun {
+			continue
+		}
+		_, err := client.DeleteSuppressedDestination(&sesv2.DeleteSuppressedDestinationInput{EmailAddress: aws.String(address)})
+		if isNotFound(err) {
+			continue
+		}
+		common.FatalOnErrorW(err, fmt.Sprintf("failed to remove %s", address))
+	}
+
+	for _, address := range additions {
+		fmt.Printf("+ %s (%s)\n", address, *reason)
+		if *dryRun {
+			continue
+		}
+		_, err := client.PutSuppressedDestination(&sesv2.PutSuppressedDestinationInput{
+			EmailAddress: aws.String(address),
+			Reason:       reason,
+		})
+		common.FatalOnErrorW(err, fmt.Sprintf("failed to add %s", address))
+	}
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"sort"
+	"time"
+)
+
+const (
+	MetricSend      = "Send"
+	MetricDelivery  = "Delivery"
+	MetricBounce    = "Bounce"
+	MetricComplaint = "Complaint"
+
+	// rates above which SES reviews the account
+	bounceRateReview    = 0.05
+	complaintRateReview = 0.001
+)
+
+var Metrics = []string{MetricSend, MetricDelivery, MetricBounce, MetricComplaint}
+
+type DailyMetrics struct {
+	Date          string  `json:"date"`
+	Send          float64 `json:"send"`
+	Delivery      float64 `json:"delivery"`
+	Bounce        float64 `json:"bounce"`
+	Complaint     float64 `json:"complaint"`
+	BounceRate    float64 `json:"bounce_rate"`
+	ComplaintRate float64 `json:"complaint_rate"`
+	// Review is set when a rate is above the level where SES reviews the account
+	Review bool `json:"review"`
+}
+
+// NewDailyMetrics returns the metrics per day from the daily sums of each
+// metric, with the bounce and complaint rates of the emails sent that day.
+func NewDailyMetrics(sums map[string]map[time.Time]float64) []*DailyMetrics {
+	days := map[string]*DailyMetrics{}
+	for metric, values := range sums {
+		for timestamp, value := range values {
+			date := timestamp.UTC().Format("2006-01-02")
+			if days[date] == nil {
+				days[date] = &DailyMetrics{Date: date}
+			}
+			switch metric {
+			case MetricSend:
+				days[date].Send += value
+			case MetricDelivery:
+				days[date].Delivery += value
+			case MetricBounce:
+				days[date].Bounce += value
+			case MetricComplaint:
+				days[date].Complaint += value
+			}
+		}
+	}
+
+	result := []*DailyMetrics{}
+	for _, day := range days {
+		if day.Send > 0 {
+			day.BounceRate = day.Bounce / day.Send
+			day.ComplaintRate = day.Complaint / day.Send
+		}
+		day.Review = day.BounceRate >= bounceRateReview || day.ComplaintRate >= complaintRateReview
+		result = append(result, day)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Date < result[j].Date })
+	return result
+}
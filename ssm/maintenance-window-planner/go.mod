@@ -0,0 +1,9 @@
+module github.com/hamstah/awstools/ssm/maintenance-window-planner
+
+go 1.15
+
+require (
+	github.com/aws/aws-sdk-go v1.36.31
+	github.com/hamstah/awstools/common v0.0.0-20210118215825-d772cda2a155
+	gopkg.in/alecthomas/kingpin.v2 v2.2.6
+)
@@ -0,0 +1,261 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ssm"
+	"github.com/hamstah/awstools/common"
+	kingpin "gopkg.in/alecthomas/kingpin.v2"
+)
+
+var (
+	windowIDs  = kingpin.Flag("window-id", "Only use this maintenance window. Can be repeated.").Strings()
+	namePrefix = kingpin.Flag("name-prefix", "Only use the maintenance windows with this name prefix.").String()
+	pause      = kingpin.Flag("pause", "Disable the enabled maintenance windows.").Default("false").Bool()
+	shift      = kingpin.Flag("shift", "Delay the enabled maintenance windows that would run before --until to --until.").Default("false").Bool()
+	until      = kingpin.Flag("until", "End of the freeze period for --shift (e.g. 2021-02-01 or 2021-02-01T09:00:00Z).").String()
+	restore    = kingpin.Flag("restore", "Restore the maintenance windows from the state file.").Default("false").Bool()
+	stateFile  = kingpin.Flag("state-file", "File to save the maintenance windows to before changing them, and to restore them from.").Default("maintenance-windows-state.json").String()
+	dryRun     = kingpin.Flag("dry-run", "Print the changes without applying them.").Default("false").Bool()
+	format     = kingpin.Flag("format", "Output format.").Default("text").Enum("text", "json")
+)
+
+// State is the content of the state file, the windows as they were before the changes.
+type State struct {
+	CreatedAt time.Time                         `json:"created_at"`
+	Changes   []Change                          `json:"changes"`
+	Windows   []*ssm.GetMaintenanceWindowOutput `json:"windows"`
+}
+
+type WindowDetails struct {
+	Window  Window                         `json:"window"`
+	Targets []*ssm.MaintenanceWindowTarget `json:"targets"`
+	Tasks   []*ssm.MaintenanceWindowTask   `json:"tasks"`
+}
+
+func main() {
+	kingpin.CommandLine.Name = "ssm-maintenance-window-planner"
+	kingpin.CommandLine.Help = "List, pause or shift SSM maintenance windows for a freeze period and restore them."
+	flags := common.HandleFlags()
+
+	actions := 0
+	for _, action := range []bool{*pause, *shift, *restore} {
+		if action {
+			actions++
+		}
+	}
+	if actions > 1 {
+		common.Fatalln("Only one of --pause, --shift and --restore can be used")
+	}
+
+	session, conf := common.OpenSession(flags)
+	client := ssm.New(session, conf)
+
+	if *restore {
+		restoreWindows(client)
+		return
+	}
+
+	windows, err := listWindows(client)
+	common.FatalOnErrorW(err, "failed to list the maintenance windows")
+	windows = Select(windows, *windowIDs, *namePrefix)
+
+	switch {
+	case *pause:
+		changeWindows(client, windows, ActionPause, time.Time{})
+	case *shift:
+		if *until == "" {
+			common.Fatalln("--shift requires --until")
+		}
+		untilTime, err := ParseTime(*until)
+		common.FatalOnErrorW(err, "invalid --until")
+		changeWindows(client, windows, ActionShift, untilTime)
+	default:
+		printWindows(client, windows)
+	}
+}
+
+func listWindows(client *ssm.SSM) ([]Window, error) {
+	windows := []Window{}
+	var parseErr error
+	err := client.DescribeMaintenanceWindowsPages(&ssm.DescribeMaintenanceWindowsInput{},
+		func(page *ssm.DescribeMaintenanceWindowsOutput, lastPage bool) bool {
+			for _, identity := range page.WindowIdentities {
+				window := Window{
+					ID:               *identity.WindowId,
+					Name:             aws.StringValue(identity.Name),
+					Enabled:          aws.BoolValue(identity.Enabled),
+					Schedule:         aws.StringValue(identity.Schedule),
+					ScheduleTimezone: aws.StringValue(identity.ScheduleTimezone),
+					StartDate:        aws.StringValue(identity.StartDate),
+					EndDate:          aws.StringValue(identity.EndDate),
+				}
+				if aws.StringValue(identity.NextExecutionTime) != "" {
+					next, err := ParseTime(*identity.NextExecutionTime)
+					if err != nil {
+						parseErr = err
+						return false
+					}
+					window.NextExecutionTime = &next
+				}
+				windows = append(windows, window)
+			}
+			return true
+		})
+	if err != nil {
+		return nil, err
+	}
+	return windows, parseErr
+}
+
+func printWindows(client *ssm.SSM, windows []Window) {
+	details := []WindowDetails{}
+	for _, window := range windows {
+		windowDetails := WindowDetails{Window: window}
+
+		err := client.DescribeMaintenanceWindowTargetsPages(&ssm.DescribeMaintenanceWindowTargetsInput{WindowId: aws.String(window.ID)},
+			func(page *ssm.DescribeMaintenanceWindowTargetsOutput, lastPage bool) bool {
+				windowDetails.Targets = append(windowDetails.Targets, page.Targets...)
+				return true
+			})
+		common.FatalOnErrorW(err, "failed to list the maintenance window targets")
+
+		err = client.DescribeMaintenanceWindowTasksPages(&ssm.DescribeMaintenanceWindowTasksInput{WindowId: aws.String(window.ID)},
+			func(page *ssm.DescribeMaintenanceWindowTasksOutput, lastPage bool) bool {
+				windowDetails.Tasks = append(windowDetails.Tasks, page.Tasks...)
+				return true
+			})
+		common.FatalOnErrorW(err, "failed to list the maintenance window tasks")
+
+		details = append(details, windowDetails)
+	}
+
+	if *format == "json" {
+		output, err := json.MarshalIndent(details, "", "  ")
+		common.FatalOnErrorW(err, "failed to serialise the maintenance windows")
+		fmt.Println(string(output))
+		return
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(writer, "ID\tNAME\tENABLED\tSCHEDULE\tTIMEZONE\tNEXT EXECUTION\tTARGETS\tTASKS")
+	for _, windowDetails := range details {
+		window := windowDetails.Window
+		next := ""
+		if window.NextExecutionTime != nil {
+			next = window.NextExecutionTime.Format(time.RFC3339)
+		}
+		fmt.Fprintf(writer, "%s\t%s\t%t\t%s\t%s\t%s\t%d\t%d\n",
+			window.ID,
+			window.Name,
+			window.Enabled,
+			window.Schedule,
+			window.ScheduleTimezone,
+			next,
+			len(windowDetails.Targets),
+			len(windowDetails.Tasks),
+		)
+	}
+	writer.Flush()
+}
+
+func printChanges(changes []Change) {
+	if *format == "json" {
+		output, err := json.MarshalIndent(changes, "", "  ")
+		common.FatalOnErrorW(err, "failed to serialise the changes")
+		fmt.Println(string(output))
+		return
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(writer, "ID\tNAME\tACTION\tSTART DATE")
+	for _, change := range changes {
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%s\n", change.Window.ID, change.Window.Name, change.Action, change.StartDate)
+	}
+	writer.Flush()
+}
+
+func changeWindows(client *ssm.SSM, windows []Window, action string, untilTime time.Time) {
+	changes, err := Plan(windows, action, untilTime)
+	common.FatalOnErrorW(err, "failed to plan the changes")
+
+	printChanges(changes)
+	if *dryRun || len(changes) == 0 {
+		return
+	}
+
+	// don't overwrite the state of a previous freeze that wasn't restored
+	if _, err := os.Stat(*stateFile); err == nil {
+		common.Fatalln(fmt.Sprintf("State file %s already exists, restore it first", *stateFile))
+	}
+
+	state := State{
+		CreatedAt: time.Now().UTC(),
+		Changes:   changes,
+	}
+	for _, change := range changes {
+		window, err := client.GetMaintenanceWindow(&ssm.GetMaintenanceWindowInput{WindowId: aws.String(change.Window.ID)})
+		common.FatalOnErrorW(err, "failed to get the maintenance window")
+		state.Windows = append(state.Windows, window)
+	}
+
+	data, err := json.MarshalIndent(state, "", "  ")
+	common.FatalOnErrorW(err, "failed to serialise the state")
+	err = ioutil.WriteFile(*stateFile, data, 0644)
+	common.FatalOnErrorW(err, "failed to write the state file")
+
+	for _, change := range changes {
+		input := &ssm.UpdateMaintenanceWindowInput{WindowId: aws.String(change.Window.ID)}
+		if change.Action == ActionPause {
+			input.Enabled = aws.Bool(false)
+		} else {
+			input.StartDate = aws.String(change.StartDate)
+		}
+
+		_, err := client.UpdateMaintenanceWindow(input)
+		common.FatalOnErrorW(err, fmt.Sprintf("failed to update the maintenance window %s", change.Window.ID))
+	}
+}
+
+func restoreWindows(client *ssm.SSM) {
+	data, err := ioutil.ReadFile(*stateFile)
+	common.FatalOnErrorW(err, "failed to read the state file")
+
+	state := State{}
+	err = json.Unmarshal(data, &state)
+	common.FatalOnErrorW(err, "failed to parse the state file")
+
+	printChanges(state.Changes)
+	if *dryRun {
+		return
+	}
+
+	for _, window := range state.Windows {
+		// replace to also restore the start dates that were not set
+		_, err := client.UpdateMaintenanceWindow(&ssm.UpdateMaintenanceWindowInput{
+			WindowId:                 window.WindowId,
+			Name:                     window.Name,
+			Description:              window.Description,
+			Schedule:                 window.Schedule,
+			ScheduleTimezone:         window.ScheduleTimezone,
+			ScheduleOffset:           window.ScheduleOffset,
+			Duration:                 window.Duration,
+			Cutoff:                   window.Cutoff,
+			AllowUnassociatedTargets: window.AllowUnassociatedTargets,
+			Enabled:                  window.Enabled,
+			StartDate:                window.StartDate,
+			EndDate:                  window.EndDate,
+			Replace:                  aws.Bool(true),
+		})
+		common.FatalOnErrorW(err, fmt.Sprintf("failed to restore the maintenance window %s", aws.StringValue(window.WindowId)))
+	}
+
+	err = os.Remove(*stateFile)
+	common.FatalOnErrorW(err, "failed to remove the state file")
+}
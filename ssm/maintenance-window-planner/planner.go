@@ -0,0 +1,107 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	ActionPause = "pause"
+	ActionShift = "shift"
+)
+
+// Window is the subset of a maintenance window used to plan the changes.
+type Window struct {
+	ID                string     `json:"window_id"`
+	Name              string     `json:"name"`
+	Enabled           bool       `json:"enabled"`
+	Schedule          string     `json:"schedule"`
+	ScheduleTimezone  string     `json:"schedule_timezone,omitempty"`
+	StartDate         string     `json:"start_date,omitempty"`
+	EndDate           string     `json:"end_date,omitempty"`
+	NextExecutionTime *time.Time `json:"next_execution_time,omitempty"`
+}
+
+// Change is a change to apply to a maintenance window.
+type Change struct {
+	Window    Window `json:"window"`
+	Action    string `json:"action"`
+	StartDate string `json:"start_date,omitempty"`
+}
+
+// ParseTime parses the dates of the SSM API (e.g. 2021-01-20T02:00Z) and
+// of the flags, which can also be a day.
+func ParseTime(value string) (time.Time, error) {
+	for _, layout := range []string{time.RFC3339, "2006-01-02T15:04Z07:00", "2006-01-02"} {
+		t, err := time.Parse(layout, value)
+		if err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date %s", value)
+}
+
+// Select returns the windows with one of the ids, or with the name prefix.
+func Select(windows []Window, ids []string, namePrefix string) []Window {
+	only := map[string]bool{}
+	for _, id := range ids {
+		only[id] = true
+	}
+
+	selected := []Window{}
+	for _, window := range windows {
+		if len(only) > 0 && !only[window.ID] {
+			continue
+		}
+		if !strings.HasPrefix(window.Name, namePrefix) {
+			continue
+		}
+		selected = append(selected, window)
+	}
+	return selected
+}
+
+// Plan returns the changes to pause the enabled windows, or to shift the start
+// date of the enabled windows that would run before until to until.
+func Plan(windows []Window, action string, until time.Time) ([]Change, error) {
+	changes := []Change{}
+	for _, window := range windows {
+		if !window.Enabled {
+			continue
+		}
+
+		switch action {
+		case ActionPause:
+			changes = append(changes, Change{Window: window, Action: ActionPause})
+		case ActionShift:
+			if window.NextExecutionTime != nil && !window.NextExecutionTime.Before(until) {
+				continue
+			}
+			if window.StartDate != "" {
+				startDate, err := ParseTime(window.StartDate)
+				if err != nil {
+					return nil, err
+				}
+				if !startDate.Before(until) {
+					continue
+				}
+			}
+			if window.EndDate != "" {
+				endDate, err := ParseTime(window.EndDate)
+				if err != nil {
+					return nil, err
+				}
+				// the window ends during the freeze
+				if !endDate.After(until) {
+					changes = append(changes, Change{Window: window, Action: ActionPause})
+					continue
+				}
+			}
+			changes = append(changes, Change{Window: window, Action: ActionShift, StartDate: until.UTC().Format(time.RFC3339)})
+		default:
+			return nil, fmt.Errorf("unknown action %s", action)
+		}
+	}
+	return changes, nil
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func windowsForTest(t *testing.T) []Window {
+	next := func(value string) *time.Time {
+		parsed, err := ParseTime(value)
+		require.NoError(t, err)
+		return &parsed
+	}
+
+	return []Window{
+		{ID: "mw-1", Name: "patch-web", Enabled: true, NextExecutionTime: next("2021-01-20T02:00Z")},
+		{ID: "mw-2", Name: "patch-db", Enabled: true, NextExecutionTime: next("2021-02-20T02:00Z")},
+		{ID: "mw-3", Name: "patch-old", Enabled: false, NextExecutionTime: next("2021-01-20T02:00Z")},
+		{ID: "mw-4", Name: "backup", Enabled: true, NextExecutionTime: next("2021-01-21T02:00Z"), EndDate: "2021-01-25T00:00:00Z"},
+	}
+}
+
+func TestSelect(t *testing.T) {
+	windows := windowsForTest(t)
+
+	assert.Len(t, Select(windows, nil, ""), 4)
+	assert.Len(t, Select(windows, nil, "patch-"), 3)
+
+	selected := Select(windows, []string{"mw-2", "mw-4"}, "patch-")
+	require.Len(t, selected, 1)
+	assert.Equal(t, "mw-2", selected[0].ID)
+}
+
+func TestPlan(t *testing.T) {
+	windows := windowsForTest(t)
+	until, err := ParseTime("2021-02-01")
+	require.NoError(t, err)
+
+	changes, err := Plan(windows, ActionPause, until)
+	require.NoError(t, err)
+	require.Len(t, changes, 3)
+
+	changes, err = Plan(windows, ActionShift, until)
+	require.NoError(t, err)
+	require.Len(t, changes, 2)
+	assert.Equal(t, "mw-1", changes[0].Window.ID)
+	assert.Equal(t, ActionShift, changes[0].Action)
+	assert.Equal(t, "2021-02-01T00:00:00Z", changes[0].StartDate)
+	assert.Equal(t, "mw-4", changes[1].Window.ID)
+	assert.Equal(t, ActionPause, changes[1].Action)
+
+	_, err = Plan(windows, "delete", until)
+	assert.Error(t, err)
+}
+
+func TestParseTime(t *testing.T) {
+	for _, value := range []string{"2021-01-20T02:00Z", "2021-01-20T02:00:00Z", "2021-01-20T03:00:00+01:00"} {
+		parsed, err := ParseTime(value)
+		require.NoError(t, err)
+		assert.True(t, parsed.Equal(time.Date(2021, 1, 20, 2, 0, 0, 0, time.UTC)), value)
+	}
+
+	_, err := ParseTime("tomorrow")
+	assert.Error(t, err)
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"sort"
+	"strings"
+)
+
+const (
+	IPv4 = "IPV4"
+	IPv6 = "IPV6"
+
+	// maximum number of addresses in an IP set
+	maxAddresses = 10000
+)
+
+// NormaliseCIDR validates an address or CIDR of the given version (IPV4 or
+// IPV6) and returns its network in CIDR notation, single addresses get a /32
+// or /128 prefix.
+func NormaliseCIDR(value, version string) (string, error) {
+	value = strings.TrimSpace(value)
+	if !strings.Contains(value, "/") {
+		ip := net.ParseIP(value)
+		if ip == nil {
+			return "", fmt.Errorf("invalid address %s", value)
+		}
+		if ip.To4() != nil {
+			value += "/32"
+		} else {
+			value += "/128"
+		}
+	}
+
+	ip, network, err := net.ParseCIDR(value)
+	if err != nil {
+		return "", err
+	}
+
+	isIPv4 := ip.To4() != nil
+	if (version == IPv4) != isIPv4 {
+		return "", fmt.Errorf("%s is not an %s address", value, version)
+	}
+
+	return network.String(), nil
+}
+
+// ReadCIDRs reads one address or CIDR per line, ignoring empty lines and
+// comments starting with #.
+func ReadCIDRs(reader io.Reader) ([]string, error) {
+	cidrs := []string{}
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if index := strings.Index(line, "#"); index >= 0 {
+			line = line[:index]
+		}
+		line = strings.TrimSpace(line)
+		if line != "" {
+			cidrs = append(cidrs, line)
+		}
+	}
+	return cidrs, scanner.Err()
+}
+
+type Change struct {
+	Addresses []string
+	Added     []string
+	Removed   []string
+}
+
+func (c *Change) Empty() bool {
+	return len(c.Added) == 0 && len(c.Removed) == 0
+}
+
+func normaliseAll(values []string, version string) ([]string, error) {
+	result := []string{}
+	for _, value := range values {
+		cidr, err := NormaliseCIDR(value, version)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, cidr)
+	}
+	return result, nil
+}
+
+// Apply adds and removes CIDRs to the addresses of an IP set. Adding an
+// existing CIDR or removing a missing one is not a change.
+func Apply(addresses []string, add []string, remove []string, version string) (*Change, error) {
+	add, err := normaliseAll(add, version)
+	if err != nil {
+		return nil, err
+	}
+	remove, err = normaliseAll(remove, version)
+	if err != nil {
+		return nil, err
+	}
+
+	set := map[string]bool{}
+	for _, address := range addresses {
+		set[address] = true
+	}
+
+	change := &Change{Added: []string{}, Removed: []string{}}
+	for _, cidr := range remove {
+		if set[cidr] {
+			delete(set, cidr)
+			change.Removed = append(change.Removed, cidr)
+		}
+	}
+	for _, cidr := range add {
+		if !set[cidr] {
+			set[cidr] = true
+			change.Added = append(change.Added, cidr)
+		}
+	}
+
+	change.Addresses = []string{}
+	for address := range set {
+		change.Addresses = append(change.Addresses, address)
+	}
+	sort.Strings(change.Addresses)
+	sort.Strings(change.Added)
+	sort.Strings(change.Removed)
+
+	if len(change.Addresses) > maxAddresses {
+		return nil, fmt.Errorf("an IP set can't have more than %d addresses, got %d", maxAddresses, len(change.Addresses))
+	}
+
+	return change, nil
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormaliseCIDR(t *testing.T) {
+	cidr, err := NormaliseCIDR("10.0.0.1", IPv4)
+	require.NoError(t, err)
+	require.Equal(t, "10.0.0.1/32", cidr)
+
+	cidr, err = NormaliseCIDR(" 10.0.1.17/24 ", IPv4)
+	require.NoError(t, err)
+	require.Equal(t, "10.0.1.0/24", cidr)
+
+	cidr, err = NormaliseCIDR("2001:db8::1", IPv6)
+	require.NoError(t, err)
+	require.Equal(t, "2001:db8::1/128", cidr)
+
+	_, err = NormaliseCIDR("2001:db8::/32", IPv4)
+	require.Error(t, err)
+
+	_, err = NormaliseCIDR("not-an-ip", IPv4)
+	require.Error(t, err)
+}
+
+func TestReadCIDRs(t *testing.T) {
+	cidrs, err := ReadCIDRs(strings.NewReader("# incident 42\n192.0.2.1\n\n198.51.100.0/24 # scanner\n"))
+	require.NoError(t, err)
+	require.Equal(t, []string{"192.0.2.1", "198.51.100.0/24"}, cidrs)
+}
+
+func TestApply(t *testing.T) {
+	change, err := Apply(
+		[]string{"192.0.2.1/32", "198.51.100.0/24"},
+		[]string{"203.0.113.7", "192.0.2.1"},
+		[]string{"198.51.100.0/24", "10.0.0.0/8"},
+		IPv4,
+	)
+	require.NoError(t, err)
+	require.Equal(t, []string{"192.0.2.1/32", "203.0.113.7/32"}, change.Addresses)
+	require.Equal(t, []string{"203.0.113.7/32"}, change.Added)
+	require.Equal(t, []string{"198.51.100.0/24"}, change.Removed)
+	require.False(t, change.Empty())
+
+	change, err = Apply([]string{"192.0.2.1/32"}, []string{"192.0.2.1"}, nil, IPv4)
+	require.NoError(t, err)
+	require.True(t, change.Empty())
+}
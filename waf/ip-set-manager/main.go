@@ -0,0 +1,138 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/wafv2"
+	"github.com/hamstah/awstools/common"
+	log "github.com/sirupsen/logrus"
+	kingpin "gopkg.in/alecthomas/kingpin.v2"
+)
+
+var (
+	name        = kingpin.Flag("name", "Name of the IP set.").Required().String()
+	id          = kingpin.Flag("id", "ID of the IP set, looked up from the name by default.").String()
+	scope       = kingpin.Flag("scope", "Scope of the IP set, CLOUDFRONT IP sets are in us-east-1.").Default(wafv2.ScopeRegional).Enum(wafv2.ScopeRegional, wafv2.ScopeCloudfront)
+	add         = kingpin.Flag("add", "Address or CIDR to add. Can be repeated.").Strings()
+	remove      = kingpin.Flag("remove", "Address or CIDR to remove. Can be repeated.").Strings()
+	addFile     = kingpin.Flag("add-file", "File with the addresses or CIDRs to add, one per line.").String()
+	removeFile  = kingpin.Flag("remove-file", "File with the addresses or CIDRs to remove, one per line.").String()
+	dryRun      = kingpin.Flag("dry-run", "Print the changes without applying them.").Default("false").Bool()
+	maxAttempts = kingpin.Flag("max-attempts", "Number of attempts when the IP set is changed by someone else at the same time.").Default("5").Int()
+)
+
+func readCIDRsFile(filename string) []string {
+	if filename == "" {
+		return []string{}
+	}
+
+	file, err := os.Open(filename)
+	common.FatalOnErrorW(err, "failed to open the file")
+	defer file.Close()
+
+	cidrs, err := ReadCIDRs(file)
+	common.FatalOnErrorW(err, fmt.Sprintf("failed to read %s", filename))
+	return cidrs
+}
+
+func findIPSetID(client *wafv2.WAFV2) (string, error) {
+	input := &wafv2.ListIPSetsInput{Scope: scope}
+	for {
+		page, err := client.ListIPSets(input)
+		if err != nil {
+			return "", err
+		}
+
+		for _, ipSet := range page.IPSets {
+			if aws.StringValue(ipSet.Name) == *name {
+				return *ipSet.Id, nil
+			}
+		}
+
+		if page.NextMarker == nil || len(page.IPSets) == 0 {
+			break
+		}
+		input.NextMarker = page.NextMarker
+	}
+
+	return "", fmt.Errorf("IP set %s not found", *name)
+}
+
+func printChange(change *Change) {
+	for _, cidr := range change.Removed {
+		fmt.Printf("- %s\n", cidr)
+	}
+	for _, cidr := range change.Added {
+		fmt.Printf("+ %s\n", cidr)
+	}
+}
+
+func main() {
+	kingpin.CommandLine.Name = "waf-ip-set-manager"
+	kingpin.CommandLine.Help = "Add or remove addresses in WAFv2 IP sets."
+	flags := common.HandleFlags()
+
+	session, conf := common.OpenSession(flags)
+	if *scope == wafv2.ScopeCloudfront {
+		conf = conf.Copy(&aws.Config{Region: aws.String("us-east-1")})
+	}
+	client := wafv2.New(session, conf)
+
+	additions := append(*add, readCIDRsFile(*addFile)...)
+	removals := append(*remove, readCIDRsFile(*removeFile)...)
+
+	ipSetID := *id
+	if ipSetID == "" {
+		var err error
+		ipSetID, err = findIPSetID(client)
+		common.FatalOnError(err)
+	}
+
+	for attempt := 1; ; attempt++ {
+		ipSet, err := client.GetIPSet(&wafv2.GetIPSetInput{
+			Name:  name,
+			Id:    aws.String(ipSetID),
+			Scope: scope,
+		})
+		common.FatalOnErrorW(err, "failed to get the IP set")
+
+		change, err := Apply(aws.StringValueSlice(ipSet.IPSet.Addresses), additions, removals, aws.StringValue(ipSet.IPSet.IPAddressVersion))
+		common.FatalOnError(err)
+
+		if len(additions) == 0 && len(removals) == 0 {
+			// nothing to change, list the addresses
+			for _, address := range change.Addresses {
+				fmt.Println(address)
+			}
+			return
+		}
+
+		if attempt == 1 {
+			printChange(change)
+		}
+		if *dryRun || change.Empty() {
+			return
+		}
+
+		_, err = client.UpdateIPSet(&wafv2.UpdateIPSetInput{
+			Name:        name,
+			Id:          aws.String(ipSetID),
+			Scope:       scope,
+			Description: ipSet.IPSet.Description,
+			Addresses:   aws.StringSlice(change.Addresses),
+			LockToken:   ipSet.LockToken,
+		})
+		if err == nil {
+			return
+		}
+
+		awsErr, ok := err.(awserr.Error)
+		if !ok || awsErr.Code() != wafv2.ErrCodeWAFOptimisticLockException || attempt >= *maxAttempts {
+			common.FatalOnErrorW(err, "failed to update the IP set")
+		}
+		log.WithField("attempt", attempt).Warn("The IP set was changed by someone else, retrying")
+	}
+}
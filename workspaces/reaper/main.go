@@ -0,0 +1,294 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/appstream"
+	"github.com/aws/aws-sdk-go/service/workspaces"
+	"github.com/hamstah/awstools/common"
+	kingpin "gopkg.in/alecthomas/kingpin.v2"
+)
+
+var (
+	scanRegions    = kingpin.Flag("scan-region", "Region of the workspaces and fleets. Can be repeated, defaults to the session region.").Strings()
+	unusedDays     = kingpin.Flag("unused-days", "Number of days without connection after which a workspace is unused.").Default("30").Int()
+	neverConnected = kingpin.Flag("include-never-connected", "Consider the workspaces nobody ever connected to as unused.").Default("false").Bool()
+	directoryID    = kingpin.Flag("directory-id", "Only the workspaces of this directory.").String()
+	excludeUsers   = kingpin.Flag("exclude-user", "User whose workspace is never stopped or terminated. Can be repeated.").Strings()
+	action         = kingpin.Flag("action", "Action on the unused workspaces.").Default(ActionList).Enum(ActionList, ActionStop, ActionTerminate)
+	dryRun         = kingpin.Flag("dry-run", "Only report the unused workspaces that would be stopped or terminated.").Default("false").Bool()
+	format         = kingpin.Flag("format", "Output format.").Default("text").Enum("text", "json")
+)
+
+type Inventory struct {
+	Workspaces []*Workspace `json:"workspaces"`
+	Fleets     []*Fleet     `json:"appstream_fleets"`
+}
+
+func listWorkspaces(client *workspaces.WorkSpaces, region string) ([]*Workspace, error) {
+	input := &workspaces.DescribeWorkspacesInput{}
+	if *directoryID != "" {
+		input.DirectoryId = directoryID
+	}
+
+	result := []*Workspace{}
+	byID := map[string]*Workspace{}
+	err := client.DescribeWorkspacesPages(input,
+		func(page *workspaces.DescribeWorkspacesOutput, lastPage bool) bool {
+			for _, workspace := range page.Workspaces {
+				item := &Workspace{
+					Region:      region,
+					ID:          aws.StringValue(workspace.WorkspaceId),
+					UserName:    aws.StringValue(workspace.UserName),
+					DirectoryID: aws.StringValue(workspace.DirectoryId),
+					State:       aws.StringValue(workspace.State),
+				}
+				if workspace.WorkspaceProperties != nil {
+					item.RunningMode = aws.StringValue(workspace.WorkspaceProperties.RunningMode)
+				}
+				result = append(result, item)
+				byID[item.ID] = item
+			}
+			return true
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	ids := []string{}
+	for _, workspace := range result {
+		ids = append(ids, workspace.ID)
+	}
+	for _, batch := range Batches(ids) {
+		connectionInput := &workspaces.DescribeWorkspacesConnectionStatusInput{WorkspaceIds: aws.StringSlice(batch)}
+		for {
+			page, err := client.DescribeWorkspacesConnectionStatus(connectionInput)
+			if err != nil {
+				return nil, err
+			}
+			for _, status := range page.WorkspacesConnectionStatus {
+				if workspace, ok := byID[aws.StringValue(status.WorkspaceId)]; ok {
+					workspace.ConnectionState = aws.StringValue(status.ConnectionState)
+					workspace.LastConnection = status.LastKnownUserConnectionTimestamp
+				}
+			}
+			if page.NextToken == nil {
+				break
+			}
+			connectionInput.NextToken = page.NextToken
+		}
+	}
+	return result, nil
+}
+
+func listFleets(client *appstream.AppStream, region string) ([]*Fleet, error) {
+	result := []*Fleet{}
+	input := &appstream.DescribeFleetsInput{}
+	for {
+		page, err := client.DescribeFleets(input)
+		if err != nil {
+			return nil, err
+		}
+		for _, fleet := range page.Fleets {
+			item := &Fleet{
+				Region:       region,
+				Name:         aws.StringValue(fleet.Name),
+				State:        aws.StringValue(fleet.State),
+				FleetType:    aws.StringValue(fleet.FleetType),
+				InstanceType: aws.StringValue(fleet.InstanceType),
+			}
+			if capacity := fleet.ComputeCapacityStatus; capacity != nil {
+				item.Desired = aws.Int64Value(capacity.Desired)
+				item.Running = aws.Int64Value(capacity.Running)
+				item.InUse = aws.Int64Value(capacity.InUse)
+			}
+			result = append(result, item)
+		}
+		if page.NextToken == nil {
+			break
+		}
+		input.NextToken = page.NextToken
+	}
+	return result, nil
+}
+
+// apply stops or terminates the workspaces and returns the failures by id.
+func apply(client *workspaces.WorkSpaces, ids []string) (map[string]string, error) {
+	failures := map[string]string{}
+	for _, batch := range Batches(ids) {
+		var failed []*workspaces.FailedWorkspaceChangeRequest
+		if *action == ActionStop {
+			requests := []*workspaces.StopRequest{}
+			for _, id := range batch {
+				requests = append(requests, &workspaces.StopRequest{WorkspaceId: aws.String(id)})
+			}
+			res, err := client.StopWorkspaces(&workspaces.StopWorkspacesInput{StopWorkspaceRequests: requests})
+			if err != nil {
+				return nil, err
+			}
+			failed = res.FailedRequests
+		} else {
+			requests := []*workspaces.TerminateRequest{}
+			for _, id := range batch {
+				requests = append(requests, &workspaces.TerminateRequest{WorkspaceId: aws.String(id)})
+			}
+			res, err := client.TerminateWorkspaces(&workspaces.TerminateWorkspacesInput{TerminateWorkspaceRequests: requests})
+			if err != nil {
+				return nil, err
+			}
+			failed = res.FailedRequests
+		}
+
+		for _, request := range failed {
+			failures[aws.StringValue(request.WorkspaceId)] = fmt.Sprintf("%s: %s", aws.StringValue(request.ErrorCode), aws.StringValue(request.ErrorMessage))
+		}
+	}
+	return failures, nil
+}
+
+func contains(values []string, value string) bool {
+	for _, item := range values {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+func formatTime(value *time.Time) string {
+	if value == nil {
+		return "never"
+	}
+	return value.UTC().Format(time.RFC3339)
+}
+
+func main() {
+	kingpin.CommandLine.Name = "workspaces-reaper"
+	kingpin.CommandLine.Help = "List the WorkSpaces and AppStream fleets, and stop or terminate the unused workspaces."
+	flags := common.HandleFlags()
+
+	sess, conf := common.OpenSession(flags)
+
+	regions := *scanRegions
+	if len(regions) == 0 {
+		regions = []string{aws.StringValue(conf.Region)}
+	}
+
+	excluded := map[string]bool{}
+	for _, user := range *excludeUsers {
+		excluded[user] = true
+	}
+
+	now := time.Now()
+	inventory := &Inventory{Workspaces: []*Workspace{}, Fleets: []*Fleet{}}
+	failed := false
+	for _, region := range regions {
+		regionConf := conf.Copy(&aws.Config{Region: aws.String(region)})
+		client := workspaces.New(sess, regionConf)
+
+		regionWorkspaces, err := listWorkspaces(client, region)
+		common.FatalOnErrorW(err, fmt.Sprintf("failed to list the workspaces of %s", region))
+		inventory.Workspaces = append(inventory.Workspaces, regionWorkspaces...)
+
+		fleets, err := listFleets(appstream.New(sess, regionConf), region)
+		common.FatalOnErrorW(err, fmt.Sprintf("failed to list the AppStream fleets of %s", region))
+		inventory.Fleets = append(inventory.Fleets, fleets...)
+
+		toApply := []string{}
+		for _, workspace := range regionWorkspaces {
+			workspace.Unused = workspace.IsUnused(now, *unusedDays, *neverConnected)
+			if !workspace.Unused || *action == ActionList {
+				continue
+			}
+			if excluded[workspace.UserName] {
+				workspace.Action = "excluded"
+				continue
+			}
+			if !workspace.CanApply(*action) {
+				workspace.Action = fmt.Sprintf("skipped: can't %s a %s %s workspace", *action, workspace.RunningMode, workspace.State)
+				continue
+			}
+			if *dryRun {
+				workspace.Action = fmt.Sprintf("would %s", *action)
+				continue
+			}
+			toApply = append(toApply, workspace.ID)
+		}
+		if len(toApply) == 0 {
+			continue
+		}
+
+		failures, err := apply(client, toApply)
+		common.FatalOnErrorW(err, fmt.Sprintf("failed to %s the workspaces of %s", *action, region))
+		for _, workspace := range regionWorkspaces {
+			if !contains(toApply, workspace.ID) {
+				continue
+			}
+			if failure, ok := failures[workspace.ID]; ok {
+				workspace.Action = fmt.Sprintf("failed: %s", failure)
+				failed = true
+				continue
+			}
+			workspace.Action = map[string]string{ActionStop: "stopped", ActionTerminate: "terminated"}[*action]
+		}
+	}
+
+	if *format == "json" {
+		output, err := json.MarshalIndent(inventory, "", "  ")
+		common.FatalOnErrorW(err, "failed to serialise the inventory")
+		fmt.Println(string(output))
+	} else {
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "REGION\tWORKSPACE\tUSER\tSTATE\tRUNNING MODE\tLAST CONNECTION\tUNUSED\tACTION")
+		for _, workspace := range inventory.Workspaces {
+			unused := ""
+			if workspace.Unused {
+				unused = "yes"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+				workspace.Region,
+				workspace.ID,
+				workspace.UserName,
+				workspace.State,
+				workspace.RunningMode,
+				formatTime(workspace.LastConnection),
+				unused,
+				workspace.Action,
+			)
+		}
+		w.Flush()
+
+		if len(inventory.Fleets) > 0 {
+			fmt.Println()
+			w = tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+			fmt.Fprintln(w, "REGION\tFLEET\tTYPE\tSTATE\tINSTANCE TYPE\tDESIRED\tRUNNING\tIN USE\tIDLE")
+			for _, fleet := range inventory.Fleets {
+				idle := ""
+				if fleet.Idle() {
+					idle = "yes"
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%d\t%d\t%d\t%s\n",
+					fleet.Region,
+					fleet.Name,
+					fleet.FleetType,
+					fleet.State,
+					fleet.InstanceType,
+					fleet.Desired,
+					fleet.Running,
+					fleet.InUse,
+					idle,
+				)
+			}
+			w.Flush()
+		}
+	}
+
+	if failed {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"time"
+)
+
+const (
+	ActionList      = "list"
+	ActionStop      = "stop"
+	ActionTerminate = "terminate"
+
+	// maximum number of workspaces per call of the WorkSpaces API
+	workspacesBatchSize = 25
+)
+
+type Workspace struct {
+	Region          string     `json:"region"`
+	ID              string     `json:"id"`
+	UserName        string     `json:"user_name"`
+	DirectoryID     string     `json:"directory_id"`
+	State           string     `json:"state"`
+	RunningMode     string     `json:"running_mode"`
+	ConnectionState string     `json:"connection_state"`
+	LastConnection  *time.Time `json:"last_connection"`
+	Unused          bool       `json:"unused"`
+	Action          string     `json:"action"`
+}
+
+// IsUnused returns true if the last connection to the workspace is older
+// than days. Workspaces never connected to are only unused with
+// neverConnected, the API doesn't return when they were created.
+func (w *Workspace) IsUnused(now time.Time, days int, neverConnected bool) bool {
+	if w.ConnectionState == "CONNECTED" {
+		return false
+	}
+	if w.LastConnection == nil {
+		return neverConnected
+	}
+	return now.Sub(*w.LastConnection) >= time.Duration(days)*24*time.Hour
+}
+
+// CanApply returns true if the action can be applied to the workspace in its
+// current state: only available AUTO_STOP workspaces can be stopped.
+func (w *Workspace) CanApply(action string) bool {
+	switch action {
+	case ActionStop:
+		return w.State == "AVAILABLE" && w.RunningMode == "AUTO_STOP"
+	case ActionTerminate:
+		return w.State != "TERMINATING" && w.State != "TERMINATED"
+	}
+	return false
+}
+
+// Batches splits the ids in batches of the maximum size of the WorkSpaces API.
+func Batches(ids []string) [][]string {
+	batches := [][]string{}
+	for start := 0; start < len(ids); start += workspacesBatchSize {
+		end := start + workspacesBatchSize
+		if end > len(ids) {
+			end = len(ids)
+		}
+		batches = append(batches, ids[start:end])
+	}
+	return batches
+}
+
+type Fleet struct {
+	Region       string `json:"region"`
+	Name         string `json:"name"`
+	State        string `json:"state"`
+	FleetType    string `json:"fleet_type"`
+	InstanceType string `json:"instance_type"`
+	Desired      int64  `json:"desired"`
+	Running      int64  `json:"running"`
+	InUse        int64  `json:"in_use"`
+}
+
+// Idle returns true if the fleet has running instances but no session.
+func (f *Fleet) Idle() bool {
+	return f.Running > 0 && f.InUse == 0
+}
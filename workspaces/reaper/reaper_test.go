@@ -0,0 +1,41 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsUnused(t *testing.T) {
+	now := time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC)
+	old := now.AddDate(0, 0, -45)
+	recent := now.AddDate(0, 0, -3)
+
+	assert.True(t, (&Workspace{LastConnection: &old}).IsUnused(now, 30, false))
+	assert.False(t, (&Workspace{LastConnection: &recent}).IsUnused(now, 30, false))
+	assert.False(t, (&Workspace{LastConnection: &old, ConnectionState: "CONNECTED"}).IsUnused(now, 30, false))
+	assert.False(t, (&Workspace{}).IsUnused(now, 30, false))
+	assert.True(t, (&Workspace{}).IsUnused(now, 30, true))
+}
+
+func TestCanApply(t *testing.T) {
+	assert.True(t, (&Workspace{State: "AVAILABLE", RunningMode: "AUTO_STOP"}).CanApply(ActionStop))
+	assert.False(t, (&Workspace{State: "AVAILABLE", RunningMode: "ALWAYS_ON"}).CanApply(ActionStop))
+	assert.False(t, (&Workspace{State: "STOPPED", RunningMode: "AUTO_STOP"}).CanApply(ActionStop))
+	assert.True(t, (&Workspace{State: "STOPPED", RunningMode: "AUTO_STOP"}).CanApply(ActionTerminate))
+	assert.False(t, (&Workspace{State: "TERMINATING"}).CanApply(ActionTerminate))
+	assert.False(t, (&Workspace{State: "AVAILABLE"}).CanApply(ActionList))
+}
+
+func TestBatches(t *testing.T) {
+	ids := []string{}
+	for i := 0; i < 60; i++ {
+		ids = append(ids, "ws")
+	}
+	batches := Batches(ids)
+	assert.Len(t, batches, 3)
+	assert.Len(t, batches[0], 25)
+	assert.Len(t, batches[2], 10)
+	assert.Empty(t, Batches([]string{}))
+}